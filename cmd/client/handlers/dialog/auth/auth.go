@@ -2,16 +2,25 @@ package auth
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
 	"github.com/ramil063/secondgodiplom/cmd/client/handlers/dialog"
+	accountService "github.com/ramil063/secondgodiplom/cmd/client/services/account"
 	authService "github.com/ramil063/secondgodiplom/cmd/client/services/auth"
 )
 
+// totpPromptAttempts сколько раз предлагается ввести код второго фактора
+const totpPromptAttempts = 3
+
 // Login основная функция авторизации пользователя
-func Login(client authService.Servicer) (dialog.AppState, dialog.UserSession) {
+// для учетной записи, ожидающей удаления, предлагается отменить удаление
+func Login(client authService.Servicer, accountServ accountService.Servicer) (dialog.AppState, dialog.UserSession) {
 	err := dialog.ClearScreen()
 	if err != nil {
 		fmt.Printf("❌ Ошибка очистки экрана: %v\n", err)
@@ -47,6 +56,13 @@ func Login(client authService.Servicer) (dialog.AppState, dialog.UserSession) {
 
 	// Отправка запроса авторизации
 	session, err := client.LoginProcess(login, password)
+	if status.Code(err) == codes.PermissionDenied && restoreAccount(accountServ, reader, login, password) {
+		session, err = client.LoginProcess(login, password)
+	}
+	var totpRequired *authService.TOTPRequiredError
+	if errors.As(err, &totpRequired) {
+		session, err = verifyTOTP(client, reader, totpRequired.Challenge, password)
+	}
 	if err != nil {
 		fmt.Printf("❌ Ошибка авторизации: %v\n", err)
 		err = dialog.PressEnterToContinue()
@@ -59,3 +75,77 @@ func Login(client authService.Servicer) (dialog.AppState, dialog.UserSession) {
 
 	return dialog.StateUserProfile, session
 }
+
+// restoreAccount предложение отменить отложенное удаление учетной записи
+// возвращает true, если учетная запись восстановлена
+func restoreAccount(accountServ accountService.Servicer, reader *bufio.Reader, login, password string) bool {
+	fmt.Print("Аккаунт деактивирован и ожидает удаления. Восстановить? (y/n): ")
+	answer, err := reader.ReadString('\n')
+	if err != nil || !strings.EqualFold(strings.TrimSpace(answer), "y") {
+		return false
+	}
+
+	if err = accountServ.RestoreAccountProcess(login, password); err != nil {
+		fmt.Printf("❌ Ошибка восстановления аккаунта: %v\n", err)
+		return false
+	}
+	fmt.Println("✅ Аккаунт восстановлен.")
+	return true
+}
+
+// verifyTOTP запрос кода второго фактора, при ошибке ввода код можно ввести еще раз
+func verifyTOTP(client authService.Servicer, reader *bufio.Reader, challenge, password string) (dialog.UserSession, error) {
+	var err error
+	for attempt := 1; attempt <= totpPromptAttempts; attempt++ {
+		fmt.Print("Код из приложения-аутентификатора или код восстановления: ")
+		var code string
+		code, err = reader.ReadString('\n')
+		if err != nil {
+			return dialog.UserSession{}, err
+		}
+
+		var session dialog.UserSession
+		session, err = client.VerifyTOTPProcess(challenge, strings.TrimSpace(code), password)
+		if err == nil {
+			return session, nil
+		}
+		fmt.Printf("❌ Неверный код: %v\n", err)
+	}
+	return dialog.UserSession{}, err
+}
+
+// Logout выход из аккаунта
+// по желанию пользователя завершаются сессии на всех устройствах
+func Logout(client authService.Servicer) (dialog.AppState, dialog.UserSession) {
+	err := dialog.ClearScreen()
+	if err != nil {
+		fmt.Printf("❌ Ошибка очистки экрана: %v\n", err)
+		return dialog.StateExit, dialog.UserSession{}
+	}
+	fmt.Println("\n=== ВЫХОД ИЗ АККАУНТА ===")
+
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Завершить сессии на всех устройствах? (y/n): ")
+	answer, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Printf("❌ Ошибка считывания ответа: %v\n", err)
+		return dialog.StateMainMenu, dialog.UserSession{}
+	}
+	allDevices := strings.EqualFold(strings.TrimSpace(answer), "y")
+
+	revoked, err := client.LogoutProcess(allDevices)
+	if err != nil {
+		fmt.Printf("⚠️ Сессия на сервере не завершена: %v\n", err)
+		fmt.Println("Сохраненные токены удалены.")
+	} else if allDevices {
+		fmt.Printf("✅ Вы вышли из аккаунта. Завершено сессий: %d\n", revoked)
+	} else {
+		fmt.Println("✅ Вы вышли из аккаунта.")
+	}
+
+	err = dialog.PressEnterToContinue()
+	if err != nil {
+		fmt.Printf("❌ Ошибка при нажатии на Enter: %v\n", err)
+	}
+	return dialog.StateMainMenu, dialog.UserSession{}
+}
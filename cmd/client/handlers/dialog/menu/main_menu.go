@@ -18,7 +18,8 @@ func ShowMainMenu() dialog.AppState {
 	fmt.Println("1. Регистрация")
 	fmt.Println("2. Авторизация")
 	fmt.Println("3. Профиль")
-	fmt.Println("4. Выход")
+	fmt.Println("4. Выйти из аккаунта")
+	fmt.Println("5. Выход")
 	fmt.Println("====================")
 	fmt.Print("Выберите действие: ")
 
@@ -38,6 +39,8 @@ func ShowMainMenu() dialog.AppState {
 	case "3":
 		return dialog.StateUserProfile
 	case "4":
+		return dialog.StateLogout
+	case "5":
 		return dialog.StateExit
 	default:
 		fmt.Println("❌ Неверный выбор! Попробуйте снова.")
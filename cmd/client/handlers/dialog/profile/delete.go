@@ -0,0 +1,41 @@
+package profile
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ramil063/secondgodiplom/cmd/client/services/account"
+)
+
+// deleteAccount удаление учетной записи после подтверждения паролем
+// возвращает true, если учетная запись удалена или деактивирована и сессия больше не действует
+func deleteAccount(accountServ account.Servicer) (bool, error) {
+	reader := bufio.NewReader(os.Stdin)
+
+	fmt.Println("⚠️ Все записи, файлы и сессии на всех устройствах будут удалены.")
+	fmt.Print("Отложить удаление, чтобы учетную запись можно было восстановить? (y/n): ")
+	answer, err := reader.ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("❌ Ошибка считывания ответа: %w", err)
+	}
+	schedule := strings.EqualFold(strings.TrimSpace(answer), "y")
+
+	fmt.Print("Введите пароль для подтверждения: ")
+	password, err := reader.ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("❌ Ошибка считывания пароля: %w", err)
+	}
+
+	purgeAt, err := accountServ.DeleteAccountProcess(strings.TrimSpace(password), schedule)
+	if err != nil {
+		return false, fmt.Errorf("❌ Ошибка удаления аккаунта: %w", err)
+	}
+	if schedule {
+		fmt.Printf("✅ Аккаунт деактивирован и будет удален %s. До этого его можно восстановить при входе.\n", purgeAt)
+	} else {
+		fmt.Println("✅ Аккаунт и все данные удалены.")
+	}
+	return true, nil
+}
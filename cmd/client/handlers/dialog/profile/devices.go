@@ -0,0 +1,75 @@
+package profile
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ramil063/secondgodiplom/cmd/client/handlers/dialog"
+	"github.com/ramil063/secondgodiplom/cmd/client/services/auth"
+)
+
+// showDevices список устройств, на которых выполнен вход, с возможностью завершить сессию
+func showDevices(authServ auth.Servicer) {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		err := dialog.ClearScreen()
+		if err != nil {
+			fmt.Printf("❌ Ошибка очистки экрана: %v\n", err)
+		}
+		fmt.Println("=== МОИ УСТРОЙСТВА ===")
+
+		sessions, err := authServ.GetSessions()
+		if err != nil {
+			fmt.Printf("❌ Ошибка получения списка сессий: %v\n", err)
+			err = dialog.PressEnterToContinue()
+			if err != nil {
+				fmt.Printf("❌ Ошибка при нажатии на Enter: %v\n", err)
+			}
+			return
+		}
+
+		for _, session := range sessions {
+			current := ""
+			if session.Current {
+				current = " (это устройство)"
+			}
+			lastUsedAt := session.LastUsedAt
+			if lastUsedAt == "" {
+				lastUsedAt = "-"
+			}
+			fmt.Printf("ID: %d%s\n", session.Id, current)
+			fmt.Printf("  Устройство: %s\n", session.UserAgent)
+			fmt.Printf("  IP: %s\n", session.ClientIp)
+			fmt.Printf("  Вход: %s\n", session.CreatedAt)
+			fmt.Printf("  Последнее обращение: %s\n", lastUsedAt)
+		}
+		fmt.Println("======================")
+		fmt.Print("Введите ID сессии, чтобы завершить ее (Enter - назад): ")
+
+		choice, err := reader.ReadString('\n')
+		if err != nil {
+			fmt.Printf("❌ Ошибка считывания выбора: %v\n", err)
+			return
+		}
+		choice = strings.TrimSpace(choice)
+		if choice == "" {
+			return
+		}
+
+		id, err := strconv.ParseInt(choice, 10, 64)
+		if err != nil {
+			fmt.Println("❌ Неверный ID сессии!")
+		} else if err = authServ.RevokeSession(id); err != nil {
+			fmt.Printf("❌ Ошибка завершения сессии: %v\n", err)
+		} else {
+			fmt.Println("✅ Сессия завершена")
+		}
+		err = dialog.PressEnterToContinue()
+		if err != nil {
+			fmt.Printf("❌ Ошибка при нажатии на Enter: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package profile
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ramil063/secondgodiplom/cmd/client/services/account"
+)
+
+// changePassword смена пароля учетной записи
+// пароль является и мастер-паролем хранилища, сессии на других устройствах завершаются
+func changePassword(accountServ account.Servicer) error {
+	reader := bufio.NewReader(os.Stdin)
+
+	fmt.Print("Текущий пароль: ")
+	oldPassword, err := reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("❌ Ошибка считывания пароля: %w", err)
+	}
+	fmt.Print("Новый пароль: ")
+	newPassword, err := reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("❌ Ошибка считывания пароля: %w", err)
+	}
+	fmt.Print("Повторите новый пароль: ")
+	repeatPassword, err := reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("❌ Ошибка считывания пароля: %w", err)
+	}
+
+	newPassword = strings.TrimSpace(newPassword)
+	if newPassword != strings.TrimSpace(repeatPassword) {
+		return fmt.Errorf("❌ Пароли не совпадают")
+	}
+
+	revoked, err := accountServ.ChangePasswordProcess(strings.TrimSpace(oldPassword), newPassword)
+	if err != nil {
+		return fmt.Errorf("❌ Ошибка смены пароля: %w", err)
+	}
+	fmt.Printf("✅ Пароль изменен. Завершено сессий на других устройствах: %d\n", revoked)
+	return nil
+}
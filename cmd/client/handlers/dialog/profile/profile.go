@@ -8,6 +8,8 @@ import (
 
 	"github.com/ramil063/secondgodiplom/cmd/client/handlers/dialog"
 	"github.com/ramil063/secondgodiplom/cmd/client/handlers/dialog/profile/items"
+	"github.com/ramil063/secondgodiplom/cmd/client/services/account"
+	"github.com/ramil063/secondgodiplom/cmd/client/services/auth"
 	"github.com/ramil063/secondgodiplom/cmd/client/services/items/bankcard"
 	"github.com/ramil063/secondgodiplom/cmd/client/services/items/binarydata"
 	"github.com/ramil063/secondgodiplom/cmd/client/services/items/password"
@@ -17,6 +19,8 @@ import (
 // UserProfile функция работы с главным меню профиля пользователя
 func UserProfile(
 	session dialog.UserSession,
+	authServ auth.Servicer,
+	accountServ account.Servicer,
 	bcServ bankcard.Servicer,
 	bServ binarydata.Servicer,
 	passwordServ password.Servicer,
@@ -30,6 +34,10 @@ func UserProfile(
 		fmt.Println("❌ Пожалуйста авторизуйтесь!")
 		return dialog.StateMainMenu // Выход в главное меню
 	}
+	if err := unlockVault(); err != nil {
+		fmt.Printf("❌ Ошибка разблокировки хранилища: %v\n", err)
+		return dialog.StateMainMenu // Выход в главное меню
+	}
 	for {
 		err := dialog.ClearScreen()
 		if err != nil {
@@ -55,8 +63,49 @@ func UserProfile(
 		case "4":
 			items.WorkWithFile(bServ)
 		case "5":
-			return dialog.StateMainMenu // Выход в главное меню
+			err = enableClientEncryption(authServ, bcServ, bServ, passwordServ, textdataServ)
+			if err != nil {
+				fmt.Println(err)
+			}
+			err = dialog.PressEnterToContinue()
+			if err != nil {
+				fmt.Printf("❌ Ошибка при нажатии на Enter: %v\n", err)
+			}
 		case "6":
+			showDevices(authServ)
+		case "7":
+			err = enableTOTP(authServ)
+			if err != nil {
+				fmt.Println(err)
+			}
+			err = dialog.PressEnterToContinue()
+			if err != nil {
+				fmt.Printf("❌ Ошибка при нажатии на Enter: %v\n", err)
+			}
+		case "8":
+			err = changePassword(accountServ)
+			if err != nil {
+				fmt.Println(err)
+			}
+			err = dialog.PressEnterToContinue()
+			if err != nil {
+				fmt.Printf("❌ Ошибка при нажатии на Enter: %v\n", err)
+			}
+		case "9":
+			deleted, err := deleteAccount(accountServ)
+			if err != nil {
+				fmt.Println(err)
+			}
+			err = dialog.PressEnterToContinue()
+			if err != nil {
+				fmt.Printf("❌ Ошибка при нажатии на Enter: %v\n", err)
+			}
+			if deleted {
+				return dialog.StateAccountDeleted
+			}
+		case "10":
+			return dialog.StateMainMenu // Выход в главное меню
+		case "11":
 			return dialog.StateExit // Полный выход
 		default:
 			fmt.Println("❌ Неверный выбор!")
@@ -75,8 +124,13 @@ func showUserProfileMenu() {
 	fmt.Println("2. Работа с текстом")
 	fmt.Println("3. Работа с банковскими картами")
 	fmt.Println("4. Работа с файлами")
-	fmt.Println("5. Выйти в главное меню")
-	fmt.Println("6. Выйти из приложения")
+	fmt.Println("5. Включить сквозное шифрование")
+	fmt.Println("6. Мои устройства")
+	fmt.Println("7. Включить двухфакторную аутентификацию")
+	fmt.Println("8. Сменить пароль")
+	fmt.Println("9. Удалить аккаунт")
+	fmt.Println("10. Выйти в главное меню")
+	fmt.Println("11. Выйти из приложения")
 	fmt.Println("========================")
 	fmt.Print("Выберите действие: ")
 }
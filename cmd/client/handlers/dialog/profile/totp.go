@@ -0,0 +1,39 @@
+package profile
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ramil063/secondgodiplom/cmd/client/services/auth"
+)
+
+// enableTOTP подключение второго фактора
+// секрет добавляется в приложение-аутентификатор, коды восстановления показываются один раз
+func enableTOTP(authServ auth.Servicer) error {
+	enrollment, err := authServ.EnrollTOTP()
+	if err != nil {
+		return fmt.Errorf("❌ Ошибка подключения двухфакторной аутентификации: %w", err)
+	}
+
+	fmt.Println("Добавьте аккаунт в приложение-аутентификатор:")
+	fmt.Printf("  Секрет: %s\n", enrollment.Secret)
+	fmt.Printf("  Ссылка: %s\n", enrollment.OtpauthUri)
+	fmt.Println("Сохраните коды восстановления, они понадобятся без доступа к приложению:")
+	for _, code := range enrollment.RecoveryCodes {
+		fmt.Printf("  %s\n", code)
+	}
+
+	fmt.Print("Введите код из приложения для подтверждения: ")
+	code, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("❌ Ошибка считывания кода: %w", err)
+	}
+	if err = authServ.ConfirmTOTP(strings.TrimSpace(code)); err != nil {
+		return fmt.Errorf("❌ Ошибка подтверждения кода: %w", err)
+	}
+
+	fmt.Println("✅ Двухфакторная аутентификация включена")
+	return nil
+}
@@ -0,0 +1,176 @@
+package profile
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ramil063/secondgodiplom/cmd/client/generics/list"
+	"github.com/ramil063/secondgodiplom/cmd/client/handlers/items"
+	bankcardQueue "github.com/ramil063/secondgodiplom/cmd/client/handlers/queue/bankcard"
+	binaryQueue "github.com/ramil063/secondgodiplom/cmd/client/handlers/queue/binary"
+	passwordQueue "github.com/ramil063/secondgodiplom/cmd/client/handlers/queue/password"
+	textdataQueue "github.com/ramil063/secondgodiplom/cmd/client/handlers/queue/textdata"
+	"github.com/ramil063/secondgodiplom/cmd/client/services/auth"
+	"github.com/ramil063/secondgodiplom/cmd/client/services/items/bankcard"
+	"github.com/ramil063/secondgodiplom/cmd/client/services/items/binarydata"
+	"github.com/ramil063/secondgodiplom/cmd/client/services/items/password"
+	"github.com/ramil063/secondgodiplom/cmd/client/services/items/textdata"
+	bankcardPb "github.com/ramil063/secondgodiplom/internal/proto/gen/items/bankcard"
+	binarydataPb "github.com/ramil063/secondgodiplom/internal/proto/gen/items/binarydata"
+	passwordPb "github.com/ramil063/secondgodiplom/internal/proto/gen/items/password"
+	textdataPb "github.com/ramil063/secondgodiplom/internal/proto/gen/items/textdata"
+)
+
+// unlockVault разблокировка хранилища мастер-паролем, если включено сквозное шифрование
+// например при запуске приложения с сохраненными токенами
+func unlockVault() error {
+	_, err := items.GetVault()
+	if !errors.Is(err, items.ErrVaultLocked) {
+		return err
+	}
+
+	masterPassword, err := readMasterPassword()
+	if err != nil {
+		return err
+	}
+	return items.UnlockVault(masterPassword)
+}
+
+// enableClientEncryption включение сквозного шифрования и перешифрование уже сохраненных данных
+// записи отправляются на обновление через очередь, файлы загружаются заново
+func enableClientEncryption(
+	authServ auth.Servicer,
+	bcServ bankcard.Servicer,
+	bServ binarydata.Servicer,
+	passwordServ password.Servicer,
+	textdataServ textdata.Servicer,
+) error {
+	v, err := items.GetVault()
+	if err != nil {
+		return err
+	}
+	if v != nil {
+		fmt.Println("Сквозное шифрование уже включено")
+		return nil
+	}
+
+	masterPassword, err := readMasterPassword()
+	if err != nil {
+		return err
+	}
+	if err = authServ.EnableClientEncryption(masterPassword); err != nil {
+		return fmt.Errorf("❌ Ошибка включения сквозного шифрования: %w", err)
+	}
+	fmt.Println("✅ Сквозное шифрование включено, перешифровываем сохраненные данные...")
+
+	err = forEachItem[passwordPb.PasswordItem](passwordServ, func(item *passwordPb.PasswordItem) error {
+		_, err := passwordQueue.SaveToUpdateQueue(item.Id, item.Login, item.Password, item.Target, item.Description)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("❌ Ошибка перешифрования паролей: %w", err)
+	}
+
+	err = forEachItem[textdataPb.TextDataItem](textdataServ, func(item *textdataPb.TextDataItem) error {
+		_, err := textdataQueue.SaveToUpdateQueue(item.Id, item.TextData, item.Description)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("❌ Ошибка перешифрования текстовых данных: %w", err)
+	}
+
+	err = forEachItem[bankcardPb.CardDataItem](bcServ, func(item *bankcardPb.CardDataItem) error {
+		_, err := bankcardQueue.SaveToUpdateQueue(
+			item.Id,
+			item.Number,
+			item.ValidUntilYear,
+			item.ValidUntilMonth,
+			item.Cvv,
+			item.Holder,
+			item.Description,
+		)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("❌ Ошибка перешифрования банковских карт: %w", err)
+	}
+
+	err = forEachItem[binarydataPb.FileListItem](bServ, func(item *binarydataPb.FileListItem) error {
+		if item.ClientEncrypted {
+			return nil
+		}
+		return reuploadFile(bServ, item)
+	})
+	if err != nil {
+		return fmt.Errorf("❌ Ошибка перешифрования файлов: %w", err)
+	}
+
+	fmt.Println("✅ Данные сохранены в очередь для перешифрования")
+	return nil
+}
+
+// reuploadFile повторная загрузка файла с шифрованием на клиенте
+// старая версия файла удаляется через очередь
+func reuploadFile(bServ binarydata.Servicer, item *binarydataPb.FileListItem) error {
+	tempDir, err := os.MkdirTemp("", "gophkeeper")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	filePath, err := bServ.DownloadData(items.CreateAuthContext(), item.Id, tempDir)
+	if err != nil {
+		return err
+	}
+	fileData, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = bServ.UploadData(items.CreateAuthContext(), fileData, fileInfo, filePath, item.Description)
+	if err != nil {
+		return err
+	}
+	_, err = binaryQueue.SaveToDeleteQueue(item.Id)
+	return err
+}
+
+// forEachItem обход всех страниц списка данных пользователя
+// страницы собираются заранее, так как обработка может менять список на сервере
+func forEachItem[T list.Listable](service list.Lister[T], fn func(item *T) error) error {
+	var allItems []*T
+	for page := int32(1); ; page++ {
+		resp, err := service.ListItems(items.CreateAuthContext(), page, "")
+		if err != nil {
+			return err
+		}
+		allItems = append(allItems, resp.Items...)
+		if page >= resp.TotalPages {
+			break
+		}
+	}
+
+	for _, item := range allItems {
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readMasterPassword() (string, error) {
+	fmt.Print("Введите мастер-пароль: ")
+	reader := bufio.NewReader(os.Stdin)
+	masterPassword, err := reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("❌ Ошибка считывания пароля: %w", err)
+	}
+	return strings.TrimSpace(masterPassword), nil
+}
@@ -6,8 +6,13 @@ import (
 	"os"
 	"strings"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
 	"github.com/ramil063/secondgodiplom/cmd/client/handlers/dialog"
 	"github.com/ramil063/secondgodiplom/cmd/client/services/registration"
+	"github.com/ramil063/secondgodiplom/internal/security/passpolicy"
 )
 
 // UserData данные необходимые пользователю для регистрации
@@ -23,52 +28,17 @@ func collectRegistrationData() (UserData, error) {
 	userData := UserData{}
 
 	// Сбор данных с валидацией
-	for {
-		fmt.Print("Введите логин: ")
-		login, err := reader.ReadString('\n')
-		if err != nil {
-			return userData, fmt.Errorf("❌ Ошибка считывания: %s\n", err)
-		}
-		login = strings.TrimSpace(login)
-
-		if len(login) < 3 {
-			fmt.Println("❌ Логин должен содержать минимум 3 символа")
-			continue
-		}
-		userData.Login = login
-		break
-	}
-
-	for {
-		fmt.Print("Введите пароль: ")
-		password, err := reader.ReadString('\n')
-		if err != nil {
-			return userData, fmt.Errorf("❌ Ошибка считывания: %s\n", err)
-		}
-		password = strings.TrimSpace(password)
-
-		if len(password) < 6 {
-			fmt.Println("❌ Пароль должен содержать минимум 6 символов")
-			continue
-		}
-		userData.Password = password
-		break
+	login, err := readLogin(reader)
+	if err != nil {
+		return userData, err
 	}
+	userData.Login = login
 
-	for {
-		fmt.Print("Введите подтверждение пароля: ")
-		passwordConfirm, err := reader.ReadString('\n')
-		if err != nil {
-			return userData, fmt.Errorf("❌ Ошибка считывания: %s\n", err)
-		}
-		passwordConfirm = strings.TrimSpace(passwordConfirm)
-
-		if passwordConfirm != userData.Password {
-			fmt.Println("❌ Неправильно задано подтверждение пароля")
-			continue
-		}
-		break
+	password, err := readPassword(reader, login)
+	if err != nil {
+		return userData, err
 	}
+	userData.Password = password
 
 	for {
 		fmt.Print("Введите имя: ")
@@ -121,21 +91,153 @@ func Registration(service registration.Servicer) dialog.AppState {
 	fmt.Printf("Имя: %s\n", userData.FirstName)
 	fmt.Printf("Фамилия: %s\n", userData.LastName)
 
-	if confirmData() {
+	if !confirmData() {
+		fmt.Println("❌ Регистрация отменена")
+		return dialog.StateMainMenu
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
 		resp, err := service.RegisterUser(
 			userData.Login,
 			userData.Password,
 			userData.FirstName,
 			userData.LastName)
-		if err != nil {
+		if err == nil {
+			fmt.Printf("Пользователь %s зарегистрирован успешно!\n", resp.UserId)
+			return dialog.StateMainMenu
+		}
+
+		// логин или пароль не подошли серверу: показываем нарушения и запрашиваем только их
+		violations := fieldViolations(err)
+		if len(violations) == 0 {
 			fmt.Println("❌ Возникла ошибка при регистрации пользователя:", err)
 			return dialog.StateMainMenu
 		}
-		fmt.Printf("Пользователь %s зарегистрирован успешно!\n", resp.UserId)
-	} else {
-		fmt.Println("❌ Регистрация отменена")
+		fmt.Println("❌ Данные не соответствуют требованиям сервера:")
+		fields := make(map[string]bool)
+		for _, violation := range violations {
+			fmt.Printf("   - %s: %s\n", fieldTitle(violation.Field), violation.Description)
+			fields[violation.Field] = true
+		}
+
+		if fields["login"] {
+			if userData.Login, err = readLogin(reader); err != nil {
+				fmt.Println("❌ Возникла ошибка при регистрации пользователя:", err)
+				return dialog.StateMainMenu
+			}
+		}
+		if fields["password"] {
+			if userData.Password, err = readPassword(reader, userData.Login); err != nil {
+				fmt.Println("❌ Возникла ошибка при регистрации пользователя:", err)
+				return dialog.StateMainMenu
+			}
+		}
+	}
+}
+
+// readLogin запрос логина
+func readLogin(reader *bufio.Reader) (string, error) {
+	for {
+		fmt.Print("Введите логин: ")
+		login, err := reader.ReadString('\n')
+		if err != nil {
+			return "", fmt.Errorf("❌ Ошибка считывания: %s\n", err)
+		}
+		login = strings.TrimSpace(login)
+
+		if len(login) < 3 {
+			fmt.Println("❌ Логин должен содержать минимум 3 символа")
+			continue
+		}
+		return login, nil
+	}
+}
+
+// readPassword запрос пароля с подтверждением
+// после ввода показывается оценка надежности; окончательно пароль проверяет сервер по своей политике
+func readPassword(reader *bufio.Reader, login string) (string, error) {
+	for {
+		fmt.Print("Введите пароль: ")
+		password, err := reader.ReadString('\n')
+		if err != nil {
+			return "", fmt.Errorf("❌ Ошибка считывания: %s\n", err)
+		}
+		password = strings.TrimSpace(password)
+
+		if password == "" {
+			fmt.Println("❌ Пароль не может быть пустым")
+			continue
+		}
+
+		strength := passpolicy.Estimate(password, login)
+		fmt.Printf("Надежность пароля: %s\n", strengthMeter(strength.Score))
+		if strength.Score < passpolicy.DefaultMinScore && !confirmWeakPassword(reader) {
+			continue
+		}
+
+		fmt.Print("Введите подтверждение пароля: ")
+		passwordConfirm, err := reader.ReadString('\n')
+		if err != nil {
+			return "", fmt.Errorf("❌ Ошибка считывания: %s\n", err)
+		}
+		if strings.TrimSpace(passwordConfirm) != password {
+			fmt.Println("❌ Неправильно задано подтверждение пароля")
+			continue
+		}
+		return password, nil
+	}
+}
+
+// strengthLabels подписи оценок надежности пароля
+var strengthLabels = []string{"очень слабый", "слабый", "средний", "хороший", "надежный"}
+
+// strengthMeter шкала надежности пароля: заполненные деления по оценке от 0 до 4
+func strengthMeter(score int) string {
+	score = max(passpolicy.ScoreVeryWeak, min(score, passpolicy.ScoreStrong))
+	return fmt.Sprintf(
+		"[%s%s] %s",
+		strings.Repeat("█", score),
+		strings.Repeat("░", passpolicy.ScoreStrong-score),
+		strengthLabels[score],
+	)
+}
+
+// confirmWeakPassword подтверждение слабого пароля, сервер может его отклонить
+func confirmWeakPassword(reader *bufio.Reader) bool {
+	fmt.Print("Пароль легко подобрать, сервер может его отклонить. Оставить его? (y/n): ")
+	answer, err := reader.ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.TrimSpace(strings.ToLower(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// fieldViolations нарушения требований к полям из ошибки InvalidArgument
+func fieldViolations(err error) []*errdetails.BadRequest_FieldViolation {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.InvalidArgument {
+		return nil
+	}
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			violations = append(violations, badRequest.FieldViolations...)
+		}
+	}
+	return violations
+}
+
+// fieldTitle название поля для пользователя
+func fieldTitle(field string) string {
+	switch field {
+	case "login":
+		return "логин"
+	case "password":
+		return "пароль"
 	}
-	return dialog.StateMainMenu
+	return field
 }
 
 func confirmData() bool {
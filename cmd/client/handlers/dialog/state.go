@@ -8,6 +8,8 @@ const (
 	StateRegistration
 	StateLogin
 	StateUserProfile
+	StateLogout
+	StateAccountDeleted
 	StateExit
 )
 
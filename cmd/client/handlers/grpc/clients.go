@@ -2,10 +2,13 @@ package grpc
 
 import (
 	"fmt"
+	"os"
+	"runtime"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/account"
 	"github.com/ramil063/secondgodiplom/internal/proto/gen/auth"
 	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/bankcard"
 	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/binarydata"
@@ -17,6 +20,7 @@ import (
 type Clients struct {
 	conn               *grpc.ClientConn
 	AuthClient         auth.AuthServiceClient
+	AccountClient      account.AccountServiceClient
 	RegistrationClient auth.RegistrationServiceClient
 	PasswordsClient    password.ServiceClient
 	TextDataClient     textdata.ServiceClient
@@ -30,6 +34,7 @@ func NewGRPCClients(serverAddr string) (*Clients, error) {
 	conn, err := grpc.NewClient(
 		serverAddr,
 		grpc.WithTransportCredentials(credentials),
+		grpc.WithUserAgent(userAgent()),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create gRPC client: %w", err)
@@ -38,6 +43,7 @@ func NewGRPCClients(serverAddr string) (*Clients, error) {
 	return &Clients{
 		conn:               conn,
 		AuthClient:         auth.NewAuthServiceClient(conn),
+		AccountClient:      account.NewAccountServiceClient(conn),
 		RegistrationClient: auth.NewRegistrationServiceClient(conn),
 		PasswordsClient:    password.NewServiceClient(conn),
 		TextDataClient:     textdata.NewServiceClient(conn),
@@ -45,3 +51,12 @@ func NewGRPCClients(serverAddr string) (*Clients, error) {
 		BinaryDataClient:   binarydata.NewServiceClient(conn),
 	}, nil
 }
+
+// userAgent название клиента с именем компьютера и платформой, по нему пользователь узнает устройство в списке сессий
+func userAgent() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("gophkeeper-client (%s; %s/%s)", hostname, runtime.GOOS, runtime.GOARCH)
+}
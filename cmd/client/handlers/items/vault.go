@@ -0,0 +1,116 @@
+package items
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+
+	cookieContants "github.com/ramil063/secondgodiplom/internal/constants/cookie"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/vault"
+)
+
+// ErrVaultLocked хранилище не разблокировано мастер-паролем
+var ErrVaultLocked = errors.New("vault is locked")
+
+var (
+	vaultMutex    sync.RWMutex
+	unlockedVault *vault.Vault
+)
+
+// PasswordPayload чувствительные данные пароля, шифруемые на клиенте
+type PasswordPayload struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+	Target   string `json:"target"`
+}
+
+// TextDataPayload текстовые данные, шифруемые на клиенте
+type TextDataPayload struct {
+	TextData string `json:"text_data"`
+}
+
+// BankCardPayload чувствительные данные банковской карты, шифруемые на клиенте
+type BankCardPayload struct {
+	Number          string `json:"number"`
+	ValidUntilYear  int32  `json:"valid_until_year"`
+	ValidUntilMonth int32  `json:"valid_until_month"`
+	Cvv             int32  `json:"cvv"`
+	Holder          string `json:"holder"`
+}
+
+// SetVault установка разблокированного хранилища для текущей сессии
+func SetVault(v *vault.Vault) {
+	vaultMutex.Lock()
+	defer vaultMutex.Unlock()
+	unlockedVault = v
+}
+
+// GetVault получение хранилища текущей сессии
+// если сквозное шифрование не включено, возвращается nil без ошибки
+// если включено, но хранилище не разблокировано, возвращается ErrVaultLocked
+func GetVault() (*vault.Vault, error) {
+	vaultMutex.RLock()
+	v := unlockedVault
+	vaultMutex.RUnlock()
+	if v != nil {
+		return v, nil
+	}
+
+	keyFile, err := vault.LoadKeyFile(cookieContants.FileToSaveVault)
+	if err != nil {
+		return nil, err
+	}
+	if keyFile == nil {
+		return nil, nil
+	}
+	return nil, ErrVaultLocked
+}
+
+// UnlockVault разблокировка хранилища мастер-паролем по сохраненному файлу ключа
+func UnlockVault(password string) error {
+	keyFile, err := vault.LoadKeyFile(cookieContants.FileToSaveVault)
+	if err != nil {
+		return err
+	}
+	if keyFile == nil {
+		return nil
+	}
+	v, err := vault.Unlock(password, keyFile.KdfSalt, keyFile.WrappedVaultKey)
+	if err != nil {
+		return err
+	}
+	SetVault(v)
+	return nil
+}
+
+// SealPayload сериализация и шифрование данных ключом хранилища
+// aad связанные данные записи (vault.ItemAAD)
+func SealPayload(v *vault.Vault, payload any, aad []byte) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return v.Seal(data, aad)
+}
+
+// OpenPayload расшифровка данных ключом хранилища текущей сессии
+// aad связанные данные записи (vault.ItemAAD)
+// данные, зашифрованные до привязки к записи, расшифровываются без связанных данных
+// и привязываются при следующем изменении записи, до этого их можно переставить между записями
+func OpenPayload(encryptedPayload []byte, aad []byte, payload any) error {
+	v, err := GetVault()
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		return ErrVaultLocked
+	}
+	data, err := v.Open(encryptedPayload, aad)
+	if err != nil {
+		var legacyErr error
+		if data, legacyErr = v.Open(encryptedPayload, nil); legacyErr != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(data, payload)
+}
@@ -6,9 +6,12 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/ramil063/secondgodiplom/cmd/client/handlers/items"
+	itemsConstants "github.com/ramil063/secondgodiplom/internal/constants/items"
 	"github.com/ramil063/secondgodiplom/internal/constants/queue"
 	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/bankcard"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/vault"
 )
 
 // Init инициализация всего необходимого для очереди в частности создаем директорию
@@ -91,22 +94,20 @@ func sendCreate(client bankcard.ServiceClient, filename string) {
 		return
 	}
 
+	// Формируем запрос, при включенном сквозном шифровании данные шифруются на клиенте
+	createRequest, err := newCreateRequest(client, request)
+	if err != nil {
+		fmt.Printf("❌ Запрос %v отложен: %v\n", request.GeneratedID, err)
+		return
+	}
+
 	// Помечаем как обрабатывается
 	request.Status = queue.RequestStatusProcessing
 	saveRequest(filename, request)
 
 	// Отправляем на сервер
 	ctx := items.CreateAuthContext()
-	resp, err := client.CreateCardData(ctx, &bankcard.CreateCardDataRequest{
-		Number:          request.Number,
-		ValidUntilYear:  request.ValidUntilYear,
-		ValidUntilMonth: request.ValidUntilMonth,
-		Cvv:             request.Cvv,
-		Holder:          request.Holder,
-		Description:     request.Description,
-		MetaDataName:    request.MetaDataName,
-		MetaDataValue:   request.MetaDataValue,
-	})
+	resp, err := client.CreateCardData(ctx, createRequest)
 
 	if err != nil {
 		fmt.Printf("❌ Ошибка отправки %s: %v\n", request.GeneratedID, err)
@@ -141,21 +142,20 @@ func sendUpdate(client bankcard.ServiceClient, filename string) {
 		return
 	}
 
+	// Формируем запрос, при включенном сквозном шифровании данные шифруются на клиенте
+	updateRequest, err := newUpdateRequest(request)
+	if err != nil {
+		fmt.Printf("❌ Запрос %v отложен: %v\n", request.ID, err)
+		return
+	}
+
 	// Помечаем как обрабатывается
 	request.Status = queue.RequestStatusProcessing
 	saveRequest(filename, request)
 
 	// Отправляем на сервер
 	ctx := items.CreateAuthContext()
-	resp, err := client.UpdateCardData(ctx, &bankcard.UpdateCardDataRequest{
-		Id:              request.ID,
-		Number:          request.Number,
-		ValidUntilYear:  request.ValidUntilYear,
-		ValidUntilMonth: request.ValidUntilMonth,
-		Cvv:             request.Cvv,
-		Holder:          request.Holder,
-		Description:     request.Description,
-	})
+	resp, err := client.UpdateCardData(ctx, updateRequest)
 
 	if err != nil {
 		fmt.Printf("❌ Ошибка отправки %d: %v\n", request.ID, err)
@@ -223,3 +223,87 @@ func saveRequest(filename string, request Request) {
 	data, _ := json.MarshalIndent(request, "", "  ")
 	os.WriteFile(filename, data, 0644)
 }
+
+// newCreateRequest формирование запроса на создание данных банковской карты
+// при включенном сквозном шифровании чувствительные данные передаются только в зашифрованном виде
+func newCreateRequest(client bankcard.ServiceClient, request Request) (*bankcard.CreateCardDataRequest, error) {
+	req := &bankcard.CreateCardDataRequest{
+		Number:          request.Number,
+		ValidUntilYear:  request.ValidUntilYear,
+		ValidUntilMonth: request.ValidUntilMonth,
+		Cvv:             request.Cvv,
+		Holder:          request.Holder,
+		Description:     request.Description,
+		MetaDataName:    request.MetaDataName,
+		MetaDataValue:   request.MetaDataValue,
+	}
+
+	v, err := items.GetVault()
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return req, nil
+	}
+
+	// Идентификатор резервируется заранее, так как шифротекст привязывается к записи
+	reserved, err := client.ReserveCardDataID(items.CreateAuthContext(), &empty.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	req.Id = reserved.Id
+	req.EncryptedPayload, err = items.SealPayload(v, items.BankCardPayload{
+		Number:          request.Number,
+		ValidUntilYear:  request.ValidUntilYear,
+		ValidUntilMonth: request.ValidUntilMonth,
+		Cvv:             request.Cvv,
+		Holder:          request.Holder,
+	}, vault.ItemAAD(req.Id, itemsConstants.TypeCard))
+	if err != nil {
+		return nil, err
+	}
+	req.Number = ""
+	req.ValidUntilYear = 0
+	req.ValidUntilMonth = 0
+	req.Cvv = 0
+	req.Holder = ""
+	return req, nil
+}
+
+// newUpdateRequest формирование запроса на обновление данных банковской карты
+// при включенном сквозном шифровании чувствительные данные передаются только в зашифрованном виде
+func newUpdateRequest(request Request) (*bankcard.UpdateCardDataRequest, error) {
+	req := &bankcard.UpdateCardDataRequest{
+		Id:              request.ID,
+		Number:          request.Number,
+		ValidUntilYear:  request.ValidUntilYear,
+		ValidUntilMonth: request.ValidUntilMonth,
+		Cvv:             request.Cvv,
+		Holder:          request.Holder,
+		Description:     request.Description,
+	}
+
+	v, err := items.GetVault()
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return req, nil
+	}
+	req.EncryptedPayload, err = items.SealPayload(v, items.BankCardPayload{
+		Number:          request.Number,
+		ValidUntilYear:  request.ValidUntilYear,
+		ValidUntilMonth: request.ValidUntilMonth,
+		Cvv:             request.Cvv,
+		Holder:          request.Holder,
+	}, vault.ItemAAD(req.Id, itemsConstants.TypeCard))
+	if err != nil {
+		return nil, err
+	}
+	req.Number = ""
+	req.ValidUntilYear = 0
+	req.ValidUntilMonth = 0
+	req.Cvv = 0
+	req.Holder = ""
+	return req, nil
+}
@@ -7,9 +7,12 @@ import (
 	"path/filepath"
 	"strconv"
 
+	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/ramil063/secondgodiplom/cmd/client/handlers/items"
+	itemsConstants "github.com/ramil063/secondgodiplom/internal/constants/items"
 	"github.com/ramil063/secondgodiplom/internal/constants/queue"
 	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/password"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/vault"
 )
 
 // Init инициализация всего необходимого для очереди в частности создаем директорию
@@ -92,20 +95,20 @@ func sendCreate(client password.ServiceClient, filename string) {
 		return
 	}
 
+	// Формируем запрос, при включенном сквозном шифровании данные шифруются на клиенте
+	createRequest, err := newCreateRequest(client, request)
+	if err != nil {
+		fmt.Printf("❌ Запрос %v отложен: %v\n", request.ID, err)
+		return
+	}
+
 	// Помечаем как обрабатывается
 	request.Status = queue.RequestStatusProcessing
 	saveRequest(filename, request)
 
 	// Отправляем на сервер
 	ctx := items.CreateAuthContext()
-	resp, err := client.CreatePassword(ctx, &password.CreatePasswordRequest{
-		Login:         request.Login,
-		Password:      request.Password,
-		Target:        request.Target,
-		Description:   request.Description,
-		MetaDataName:  request.MetaDataName,
-		MetaDataValue: request.MetaDataValue,
-	})
+	resp, err := client.CreatePassword(ctx, createRequest)
 
 	if err != nil {
 		fmt.Printf("❌ Ошибка отправки %s: %v\n", request.ID, err)
@@ -140,20 +143,20 @@ func sendUpdate(client password.ServiceClient, filename string) {
 		return
 	}
 
+	// Формируем запрос, при включенном сквозном шифровании данные шифруются на клиенте
+	updateRequest, err := newUpdateRequest(request)
+	if err != nil {
+		fmt.Printf("❌ Запрос %v отложен: %v\n", request.ID, err)
+		return
+	}
+
 	// Помечаем как обрабатывается
 	request.Status = queue.RequestStatusProcessing
 	saveRequest(filename, request)
 
 	// Отправляем на сервер
 	ctx := items.CreateAuthContext()
-	intID, err := strconv.Atoi(request.ID)
-	resp, err := client.UpdatePassword(ctx, &password.UpdatePasswordRequest{
-		Id:          int64(intID),
-		Login:       request.Login,
-		Password:    request.Password,
-		Target:      request.Target,
-		Description: request.Description,
-	})
+	resp, err := client.UpdatePassword(ctx, updateRequest)
 
 	if err != nil {
 		fmt.Printf("❌ Ошибка отправки %s: %v\n", request.ID, err)
@@ -222,3 +225,79 @@ func saveRequest(filename string, request Request) {
 	data, _ := json.MarshalIndent(request, "", "  ")
 	os.WriteFile(filename, data, 0644)
 }
+
+// newCreateRequest формирование запроса на создание пароля
+// при включенном сквозном шифровании чувствительные данные передаются только в зашифрованном виде
+func newCreateRequest(client password.ServiceClient, request Request) (*password.CreatePasswordRequest, error) {
+	req := &password.CreatePasswordRequest{
+		Login:         request.Login,
+		Password:      request.Password,
+		Target:        request.Target,
+		Description:   request.Description,
+		MetaDataName:  request.MetaDataName,
+		MetaDataValue: request.MetaDataValue,
+	}
+
+	v, err := items.GetVault()
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return req, nil
+	}
+
+	// Идентификатор резервируется заранее, так как шифротекст привязывается к записи
+	reserved, err := client.ReservePasswordID(items.CreateAuthContext(), &empty.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	req.Id = reserved.Id
+	req.EncryptedPayload, err = items.SealPayload(v, items.PasswordPayload{
+		Login:    request.Login,
+		Password: request.Password,
+		Target:   request.Target,
+	}, vault.ItemAAD(req.Id, itemsConstants.TypePasswords))
+	if err != nil {
+		return nil, err
+	}
+	req.Login = ""
+	req.Password = ""
+	req.Target = ""
+	return req, nil
+}
+
+// newUpdateRequest формирование запроса на обновление пароля
+// при включенном сквозном шифровании чувствительные данные передаются только в зашифрованном виде
+func newUpdateRequest(request Request) (*password.UpdatePasswordRequest, error) {
+	intID, err := strconv.Atoi(request.ID)
+	if err != nil {
+		return nil, err
+	}
+	req := &password.UpdatePasswordRequest{
+		Id:          int64(intID),
+		Login:       request.Login,
+		Password:    request.Password,
+		Target:      request.Target,
+		Description: request.Description,
+	}
+
+	v, err := items.GetVault()
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return req, nil
+	}
+	req.EncryptedPayload, err = items.SealPayload(v, items.PasswordPayload{
+		Login:    request.Login,
+		Password: request.Password,
+		Target:   request.Target,
+	}, vault.ItemAAD(req.Id, itemsConstants.TypePasswords))
+	if err != nil {
+		return nil, err
+	}
+	req.Login = ""
+	req.Password = ""
+	req.Target = ""
+	return req, nil
+}
@@ -7,9 +7,12 @@ import (
 	"path/filepath"
 	"strconv"
 
+	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/ramil063/secondgodiplom/cmd/client/handlers/items"
+	itemsConstants "github.com/ramil063/secondgodiplom/internal/constants/items"
 	"github.com/ramil063/secondgodiplom/internal/constants/queue"
 	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/textdata"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/vault"
 )
 
 // Init инициализация всего необходимого для очереди в частности создаем директорию
@@ -92,18 +95,20 @@ func sendCreate(client textdata.ServiceClient, filename string) {
 		return
 	}
 
+	// Формируем запрос, при включенном сквозном шифровании данные шифруются на клиенте
+	createRequest, err := newCreateRequest(client, request)
+	if err != nil {
+		fmt.Printf("❌ Запрос %v отложен: %v\n", request.ID, err)
+		return
+	}
+
 	// Помечаем как обрабатывается
 	request.Status = queue.RequestStatusProcessing
 	saveRequest(filename, request)
 
 	// Отправляем на сервер
 	ctx := items.CreateAuthContext()
-	resp, err := client.CreateTextData(ctx, &textdata.CreateTextDataRequest{
-		TextData:      request.TextData,
-		Description:   request.Description,
-		MetaDataName:  request.MetaDataName,
-		MetaDataValue: request.MetaDataValue,
-	})
+	resp, err := client.CreateTextData(ctx, createRequest)
 
 	if err != nil {
 		fmt.Printf("❌ Ошибка отправки %s: %v\n", request.ID, err)
@@ -138,20 +143,20 @@ func sendUpdate(client textdata.ServiceClient, filename string) {
 		return
 	}
 
+	// Формируем запрос, при включенном сквозном шифровании данные шифруются на клиенте
+	updateRequest, err := newUpdateRequest(request)
+	if err != nil {
+		fmt.Printf("❌ Запрос %v отложен: %v\n", request.ID, err)
+		return
+	}
+
 	// Помечаем как обрабатывается
 	request.Status = queue.RequestStatusProcessing
 	saveRequest(filename, request)
 
 	// Отправляем на сервер
 	ctx := items.CreateAuthContext()
-	intID, err := strconv.Atoi(request.ID)
-	resp, err := client.UpdateTextData(ctx, &textdata.UpdateTextDataRequest{
-		Id:            int64(intID),
-		TextData:      request.TextData,
-		Description:   request.Description,
-		MetaDataName:  request.MetaDataName,
-		MetaDataValue: request.MetaDataValue,
-	})
+	resp, err := client.UpdateTextData(ctx, updateRequest)
 
 	if err != nil {
 		fmt.Printf("❌ Ошибка отправки %s: %v\n", request.ID, err)
@@ -220,3 +225,69 @@ func saveRequest(filename string, request Request) {
 	data, _ := json.MarshalIndent(request, "", "  ")
 	os.WriteFile(filename, data, 0644)
 }
+
+// newCreateRequest формирование запроса на создание текстовых данных
+// при включенном сквозном шифровании чувствительные данные передаются только в зашифрованном виде
+func newCreateRequest(client textdata.ServiceClient, request Request) (*textdata.CreateTextDataRequest, error) {
+	req := &textdata.CreateTextDataRequest{
+		TextData:      request.TextData,
+		Description:   request.Description,
+		MetaDataName:  request.MetaDataName,
+		MetaDataValue: request.MetaDataValue,
+	}
+
+	v, err := items.GetVault()
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return req, nil
+	}
+
+	// Идентификатор резервируется заранее, так как шифротекст привязывается к записи
+	reserved, err := client.ReserveTextDataID(items.CreateAuthContext(), &empty.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	req.Id = reserved.Id
+	req.EncryptedPayload, err = items.SealPayload(v, items.TextDataPayload{
+		TextData: request.TextData,
+	}, vault.ItemAAD(req.Id, itemsConstants.TypeText))
+	if err != nil {
+		return nil, err
+	}
+	req.TextData = ""
+	return req, nil
+}
+
+// newUpdateRequest формирование запроса на обновление текстовых данных
+// при включенном сквозном шифровании чувствительные данные передаются только в зашифрованном виде
+func newUpdateRequest(request Request) (*textdata.UpdateTextDataRequest, error) {
+	intID, err := strconv.Atoi(request.ID)
+	if err != nil {
+		return nil, err
+	}
+	req := &textdata.UpdateTextDataRequest{
+		Id:            int64(intID),
+		TextData:      request.TextData,
+		Description:   request.Description,
+		MetaDataName:  request.MetaDataName,
+		MetaDataValue: request.MetaDataValue,
+	}
+
+	v, err := items.GetVault()
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return req, nil
+	}
+	req.EncryptedPayload, err = items.SealPayload(v, items.TextDataPayload{
+		TextData: request.TextData,
+	}, vault.ItemAAD(req.Id, itemsConstants.TypeText))
+	if err != nil {
+		return nil, err
+	}
+	req.TextData = ""
+	return req, nil
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/ramil063/secondgodiplom/cmd/client/handlers/dialog/registration"
 	"github.com/ramil063/secondgodiplom/cmd/client/handlers/grpc"
 	"github.com/ramil063/secondgodiplom/cmd/client/handlers/queue"
+	accountService "github.com/ramil063/secondgodiplom/cmd/client/services/account"
 	authService "github.com/ramil063/secondgodiplom/cmd/client/services/auth"
 	bankcardService "github.com/ramil063/secondgodiplom/cmd/client/services/items/bankcard"
 	binarydataService "github.com/ramil063/secondgodiplom/cmd/client/services/items/binarydata"
@@ -50,6 +51,7 @@ func main() {
 	defer queueSender.Stop()
 
 	authServ := authService.NewService(clients.AuthClient)
+	accountServ := accountService.NewService(clients.AccountClient)
 	regService := registrationService.NewService(clients.RegistrationClient)
 	bcServ := bankcardService.NewService(clients.BankCardDataClient)
 	bServ := binarydataService.NewService(clients.BinaryDataClient)
@@ -76,10 +78,15 @@ func main() {
 		case dialog.StateRegistration:
 			nextState = registration.Registration(regService)
 		case dialog.StateLogin:
-			nextState, newSession = auth.Login(authServ)
+			nextState, newSession = auth.Login(authServ, accountServ)
 			session = newSession
+		case dialog.StateLogout:
+			nextState, session = auth.Logout(authServ)
+		case dialog.StateAccountDeleted:
+			// токены удаленного аккаунта больше не действуют
+			nextState, session = dialog.StateMainMenu, dialog.UserSession{}
 		case dialog.StateUserProfile:
-			nextState = profile.UserProfile(session, bcServ, bServ, passwordServ, textdataServ)
+			nextState = profile.UserProfile(session, authServ, accountServ, bcServ, bServ, passwordServ, textdataServ)
 		default:
 			nextState = dialog.StateMainMenu
 		}
@@ -0,0 +1,97 @@
+package account
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ramil063/secondgodiplom/cmd/client/handlers/items"
+	cookieContants "github.com/ramil063/secondgodiplom/internal/constants/cookie"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/account"
+	"github.com/ramil063/secondgodiplom/internal/security/cookie"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/vault"
+)
+
+// Servicer интерфейс описывающий методы управления учетной записью
+type Servicer interface {
+	ChangePasswordProcess(oldPassword, newPassword string) (int64, error)
+	DeleteAccountProcess(password string, schedule bool) (string, error)
+	RestoreAccountProcess(login, password string) error
+}
+
+// Service сервис по работе с учетной записью
+type Service struct {
+	client account.AccountServiceClient
+}
+
+// NewService инициализация сервиса по работе с учетной записью
+// в сервисе находится gRPC клиент для отправки данных на сервер
+func NewService(client account.AccountServiceClient) *Service {
+	return &Service{
+		client: client,
+	}
+}
+
+// ChangePasswordProcess смена пароля, возвращает число завершенных сессий на других устройствах
+// при сквозном шифровании ключ хранилища обертывается ключом из нового пароля
+func (s *Service) ChangePasswordProcess(oldPassword, newPassword string) (int64, error) {
+	req := &account.ChangePasswordRequest{
+		OldPassword: oldPassword,
+		NewPassword: newPassword,
+	}
+
+	keyFile, err := vault.LoadKeyFile(cookieContants.FileToSaveVault)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load vault key: %w", err)
+	}
+	if keyFile != nil {
+		req.KdfSalt, req.WrappedVaultKey, err = vault.Rewrap(oldPassword, newPassword, keyFile.KdfSalt, keyFile.WrappedVaultKey)
+		if err != nil {
+			return 0, fmt.Errorf("failed to rewrap vault key: %w", err)
+		}
+	}
+
+	resp, err := s.client.ChangePassword(items.CreateAuthContext(), req)
+	if err != nil {
+		return 0, err
+	}
+
+	if keyFile != nil {
+		err = vault.SaveKeyFile(cookieContants.FileToSaveVault, req.KdfSalt, req.WrappedVaultKey)
+		if err != nil {
+			return resp.RevokedSessions, fmt.Errorf("failed to save vault key: %w", err)
+		}
+	}
+	return resp.RevokedSessions, nil
+}
+
+// DeleteAccountProcess удаление учетной записи, возвращает дату окончательного удаления при отложенном удалении
+// сохраненные токены удаляются, при немедленном удалении удаляется и файл ключа хранилища
+func (s *Service) DeleteAccountProcess(password string, schedule bool) (string, error) {
+	resp, err := s.client.DeleteAccount(items.CreateAuthContext(), &account.DeleteAccountRequest{
+		Password: password,
+		Schedule: schedule,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	items.SetVault(nil)
+	if err = cookie.RemoveTokens(cookieContants.FileToSaveCookie); err != nil {
+		return resp.PurgeAt, fmt.Errorf("failed to remove tokens: %w", err)
+	}
+	if !resp.Scheduled {
+		if err = vault.RemoveKeyFile(cookieContants.FileToSaveVault); err != nil {
+			return "", fmt.Errorf("failed to remove vault key: %w", err)
+		}
+	}
+	return resp.PurgeAt, nil
+}
+
+// RestoreAccountProcess отмена отложенного удаления учетной записи, после нее можно войти как обычно
+func (s *Service) RestoreAccountProcess(login, password string) error {
+	_, err := s.client.RestoreAccount(context.Background(), &account.RestoreAccountRequest{
+		Login:    login,
+		Password: password,
+	})
+	return err
+}
@@ -0,0 +1,2 @@
+// Package account в этом пакете собраны функции для управления учетной записью пользователя
+package account
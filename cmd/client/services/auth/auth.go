@@ -5,15 +5,34 @@ import (
 	"fmt"
 
 	"github.com/ramil063/secondgodiplom/cmd/client/handlers/dialog"
+	"github.com/ramil063/secondgodiplom/cmd/client/handlers/items"
 	cookieContants "github.com/ramil063/secondgodiplom/internal/constants/cookie"
 	"github.com/ramil063/secondgodiplom/internal/proto/gen/auth"
 	"github.com/ramil063/secondgodiplom/internal/security/cookie"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/vault"
 )
 
 // Servicer интерфейс для работы с авторизацией
 type Servicer interface {
 	LoginProcess(login, password string) (dialog.UserSession, error)
 	RefreshProcess() error
+	EnableClientEncryption(password string) error
+	LogoutProcess(allDevices bool) (int64, error)
+	GetSessions() ([]*auth.Session, error)
+	RevokeSession(id int64) error
+	VerifyTOTPProcess(challenge, code, password string) (dialog.UserSession, error)
+	EnrollTOTP() (*auth.EnrollTOTPResponse, error)
+	ConfirmTOTP(code string) error
+}
+
+// TOTPRequiredError для входа нужен код второго фактора
+// Challenge передается в VerifyTOTPProcess вместе с кодом
+type TOTPRequiredError struct {
+	Challenge string
+}
+
+func (e *TOTPRequiredError) Error() string {
+	return "second factor required"
 }
 
 // Service сервис по работе с аторизацией
@@ -31,6 +50,7 @@ func NewService(client auth.AuthServiceClient) *Service {
 
 // LoginProcess функция для авторизации и сохранения токенов авторизации
 // токены сохраняются в специальный файл
+// если подключен второй фактор, возвращается TOTPRequiredError
 func (s *Service) LoginProcess(login, password string) (dialog.UserSession, error) {
 	resp, err := s.client.Login(context.Background(), &auth.LoginRequest{
 		Login:    login,
@@ -41,13 +61,42 @@ func (s *Service) LoginProcess(login, password string) (dialog.UserSession, erro
 		return dialog.UserSession{}, err
 	}
 
+	if resp.TotpRequired {
+		return dialog.UserSession{}, &TOTPRequiredError{Challenge: resp.Challenge}
+	}
+
+	return completeLogin(resp, password)
+}
+
+// VerifyTOTPProcess завершение входа кодом второго фактора или кодом восстановления
+// пароль нужен для разблокировки хранилища при сквозном шифровании
+func (s *Service) VerifyTOTPProcess(challenge, code, password string) (dialog.UserSession, error) {
+	resp, err := s.client.VerifyTOTP(context.Background(), &auth.VerifyTOTPRequest{
+		Challenge: challenge,
+		Code:      code,
+	})
+	if err != nil {
+		return dialog.UserSession{}, err
+	}
+	return completeLogin(resp, password)
+}
+
+// completeLogin сохранение выданных токенов и разблокировка хранилища
+func completeLogin(resp *auth.LoginResponse, password string) (dialog.UserSession, error) {
 	// Сохраняем токены (например, в файл)
-	err = cookie.SaveTokens(resp.AccessToken, resp.RefreshToken, cookieContants.FileToSaveCookie, resp.ExpiresIn)
+	err := cookie.SaveTokens(resp.AccessToken, resp.RefreshToken, cookieContants.FileToSaveCookie, resp.ExpiresIn)
 	if err != nil {
 		fmt.Println("❌ Ошибка сохранения токенов:", err)
 		return dialog.UserSession{}, err
 	}
 
+	// Разблокируем хранилище для сквозного шифрования
+	err = unlockVault(password, resp.KdfSalt, resp.WrappedVaultKey)
+	if err != nil {
+		fmt.Println("❌ Ошибка разблокировки хранилища:", err)
+		return dialog.UserSession{}, err
+	}
+
 	return dialog.UserSession{
 		AccessToken:  resp.AccessToken,
 		RefreshToken: resp.RefreshToken,
@@ -55,6 +104,17 @@ func (s *Service) LoginProcess(login, password string) (dialog.UserSession, erro
 	}, nil
 }
 
+// EnrollTOTP начало подключения второго фактора: секрет и коды восстановления
+func (s *Service) EnrollTOTP() (*auth.EnrollTOTPResponse, error) {
+	return s.client.EnrollTOTP(items.CreateAuthContext(), &auth.EnrollTOTPRequest{})
+}
+
+// ConfirmTOTP подтверждение подключения второго фактора кодом из приложения
+func (s *Service) ConfirmTOTP(code string) error {
+	_, err := s.client.ConfirmTOTP(items.CreateAuthContext(), &auth.ConfirmTOTPRequest{Code: code})
+	return err
+}
+
 // RefreshProcess функция для обновления токенов при истечении срока жизни токена авторизации
 func (s *Service) RefreshProcess() error {
 	_, refreshToken, _, err := cookie.LoadTokens(cookieContants.FileToSaveCookie)
@@ -80,3 +140,93 @@ func (s *Service) RefreshProcess() error {
 	fmt.Println("Обновление токенов авторизации прошло успешно. Токены сохранены.")
 	return nil
 }
+
+// LogoutProcess выход: отзыв токенов на сервере и удаление сохраненных токенов
+// при allDevices отзываются сессии на всех устройствах, возвращается число отозванных сессий
+// локальные токены удаляются, даже если сервер недоступен
+func (s *Service) LogoutProcess(allDevices bool) (int64, error) {
+	if _, _, _, err := cookie.LoadTokens(cookieContants.FileToSaveCookie); err != nil {
+		items.SetVault(nil)
+		return 0, nil
+	}
+
+	var revoked int64
+	var err error
+	if allDevices {
+		var resp *auth.LogoutAllResponse
+		resp, err = s.client.LogoutAll(items.CreateAuthContext(), &auth.LogoutAllRequest{})
+		if err == nil {
+			revoked = resp.RevokedSessions
+		}
+	} else {
+		_, err = s.client.Logout(items.CreateAuthContext(), &auth.LogoutRequest{})
+		if err == nil {
+			revoked = 1
+		}
+	}
+
+	items.SetVault(nil)
+	if removeErr := cookie.RemoveTokens(cookieContants.FileToSaveCookie); removeErr != nil {
+		return revoked, removeErr
+	}
+	return revoked, err
+}
+
+// GetSessions список активных сессий пользователя на устройствах
+func (s *Service) GetSessions() ([]*auth.Session, error) {
+	resp, err := s.client.ListSessions(items.CreateAuthContext(), &auth.ListSessionsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Sessions, nil
+}
+
+// RevokeSession завершение сессии на другом устройстве
+func (s *Service) RevokeSession(id int64) error {
+	_, err := s.client.RevokeSession(items.CreateAuthContext(), &auth.RevokeSessionRequest{Id: id})
+	return err
+}
+
+// EnableClientEncryption включение сквозного шифрования для пользователя
+// ключ хранилища создается на клиенте, на сервер передается только в обернутом мастер-паролем виде
+func (s *Service) EnableClientEncryption(password string) error {
+	v, kdfSalt, wrappedVaultKey, err := vault.Generate(password)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.EnableClientEncryption(items.CreateAuthContext(), &auth.EnableClientEncryptionRequest{
+		KdfSalt:         kdfSalt,
+		WrappedVaultKey: wrappedVaultKey,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = vault.SaveKeyFile(cookieContants.FileToSaveVault, kdfSalt, wrappedVaultKey)
+	if err != nil {
+		return err
+	}
+	items.SetVault(v)
+	return nil
+}
+
+// unlockVault разблокировка хранилища мастер-паролем и сохранение параметров ключа в файл
+// если у пользователя не включено сквозное шифрование, файл ключа удаляется
+func unlockVault(password string, kdfSalt, wrappedVaultKey []byte) error {
+	if len(wrappedVaultKey) == 0 {
+		items.SetVault(nil)
+		return vault.RemoveKeyFile(cookieContants.FileToSaveVault)
+	}
+
+	v, err := vault.Unlock(password, kdfSalt, wrappedVaultKey)
+	if err != nil {
+		return err
+	}
+	err = vault.SaveKeyFile(cookieContants.FileToSaveVault, kdfSalt, wrappedVaultKey)
+	if err != nil {
+		return err
+	}
+	items.SetVault(v)
+	return nil
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 
 	"github.com/ramil063/secondgodiplom/cmd/client/generics/list"
+	"github.com/ramil063/secondgodiplom/cmd/client/handlers/items"
+	itemsConstants "github.com/ramil063/secondgodiplom/internal/constants/items"
 	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/bankcard"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/vault"
 )
 
 // Servicer интерфейс по работе с данными банковских карт
@@ -34,6 +37,9 @@ func (s *Service) GetCardData(ctx context.Context, id int64) (bankcard.CardDataI
 	if err != nil {
 		return bankcard.CardDataItem{}, err
 	}
+	if err = openCardDataItem(resp); err != nil {
+		return bankcard.CardDataItem{}, err
+	}
 	return bankcard.CardDataItem{
 		Id:              resp.Id,
 		Number:          resp.Number,
@@ -56,6 +62,11 @@ func (s *Service) ListItems(ctx context.Context, page int32, filter string) (*li
 	if err != nil {
 		return nil, err
 	}
+	for _, item := range resp.Cards {
+		if err = openCardDataItem(item); err != nil {
+			return nil, err
+		}
+	}
 
 	return &list.Response[bankcard.CardDataItem]{
 		Items:       resp.Cards,
@@ -64,3 +75,20 @@ func (s *Service) ListItems(ctx context.Context, page int32, filter string) (*li
 		CurrentPage: resp.CurrentPage,
 	}, nil
 }
+
+// openCardDataItem расшифровка данных банковской карты, зашифрованных на клиенте
+func openCardDataItem(item *bankcard.CardDataItem) error {
+	if len(item.EncryptedPayload) == 0 {
+		return nil
+	}
+	var payload items.BankCardPayload
+	if err := items.OpenPayload(item.EncryptedPayload, vault.ItemAAD(item.Id, itemsConstants.TypeCard), &payload); err != nil {
+		return err
+	}
+	item.Number = payload.Number
+	item.ValidUntilYear = payload.ValidUntilYear
+	item.ValidUntilMonth = payload.ValidUntilMonth
+	item.Cvv = payload.Cvv
+	item.Holder = payload.Holder
+	return nil
+}
@@ -8,7 +8,9 @@ import (
 	"path/filepath"
 	"sync"
 
+	"github.com/ramil063/secondgodiplom/cmd/client/handlers/items"
 	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/binarydata"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/vault"
 )
 
 var writeMutex sync.Mutex
@@ -33,6 +35,18 @@ func (s *Service) DownloadData(ctx context.Context, fileID int64, downloadDir st
 		return "", fmt.Errorf("❌ Возникла ошибка(метаданные должны быть отправлены первыми)")
 	}
 
+	// Части файла, зашифрованные на клиенте, расшифровываются ключом хранилища
+	var v *vault.Vault
+	if metadata.ClientEncrypted {
+		v, err = items.GetVault()
+		if err != nil {
+			return "", fmt.Errorf("❌ Возникла ошибка: %w", err)
+		}
+		if v == nil {
+			return "", fmt.Errorf("❌ Возникла ошибка: %w", items.ErrVaultLocked)
+		}
+	}
+
 	// 5. Создаем файл для записи
 	filePath := filepath.Join(downloadDir, metadata.Filename)
 	file, err := os.Create(filePath)
@@ -49,43 +63,82 @@ func (s *Service) DownloadData(ctx context.Context, fileID int64, downloadDir st
 	// Запускаем workers для записи
 	for i := 0; i < numberOfWorkers; i++ {
 		wg.Add(1)
-		go writeChunkWorker(file, chunks, errors, &wg)
+		go writeChunkWorker(file, v, fileID, metadata.TotalChunks, chunks, errors, &wg)
 	}
 
 	// 7. Получаем и обрабатываем чанки
-	var receivedBytes int64
+	// каждый чанк должен прийти ровно один раз, иначе файл поврежден
+	received := make(map[int32]bool, metadata.TotalChunks)
+	var streamErr error
 	for {
 		response, err := stream.Recv()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			close(chunks)
-			fmt.Println("Возникла ошибка: %w", err)
+			streamErr = err
+			break
 		}
 
 		if chunk := response.GetChunk(); chunk != nil {
+			if chunk.ChunkIndex < 0 || chunk.ChunkIndex >= metadata.TotalChunks || received[chunk.ChunkIndex] {
+				streamErr = fmt.Errorf("файл поврежден: неожиданная часть %d", chunk.ChunkIndex)
+				break
+			}
+			received[chunk.ChunkIndex] = true
 			chunks <- chunk
-			receivedBytes += int64(len(chunk.Data))
 		}
 	}
 
 	close(chunks)
 	wg.Wait()
 
+	if streamErr == nil && int32(len(received)) != metadata.TotalChunks {
+		streamErr = fmt.Errorf("файл поврежден: получено частей %d из %d", len(received), metadata.TotalChunks)
+	}
 	// Проверяем ошибки
-	select {
-	case err = <-errors:
-		return "", fmt.Errorf("❌ Возникла ошибка: %w", err)
-	default:
+	if streamErr == nil {
+		select {
+		case streamErr = <-errors:
+		default:
+		}
+	}
+	if streamErr != nil {
+		// Недокачанный или поврежденный файл не оставляем
+		file.Close()
+		_ = os.Remove(filePath)
+		return "", fmt.Errorf("❌ Возникла ошибка: %w", streamErr)
 	}
 	return filePath, nil
 }
 
-func writeChunkWorker(file *os.File, chunks <-chan *binarydata.FileChunk, errors chan<- error, wg *sync.WaitGroup) {
+func writeChunkWorker(
+	file *os.File,
+	v *vault.Vault,
+	fileID int64,
+	totalChunks int32,
+	chunks <-chan *binarydata.FileChunk,
+	errors chan<- error,
+	wg *sync.WaitGroup,
+) {
 	defer wg.Done()
 
+	failed := false
 	for chunk := range chunks {
+		// После ошибки канал дочитывается, чтобы не блокировать прием частей
+		if failed {
+			continue
+		}
+		if v != nil {
+			data, err := openChunk(v, fileID, totalChunks, chunk)
+			if err != nil {
+				reportError(errors, fmt.Errorf("\n Возникла ошибка расшифровки %d: %w", chunk.ChunkIndex, err))
+				failed = true
+				continue
+			}
+			chunk.Data = data
+		}
+
 		// Используем mutex для thread-safe записи в файл
 		err := func() error {
 			writeMutex.Lock()
@@ -103,8 +156,32 @@ func writeChunkWorker(file *os.File, chunks <-chan *binarydata.FileChunk, errors
 		}()
 
 		if err != nil {
-			errors <- fmt.Errorf("\n Возникла ошибка %d: %w", chunk.ChunkIndex, err)
-			return
+			reportError(errors, fmt.Errorf("\n Возникла ошибка %d: %w", chunk.ChunkIndex, err))
+			failed = true
+		}
+	}
+}
+
+// reportError передача ошибки worker без ожидания
+// в канал попадает первая ошибка, остальные отбрасываются, иначе workers блокировались бы на заполненном канале
+func reportError(errors chan<- error, err error) {
+	select {
+	case errors <- err:
+	default:
+	}
+}
+
+// openChunk расшифровка части файла, зашифрованной на клиенте
+// часть привязана к файлу, своему номеру и признаку последней части (vault.ChunkAAD),
+// части, зашифрованные до привязки, расшифровываются без связанных данных
+func openChunk(v *vault.Vault, fileID int64, totalChunks int32, chunk *binarydata.FileChunk) ([]byte, error) {
+	data, err := v.Open(chunk.Data, vault.ChunkAAD(fileID, chunk.ChunkIndex, chunk.ChunkIndex == totalChunks-1))
+	if err != nil {
+		legacyData, legacyErr := v.Open(chunk.Data, nil)
+		if legacyErr != nil {
+			return nil, err
 		}
+		return legacyData, nil
 	}
+	return data, nil
 }
@@ -9,7 +9,10 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/ramil063/secondgodiplom/cmd/client/handlers/items"
 	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/binarydata"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/vault"
 )
 
 // UploadData загрузка файла на сервер
@@ -21,6 +24,22 @@ func (s *Service) UploadData(
 	description string,
 ) (*binarydata.UploadFileResponse, int, error) {
 
+	// При включенном сквозном шифровании части файла шифруются ключом хранилища
+	v, err := items.GetVault()
+	if err != nil {
+		return nil, 0, fmt.Errorf("❌ Возникла ошибка: %s\n", err.Error())
+	}
+
+	// Идентификатор файла резервируется заранее, так как части привязываются к нему
+	var fileID int64
+	if v != nil {
+		reserved, err := s.client.ReserveFileID(ctx, &empty.Empty{})
+		if err != nil {
+			return nil, 0, fmt.Errorf("❌ Возникла ошибка: %s\n", err.Error())
+		}
+		fileID = reserved.FileId
+	}
+
 	stream, err := s.client.UploadFile(ctx)
 	if err != nil {
 		return nil, 0, fmt.Errorf("❌ Возникла ошибка: %s\n", err.Error())
@@ -32,12 +51,14 @@ func (s *Service) UploadData(
 	err = stream.Send(&binarydata.UploadFileRequest{
 		Data: &binarydata.UploadFileRequest_Metadata{
 			Metadata: &binarydata.FileMetadata{
-				Filename:     fileInfo.Name(),
-				MimeType:     getMimeType(filePath),
-				OriginalSize: fileInfo.Size(),
-				Description:  description,
-				ChunkSize:    int32(chunkSize),
-				TotalChunks:  int32(totalChunks),
+				Filename:        fileInfo.Name(),
+				MimeType:        getMimeType(filePath),
+				OriginalSize:    fileInfo.Size(),
+				Description:     description,
+				ChunkSize:       int32(chunkSize),
+				TotalChunks:     int32(totalChunks),
+				ClientEncrypted: v != nil,
+				FileId:          fileID,
 			},
 		},
 	})
@@ -64,10 +85,20 @@ func (s *Service) UploadData(
 			end = len(fileData)
 		}
 
+		chunkData := fileData[start:end]
+		if v != nil {
+			chunkData, err = v.Seal(chunkData, vault.ChunkAAD(fileID, int32(i), i == totalChunks-1))
+			if err != nil {
+				close(chunks)
+				wg.Wait()
+				return nil, 0, fmt.Errorf("❌ Возникла ошибка шифрования: %s\n", err.Error())
+			}
+		}
+
 		chunks <- &binarydata.UploadFileRequest{
 			Data: &binarydata.UploadFileRequest_Chunk{
 				Chunk: &binarydata.FileChunk{
-					Data:       fileData[start:end],
+					Data:       chunkData,
 					ChunkIndex: int32(i),
 					IsLast:     i == totalChunks-1,
 				},
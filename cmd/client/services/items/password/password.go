@@ -5,7 +5,10 @@ import (
 	"fmt"
 
 	"github.com/ramil063/secondgodiplom/cmd/client/generics/list"
+	"github.com/ramil063/secondgodiplom/cmd/client/handlers/items"
+	itemsConstants "github.com/ramil063/secondgodiplom/internal/constants/items"
 	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/password"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/vault"
 )
 
 // Servicer интерфейс по работе с данными паролей
@@ -36,6 +39,9 @@ func (s *Service) GetPassword(ctx context.Context, id int64) (*password.Password
 	if err != nil {
 		return nil, fmt.Errorf("❌ Ошибка получения данных\n")
 	}
+	if err = openPasswordItem(resp); err != nil {
+		return nil, fmt.Errorf("❌ Ошибка расшифровки данных: %v\n", err)
+	}
 	return resp, nil
 }
 
@@ -49,6 +55,11 @@ func (s *Service) ListItems(ctx context.Context, page int32, filter string) (*li
 	if err != nil {
 		return nil, fmt.Errorf("❌ Ошибка получения данных: %v\n", err)
 	}
+	for _, item := range resp.Passwords {
+		if err = openPasswordItem(item); err != nil {
+			return nil, fmt.Errorf("❌ Ошибка расшифровки данных: %v\n", err)
+		}
+	}
 
 	return &list.Response[password.PasswordItem]{
 		Items:       resp.Passwords,
@@ -57,3 +68,18 @@ func (s *Service) ListItems(ctx context.Context, page int32, filter string) (*li
 		CurrentPage: resp.CurrentPage,
 	}, nil
 }
+
+// openPasswordItem расшифровка данных пароля, зашифрованных на клиенте
+func openPasswordItem(item *password.PasswordItem) error {
+	if len(item.EncryptedPayload) == 0 {
+		return nil
+	}
+	var payload items.PasswordPayload
+	if err := items.OpenPayload(item.EncryptedPayload, vault.ItemAAD(item.Id, itemsConstants.TypePasswords), &payload); err != nil {
+		return err
+	}
+	item.Login = payload.Login
+	item.Password = payload.Password
+	item.Target = payload.Target
+	return nil
+}
@@ -5,7 +5,10 @@ import (
 	"fmt"
 
 	"github.com/ramil063/secondgodiplom/cmd/client/generics/list"
+	"github.com/ramil063/secondgodiplom/cmd/client/handlers/items"
+	itemsConstants "github.com/ramil063/secondgodiplom/internal/constants/items"
 	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/textdata"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/vault"
 )
 
 // Servicer интерфейс по работе с текстовыми данными
@@ -35,6 +38,9 @@ func (s *Service) GetTextData(ctx context.Context, id int64) (*textdata.TextData
 	if err != nil {
 		return nil, fmt.Errorf("❌ Ошибка получения данных\n")
 	}
+	if err = openTextDataItem(resp); err != nil {
+		return nil, fmt.Errorf("❌ Ошибка расшифровки данных: %v\n", err)
+	}
 	return resp, nil
 }
 
@@ -47,6 +53,11 @@ func (s *Service) ListItems(ctx context.Context, page int32, filter string) (*li
 	if err != nil {
 		return nil, fmt.Errorf("❌ Ошибка получения данных: %v\n", err)
 	}
+	for _, item := range resp.TextDataItems {
+		if err = openTextDataItem(item); err != nil {
+			return nil, fmt.Errorf("❌ Ошибка расшифровки данных: %v\n", err)
+		}
+	}
 	return &list.Response[textdata.TextDataItem]{
 		Items:       resp.TextDataItems,
 		TotalPages:  resp.TotalPages,
@@ -54,3 +65,16 @@ func (s *Service) ListItems(ctx context.Context, page int32, filter string) (*li
 		CurrentPage: resp.CurrentPage,
 	}, nil
 }
+
+// openTextDataItem расшифровка текстовых данных, зашифрованных на клиенте
+func openTextDataItem(item *textdata.TextDataItem) error {
+	if len(item.EncryptedPayload) == 0 {
+		return nil
+	}
+	var payload items.TextDataPayload
+	if err := items.OpenPayload(item.EncryptedPayload, vault.ItemAAD(item.Id, itemsConstants.TypeText), &payload); err != nil {
+		return err
+	}
+	item.TextData = payload.TextData
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/ramil063/secondgodiplom/internal/proto/gen/auth"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/vault"
 )
 
 // Servicer интерфейс описывающий методы работы с регистрацией пользователя
@@ -26,12 +27,20 @@ func NewService(client auth.RegistrationServiceClient) *Service {
 }
 
 // RegisterUser функция регистрации пользователя
+// ключ хранилища для сквозного шифрования создается на клиенте и передается только в обернутом виде
 func (s *Service) RegisterUser(login, password, firstName, lastName string) (*auth.RegisterResponse, error) {
+	_, kdfSalt, wrappedVaultKey, err := vault.Generate(password)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Ошибка создания ключа хранилища: %w\n", err)
+	}
+
 	resp, err := s.client.Register(context.Background(), &auth.RegisterRequest{
-		Login:     login,
-		Password:  password,
-		FirstName: firstName,
-		LastName:  lastName,
+		Login:           login,
+		Password:        password,
+		FirstName:       firstName,
+		LastName:        lastName,
+		KdfSalt:         kdfSalt,
+		WrappedVaultKey: wrappedVaultKey,
 	})
 
 	if err != nil {
@@ -9,24 +9,110 @@ import (
 
 	"github.com/caarlos0/env/v6"
 
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/account"
+	modelAuth "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	"github.com/ramil063/secondgodiplom/internal/hash"
 	"github.com/ramil063/secondgodiplom/internal/logger"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/keyprovider"
+	"github.com/ramil063/secondgodiplom/internal/security/jwt"
+	"github.com/ramil063/secondgodiplom/internal/security/passpolicy"
+)
+
+// Драйверы хранилища сервера
+const (
+	StorageDriverPostgres = "postgres" // PostgreSQL, database_uri - строка подключения
+	StorageDriverSQLite   = "sqlite"   // встроенная бд SQLite, database_uri - путь к файлу бд
 )
 
 type envConfig struct {
 	GRPCConfigPath string `env:"GRPC_CONFIG_PATH"`
 }
 
+// CryptoKeyConfig мастер-ключ предыдущей версии
+// нужен для расшифровки данных, которые еще не перешифрованы новым ключом
+// ключ задается напрямую (key) или через источник ключа (provider)
+type CryptoKeyConfig struct {
+	Version  int                 `json:"version"`
+	Key      string              `json:"key"`
+	Provider *keyprovider.Config `json:"provider"`
+}
+
+// SealConfig запуск сервера в запечатанном режиме
+// мастер-ключ не хранится в конфигурации, он собирается из долей Шамира, переданных операторами через Unseal
+// key_check - контрольное значение ключа, которое печатает подкоманда split-key
+type SealConfig struct {
+	Enabled   bool   `json:"enabled"`
+	Threshold int    `json:"threshold"`
+	KeyCheck  string `json:"key_check"`
+}
+
+// LoginThrottleConfig защита входа от подбора пароля, незаданные значения берутся по умолчанию
+// после каждой неудачи по логину задержка до следующей попытки удваивается от base_delay до max_delay,
+// после max_failures неудач по логину или max_ip_failures неудач с одного IP адреса вход блокируется на lockout
+type LoginThrottleConfig struct {
+	MaxFailures   int    `json:"max_failures"`
+	MaxIPFailures int    `json:"max_ip_failures"`
+	BaseDelay     string `json:"base_delay"`
+	MaxDelay      string `json:"max_delay"`
+	Lockout       string `json:"lockout"`
+}
+
+// Argon2Config параметры хеширования паролей Argon2id, незаданные значения берутся по умолчанию
+// хеши с прежними параметрами пересчитываются при следующем входе пользователя
+type Argon2Config struct {
+	MemoryKiB   uint32 `json:"memory_kib"`
+	Iterations  uint32 `json:"iterations"`
+	Parallelism uint8  `json:"parallelism"`
+}
+
+// PasswordConfig требования к паролю при регистрации, незаданные значения берутся по умолчанию
+// min_score - наименьшая оценка надежности от 0 до 4, 0 отключает проверку надежности
+type PasswordConfig struct {
+	MinLength   int  `json:"min_length"`
+	MinScore    *int `json:"min_score"`
+	AllowLogin  bool `json:"allow_login"`
+	AllowCommon bool `json:"allow_common"`
+}
+
+// JWTKeyConfig открытый ключ предыдущего ключа подписи токенов доступа
+// нужен, пока не истекли выданные им токены; без key_id идентификатор вычисляется по ключу
+type JWTKeyConfig struct {
+	KeyID     string `json:"key_id"`
+	PublicKey string `json:"public_key"`
+}
+
+// JWTSigningConfig ключ подписи токенов доступа Ed25519
+// seed ключа задается напрямую (key) или через источник ключа (provider), как и мастер-ключ
+// без ключа сервер при каждом запуске генерирует временный ключ
+type JWTSigningConfig struct {
+	KeyID        string              `json:"key_id"`
+	Key          string              `json:"key"`
+	Provider     *keyprovider.Config `json:"provider"`
+	PreviousKeys []JWTKeyConfig      `json:"previous_keys"`
+}
+
 // ServerConfig структура для парсинга файла конфигурации
 type ServerConfig struct {
-	Address          string `json:"address"`
-	DatabaseURI      string `json:"database_uri"`
-	HashKey          string `json:"hash_key"`
-	CryptoKey        string `json:"crypto_key"`
-	StoreInterval    string `json:"store_interval"`
-	Secret           string `json:"secret"`
-	WorkersCount     int    `json:"workers_count"`
-	DbMaxConnections int32  `json:"db_max_connections"`
-	DbMinConnections int32  `json:"db_min_connections"`
+	Address            string              `json:"address"`
+	DatabaseURI        string              `json:"database_uri"`
+	StorageDriver      string              `json:"storage_driver"`
+	HashKey            string              `json:"hash_key"`
+	CryptoKey          string              `json:"crypto_key"`
+	CryptoKeyProvider  *keyprovider.Config `json:"crypto_key_provider"`
+	CryptoKeyVersion   int                 `json:"crypto_key_version"`
+	PreviousCryptoKeys []CryptoKeyConfig   `json:"previous_crypto_keys"`
+	CryptoAlgorithm    string              `json:"crypto_algorithm"`
+	Seal               SealConfig          `json:"seal"`
+	StoreInterval      string              `json:"store_interval"`
+	JWTSigning         JWTSigningConfig    `json:"jwt_signing"`
+	WorkersCount       int                 `json:"workers_count"`
+	DbMaxConnections   int32               `json:"db_max_connections"`
+	DbMinConnections   int32               `json:"db_min_connections"`
+	AccountGracePeriod string              `json:"account_deletion_grace_period"`
+	LoginThrottle      LoginThrottleConfig `json:"login_throttle"`
+	Argon2             Argon2Config        `json:"argon2"`
+	Password           PasswordConfig      `json:"password_policy"`
 }
 
 // loadConfig загружает конфигурацию из файла
@@ -51,6 +137,62 @@ func (cfg *ServerConfig) prepareConfig() error {
 	}
 	cfg.StoreInterval = strconv.FormatFloat(storeInterval.Seconds(), 'f', 0, 64)
 
+	switch cfg.StorageDriver {
+	case "", StorageDriverPostgres, StorageDriverSQLite:
+	default:
+		return fmt.Errorf("unknown storage_driver %q", cfg.StorageDriver)
+	}
+
+	if cfg.AccountGracePeriod != "" {
+		gracePeriod, err := time.ParseDuration(cfg.AccountGracePeriod)
+		if err != nil {
+			return fmt.Errorf("failed to parse account_deletion_grace_period: %w", err)
+		}
+		if gracePeriod <= 0 {
+			return fmt.Errorf("account_deletion_grace_period must be positive")
+		}
+	}
+
+	for name, value := range map[string]string{
+		"base_delay": cfg.LoginThrottle.BaseDelay,
+		"max_delay":  cfg.LoginThrottle.MaxDelay,
+		"lockout":    cfg.LoginThrottle.Lockout,
+	} {
+		if value == "" {
+			continue
+		}
+		if _, err = time.ParseDuration(value); err != nil {
+			return fmt.Errorf("failed to parse login_throttle.%s: %w", name, err)
+		}
+	}
+
+	if minScore := cfg.Password.MinScore; minScore != nil && (*minScore < 0 || *minScore > passpolicy.ScoreStrong) {
+		return fmt.Errorf("password_policy.min_score must be between 0 and %d", passpolicy.ScoreStrong)
+	}
+
+	for _, previousKey := range cfg.JWTSigning.PreviousKeys {
+		if _, err = jwt.ParsePublicKey(previousKey.PublicKey); err != nil {
+			return fmt.Errorf("failed to parse jwt_signing.previous_keys: %w", err)
+		}
+	}
+
+	if cfg.CryptoKeyVersion == 0 {
+		cfg.CryptoKeyVersion = 1
+	}
+
+	if cfg.Seal.Enabled {
+		if cfg.Seal.Threshold < 2 {
+			return fmt.Errorf("seal threshold must be at least 2")
+		}
+		if cfg.Seal.KeyCheck == "" {
+			return fmt.Errorf("seal key_check is required")
+		}
+		// ключ в конфигурации лишает смысла запечатанный режим
+		if cfg.CryptoKey != "" || cfg.CryptoKeyProvider != nil {
+			return fmt.Errorf("crypto_key must not be set when the server starts sealed")
+		}
+	}
+
 	return nil
 }
 
@@ -90,3 +232,104 @@ func GetConfig() (*ServerConfig, error) {
 
 	return &config, err
 }
+
+// Driver драйвер хранилища, по умолчанию PostgreSQL
+func (cfg *ServerConfig) Driver() string {
+	if cfg.StorageDriver == "" {
+		return StorageDriverPostgres
+	}
+	return cfg.StorageDriver
+}
+
+// DeletionGracePeriod срок восстановления учетной записи при отложенном удалении
+// если срок не задан, используется срок по умолчанию
+func (cfg *ServerConfig) DeletionGracePeriod() time.Duration {
+	gracePeriod, err := time.ParseDuration(cfg.AccountGracePeriod)
+	if err != nil || gracePeriod <= 0 {
+		return account.DefaultDeletionGracePeriod
+	}
+	return gracePeriod
+}
+
+// LoginPolicy ограничение подбора пароля, незаданные значения берутся по умолчанию
+func (cfg *ServerConfig) LoginPolicy() modelAuth.LoginPolicy {
+	policy := modelAuth.DefaultLoginPolicy()
+	if cfg.LoginThrottle.MaxFailures > 0 {
+		policy.MaxFailures = cfg.LoginThrottle.MaxFailures
+	}
+	if cfg.LoginThrottle.MaxIPFailures > 0 {
+		policy.MaxIPFailures = cfg.LoginThrottle.MaxIPFailures
+	}
+	if d, err := time.ParseDuration(cfg.LoginThrottle.BaseDelay); err == nil && d > 0 {
+		policy.BaseDelay = d
+	}
+	if d, err := time.ParseDuration(cfg.LoginThrottle.MaxDelay); err == nil && d > 0 {
+		policy.MaxDelay = d
+	}
+	if d, err := time.ParseDuration(cfg.LoginThrottle.Lockout); err == nil && d > 0 {
+		policy.Lockout = d
+	}
+	return policy
+}
+
+// Argon2Params параметры хеширования паролей, незаданные значения берутся по умолчанию
+func (cfg *ServerConfig) Argon2Params() hash.Argon2Params {
+	params := hash.DefaultArgon2Params
+	if cfg.Argon2.MemoryKiB > 0 {
+		params.Memory = cfg.Argon2.MemoryKiB
+	}
+	if cfg.Argon2.Iterations > 0 {
+		params.Iterations = cfg.Argon2.Iterations
+	}
+	if cfg.Argon2.Parallelism > 0 {
+		params.Parallelism = cfg.Argon2.Parallelism
+	}
+	return params
+}
+
+// MasterKeyProvider источник текущего мастер-ключа
+// источник из crypto_key_provider важнее ключа в открытом виде (crypto_key), nil если ключ не задан
+func (cfg *ServerConfig) MasterKeyProvider() (crypto.KeyProvider, error) {
+	return keyProvider(cfg.CryptoKey, cfg.CryptoKeyProvider)
+}
+
+// JWTKeyProvider источник seed ключа подписи токенов доступа, nil если ключ не задан
+func (cfg *ServerConfig) JWTKeyProvider() (crypto.KeyProvider, error) {
+	return keyProvider(cfg.JWTSigning.Key, cfg.JWTSigning.Provider)
+}
+
+// KeyProvider источник мастер-ключа предыдущей версии
+func (c *CryptoKeyConfig) KeyProvider() (crypto.KeyProvider, error) {
+	provider, err := keyProvider(c.Key, c.Provider)
+	if err != nil {
+		return nil, err
+	}
+	if provider == nil {
+		return nil, fmt.Errorf("crypto key version %d has no key", c.Version)
+	}
+	return provider, nil
+}
+
+func keyProvider(key string, providerConfig *keyprovider.Config) (crypto.KeyProvider, error) {
+	if providerConfig != nil {
+		return keyprovider.New(*providerConfig)
+	}
+	if key != "" {
+		return &keyprovider.StaticProvider{Key: []byte(key)}, nil
+	}
+	return nil, nil
+}
+
+// PasswordPolicy требования к паролю при регистрации, незаданные значения берутся по умолчанию
+func (cfg *ServerConfig) PasswordPolicy() passpolicy.Policy {
+	policy := passpolicy.DefaultPolicy()
+	if cfg.Password.MinLength > 0 {
+		policy.MinLength = cfg.Password.MinLength
+	}
+	if cfg.Password.MinScore != nil {
+		policy.MinScore = *cfg.Password.MinScore
+	}
+	policy.ForbidLogin = !cfg.Password.AllowLogin
+	policy.ForbidCommon = !cfg.Password.AllowCommon
+	return policy
+}
@@ -4,8 +4,16 @@ import (
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/account"
+	modelAuth "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	"github.com/ramil063/secondgodiplom/internal/hash"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/keyprovider"
+	"github.com/ramil063/secondgodiplom/internal/security/passpolicy"
 )
 
 func TestAgentConfig_loadConfig(t *testing.T) {
@@ -93,6 +101,7 @@ func TestAgentConfig_prepareConfig(t *testing.T) {
 			assert.Equal(t, tt.conf.HashKey, cfg.HashKey)
 			assert.Equal(t, tt.conf.CryptoKey, cfg.CryptoKey)
 			assert.Equal(t, "1", cfg.StoreInterval)
+			assert.Equal(t, 1, cfg.CryptoKeyVersion)
 		})
 	}
 }
@@ -106,8 +115,10 @@ func TestGetConfig(t *testing.T) {
 "store_file": "/path/to/file.db",
 "database_uri": "database",
 "crypto_key": "/path/to/key.pem",
+"crypto_key_version": 2,
+"previous_crypto_keys": [{"version": 1, "key": "/path/to/old.pem"}],
 "hash_key": "test",
-  "secret": "secret",
+  "jwt_signing": {"key_id": "2026-10", "key": "c2VlZA==", "previous_keys": [{"key_id": "2026-04", "public_key": "11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo="}]},
   "workers_count": 1,
   "db_max_connections": 1,
   "db_min_connections": 1
@@ -128,8 +139,18 @@ func TestGetConfig(t *testing.T) {
 				DatabaseURI:      "database",
 				HashKey:          "test",
 				CryptoKey:        "/path/to/key.pem",
-				StoreInterval:    "1",
-				Secret:           "secret",
+				CryptoKeyVersion: 2,
+				PreviousCryptoKeys: []CryptoKeyConfig{
+					{Version: 1, Key: "/path/to/old.pem"},
+				},
+				StoreInterval: "1",
+				JWTSigning: JWTSigningConfig{
+					KeyID: "2026-10",
+					Key:   "c2VlZA==",
+					PreviousKeys: []JWTKeyConfig{
+						{KeyID: "2026-04", PublicKey: "11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo="},
+					},
+				},
 				WorkersCount:     1,
 				DbMaxConnections: 1,
 				DbMinConnections: 1,
@@ -146,3 +167,245 @@ func TestGetConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestServerConfig_MasterKeyProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *ServerConfig
+		want    crypto.KeyProvider
+		wantErr bool
+	}{
+		{
+			name: "no key",
+			cfg:  &ServerConfig{},
+			want: nil,
+		},
+		{
+			name: "plaintext key",
+			cfg:  &ServerConfig{CryptoKey: "key"},
+			want: &keyprovider.StaticProvider{Key: []byte("key")},
+		},
+		{
+			name: "provider wins over plaintext key",
+			cfg: &ServerConfig{
+				CryptoKey:         "key",
+				CryptoKeyProvider: &keyprovider.Config{Type: keyprovider.TypeFile, Path: "/etc/gophkeeper/master.key"},
+			},
+			want: &keyprovider.FileProvider{Path: "/etc/gophkeeper/master.key"},
+		},
+		{
+			name: "unknown provider",
+			cfg: &ServerConfig{
+				CryptoKeyProvider: &keyprovider.Config{Type: "hsm"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.cfg.MasterKeyProvider()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := (&CryptoKeyConfig{Version: 1}).KeyProvider()
+	assert.Error(t, err)
+}
+
+func TestServerConfig_prepareConfigSeal(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *ServerConfig
+		wantErr bool
+	}{
+		{
+			name: "sealed",
+			cfg: &ServerConfig{
+				StoreInterval: "1s",
+				Seal:          SealConfig{Enabled: true, Threshold: 3, KeyCheck: "check"},
+			},
+		},
+		{
+			name: "threshold too small",
+			cfg: &ServerConfig{
+				StoreInterval: "1s",
+				Seal:          SealConfig{Enabled: true, Threshold: 1, KeyCheck: "check"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "no key check",
+			cfg: &ServerConfig{
+				StoreInterval: "1s",
+				Seal:          SealConfig{Enabled: true, Threshold: 3},
+			},
+			wantErr: true,
+		},
+		{
+			name: "key in config",
+			cfg: &ServerConfig{
+				StoreInterval: "1s",
+				CryptoKey:     "key",
+				Seal:          SealConfig{Enabled: true, Threshold: 3, KeyCheck: "check"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.prepareConfig()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestServerConfig_prepareConfigJWTSigning(t *testing.T) {
+	cfg := &ServerConfig{
+		StoreInterval: "1s",
+		JWTSigning: JWTSigningConfig{
+			PreviousKeys: []JWTKeyConfig{{KeyID: "2026-04", PublicKey: "11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo="}},
+		},
+	}
+	assert.NoError(t, cfg.prepareConfig())
+
+	cfg.JWTSigning.PreviousKeys = append(cfg.JWTSigning.PreviousKeys, JWTKeyConfig{PublicKey: "c2VlZA=="})
+	assert.Error(t, cfg.prepareConfig())
+}
+
+func TestServerConfig_Driver(t *testing.T) {
+	tests := []struct {
+		name           string
+		driver         string
+		want           string
+		wantPrepareErr bool
+	}{
+		{
+			name: "default",
+			want: StorageDriverPostgres,
+		},
+		{
+			name:   "sqlite",
+			driver: StorageDriverSQLite,
+			want:   StorageDriverSQLite,
+		},
+		{
+			name:           "unknown",
+			driver:         "mysql",
+			want:           "mysql",
+			wantPrepareErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &ServerConfig{StoreInterval: "1s", StorageDriver: tt.driver}
+			err := cfg.prepareConfig()
+			if tt.wantPrepareErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, cfg.Driver())
+		})
+	}
+}
+
+func TestServerConfig_DeletionGracePeriod(t *testing.T) {
+	tests := []struct {
+		name           string
+		gracePeriod    string
+		want           time.Duration
+		wantPrepareErr bool
+	}{
+		{
+			name: "default",
+			want: account.DefaultDeletionGracePeriod,
+		},
+		{
+			name:        "configured",
+			gracePeriod: "72h",
+			want:        72 * time.Hour,
+		},
+		{
+			name:           "invalid",
+			gracePeriod:    "three days",
+			want:           account.DefaultDeletionGracePeriod,
+			wantPrepareErr: true,
+		},
+		{
+			name:           "negative",
+			gracePeriod:    "-1h",
+			want:           account.DefaultDeletionGracePeriod,
+			wantPrepareErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &ServerConfig{StoreInterval: "1s", AccountGracePeriod: tt.gracePeriod}
+			err := cfg.prepareConfig()
+			if tt.wantPrepareErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, cfg.DeletionGracePeriod())
+		})
+	}
+}
+
+func TestServerConfig_LoginPolicy(t *testing.T) {
+	assert.Equal(t, modelAuth.DefaultLoginPolicy(), (&ServerConfig{}).LoginPolicy())
+
+	cfg := &ServerConfig{
+		StoreInterval: "1s",
+		LoginThrottle: LoginThrottleConfig{
+			MaxFailures: 3,
+			BaseDelay:   "2s",
+			Lockout:     "1h",
+		},
+	}
+	assert.NoError(t, cfg.prepareConfig())
+	assert.Equal(t, modelAuth.LoginPolicy{
+		MaxFailures:   3,
+		MaxIPFailures: modelAuth.DefaultMaxIPFailures,
+		BaseDelay:     2 * time.Second,
+		MaxDelay:      modelAuth.DefaultLoginMaxDelay,
+		Lockout:       time.Hour,
+	}, cfg.LoginPolicy())
+
+	cfg.LoginThrottle.MaxDelay = "one minute"
+	assert.Error(t, cfg.prepareConfig())
+}
+
+func TestServerConfig_Argon2Params(t *testing.T) {
+	assert.Equal(t, hash.DefaultArgon2Params, (&ServerConfig{}).Argon2Params())
+
+	cfg := &ServerConfig{Argon2: Argon2Config{MemoryKiB: 19 * 1024, Iterations: 2}}
+	want := hash.DefaultArgon2Params
+	want.Memory = 19 * 1024
+	want.Iterations = 2
+	assert.Equal(t, want, cfg.Argon2Params())
+}
+
+func TestServerConfig_PasswordPolicy(t *testing.T) {
+	assert.Equal(t, passpolicy.DefaultPolicy(), (&ServerConfig{}).PasswordPolicy())
+
+	minScore := 0
+	cfg := &ServerConfig{
+		StoreInterval: "1s",
+		Password:      PasswordConfig{MinLength: 12, MinScore: &minScore, AllowLogin: true},
+	}
+	assert.NoError(t, cfg.prepareConfig())
+	assert.Equal(t, passpolicy.Policy{MinLength: 12, MinScore: 0, ForbidLogin: false, ForbidCommon: true}, cfg.PasswordPolicy())
+
+	minScore = 5
+	assert.Error(t, cfg.prepareConfig())
+}
@@ -0,0 +1,236 @@
+package interceptors
+
+import (
+	"context"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	authServer "github.com/ramil063/secondgodiplom/cmd/gophkeeper/server/auth"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/audit"
+	authModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	"github.com/ramil063/secondgodiplom/internal/logger"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/auth"
+)
+
+// AuditRecorder запись событий в журнал аудита
+type AuditRecorder interface {
+	SaveAuditEvent(ctx context.Context, event *audit.Event) error
+}
+
+// auditRule событие журнала аудита для метода
+// byLogin - пользователь определяется по логину из запроса (вход до выдачи токена)
+type auditRule struct {
+	event   string
+	byLogin bool
+}
+
+// auditRules методы, вызовы которых записываются в журнал аудита
+var auditRules = map[string]auditRule{
+	"/auth.AuthService/Login":         {event: audit.EventLogin, byLogin: true},
+	"/auth.AuthService/VerifyTOTP":    {event: audit.EventLoginTOTP},
+	"/auth.AuthService/Refresh":       {event: audit.EventTokenRefresh},
+	"/auth.AuthService/Logout":        {event: audit.EventLogout},
+	"/auth.AuthService/LogoutAll":     {event: audit.EventLogoutAll},
+	"/auth.AuthService/RevokeSession": {event: audit.EventSessionRevoke},
+	"/auth.AuthService/ConfirmTOTP":   {event: audit.EventTOTPEnable},
+
+	"/account.AccountService/ChangePassword": {event: audit.EventPasswordChange},
+	"/account.AccountService/DeleteAccount":  {event: audit.EventAccountDelete},
+	"/account.AccountService/RestoreAccount": {event: audit.EventAccountRestore, byLogin: true},
+
+	"/token.TokenService/CreatePersonalToken": {event: audit.EventPersonalTokenCreate},
+	"/token.TokenService/RevokePersonalToken": {event: audit.EventPersonalTokenRevoke},
+
+	"/items.password.Service/CreatePassword": {event: audit.EventItemCreate},
+	"/items.password.Service/GetPassword":    {event: audit.EventItemRead},
+	"/items.password.Service/ListPasswords":  {event: audit.EventItemList},
+	"/items.password.Service/UpdatePassword": {event: audit.EventItemUpdate},
+	"/items.password.Service/DeletePassword": {event: audit.EventItemDelete},
+
+	"/items.textdata.Service/CreateTextData":    {event: audit.EventItemCreate},
+	"/items.textdata.Service/GetTextData":       {event: audit.EventItemRead},
+	"/items.textdata.Service/ListTextDataItems": {event: audit.EventItemList},
+	"/items.textdata.Service/UpdateTextData":    {event: audit.EventItemUpdate},
+	"/items.textdata.Service/DeleteTextData":    {event: audit.EventItemDelete},
+
+	"/items.bankcard.Service/CreateCardData": {event: audit.EventItemCreate},
+	"/items.bankcard.Service/GetCardData":    {event: audit.EventItemRead},
+	"/items.bankcard.Service/ListCardsData":  {event: audit.EventItemList},
+	"/items.bankcard.Service/UpdateCardData": {event: audit.EventItemUpdate},
+	"/items.bankcard.Service/DeleteCardData": {event: audit.EventItemDelete},
+
+	"/items.binarydata.Service/UploadFile":   {event: audit.EventFileUpload},
+	"/items.binarydata.Service/DownloadFile": {event: audit.EventFileDownload},
+	"/items.binarydata.Service/GetFileInfo":  {event: audit.EventFileRead},
+	"/items.binarydata.Service/ListFiles":    {event: audit.EventFileList},
+	"/items.binarydata.Service/DeleteFile":   {event: audit.EventFileDelete},
+}
+
+// loginRequest запрос входа по логину
+type loginRequest interface {
+	GetLogin() string
+}
+
+// accessTokenResponse ответ с выданным токеном доступа
+type accessTokenResponse interface {
+	GetAccessToken() string
+}
+
+// AuditInterceptors запись вызовов в журнал аудита
+type AuditInterceptors struct {
+	Unary  grpc.UnaryServerInterceptor
+	Stream grpc.StreamServerInterceptor
+}
+
+// NewAuditInterceptors инициализация интерсепторов журнала аудита
+// ставятся после интерсепторов авторизации, чтобы пользователь был уже известен;
+// ошибка записи в журнал не прерывает вызов
+func NewAuditInterceptors(recorder AuditRecorder) *AuditInterceptors {
+	return &AuditInterceptors{
+		Unary: func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			rule, ok := auditRules[info.FullMethod]
+			if !ok {
+				return handler(ctx, req)
+			}
+			resp, err := handler(ctx, req)
+			recordAuditEvent(ctx, recorder, info.FullMethod, rule, req, resp, err)
+			return resp, err
+		},
+		Stream: func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			rule, ok := auditRules[info.FullMethod]
+			if !ok {
+				return handler(srv, ss)
+			}
+			stream := &auditServerStream{ServerStream: ss}
+			err := handler(srv, stream)
+			recordAuditEvent(ss.Context(), recorder, info.FullMethod, rule, stream.request(), stream.response(), err)
+			return err
+		},
+	}
+}
+
+// recordAuditEvent запись события вызова
+// объект действия берется из запроса, а если в запросе его нет (создание) - из ответа
+func recordAuditEvent(
+	ctx context.Context,
+	recorder AuditRecorder,
+	fullMethod string,
+	rule auditRule,
+	req interface{},
+	resp interface{},
+	callErr error,
+) {
+	info := authServer.ClientInfo(ctx)
+	event := &audit.Event{
+		Type:       rule.event,
+		Method:     fullMethod,
+		Success:    callErr == nil,
+		StatusCode: status.Code(callErr).String(),
+		ClientIP:   info.ClientIP,
+		UserAgent:  info.UserAgent,
+	}
+
+	if userID, ok := ctx.Value("userID").(int); ok {
+		event.UserID = &userID
+	} else if userID, ok := userFromResponse(resp); ok {
+		event.UserID = &userID
+	}
+	if r, ok := req.(loginRequest); ok && rule.byLogin {
+		event.Login = r.GetLogin()
+	}
+	if personal, ok := ctx.Value("personalToken").(*authModel.PersonalToken); ok {
+		event.PersonalTokenID = &personal.ID
+	}
+
+	if id, ok := targetID(req); ok && id > 0 {
+		event.TargetID = &id
+	} else if id, ok = targetID(resp); ok && id > 0 {
+		event.TargetID = &id
+	}
+
+	// пароль принят, но токены будут выданы только после второго фактора
+	if r, ok := resp.(*auth.LoginResponse); ok && r.GetTotpRequired() {
+		event.Type = audit.EventLoginSecondFactor
+	}
+
+	// вызов мог быть отменен клиентом, а событие все равно нужно сохранить
+	if err := recorder.SaveAuditEvent(context.WithoutCancel(ctx), event); err != nil {
+		logger.WriteErrorLog(err.Error())
+	}
+}
+
+// targetID идентификатор объекта действия из запроса или ответа
+func targetID(message interface{}) (int64, bool) {
+	switch m := message.(type) {
+	case fileRequest:
+		return m.GetFileId(), true
+	case itemRequest:
+		return m.GetId(), true
+	}
+	return 0, false
+}
+
+// userFromResponse пользователь из токена доступа, выданного в ответе
+// токен только что подписан этим сервером, поэтому подпись не проверяется
+func userFromResponse(resp interface{}) (int, bool) {
+	r, ok := resp.(accessTokenResponse)
+	if !ok || r.GetAccessToken() == "" {
+		return 0, false
+	}
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(r.GetAccessToken(), claims); err != nil {
+		return 0, false
+	}
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return 0, false
+	}
+	return int(userID), true
+}
+
+// auditServerStream поток, в котором запоминается первый запрос и последний ответ
+type auditServerStream struct {
+	grpc.ServerStream
+	mu       sync.Mutex
+	firstReq interface{}
+	lastResp interface{}
+}
+
+// RecvMsg получение сообщения, первое запоминается
+func (s *auditServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.mu.Lock()
+		if s.firstReq == nil {
+			s.firstReq = m
+		}
+		s.mu.Unlock()
+	}
+	return err
+}
+
+// SendMsg отправка сообщения, последнее запоминается
+func (s *auditServerStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.mu.Lock()
+		s.lastResp = m
+		s.mu.Unlock()
+	}
+	return err
+}
+
+func (s *auditServerStream) request() interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.firstReq
+}
+
+func (s *auditServerStream) response() interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastResp
+}
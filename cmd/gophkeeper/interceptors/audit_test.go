@@ -0,0 +1,247 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/audit"
+	authModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/auth"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/binarydata"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/password"
+)
+
+type auditEvents struct {
+	events []*audit.Event
+	err    error
+}
+
+func (a *auditEvents) SaveAuditEvent(ctx context.Context, event *audit.Event) error {
+	a.events = append(a.events, event)
+	return a.err
+}
+
+func auditContext() context.Context {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 51234},
+	})
+	return metadata.NewIncomingContext(ctx, metadata.Pairs("user-agent", "gophkeeper-client/1.0"))
+}
+
+func TestNewAuditInterceptors_Unary(t *testing.T) {
+	accessToken := signToken(t, newTestKeys(t), jwt.MapClaims{"user_id": 7})
+	userID := 7
+	personalTokenID := 3
+	itemID := int64(42)
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		fullMethod string
+		req        interface{}
+		resp       interface{}
+		err        error
+		want       *audit.Event
+	}{
+		{
+			name:       "successful login",
+			ctx:        auditContext(),
+			fullMethod: "/auth.AuthService/Login",
+			req:        &auth.LoginRequest{Login: "alice"},
+			resp:       &auth.LoginResponse{AccessToken: accessToken},
+			want: &audit.Event{
+				UserID:     &userID,
+				Login:      "alice",
+				Type:       audit.EventLogin,
+				Method:     "/auth.AuthService/Login",
+				Success:    true,
+				StatusCode: codes.OK.String(),
+				ClientIP:   "10.0.0.7",
+				UserAgent:  "gophkeeper-client/1.0",
+			},
+		},
+		{
+			name:       "failed login",
+			ctx:        auditContext(),
+			fullMethod: "/auth.AuthService/Login",
+			req:        &auth.LoginRequest{Login: "alice"},
+			err:        status.Error(codes.Unauthenticated, "invalid credentials"),
+			want: &audit.Event{
+				Login:      "alice",
+				Type:       audit.EventLogin,
+				Method:     "/auth.AuthService/Login",
+				StatusCode: codes.Unauthenticated.String(),
+				ClientIP:   "10.0.0.7",
+				UserAgent:  "gophkeeper-client/1.0",
+			},
+		},
+		{
+			name:       "login waits for second factor",
+			ctx:        auditContext(),
+			fullMethod: "/auth.AuthService/Login",
+			req:        &auth.LoginRequest{Login: "alice"},
+			resp:       &auth.LoginResponse{TotpRequired: true},
+			want: &audit.Event{
+				Login:      "alice",
+				Type:       audit.EventLoginSecondFactor,
+				Method:     "/auth.AuthService/Login",
+				Success:    true,
+				StatusCode: codes.OK.String(),
+				ClientIP:   "10.0.0.7",
+				UserAgent:  "gophkeeper-client/1.0",
+			},
+		},
+		{
+			name:       "item read with personal token",
+			ctx:        context.WithValue(context.WithValue(context.Background(), "userID", 7), "personalToken", &authModel.PersonalToken{ID: 3}),
+			fullMethod: "/items.password.Service/GetPassword",
+			req:        &password.GetPasswordRequest{Id: itemID},
+			resp:       &password.PasswordItem{Id: itemID},
+			want: &audit.Event{
+				UserID:          &userID,
+				Type:            audit.EventItemRead,
+				Method:          "/items.password.Service/GetPassword",
+				TargetID:        &itemID,
+				PersonalTokenID: &personalTokenID,
+				Success:         true,
+				StatusCode:      codes.OK.String(),
+			},
+		},
+		{
+			// в запросе создания нет ID, он берется из ответа
+			name:       "item create",
+			ctx:        context.WithValue(context.Background(), "userID", 7),
+			fullMethod: "/items.password.Service/CreatePassword",
+			req:        &password.CreatePasswordRequest{Login: "site-login"},
+			resp:       &password.PasswordItem{Id: itemID},
+			want: &audit.Event{
+				UserID:     &userID,
+				Type:       audit.EventItemCreate,
+				Method:     "/items.password.Service/CreatePassword",
+				TargetID:   &itemID,
+				Success:    true,
+				StatusCode: codes.OK.String(),
+			},
+		},
+		{
+			name:       "not audited method",
+			ctx:        context.Background(),
+			fullMethod: "/auth.AuthService/GetPublicKeys",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &auditEvents{}
+			interceptors := NewAuditInterceptors(recorder)
+
+			resp, err := interceptors.Unary(tt.ctx, tt.req, &grpc.UnaryServerInfo{FullMethod: tt.fullMethod},
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					return tt.resp, tt.err
+				})
+			assert.Equal(t, tt.resp, resp)
+			assert.Equal(t, tt.err, err)
+
+			if tt.want == nil {
+				assert.Empty(t, recorder.events)
+				return
+			}
+			require.Len(t, recorder.events, 1)
+			assert.Equal(t, tt.want, recorder.events[0])
+		})
+	}
+}
+
+func TestNewAuditInterceptors_RecorderError(t *testing.T) {
+	recorder := &auditEvents{err: errors.New("connection refused")}
+	interceptors := NewAuditInterceptors(recorder)
+
+	// ошибка записи в журнал не меняет результат вызова
+	resp, err := interceptors.Unary(context.WithValue(context.Background(), "userID", 7), &password.GetPasswordRequest{Id: 1},
+		&grpc.UnaryServerInfo{FullMethod: "/items.password.Service/GetPassword"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return &password.PasswordItem{Id: 1}, nil
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, &password.PasswordItem{Id: 1}, resp)
+	assert.Len(t, recorder.events, 1)
+}
+
+type uploadStream struct {
+	MockServerStream
+}
+
+func (s *uploadStream) RecvMsg(m interface{}) error {
+	m.(*binarydata.UploadFileRequest).Data = &binarydata.UploadFileRequest_Metadata{
+		Metadata: &binarydata.FileMetadata{Filename: "report.pdf"},
+	}
+	return nil
+}
+
+func TestNewAuditInterceptors_Stream(t *testing.T) {
+	userID := 7
+	fileID := int64(11)
+
+	tests := []struct {
+		name       string
+		fullMethod string
+		handler    grpc.StreamHandler
+		want       *audit.Event
+	}{
+		{
+			// ID загруженного файла известен только из последнего ответа
+			name:       "file upload",
+			fullMethod: "/items.binarydata.Service/UploadFile",
+			handler: func(srv interface{}, stream grpc.ServerStream) error {
+				req := &binarydata.UploadFileRequest{}
+				if err := stream.RecvMsg(req); err != nil {
+					return err
+				}
+				return stream.SendMsg(&binarydata.UploadFileResponse{FileId: fileID})
+			},
+			want: &audit.Event{
+				UserID:     &userID,
+				Type:       audit.EventFileUpload,
+				Method:     "/items.binarydata.Service/UploadFile",
+				TargetID:   &fileID,
+				Success:    true,
+				StatusCode: codes.OK.String(),
+			},
+		},
+		{
+			name:       "file download failed",
+			fullMethod: "/items.binarydata.Service/DownloadFile",
+			handler: func(srv interface{}, stream grpc.ServerStream) error {
+				return status.Error(codes.NotFound, "file not found")
+			},
+			want: &audit.Event{
+				UserID:     &userID,
+				Type:       audit.EventFileDownload,
+				Method:     "/items.binarydata.Service/DownloadFile",
+				StatusCode: codes.NotFound.String(),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &auditEvents{}
+			interceptors := NewAuditInterceptors(recorder)
+			stream := &uploadStream{MockServerStream{ctx: context.WithValue(context.Background(), "userID", 7)}}
+
+			_ = interceptors.Stream(nil, stream, &grpc.StreamServerInfo{FullMethod: tt.fullMethod}, tt.handler)
+
+			require.Len(t, recorder.events, 1)
+			assert.Equal(t, tt.want, recorder.events[0])
+		})
+	}
+}
@@ -7,7 +7,12 @@ import (
 
 	"github.com/golang-jwt/jwt/v4"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	authModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	internalJwt "github.com/ramil063/secondgodiplom/internal/security/jwt"
 )
 
 // AuthInterceptors обработка авторизации
@@ -16,11 +21,19 @@ type AuthInterceptors struct {
 	Stream grpc.StreamServerInterceptor
 }
 
+// PersonalTokenResolver получение действующего персонального токена по его значению
+type PersonalTokenResolver interface {
+	GetPersonalToken(ctx context.Context, token string) (*authModel.PersonalToken, error)
+}
+
 // NewAuthInterceptors инициализация основной структуры авторизации
-func NewAuthInterceptors(secret string) *AuthInterceptors {
+// подпись токена проверяется открытым ключом из keys по заголовку kid,
+// отозванные и неизвестные серверу токены отклоняются, результат проверки кешируется
+// personal - хранилище персональных токенов, nil - персональные токены не принимаются
+func NewAuthInterceptors(keys *internalJwt.KeySet, tokens *TokenCache, personal PersonalTokenResolver) *AuthInterceptors {
 	return &AuthInterceptors{
-		Unary:  NewAuthInterceptor(secret),
-		Stream: NewStreamAuthInterceptor(secret),
+		Unary:  NewAuthInterceptor(keys, tokens, personal),
+		Stream: NewStreamAuthInterceptor(keys, tokens, personal),
 	}
 }
 
@@ -36,30 +49,35 @@ func (w *wrappedServerStream) Context() context.Context {
 }
 
 // NewAuthInterceptor инициализация простого интерсептора авторизации
-func NewAuthInterceptor(secret string) grpc.UnaryServerInterceptor {
+// вызовы с персональным токеном ограничены его правами
+func NewAuthInterceptor(keys *internalJwt.KeySet, tokens *TokenCache, personal PersonalTokenResolver) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		// Пропускаем аутентификационные методы
 		if isAuthMethod(info.FullMethod) {
 			return handler(ctx, req)
 		}
 
-		newCtx, err := authenticateRequest(ctx, secret)
+		newCtx, err := authenticateRequest(ctx, keys, tokens, personal)
 		if err != nil {
 			return nil, err
 		}
+		if err = checkScope(newCtx, info.FullMethod, req); err != nil {
+			return nil, err
+		}
 
 		return handler(newCtx, req)
 	}
 }
 
 // NewStreamAuthInterceptor инициализация стримингового интерсептора авторизации
-func NewStreamAuthInterceptor(secret string) grpc.StreamServerInterceptor {
+// для персонального токена с правом только на отдельные объекты ID объекта проверяется по первому сообщению клиента
+func NewStreamAuthInterceptor(keys *internalJwt.KeySet, tokens *TokenCache, personal PersonalTokenResolver) grpc.StreamServerInterceptor {
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		// Пропускаем аутентификационные методы
 		if isAuthMethod(info.FullMethod) {
 			return handler(srv, ss)
 		}
-		newCtx, err := authenticateRequest(ss.Context(), secret)
+		newCtx, err := authenticateRequest(ss.Context(), keys, tokens, personal)
 		if err != nil {
 			return err
 		}
@@ -69,31 +87,79 @@ func NewStreamAuthInterceptor(secret string) grpc.StreamServerInterceptor {
 			ServerStream: ss,
 			ctx:          newCtx,
 		}
+		if err = checkScope(newCtx, info.FullMethod, nil); err != nil {
+			if !needsObjectCheck(newCtx, info.FullMethod) {
+				return err
+			}
+			return handler(srv, &scopedServerStream{ServerStream: wrappedStream, fullMethod: info.FullMethod})
+		}
 		return handler(srv, wrappedStream)
 	}
 }
 
-func authenticateRequest(ctx context.Context, secret string) (context.Context, error) {
+// authenticateRequest проверка подписи токена и того, что токен не отозван
+// в контекст кладется пользователь и сам токен (нужен для выхода)
+func authenticateRequest(
+	ctx context.Context,
+	keys *internalJwt.KeySet,
+	tokens *TokenCache,
+	personal PersonalTokenResolver,
+) (context.Context, error) {
 	token, err := extractTokenFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
+	if strings.HasPrefix(token, authModel.PersonalTokenPrefix) {
+		return authenticatePersonalToken(ctx, token, personal)
+	}
 
-	userID, err := validateAccessToken(token, secret)
+	userID, err := validateAccessToken(token, keys)
 	if err != nil {
 		return nil, err
 	}
 
+	active, err := tokens.IsActive(ctx, token, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check token")
+	}
+	if !active {
+		return nil, fmt.Errorf("token revoked")
+	}
+
+	ctx = context.WithValue(ctx, "accessToken", token)
 	return context.WithValue(ctx, "userID", userID), nil
 }
 
+// authenticatePersonalToken проверка персонального токена по хешу в хранилище
+// в контекст кладется пользователь и токен с его правами, значение токена в контекст не попадает
+func authenticatePersonalToken(ctx context.Context, token string, personal PersonalTokenResolver) (context.Context, error) {
+	if personal == nil {
+		return nil, fmt.Errorf("invalid token")
+	}
+	pt, err := personal.GetPersonalToken(ctx, token)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, fmt.Errorf("invalid token")
+		}
+		return nil, fmt.Errorf("failed to check token")
+	}
+
+	ctx = context.WithValue(ctx, "personalToken", pt)
+	return context.WithValue(ctx, "userID", pt.UserID), nil
+}
+
 // Пропускаем методы аутентификации
 func isAuthMethod(fullMethod string) bool {
 	authMethods := map[string]bool{
-		"/auth.AuthService/Login":            true,
-		"/auth.AuthService/StreamLogin":      true,
-		"/auth.RegistrationService/Register": true,
-		"/auth.AuthService/Refresh":          true,
+		"/auth.AuthService/Login":                true,
+		"/auth.AuthService/StreamLogin":          true,
+		"/auth.RegistrationService/Register":     true,
+		"/auth.AuthService/Refresh":              true,
+		"/auth.AuthService/VerifyTOTP":           true,
+		"/auth.AuthService/GetPublicKeys":        true,
+		"/account.AccountService/RestoreAccount": true,
+		"/seal.SealService/Unseal":               true,
+		"/seal.SealService/Status":               true,
 	}
 	return authMethods[fullMethod]
 }
@@ -118,12 +184,18 @@ func extractTokenFromContext(ctx context.Context) (string, error) {
 	return strings.TrimPrefix(authHeader, "Bearer "), nil
 }
 
-func validateAccessToken(tokenString, secret string) (int, error) {
+// validateAccessToken проверка подписи токена ключом, выбранным по заголовку kid
+// принимается только Ed25519, токены без kid или с неизвестным ключом отклоняются
+func validateAccessToken(tokenString string, keys *internalJwt.KeySet) (int, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		if _, ok := token.Method.(*jwt.SigningMethodEd25519); !ok {
 			return nil, fmt.Errorf("unexpected signing method")
 		}
-		return []byte(secret), nil
+		kid, ok := token.Header["kid"].(string)
+		if !ok {
+			return nil, fmt.Errorf("kid not found in token header")
+		}
+		return keys.VerificationKey(kid)
 	})
 
 	if err != nil || !token.Valid {
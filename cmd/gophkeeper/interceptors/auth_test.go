@@ -2,6 +2,7 @@ package interceptors
 
 import (
 	"context"
+	"crypto/ed25519"
 	"testing"
 	"time"
 
@@ -16,8 +17,8 @@ import (
 )
 
 func TestNewAuthInterceptor(t *testing.T) {
-	secret := "test_secret"
-	interceptor := NewAuthInterceptor(secret)
+	keys := newTestKeys(t)
+	interceptor := NewAuthInterceptor(keys, activeTokens(), nil)
 
 	tests := []struct {
 		name         string
@@ -38,7 +39,7 @@ func TestNewAuthInterceptor(t *testing.T) {
 			name:       "valid token",
 			fullMethod: "/user.UserService/GetUser",
 			setupContext: func() context.Context {
-				token, _ := internalJwt.GenerateAccessToken(1, secret)
+				token, _ := internalJwt.GenerateAccessToken(1, keys)
 				md := metadata.Pairs("authorization", "Bearer "+token)
 				ctx := context.WithValue(context.Background(), "user_id", 1)
 				return metadata.NewIncomingContext(ctx, md)
@@ -96,9 +97,9 @@ func TestNewAuthInterceptor(t *testing.T) {
 }
 
 func TestNewAuthInterceptors(t *testing.T) {
-	secret := "test_secret"
+	keys := newTestKeys(t)
 
-	interceptors := NewAuthInterceptors(secret)
+	interceptors := NewAuthInterceptors(keys, activeTokens(), nil)
 
 	assert.NotNil(t, interceptors.Unary)
 	assert.NotNil(t, interceptors.Stream)
@@ -120,8 +121,8 @@ func (m *MockServerStream) SendMsg(interface{}) error    { return nil }
 func (m *MockServerStream) RecvMsg(interface{}) error    { return nil }
 
 func TestStreamAuthInterceptor_Integration(t *testing.T) {
-	secret := "test_secret_123456"
-	interceptor := NewStreamAuthInterceptor(secret)
+	keys := newTestKeys(t)
+	interceptor := NewStreamAuthInterceptor(keys, activeTokens(), nil)
 
 	tests := []struct {
 		name        string
@@ -132,7 +133,7 @@ func TestStreamAuthInterceptor_Integration(t *testing.T) {
 		{
 			name: "successful authentication",
 			setupStream: func() grpc.ServerStream {
-				token, _ := internalJwt.GenerateAccessToken(456, secret)
+				token, _ := internalJwt.GenerateAccessToken(456, keys)
 				md := metadata.Pairs("authorization", "Bearer "+token)
 				ctx := metadata.NewIncomingContext(context.Background(), md)
 				return &MockServerStream{ctx: ctx}
@@ -178,7 +179,7 @@ func TestStreamAuthInterceptor_Integration(t *testing.T) {
 }
 
 func Test_authenticateRequest(t *testing.T) {
-	secret := "test_secret_123456"
+	keys := newTestKeys(t)
 
 	tests := []struct {
 		name         string
@@ -190,7 +191,7 @@ func Test_authenticateRequest(t *testing.T) {
 		{
 			name: "valid token",
 			setupContext: func() context.Context {
-				token, _ := internalJwt.GenerateAccessToken(123, secret)
+				token, _ := internalJwt.GenerateAccessToken(123, keys)
 				md := metadata.Pairs("authorization", "Bearer "+token)
 				return metadata.NewIncomingContext(context.Background(), md)
 			},
@@ -233,10 +234,10 @@ func Test_authenticateRequest(t *testing.T) {
 			errorMsg:  "invalid token",
 		},
 		{
-			name: "different secret",
+			name: "different key",
 			setupContext: func() context.Context {
-				// Токен подписан другим секретом
-				token, _ := internalJwt.GenerateAccessToken(123, "different_secret")
+				// Токен подписан другим ключом
+				token, _ := internalJwt.GenerateAccessToken(123, newTestKeys(t))
 				md := metadata.Pairs("authorization", "Bearer "+token)
 				return metadata.NewIncomingContext(context.Background(), md)
 			},
@@ -249,7 +250,7 @@ func Test_authenticateRequest(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctx := tt.setupContext()
 
-			newCtx, err := authenticateRequest(ctx, secret)
+			newCtx, err := authenticateRequest(ctx, keys, activeTokens(), nil)
 
 			if tt.wantError {
 				assert.Error(t, err)
@@ -271,7 +272,7 @@ func Test_authenticateRequest(t *testing.T) {
 }
 
 func TestExtractAndValidate_Integration(t *testing.T) {
-	secret := "integration_test_secret"
+	keys := newTestKeys(t)
 
 	tests := []struct {
 		name         string
@@ -282,7 +283,7 @@ func TestExtractAndValidate_Integration(t *testing.T) {
 		{
 			name: "full cycle success",
 			setupContext: func() context.Context {
-				token, _ := internalJwt.GenerateAccessToken(789, secret)
+				token, _ := internalJwt.GenerateAccessToken(789, keys)
 				md := metadata.Pairs("authorization", "Bearer "+token)
 				return metadata.NewIncomingContext(context.Background(), md)
 			},
@@ -292,7 +293,7 @@ func TestExtractAndValidate_Integration(t *testing.T) {
 		{
 			name: "full cycle with different user",
 			setupContext: func() context.Context {
-				token, _ := internalJwt.GenerateAccessToken(999, secret)
+				token, _ := internalJwt.GenerateAccessToken(999, keys)
 				md := metadata.Pairs("authorization", "Bearer "+token)
 				return metadata.NewIncomingContext(context.Background(), md)
 			},
@@ -321,7 +322,7 @@ func TestExtractAndValidate_Integration(t *testing.T) {
 			require.NoError(t, err)
 
 			// Валидируем токен
-			userID, err := validateAccessToken(token, secret)
+			userID, err := validateAccessToken(token, keys)
 
 			if tt.wantError {
 				assert.Error(t, err)
@@ -437,7 +438,7 @@ func TestExtractTokenFromContext(t *testing.T) {
 }
 
 func TestValidateAccessToken(t *testing.T) {
-	secret := "test_secret_key_1234567890"
+	keys := newTestKeys(t)
 
 	tests := []struct {
 		name       string
@@ -449,7 +450,7 @@ func TestValidateAccessToken(t *testing.T) {
 		{
 			name: "valid token",
 			setupToken: func() string {
-				token, _ := internalJwt.GenerateAccessToken(123, secret)
+				token, _ := internalJwt.GenerateAccessToken(123, keys)
 				return token
 			},
 			wantUserID: 123,
@@ -458,7 +459,7 @@ func TestValidateAccessToken(t *testing.T) {
 		{
 			name: "token with different user ID",
 			setupToken: func() string {
-				token, _ := internalJwt.GenerateAccessToken(456, secret)
+				token, _ := internalJwt.GenerateAccessToken(456, keys)
 				return token
 			},
 			wantUserID: 456,
@@ -467,8 +468,8 @@ func TestValidateAccessToken(t *testing.T) {
 		{
 			name: "invalid token signature",
 			setupToken: func() string {
-				// Токен подписан другим секретом
-				token, _ := internalJwt.GenerateAccessToken(123, "different_secret")
+				// Токен подписан другим ключом
+				token, _ := internalJwt.GenerateAccessToken(123, newTestKeys(t))
 				return token
 			},
 			wantError: true,
@@ -494,13 +495,11 @@ func TestValidateAccessToken(t *testing.T) {
 			name: "expired token",
 			setupToken: func() string {
 				// Создаем просроченный токен
-				token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+				return signToken(t, keys, jwt.MapClaims{
 					"user_id": 123,
 					"exp":     time.Now().Add(-time.Hour).Unix(),
 					"iat":     time.Now().Add(-2 * time.Hour).Unix(),
 				})
-				signedToken, _ := token.SignedString([]byte(secret))
-				return signedToken
 			},
 			wantError: true,
 			errorMsg:  "invalid token",
@@ -509,11 +508,9 @@ func TestValidateAccessToken(t *testing.T) {
 			name: "token without user_id claim",
 			setupToken: func() string {
 				// Токен без user_id
-				token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+				return signToken(t, keys, jwt.MapClaims{
 					"exp": time.Now().Add(time.Hour).Unix(),
 				})
-				signedToken, _ := token.SignedString([]byte(secret))
-				return signedToken
 			},
 			wantError: true,
 			errorMsg:  "user_id not found in token",
@@ -522,12 +519,10 @@ func TestValidateAccessToken(t *testing.T) {
 			name: "token with non-numeric user_id",
 			setupToken: func() string {
 				// Токен с user_id как строка
-				token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+				return signToken(t, keys, jwt.MapClaims{
 					"user_id": "not_a_number",
 					"exp":     time.Now().Add(time.Hour).Unix(),
 				})
-				signedToken, _ := token.SignedString([]byte(secret))
-				return signedToken
 			},
 			wantError: true,
 			errorMsg:  "user_id not found in token",
@@ -536,23 +531,70 @@ func TestValidateAccessToken(t *testing.T) {
 			name: "token with float user_id",
 			setupToken: func() string {
 				// Токен с user_id как float
-				token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+				return signToken(t, keys, jwt.MapClaims{
 					"user_id": 123.0, // Float вместо int
 					"exp":     time.Now().Add(time.Hour).Unix(),
 				})
-				signedToken, _ := token.SignedString([]byte(secret))
-				return signedToken
 			},
 			wantUserID: 123, // Должно преобразовать float в int
 			wantError:  false,
 		},
+		{
+			name: "token signed with previous key",
+			setupToken: func() string {
+				// после ротации токен предыдущего ключа проверяется по kid до истечения срока
+				previous := newTestKeys(t)
+				previousKey, err := previous.VerificationKey(previous.SigningKeyID())
+				require.NoError(t, err)
+				require.NoError(t, keys.AddVerificationKey(previous.SigningKeyID(), previousKey))
+				token, _ := internalJwt.GenerateAccessToken(321, previous)
+				return token
+			},
+			wantUserID: 321,
+			wantError:  false,
+		},
+		{
+			name: "token without kid",
+			setupToken: func() string {
+				token := signToken(t, keys, jwt.MapClaims{
+					"user_id": 123,
+					"exp":     time.Now().Add(time.Hour).Unix(),
+				})
+				parsed, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
+				require.NoError(t, err)
+				delete(parsed.Header, "kid")
+				signingKey := testSigningKey()
+				unsigned, err := parsed.SignedString(signingKey)
+				require.NoError(t, err)
+				return unsigned
+			},
+			wantError: true,
+			errorMsg:  "invalid token",
+		},
+		{
+			name: "hmac token",
+			setupToken: func() string {
+				// токен HS256 с секретом, равным открытому ключу, не принимается
+				publicKey, err := keys.VerificationKey(keys.SigningKeyID())
+				require.NoError(t, err)
+				token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+					"user_id": 123,
+					"exp":     time.Now().Add(time.Hour).Unix(),
+				})
+				token.Header["kid"] = keys.SigningKeyID()
+				signedToken, _ := token.SignedString([]byte(publicKey))
+				return signedToken
+			},
+			wantError: true,
+			errorMsg:  "invalid token",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			token := tt.setupToken()
 
-			userID, err := validateAccessToken(token, secret)
+			userID, err := validateAccessToken(token, keys)
 
 			if tt.wantError {
 				assert.Error(t, err)
@@ -583,6 +625,13 @@ func Test_isAuthMethod(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "unseal",
+			args: args{
+				fullMethod: "/seal.SealService/Unseal",
+			},
+			want: true,
+		},
 		{
 			name: "test 1",
 			args: args{
@@ -599,3 +648,30 @@ func Test_isAuthMethod(t *testing.T) {
 		})
 	}
 }
+
+// testSigningKey постоянный ключ подписи для тестов
+func testSigningKey() ed25519.PrivateKey {
+	seed := make([]byte, ed25519.SeedSize)
+	copy(seed, "gophkeeper interceptor test key")
+	return ed25519.NewKeyFromSeed(seed)
+}
+
+// newTestKeys набор ключей подписи со случайным ключом
+func newTestKeys(t *testing.T) *internalJwt.KeySet {
+	t.Helper()
+	keys, err := internalJwt.NewEphemeralKeySet()
+	require.NoError(t, err)
+	return keys
+}
+
+// signToken подпись произвольных claims текущим ключом набора
+func signToken(t *testing.T, keys *internalJwt.KeySet, claims jwt.MapClaims) string {
+	t.Helper()
+	signingKey := testSigningKey()
+	require.NoError(t, keys.AddVerificationKey("test-key", signingKey.Public().(ed25519.PublicKey)))
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = "test-key"
+	signedToken, err := token.SignedString(signingKey)
+	require.NoError(t, err)
+	return signedToken
+}
@@ -0,0 +1,140 @@
+package interceptors
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	authModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	"github.com/ramil063/secondgodiplom/internal/security/scope"
+)
+
+// methodScope право, которое нужно персональному токену для вызова метода
+// если задан kind, вместо права на весь раздел подойдет право на объект, ID которого передан в запросе
+type methodScope struct {
+	resource string
+	access   string
+	kind     string
+}
+
+// methodScopes права для методов, доступных персональным токенам
+// остальные методы (учетная запись, сессии, сами персональные токены) вызываются только с токеном из Login
+var methodScopes = map[string]methodScope{
+	"/items.password.Service/CreatePassword":    {resource: scope.ResourcePasswords, access: scope.AccessWrite},
+	"/items.password.Service/ReservePasswordID": {resource: scope.ResourcePasswords, access: scope.AccessWrite},
+	"/items.password.Service/GetPassword":       {resource: scope.ResourcePasswords, access: scope.AccessRead, kind: scope.KindItem},
+	"/items.password.Service/ListPasswords":     {resource: scope.ResourcePasswords, access: scope.AccessRead},
+	"/items.password.Service/UpdatePassword":    {resource: scope.ResourcePasswords, access: scope.AccessWrite, kind: scope.KindItem},
+	"/items.password.Service/DeletePassword":    {resource: scope.ResourcePasswords, access: scope.AccessWrite, kind: scope.KindItem},
+
+	"/items.textdata.Service/CreateTextData":    {resource: scope.ResourceTexts, access: scope.AccessWrite},
+	"/items.textdata.Service/ReserveTextDataID": {resource: scope.ResourceTexts, access: scope.AccessWrite},
+	"/items.textdata.Service/GetTextData":       {resource: scope.ResourceTexts, access: scope.AccessRead, kind: scope.KindItem},
+	"/items.textdata.Service/ListTextDataItems": {resource: scope.ResourceTexts, access: scope.AccessRead},
+	"/items.textdata.Service/UpdateTextData":    {resource: scope.ResourceTexts, access: scope.AccessWrite, kind: scope.KindItem},
+	"/items.textdata.Service/DeleteTextData":    {resource: scope.ResourceTexts, access: scope.AccessWrite, kind: scope.KindItem},
+
+	"/items.bankcard.Service/CreateCardData":    {resource: scope.ResourceCards, access: scope.AccessWrite},
+	"/items.bankcard.Service/ReserveCardDataID": {resource: scope.ResourceCards, access: scope.AccessWrite},
+	"/items.bankcard.Service/GetCardData":       {resource: scope.ResourceCards, access: scope.AccessRead, kind: scope.KindItem},
+	"/items.bankcard.Service/ListCardsData":     {resource: scope.ResourceCards, access: scope.AccessRead},
+	"/items.bankcard.Service/UpdateCardData":    {resource: scope.ResourceCards, access: scope.AccessWrite, kind: scope.KindItem},
+	"/items.bankcard.Service/DeleteCardData":    {resource: scope.ResourceCards, access: scope.AccessWrite, kind: scope.KindItem},
+
+	"/items.binarydata.Service/UploadFile":    {resource: scope.ResourceFiles, access: scope.AccessWrite},
+	"/items.binarydata.Service/ReserveFileID": {resource: scope.ResourceFiles, access: scope.AccessWrite},
+	"/items.binarydata.Service/DownloadFile":  {resource: scope.ResourceFiles, access: scope.AccessRead, kind: scope.KindFile},
+	"/items.binarydata.Service/GetFileInfo":   {resource: scope.ResourceFiles, access: scope.AccessRead, kind: scope.KindFile},
+	"/items.binarydata.Service/ListFiles":     {resource: scope.ResourceFiles, access: scope.AccessRead},
+	"/items.binarydata.Service/DeleteFile":    {resource: scope.ResourceFiles, access: scope.AccessWrite, kind: scope.KindFile},
+}
+
+// itemRequest запрос к одной записи
+type itemRequest interface {
+	GetId() int64
+}
+
+// fileRequest запрос к одному файлу
+type fileRequest interface {
+	GetFileId() int64
+}
+
+// errScopeDenied вызов вне прав персонального токена
+var errScopeDenied = status.Error(codes.PermissionDenied, "personal token scope does not allow this method")
+
+// checkScope проверка прав персонального токена из контекста на вызов метода
+// req - запрос с ID объекта, для потоков проверка по объекту откладывается до получения первого сообщения (req == nil)
+// вызовы с токеном из Login не ограничиваются
+func checkScope(ctx context.Context, fullMethod string, req interface{}) error {
+	personal, ok := ctx.Value("personalToken").(*authModel.PersonalToken)
+	if !ok {
+		return nil
+	}
+	rule, ok := methodScopes[fullMethod]
+	if !ok {
+		return errScopeDenied
+	}
+	if scope.Allows(personal.Scopes, rule.resource, rule.access) {
+		return nil
+	}
+	if rule.kind == "" || req == nil {
+		return errScopeDenied
+	}
+
+	id, ok := objectID(rule.kind, req)
+	if !ok || !scope.AllowsObject(personal.Scopes, rule.kind, id, rule.access) {
+		return errScopeDenied
+	}
+	return nil
+}
+
+// needsObjectCheck права на раздел нет, но метод может быть разрешен правом на объект из запроса
+func needsObjectCheck(ctx context.Context, fullMethod string) bool {
+	personal, ok := ctx.Value("personalToken").(*authModel.PersonalToken)
+	if !ok {
+		return false
+	}
+	rule, ok := methodScopes[fullMethod]
+	return ok && rule.kind != "" && scope.HasObjects(personal.Scopes, rule.kind)
+}
+
+// objectID идентификатор объекта из запроса
+func objectID(kind string, req interface{}) (int64, bool) {
+	switch kind {
+	case scope.KindItem:
+		r, ok := req.(itemRequest)
+		if !ok {
+			return 0, false
+		}
+		return r.GetId(), true
+	case scope.KindFile:
+		r, ok := req.(fileRequest)
+		if !ok {
+			return 0, false
+		}
+		return r.GetFileId(), true
+	}
+	return 0, false
+}
+
+// scopedServerStream поток, права на который проверяются по первому сообщению клиента
+type scopedServerStream struct {
+	grpc.ServerStream
+	fullMethod string
+	once       sync.Once
+	err        error
+}
+
+// RecvMsg получение сообщения с проверкой прав по первому из них
+func (s *scopedServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	s.once.Do(func() {
+		s.err = checkScope(s.Context(), s.fullMethod, m)
+	})
+	return s.err
+}
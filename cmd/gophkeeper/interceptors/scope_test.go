@@ -0,0 +1,237 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	authModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/binarydata"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/password"
+	internalJwt "github.com/ramil063/secondgodiplom/internal/security/jwt"
+)
+
+// personalTokens персональные токены по значению
+type personalTokens map[string]*authModel.PersonalToken
+
+func (p personalTokens) GetPersonalToken(ctx context.Context, token string) (*authModel.PersonalToken, error) {
+	if token == "gkp_broken" {
+		return nil, errors.New("connection refused")
+	}
+	pt, ok := p[token]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "token not found")
+	}
+	return pt, nil
+}
+
+func testPersonalTokens() personalTokens {
+	return personalTokens{
+		"gkp_passwords": {ID: 1, UserID: 7, Scopes: []string{"passwords:read"}},
+		"gkp_item":      {ID: 2, UserID: 7, Scopes: []string{"item:5:read"}},
+		"gkp_file":      {ID: 3, UserID: 7, Scopes: []string{"file:9:read"}},
+	}
+}
+
+func bearerContext(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func TestNewAuthInterceptor_PersonalToken(t *testing.T) {
+	keys := newTestKeys(t)
+	interceptor := NewAuthInterceptor(keys, activeTokens(), testPersonalTokens())
+	accessToken, err := internalJwt.GenerateAccessToken(7, keys)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		token      string
+		fullMethod string
+		req        interface{}
+		wantCode   codes.Code
+	}{
+		{
+			name:       "resource scope",
+			token:      "gkp_passwords",
+			fullMethod: "/items.password.Service/ListPasswords",
+			req:        &password.ListPasswordsRequest{},
+			wantCode:   codes.OK,
+		},
+		{
+			name:       "resource scope allows single item",
+			token:      "gkp_passwords",
+			fullMethod: "/items.password.Service/GetPassword",
+			req:        &password.GetPasswordRequest{Id: 42},
+			wantCode:   codes.OK,
+		},
+		{
+			name:       "read scope does not allow write",
+			token:      "gkp_passwords",
+			fullMethod: "/items.password.Service/DeletePassword",
+			req:        &password.DeletePasswordRequest{Id: 42},
+			wantCode:   codes.PermissionDenied,
+		},
+		{
+			name:       "other resource",
+			token:      "gkp_passwords",
+			fullMethod: "/items.textdata.Service/ListTextDataItems",
+			wantCode:   codes.PermissionDenied,
+		},
+		{
+			name:       "item scope",
+			token:      "gkp_item",
+			fullMethod: "/items.password.Service/GetPassword",
+			req:        &password.GetPasswordRequest{Id: 5},
+			wantCode:   codes.OK,
+		},
+		{
+			name:       "item scope other item",
+			token:      "gkp_item",
+			fullMethod: "/items.password.Service/GetPassword",
+			req:        &password.GetPasswordRequest{Id: 6},
+			wantCode:   codes.PermissionDenied,
+		},
+		{
+			name:       "item scope does not allow list",
+			token:      "gkp_item",
+			fullMethod: "/items.password.Service/ListPasswords",
+			req:        &password.ListPasswordsRequest{},
+			wantCode:   codes.PermissionDenied,
+		},
+		{
+			name:       "method outside personal tokens",
+			token:      "gkp_passwords",
+			fullMethod: "/token.TokenService/CreatePersonalToken",
+			wantCode:   codes.PermissionDenied,
+		},
+		{
+			name:       "access token is not limited",
+			token:      accessToken,
+			fullMethod: "/token.TokenService/CreatePersonalToken",
+			wantCode:   codes.OK,
+		},
+		{
+			name:       "unknown personal token",
+			token:      "gkp_unknown",
+			fullMethod: "/items.password.Service/ListPasswords",
+			wantCode:   codes.Unknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := &grpc.UnaryServerInfo{FullMethod: tt.fullMethod}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				assert.Equal(t, 7, ctx.Value("userID"))
+				return "ok", nil
+			}
+
+			_, err := interceptor(bearerContext(tt.token), tt.req, info, handler)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+func Test_authenticatePersonalToken(t *testing.T) {
+	keys := newTestKeys(t)
+
+	ctx, err := authenticateRequest(bearerContext("gkp_passwords"), keys, activeTokens(), testPersonalTokens())
+	require.NoError(t, err)
+	assert.Equal(t, 7, ctx.Value("userID"))
+	assert.Nil(t, ctx.Value("accessToken"))
+
+	_, err = authenticateRequest(bearerContext("gkp_broken"), keys, activeTokens(), testPersonalTokens())
+	assert.ErrorContains(t, err, "failed to check token")
+
+	// без хранилища персональные токены не принимаются
+	_, err = authenticateRequest(bearerContext("gkp_passwords"), keys, activeTokens(), nil)
+	assert.ErrorContains(t, err, "invalid token")
+}
+
+// downloadStream поток скачивания файла с одним запросом клиента
+type downloadStream struct {
+	MockServerStream
+	fileID int64
+}
+
+func (s *downloadStream) RecvMsg(m interface{}) error {
+	m.(*binarydata.DownloadFileRequest).FileId = s.fileID
+	return nil
+}
+
+func TestNewStreamAuthInterceptor_PersonalToken(t *testing.T) {
+	interceptor := NewStreamAuthInterceptor(newTestKeys(t), activeTokens(), testPersonalTokens())
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		return stream.RecvMsg(&binarydata.DownloadFileRequest{})
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		fullMethod string
+		fileID     int64
+		wantCode   codes.Code
+	}{
+		{
+			name:       "file scope",
+			token:      "gkp_file",
+			fullMethod: "/items.binarydata.Service/DownloadFile",
+			fileID:     9,
+			wantCode:   codes.OK,
+		},
+		{
+			name:       "file scope other file",
+			token:      "gkp_file",
+			fullMethod: "/items.binarydata.Service/DownloadFile",
+			fileID:     10,
+			wantCode:   codes.PermissionDenied,
+		},
+		{
+			name:       "no file scopes",
+			token:      "gkp_passwords",
+			fullMethod: "/items.binarydata.Service/DownloadFile",
+			fileID:     9,
+			wantCode:   codes.PermissionDenied,
+		},
+		{
+			name:       "file scope does not allow upload",
+			token:      "gkp_file",
+			fullMethod: "/items.binarydata.Service/UploadFile",
+			wantCode:   codes.PermissionDenied,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := &downloadStream{MockServerStream: MockServerStream{ctx: bearerContext(tt.token)}, fileID: tt.fileID}
+			info := &grpc.StreamServerInfo{FullMethod: tt.fullMethod}
+
+			err := interceptor(nil, stream, info, handler)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+func Test_methodScopes(t *testing.T) {
+	// у методов с проверкой по объекту в запросе есть ID объекта
+	requests := map[string]interface{}{
+		"/items.password.Service/GetPassword":    &password.GetPasswordRequest{},
+		"/items.binarydata.Service/DownloadFile": &binarydata.DownloadFileRequest{},
+		"/items.binarydata.Service/DeleteFile":   &binarydata.DeleteFileRequest{},
+		"/items.binarydata.Service/GetFileInfo":  &binarydata.GetFileInfoRequest{},
+	}
+	for method, req := range requests {
+		rule, ok := methodScopes[method]
+		require.True(t, ok, method)
+		_, ok = objectID(rule.kind, req)
+		assert.True(t, ok, method)
+	}
+	for method := range methodScopes {
+		assert.False(t, isAuthMethod(method), method)
+	}
+}
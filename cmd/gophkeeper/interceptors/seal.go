@@ -0,0 +1,50 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// sealServicePrefix методы сервиса распечатывания, доступные в запечатанном режиме
+const sealServicePrefix = "/seal.SealService/"
+
+// SealChecker источник признака запечатанного состояния сервера
+type SealChecker interface {
+	Sealed() bool
+}
+
+// SealInterceptors отклонение вызовов, пока сервер запечатан
+type SealInterceptors struct {
+	Unary  grpc.UnaryServerInterceptor
+	Stream grpc.StreamServerInterceptor
+}
+
+// NewSealInterceptors инициализация интерсепторов запечатанного режима
+// пока мастер-ключ не собран, доступен только сервис распечатывания
+func NewSealInterceptors(checker SealChecker) *SealInterceptors {
+	return &SealInterceptors{
+		Unary: func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := checkSealed(checker, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		},
+		Stream: func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := checkSealed(checker, info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		},
+	}
+}
+
+func checkSealed(checker SealChecker, fullMethod string) error {
+	if strings.HasPrefix(fullMethod, sealServicePrefix) || !checker.Sealed() {
+		return nil
+	}
+	return status.Error(codes.FailedPrecondition, "server is sealed")
+}
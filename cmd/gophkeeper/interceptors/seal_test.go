@@ -0,0 +1,68 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type sealState bool
+
+func (s sealState) Sealed() bool {
+	return bool(s)
+}
+
+func TestNewSealInterceptors(t *testing.T) {
+	tests := []struct {
+		name       string
+		sealed     bool
+		fullMethod string
+		wantCode   codes.Code
+	}{
+		{
+			name:       "sealed item call",
+			sealed:     true,
+			fullMethod: "/items.password.Service/GetPassword",
+			wantCode:   codes.FailedPrecondition,
+		},
+		{
+			name:       "sealed login",
+			sealed:     true,
+			fullMethod: "/auth.AuthService/Login",
+			wantCode:   codes.FailedPrecondition,
+		},
+		{
+			name:       "sealed unseal",
+			sealed:     true,
+			fullMethod: "/seal.SealService/Unseal",
+			wantCode:   codes.OK,
+		},
+		{
+			name:       "unsealed item call",
+			sealed:     false,
+			fullMethod: "/items.password.Service/GetPassword",
+			wantCode:   codes.OK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptors := NewSealInterceptors(sealState(tt.sealed))
+
+			_, err := interceptors.Unary(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: tt.fullMethod},
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					return "ok", nil
+				})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+
+			err = interceptors.Stream(nil, &wrappedServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: tt.fullMethod},
+				func(srv interface{}, stream grpc.ServerStream) error {
+					return nil
+				})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package interceptors
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ramil063/secondgodiplom/internal/hash"
+)
+
+const (
+	// DefaultTokenCacheTTL сколько хранится результат проверки токена
+	// отзыв на другом экземпляре сервера вступает в силу не позже, чем через это время
+	DefaultTokenCacheTTL = 30 * time.Second
+	// defaultTokenCacheSize максимальное число токенов в кеше
+	defaultTokenCacheSize = 10000
+)
+
+// TokenChecker проверка токена авторизации в хранилище
+type TokenChecker interface {
+	IsAccessTokenActive(ctx context.Context, accessToken string) (bool, error)
+}
+
+// tokenCacheEntry результат проверки одного токена
+type tokenCacheEntry struct {
+	userID    int
+	active    bool
+	checkedAt time.Time
+}
+
+// TokenCache кеш проверки отзыва токенов авторизации
+// избавляет от запроса в базу на каждый вызов, хранит хеши токенов, а не сами токены
+type TokenCache struct {
+	mu      sync.Mutex
+	checker TokenChecker
+	ttl     time.Duration
+	maxSize int
+	entries map[string]tokenCacheEntry
+	now     func() time.Time
+}
+
+// NewTokenCache инициализация кеша проверки токенов
+func NewTokenCache(checker TokenChecker, ttl time.Duration) *TokenCache {
+	return &TokenCache{
+		checker: checker,
+		ttl:     ttl,
+		maxSize: defaultTokenCacheSize,
+		entries: make(map[string]tokenCacheEntry),
+		now:     time.Now,
+	}
+}
+
+// IsActive проверка, что токен пользователя не отозван
+// результат, в том числе отрицательный, запоминается на время ttl
+func (c *TokenCache) IsActive(ctx context.Context, accessToken string, userID int) (bool, error) {
+	key := hash.GetTokenHash(accessToken)
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && c.now().Sub(entry.checkedAt) < c.ttl {
+		return entry.active, nil
+	}
+
+	active, err := c.checker.IsAccessTokenActive(ctx, accessToken)
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= c.maxSize {
+		c.evictExpired()
+	}
+	c.entries[key] = tokenCacheEntry{
+		userID:    userID,
+		active:    active,
+		checkedAt: c.now(),
+	}
+	return active, nil
+}
+
+// Invalidate отметка токена отозванным, вызывается при выходе
+func (c *TokenCache) Invalidate(accessToken string, userID int) {
+	c.InvalidateHash(hash.GetTokenHash(accessToken), userID)
+}
+
+// InvalidateHash отметка отозванным токена по его хешу, вызывается при завершении одной сессии
+// остальные токены пользователя, в том числе токен вызывающего, не трогаются
+func (c *TokenCache) InvalidateHash(tokenHash string, userID int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[tokenHash] = tokenCacheEntry{
+		userID:    userID,
+		checkedAt: c.now(),
+	}
+}
+
+// InvalidateUser сброс результатов проверки всех токенов пользователя после отзыва части его сессий
+// записи удаляются, а не отмечаются отозванными: следующая проверка идет в хранилище,
+// поэтому оставленные сессии (например, текущая при смене пароля) продолжают работать
+func (c *TokenCache) InvalidateUser(userID int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, entry := range c.entries {
+		if entry.userID == userID {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// evictExpired удаление устаревших записей, если кеш все равно полон - он очищается
+func (c *TokenCache) evictExpired() {
+	now := c.now()
+	for key, entry := range c.entries {
+		if now.Sub(entry.checkedAt) >= c.ttl {
+			delete(c.entries, key)
+		}
+	}
+	if len(c.entries) >= c.maxSize {
+		c.entries = make(map[string]tokenCacheEntry)
+	}
+}
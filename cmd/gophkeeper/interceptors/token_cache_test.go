@@ -0,0 +1,169 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/ramil063/secondgodiplom/internal/hash"
+	internalJwt "github.com/ramil063/secondgodiplom/internal/security/jwt"
+)
+
+// tokenChecker заглушка хранилища токенов, считает обращения
+type tokenChecker struct {
+	active map[string]bool
+	err    error
+	calls  int
+}
+
+func (c *tokenChecker) IsAccessTokenActive(ctx context.Context, accessToken string) (bool, error) {
+	c.calls++
+	return c.active[accessToken], c.err
+}
+
+// allTokensActive хранилище, в котором активен любой токен
+type allTokensActive struct{}
+
+func (allTokensActive) IsAccessTokenActive(ctx context.Context, accessToken string) (bool, error) {
+	return true, nil
+}
+
+func activeTokens() *TokenCache {
+	return NewTokenCache(allTokensActive{}, DefaultTokenCacheTTL)
+}
+
+func TestTokenCache_IsActive(t *testing.T) {
+	checker := &tokenChecker{active: map[string]bool{"active": true}}
+	cache := NewTokenCache(checker, time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	active, err := cache.IsActive(context.Background(), "active", 1)
+	require.NoError(t, err)
+	assert.True(t, active)
+	active, err = cache.IsActive(context.Background(), "unknown", 1)
+	require.NoError(t, err)
+	assert.False(t, active)
+	assert.Equal(t, 2, checker.calls)
+
+	// Повторные проверки берутся из кеша, в том числе отрицательные
+	_, _ = cache.IsActive(context.Background(), "active", 1)
+	_, _ = cache.IsActive(context.Background(), "unknown", 1)
+	assert.Equal(t, 2, checker.calls)
+
+	// После ttl токен проверяется заново
+	now = now.Add(time.Minute)
+	checker.active["active"] = false
+	active, err = cache.IsActive(context.Background(), "active", 1)
+	require.NoError(t, err)
+	assert.False(t, active)
+	assert.Equal(t, 3, checker.calls)
+}
+
+func TestTokenCache_Invalidate(t *testing.T) {
+	checker := &tokenChecker{active: map[string]bool{"first": true, "second": true, "other": true}}
+	cache := NewTokenCache(checker, time.Minute)
+
+	for _, token := range []string{"first", "second"} {
+		active, err := cache.IsActive(context.Background(), token, 1)
+		require.NoError(t, err)
+		require.True(t, active)
+	}
+	active, err := cache.IsActive(context.Background(), "other", 2)
+	require.NoError(t, err)
+	require.True(t, active)
+
+	cache.Invalidate("first", 1)
+	active, _ = cache.IsActive(context.Background(), "first", 1)
+	assert.False(t, active)
+	active, _ = cache.IsActive(context.Background(), "second", 1)
+	assert.True(t, active)
+
+	// сброс пользователя заново проверяет его токены в хранилище, токены других пользователей не трогаются
+	checker.active["second"] = false
+	cache.InvalidateUser(1)
+	active, _ = cache.IsActive(context.Background(), "second", 1)
+	assert.False(t, active)
+	active, _ = cache.IsActive(context.Background(), "other", 2)
+	assert.True(t, active)
+	assert.Equal(t, 4, checker.calls)
+}
+
+func TestTokenCache_InvalidateHash(t *testing.T) {
+	checker := &tokenChecker{active: map[string]bool{"current": true, "other": true}}
+	cache := NewTokenCache(checker, time.Minute)
+	for _, token := range []string{"current", "other"} {
+		_, err := cache.IsActive(context.Background(), token, 1)
+		require.NoError(t, err)
+	}
+
+	cache.InvalidateHash(hash.GetTokenHash("other"), 1)
+	active, _ := cache.IsActive(context.Background(), "other", 1)
+	assert.False(t, active)
+	active, _ = cache.IsActive(context.Background(), "current", 1)
+	assert.True(t, active)
+	assert.Equal(t, 2, checker.calls)
+}
+
+func TestTokenCache_InvalidateUser_KeepsActiveTokens(t *testing.T) {
+	checker := &tokenChecker{active: map[string]bool{"current": true, "other": true}}
+	cache := NewTokenCache(checker, time.Minute)
+	for _, token := range []string{"current", "other"} {
+		_, err := cache.IsActive(context.Background(), token, 1)
+		require.NoError(t, err)
+	}
+
+	// в хранилище отозвана только другая сессия
+	checker.active["other"] = false
+	cache.InvalidateUser(1)
+
+	active, err := cache.IsActive(context.Background(), "current", 1)
+	require.NoError(t, err)
+	assert.True(t, active)
+	active, err = cache.IsActive(context.Background(), "other", 1)
+	require.NoError(t, err)
+	assert.False(t, active)
+}
+
+func TestTokenCache_Eviction(t *testing.T) {
+	checker := &tokenChecker{active: map[string]bool{}}
+	cache := NewTokenCache(checker, time.Minute)
+	cache.maxSize = 2
+
+	for _, token := range []string{"a", "b", "c"} {
+		_, err := cache.IsActive(context.Background(), token, 1)
+		require.NoError(t, err)
+	}
+	assert.LessOrEqual(t, len(cache.entries), 2)
+}
+
+func TestTokenCache_CheckerError(t *testing.T) {
+	checker := &tokenChecker{err: errors.New("connection refused")}
+	cache := NewTokenCache(checker, time.Minute)
+
+	_, err := cache.IsActive(context.Background(), "token", 1)
+	assert.Error(t, err)
+	_, err = cache.IsActive(context.Background(), "token", 1)
+	assert.Error(t, err)
+	assert.Equal(t, 2, checker.calls, "ошибки не кешируются")
+}
+
+func Test_authenticateRequest_RevokedToken(t *testing.T) {
+	keys := newTestKeys(t)
+	token, err := internalJwt.GenerateAccessToken(123, keys)
+	require.NoError(t, err)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+
+	// Подпись верна, но токен отозван или неизвестен серверу
+	_, err = authenticateRequest(ctx, keys, NewTokenCache(&tokenChecker{}, time.Minute), nil)
+	assert.ErrorContains(t, err, "token revoked")
+
+	newCtx, err := authenticateRequest(ctx, keys, NewTokenCache(&tokenChecker{active: map[string]bool{token: true}}, time.Minute), nil)
+	require.NoError(t, err)
+	assert.Equal(t, token, newCtx.Value("accessToken"))
+}
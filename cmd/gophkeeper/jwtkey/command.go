@@ -0,0 +1,63 @@
+package jwtkey
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/ramil063/secondgodiplom/internal/security/jwt"
+)
+
+// CommandName название подкоманды сервера
+const CommandName = "jwt-key"
+
+// options параметры подкоманды
+type options struct {
+	public bool
+}
+
+func parseOptions(args []string, out io.Writer) (*options, error) {
+	var opts options
+
+	flags := flag.NewFlagSet(CommandName, flag.ContinueOnError)
+	flags.SetOutput(out)
+	flags.BoolVar(&opts.public, "public", false, "прочитать seed ключа из стандартного ввода и напечатать только открытый ключ")
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	return &opts, nil
+}
+
+// Run генерация ключа подписи или вывод открытого ключа существующего
+// открытый ключ текущего ключа переносится в previous_keys при ротации
+func Run(args []string, in io.Reader, out io.Writer) error {
+	opts, err := parseOptions(args, out)
+	if err != nil {
+		return err
+	}
+
+	var signingKey ed25519.PrivateKey
+	if opts.public {
+		seed, err := io.ReadAll(in)
+		if err != nil {
+			return fmt.Errorf("failed to read key: %w", err)
+		}
+		if signingKey, err = jwt.ParsePrivateKey(seed); err != nil {
+			return err
+		}
+	} else {
+		if _, signingKey, err = ed25519.GenerateKey(rand.Reader); err != nil {
+			return fmt.Errorf("failed to generate key: %w", err)
+		}
+		fmt.Fprintf(out, "key: %s\n", base64.StdEncoding.EncodeToString(signingKey.Seed()))
+	}
+
+	publicKey := signingKey.Public().(ed25519.PublicKey)
+	fmt.Fprintf(out, "public_key: %s\n", base64.StdEncoding.EncodeToString(publicKey))
+	fmt.Fprintf(out, "key_id: %s\n", jwt.KeyID(publicKey))
+	return nil
+}
@@ -0,0 +1,48 @@
+package jwtkey
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ramil063/secondgodiplom/internal/security/jwt"
+)
+
+// parseOutput значения из вывода подкоманды по названиям полей
+func parseOutput(output string) map[string]string {
+	values := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		name, value, ok := strings.Cut(line, ": ")
+		if ok {
+			values[name] = value
+		}
+	}
+	return values
+}
+
+func TestRun(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, Run(nil, strings.NewReader(""), &out))
+	generated := parseOutput(out.String())
+
+	signingKey, err := jwt.ParsePrivateKey([]byte(generated["key"]))
+	require.NoError(t, err)
+	publicKey := signingKey.Public().(ed25519.PublicKey)
+	assert.Equal(t, base64.StdEncoding.EncodeToString(publicKey), generated["public_key"])
+	assert.Equal(t, jwt.KeyID(publicKey), generated["key_id"])
+
+	// для ротации открытый ключ печатается по seed текущего ключа
+	out.Reset()
+	require.NoError(t, Run([]string{"-public"}, strings.NewReader(generated["key"]+"\n"), &out))
+	public := parseOutput(out.String())
+	assert.Empty(t, public["key"])
+	assert.Equal(t, generated["public_key"], public["public_key"])
+	assert.Equal(t, generated["key_id"], public["key_id"])
+
+	assert.Error(t, Run([]string{"-public"}, strings.NewReader("not a key"), &out))
+}
@@ -0,0 +1,4 @@
+// Package jwtkey подготовка ключа подписи токенов доступа Ed25519
+// - генерируется новый ключ или читается seed существующего из стандартного ввода
+// - печатаются seed для секции jwt_signing и открытый ключ с идентификатором для previous_keys
+package jwtkey
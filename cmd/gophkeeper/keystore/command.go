@@ -0,0 +1,75 @@
+package keystore
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/keyprovider"
+)
+
+// CommandName название подкоманды сервера
+const CommandName = "create-keystore"
+
+// options параметры подкоманды
+type options struct {
+	out           string
+	passphraseEnv string
+}
+
+func parseOptions(args []string, out io.Writer) (*options, error) {
+	var opts options
+
+	flags := flag.NewFlagSet(CommandName, flag.ContinueOnError)
+	flags.SetOutput(out)
+	flags.StringVar(&opts.out, "out", "", "путь к файлу хранилища")
+	flags.StringVar(&opts.passphraseEnv, "passphrase-env", keyprovider.DefaultPassphraseEnv, "переменная окружения с паролем хранилища")
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if opts.out == "" {
+		return nil, errors.New("out is required")
+	}
+	return &opts, nil
+}
+
+// readKey чтение ключа из входного потока, перевод строки в конце отбрасывается
+func readKey(in io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	key := bytes.TrimSpace(data)
+	if len(key) == 0 {
+		return nil, keyprovider.ErrEmptyKey
+	}
+	return key, nil
+}
+
+// Run создание хранилища мастер-ключа
+func Run(args []string, in io.Reader, out io.Writer) error {
+	opts, err := parseOptions(args, out)
+	if err != nil {
+		return err
+	}
+
+	passphrase := os.Getenv(opts.passphraseEnv)
+	if passphrase == "" {
+		return fmt.Errorf("%w: set %s", keyprovider.ErrEmptyPassphrase, opts.passphraseEnv)
+	}
+
+	key, err := readKey(in)
+	if err != nil {
+		return err
+	}
+	if err = keyprovider.WriteKeystore(opts.out, key, passphrase); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Хранилище ключа сохранено: %s\n", opts.out)
+	return nil
+}
@@ -0,0 +1,64 @@
+package keystore
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/keyprovider"
+)
+
+func TestRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "master.keystore")
+	t.Setenv("GOPHKEEPER_TEST_PASSPHRASE", "secret")
+
+	tests := []struct {
+		name    string
+		args    []string
+		in      string
+		wantErr bool
+	}{
+		{
+			name: "key from stdin",
+			args: []string{"-out", path, "-passphrase-env", "GOPHKEEPER_TEST_PASSPHRASE"},
+			in:   "123456789012345678901234\n",
+		},
+		{
+			name:    "no out",
+			args:    []string{"-passphrase-env", "GOPHKEEPER_TEST_PASSPHRASE"},
+			in:      "123456789012345678901234\n",
+			wantErr: true,
+		},
+		{
+			name:    "no passphrase",
+			args:    []string{"-out", path, "-passphrase-env", "GOPHKEEPER_TEST_MISSING"},
+			in:      "123456789012345678901234\n",
+			wantErr: true,
+		},
+		{
+			name:    "empty key",
+			args:    []string{"-out", path, "-passphrase-env", "GOPHKEEPER_TEST_PASSPHRASE"},
+			in:      "\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Run(tt.args, strings.NewReader(tt.in), &bytes.Buffer{})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			key, err := (&keyprovider.KeystoreProvider{Path: path, Passphrase: "secret"}).GetKey(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, []byte("123456789012345678901234"), key)
+		})
+	}
+}
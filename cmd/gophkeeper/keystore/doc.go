@@ -0,0 +1,4 @@
+// Package keystore создание файла хранилища мастер-ключа, защищенного паролем
+// - ключ читается из стандартного ввода
+// - пароль берется из переменной окружения
+package keystore
@@ -8,7 +8,13 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/jwtkey"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/keystore"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/migrate"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/rotation"
 	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/server"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/splitkey"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/unseal"
 	"github.com/ramil063/secondgodiplom/internal/logger"
 )
 
@@ -17,17 +23,87 @@ func main() {
 	ctxGrSh, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
 	defer stop()
 
+	// подкоманда управления миграциями схемы бд
+	if len(os.Args) > 1 && os.Args[1] == migrate.CommandName {
+		if err := migrate.Run(ctxGrSh, os.Args[2:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// подкоманда ротации мастер-ключа, сервер при этом не запускается
+	if len(os.Args) > 1 && os.Args[1] == rotation.CommandName {
+		if err := rotation.Run(ctxGrSh, os.Args[2:], os.Stdin, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// подкоманда создания хранилища мастер-ключа, защищенного паролем
+	if len(os.Args) > 1 && os.Args[1] == keystore.CommandName {
+		if err := keystore.Run(os.Args[2:], os.Stdin, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// подкоманда разделения мастер-ключа на доли для запечатанного режима
+	if len(os.Args) > 1 && os.Args[1] == splitkey.CommandName {
+		if err := splitkey.Run(os.Args[2:], os.Stdin, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// подкоманда генерации ключа подписи токенов доступа
+	if len(os.Args) > 1 && os.Args[1] == jwtkey.CommandName {
+		if err := jwtkey.Run(os.Args[2:], os.Stdin, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// подкоманда передачи доли мастер-ключа запечатанному серверу
+	if len(os.Args) > 1 && os.Args[1] == unseal.CommandName {
+		if err := unseal.Run(ctxGrSh, os.Args[2:], os.Stdin, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// без подключения к бд или со схемой бд новее миграций сервера сервер не запускается
 	config, grpcStorage, manager, err := server.PrepareServerEnvironment()
 	if err != nil {
 		logger.WriteErrorLog(err.Error())
+		log.Fatal(err)
 	}
 
-	grpcServer, lis, err := server.GetGRPCServer(config)
+	sealer := server.PrepareSealer(config, manager)
+	tokens := server.NewTokenCache(grpcStorage)
+
+	// без ключей подписи токены не выдаются и не проверяются
+	jwtKeys, err := server.PrepareJWTKeys(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	grpcServer, lis, err := server.GetGRPCServer(
+		config,
+		sealer,
+		tokens,
+		jwtKeys,
+		server.NewPersonalTokenStorage(grpcStorage),
+		server.NewAuditStorage(grpcStorage),
+	)
 	if err != nil {
 		logger.WriteErrorLog(err.Error())
 	}
 
-	server.RegisterServiceServers(grpcServer, grpcStorage, config, manager)
+	server.RegisterServiceServers(grpcServer, grpcStorage, config, manager, sealer, tokens, jwtKeys)
+	server.StartAccountPurge(ctxGrSh, grpcStorage)
+	if sealer != nil {
+		fmt.Println("Server is sealed, waiting for unseal key shares")
+	}
 
 	// через этот канал сообщим основному потоку, что соединения закрыты
 	idleConnectsClosed := make(chan struct{})
@@ -54,6 +130,6 @@ func main() {
 
 	// ждём завершения процедуры graceful shutdown
 	<-idleConnectsClosed
-	grpcStorage.GetRepository().Pool.Close()
+	grpcStorage.Close()
 	fmt.Println("Server Shutdown gracefully")
 }
@@ -0,0 +1,136 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	serverConfig "github.com/ramil063/secondgodiplom/cmd/gophkeeper/config"
+	"github.com/ramil063/secondgodiplom/internal/storage/db"
+	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
+	"github.com/ramil063/secondgodiplom/internal/storage/db/migrations"
+)
+
+// CommandName название подкоманды сервера
+const CommandName = "migrate"
+
+// действия подкоманды
+const (
+	actionUp     = "up"
+	actionDown   = "down"
+	actionStatus = "status"
+)
+
+// options параметры подкоманды
+type options struct {
+	action string
+	steps  int
+}
+
+func parseOptions(args []string, out io.Writer) (*options, error) {
+	if len(args) == 0 {
+		return nil, errors.New("action is required: up, down or status")
+	}
+	opts := options{action: args[0]}
+
+	flags := flag.NewFlagSet(CommandName+" "+opts.action, flag.ContinueOnError)
+	flags.SetOutput(out)
+	switch opts.action {
+	case actionUp, actionStatus:
+	case actionDown:
+		flags.IntVar(&opts.steps, "steps", 1, "количество откатываемых миграций")
+	default:
+		return nil, fmt.Errorf("unknown action %s, expected up, down or status", opts.action)
+	}
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return nil, err
+	}
+	if opts.action == actionDown && opts.steps < 1 {
+		return nil, errors.New("steps must be positive")
+	}
+	return &opts, nil
+}
+
+// Run выполнение действия с миграциями
+// подключение к бд берется из конфигурации сервера
+func Run(ctx context.Context, args []string, out io.Writer) error {
+	opts, err := parseOptions(args, out)
+	if err != nil {
+		return err
+	}
+
+	config, err := serverConfig.GetConfig()
+	if err != nil {
+		return err
+	}
+	if config.DatabaseURI == "" {
+		return errors.New("database_uri is not set in config")
+	}
+	// схема встроенной бд обновляется при ее открытии, откат миграций для нее не поддерживается
+	if config.Driver() != serverConfig.StorageDriverPostgres {
+		return fmt.Errorf("migrations are only managed for storage_driver %q", serverConfig.StorageDriverPostgres)
+	}
+
+	rep, err := repository.NewRepository(config)
+	if err != nil {
+		return err
+	}
+	defer rep.Pool.Close()
+
+	if err = db.CheckPing(*rep); err != nil {
+		return fmt.Errorf("ping db: %w", err)
+	}
+
+	migrator, err := migrations.NewMigrator(*rep)
+	if err != nil {
+		return err
+	}
+
+	switch opts.action {
+	case actionUp:
+		applied, err := migrator.Up(ctx)
+		printMigrations(out, "Применена миграция", applied)
+		if err == nil && len(applied) == 0 {
+			fmt.Fprintf(out, "Схема бд актуальна, версия %d\n", migrator.Latest())
+		}
+		return err
+	case actionDown:
+		reverted, err := migrator.Down(ctx, opts.steps)
+		printMigrations(out, "Откачена миграция", reverted)
+		return err
+	default:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return printStatus(out, statuses, migrator.Latest())
+	}
+}
+
+// printMigrations вывод примененных или откаченных миграций
+func printMigrations(out io.Writer, prefix string, list []migrations.Migration) {
+	for _, migration := range list {
+		fmt.Fprintf(out, "%s %04d_%s\n", prefix, migration.Version, migration.Name)
+	}
+}
+
+// printStatus вывод таблицы миграций, версии новее известных серверу отмечаются отдельно
+func printStatus(out io.Writer, statuses []migrations.Status, latest int) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "не применена"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		if status.Version > latest {
+			appliedAt += " (неизвестна серверу)"
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,89 @@
+package migrate
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ramil063/secondgodiplom/internal/storage/db/migrations"
+)
+
+func TestParseOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    *options
+		wantErr bool
+	}{
+		{
+			name: "up",
+			args: []string{"up"},
+			want: &options{action: actionUp},
+		},
+		{
+			name: "down by default one step",
+			args: []string{"down"},
+			want: &options{action: actionDown, steps: 1},
+		},
+		{
+			name: "down several steps",
+			args: []string{"down", "-steps", "3"},
+			want: &options{action: actionDown, steps: 3},
+		},
+		{
+			name: "status",
+			args: []string{"status"},
+			want: &options{action: actionStatus},
+		},
+		{
+			name:    "no action",
+			wantErr: true,
+		},
+		{
+			name:    "unknown action",
+			args:    []string{"redo"},
+			wantErr: true,
+		},
+		{
+			name:    "steps only for down",
+			args:    []string{"up", "-steps", "2"},
+			wantErr: true,
+		},
+		{
+			name:    "zero steps",
+			args:    []string{"down", "-steps", "0"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseOptions(tt.args, io.Discard)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPrintStatus(t *testing.T) {
+	appliedAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	statuses := []migrations.Status{
+		{Migration: migrations.Migration{Version: 1, Name: "initial"}, AppliedAt: &appliedAt},
+		{Migration: migrations.Migration{Version: 2, Name: "add_index"}},
+		{Migration: migrations.Migration{Version: 3, Name: "from_newer_server"}, AppliedAt: &appliedAt},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, printStatus(&out, statuses, 2))
+	assert.Equal(t, "VERSION  NAME               APPLIED AT\n"+
+		"0001     initial            2026-10-18 12:00:00\n"+
+		"0002     add_index          не применена\n"+
+		"0003     from_newer_server  2026-10-18 12:00:00 (неизвестна серверу)\n", out.String())
+}
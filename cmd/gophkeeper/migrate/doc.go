@@ -0,0 +1,5 @@
+// Package migrate управление миграциями схемы бд
+// - up применяет все новые миграции
+// - down откатывает последние примененные миграции
+// - status показывает версии схемы и даты их применения
+package migrate
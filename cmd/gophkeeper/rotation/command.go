@@ -0,0 +1,193 @@
+package rotation
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	serverConfig "github.com/ramil063/secondgodiplom/cmd/gophkeeper/config"
+	localStorage "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto"
+)
+
+// CommandName название подкоманды сервера
+const CommandName = "rotate-key"
+
+const defaultBatchSize = 100
+
+// keyProviderTimeout время на получение ключа из внешнего источника (например, Vault)
+const keyProviderTimeout = 30 * time.Second
+
+// options параметры подкоманды
+// сами ключи в аргументах не передаются, чтобы не попасть в список процессов и историю оболочки
+type options struct {
+	oldVersion int
+	newVersion int
+	keysStdin  bool
+	algorithm  string
+	batchSize  int
+}
+
+func parseOptions(args []string, out io.Writer) (*options, error) {
+	var opts options
+
+	flags := flag.NewFlagSet(CommandName, flag.ContinueOnError)
+	flags.SetOutput(out)
+	flags.IntVar(&opts.oldVersion, "old-version", crypto.DefaultKeyVersion, "версия текущего мастер-ключа")
+	flags.IntVar(&opts.newVersion, "new-version", 0,
+		"версия нового мастер-ключа (по умолчанию crypto_key_version, с -keys-stdin - old-version+1)")
+	flags.BoolVar(&opts.keysStdin, "keys-stdin", false,
+		"читать старый и новый ключ из стандартного ввода (по строке) вместо источников ключей конфигурации")
+	flags.StringVar(&opts.algorithm, "algorithm", crypto.DefaultAlgorithm, "алгоритм шифрования перешифрованных данных")
+	flags.IntVar(&opts.batchSize, "batch-size", defaultBatchSize, "количество записей в одной пачке")
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if opts.keysStdin && opts.newVersion == 0 {
+		opts.newVersion = opts.oldVersion + 1
+	}
+	if opts.newVersion == opts.oldVersion {
+		return nil, errors.New("new-version must differ from old-version")
+	}
+	if _, err := crypto.GetAlgorithm(opts.algorithm); err != nil {
+		return nil, err
+	}
+	if opts.batchSize < 1 {
+		opts.batchSize = defaultBatchSize
+	}
+	return &opts, nil
+}
+
+// masterKeys старый и новый мастер-ключ ротации
+type masterKeys struct {
+	oldKey []byte
+	newKey []byte
+}
+
+// readKeys чтение ключей из входного потока: первая строка - старый ключ, вторая - новый
+func readKeys(in io.Reader) (*masterKeys, error) {
+	var lines [][]byte
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() && len(lines) < 2 {
+		if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+			lines = append(lines, append([]byte(nil), line...))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read keys: %w", err)
+	}
+	if len(lines) < 2 {
+		return nil, errors.New("old and new keys are required on stdin, one per line")
+	}
+	return &masterKeys{oldKey: lines[0], newKey: lines[1]}, nil
+}
+
+// loadKeys загрузка ключей из источников конфигурации сервера, как при запуске сервера:
+// новый ключ - текущий (crypto_key_provider или crypto_key), старый - из previous_crypto_keys
+func loadKeys(ctx context.Context, opts *options, config *serverConfig.ServerConfig) (*masterKeys, error) {
+	if opts.newVersion == 0 {
+		opts.newVersion = config.CryptoKeyVersion
+	}
+	if opts.newVersion != config.CryptoKeyVersion {
+		return nil, fmt.Errorf("new-version %d does not match crypto_key_version %d", opts.newVersion, config.CryptoKeyVersion)
+	}
+	if opts.newVersion == opts.oldVersion {
+		return nil, errors.New("new-version must differ from old-version")
+	}
+
+	newProvider, err := config.MasterKeyProvider()
+	if err != nil {
+		return nil, err
+	}
+	if newProvider == nil {
+		return nil, errors.New("current master key is not set in config, use -keys-stdin")
+	}
+	var oldProvider crypto.KeyProvider
+	for _, previousKey := range config.PreviousCryptoKeys {
+		if previousKey.Version != opts.oldVersion {
+			continue
+		}
+		if oldProvider, err = previousKey.KeyProvider(); err != nil {
+			return nil, err
+		}
+	}
+	if oldProvider == nil {
+		return nil, fmt.Errorf("previous_crypto_keys has no key version %d", opts.oldVersion)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, keyProviderTimeout)
+	defer cancel()
+
+	var keys masterKeys
+	if keys.oldKey, err = oldProvider.GetKey(ctx); err != nil {
+		return nil, fmt.Errorf("failed to get key version %d: %w", opts.oldVersion, err)
+	}
+	if keys.newKey, err = newProvider.GetKey(ctx); err != nil {
+		return nil, fmt.Errorf("failed to get key version %d: %w", opts.newVersion, err)
+	}
+	return &keys, nil
+}
+
+// prepareManager связка из старого и нового ключа, новый ключ текущий
+func prepareManager(opts *options, keys *masterKeys) (*crypto.Manager, error) {
+	manager := crypto.NewCryptoManager()
+	if err := manager.SetAlgorithm(opts.algorithm); err != nil {
+		return nil, err
+	}
+	if err := manager.AddMasterKey(opts.oldVersion, keys.oldKey); err != nil {
+		return nil, err
+	}
+	if err := manager.AddMasterKey(opts.newVersion, keys.newKey); err != nil {
+		return nil, err
+	}
+	if err := manager.UseMasterKey(opts.newVersion); err != nil {
+		return nil, err
+	}
+	return manager, nil
+}
+
+// Run запуск ротации мастер-ключа
+// подключение к бд и ключи берутся из конфигурации сервера, с -keys-stdin ключи читаются из in
+func Run(ctx context.Context, args []string, in io.Reader, out io.Writer) error {
+	opts, err := parseOptions(args, out)
+	if err != nil {
+		return err
+	}
+
+	config, err := serverConfig.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	var keys *masterKeys
+	if opts.keysStdin {
+		keys, err = readKeys(in)
+	} else {
+		keys, err = loadKeys(ctx, opts, config)
+	}
+	if err != nil {
+		return err
+	}
+	manager, err := prepareManager(opts, keys)
+	if err != nil {
+		return err
+	}
+
+	storage, err := localStorage.NewDBStorage(ctx, config)
+	if err != nil {
+		return fmt.Errorf("init db: %w", err)
+	}
+	defer storage.Close()
+	manager.SetKeyStore(storage.DataKeys())
+
+	fmt.Fprintf(out, "Ротация мастер-ключа: версия %d -> %d\n", opts.oldVersion, opts.newVersion)
+	rotation := NewRotation(storage.Rotation(), manager, opts.oldVersion, opts.batchSize, out)
+	return rotation.Rotate(ctx)
+}
@@ -0,0 +1,5 @@
+// Package rotation ротация мастер-ключа сервера
+// - перешифрование ключей пользователей новым мастер-ключом
+// - перешифрование записей и частей файлов пачками
+// - продолжение прерванной ротации с того же места
+package rotation
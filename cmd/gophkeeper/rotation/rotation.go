@@ -0,0 +1,186 @@
+package rotation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage"
+	rotationModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/rotation"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto"
+)
+
+// maxRowRetries сколько раз перечитывается запись, измененная сервером во время ротации
+const maxRowRetries = 3
+
+// Rotation перешифрование данных со старого мастер-ключа на новый
+// обработанные записи получают новую версию ключа, поэтому повторный запуск продолжает с необработанных
+type Rotation struct {
+	storage    storage.Rotator
+	manager    *crypto.Manager
+	oldVersion int
+	batchSize  int
+	out        io.Writer
+}
+
+// NewRotation инициализация ротации
+// в manager должны быть загружены оба ключа, новый ключ должен быть текущим
+func NewRotation(
+	storage storage.Rotator,
+	manager *crypto.Manager,
+	oldVersion int,
+	batchSize int,
+	out io.Writer,
+) *Rotation {
+	return &Rotation{
+		storage:    storage,
+		manager:    manager,
+		oldVersion: oldVersion,
+		batchSize:  batchSize,
+		out:        out,
+	}
+}
+
+// Rotate перешифрование всех таблиц
+// записи, которые не удалось перешифровать, пропускаются, по итогу возвращается ошибка
+func (r *Rotation) Rotate(ctx context.Context) error {
+	var failed int64
+	for _, table := range rotationModel.Tables {
+		tableFailed, err := r.rotateTable(ctx, table)
+		if err != nil {
+			return err
+		}
+		failed += tableFailed
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to rotate %d rows, run the command again to retry", failed)
+	}
+	fmt.Fprintln(r.out, "Ротация ключа завершена")
+	return nil
+}
+
+func (r *Rotation) rotateTable(ctx context.Context, table string) (int64, error) {
+	total, err := r.storage.CountRows(ctx, table, r.oldVersion)
+	if err != nil {
+		return 0, err
+	}
+
+	var processed, failed int64
+	var afterID int64
+	for {
+		select {
+		case <-ctx.Done():
+			return failed, ctx.Err()
+		default:
+		}
+
+		rows, err := r.storage.GetRows(ctx, table, r.oldVersion, afterID, r.batchSize)
+		if err != nil {
+			return failed, err
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		for _, row := range rows {
+			afterID = row.ID
+			if err = r.rotateChangedRow(ctx, table, row); err != nil {
+				fmt.Fprintf(r.out, "\n%s: запись %d не перешифрована: %v\n", table, row.ID, err)
+				failed++
+				continue
+			}
+			processed++
+		}
+		fmt.Fprintf(r.out, "\r%s: %d/%d", table, processed, total)
+	}
+	fmt.Fprintf(r.out, "\r%s: %d/%d\n", table, processed, total)
+	return failed, nil
+}
+
+// rotateChangedRow перешифрование записи с повтором, если сервер изменил ее между чтением и сохранением
+// запись перечитывается; если сервер уже сохранил ее новым ключом, перешифровывать нечего
+func (r *Rotation) rotateChangedRow(ctx context.Context, table string, row *rotationModel.Row) error {
+	for attempt := 0; ; attempt++ {
+		err := r.rotateRow(ctx, table, row)
+		if !errors.Is(err, rotationModel.ErrRowChanged) || attempt == maxRowRetries {
+			return err
+		}
+
+		rows, err := r.storage.GetRows(ctx, table, r.oldVersion, row.ID-1, 1)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 || rows[0].ID != row.ID {
+			return nil
+		}
+		row = rows[0]
+	}
+}
+
+// rotateRow перешифрование одной записи
+// ключи пользователей перешифровываются мастер-ключом, остальные данные ключом пользователя
+func (r *Rotation) rotateRow(ctx context.Context, table string, row *rotationModel.Row) error {
+	var decryptor crypto.Decryptor
+	var encryptor crypto.Encryptor
+	var err error
+
+	if table == rotationModel.TableDataKey {
+		decryptor, err = r.manager.GetMasterDecryptor(r.oldVersion)
+		if err != nil {
+			return err
+		}
+		encryptor = r.manager.GetGRPCEncryptor()
+	} else {
+		userCtx := context.WithValue(ctx, "userID", row.UserID)
+		decryptor, err = r.manager.GetDecryptor(userCtx, r.oldVersion)
+		if err != nil {
+			return err
+		}
+		encryptor, err = r.manager.GetEncryptor(userCtx)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Записи без привязки к владельцу заодно получают связанные данные
+	oldIV := row.IV
+	data, err := decryptor.Decrypt(row.Data, row.IV, row.EncryptionAlgorithm, storedRowAAD(table, row))
+	if err != nil {
+		return err
+	}
+	row.Data, row.EncryptionAlgorithm, row.IV, err = encryptor.Encrypt(data, rowAAD(table, row))
+	if err != nil {
+		return err
+	}
+	row.KeyVersion = r.manager.KeyVersion()
+	row.AADBound = true
+	row.StreamBound = table == rotationModel.TableChunk
+
+	return r.storage.UpdateRow(ctx, table, row, r.oldVersion, oldIV)
+}
+
+// rowAAD связанные данные записи, те же, что использует сервер при сохранении
+func rowAAD(table string, row *rotationModel.Row) []byte {
+	switch table {
+	case rotationModel.TableDataKey:
+		return crypto.DataKeyAAD(row.UserID)
+	case rotationModel.TableChunk:
+		return crypto.StreamChunkAAD(row.FileID, row.ChunkIndex, row.FinalChunk)
+	default:
+		return crypto.ItemAAD(int64(row.UserID), row.ID, row.ItemType)
+	}
+}
+
+// storedRowAAD связанные данные, с которыми запись зашифрована сейчас
+// записи без привязки зашифрованы без связанных данных, части файлов до STREAM - только с номером части
+func storedRowAAD(table string, row *rotationModel.Row) []byte {
+	if !row.AADBound {
+		return nil
+	}
+	if table == rotationModel.TableChunk && !row.StreamBound {
+		return crypto.ChunkAAD(row.FileID, row.ChunkIndex)
+	}
+	return rowAAD(table, row)
+}
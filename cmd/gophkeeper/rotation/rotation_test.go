@@ -0,0 +1,343 @@
+package rotation
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	serverConfig "github.com/ramil063/secondgodiplom/cmd/gophkeeper/config"
+	storageMock "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/mocks"
+	rotationModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/rotation"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto"
+)
+
+const (
+	testOldKey = "123456789012345678901234"
+	testNewKey = "abcdefghijklmnopqrstuvwx"
+)
+
+func encryptRow(t *testing.T, key string, id int64, data string) *rotationModel.Row {
+	encryptor, err := crypto.NewEncryptor([]byte(key), crypto.AlgorithmLegacyAES)
+	require.NoError(t, err)
+	encryptedData, algorithm, iv, err := encryptor.Encrypt([]byte(data), nil)
+	require.NoError(t, err)
+	return &rotationModel.Row{
+		ID:                  id,
+		UserID:              1,
+		Data:                encryptedData,
+		IV:                  iv,
+		EncryptionAlgorithm: algorithm,
+		KeyVersion:          1,
+	}
+}
+
+// encryptBoundItem запись, уже привязанная к владельцу через связанные данные
+func encryptBoundItem(t *testing.T, key string, id int64, data string) *rotationModel.Row {
+	encryptor, err := crypto.NewEncryptor([]byte(key), crypto.DefaultAlgorithm)
+	require.NoError(t, err)
+	encryptedData, algorithm, iv, err := encryptor.Encrypt([]byte(data), crypto.ItemAAD(1, id, "text"))
+	require.NoError(t, err)
+	return &rotationModel.Row{
+		ID:                  id,
+		UserID:              1,
+		Data:                encryptedData,
+		IV:                  iv,
+		EncryptionAlgorithm: algorithm,
+		KeyVersion:          1,
+		ItemType:            "text",
+		AADBound:            true,
+	}
+}
+
+// encryptBoundChunk последняя часть файла, привязанная только к номеру части (до STREAM)
+func encryptBoundChunk(t *testing.T, key string, id int64, data string) *rotationModel.Row {
+	encryptor, err := crypto.NewEncryptor([]byte(key), crypto.DefaultAlgorithm)
+	require.NoError(t, err)
+	encryptedData, algorithm, iv, err := encryptor.Encrypt([]byte(data), crypto.ChunkAAD(3, 1))
+	require.NoError(t, err)
+	return &rotationModel.Row{
+		ID:                  id,
+		UserID:              1,
+		Data:                encryptedData,
+		IV:                  iv,
+		EncryptionAlgorithm: algorithm,
+		KeyVersion:          1,
+		FileID:              3,
+		ChunkIndex:          1,
+		FinalChunk:          true,
+		AADBound:            true,
+	}
+}
+
+func decryptRow(t *testing.T, key string, table string, row *rotationModel.Row) string {
+	decryptor, err := crypto.NewDecryptor([]byte(key))
+	require.NoError(t, err)
+	data, err := decryptor.Decrypt(row.Data, row.IV, row.EncryptionAlgorithm, rowAAD(table, row))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func testKeys() *masterKeys {
+	return &masterKeys{oldKey: []byte(testOldKey), newKey: []byte(testNewKey)}
+}
+
+func TestRotation_Rotate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	manager, err := prepareManager(&options{oldVersion: 1, newVersion: 2, algorithm: crypto.AlgorithmXChaCha20Poly1305}, testKeys())
+	require.NoError(t, err)
+
+	rows := map[string][]*rotationModel.Row{
+		rotationModel.TableDataKey: {encryptRow(t, testOldKey, 1, "data key")},
+		rotationModel.TableItem: {
+			encryptRow(t, testOldKey, 3, "item 3"),
+			encryptBoundItem(t, testOldKey, 7, "item 7"),
+		},
+		rotationModel.TableChunk: {
+			encryptRow(t, testOldKey, 2, "chunk"),
+			encryptBoundChunk(t, testOldKey, 4, "last chunk"),
+		},
+	}
+
+	rotator := storageMock.NewMockRotator(ctrl)
+	updated := map[string][]*rotationModel.Row{}
+	for table, tableRows := range rows {
+		lastID := tableRows[len(tableRows)-1].ID
+		rotator.EXPECT().CountRows(gomock.Any(), table, 1).Return(int64(len(tableRows)), nil)
+		rotator.EXPECT().GetRows(gomock.Any(), table, 1, int64(0), 2).Return(tableRows, nil)
+		rotator.EXPECT().GetRows(gomock.Any(), table, 1, lastID, 2).Return(nil, nil)
+		rotator.EXPECT().
+			UpdateRow(gomock.Any(), table, gomock.Any(), 1, gomock.Any()).
+			DoAndReturn(func(_ context.Context, table string, row *rotationModel.Row, _ int, _ []byte) error {
+				updated[table] = append(updated[table], row)
+				return nil
+			}).
+			Times(len(tableRows))
+	}
+
+	out := &bytes.Buffer{}
+	err = NewRotation(rotator, manager, 1, 2, out).Rotate(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "data key", decryptRow(t, testNewKey, rotationModel.TableDataKey, updated[rotationModel.TableDataKey][0]))
+	assert.Equal(t, "item 3", decryptRow(t, testNewKey, rotationModel.TableItem, updated[rotationModel.TableItem][0]))
+	assert.Equal(t, "item 7", decryptRow(t, testNewKey, rotationModel.TableItem, updated[rotationModel.TableItem][1]))
+	assert.Equal(t, "chunk", decryptRow(t, testNewKey, rotationModel.TableChunk, updated[rotationModel.TableChunk][0]))
+	// Части файлов переходят на потоковую схему с признаком последней части
+	assert.Equal(t, "last chunk", decryptRow(t, testNewKey, rotationModel.TableChunk, updated[rotationModel.TableChunk][1]))
+	for _, row := range updated[rotationModel.TableChunk] {
+		assert.True(t, row.StreamBound)
+	}
+	for _, tableRows := range updated {
+		for _, row := range tableRows {
+			assert.Equal(t, 2, row.KeyVersion)
+			assert.Equal(t, crypto.AlgorithmXChaCha20Poly1305, row.EncryptionAlgorithm)
+			// Записи без привязки получают связанные данные при перешифровании
+			assert.True(t, row.AADBound)
+		}
+	}
+	assert.Contains(t, out.String(), "encrypted_item: 2/2")
+}
+
+func TestRotation_Rotate_FailedRow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	manager, err := prepareManager(&options{oldVersion: 1, newVersion: 2, algorithm: crypto.DefaultAlgorithm}, testKeys())
+	require.NoError(t, err)
+
+	// Запись зашифрована неизвестным ключом, остальные записи все равно обрабатываются
+	brokenRow := encryptRow(t, testNewKey, 1, "broken")
+	goodRow := encryptRow(t, testOldKey, 2, "good")
+
+	rotator := storageMock.NewMockRotator(ctrl)
+	rotator.EXPECT().CountRows(gomock.Any(), gomock.Any(), 1).Return(int64(0), nil).Times(3)
+	rotator.EXPECT().GetRows(gomock.Any(), rotationModel.TableDataKey, 1, int64(0), 100).Return(nil, nil)
+	rotator.EXPECT().GetRows(gomock.Any(), rotationModel.TableItem, 1, int64(0), 100).
+		Return([]*rotationModel.Row{brokenRow, goodRow}, nil)
+	rotator.EXPECT().GetRows(gomock.Any(), rotationModel.TableItem, 1, int64(2), 100).Return(nil, nil)
+	rotator.EXPECT().GetRows(gomock.Any(), rotationModel.TableChunk, 1, int64(0), 100).Return(nil, nil)
+	rotator.EXPECT().UpdateRow(gomock.Any(), rotationModel.TableItem, goodRow, 1, goodRow.IV).Return(nil)
+
+	err = NewRotation(rotator, manager, 1, 100, &bytes.Buffer{}).Rotate(context.Background())
+	assert.Error(t, err)
+}
+
+func TestRotation_Rotate_ChangedRow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	manager, err := prepareManager(&options{oldVersion: 1, newVersion: 2, algorithm: crypto.DefaultAlgorithm}, testKeys())
+	require.NoError(t, err)
+
+	// Сервер перезаписал запись 3 после чтения, запись 4 - уже новым ключом
+	staleRow := encryptRow(t, testOldKey, 3, "stale")
+	freshRow := encryptRow(t, testOldKey, 3, "fresh")
+	movedRow := encryptRow(t, testOldKey, 4, "moved")
+
+	rotator := storageMock.NewMockRotator(ctrl)
+	rotator.EXPECT().CountRows(gomock.Any(), gomock.Any(), 1).Return(int64(0), nil).Times(3)
+	rotator.EXPECT().GetRows(gomock.Any(), rotationModel.TableDataKey, 1, int64(0), 100).Return(nil, nil)
+	rotator.EXPECT().GetRows(gomock.Any(), rotationModel.TableItem, 1, int64(0), 100).
+		Return([]*rotationModel.Row{staleRow, movedRow}, nil)
+	rotator.EXPECT().GetRows(gomock.Any(), rotationModel.TableItem, 1, int64(4), 100).Return(nil, nil)
+	rotator.EXPECT().GetRows(gomock.Any(), rotationModel.TableChunk, 1, int64(0), 100).Return(nil, nil)
+
+	staleIV := staleRow.IV
+	rotator.EXPECT().UpdateRow(gomock.Any(), rotationModel.TableItem, gomock.Any(), 1, staleIV).
+		Return(rotationModel.ErrRowChanged)
+	rotator.EXPECT().GetRows(gomock.Any(), rotationModel.TableItem, 1, int64(2), 1).
+		Return([]*rotationModel.Row{freshRow}, nil)
+	var saved *rotationModel.Row
+	rotator.EXPECT().UpdateRow(gomock.Any(), rotationModel.TableItem, gomock.Any(), 1, freshRow.IV).
+		DoAndReturn(func(_ context.Context, _ string, row *rotationModel.Row, _ int, _ []byte) error {
+			saved = row
+			return nil
+		})
+
+	rotator.EXPECT().UpdateRow(gomock.Any(), rotationModel.TableItem, gomock.Any(), 1, movedRow.IV).
+		Return(rotationModel.ErrRowChanged)
+	rotator.EXPECT().GetRows(gomock.Any(), rotationModel.TableItem, 1, int64(3), 1).Return(nil, nil)
+
+	err = NewRotation(rotator, manager, 1, 100, &bytes.Buffer{}).Rotate(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+	assert.Equal(t, "fresh", decryptRow(t, testNewKey, rotationModel.TableItem, saved))
+}
+
+func TestParseOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    *options
+		wantErr bool
+	}{
+		{
+			name: "keys from config",
+			args: []string{},
+			want: &options{
+				oldVersion: 1,
+				algorithm:  crypto.DefaultAlgorithm,
+				batchSize:  defaultBatchSize,
+			},
+		},
+		{
+			name: "keys from stdin",
+			args: []string{"-keys-stdin"},
+			want: &options{
+				oldVersion: 1,
+				newVersion: 2,
+				keysStdin:  true,
+				algorithm:  crypto.DefaultAlgorithm,
+				batchSize:  defaultBatchSize,
+			},
+		},
+		{
+			name: "explicit versions",
+			args: []string{
+				"-old-version", "2", "-new-version", "5",
+				"-algorithm", crypto.AlgorithmXChaCha20Poly1305, "-batch-size", "10",
+			},
+			want: &options{
+				oldVersion: 2,
+				newVersion: 5,
+				algorithm:  crypto.AlgorithmXChaCha20Poly1305,
+				batchSize:  10,
+			},
+		},
+		{
+			name:    "keys in arguments",
+			args:    []string{"-old-key", testOldKey, "-new-key", testNewKey},
+			wantErr: true,
+		},
+		{
+			name:    "unknown algorithm",
+			args:    []string{"-algorithm", "DES"},
+			wantErr: true,
+		},
+		{
+			name:    "same versions",
+			args:    []string{"-new-version", "1"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseOptions(tt.args, &bytes.Buffer{})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_readKeys(t *testing.T) {
+	keys, err := readKeys(strings.NewReader(testOldKey + "\n\n" + testNewKey + "\n"))
+	require.NoError(t, err)
+	assert.Equal(t, testKeys(), keys)
+
+	_, err = readKeys(strings.NewReader(testOldKey + "\n"))
+	assert.Error(t, err)
+}
+
+func Test_loadKeys(t *testing.T) {
+	config := &serverConfig.ServerConfig{
+		CryptoKey:          testNewKey,
+		CryptoKeyVersion:   2,
+		PreviousCryptoKeys: []serverConfig.CryptoKeyConfig{{Version: 1, Key: testOldKey}},
+	}
+	tests := []struct {
+		name    string
+		opts    *options
+		config  *serverConfig.ServerConfig
+		wantErr bool
+	}{
+		{
+			name:   "current and previous key",
+			opts:   &options{oldVersion: 1},
+			config: config,
+		},
+		{
+			name:    "no previous key",
+			opts:    &options{oldVersion: 3},
+			config:  config,
+			wantErr: true,
+		},
+		{
+			name:    "new version differs from config",
+			opts:    &options{oldVersion: 1, newVersion: 5},
+			config:  config,
+			wantErr: true,
+		},
+		{
+			name: "no current key",
+			opts: &options{oldVersion: 1},
+			config: &serverConfig.ServerConfig{
+				CryptoKeyVersion:   2,
+				PreviousCryptoKeys: config.PreviousCryptoKeys,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := loadKeys(context.Background(), tt.opts, tt.config)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testKeys(), keys)
+			assert.Equal(t, 2, tt.opts.newVersion)
+		})
+	}
+}
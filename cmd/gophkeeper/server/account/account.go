@@ -0,0 +1,119 @@
+package account
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	authServer "github.com/ramil063/secondgodiplom/cmd/gophkeeper/server/auth"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage"
+	accountModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/account"
+	"github.com/ramil063/secondgodiplom/internal/hash"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/account"
+)
+
+// UserTokenInvalidator сброс результатов проверки токенов пользователя после их отзыва
+type UserTokenInvalidator interface {
+	InvalidateUser(userID int)
+}
+
+// Server надстройка над стандартным gRPC сервером(учетная запись)
+type Server struct {
+	account.UnimplementedAccountServiceServer
+
+	storage     storage.AccountManager
+	tokens      UserTokenInvalidator
+	guard       *authServer.LoginGuard
+	gracePeriod time.Duration
+}
+
+// NewServer инициализация сервера учетной записи
+// tokens - кеш проверки токенов интерсептора авторизации, сбрасывается при отзыве сессий
+// guard - защита от подбора пароля при восстановлении учетной записи
+// gracePeriod - срок, в течение которого можно восстановить учетную запись при отложенном удалении
+func NewServer(
+	storage storage.AccountManager,
+	tokens UserTokenInvalidator,
+	guard *authServer.LoginGuard,
+	gracePeriod time.Duration,
+) *Server {
+	return &Server{
+		storage:     storage,
+		tokens:      tokens,
+		guard:       guard,
+		gracePeriod: gracePeriod,
+	}
+}
+
+// ChangePassword смена пароля пользователя
+// сессии на других устройствах завершаются, текущая остается
+// при сквозном шифровании клиент передает ключ хранилища, обернутый ключом из нового пароля
+// ключи шифрования данных на сервере обернуты мастер-ключом и от пароля не зависят
+func (s *Server) ChangePassword(
+	ctx context.Context,
+	req *account.ChangePasswordRequest,
+) (*account.ChangePasswordResponse, error) {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
+	}
+	accessToken, ok := ctx.Value("accessToken").(string)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
+	}
+	if req.NewPassword == "" {
+		return nil, status.Error(codes.InvalidArgument, "new password is required")
+	}
+	if req.NewPassword == req.OldPassword {
+		return nil, status.Error(codes.InvalidArgument, "new password must differ from the old one")
+	}
+
+	u, err := s.storage.GetAccount(ctx, userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get account")
+	}
+	if !hash.CheckPasswordHash(req.OldPassword, u.PasswordHash) {
+		return nil, status.Error(codes.PermissionDenied, "invalid password")
+	}
+
+	clientEncrypted := len(u.WrappedVaultKey) > 0
+	rewrapped := len(req.KdfSalt) > 0 && len(req.WrappedVaultKey) > 0
+	if clientEncrypted && !rewrapped {
+		return nil, status.Error(codes.FailedPrecondition, "client encryption enabled: rewrapped vault key is required")
+	}
+	if !clientEncrypted && (len(req.KdfSalt) > 0 || len(req.WrappedVaultKey) > 0) {
+		return nil, status.Error(codes.InvalidArgument, "client encryption is not enabled")
+	}
+
+	newPasswordHash, err := hash.GetPasswordHash(req.NewPassword)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to hash password")
+	}
+
+	client := authServer.ClientInfo(ctx)
+	revoked, err := s.storage.ChangePassword(ctx, &accountModel.PasswordChange{
+		UserID:             userID,
+		OldPasswordHash:    u.PasswordHash,
+		NewPasswordHash:    newPasswordHash,
+		KdfSalt:            req.KdfSalt,
+		WrappedVaultKey:    req.WrappedVaultKey,
+		CurrentAccessToken: accessToken,
+		Event: accountModel.SecurityEvent{
+			UserID:    userID,
+			Type:      accountModel.EventPasswordChanged,
+			ClientIP:  client.ClientIP,
+			UserAgent: client.UserAgent,
+		},
+	})
+	if err != nil {
+		if status.Code(err) == codes.Aborted {
+			return nil, err
+		}
+		return nil, status.Error(codes.Internal, "failed to change password")
+	}
+	s.tokens.InvalidateUser(userID)
+
+	return &account.ChangePasswordResponse{RevokedSessions: revoked}, nil
+}
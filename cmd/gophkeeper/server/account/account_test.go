@@ -0,0 +1,223 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/interceptors"
+	storageMock "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/mocks"
+	accountModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/account"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/user"
+	"github.com/ramil063/secondgodiplom/internal/hash"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/account"
+	internalJwt "github.com/ramil063/secondgodiplom/internal/security/jwt"
+)
+
+// userTokenInvalidator запоминает пользователей, чьи токены сброшены
+type userTokenInvalidator struct {
+	users []int
+}
+
+func (i *userTokenInvalidator) InvalidateUser(userID int) {
+	i.users = append(i.users, userID)
+}
+
+func TestServer_ChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	oldHash, err := hash.GetPasswordHash("old")
+	require.NoError(t, err)
+	authCtx := metadata.NewIncomingContext(
+		context.WithValue(context.WithValue(context.Background(), "userID", 1), "accessToken", "token"),
+		metadata.Pairs("user-agent", "laptop"),
+	)
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		req        *account.ChangePasswordRequest
+		account    *user.User
+		storageErr error
+		callChange bool
+		wantCode   codes.Code
+	}{
+		{
+			name:       "success",
+			ctx:        authCtx,
+			req:        &account.ChangePasswordRequest{OldPassword: "old", NewPassword: "new"},
+			account:    &user.User{ID: 1, PasswordHash: oldHash},
+			callChange: true,
+			wantCode:   codes.OK,
+		},
+		{
+			name: "success with client encryption",
+			ctx:  authCtx,
+			req: &account.ChangePasswordRequest{
+				OldPassword:     "old",
+				NewPassword:     "new",
+				KdfSalt:         []byte("new salt"),
+				WrappedVaultKey: []byte("rewrapped"),
+			},
+			account:    &user.User{ID: 1, PasswordHash: oldHash, WrappedVaultKey: []byte("wrapped")},
+			callChange: true,
+			wantCode:   codes.OK,
+		},
+		{
+			name:     "client encryption without rewrapped key",
+			ctx:      authCtx,
+			req:      &account.ChangePasswordRequest{OldPassword: "old", NewPassword: "new"},
+			account:  &user.User{ID: 1, PasswordHash: oldHash, WrappedVaultKey: []byte("wrapped")},
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name: "vault key without client encryption",
+			ctx:  authCtx,
+			req: &account.ChangePasswordRequest{
+				OldPassword:     "old",
+				NewPassword:     "new",
+				KdfSalt:         []byte("new salt"),
+				WrappedVaultKey: []byte("rewrapped"),
+			},
+			account:  &user.User{ID: 1, PasswordHash: oldHash},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "wrong old password",
+			ctx:      authCtx,
+			req:      &account.ChangePasswordRequest{OldPassword: "wrong", NewPassword: "new"},
+			account:  &user.User{ID: 1, PasswordHash: oldHash},
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:       "changed concurrently",
+			ctx:        authCtx,
+			req:        &account.ChangePasswordRequest{OldPassword: "old", NewPassword: "new"},
+			account:    &user.User{ID: 1, PasswordHash: oldHash},
+			callChange: true,
+			storageErr: status.Error(codes.Aborted, "account changed concurrently"),
+			wantCode:   codes.Aborted,
+		},
+		{
+			name:       "storage error",
+			ctx:        authCtx,
+			req:        &account.ChangePasswordRequest{OldPassword: "old", NewPassword: "new"},
+			account:    &user.User{ID: 1, PasswordHash: oldHash},
+			callChange: true,
+			storageErr: errors.New("connection refused"),
+			wantCode:   codes.Internal,
+		},
+		{
+			name:     "same password",
+			ctx:      authCtx,
+			req:      &account.ChangePasswordRequest{OldPassword: "old", NewPassword: "old"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "unauthenticated",
+			ctx:      context.Background(),
+			req:      &account.ChangePasswordRequest{OldPassword: "old", NewPassword: "new"},
+			wantCode: codes.Unauthenticated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storageMock.NewMockAccountManager(ctrl)
+			tokens := &userTokenInvalidator{}
+			s := NewServer(mockStorage, tokens, nil, time.Hour)
+
+			if tt.account != nil {
+				mockStorage.EXPECT().GetAccount(tt.ctx, 1).Return(tt.account, nil)
+			}
+			if tt.callChange {
+				mockStorage.EXPECT().
+					ChangePassword(tt.ctx, gomock.Any()).
+					DoAndReturn(func(_ context.Context, change *accountModel.PasswordChange) (int64, error) {
+						assert.Equal(t, tt.account.PasswordHash, change.OldPasswordHash)
+						assert.True(t, hash.CheckPasswordHash(tt.req.NewPassword, change.NewPasswordHash))
+						assert.Equal(t, tt.req.WrappedVaultKey, change.WrappedVaultKey)
+						assert.Equal(t, "token", change.CurrentAccessToken)
+						assert.Equal(t, accountModel.SecurityEvent{
+							UserID:    1,
+							Type:      accountModel.EventPasswordChanged,
+							UserAgent: "laptop",
+						}, change.Event)
+						return 2, tt.storageErr
+					})
+			}
+
+			got, err := s.ChangePassword(tt.ctx, tt.req)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				assert.Equal(t, int64(2), got.RevokedSessions)
+				assert.Equal(t, []int{1}, tokens.users)
+			} else {
+				assert.Empty(t, tokens.users)
+			}
+		})
+	}
+}
+
+// accessTokens заглушка хранилища токенов авторизации для кеша интерсептора
+type accessTokens struct {
+	active map[string]bool
+}
+
+func (a *accessTokens) IsAccessTokenActive(ctx context.Context, accessToken string) (bool, error) {
+	return a.active[accessToken], nil
+}
+
+func TestServer_ChangePassword_KeepsCurrentSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	keys, err := internalJwt.NewEphemeralKeySet()
+	require.NoError(t, err)
+	token, err := internalJwt.GenerateAccessToken(1, keys)
+	require.NoError(t, err)
+	oldHash, err := hash.GetPasswordHash("old")
+	require.NoError(t, err)
+
+	checker := &accessTokens{active: map[string]bool{token: true}}
+	tokens := interceptors.NewTokenCache(checker, time.Minute)
+	mockStorage := storageMock.NewMockAccountManager(ctrl)
+	s := NewServer(mockStorage, tokens, nil, time.Hour)
+	interceptor := interceptors.NewAuthInterceptor(keys, tokens, nil)
+	info := &grpc.UnaryServerInfo{FullMethod: "/account.AccountService/ChangePassword"}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+
+	mockStorage.EXPECT().GetAccount(gomock.Any(), 1).Return(&user.User{ID: 1, PasswordHash: oldHash}, nil)
+	mockStorage.EXPECT().
+		ChangePassword(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, change *accountModel.PasswordChange) (int64, error) {
+			// в хранилище отзываются только другие сессии, текущий токен остается активным
+			assert.Equal(t, token, change.CurrentAccessToken)
+			return 1, nil
+		})
+
+	_, err = interceptor(ctx, &account.ChangePasswordRequest{OldPassword: "old", NewPassword: "new"}, info,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return s.ChangePassword(ctx, req.(*account.ChangePasswordRequest))
+		})
+	require.NoError(t, err)
+
+	// следующий вызов с тем же токеном проходит авторизацию
+	called := false
+	_, err = interceptor(ctx, &account.ChangePasswordRequest{}, info,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			called = true
+			return nil, nil
+		})
+	require.NoError(t, err)
+	assert.True(t, called)
+}
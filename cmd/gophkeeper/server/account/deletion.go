@@ -0,0 +1,110 @@
+package account
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	authServer "github.com/ramil063/secondgodiplom/cmd/gophkeeper/server/auth"
+	accountModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/account"
+	"github.com/ramil063/secondgodiplom/internal/hash"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/account"
+)
+
+// DeleteAccount удаление учетной записи после повторной проверки пароля
+// без отложенного удаления данные, файлы и токены пользователя удаляются сразу,
+// иначе учетная запись деактивируется, все сессии завершаются, а данные удаляются по истечении срока восстановления
+func (s *Server) DeleteAccount(
+	ctx context.Context,
+	req *account.DeleteAccountRequest,
+) (*account.DeleteAccountResponse, error) {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
+	}
+
+	u, err := s.storage.GetAccount(ctx, userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get account")
+	}
+	if !hash.CheckPasswordHash(req.Password, u.PasswordHash) {
+		return nil, status.Error(codes.PermissionDenied, "invalid password")
+	}
+
+	if !req.Schedule {
+		err = s.storage.DeleteAccount(ctx, userID)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "failed to delete account")
+		}
+		s.tokens.InvalidateUser(userID)
+		return &account.DeleteAccountResponse{}, nil
+	}
+
+	purgeAt := time.Now().Add(s.gracePeriod)
+	client := authServer.ClientInfo(ctx)
+	err = s.storage.DeactivateAccount(ctx, &accountModel.Deactivation{
+		UserID:  userID,
+		PurgeAt: purgeAt,
+		Event: accountModel.SecurityEvent{
+			UserID:    userID,
+			Type:      accountModel.EventAccountDeactivated,
+			ClientIP:  client.ClientIP,
+			UserAgent: client.UserAgent,
+		},
+	})
+	if err != nil {
+		if status.Code(err) == codes.FailedPrecondition {
+			return nil, err
+		}
+		return nil, status.Error(codes.Internal, "failed to deactivate account")
+	}
+	s.tokens.InvalidateUser(userID)
+
+	return &account.DeleteAccountResponse{
+		Scheduled: true,
+		PurgeAt:   purgeAt.Format(time.RFC3339),
+	}, nil
+}
+
+// RestoreAccount отмена отложенного удаления учетной записи
+// токены деактивированного пользователя отозваны, поэтому метод доступен без авторизации по логину и паролю
+// попытки ограничиваются так же, как вход по паролю
+func (s *Server) RestoreAccount(
+	ctx context.Context,
+	req *account.RestoreAccountRequest,
+) (*account.RestoreAccountResponse, error) {
+	if err := s.guard.Check(ctx, req.Login); err != nil {
+		return nil, err
+	}
+
+	u, err := s.storage.GetAccountByLogin(ctx, req.Login)
+	if err != nil && status.Code(err) != codes.NotFound {
+		return nil, status.Error(codes.Internal, "failed to get account")
+	}
+	if !authServer.VerifyPassword(u, req.Password) {
+		// Неудачная попытка уже учтена в guard.Check
+		return nil, authServer.ErrInvalidCredentials
+	}
+	s.guard.Succeed(ctx, req.Login)
+	if u.IsActive {
+		return nil, status.Error(codes.FailedPrecondition, "account is active")
+	}
+
+	client := authServer.ClientInfo(ctx)
+	err = s.storage.RestoreAccount(ctx, u.ID, accountModel.SecurityEvent{
+		UserID:    u.ID,
+		Type:      accountModel.EventAccountRestored,
+		ClientIP:  client.ClientIP,
+		UserAgent: client.UserAgent,
+	})
+	if err != nil {
+		if status.Code(err) == codes.FailedPrecondition {
+			return nil, err
+		}
+		return nil, status.Error(codes.Internal, "failed to restore account")
+	}
+
+	return &account.RestoreAccountResponse{}, nil
+}
@@ -0,0 +1,211 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	authServer "github.com/ramil063/secondgodiplom/cmd/gophkeeper/server/auth"
+	storageMock "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/mocks"
+	accountModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/account"
+	modelAuth "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/user"
+	"github.com/ramil063/secondgodiplom/internal/hash"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/account"
+)
+
+func TestServer_DeleteAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	passwordHash, err := hash.GetPasswordHash("secret")
+	require.NoError(t, err)
+	authCtx := metadata.NewIncomingContext(
+		context.WithValue(context.Background(), "userID", 1),
+		metadata.Pairs("user-agent", "laptop"),
+	)
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		req        *account.DeleteAccountRequest
+		prepare    func(mockStorage *storageMock.MockAccountManager)
+		wantCode   codes.Code
+		wantPurged bool
+	}{
+		{
+			name: "immediate",
+			ctx:  authCtx,
+			req:  &account.DeleteAccountRequest{Password: "secret"},
+			prepare: func(mockStorage *storageMock.MockAccountManager) {
+				mockStorage.EXPECT().DeleteAccount(authCtx, 1).Return(nil)
+			},
+			wantCode:   codes.OK,
+			wantPurged: true,
+		},
+		{
+			name: "scheduled",
+			ctx:  authCtx,
+			req:  &account.DeleteAccountRequest{Password: "secret", Schedule: true},
+			prepare: func(mockStorage *storageMock.MockAccountManager) {
+				mockStorage.EXPECT().
+					DeactivateAccount(authCtx, gomock.Any()).
+					DoAndReturn(func(_ context.Context, deactivation *accountModel.Deactivation) error {
+						assert.Equal(t, 1, deactivation.UserID)
+						assert.WithinDuration(t, time.Now().Add(time.Hour), deactivation.PurgeAt, time.Minute)
+						assert.Equal(t, accountModel.SecurityEvent{
+							UserID:    1,
+							Type:      accountModel.EventAccountDeactivated,
+							UserAgent: "laptop",
+						}, deactivation.Event)
+						return nil
+					})
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "already deactivated",
+			ctx:  authCtx,
+			req:  &account.DeleteAccountRequest{Password: "secret", Schedule: true},
+			prepare: func(mockStorage *storageMock.MockAccountManager) {
+				mockStorage.EXPECT().
+					DeactivateAccount(authCtx, gomock.Any()).
+					Return(status.Error(codes.FailedPrecondition, "account is already deactivated"))
+			},
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name: "storage error",
+			ctx:  authCtx,
+			req:  &account.DeleteAccountRequest{Password: "secret"},
+			prepare: func(mockStorage *storageMock.MockAccountManager) {
+				mockStorage.EXPECT().DeleteAccount(authCtx, 1).Return(errors.New("connection refused"))
+			},
+			wantCode: codes.Internal,
+		},
+		{
+			name:     "wrong password",
+			ctx:      authCtx,
+			req:      &account.DeleteAccountRequest{Password: "wrong"},
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "unauthenticated",
+			ctx:      context.Background(),
+			req:      &account.DeleteAccountRequest{Password: "secret"},
+			wantCode: codes.Unauthenticated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storageMock.NewMockAccountManager(ctrl)
+			tokens := &userTokenInvalidator{}
+			s := NewServer(mockStorage, tokens, nil, time.Hour)
+
+			if tt.ctx != context.Background() {
+				mockStorage.EXPECT().GetAccount(tt.ctx, 1).Return(&user.User{ID: 1, PasswordHash: passwordHash}, nil)
+			}
+			if tt.prepare != nil {
+				tt.prepare(mockStorage)
+			}
+
+			got, err := s.DeleteAccount(tt.ctx, tt.req)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode != codes.OK {
+				assert.Empty(t, tokens.users)
+				return
+			}
+			assert.Equal(t, []int{1}, tokens.users)
+			assert.Equal(t, !tt.wantPurged, got.Scheduled)
+			assert.Equal(t, tt.wantPurged, got.PurgeAt == "")
+		})
+	}
+}
+
+func TestServer_RestoreAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	passwordHash, err := hash.GetPasswordHash("secret")
+	require.NoError(t, err)
+	ctx := context.Background()
+	deactivated := &user.User{ID: 1, Login: "alice", PasswordHash: passwordHash}
+
+	tests := []struct {
+		name        string
+		req         *account.RestoreAccountRequest
+		account     *user.User
+		accountErr  error
+		restoreErr  error
+		callRestore bool
+		wantCode    codes.Code
+	}{
+		{
+			name:        "success",
+			req:         &account.RestoreAccountRequest{Login: "alice", Password: "secret"},
+			account:     deactivated,
+			callRestore: true,
+			wantCode:    codes.OK,
+		},
+		{
+			name:        "grace period expired",
+			req:         &account.RestoreAccountRequest{Login: "alice", Password: "secret"},
+			account:     deactivated,
+			callRestore: true,
+			restoreErr:  status.Error(codes.FailedPrecondition, "account is not scheduled for deletion"),
+			wantCode:    codes.FailedPrecondition,
+		},
+		{
+			name:     "active account",
+			req:      &account.RestoreAccountRequest{Login: "alice", Password: "secret"},
+			account:  &user.User{ID: 1, Login: "alice", PasswordHash: passwordHash, IsActive: true},
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name:     "wrong password",
+			req:      &account.RestoreAccountRequest{Login: "alice", Password: "wrong"},
+			account:  deactivated,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			// неизвестный логин неотличим от неверного пароля
+			name:       "unknown user",
+			req:        &account.RestoreAccountRequest{Login: "bob", Password: "secret"},
+			accountErr: status.Error(codes.NotFound, "user not found"),
+			wantCode:   codes.Unauthenticated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storageMock.NewMockAccountManager(ctrl)
+			mockThrottler := storageMock.NewMockLoginThrottler(ctrl)
+			policy := modelAuth.DefaultLoginPolicy()
+			guard := authServer.NewLoginGuard(mockThrottler, policy)
+			s := NewServer(mockStorage, &userTokenInvalidator{}, guard, time.Hour)
+
+			subject := "login:" + tt.req.Login
+			mockThrottler.EXPECT().GetLoginAttempts(ctx, []string{subject}).Return(nil, nil)
+			mockThrottler.EXPECT().ReserveLoginAttempt(ctx, subject, policy.MaxFailures, policy.Lockout).Return(true, nil)
+			if tt.wantCode != codes.Unauthenticated {
+				mockThrottler.EXPECT().ResetLoginFailures(ctx, subject).Return(nil)
+			}
+			mockStorage.EXPECT().GetAccountByLogin(ctx, tt.req.Login).Return(tt.account, tt.accountErr)
+			if tt.callRestore {
+				mockStorage.EXPECT().
+					RestoreAccount(ctx, 1, accountModel.SecurityEvent{UserID: 1, Type: accountModel.EventAccountRestored}).
+					Return(tt.restoreErr)
+			}
+
+			_, err := s.RestoreAccount(ctx, tt.req)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
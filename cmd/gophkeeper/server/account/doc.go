@@ -0,0 +1,2 @@
+// Package account логика управления учетной записью пользователя на сервере
+package account
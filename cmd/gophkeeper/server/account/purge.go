@@ -0,0 +1,41 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage"
+	"github.com/ramil063/secondgodiplom/internal/logger"
+)
+
+// DefaultPurgeInterval период проверки учетных записей, у которых истек срок восстановления
+const DefaultPurgeInterval = time.Hour
+
+// RunPurge окончательное удаление деактивированных учетных записей по истечении срока восстановления
+// первая проверка выполняется сразу, далее раз в interval до отмены контекста
+func RunPurge(ctx context.Context, storage storage.AccountManager, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		Purge(ctx, storage)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge однократное удаление учетных записей, у которых истек срок восстановления
+func Purge(ctx context.Context, storage storage.AccountManager) {
+	purged, err := storage.PurgeDeletedAccounts(ctx)
+	if err != nil {
+		logger.WriteErrorLog(err.Error())
+	}
+	if purged > 0 {
+		logger.WriteInfoLog(fmt.Sprintf("purged %d deleted accounts", purged))
+	}
+}
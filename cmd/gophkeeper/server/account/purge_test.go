@@ -0,0 +1,53 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	storageMock "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/mocks"
+)
+
+func TestPurge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := storageMock.NewMockAccountManager(ctrl)
+	ctx := context.Background()
+
+	mockStorage.EXPECT().PurgeDeletedAccounts(ctx).Return(2, nil)
+	Purge(ctx, mockStorage)
+
+	// ошибка удаления только пишется в лог, следующая проверка выполнится по расписанию
+	mockStorage.EXPECT().PurgeDeletedAccounts(ctx).Return(0, errors.New("connection refused"))
+	Purge(ctx, mockStorage)
+}
+
+func TestRunPurge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := storageMock.NewMockAccountManager(ctrl)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// первая проверка выполняется сразу после запуска
+	mockStorage.EXPECT().PurgeDeletedAccounts(ctx).DoAndReturn(func(context.Context) (int, error) {
+		cancel()
+		return 0, nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		RunPurge(ctx, mockStorage, time.Hour)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunPurge did not stop after context cancel")
+	}
+}
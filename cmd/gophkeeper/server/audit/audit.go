@@ -0,0 +1,112 @@
+package audit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage"
+	auditModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/audit"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/audit"
+)
+
+// Server надстройка над стандартным gRPC сервером(журнал аудита)
+type Server struct {
+	audit.UnimplementedAuditServiceServer
+
+	storage storage.AuditLogger
+}
+
+// NewServer инициализация сервера журнала аудита
+func NewServer(storage storage.AuditLogger) *Server {
+	return &Server{
+		storage: storage,
+	}
+}
+
+// ListEvents события пользователя от новых к старым
+// страница продолжается по next_page_token - идентификатору последнего события предыдущей страницы
+func (s *Server) ListEvents(ctx context.Context, req *audit.ListEventsRequest) (*audit.ListEventsResponse, error) {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
+	}
+
+	filter, err := eventFilter(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.storage.ListAuditEvents(ctx, filter)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get audit events")
+	}
+
+	resp := &audit.ListEventsResponse{Events: make([]*audit.Event, 0, len(events))}
+	for _, event := range events {
+		item := &audit.Event{
+			Id:         event.ID,
+			Type:       event.Type,
+			Method:     event.Method,
+			Success:    event.Success,
+			StatusCode: event.StatusCode,
+			ClientIp:   event.ClientIP,
+			UserAgent:  event.UserAgent,
+			CreatedAt:  event.CreatedAt.String(),
+		}
+		if event.TargetID != nil {
+			item.TargetId = *event.TargetID
+		}
+		if event.PersonalTokenID != nil {
+			item.PersonalTokenId = int64(*event.PersonalTokenID)
+		}
+		resp.Events = append(resp.Events, item)
+	}
+	if len(events) == filter.Limit {
+		resp.NextPageToken = strconv.FormatInt(events[len(events)-1].ID, 10)
+	}
+	return resp, nil
+}
+
+// eventFilter условия выборки из запроса
+func eventFilter(userID int, req *audit.ListEventsRequest) (*auditModel.Filter, error) {
+	filter := &auditModel.Filter{
+		UserID:     userID,
+		Types:      req.Types,
+		TargetID:   req.TargetId,
+		FailedOnly: req.FailedOnly,
+		Limit:      int(req.PageSize),
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = auditModel.DefaultPageSize
+	}
+	if filter.Limit > auditModel.MaxPageSize {
+		filter.Limit = auditModel.MaxPageSize
+	}
+
+	if req.PageToken != "" {
+		beforeID, err := strconv.ParseInt(req.PageToken, 10, 64)
+		if err != nil || beforeID <= 0 {
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+		filter.BeforeID = beforeID
+	}
+	if req.Since != "" {
+		since, err := time.Parse(time.RFC3339, req.Since)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "since must be in RFC 3339 format")
+		}
+		filter.Since = &since
+	}
+	if req.Until != "" {
+		until, err := time.Parse(time.RFC3339, req.Until)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "until must be in RFC 3339 format")
+		}
+		filter.Until = &until
+	}
+	return filter, nil
+}
@@ -0,0 +1,92 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	storageMock "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/mocks"
+	auditModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/audit"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/audit"
+)
+
+func TestServer_ListEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := storageMock.NewMockAuditLogger(ctrl)
+	s := NewServer(mockStorage)
+	ctx := context.WithValue(context.Background(), "userID", 1)
+	createdAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	userID := 1
+	targetID := int64(42)
+	tokenID := 3
+
+	mockStorage.EXPECT().
+		ListAuditEvents(ctx, &auditModel.Filter{UserID: 1, Types: []string{auditModel.EventItemRead}, Limit: 2}).
+		Return([]auditModel.Event{
+			{ID: 11, UserID: &userID, Type: auditModel.EventItemRead, TargetID: &targetID, PersonalTokenID: &tokenID,
+				Success: true, StatusCode: "OK", CreatedAt: createdAt},
+			{ID: 9, UserID: &userID, Type: auditModel.EventItemRead, StatusCode: "NotFound", CreatedAt: createdAt},
+		}, nil)
+	got, err := s.ListEvents(ctx, &audit.ListEventsRequest{PageSize: 2, Types: []string{auditModel.EventItemRead}})
+	require.NoError(t, err)
+	require.Len(t, got.Events, 2)
+	assert.Equal(t, int64(42), got.Events[0].TargetId)
+	assert.Equal(t, int64(3), got.Events[0].PersonalTokenId)
+	assert.Equal(t, int64(0), got.Events[1].TargetId)
+	assert.Equal(t, "9", got.NextPageToken)
+
+	// последняя страница
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	mockStorage.EXPECT().
+		ListAuditEvents(ctx, &auditModel.Filter{UserID: 1, Since: &since, BeforeID: 9, FailedOnly: true, Limit: auditModel.DefaultPageSize}).
+		Return([]auditModel.Event{{ID: 5, UserID: &userID, Type: auditModel.EventLogin, CreatedAt: createdAt}}, nil)
+	got, err = s.ListEvents(ctx, &audit.ListEventsRequest{PageToken: "9", Since: "2026-10-01T00:00:00Z", FailedOnly: true})
+	require.NoError(t, err)
+	assert.Len(t, got.Events, 1)
+	assert.Empty(t, got.NextPageToken)
+
+	mockStorage.EXPECT().ListAuditEvents(ctx, gomock.Any()).Return(nil, errors.New("connection refused"))
+	_, err = s.ListEvents(ctx, &audit.ListEventsRequest{PageSize: 1000})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestServer_ListEvents_InvalidRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := NewServer(storageMock.NewMockAuditLogger(ctrl))
+	ctx := context.WithValue(context.Background(), "userID", 1)
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		req      *audit.ListEventsRequest
+		wantCode codes.Code
+	}{
+		{name: "unauthenticated", ctx: context.Background(), req: &audit.ListEventsRequest{}, wantCode: codes.Unauthenticated},
+		{name: "invalid page token", ctx: ctx, req: &audit.ListEventsRequest{PageToken: "abc"}, wantCode: codes.InvalidArgument},
+		{name: "invalid since", ctx: ctx, req: &audit.ListEventsRequest{Since: "yesterday"}, wantCode: codes.InvalidArgument},
+		{name: "invalid until", ctx: ctx, req: &audit.ListEventsRequest{Until: "2026-10-01"}, wantCode: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.ListEvents(tt.ctx, tt.req)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+func Test_eventFilter_PageSize(t *testing.T) {
+	filter, err := eventFilter(1, &audit.ListEventsRequest{PageSize: 1000})
+	require.NoError(t, err)
+	assert.Equal(t, auditModel.MaxPageSize, filter.Limit)
+}
@@ -0,0 +1,2 @@
+// Package audit логика просмотра журнала аудита на сервере
+package audit
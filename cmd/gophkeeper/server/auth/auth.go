@@ -5,52 +5,134 @@ import (
 	"time"
 
 	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/account"
 	modelAuth "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/user"
 	"github.com/ramil063/secondgodiplom/internal/hash"
+	"github.com/ramil063/secondgodiplom/internal/logger"
 	"github.com/ramil063/secondgodiplom/internal/proto/gen/auth"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto"
 	"github.com/ramil063/secondgodiplom/internal/security/jwt"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+// TokenInvalidator сброс результатов проверки токенов после их отзыва
+type TokenInvalidator interface {
+	Invalidate(accessToken string, userID int)
+	InvalidateHash(tokenHash string, userID int)
+	InvalidateUser(userID int)
+}
+
 // Server надстройка над стандартным gRPC сервером(авторизация)
 type Server struct {
 	auth.UnimplementedAuthServiceServer
 
 	storage storage.Authenticator
-	Secret  string
+	tokens  TokenInvalidator
+	keys    crypto.KeyResolver
+	guard   *LoginGuard
+	Signer  *jwt.KeySet
 }
 
-// NewAuthServer инициализация сервера авторизации, хранилища и секрета для шифрования данных
-func NewAuthServer(storage storage.Authenticator, secret string) *Server {
+// NewAuthServer инициализация сервера авторизации, хранилища и ключей подписи токенов доступа
+// tokens - кеш проверки токенов интерсептора авторизации, сбрасывается при выходе
+// keys - ключи пользователей, ими шифруется секрет второго фактора
+// guard - защита входа по паролю от подбора
+func NewAuthServer(
+	storage storage.Authenticator,
+	signer *jwt.KeySet,
+	tokens TokenInvalidator,
+	keys crypto.KeyResolver,
+	guard *LoginGuard,
+) *Server {
 	return &Server{
 		storage: storage,
-		Secret:  secret,
+		tokens:  tokens,
+		keys:    keys,
+		guard:   guard,
+		Signer:  signer,
 	}
 }
 
 // Login авторизация пользователя
 // сохранение данных по токенам
 func (s *Server) Login(ctx context.Context, req *auth.LoginRequest) (*auth.LoginResponse, error) {
-	// Проверяем пользователя
+	if err := s.guard.Check(ctx, req.Login); err != nil {
+		return nil, err
+	}
+
+	// Проверяем пользователя, неизвестный логин неотличим от неверного пароля
 	user, err := s.storage.GetUserByLogin(ctx, req.Login)
+	if err != nil && status.Code(err) != codes.NotFound {
+		return nil, status.Error(codes.Internal, "failed to get user")
+	}
+
+	// Проверяем пароль (Argon2id или bcrypt для старых учетных записей)
+	if !VerifyPassword(user, req.Password) {
+		// Неудачная попытка уже учтена в guard.Check
+		return nil, ErrInvalidCredentials
+	}
+	s.guard.Succeed(ctx, req.Login)
+	s.rehashPassword(ctx, user, req.Password)
+
+	// Деактивированную учетную запись можно только восстановить через AccountService.RestoreAccount
+	if !user.IsActive {
+		return nil, status.Error(codes.PermissionDenied, "account is deactivated")
+	}
+
+	// Со вторым фактором токены выдаются только после VerifyTOTP
+	totpState, err := s.storage.GetTOTP(ctx, user.ID)
 	if err != nil {
-		return nil, status.Error(codes.NotFound, "user not found")
+		return nil, status.Error(codes.Internal, "failed to check second factor")
 	}
+	if totpState != nil && totpState.Confirmed {
+		challenge, err := jwt.GenerateRefreshToken()
+		if err != nil {
+			return nil, status.Error(codes.Internal, "failed to generate challenge")
+		}
+		err = s.storage.SaveLoginChallenge(ctx, user.ID, challenge)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "failed to save challenge")
+		}
+		return &auth.LoginResponse{
+			TotpRequired: true,
+			Challenge:    challenge,
+			ExpiresIn:    modelAuth.TOTPChallengeExpiredSeconds,
+		}, nil
+	}
+
+	return s.issueTokens(ctx, user)
+}
 
-	// Проверяем пароль (bcrypt)
-	if !hash.CheckPasswordHash(req.Password, user.PasswordHash) {
-		return nil, status.Error(codes.Unauthenticated, "invalid password")
+// rehashPassword пересчет хеша bcrypt или Argon2id с устаревшими параметрами по текущим параметрам
+// ошибка только логируется: вход возможен и со старым хешем
+func (s *Server) rehashPassword(ctx context.Context, user *user.User, password string) {
+	if !hash.NeedsRehash(user.PasswordHash) {
+		return
 	}
+	newHash, err := hash.GetPasswordHash(password)
+	if err != nil {
+		logger.WriteErrorLog(err.Error())
+		return
+	}
+	if err = s.storage.RehashPassword(ctx, user.ID, user.PasswordHash, newHash); err != nil {
+		logger.WriteErrorLog(err.Error())
+		return
+	}
+	user.PasswordHash = newHash
+}
 
+// issueTokens выдача и сохранение пары токенов после успешного входа
+func (s *Server) issueTokens(ctx context.Context, user *user.User) (*auth.LoginResponse, error) {
 	// Генерируем токены
-	accessToken, err := jwt.GenerateAccessToken(user.ID, s.Secret)
+	accessToken, err := jwt.GenerateAccessToken(user.ID, s.Signer)
 	if err != nil {
 		return nil, status.Error(codes.Internal, "failed to generate token")
 	}
 
 	// Сохраняем access token в БД
-	accessTokenId, err := s.storage.SaveAccessToken(ctx, user.ID, accessToken)
+	accessTokenId, err := s.storage.SaveAccessToken(ctx, user.ID, accessToken, sessionInfo(ctx, time.Now()))
 	if err != nil {
 		return nil, status.Error(codes.Internal, "failed to save refresh token")
 	}
@@ -59,45 +141,66 @@ func (s *Server) Login(ctx context.Context, req *auth.LoginRequest) (*auth.Login
 	if err != nil {
 		return nil, status.Error(codes.Internal, "failed to generate refresh token")
 	}
+	// Вход начинает новое семейство refresh token'ов
+	familyID, err := jwt.GenerateTokenFamilyID()
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to generate refresh token")
+	}
 
 	// Сохраняем refresh token в БД
-	err = s.storage.SaveRefreshToken(ctx, accessTokenId, refreshToken)
+	err = s.storage.SaveRefreshToken(ctx, accessTokenId, refreshToken, familyID)
 	if err != nil {
 		return nil, status.Error(codes.Internal, "failed to save refresh token")
 	}
 
 	return &auth.LoginResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    modelAuth.TokenExpiredSeconds, // 30 минут
+		AccessToken:     accessToken,
+		RefreshToken:    refreshToken,
+		ExpiresIn:       modelAuth.TokenExpiredSeconds, // 30 минут
+		KdfSalt:         user.KdfSalt,
+		WrappedVaultKey: user.WrappedVaultKey,
 	}, nil
 }
 
 // Refresh обновление токенов на более свежие
+// повторное предъявление уже обмененного refresh token'а означает его кражу:
+// отзывается все семейство токенов этого входа и записывается событие безопасности
 func (s *Server) Refresh(ctx context.Context, req *auth.RefreshRequest) (*auth.RefreshResponse, error) {
 	// 1. Валидируем старый refresh token
 	oldTokenInfo, err := s.storage.GetRefreshToken(ctx, req.RefreshToken)
 	if err != nil {
 		return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
 	}
+	if oldTokenInfo.IsRevoked {
+		if oldTokenInfo.IsRotated {
+			s.revokeTokenFamily(ctx, oldTokenInfo)
+		}
+		return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+	}
 
 	// 2. Проверяем expiry
 	if time.Now().After(oldTokenInfo.ExpiresAt) {
 		return nil, status.Error(codes.Unauthenticated, "refresh token expired")
 	}
 
-	// 3. Отзываем старый refresh token
+	// 3. Отзываем старый refresh token вместе с его токеном авторизации
+	// токен, отозванный параллельным запросом, тоже считается повторно использованным
 	err = s.storage.RevokeRefreshToken(ctx, req.RefreshToken)
 	if err != nil {
+		if status.Code(err) == codes.FailedPrecondition {
+			s.revokeTokenFamily(ctx, oldTokenInfo)
+			return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+		}
 		return nil, status.Error(codes.Internal, "failed to revoke token")
 	}
 
 	// 5. Генерируем НОВУЮ пару токенов
-	accessToken, err := jwt.GenerateAccessToken(oldTokenInfo.UserID, s.Secret)
+	accessToken, err := jwt.GenerateAccessToken(oldTokenInfo.UserID, s.Signer)
 	if err != nil {
 		return nil, status.Error(codes.Internal, "failed to generate access token")
 	}
-	accessTokenID, err := s.storage.SaveAccessToken(ctx, oldTokenInfo.UserID, accessToken)
+	session := sessionInfo(ctx, oldTokenInfo.SessionCreated)
+	accessTokenID, err := s.storage.SaveAccessToken(ctx, oldTokenInfo.UserID, accessToken, session)
 	if err != nil {
 		return nil, status.Error(codes.Internal, "failed to save access token")
 	}
@@ -106,7 +209,7 @@ func (s *Server) Refresh(ctx context.Context, req *auth.RefreshRequest) (*auth.R
 	if err != nil {
 		return nil, status.Error(codes.Internal, "failed to generate refresh token")
 	}
-	err = s.storage.SaveRefreshToken(ctx, accessTokenID, newRefreshToken)
+	err = s.storage.SaveRefreshToken(ctx, accessTokenID, newRefreshToken, oldTokenInfo.FamilyID)
 	if err != nil {
 		return nil, status.Error(codes.Internal, "failed to save refresh token")
 	}
@@ -117,3 +220,85 @@ func (s *Server) Refresh(ctx context.Context, req *auth.RefreshRequest) (*auth.R
 		ExpiresIn:    modelAuth.TokenExpiredSeconds,
 	}, nil
 }
+
+// revokeTokenFamily отзыв семейства токенов при повторном использовании refresh token'а
+// ошибка только логируется: клиенту в любом случае отказывается в обновлении
+func (s *Server) revokeTokenFamily(ctx context.Context, token *modelAuth.RefreshToken) {
+	client := ClientInfo(ctx)
+	_, err := s.storage.RevokeTokenFamily(ctx, token.FamilyID, account.SecurityEvent{
+		UserID:    token.UserID,
+		Type:      account.EventRefreshTokenReuse,
+		ClientIP:  client.ClientIP,
+		UserAgent: client.UserAgent,
+	})
+	if err != nil {
+		logger.WriteErrorLog(err.Error())
+	}
+	s.tokens.InvalidateUser(token.UserID)
+}
+
+// EnableClientEncryption включение сквозного шифрования для существующего пользователя
+// сервер сохраняет только соль и обернутый на клиенте ключ хранилища
+func (s *Server) EnableClientEncryption(
+	ctx context.Context,
+	req *auth.EnableClientEncryptionRequest,
+) (*auth.EnableClientEncryptionResponse, error) {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
+	}
+	if len(req.KdfSalt) == 0 || len(req.WrappedVaultKey) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "kdf salt and wrapped vault key are required")
+	}
+
+	err := s.storage.SaveClientKeys(ctx, userID, req.KdfSalt, req.WrappedVaultKey)
+	if err != nil {
+		if status.Code(err) == codes.FailedPrecondition {
+			return nil, err
+		}
+		return nil, status.Error(codes.Internal, "failed to save client keys")
+	}
+
+	return &auth.EnableClientEncryptionResponse{Success: true}, nil
+}
+
+// Logout выход пользователя
+// текущий токен авторизации и его refresh token отзываются
+func (s *Server) Logout(ctx context.Context, _ *auth.LogoutRequest) (*auth.LogoutResponse, error) {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
+	}
+	accessToken, ok := ctx.Value("accessToken").(string)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
+	}
+
+	err := s.storage.RevokeAccessToken(ctx, userID, accessToken)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, err
+		}
+		return nil, status.Error(codes.Internal, "failed to revoke token")
+	}
+	s.tokens.Invalidate(accessToken, userID)
+
+	return &auth.LogoutResponse{Success: true}, nil
+}
+
+// LogoutAll выход пользователя на всех устройствах
+// отзываются все токены пользователя, включая текущий
+func (s *Server) LogoutAll(ctx context.Context, _ *auth.LogoutAllRequest) (*auth.LogoutAllResponse, error) {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
+	}
+
+	revoked, err := s.storage.RevokeUserTokens(ctx, userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to revoke tokens")
+	}
+	s.tokens.InvalidateUser(userID)
+
+	return &auth.LogoutAllResponse{RevokedSessions: revoked}, nil
+}
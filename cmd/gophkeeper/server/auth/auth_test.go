@@ -2,26 +2,35 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage"
 	storageMock "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/mocks"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/account"
 	modelAuth "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
 	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/user"
 	"github.com/ramil063/secondgodiplom/internal/hash"
 	"github.com/ramil063/secondgodiplom/internal/proto/gen/auth"
+	"github.com/ramil063/secondgodiplom/internal/security/jwt"
 	storageAuth "github.com/ramil063/secondgodiplom/internal/storage/db/dml/auth"
 	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestNewAuthServer(t *testing.T) {
+	signer := newTestSigner(t)
+
 	type args struct {
 		storage storage.Authenticator
-		secret  string
+		signer  *jwt.KeySet
 	}
 	tests := []struct {
 		name string
@@ -34,19 +43,22 @@ func TestNewAuthServer(t *testing.T) {
 				storage: &storageAuth.Auth{
 					Repository: &repository.Repository{},
 				},
-				secret: "secret",
+				signer: signer,
 			},
 			want: &Server{
 				storage: &storageAuth.Auth{
 					Repository: &repository.Repository{},
 				},
-				Secret: "secret",
+				tokens: &tokenInvalidator{},
+				keys:   &userKeys{},
+				guard:  &LoginGuard{},
+				Signer: signer,
 			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := NewAuthServer(tt.args.storage, tt.args.secret); !reflect.DeepEqual(got, tt.want) {
+			if got := NewAuthServer(tt.args.storage, tt.args.signer, &tokenInvalidator{}, &userKeys{}, &LoginGuard{}); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("NewAuthServer() = %v, want %v", got, tt.want)
 			}
 		})
@@ -85,12 +97,17 @@ func TestServer_Login(t *testing.T) {
 			mockStorage := storageMock.NewMockAuthenticator(ctrl)
 			s := &Server{
 				storage: mockStorage,
-				Secret:  "secret",
+				guard:   NewLoginGuard(mockStorage, modelAuth.DefaultLoginPolicy()),
+				Signer:  newTestSigner(t),
 			}
 
 			pHash, err := hash.GetPasswordHash(tt.args.req.Password)
 			assert.NoError(t, err)
 
+			mockStorage.EXPECT().GetLoginAttempts(tt.args.ctx, []string{"login:test"}).Return(nil, nil)
+			mockStorage.EXPECT().ReserveLoginAttempt(tt.args.ctx, "login:test", modelAuth.DefaultMaxLoginFailures, modelAuth.DefaultLoginLockout).Return(true, nil)
+			mockStorage.EXPECT().ResetLoginFailures(tt.args.ctx, "login:test").Return(nil)
+
 			mockStorage.EXPECT().
 				GetUserByLogin(tt.args.ctx, tt.args.req.Login).
 				Return(&user.User{
@@ -99,12 +116,16 @@ func TestServer_Login(t *testing.T) {
 					PasswordHash: pHash,
 					FirstName:    "test",
 					LastName:     "test",
+					IsActive:     true,
 				}, nil)
 			mockStorage.EXPECT().
-				SaveAccessToken(tt.args.ctx, tt.userID, gomock.Any()).
+				GetTOTP(tt.args.ctx, tt.userID).
+				Return(nil, nil)
+			mockStorage.EXPECT().
+				SaveAccessToken(tt.args.ctx, tt.userID, gomock.Any(), gomock.Any()).
 				Return(tt.accessTokenID, nil)
 			mockStorage.EXPECT().
-				SaveRefreshToken(tt.args.ctx, tt.accessTokenID, gomock.Any()).
+				SaveRefreshToken(tt.args.ctx, tt.accessTokenID, gomock.Any(), gomock.Any()).
 				Return(nil)
 
 			got, err := s.Login(tt.args.ctx, tt.args.req)
@@ -116,6 +137,71 @@ func TestServer_Login(t *testing.T) {
 	}
 }
 
+func TestServer_LoginDeactivated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := storageMock.NewMockAuthenticator(ctrl)
+	s := &Server{
+		storage: mockStorage,
+		guard:   NewLoginGuard(mockStorage, modelAuth.DefaultLoginPolicy()),
+		Signer:  newTestSigner(t),
+	}
+
+	pHash, err := hash.GetPasswordHash("test")
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	mockStorage.EXPECT().GetLoginAttempts(ctx, []string{"login:test"}).Return(nil, nil)
+	mockStorage.EXPECT().ReserveLoginAttempt(ctx, "login:test", modelAuth.DefaultMaxLoginFailures, modelAuth.DefaultLoginLockout).Return(true, nil)
+	mockStorage.EXPECT().ResetLoginFailures(ctx, "login:test").Return(nil)
+	mockStorage.EXPECT().
+		GetUserByLogin(ctx, "test").
+		Return(&user.User{ID: 1, Login: "test", PasswordHash: pHash, IsActive: false}, nil)
+
+	got, err := s.Login(ctx, &auth.LoginRequest{Login: "test", Password: "test"})
+	assert.Nil(t, got)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestServer_LoginRehashLegacyPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := storageMock.NewMockAuthenticator(ctrl)
+	s := &Server{
+		storage: mockStorage,
+		guard:   NewLoginGuard(mockStorage, modelAuth.DefaultLoginPolicy()),
+		Signer:  newTestSigner(t),
+	}
+
+	legacyHash, err := bcrypt.GenerateFromPassword([]byte("test"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	mockStorage.EXPECT().GetLoginAttempts(ctx, []string{"login:test"}).Return(nil, nil)
+	mockStorage.EXPECT().ReserveLoginAttempt(ctx, "login:test", modelAuth.DefaultMaxLoginFailures, modelAuth.DefaultLoginLockout).Return(true, nil)
+	mockStorage.EXPECT().ResetLoginFailures(ctx, "login:test").Return(nil)
+	mockStorage.EXPECT().
+		GetUserByLogin(ctx, "test").
+		Return(&user.User{ID: 1, Login: "test", PasswordHash: string(legacyHash), IsActive: true}, nil)
+	// хеш bcrypt после успешного входа заменяется на Argon2id
+	mockStorage.EXPECT().
+		RehashPassword(ctx, 1, string(legacyHash), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ int, _, newHash string) error {
+			assert.True(t, strings.HasPrefix(newHash, "$argon2id$"))
+			assert.True(t, hash.CheckPasswordHash("test", newHash))
+			return nil
+		})
+	mockStorage.EXPECT().GetTOTP(ctx, 1).Return(nil, nil)
+	mockStorage.EXPECT().SaveAccessToken(ctx, 1, gomock.Any(), gomock.Any()).Return(1, nil)
+	mockStorage.EXPECT().SaveRefreshToken(ctx, 1, gomock.Any(), gomock.Any()).Return(nil)
+
+	got, err := s.Login(ctx, &auth.LoginRequest{Login: "test", Password: "test"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, got.AccessToken)
+}
+
 func TestServer_Refresh(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -147,24 +233,28 @@ func TestServer_Refresh(t *testing.T) {
 			mockStorage := storageMock.NewMockAuthenticator(ctrl)
 			s := &Server{
 				storage: mockStorage,
-				Secret:  "secret",
+				Signer:  newTestSigner(t),
 			}
 
+			sessionCreated := time.Now().Add(-time.Hour)
 			mockStorage.EXPECT().
 				GetRefreshToken(tt.args.ctx, tt.args.req.RefreshToken).
 				Return(&modelAuth.RefreshToken{
-					UserID:    tt.userID,
-					ExpiresAt: time.Now().Add(time.Minute),
+					UserID:         tt.userID,
+					ExpiresAt:      time.Now().Add(time.Minute),
+					SessionCreated: sessionCreated,
+					FamilyID:       "family-1",
 				}, nil)
 			mockStorage.EXPECT().
 				RevokeRefreshToken(tt.args.ctx, tt.args.req.RefreshToken).
 				Return(nil)
 			mockStorage.EXPECT().
-				SaveAccessToken(tt.args.ctx, tt.userID, gomock.Any()).
+				SaveAccessToken(tt.args.ctx, tt.userID, gomock.Any(), modelAuth.SessionInfo{CreatedAt: sessionCreated}).
 				Return(tt.accessTokenID, nil)
 
+			// новый токен остается в семействе старого
 			mockStorage.EXPECT().
-				SaveRefreshToken(tt.args.ctx, tt.accessTokenID, gomock.Any()).
+				SaveRefreshToken(tt.args.ctx, tt.accessTokenID, gomock.Any(), "family-1").
 				Return(nil)
 
 			got, err := s.Refresh(tt.args.ctx, tt.args.req)
@@ -175,3 +265,237 @@ func TestServer_Refresh(t *testing.T) {
 		})
 	}
 }
+
+func TestServer_RefreshReuse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	tests := []struct {
+		name         string
+		token        *modelAuth.RefreshToken
+		revokeErr    error
+		revokeFamily bool
+	}{
+		{
+			name: "rotated token replayed",
+			token: &modelAuth.RefreshToken{
+				UserID: 1, ExpiresAt: time.Now().Add(time.Minute), FamilyID: "family-1", IsRevoked: true, IsRotated: true,
+			},
+			revokeFamily: true,
+		},
+		{
+			// токен отозван выходом, а не обменом - это не кража
+			name: "token revoked by logout",
+			token: &modelAuth.RefreshToken{
+				UserID: 1, ExpiresAt: time.Now().Add(time.Minute), FamilyID: "family-1", IsRevoked: true,
+			},
+		},
+		{
+			name: "token rotated concurrently",
+			token: &modelAuth.RefreshToken{
+				UserID: 1, ExpiresAt: time.Now().Add(time.Minute), FamilyID: "family-1",
+			},
+			revokeErr:    status.Error(codes.FailedPrecondition, "refresh token already revoked"),
+			revokeFamily: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storageMock.NewMockAuthenticator(ctrl)
+			tokens := &tokenInvalidator{}
+			s := NewAuthServer(mockStorage, newTestSigner(t), tokens, nil, nil)
+
+			mockStorage.EXPECT().GetRefreshToken(ctx, "stolen").Return(tt.token, nil)
+			if !tt.token.IsRevoked {
+				mockStorage.EXPECT().RevokeRefreshToken(ctx, "stolen").Return(tt.revokeErr)
+			}
+			if tt.revokeFamily {
+				mockStorage.EXPECT().
+					RevokeTokenFamily(ctx, "family-1", account.SecurityEvent{UserID: 1, Type: account.EventRefreshTokenReuse}).
+					Return(int64(1), nil)
+			}
+
+			got, err := s.Refresh(ctx, &auth.RefreshRequest{RefreshToken: "stolen"})
+			assert.Nil(t, got)
+			assert.Equal(t, codes.Unauthenticated, status.Code(err))
+			if tt.revokeFamily {
+				assert.Equal(t, []int{1}, tokens.users)
+			} else {
+				assert.Empty(t, tokens.users)
+			}
+		})
+	}
+}
+
+func TestServer_EnableClientEncryption(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		req        *auth.EnableClientEncryptionRequest
+		storageErr error
+		callSave   bool
+		wantCode   codes.Code
+	}{
+		{
+			name: "success",
+			ctx:  context.WithValue(context.Background(), "userID", 1),
+			req: &auth.EnableClientEncryptionRequest{
+				KdfSalt:         []byte("salt"),
+				WrappedVaultKey: []byte("wrapped"),
+			},
+			callSave: true,
+			wantCode: codes.OK,
+		},
+		{
+			name: "already enabled",
+			ctx:  context.WithValue(context.Background(), "userID", 1),
+			req: &auth.EnableClientEncryptionRequest{
+				KdfSalt:         []byte("salt"),
+				WrappedVaultKey: []byte("wrapped"),
+			},
+			storageErr: status.Error(codes.FailedPrecondition, "client encryption already enabled"),
+			callSave:   true,
+			wantCode:   codes.FailedPrecondition,
+		},
+		{
+			name:     "empty keys",
+			ctx:      context.WithValue(context.Background(), "userID", 1),
+			req:      &auth.EnableClientEncryptionRequest{},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "unauthenticated",
+			ctx:      context.Background(),
+			req:      &auth.EnableClientEncryptionRequest{},
+			wantCode: codes.Unauthenticated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storageMock.NewMockAuthenticator(ctrl)
+			s := &Server{
+				storage: mockStorage,
+				Signer:  newTestSigner(t),
+			}
+
+			if tt.callSave {
+				mockStorage.EXPECT().
+					SaveClientKeys(tt.ctx, 1, tt.req.KdfSalt, tt.req.WrappedVaultKey).
+					Return(tt.storageErr)
+			}
+
+			got, err := s.EnableClientEncryption(tt.ctx, tt.req)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				assert.True(t, got.Success)
+			}
+		})
+	}
+}
+
+// tokenInvalidator заглушка кеша проверки токенов, запоминает сброшенные токены
+type tokenInvalidator struct {
+	tokens []string
+	hashes []string
+	users  []int
+}
+
+func (i *tokenInvalidator) Invalidate(accessToken string, userID int) {
+	i.tokens = append(i.tokens, accessToken)
+}
+
+func (i *tokenInvalidator) InvalidateHash(tokenHash string, userID int) {
+	i.hashes = append(i.hashes, tokenHash)
+}
+
+func (i *tokenInvalidator) InvalidateUser(userID int) {
+	i.users = append(i.users, userID)
+}
+
+func TestServer_Logout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authCtx := context.WithValue(context.WithValue(context.Background(), "userID", 1), "accessToken", "token")
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		callRevoke bool
+		storageErr error
+		wantCode   codes.Code
+	}{
+		{
+			name:       "success",
+			ctx:        authCtx,
+			callRevoke: true,
+			wantCode:   codes.OK,
+		},
+		{
+			name:       "token not found",
+			ctx:        authCtx,
+			callRevoke: true,
+			storageErr: status.Error(codes.NotFound, "token not found"),
+			wantCode:   codes.NotFound,
+		},
+		{
+			name:       "storage error",
+			ctx:        authCtx,
+			callRevoke: true,
+			storageErr: errors.New("connection refused"),
+			wantCode:   codes.Internal,
+		},
+		{
+			name:     "unauthenticated",
+			ctx:      context.Background(),
+			wantCode: codes.Unauthenticated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storageMock.NewMockAuthenticator(ctrl)
+			tokens := &tokenInvalidator{}
+			s := NewAuthServer(mockStorage, newTestSigner(t), tokens, nil, nil)
+
+			if tt.callRevoke {
+				mockStorage.EXPECT().RevokeAccessToken(tt.ctx, 1, "token").Return(tt.storageErr)
+			}
+
+			got, err := s.Logout(tt.ctx, &auth.LogoutRequest{})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				assert.True(t, got.Success)
+				assert.Equal(t, []string{"token"}, tokens.tokens)
+			} else {
+				assert.Empty(t, tokens.tokens)
+			}
+		})
+	}
+}
+
+func TestServer_LogoutAll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := storageMock.NewMockAuthenticator(ctrl)
+	tokens := &tokenInvalidator{}
+	s := NewAuthServer(mockStorage, newTestSigner(t), tokens, nil, nil)
+
+	ctx := context.WithValue(context.Background(), "userID", 1)
+	mockStorage.EXPECT().RevokeUserTokens(ctx, 1).Return(int64(3), nil)
+
+	got, err := s.LogoutAll(ctx, &auth.LogoutAllRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), got.RevokedSessions)
+	assert.Equal(t, []int{1}, tokens.users)
+
+	mockStorage.EXPECT().RevokeUserTokens(ctx, 1).Return(int64(0), errors.New("connection refused"))
+	_, err = s.LogoutAll(ctx, &auth.LogoutAllRequest{})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	_, err = s.LogoutAll(context.Background(), &auth.LogoutAllRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/auth"
+	"github.com/ramil063/secondgodiplom/internal/security/jwt"
+)
+
+// GetPublicKeys открытые ключи проверки подписи токенов доступа
+// по ним другие сервисы проверяют токены без обращения к серверу, ключ выбирается по заголовку kid
+func (s *Server) GetPublicKeys(_ context.Context, _ *auth.GetPublicKeysRequest) (*auth.GetPublicKeysResponse, error) {
+	keys := s.Signer.PublicKeys()
+	result := make([]*auth.PublicKey, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, &auth.PublicKey{
+			KeyId:     key.ID,
+			Algorithm: jwt.SigningAlgorithm,
+			PublicKey: key.Key,
+		})
+	}
+	return &auth.GetPublicKeysResponse{Keys: result}, nil
+}
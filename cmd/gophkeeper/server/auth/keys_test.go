@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/auth"
+	"github.com/ramil063/secondgodiplom/internal/security/jwt"
+)
+
+// newTestSigner ключи подписи токенов для тестов
+func newTestSigner(t *testing.T) *jwt.KeySet {
+	t.Helper()
+	keys, err := jwt.NewEphemeralKeySet()
+	require.NoError(t, err)
+	return keys
+}
+
+func TestServer_GetPublicKeys(t *testing.T) {
+	signer := newTestSigner(t)
+	previousKey, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	require.NoError(t, signer.AddVerificationKey("2026-04", previousKey))
+
+	s := NewAuthServer(nil, signer, &tokenInvalidator{}, nil, nil)
+	got, err := s.GetPublicKeys(context.Background(), &auth.GetPublicKeysRequest{})
+	require.NoError(t, err)
+	require.Len(t, got.Keys, 2)
+
+	currentKey, err := signer.VerificationKey(signer.SigningKeyID())
+	require.NoError(t, err)
+	assert.Equal(t, signer.SigningKeyID(), got.Keys[0].KeyId)
+	assert.Equal(t, "EdDSA", got.Keys[0].Algorithm)
+	assert.Equal(t, []byte(currentKey), got.Keys[0].PublicKey)
+	assert.Equal(t, "2026-04", got.Keys[1].KeyId)
+	assert.Equal(t, []byte(previousKey), got.Keys[1].PublicKey)
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	modelAuth "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/auth"
+)
+
+// sessionInfo данные устройства из запроса для новой пары токенов
+func sessionInfo(ctx context.Context, createdAt time.Time) modelAuth.SessionInfo {
+	info := ClientInfo(ctx)
+	info.CreatedAt = createdAt
+	return info
+}
+
+// ClientInfo данные устройства из запроса: адрес из информации о соединении и user agent из метаданных
+func ClientInfo(ctx context.Context) modelAuth.SessionInfo {
+	var info modelAuth.SessionInfo
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		info.ClientIP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(info.ClientIP); err == nil {
+			info.ClientIP = host
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("user-agent"); len(values) > 0 {
+			info.UserAgent = values[0]
+		}
+	}
+	return info
+}
+
+// ListSessions список активных сессий пользователя на устройствах
+func (s *Server) ListSessions(ctx context.Context, _ *auth.ListSessionsRequest) (*auth.ListSessionsResponse, error) {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
+	}
+	accessToken, ok := ctx.Value("accessToken").(string)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
+	}
+
+	sessions, err := s.storage.ListSessions(ctx, userID, accessToken)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get sessions")
+	}
+
+	result := make([]*auth.Session, 0, len(sessions))
+	for _, session := range sessions {
+		var lastUsedAt string
+		if session.LastUsedAt != nil {
+			lastUsedAt = session.LastUsedAt.String()
+		}
+		result = append(result, &auth.Session{
+			Id:         int64(session.ID),
+			CreatedAt:  session.CreatedAt.String(),
+			LastUsedAt: lastUsedAt,
+			ClientIp:   session.ClientIP,
+			UserAgent:  session.UserAgent,
+			Current:    session.Current,
+		})
+	}
+	return &auth.ListSessionsResponse{Sessions: result}, nil
+}
+
+// RevokeSession завершение одной сессии пользователя
+// результат проверки отозванного токена сбрасывается, чтобы отзыв вступил в силу сразу
+func (s *Server) RevokeSession(ctx context.Context, req *auth.RevokeSessionRequest) (*auth.RevokeSessionResponse, error) {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
+	}
+	if req.Id <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "session id is required")
+	}
+
+	tokenHash, err := s.storage.RevokeSession(ctx, userID, int(req.Id))
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, err
+		}
+		return nil, status.Error(codes.Internal, "failed to revoke session")
+	}
+	s.tokens.InvalidateHash(tokenHash, userID)
+
+	return &auth.RevokeSessionResponse{Success: true}, nil
+}
@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	storageMock "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/mocks"
+	modelAuth "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/auth"
+)
+
+func Test_sessionInfo(t *testing.T) {
+	createdAt := time.Now()
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want modelAuth.SessionInfo
+	}{
+		{
+			name: "peer and user agent",
+			ctx: metadata.NewIncomingContext(
+				peer.NewContext(context.Background(), &peer.Peer{
+					Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 51234},
+				}),
+				metadata.Pairs("user-agent", "gophkeeper-client (laptop) grpc-go/1.72.0"),
+			),
+			want: modelAuth.SessionInfo{
+				ClientIP:  "10.0.0.7",
+				UserAgent: "gophkeeper-client (laptop) grpc-go/1.72.0",
+				CreatedAt: createdAt,
+			},
+		},
+		{
+			name: "no connection info",
+			ctx:  context.Background(),
+			want: modelAuth.SessionInfo{CreatedAt: createdAt},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, sessionInfo(tt.ctx, createdAt))
+		})
+	}
+}
+
+func TestServer_ListSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := storageMock.NewMockAuthenticator(ctrl)
+	s := NewAuthServer(mockStorage, newTestSigner(t), &tokenInvalidator{}, nil, nil)
+
+	ctx := context.WithValue(context.WithValue(context.Background(), "userID", 1), "accessToken", "token")
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	lastUsedAt := createdAt.Add(time.Hour)
+	mockStorage.EXPECT().ListSessions(ctx, 1, "token").Return([]modelAuth.Session{
+		{ID: 2, ClientIP: "10.0.0.7", UserAgent: "laptop", CreatedAt: createdAt, LastUsedAt: &lastUsedAt, Current: true},
+		{ID: 1, ClientIP: "10.0.0.8", UserAgent: "old laptop", CreatedAt: createdAt},
+	}, nil)
+
+	got, err := s.ListSessions(ctx, &auth.ListSessionsRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, got.Sessions, 2)
+	assert.Equal(t, int64(2), got.Sessions[0].Id)
+	assert.Equal(t, lastUsedAt.String(), got.Sessions[0].LastUsedAt)
+	assert.True(t, got.Sessions[0].Current)
+	assert.Equal(t, createdAt.String(), got.Sessions[1].CreatedAt)
+	assert.Empty(t, got.Sessions[1].LastUsedAt)
+	assert.False(t, got.Sessions[1].Current)
+
+	mockStorage.EXPECT().ListSessions(ctx, 1, "token").Return(nil, errors.New("connection refused"))
+	_, err = s.ListSessions(ctx, &auth.ListSessionsRequest{})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	_, err = s.ListSessions(context.WithValue(context.Background(), "userID", 1), &auth.ListSessionsRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestServer_RevokeSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "userID", 1)
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		id         int64
+		callRevoke bool
+		storageErr error
+		wantCode   codes.Code
+	}{
+		{
+			name:       "success",
+			ctx:        ctx,
+			id:         5,
+			callRevoke: true,
+			wantCode:   codes.OK,
+		},
+		{
+			name:       "foreign or unknown session",
+			ctx:        ctx,
+			id:         5,
+			callRevoke: true,
+			storageErr: status.Error(codes.NotFound, "session not found"),
+			wantCode:   codes.NotFound,
+		},
+		{
+			name:       "storage error",
+			ctx:        ctx,
+			id:         5,
+			callRevoke: true,
+			storageErr: errors.New("connection refused"),
+			wantCode:   codes.Internal,
+		},
+		{
+			name:     "empty id",
+			ctx:      ctx,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "unauthenticated",
+			ctx:      context.Background(),
+			id:       5,
+			wantCode: codes.Unauthenticated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storageMock.NewMockAuthenticator(ctrl)
+			tokens := &tokenInvalidator{}
+			s := NewAuthServer(mockStorage, newTestSigner(t), tokens, nil, nil)
+
+			if tt.callRevoke {
+				tokenHash := "hash"
+				if tt.storageErr != nil {
+					tokenHash = ""
+				}
+				mockStorage.EXPECT().RevokeSession(tt.ctx, 1, int(tt.id)).Return(tokenHash, tt.storageErr)
+			}
+
+			got, err := s.RevokeSession(tt.ctx, &auth.RevokeSessionRequest{Id: tt.id})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				assert.True(t, got.Success)
+				assert.Equal(t, []string{"hash"}, tokens.hashes)
+			} else {
+				assert.Empty(t, tokens.hashes)
+			}
+			assert.Empty(t, tokens.users, "токены других сессий не сбрасываются")
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage"
+	modelAuth "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/user"
+	"github.com/ramil063/secondgodiplom/internal/hash"
+	"github.com/ramil063/secondgodiplom/internal/logger"
+)
+
+// Префиксы учета неудачных попыток входа
+const (
+	loginSubjectPrefix = "login:"
+	ipSubjectPrefix    = "ip:"
+)
+
+// ErrInvalidCredentials единая ошибка для неизвестного логина и неверного пароля,
+// чтобы по ответу нельзя было узнать, зарегистрирован ли логин
+var ErrInvalidCredentials = status.Error(codes.Unauthenticated, "invalid login or password")
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+// LoginGuard защита входа по паролю от подбора
+// считает неудачные попытки по логину и по IP адресу клиента, задерживает и блокирует вход
+type LoginGuard struct {
+	storage storage.LoginThrottler
+	policy  modelAuth.LoginPolicy
+	now     func() time.Time
+}
+
+// NewLoginGuard инициализация защиты входа от подбора пароля
+func NewLoginGuard(storage storage.LoginThrottler, policy modelAuth.LoginPolicy) *LoginGuard {
+	return &LoginGuard{
+		storage: storage,
+		policy:  policy,
+		now:     time.Now,
+	}
+}
+
+// Check проверка, что попытка входа разрешена: логин и IP адрес не заблокированы и задержка истекла
+// разрешенная попытка учитывается как неудачная до проверки пароля, поэтому параллельные запросы
+// получают не больше MaxFailures проверок пароля; успешный вход снимает попытку (Succeed)
+func (g *LoginGuard) Check(ctx context.Context, login string) error {
+	subjects := g.subjects(ctx, login)
+	attempts, err := g.storage.GetLoginAttempts(ctx, subjects)
+	if err != nil {
+		return status.Error(codes.Internal, "failed to check login attempts")
+	}
+
+	now := g.now()
+	var retryAt time.Time
+	for _, attempt := range attempts {
+		if attempt.LockedUntil != nil && attempt.LockedUntil.After(retryAt) {
+			retryAt = *attempt.LockedUntil
+		}
+		if attempt.Subject == loginSubjectPrefix+login {
+			next := attempt.LastFailureAt.Add(g.policy.Delay(attempt.Failures))
+			if next.After(retryAt) {
+				retryAt = next
+			}
+		}
+	}
+	if retryAt.After(now) {
+		return tooManyAttempts(retryAt.Sub(now))
+	}
+
+	for i, subject := range subjects {
+		reserved, err := g.storage.ReserveLoginAttempt(ctx, subject, g.maxFailures(login, subject), g.policy.Lockout)
+		if err == nil && reserved {
+			continue
+		}
+		// Попытки, учтенные по другим ключам, снимаются: вход не выполнялся
+		g.release(ctx, subjects[:i])
+		if err != nil {
+			return status.Error(codes.Internal, "failed to check login attempts")
+		}
+		return tooManyAttempts(g.policy.Lockout)
+	}
+	return nil
+}
+
+// Succeed сброс неудачных попыток по логину после успешного входа
+// со счетчика IP адреса снимается только попытка этого входа: иначе его можно обнулять входом в свою учетную запись
+func (g *LoginGuard) Succeed(ctx context.Context, login string) {
+	if err := g.storage.ResetLoginFailures(ctx, loginSubjectPrefix+login); err != nil {
+		logger.WriteErrorLog(err.Error())
+	}
+	g.release(ctx, g.subjects(ctx, login)[1:])
+}
+
+// release снятие учтенных попыток входа
+func (g *LoginGuard) release(ctx context.Context, subjects []string) {
+	for _, subject := range subjects {
+		if err := g.storage.ReleaseLoginAttempt(ctx, subject); err != nil {
+			logger.WriteErrorLog(err.Error())
+		}
+	}
+}
+
+// maxFailures предел неудачных попыток для ключа учета
+func (g *LoginGuard) maxFailures(login string, subject string) int {
+	if subject == loginSubjectPrefix+login {
+		return g.policy.MaxFailures
+	}
+	return g.policy.MaxIPFailures
+}
+
+// tooManyAttempts ошибка исчерпанных попыток входа с временем до следующей попытки
+func tooManyAttempts(retryIn time.Duration) error {
+	return status.Error(
+		codes.ResourceExhausted,
+		fmt.Sprintf("too many login attempts, retry in %s", retryIn.Round(time.Second)))
+}
+
+// VerifyPassword проверка пароля пользователя
+// для неизвестного пользователя (u == nil) пароль сравнивается с заглушкой, чтобы время ответа не выдавало логин
+func VerifyPassword(u *user.User, password string) bool {
+	if u == nil {
+		hash.CheckPasswordHash(password, getDummyHash())
+		return false
+	}
+	return hash.CheckPasswordHash(password, u.PasswordHash)
+}
+
+// subjects ключи учета попыток: логин и IP адрес клиента, если он известен
+func (g *LoginGuard) subjects(ctx context.Context, login string) []string {
+	subjects := []string{loginSubjectPrefix + login}
+	if ip := ClientInfo(ctx).ClientIP; ip != "" {
+		subjects = append(subjects, ipSubjectPrefix+ip)
+	}
+	return subjects
+}
+
+// getDummyHash хеш случайного пароля той же стоимости, что и у пользователей
+func getDummyHash() string {
+	dummyHashOnce.Do(func() {
+		var err error
+		dummyHash, err = hash.GetPasswordHash(fmt.Sprintf("dummy-%d", time.Now().UnixNano()))
+		if err != nil {
+			logger.WriteErrorLog(err.Error())
+		}
+	})
+	return dummyHash
+}
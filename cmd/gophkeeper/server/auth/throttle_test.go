@@ -0,0 +1,293 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	storageMock "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/mocks"
+	modelAuth "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/user"
+	"github.com/ramil063/secondgodiplom/internal/hash"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/auth"
+)
+
+func TestLoginPolicy_Delay(t *testing.T) {
+	policy := modelAuth.LoginPolicy{BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	assert.Equal(t, time.Duration(0), policy.Delay(0))
+	assert.Equal(t, time.Second, policy.Delay(1))
+	assert.Equal(t, 2*time.Second, policy.Delay(2))
+	assert.Equal(t, 8*time.Second, policy.Delay(4))
+	assert.Equal(t, 10*time.Second, policy.Delay(5))
+	assert.Equal(t, 10*time.Second, policy.Delay(1000))
+}
+
+func TestLoginGuard_Check(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	lockedUntil := now.Add(10 * time.Minute)
+	expiredLock := now.Add(-time.Minute)
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 51234},
+	})
+
+	tests := []struct {
+		name     string
+		attempts []modelAuth.LoginAttempts
+		err      error
+		reserved []bool
+		released []string
+		wantCode codes.Code
+	}{
+		{
+			name:     "no failures",
+			reserved: []bool{true, true},
+			wantCode: codes.OK,
+		},
+		{
+			name: "delay passed",
+			attempts: []modelAuth.LoginAttempts{
+				{Subject: "login:alice", Failures: 3, LastFailureAt: now.Add(-5 * time.Second)},
+			},
+			reserved: []bool{true, true},
+			wantCode: codes.OK,
+		},
+		{
+			// предел исчерпан параллельной попыткой после чтения счетчиков
+			name:     "login limit reached concurrently",
+			reserved: []bool{false},
+			wantCode: codes.ResourceExhausted,
+		},
+		{
+			name:     "ip limit reached concurrently",
+			reserved: []bool{true, false},
+			released: []string{"login:alice"},
+			wantCode: codes.ResourceExhausted,
+		},
+		{
+			name: "within delay",
+			attempts: []modelAuth.LoginAttempts{
+				{Subject: "login:alice", Failures: 3, LastFailureAt: now.Add(-time.Second)},
+			},
+			wantCode: codes.ResourceExhausted,
+		},
+		{
+			name: "login locked",
+			attempts: []modelAuth.LoginAttempts{
+				{Subject: "login:alice", Failures: 5, LastFailureAt: now.Add(-5 * time.Minute), LockedUntil: &lockedUntil},
+			},
+			wantCode: codes.ResourceExhausted,
+		},
+		{
+			name: "lock expired",
+			attempts: []modelAuth.LoginAttempts{
+				{Subject: "login:alice", Failures: 5, LastFailureAt: now.Add(-16 * time.Minute), LockedUntil: &expiredLock},
+			},
+			reserved: []bool{true, true},
+			wantCode: codes.OK,
+		},
+		{
+			// с IP адреса без блокировки попытки не задерживаются
+			name: "ip failures without lock",
+			attempts: []modelAuth.LoginAttempts{
+				{Subject: "ip:10.0.0.7", Failures: 10, LastFailureAt: now},
+			},
+			reserved: []bool{true, true},
+			wantCode: codes.OK,
+		},
+		{
+			name: "ip locked",
+			attempts: []modelAuth.LoginAttempts{
+				{Subject: "ip:10.0.0.7", Failures: 50, LastFailureAt: now, LockedUntil: &lockedUntil},
+			},
+			wantCode: codes.ResourceExhausted,
+		},
+		{
+			name:     "storage error",
+			err:      errors.New("connection refused"),
+			wantCode: codes.Internal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storageMock.NewMockLoginThrottler(ctrl)
+			policy := modelAuth.DefaultLoginPolicy()
+			guard := NewLoginGuard(mockStorage, policy)
+			guard.now = func() time.Time { return now }
+
+			subjects := []string{"login:alice", "ip:10.0.0.7"}
+			limits := []int{policy.MaxFailures, policy.MaxIPFailures}
+			mockStorage.EXPECT().GetLoginAttempts(ctx, subjects).Return(tt.attempts, tt.err)
+			for i, reserved := range tt.reserved {
+				mockStorage.EXPECT().ReserveLoginAttempt(ctx, subjects[i], limits[i], policy.Lockout).Return(reserved, nil)
+			}
+			for _, subject := range tt.released {
+				mockStorage.EXPECT().ReleaseLoginAttempt(ctx, subject).Return(nil)
+			}
+
+			err := guard.Check(ctx, "alice")
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+func TestLoginGuard_Succeed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := storageMock.NewMockLoginThrottler(ctrl)
+	policy := modelAuth.DefaultLoginPolicy()
+	guard := NewLoginGuard(mockStorage, policy)
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 51234},
+	})
+
+	// с IP адреса снимается только попытка этого входа, остальные неудачи остаются
+	mockStorage.EXPECT().ResetLoginFailures(ctx, "login:alice").Return(nil)
+	mockStorage.EXPECT().ReleaseLoginAttempt(ctx, "ip:10.0.0.7").Return(nil)
+	guard.Succeed(ctx, "alice")
+}
+
+func TestServer_Login_InvalidCredentials(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pHash, err := hash.GetPasswordHash("secret")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		login   string
+		user    *user.User
+		userErr error
+	}{
+		{
+			name:  "wrong password",
+			login: "alice",
+			user:  &user.User{ID: 1, Login: "alice", PasswordHash: pHash, IsActive: true},
+		},
+		{
+			name:    "unknown login",
+			login:   "bob",
+			userErr: status.Error(codes.NotFound, "user not found"),
+		},
+	}
+	var errs []error
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storageMock.NewMockAuthenticator(ctrl)
+			policy := modelAuth.DefaultLoginPolicy()
+			s := NewAuthServer(mockStorage, newTestSigner(t), &tokenInvalidator{}, nil, NewLoginGuard(mockStorage, policy))
+
+			mockStorage.EXPECT().GetLoginAttempts(ctx, []string{"login:" + tt.login}).Return(nil, nil)
+			mockStorage.EXPECT().GetUserByLogin(ctx, tt.login).Return(tt.user, tt.userErr)
+			mockStorage.EXPECT().ReserveLoginAttempt(ctx, "login:"+tt.login, policy.MaxFailures, policy.Lockout).Return(true, nil)
+
+			got, err := s.Login(ctx, &auth.LoginRequest{Login: tt.login, Password: "wrong"})
+			assert.Nil(t, got)
+			errs = append(errs, err)
+		})
+	}
+	// ответ не выдает, зарегистрирован ли логин
+	require.Len(t, errs, 2)
+	assert.Equal(t, codes.Unauthenticated, status.Code(errs[0]))
+	assert.Equal(t, errs[0], errs[1])
+}
+
+func TestServer_Login_Locked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := storageMock.NewMockAuthenticator(ctrl)
+	s := NewAuthServer(mockStorage, newTestSigner(t), &tokenInvalidator{}, nil, NewLoginGuard(mockStorage, modelAuth.DefaultLoginPolicy()))
+	ctx := context.Background()
+	lockedUntil := time.Now().Add(time.Hour)
+
+	// при блокировке пароль не проверяется
+	mockStorage.EXPECT().
+		GetLoginAttempts(ctx, []string{"login:alice"}).
+		Return([]modelAuth.LoginAttempts{
+			{Subject: "login:alice", Failures: 5, LastFailureAt: time.Now(), LockedUntil: &lockedUntil},
+		}, nil)
+
+	got, err := s.Login(ctx, &auth.LoginRequest{Login: "alice", Password: "secret"})
+	assert.Nil(t, got)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestServer_Login_ConcurrentFailures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pHash, err := hash.GetPasswordHash("secret")
+	require.NoError(t, err)
+	policy := modelAuth.DefaultLoginPolicy()
+	mockStorage := storageMock.NewMockAuthenticator(ctrl)
+	s := NewAuthServer(mockStorage, newTestSigner(t), &tokenInvalidator{}, nil, NewLoginGuard(mockStorage, policy))
+	ctx := context.Background()
+
+	// все запросы читают счетчики до того, как учтена хотя бы одна неудача
+	mockStorage.EXPECT().GetLoginAttempts(ctx, []string{"login:alice"}).Return(nil, nil).AnyTimes()
+	var mu sync.Mutex
+	failures := 0
+	mockStorage.EXPECT().
+		ReserveLoginAttempt(ctx, "login:alice", policy.MaxFailures, policy.Lockout).
+		DoAndReturn(func(context.Context, string, int, time.Duration) (bool, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if failures >= policy.MaxFailures {
+				return false, nil
+			}
+			failures++
+			return true, nil
+		}).
+		AnyTimes()
+	var verified atomic.Int32
+	mockStorage.EXPECT().
+		GetUserByLogin(ctx, "alice").
+		DoAndReturn(func(context.Context, string) (*user.User, error) {
+			verified.Add(1)
+			return &user.User{ID: 1, Login: "alice", PasswordHash: pHash, IsActive: true}, nil
+		}).
+		AnyTimes()
+
+	attempts := 4 * policy.MaxFailures
+	codesCh := make(chan codes.Code, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.Login(ctx, &auth.LoginRequest{Login: "alice", Password: "wrong"})
+			codesCh <- status.Code(err)
+		}()
+	}
+	wg.Wait()
+	close(codesCh)
+
+	// пароль проверяется не больше MaxFailures раз, остальные попытки отклоняются без проверки
+	assert.Equal(t, int32(policy.MaxFailures), verified.Load())
+	got := map[codes.Code]int{}
+	for code := range codesCh {
+		got[code]++
+	}
+	assert.Equal(t, map[codes.Code]int{
+		codes.Unauthenticated:   policy.MaxFailures,
+		codes.ResourceExhausted: attempts - policy.MaxFailures,
+	}, got)
+}
@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	modelAuth "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/auth"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto"
+	"github.com/ramil063/secondgodiplom/internal/security/totp"
+)
+
+// totpIssuer название сервиса в приложении-аутентификаторе
+const totpIssuer = "GophKeeper"
+
+// EnrollTOTP подключение второго фактора
+// секрет шифруется ключом пользователя, коды восстановления сохраняются хешами и показываются один раз
+// до подтверждения подключение можно начать заново
+func (s *Server) EnrollTOTP(ctx context.Context, _ *auth.EnrollTOTPRequest) (*auth.EnrollTOTPResponse, error) {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
+	}
+
+	current, err := s.storage.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get second factor")
+	}
+	if current != nil && current.Confirmed {
+		return nil, status.Error(codes.FailedPrecondition, "totp already enabled")
+	}
+
+	u, err := s.storage.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get user")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to generate secret")
+	}
+	encryptor, err := s.keys.GetEncryptor(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get encryptor")
+	}
+	encryptedSecret, algorithm, iv, err := encryptor.Encrypt(secret, crypto.TOTPSecretAAD(userID))
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to encrypt secret")
+	}
+
+	recoveryCodes, err := totp.GenerateRecoveryCodes(totp.RecoveryCodesCount)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to generate recovery codes")
+	}
+	normalizedCodes := make([]string, 0, len(recoveryCodes))
+	for _, code := range recoveryCodes {
+		normalizedCodes = append(normalizedCodes, totp.NormalizeRecoveryCode(code))
+	}
+
+	err = s.storage.SaveTOTP(ctx, userID, &modelAuth.TOTP{
+		EncryptedSecret: encryptedSecret,
+		IV:              iv,
+		Algorithm:       algorithm,
+		KeyVersion:      s.keys.KeyVersion(),
+	}, normalizedCodes)
+	if err != nil {
+		if status.Code(err) == codes.FailedPrecondition {
+			return nil, err
+		}
+		return nil, status.Error(codes.Internal, "failed to save second factor")
+	}
+
+	return &auth.EnrollTOTPResponse{
+		Secret:        totp.EncodeSecret(secret),
+		OtpauthUri:    totp.URI(totpIssuer, u.Login, secret),
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+// ConfirmTOTP подтверждение подключения второго фактора кодом из приложения
+// после подтверждения вход требует код
+func (s *Server) ConfirmTOTP(ctx context.Context, req *auth.ConfirmTOTPRequest) (*auth.ConfirmTOTPResponse, error) {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
+	}
+
+	state, err := s.storage.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get second factor")
+	}
+	if state == nil {
+		return nil, status.Error(codes.FailedPrecondition, "totp not enrolled")
+	}
+	if state.Confirmed {
+		return nil, status.Error(codes.FailedPrecondition, "totp already enabled")
+	}
+
+	secret, err := s.decryptTOTPSecret(ctx, userID, state)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to decrypt secret")
+	}
+	step, valid := totp.Validate(secret, req.Code, time.Now(), state.LastUsedStep)
+	if !valid {
+		return nil, status.Error(codes.InvalidArgument, "invalid code")
+	}
+
+	err = s.storage.ConfirmTOTP(ctx, userID, step)
+	if err != nil {
+		if status.Code(err) == codes.FailedPrecondition {
+			return nil, err
+		}
+		return nil, status.Error(codes.Internal, "failed to confirm second factor")
+	}
+	return &auth.ConfirmTOTPResponse{Success: true}, nil
+}
+
+// VerifyTOTP завершение входа со вторым фактором
+// на один вход дается TOTPChallengeMaxAttempts попыток, после успеха вход завершить повторно нельзя
+func (s *Server) VerifyTOTP(ctx context.Context, req *auth.VerifyTOTPRequest) (*auth.LoginResponse, error) {
+	if req.Challenge == "" || req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "challenge and code are required")
+	}
+
+	userID, err := s.storage.UseLoginChallengeAttempt(ctx, req.Challenge)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired challenge")
+		}
+		return nil, status.Error(codes.Internal, "failed to check challenge")
+	}
+
+	// Ключ пользователя для расшифровки секрета определяется по контексту
+	userCtx := context.WithValue(ctx, "userID", userID)
+	valid, err := s.checkSecondFactor(userCtx, userID, req.Code)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to check code")
+	}
+	if !valid {
+		return nil, status.Error(codes.Unauthenticated, "invalid code")
+	}
+
+	err = s.storage.CompleteLoginChallenge(ctx, req.Challenge)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired challenge")
+		}
+		return nil, status.Error(codes.Internal, "failed to complete challenge")
+	}
+
+	u, err := s.storage.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get user")
+	}
+	return s.issueTokens(ctx, u)
+}
+
+// checkSecondFactor проверка кода из приложения или кода восстановления
+// принятый код отмечается использованным и повторно не принимается
+func (s *Server) checkSecondFactor(ctx context.Context, userID int, code string) (bool, error) {
+	if totp.IsRecoveryCode(code) {
+		return s.storage.UseRecoveryCode(ctx, userID, totp.NormalizeRecoveryCode(code))
+	}
+
+	state, err := s.storage.GetTOTP(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if state == nil || !state.Confirmed {
+		return false, nil
+	}
+
+	secret, err := s.decryptTOTPSecret(ctx, userID, state)
+	if err != nil {
+		return false, err
+	}
+	step, valid := totp.Validate(secret, code, time.Now(), state.LastUsedStep)
+	if !valid {
+		return false, nil
+	}
+	return s.storage.UseTOTPStep(ctx, userID, step)
+}
+
+// decryptTOTPSecret расшифровка секрета второго фактора ключом пользователя
+func (s *Server) decryptTOTPSecret(ctx context.Context, userID int, state *modelAuth.TOTP) ([]byte, error) {
+	decryptor, err := s.keys.GetDecryptor(ctx, state.KeyVersion)
+	if err != nil {
+		return nil, err
+	}
+	return decryptor.Decrypt(state.EncryptedSecret, state.IV, state.Algorithm, crypto.TOTPSecretAAD(userID))
+}
@@ -0,0 +1,297 @@
+package auth
+
+import (
+	"context"
+	"encoding/base32"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	storageMock "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/mocks"
+	modelAuth "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/user"
+	"github.com/ramil063/secondgodiplom/internal/hash"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/auth"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto"
+	"github.com/ramil063/secondgodiplom/internal/security/totp"
+)
+
+var testUserKey = []byte("12345678901234567890123456789012")
+
+// userKeys ключи пользователей для тестов, пользователь обязан быть в контексте
+type userKeys struct{}
+
+func (k *userKeys) GetEncryptor(ctx context.Context) (crypto.Encryptor, error) {
+	if _, ok := ctx.Value("userID").(int); !ok {
+		return nil, crypto.ErrNoUserInContext
+	}
+	return crypto.NewEncryptor(testUserKey, crypto.DefaultAlgorithm)
+}
+
+func (k *userKeys) GetDecryptor(ctx context.Context, _ int) (crypto.Decryptor, error) {
+	if _, ok := ctx.Value("userID").(int); !ok {
+		return nil, crypto.ErrNoUserInContext
+	}
+	return crypto.NewDecryptor(testUserKey)
+}
+
+func (k *userKeys) KeyVersion() int {
+	return 1
+}
+
+// encryptedTOTP подключенный второй фактор с известным секретом
+func encryptedTOTP(t *testing.T, userID int, secret []byte, confirmed bool) *modelAuth.TOTP {
+	encryptor, err := crypto.NewEncryptor(testUserKey, crypto.DefaultAlgorithm)
+	require.NoError(t, err)
+	encryptedSecret, algorithm, iv, err := encryptor.Encrypt(secret, crypto.TOTPSecretAAD(userID))
+	require.NoError(t, err)
+	return &modelAuth.TOTP{
+		EncryptedSecret: encryptedSecret,
+		IV:              iv,
+		Algorithm:       algorithm,
+		KeyVersion:      1,
+		Confirmed:       confirmed,
+	}
+}
+
+func TestServer_Login_TOTPRequired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := storageMock.NewMockAuthenticator(ctrl)
+	guard := NewLoginGuard(mockStorage, modelAuth.DefaultLoginPolicy())
+	s := NewAuthServer(mockStorage, newTestSigner(t), &tokenInvalidator{}, &userKeys{}, guard)
+
+	ctx := context.Background()
+	pHash, err := hash.GetPasswordHash("test")
+	require.NoError(t, err)
+
+	mockStorage.EXPECT().GetLoginAttempts(ctx, []string{"login:test"}).Return(nil, nil)
+	mockStorage.EXPECT().ReserveLoginAttempt(ctx, "login:test", modelAuth.DefaultMaxLoginFailures, modelAuth.DefaultLoginLockout).Return(true, nil)
+	mockStorage.EXPECT().ResetLoginFailures(ctx, "login:test").Return(nil)
+
+	mockStorage.EXPECT().GetUserByLogin(ctx, "test").Return(&user.User{ID: 1, PasswordHash: pHash, IsActive: true}, nil)
+	mockStorage.EXPECT().GetTOTP(ctx, 1).Return(&modelAuth.TOTP{Confirmed: true}, nil)
+	mockStorage.EXPECT().SaveLoginChallenge(ctx, 1, gomock.Any()).Return(nil)
+
+	got, err := s.Login(ctx, &auth.LoginRequest{Login: "test", Password: "test"})
+	require.NoError(t, err)
+	assert.True(t, got.TotpRequired)
+	assert.NotEmpty(t, got.Challenge)
+	assert.Equal(t, modelAuth.TOTPChallengeExpiredSeconds, got.ExpiresIn)
+	assert.Empty(t, got.AccessToken)
+	assert.Empty(t, got.RefreshToken)
+}
+
+func TestServer_EnrollTOTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := storageMock.NewMockAuthenticator(ctrl)
+	s := NewAuthServer(mockStorage, newTestSigner(t), &tokenInvalidator{}, &userKeys{}, nil)
+	ctx := context.WithValue(context.Background(), "userID", 1)
+
+	var saved *modelAuth.TOTP
+	var savedCodes []string
+	mockStorage.EXPECT().GetTOTP(ctx, 1).Return(nil, nil)
+	mockStorage.EXPECT().GetUserByID(ctx, 1).Return(&user.User{ID: 1, Login: "alice"}, nil)
+	mockStorage.EXPECT().
+		SaveTOTP(ctx, 1, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ int, state *modelAuth.TOTP, recoveryCodes []string) error {
+			saved = state
+			savedCodes = recoveryCodes
+			return nil
+		})
+
+	got, err := s.EnrollTOTP(ctx, &auth.EnrollTOTPRequest{})
+	require.NoError(t, err)
+	assert.Contains(t, got.OtpauthUri, "otpauth://totp/GophKeeper:alice?")
+	assert.Len(t, got.RecoveryCodes, totp.RecoveryCodesCount)
+
+	// Сохранен зашифрованный секрет, который выдан пользователю
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(got.Secret)
+	require.NoError(t, err)
+	assert.NotEqual(t, secret, saved.EncryptedSecret)
+	decryptor, err := crypto.NewDecryptor(testUserKey)
+	require.NoError(t, err)
+	decrypted, err := decryptor.Decrypt(saved.EncryptedSecret, saved.IV, saved.Algorithm, crypto.TOTPSecretAAD(1))
+	require.NoError(t, err)
+	assert.Equal(t, secret, decrypted)
+
+	// Коды восстановления сохраняются в нормализованном виде
+	require.Len(t, savedCodes, totp.RecoveryCodesCount)
+	assert.Equal(t, totp.NormalizeRecoveryCode(got.RecoveryCodes[0]), savedCodes[0])
+
+	mockStorage.EXPECT().GetTOTP(ctx, 1).Return(&modelAuth.TOTP{Confirmed: true}, nil)
+	_, err = s.EnrollTOTP(ctx, &auth.EnrollTOTPRequest{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = s.EnrollTOTP(context.Background(), &auth.EnrollTOTPRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestServer_ConfirmTOTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	secret := []byte("12345678901234567890")
+	ctx := context.WithValue(context.Background(), "userID", 1)
+
+	tests := []struct {
+		name        string
+		state       *modelAuth.TOTP
+		code        string
+		callConfirm bool
+		wantCode    codes.Code
+	}{
+		{
+			name:        "valid code",
+			state:       encryptedTOTP(t, 1, secret, false),
+			code:        totp.Code(secret, totp.Step(time.Now())),
+			callConfirm: true,
+			wantCode:    codes.OK,
+		},
+		{
+			name:     "invalid code",
+			state:    encryptedTOTP(t, 1, secret, false),
+			code:     "000000",
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			// секрет другого пользователя не расшифровывается
+			name:     "foreign secret",
+			state:    encryptedTOTP(t, 2, secret, false),
+			code:     totp.Code(secret, totp.Step(time.Now())),
+			wantCode: codes.Internal,
+		},
+		{
+			name:     "not enrolled",
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name:     "already enabled",
+			state:    encryptedTOTP(t, 1, secret, true),
+			wantCode: codes.FailedPrecondition,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storageMock.NewMockAuthenticator(ctrl)
+			s := NewAuthServer(mockStorage, newTestSigner(t), &tokenInvalidator{}, &userKeys{}, nil)
+
+			mockStorage.EXPECT().GetTOTP(ctx, 1).Return(tt.state, nil)
+			if tt.callConfirm {
+				mockStorage.EXPECT().ConfirmTOTP(ctx, 1, gomock.Any()).Return(nil)
+			}
+
+			_, err := s.ConfirmTOTP(ctx, &auth.ConfirmTOTPRequest{Code: tt.code})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+func TestServer_VerifyTOTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	secret := []byte("12345678901234567890")
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		code         string
+		challengeErr error
+		prepare      func(mockStorage *storageMock.MockAuthenticator)
+		wantCode     codes.Code
+	}{
+		{
+			name: "valid code",
+			code: totp.Code(secret, totp.Step(time.Now())),
+			prepare: func(mockStorage *storageMock.MockAuthenticator) {
+				mockStorage.EXPECT().GetTOTP(gomock.Any(), 1).Return(encryptedTOTP(t, 1, secret, true), nil)
+				mockStorage.EXPECT().UseTOTPStep(gomock.Any(), 1, totp.Step(time.Now())).Return(true, nil)
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "replayed code",
+			code: totp.Code(secret, totp.Step(time.Now())),
+			prepare: func(mockStorage *storageMock.MockAuthenticator) {
+				mockStorage.EXPECT().GetTOTP(gomock.Any(), 1).Return(encryptedTOTP(t, 1, secret, true), nil)
+				mockStorage.EXPECT().UseTOTPStep(gomock.Any(), 1, gomock.Any()).Return(false, nil)
+			},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "wrong code",
+			code: "000000",
+			prepare: func(mockStorage *storageMock.MockAuthenticator) {
+				mockStorage.EXPECT().GetTOTP(gomock.Any(), 1).Return(encryptedTOTP(t, 1, secret, true), nil)
+			},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "recovery code",
+			code: "ABCD-efgh-ijkl-mnop",
+			prepare: func(mockStorage *storageMock.MockAuthenticator) {
+				mockStorage.EXPECT().UseRecoveryCode(gomock.Any(), 1, "abcdefghijklmnop").Return(true, nil)
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "used recovery code",
+			code: "abcd-efgh-ijkl-mnop",
+			prepare: func(mockStorage *storageMock.MockAuthenticator) {
+				mockStorage.EXPECT().UseRecoveryCode(gomock.Any(), 1, "abcdefghijklmnop").Return(false, nil)
+			},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:         "expired or exhausted challenge",
+			code:         "123456",
+			challengeErr: status.Error(codes.NotFound, "challenge not found"),
+			wantCode:     codes.Unauthenticated,
+		},
+		{
+			name:         "storage error",
+			code:         "123456",
+			challengeErr: errors.New("connection refused"),
+			wantCode:     codes.Internal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storageMock.NewMockAuthenticator(ctrl)
+			s := NewAuthServer(mockStorage, newTestSigner(t), &tokenInvalidator{}, &userKeys{}, nil)
+
+			mockStorage.EXPECT().UseLoginChallengeAttempt(ctx, "challenge").Return(1, tt.challengeErr)
+			if tt.prepare != nil {
+				tt.prepare(mockStorage)
+			}
+			if tt.wantCode == codes.OK {
+				mockStorage.EXPECT().CompleteLoginChallenge(ctx, "challenge").Return(nil)
+				mockStorage.EXPECT().GetUserByID(ctx, 1).Return(&user.User{ID: 1, KdfSalt: []byte("salt")}, nil)
+				mockStorage.EXPECT().SaveAccessToken(ctx, 1, gomock.Any(), gomock.Any()).Return(7, nil)
+				mockStorage.EXPECT().SaveRefreshToken(ctx, 7, gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			got, err := s.VerifyTOTP(ctx, &auth.VerifyTOTPRequest{Challenge: "challenge", Code: tt.code})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				assert.NotEmpty(t, got.AccessToken)
+				assert.NotEmpty(t, got.RefreshToken)
+				assert.Equal(t, []byte("salt"), got.KdfSalt)
+			}
+		})
+	}
+
+	_, err := NewAuthServer(nil, newTestSigner(t), &tokenInvalidator{}, &userKeys{}, nil).
+		VerifyTOTP(ctx, &auth.VerifyTOTPRequest{Challenge: "challenge"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -13,6 +13,7 @@ import (
 	itemsConstants "github.com/ramil063/secondgodiplom/internal/constants/items"
 	bankcardsPb "github.com/ramil063/secondgodiplom/internal/proto/gen/items/bankcard"
 	"github.com/ramil063/secondgodiplom/internal/security/crypto"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/vault"
 )
 
 // Server надстройка над стандартным gRPC сервером(логика работы с банковскими картами)
@@ -20,20 +21,32 @@ import (
 type Server struct {
 	bankcardsPb.UnimplementedServiceServer
 
-	storage   items.Itemer
-	Encryptor crypto.Encryptor
-	Decryptor crypto.Decryptor
+	storage items.Itemer
+	keys    crypto.KeyResolver
 }
 
-// NewServer инициализация сервера, шифровальщика, дешифровщика и структуры для работы с хранилищем
-func NewServer(storage items.Itemer, encryptor crypto.Encryptor, decryptor crypto.Decryptor) *Server {
+// NewServer инициализация сервера, получателя ключей шифрования пользователей и структуры для работы с хранилищем
+func NewServer(storage items.Itemer, keys crypto.KeyResolver) *Server {
 	return &Server{
-		storage:   storage,
-		Encryptor: encryptor,
-		Decryptor: decryptor,
+		storage: storage,
+		keys:    keys,
 	}
 }
 
+// ReserveCardDataID резервирование идентификатора записи для банковской карты, зашифрованных на клиенте
+// клиент привязывает шифротекст к идентификатору до создания записи (vault.ItemAAD)
+func (s *Server) ReserveCardDataID(ctx context.Context, _ *empty.Empty) (*bankcardsPb.ReservedID, error) {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
+	}
+	itemID, err := s.storage.ReserveClientItemID(ctx, int64(userID))
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to reserve item id")
+	}
+	return &bankcardsPb.ReservedID{Id: itemID}, nil
+}
+
 // CreateCardData создание записи о банковской карте
 // так же сохранение метаданных о ней
 // все специфичные данные шифруются
@@ -42,6 +55,12 @@ func (s *Server) CreateCardData(ctx context.Context, req *bankcardsPb.CreateCard
 	if !ok {
 		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
 	}
+	// Идентификатор нужен заранее, так как шифротекст привязывается к записи
+	itemID, err := s.newItemID(ctx, req.Id, req.EncryptedPayload)
+	if err != nil {
+		return nil, err
+	}
+
 	// 1. Создаем структуру для шифрования
 	sensitiveData := &itemModel.SensitiveBankCardData{
 		Number:          req.Number,
@@ -51,36 +70,45 @@ func (s *Server) CreateCardData(ctx context.Context, req *bankcardsPb.CreateCard
 		Holder:          req.Holder,
 	}
 
-	// 2. Сериализуем в JSON
-	jsonData, err := sensitiveData.ToJSON()
-	if err != nil {
-		return nil, status.Error(codes.Internal, "failed to serialize data")
-	}
-
-	// 3. Шифруем всю структуру
-	encryptedData, algorithm, iv, err := s.Encryptor.Encrypt(jsonData)
+	// 2-3. Шифруем всю структуру, если данные не зашифрованы на клиенте
+	aad := crypto.ItemAAD(int64(userID), itemID, itemsConstants.TypeCard)
+	encryptedData, algorithm, iv, err := s.encryptSensitiveData(ctx, aad, sensitiveData, req.EncryptedPayload)
 	if err != nil {
-		return nil, status.Error(codes.Internal, "failed to encrypt data")
+		return nil, err
 	}
 
-	// 4. Сохраняем в основную таблицу
-	itemID, err := s.storage.SaveEncryptedData(ctx, &itemModel.EncryptedItem{
-		UserID:              int64(userID),
-		Type:                itemsConstants.TypeCard,
-		Data:                encryptedData,
-		Description:         req.Description,
-		EncryptionAlgorithm: algorithm,
-		Iv:                  iv,
-	})
+	// 4. Сохраняем запись и метаданные в одной транзакции
+	err = s.storage.WithTx(ctx, func(ctx context.Context) error {
+		if err = s.claimItemID(ctx, userID, itemID, req.EncryptedPayload); err != nil {
+			return err
+		}
+		itemID, err = s.storage.SaveEncryptedData(ctx, &itemModel.EncryptedItem{
+			ID:                  itemID,
+			UserID:              int64(userID),
+			Type:                itemsConstants.TypeCard,
+			Data:                encryptedData,
+			Description:         req.Description,
+			EncryptionAlgorithm: algorithm,
+			Iv:                  iv,
+			KeyVersion:          s.keys.KeyVersion(),
+			AADBound:            true,
+		})
+		if err != nil {
+			return status.Error(codes.Internal, "failed to save card data")
+		}
 
-	// 4. Сохраняем метаданные в отдельную таблицу
-	err = s.storage.SaveMetadata(ctx, &itemModel.MetaData{
-		ItemID: itemID,
-		Name:   req.MetaDataName,
-		Value:  req.MetaDataValue,
+		err = s.storage.SaveMetadata(ctx, int64(userID), itemsConstants.TypeCard, &itemModel.MetaData{
+			ItemID: itemID,
+			Name:   req.MetaDataName,
+			Value:  req.MetaDataValue,
+		})
+		if err != nil {
+			return status.Error(codes.Internal, "failed to save meta data")
+		}
+		return nil
 	})
 	if err != nil {
-		return nil, status.Error(codes.Internal, "failed to save meta data")
+		return nil, err
 	}
 
 	// 5. Возвращаем ответ
@@ -98,9 +126,80 @@ func (s *Server) CreateCardData(ctx context.Context, req *bankcardsPb.CreateCard
 				Value: req.MetaDataValue,
 			},
 		},
+		EncryptedPayload: req.EncryptedPayload,
 	}, nil
 }
 
+// encryptSensitiveData шифрование чувствительных данных карты
+// данные, зашифрованные на клиенте, сохраняются как есть
+// aad связанные данные записи (crypto.ItemAAD)
+func (s *Server) encryptSensitiveData(
+	ctx context.Context,
+	aad []byte,
+	sensitiveData *itemModel.SensitiveBankCardData,
+	encryptedPayload []byte,
+) ([]byte, string, []byte, error) {
+	if len(encryptedPayload) > 0 {
+		return encryptedPayload, vault.Algorithm, nil, nil
+	}
+
+	// Сериализуем в JSON
+	jsonData, err := sensitiveData.ToJSON()
+	if err != nil {
+		return nil, "", nil, status.Error(codes.Internal, "failed to serialize data")
+	}
+
+	// Шифруем всю структуру
+	encryptor, err := s.keys.GetEncryptor(ctx)
+	if err != nil {
+		return nil, "", nil, status.Error(codes.Internal, "failed to get encryption key")
+	}
+	encryptedData, algorithm, iv, err := encryptor.Encrypt(jsonData, aad)
+	if err != nil {
+		return nil, "", nil, status.Error(codes.Internal, "failed to encrypt data")
+	}
+	return encryptedData, algorithm, iv, nil
+}
+
+// decryptSensitiveData расшифровка чувствительных данных карты в элемент ответа
+// данные, зашифрованные на клиенте, возвращаются как есть
+// записи, сохраненные без привязки, расшифровываются без связанных данных
+func (s *Server) decryptSensitiveData(
+	ctx context.Context,
+	userID int,
+	item *itemModel.ItemData,
+	pbItem *bankcardsPb.CardDataItem,
+) error {
+	if vault.IsClientEncrypted(item.EncryptionAlgorithm) {
+		pbItem.EncryptedPayload = item.Data
+		return nil
+	}
+
+	decryptor, err := s.keys.GetDecryptor(ctx, item.KeyVersion)
+	if err != nil {
+		return status.Error(codes.Internal, "failed to get decryption key")
+	}
+	var aad []byte
+	if item.AADBound {
+		aad = crypto.ItemAAD(int64(userID), item.ID, itemsConstants.TypeCard)
+	}
+	decryptedData, err := decryptor.Decrypt(item.Data, item.IV, item.EncryptionAlgorithm, aad)
+	if err != nil {
+		return status.Error(codes.Internal, "failed to decrypt cardData")
+	}
+	sensitiveData, err := itemModel.SensitiveBankCardDataFromJSON(decryptedData)
+	if err != nil {
+		return status.Error(codes.Internal, "failed to parse sensitive cardData")
+	}
+
+	pbItem.Number = sensitiveData.Number
+	pbItem.ValidUntilYear = sensitiveData.ValidUntilYear
+	pbItem.ValidUntilMonth = sensitiveData.ValidUntilMonth
+	pbItem.Cvv = sensitiveData.Cvv
+	pbItem.Holder = sensitiveData.Holder
+	return nil
+}
+
 // ListCardsData листинг данных о банковской карте
 // так же берутся мета данные карты
 func (s *Server) ListCardsData(
@@ -136,12 +235,12 @@ func (s *Server) ListCardsData(
 	for i := range list {
 		p := list[i]
 
-		decryptedData, err := s.Decryptor.Decrypt(p.Data, p.IV)
-		if err != nil {
-			continue
+		dataItem := &bankcardsPb.CardDataItem{
+			Id:          p.ID,
+			Description: p.Description,
+			CreatedAt:   p.CreatedAt.String(),
 		}
-		sensitiveData, err := itemModel.SensitiveBankCardDataFromJSON(decryptedData)
-		if err != nil {
+		if err = s.decryptSensitiveData(ctx, userID, p, dataItem); err != nil {
 			continue
 		}
 
@@ -153,18 +252,9 @@ func (s *Server) ListCardsData(
 			}
 			pbMetaData = append(pbMetaData, &pbMetaDataItem)
 		}
+		dataItem.MetaData = pbMetaData
 
-		dataItems = append(dataItems, &bankcardsPb.CardDataItem{
-			Id:              p.ID,
-			Number:          sensitiveData.Number,
-			ValidUntilYear:  sensitiveData.ValidUntilYear,
-			ValidUntilMonth: sensitiveData.ValidUntilMonth,
-			Cvv:             sensitiveData.Cvv,
-			Holder:          sensitiveData.Holder,
-			Description:     p.Description,
-			CreatedAt:       p.CreatedAt.String(),
-			MetaData:        pbMetaData,
-		})
+		dataItems = append(dataItems, dataItem)
 	}
 	totalPages := int32(math.Ceil(float64(totalCount) / float64(req.PerPage)))
 
@@ -179,55 +269,51 @@ func (s *Server) ListCardsData(
 // GetCardData получение данных об одной карте
 func (s *Server) GetCardData(ctx context.Context, req *bankcardsPb.GetCardDataRequest) (*bankcardsPb.CardDataItem, error) {
 	// Извлекаем userID из контекста
-	if _, ok := ctx.Value("userID").(int); !ok {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
 		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
 	}
 
 	// Получаем данные из репозитория
-	cardData, err := s.storage.GetItem(ctx, req.Id)
+	cardData, err := s.storage.GetItem(ctx, int64(userID), itemsConstants.TypeCard, req.Id)
+	if status.Code(err) == codes.NotFound {
+		return nil, status.Error(codes.NotFound, "card data not found")
+	}
 	if err != nil || cardData == nil {
 		return nil, status.Error(codes.Internal, "failed to get cardData")
 	}
 
-	decryptedData, err := s.Decryptor.Decrypt(cardData.Data, cardData.IV)
-	if err != nil {
-		return nil, status.Error(codes.Internal, "failed to decrypt cardData")
+	dataItem := &bankcardsPb.CardDataItem{
+		Id:          cardData.ID,
+		Description: cardData.Description,
+		CreatedAt:   cardData.CreatedAt.String(),
 	}
-	sensitiveData, err := itemModel.SensitiveBankCardDataFromJSON(decryptedData)
-	if err != nil {
-		return nil, status.Error(codes.Internal, "failed to parse sensitive cardData")
+	if err = s.decryptSensitiveData(ctx, userID, cardData, dataItem); err != nil {
+		return nil, err
 	}
 
-	var metaDataList []*bankcardsPb.MetaData
-
 	for _, val := range cardData.MetaDataItems {
-		metaDataList = append(metaDataList, &bankcardsPb.MetaData{
+		dataItem.MetaData = append(dataItem.MetaData, &bankcardsPb.MetaData{
 			Id:    val.ID,
 			Name:  val.Name,
 			Value: val.Value,
 		})
 	}
 
-	return &bankcardsPb.CardDataItem{
-		Id:              cardData.ID,
-		Number:          sensitiveData.Number,
-		ValidUntilYear:  sensitiveData.ValidUntilYear,
-		ValidUntilMonth: sensitiveData.ValidUntilMonth,
-		Cvv:             sensitiveData.Cvv,
-		Holder:          sensitiveData.Holder,
-		Description:     cardData.Description,
-		CreatedAt:       cardData.CreatedAt.String(),
-		MetaData:        metaDataList,
-	}, nil
+	return dataItem, nil
 }
 
 // DeleteCardData удаление данных о карте
 func (s *Server) DeleteCardData(ctx context.Context, req *bankcardsPb.DeleteCardDataRequest) (*empty.Empty, error) {
 	// Извлекаем userID из контекста
-	if _, ok := ctx.Value("userID").(int); !ok {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
 		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
 	}
-	err := s.storage.DeleteItem(ctx, req.Id)
+	err := s.storage.DeleteItem(ctx, int64(userID), itemsConstants.TypeCard, req.Id)
+	if status.Code(err) == codes.NotFound {
+		return nil, status.Error(codes.NotFound, "card data not found")
+	}
 	if err != nil {
 		return nil, status.Error(codes.Internal, "failed to delete card data")
 	}
@@ -239,46 +325,94 @@ func (s *Server) UpdateCardData(
 	ctx context.Context,
 	req *bankcardsPb.UpdateCardDataRequest,
 ) (*bankcardsPb.CardDataItem, error) {
-	if _, ok := ctx.Value("userID").(int); !ok {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
 		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
 	}
-	itemData, err := s.storage.GetItem(ctx, req.Id)
-	if err != nil || itemData == nil {
-		return nil, status.Error(codes.Internal, "failed to get itemData")
-	}
 
-	// 1. Создаем структуру для шифрования
-	sensitiveData := &itemModel.SensitiveBankCardData{
-		Number:          req.Number,
-		ValidUntilYear:  req.ValidUntilYear,
-		ValidUntilMonth: req.ValidUntilMonth,
-		Cvv:             req.Cvv,
-		Holder:          req.Holder,
-	}
+	// Запись проверяется и обновляется в одной транзакции
+	var itemID int64
+	err := s.storage.WithTx(ctx, func(ctx context.Context) error {
+		itemData, err := s.storage.GetItem(ctx, int64(userID), itemsConstants.TypeCard, req.Id)
+		if status.Code(err) == codes.NotFound {
+			return status.Error(codes.NotFound, "card data not found")
+		}
+		if err != nil || itemData == nil {
+			return status.Error(codes.Internal, "failed to get itemData")
+		}
 
-	// 2. Сериализуем в JSON
-	jsonData, err := sensitiveData.ToJSON()
-	if err != nil {
-		return nil, status.Error(codes.Internal, "failed to serialize data")
-	}
+		// 1. Создаем структуру для шифрования
+		sensitiveData := &itemModel.SensitiveBankCardData{
+			Number:          req.Number,
+			ValidUntilYear:  req.ValidUntilYear,
+			ValidUntilMonth: req.ValidUntilMonth,
+			Cvv:             req.Cvv,
+			Holder:          req.Holder,
+		}
+
+		// 2-3. Шифруем всю структуру, если данные не зашифрованы на клиенте
+		aad := crypto.ItemAAD(int64(userID), req.Id, itemsConstants.TypeCard)
+		encryptedData, algorithm, iv, err := s.encryptSensitiveData(ctx, aad, sensitiveData, req.EncryptedPayload)
+		if err != nil {
+			return err
+		}
 
-	// 3. Шифруем всю структуру
-	encryptedData, algorithm, iv, err := s.Encryptor.Encrypt(jsonData)
+		// 4. Сохраняем в основную таблицу
+		itemID, err = s.storage.UpdateItem(ctx, int64(userID), itemsConstants.TypeCard, req.Id, &itemModel.EncryptedItem{
+			Data:                encryptedData,
+			Description:         req.Description,
+			EncryptionAlgorithm: algorithm,
+			Iv:                  iv,
+			KeyVersion:          s.keys.KeyVersion(),
+			AADBound:            true,
+		})
+		if status.Code(err) == codes.NotFound {
+			return status.Error(codes.NotFound, "card data not found")
+		}
+		if err != nil {
+			return status.Error(codes.Internal, "failed to update card data")
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// 4. Сохраняем в основную таблицу
-	itemID, err := s.storage.UpdateItem(ctx, req.Id, &itemModel.EncryptedItem{
-		Data:                encryptedData,
-		Description:         req.Description,
-		EncryptionAlgorithm: algorithm,
-		Iv:                  iv,
-	})
-
 	// 5. Возвращаем ответ
 	return &bankcardsPb.CardDataItem{
 		Id:          itemID,
 		Description: req.Description,
 	}, nil
 }
+
+// newItemID идентификатор новой записи
+// данные, зашифрованные на клиенте, привязаны к идентификатору, зарезервированному клиентом (ReserveCardDataID)
+func (s *Server) newItemID(ctx context.Context, reservedID int64, encryptedPayload []byte) (int64, error) {
+	if len(encryptedPayload) == 0 {
+		itemID, err := s.storage.ReserveItemID(ctx)
+		if err != nil {
+			return 0, status.Error(codes.Internal, "failed to reserve item id")
+		}
+		return itemID, nil
+	}
+	if reservedID == 0 {
+		return 0, status.Error(codes.InvalidArgument, "reserved item id is required for encrypted payload")
+	}
+	return reservedID, nil
+}
+
+// claimItemID использование идентификатора, зарезервированного клиентом, для данных, зашифрованных на клиенте
+// идентификатор используется один раз, поэтому шифротекст нельзя сохранить в другую запись
+func (s *Server) claimItemID(ctx context.Context, userID int, itemID int64, encryptedPayload []byte) error {
+	if len(encryptedPayload) == 0 {
+		return nil
+	}
+	err := s.storage.ClaimItemID(ctx, int64(userID), itemID)
+	if status.Code(err) == codes.NotFound {
+		return status.Error(codes.InvalidArgument, "item id is not reserved")
+	}
+	if err != nil {
+		return status.Error(codes.Internal, "failed to claim item id")
+	}
+	return nil
+}
@@ -15,21 +15,28 @@ import (
 	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/bankcard"
 	"github.com/ramil063/secondgodiplom/internal/security/crypto"
 	cryptoMock "github.com/ramil063/secondgodiplom/internal/security/crypto/mocks"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/vault"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// runTx выполнение функции транзакции без бд
+func runTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
 func TestNewServer(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	storageMock := itemsMock.NewMockItemer(ctrl)
-	encryptorMock := cryptoMock.NewMockEncryptor(ctrl)
-	decryptorMock := cryptoMock.NewMockDecryptor(ctrl)
+	keysMock := cryptoMock.NewMockKeyResolver(ctrl)
 
 	type args struct {
-		storage   items.Itemer
-		encryptor crypto.Encryptor
-		decryptor crypto.Decryptor
+		storage items.Itemer
+		keys    crypto.KeyResolver
 	}
 	tests := []struct {
 		name string
@@ -39,20 +46,18 @@ func TestNewServer(t *testing.T) {
 		{
 			name: "TestNewServer",
 			args: args{
-				storage:   storageMock,
-				encryptor: encryptorMock,
-				decryptor: decryptorMock,
+				storage: storageMock,
+				keys:    keysMock,
 			},
 			want: &Server{
-				storage:   storageMock,
-				Encryptor: encryptorMock,
-				Decryptor: decryptorMock,
+				storage: storageMock,
+				keys:    keysMock,
 			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := NewServer(tt.args.storage, tt.args.encryptor, tt.args.decryptor); !reflect.DeepEqual(got, tt.want) {
+			if got := NewServer(tt.args.storage, tt.args.keys); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("NewServer() = %v, want %v", got, tt.want)
 			}
 		})
@@ -66,6 +71,10 @@ func TestServer_CreateCardData(t *testing.T) {
 	storageMock := itemsMock.NewMockItemer(ctrl)
 	encryptorMock := cryptoMock.NewMockEncryptor(ctrl)
 	decryptorMock := cryptoMock.NewMockDecryptor(ctrl)
+	keysMock := cryptoMock.NewMockKeyResolver(ctrl)
+	keysMock.EXPECT().GetEncryptor(gomock.Any()).Return(encryptorMock, nil).AnyTimes()
+	keysMock.EXPECT().GetDecryptor(gomock.Any(), gomock.Any()).Return(decryptorMock, nil).AnyTimes()
+	keysMock.EXPECT().KeyVersion().Return(1).AnyTimes()
 
 	type args struct {
 		ctx context.Context
@@ -121,25 +130,30 @@ func TestServer_CreateCardData(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				storage:   storageMock,
-				Encryptor: encryptorMock,
-				Decryptor: decryptorMock,
+				storage: storageMock,
+				keys:    keysMock,
 			}
 
+			storageMock.EXPECT().ReserveItemID(tt.args.ctx).Return(tt.itemID, nil)
+			storageMock.EXPECT().WithTx(tt.args.ctx, gomock.Any()).DoAndReturn(runTx)
+
 			encryptorMock.EXPECT().
-				Encrypt(gomock.Any()).
+				Encrypt(gomock.Any(), crypto.ItemAAD(int64(tt.userID), tt.itemID, itemsConstants.TypeCard)).
 				Return(tt.encryptedData, tt.algorithm, tt.iv, nil)
 
 			storageMock.EXPECT().SaveEncryptedData(tt.args.ctx, &itemModel.EncryptedItem{
+				ID:                  tt.itemID,
 				UserID:              int64(tt.userID),
 				Type:                itemsConstants.TypeCard,
 				Data:                tt.encryptedData,
 				Description:         tt.args.req.Description,
 				EncryptionAlgorithm: tt.algorithm,
 				Iv:                  tt.iv,
+				KeyVersion:          1,
+				AADBound:            true,
 			}).Return(tt.itemID, nil)
 
-			storageMock.EXPECT().SaveMetadata(tt.args.ctx, &itemModel.MetaData{
+			storageMock.EXPECT().SaveMetadata(tt.args.ctx, int64(1), itemsConstants.TypeCard, &itemModel.MetaData{
 				ItemID: tt.itemID,
 				Name:   tt.args.req.MetaDataName,
 				Value:  tt.args.req.MetaDataValue,
@@ -161,6 +175,10 @@ func TestServer_DeleteCardData(t *testing.T) {
 	storageMock := itemsMock.NewMockItemer(ctrl)
 	encryptorMock := cryptoMock.NewMockEncryptor(ctrl)
 	decryptorMock := cryptoMock.NewMockDecryptor(ctrl)
+	keysMock := cryptoMock.NewMockKeyResolver(ctrl)
+	keysMock.EXPECT().GetEncryptor(gomock.Any()).Return(encryptorMock, nil).AnyTimes()
+	keysMock.EXPECT().GetDecryptor(gomock.Any(), gomock.Any()).Return(decryptorMock, nil).AnyTimes()
+	keysMock.EXPECT().KeyVersion().Return(1).AnyTimes()
 
 	type args struct {
 		ctx context.Context
@@ -185,11 +203,10 @@ func TestServer_DeleteCardData(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				storage:   storageMock,
-				Encryptor: encryptorMock,
-				Decryptor: decryptorMock,
+				storage: storageMock,
+				keys:    keysMock,
 			}
-			storageMock.EXPECT().DeleteItem(tt.args.ctx, tt.args.req.Id).Return(nil)
+			storageMock.EXPECT().DeleteItem(tt.args.ctx, int64(1), itemsConstants.TypeCard, tt.args.req.Id).Return(nil)
 			got, err := s.DeleteCardData(tt.args.ctx, tt.args.req)
 			assert.NoError(t, err)
 			if !reflect.DeepEqual(got, tt.want) {
@@ -206,6 +223,10 @@ func TestServer_GetCardData(t *testing.T) {
 	storageMock := itemsMock.NewMockItemer(ctrl)
 	encryptorMock := cryptoMock.NewMockEncryptor(ctrl)
 	decryptorMock := cryptoMock.NewMockDecryptor(ctrl)
+	keysMock := cryptoMock.NewMockKeyResolver(ctrl)
+	keysMock.EXPECT().GetEncryptor(gomock.Any()).Return(encryptorMock, nil).AnyTimes()
+	keysMock.EXPECT().GetDecryptor(gomock.Any(), gomock.Any()).Return(decryptorMock, nil).AnyTimes()
+	keysMock.EXPECT().KeyVersion().Return(1).AnyTimes()
 
 	timeStr := "2025-09-16 06:29:40.129907335 +0300 MSK"
 	parsedTime, err := time.Parse("2006-01-02 15:04:05.999999999 -0700 MST", timeStr)
@@ -220,6 +241,7 @@ func TestServer_GetCardData(t *testing.T) {
 		args     args
 		itemData *itemModel.ItemData
 		sbcData  *itemModel.SensitiveBankCardData
+		aad      []byte
 		want     *bankcard.CardDataItem
 	}{
 		{
@@ -255,24 +277,52 @@ func TestServer_GetCardData(t *testing.T) {
 				CreatedAt:       timeStr,
 			},
 		},
+		{
+			name: "TestGetCardData aad bound",
+			args: args{
+				ctx: context.WithValue(context.Background(), "userID", 1),
+				req: &bankcard.GetCardDataRequest{
+					Id: 2,
+				},
+			},
+			itemData: &itemModel.ItemData{
+				ID:            2,
+				Data:          []byte("test"),
+				Description:   "test",
+				CreatedAt:     parsedTime,
+				AADBound:      true,
+				MetaDataItems: []*itemModel.MetaData{},
+			},
+			sbcData: &itemModel.SensitiveBankCardData{
+				Number: "5678",
+				Holder: "test",
+			},
+			aad: crypto.ItemAAD(1, 2, itemsConstants.TypeCard),
+			want: &bankcard.CardDataItem{
+				Id:          2,
+				Number:      "5678",
+				Holder:      "test",
+				Description: "test",
+				CreatedAt:   timeStr,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				storage:   storageMock,
-				Encryptor: encryptorMock,
-				Decryptor: decryptorMock,
+				storage: storageMock,
+				keys:    keysMock,
 			}
 
 			storageMock.EXPECT().
-				GetItem(tt.args.ctx, tt.args.req.Id).
+				GetItem(tt.args.ctx, int64(1), itemsConstants.TypeCard, tt.args.req.Id).
 				Return(tt.itemData, nil)
 
 			dData, err := tt.sbcData.ToJSON()
 			assert.NoError(t, err)
 
 			decryptorMock.EXPECT().
-				Decrypt(tt.itemData.Data, tt.itemData.IV).
+				Decrypt(tt.itemData.Data, tt.itemData.IV, tt.itemData.EncryptionAlgorithm, tt.aad).
 				Return(dData, nil)
 
 			got, err := s.GetCardData(tt.args.ctx, tt.args.req)
@@ -291,6 +341,10 @@ func TestServer_ListCardsData(t *testing.T) {
 	storageMock := itemsMock.NewMockItemer(ctrl)
 	encryptorMock := cryptoMock.NewMockEncryptor(ctrl)
 	decryptorMock := cryptoMock.NewMockDecryptor(ctrl)
+	keysMock := cryptoMock.NewMockKeyResolver(ctrl)
+	keysMock.EXPECT().GetEncryptor(gomock.Any()).Return(encryptorMock, nil).AnyTimes()
+	keysMock.EXPECT().GetDecryptor(gomock.Any(), gomock.Any()).Return(decryptorMock, nil).AnyTimes()
+	keysMock.EXPECT().KeyVersion().Return(1).AnyTimes()
 
 	timeStr := "2025-09-16 06:29:40.129907335 +0300 MSK"
 	parsedTime, err := time.Parse("2006-01-02 15:04:05.999999999 -0700 MST", timeStr)
@@ -355,9 +409,8 @@ func TestServer_ListCardsData(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				storage:   storageMock,
-				Encryptor: encryptorMock,
-				Decryptor: decryptorMock,
+				storage: storageMock,
+				keys:    keysMock,
 			}
 			storageMock.EXPECT().
 				GetListItems(
@@ -378,7 +431,7 @@ func TestServer_ListCardsData(t *testing.T) {
 			dData, err := tt.sbcData.ToJSON()
 			assert.NoError(t, err)
 			decryptorMock.EXPECT().
-				Decrypt(tt.itemData.Data, tt.itemData.IV).
+				Decrypt(tt.itemData.Data, tt.itemData.IV, tt.itemData.EncryptionAlgorithm, nil).
 				Return(dData, nil)
 
 			got, err := s.ListCardsData(tt.args.ctx, tt.args.req)
@@ -398,6 +451,10 @@ func TestServer_UpdateCardData(t *testing.T) {
 	storageMock := itemsMock.NewMockItemer(ctrl)
 	encryptorMock := cryptoMock.NewMockEncryptor(ctrl)
 	decryptorMock := cryptoMock.NewMockDecryptor(ctrl)
+	keysMock := cryptoMock.NewMockKeyResolver(ctrl)
+	keysMock.EXPECT().GetEncryptor(gomock.Any()).Return(encryptorMock, nil).AnyTimes()
+	keysMock.EXPECT().GetDecryptor(gomock.Any(), gomock.Any()).Return(decryptorMock, nil).AnyTimes()
+	keysMock.EXPECT().KeyVersion().Return(1).AnyTimes()
 
 	timeStr := "2025-09-16 06:29:40.129907335 +0300 MSK"
 	parsedTime, err := time.Parse("2006-01-02 15:04:05.999999999 -0700 MST", timeStr)
@@ -459,14 +516,14 @@ func TestServer_UpdateCardData(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				storage:   storageMock,
-				Encryptor: encryptorMock,
-				Decryptor: decryptorMock,
+				storage: storageMock,
+				keys:    keysMock,
 			}
 
+			storageMock.EXPECT().WithTx(tt.args.ctx, gomock.Any()).DoAndReturn(runTx)
 			storageMock.
 				EXPECT().
-				GetItem(tt.args.ctx, tt.args.req.Id).
+				GetItem(tt.args.ctx, int64(1), itemsConstants.TypeCard, tt.args.req.Id).
 				Return(tt.itemData, nil)
 
 			sensitiveData := &itemModel.SensitiveBankCardData{
@@ -480,15 +537,17 @@ func TestServer_UpdateCardData(t *testing.T) {
 			assert.NoError(t, err)
 			encryptorMock.
 				EXPECT().
-				Encrypt(jsonData).
+				Encrypt(jsonData, crypto.ItemAAD(1, tt.args.req.Id, itemsConstants.TypeCard)).
 				Return(tt.itemData.Data, tt.algorithm, tt.iv, nil)
 
 			storageMock.EXPECT().
-				UpdateItem(tt.args.ctx, tt.args.req.Id, &itemModel.EncryptedItem{
+				UpdateItem(tt.args.ctx, int64(1), itemsConstants.TypeCard, tt.args.req.Id, &itemModel.EncryptedItem{
 					Data:                tt.itemData.Data,
 					Description:         tt.args.req.Description,
 					EncryptionAlgorithm: tt.algorithm,
 					Iv:                  tt.iv,
+					KeyVersion:          1,
+					AADBound:            true,
 				}).
 				Return(tt.itemID, nil)
 
@@ -500,3 +559,135 @@ func TestServer_UpdateCardData(t *testing.T) {
 		})
 	}
 }
+
+func TestServer_ClientEncryptedCardData(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storageMock := itemsMock.NewMockItemer(ctrl)
+	encryptorMock := cryptoMock.NewMockEncryptor(ctrl)
+	decryptorMock := cryptoMock.NewMockDecryptor(ctrl)
+	keysMock := cryptoMock.NewMockKeyResolver(ctrl)
+	keysMock.EXPECT().GetEncryptor(gomock.Any()).Return(encryptorMock, nil).AnyTimes()
+	keysMock.EXPECT().GetDecryptor(gomock.Any(), gomock.Any()).Return(decryptorMock, nil).AnyTimes()
+	keysMock.EXPECT().KeyVersion().Return(1).AnyTimes()
+
+	s := &Server{
+		storage: storageMock,
+		keys:    keysMock,
+	}
+	ctx := context.WithValue(context.Background(), "userID", 1)
+	payload := []byte("opaque payload")
+
+	// Идентификатор резервирует клиент, шифротекст привязан к нему
+	storageMock.EXPECT().ReserveClientItemID(ctx, int64(1)).Return(int64(1), nil)
+	reserved, err := s.ReserveCardDataID(ctx, &empty.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), reserved.Id)
+
+	// без зарезервированного идентификатора запись не создается
+	_, err = s.CreateCardData(ctx, &bankcard.CreateCardDataRequest{EncryptedPayload: payload})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// идентификатор, не зарезервированный пользователем или уже использованный, не принимается
+	storageMock.EXPECT().WithTx(ctx, gomock.Any()).DoAndReturn(runTx)
+	storageMock.EXPECT().ClaimItemID(ctx, int64(1), int64(2)).Return(status.Error(codes.NotFound, "reserved id not found"))
+	_, err = s.CreateCardData(ctx, &bankcard.CreateCardDataRequest{Id: 2, EncryptedPayload: payload})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Данные, зашифрованные на клиенте, сохраняются без серверного шифрования
+	storageMock.EXPECT().WithTx(ctx, gomock.Any()).DoAndReturn(runTx)
+	storageMock.EXPECT().ClaimItemID(ctx, int64(1), int64(1)).Return(nil)
+	storageMock.EXPECT().SaveEncryptedData(ctx, &itemModel.EncryptedItem{
+		ID:                  1,
+		UserID:              1,
+		Type:                itemsConstants.TypeCard,
+		Data:                payload,
+		Description:         "test",
+		EncryptionAlgorithm: vault.Algorithm,
+		KeyVersion:          1,
+		AADBound:            true,
+	}).Return(int64(1), nil)
+	storageMock.EXPECT().SaveMetadata(ctx, int64(1), itemsConstants.TypeCard, gomock.Any()).Return(nil)
+
+	created, err := s.CreateCardData(ctx, &bankcard.CreateCardDataRequest{
+		Id:               reserved.Id,
+		Description:      "test",
+		EncryptedPayload: payload,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, payload, created.EncryptedPayload)
+	assert.Empty(t, created.Number)
+
+	// и возвращаются клиенту как есть
+	storageMock.EXPECT().
+		GetItem(ctx, int64(1), itemsConstants.TypeCard, int64(1)).
+		Return(&itemModel.ItemData{
+			ID:                  1,
+			Data:                payload,
+			Description:         "test",
+			EncryptionAlgorithm: vault.Algorithm,
+		}, nil)
+
+	got, err := s.GetCardData(ctx, &bankcard.GetCardDataRequest{Id: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, payload, got.EncryptedPayload)
+	assert.Empty(t, got.Number)
+}
+
+// TestServer_ForeignItem запись другого пользователя или другого типа не находится хранилищем,
+// сервер запрашивает ее от имени пользователя из контекста и отвечает NotFound
+func TestServer_ForeignItem(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	notFound := status.Error(codes.NotFound, "item not found")
+	ctx := context.WithValue(context.Background(), "userID", 2)
+
+	tests := []struct {
+		name   string
+		expect func(storage *itemsMock.MockItemer)
+		call   func(s *Server) error
+	}{
+		{
+			name: "get",
+			expect: func(storage *itemsMock.MockItemer) {
+				storage.EXPECT().GetItem(ctx, int64(2), itemsConstants.TypeCard, int64(1)).Return(nil, notFound)
+			},
+			call: func(s *Server) error {
+				_, err := s.GetCardData(ctx, &bankcard.GetCardDataRequest{Id: 1})
+				return err
+			},
+		},
+		{
+			name: "update",
+			expect: func(storage *itemsMock.MockItemer) {
+				storage.EXPECT().WithTx(ctx, gomock.Any()).DoAndReturn(runTx)
+				storage.EXPECT().GetItem(ctx, int64(2), itemsConstants.TypeCard, int64(1)).Return(nil, notFound)
+			},
+			call: func(s *Server) error {
+				_, err := s.UpdateCardData(ctx, &bankcard.UpdateCardDataRequest{Id: 1, Description: "changed"})
+				return err
+			},
+		},
+		{
+			name: "delete",
+			expect: func(storage *itemsMock.MockItemer) {
+				storage.EXPECT().DeleteItem(ctx, int64(2), itemsConstants.TypeCard, int64(1)).Return(notFound)
+			},
+			call: func(s *Server) error {
+				_, err := s.DeleteCardData(ctx, &bankcard.DeleteCardDataRequest{Id: 1})
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storageMock := itemsMock.NewMockItemer(ctrl)
+			s := NewServer(storageMock, cryptoMock.NewMockKeyResolver(ctrl))
+			tt.expect(storageMock)
+
+			assert.Equal(t, codes.NotFound, status.Code(tt.call(s)))
+		})
+	}
+}
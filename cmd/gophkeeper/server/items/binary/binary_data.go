@@ -7,10 +7,13 @@ import (
 	"math"
 	"sync"
 
+	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/config"
 	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/items/binary"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/items"
 	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/binarydata"
 	"github.com/ramil063/secondgodiplom/internal/security/crypto"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/vault"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -20,37 +23,57 @@ type Server struct {
 	binarydata.UnimplementedServiceServer
 
 	storage      binary.Filer
-	Encryptor    crypto.Encryptor
-	Decryptor    crypto.Decryptor
+	keys         crypto.KeyResolver
 	workersCount int
 }
 
-// NewServer инициализация сервера, шифровальщика, дешифровщика и структуры для работы с хранилищем
+// NewServer инициализация сервера, получателя ключей шифрования пользователей и структуры для работы с хранилищем
 // установка количества потоков обработчиков файла
-func NewServer(storage binary.Filer, encryptor crypto.Encryptor, decryptor crypto.Decryptor, config *config.ServerConfig) *Server {
+func NewServer(storage binary.Filer, keys crypto.KeyResolver, config *config.ServerConfig) *Server {
 	return &Server{
 		storage:      storage,
-		Encryptor:    encryptor,
-		Decryptor:    decryptor,
+		keys:         keys,
 		workersCount: config.WorkersCount,
 	}
 }
 
 type chunkTask struct {
-	fileID     int64
-	chunk      *binarydata.FileChunk
-	chunkIndex int32
+	fileID          int64
+	chunk           *binarydata.FileChunk
+	chunkIndex      int32
+	final           bool
+	clientEncrypted bool
 }
 
 type chunkResult struct {
+	fileID         int64
 	chunkIndex     int32
+	encryptedData  []byte
+	algorithm      string
+	iv             []byte
 	bytesProcessed int64
 	err            error
 }
 
+// ReserveFileID резервирование идентификатора файла, части которого зашифрованы на клиенте
+// клиент привязывает части к идентификатору до загрузки файла (vault.ChunkAAD)
+func (s *Server) ReserveFileID(ctx context.Context, _ *empty.Empty) (*binarydata.ReservedFileID, error) {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+	fileID, err := s.storage.ReserveClientFileID(ctx, int64(userID))
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to reserve file id")
+	}
+	return &binarydata.ReservedFileID{FileId: fileID}, nil
+}
+
 // UploadFile загрузка файла
 // так же сохранение метаданных о ней
 // все данные о файле шифруются
+// запись о файле, чанки и отметка о завершении сохраняются в одной транзакции,
+// поэтому прерванная загрузка не оставляет в бд недозагруженный файл
 func (s *Server) UploadFile(stream binarydata.Service_UploadFileServer) error {
 	ctx := stream.Context()
 	// Безопасное извлечение userID из контекста
@@ -64,33 +87,83 @@ func (s *Server) UploadFile(stream binarydata.Service_UploadFileServer) error {
 		return status.Error(codes.Internal, "invalid user ID format")
 	}
 
+	encryptor, err := s.keys.GetEncryptor(ctx)
+	if err != nil {
+		return status.Error(codes.Internal, "failed to get encryption key")
+	}
+
+	var fileID, totalBytes int64
+	err = s.storage.WithTx(ctx, func(ctx context.Context) error {
+		fileID, totalBytes, err = s.receiveFile(ctx, stream, userID, encryptor)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return stream.SendAndClose(&binarydata.UploadFileResponse{
+		FileId:        fileID,
+		BytesReceived: totalBytes,
+		Status:        "success",
+	})
+}
+
+// receiveFile прием файла из потока, возвращает идентификатор файла и принятый объем
+// чанки шифруются в нескольких потоках, а все запросы транзакции выполняются только в вызывающем потоке,
+// так как транзакция не поддерживает параллельные запросы
+func (s *Server) receiveFile(
+	ctx context.Context,
+	stream binarydata.Service_UploadFileServer,
+	userID int,
+	encryptor crypto.Encryptor,
+) (int64, int64, error) {
 	var metadata *binarydata.FileMetadata
 	var fileID int64
+	var totalChunks int32
 
 	// Создаем каналы для многопоточной обработки
 	chunks := make(chan *chunkTask, 100) // Буферизированный канал
 	results := make(chan *chunkResult, 100)
-	errors := make(chan error, 10) // Канал для ошибок
 	var wg sync.WaitGroup
 
-	// 1. Запускаем workers для обработки чанков (многопоточность!)
+	// 1. Запускаем workers для шифрования чанков (многопоточность!)
 	for i := 0; i < s.workersCount; i++ {
 		wg.Add(1)
-		go s.chunkProcessorWorker(ctx, chunks, results, &wg)
+		go s.chunkProcessorWorker(encryptor, chunks, results, &wg)
 	}
+	go func() {
+		wg.Wait()      // Ждем завершения всех workers
+		close(results) // Закрываем results после завершения workers
+	}()
 
-	// 2. Запускаем worker для сохранения в БД (отдельный поток)
+	// 2. Зашифрованные чанки сохраняются здесь же, а не в отдельном потоке
 	var totalBytes int64
-	var totalChunks int32
-	go func() {
+	var saveErr error
+	saveResult := func(result *chunkResult) {
+		// После ошибки результаты дочитываются, чтобы workers не блокировались
+		if saveErr != nil {
+			return
+		}
+		if result.err != nil {
+			saveErr = result.err
+			return
+		}
+		err := s.storage.SaveChunk(ctx, result.fileID, result.chunkIndex, result.encryptedData, result.algorithm, result.iv, s.keys.KeyVersion(), true, true)
+		if err != nil {
+			saveErr = fmt.Errorf("chunk %d save failed: %w", result.chunkIndex, err)
+			return
+		}
+		totalBytes += result.bytesProcessed
+	}
+
+	// finish ожидание шифрования и сохранения всех отправленных чанков
+	// до выхода из транзакции, иначе чанк мог бы сохраняться после ее завершения
+	finish := func() {
+		close(chunks) // Сигнализируем workers о завершении
 		for result := range results {
-			if result.err != nil {
-				errors <- result.err
-				return
-			}
-			totalBytes += result.bytesProcessed
+			saveResult(result)
 		}
-	}()
+	}
 
 	// 3. Читаем stream и распределяем задачи
 	for {
@@ -99,71 +172,122 @@ func (s *Server) UploadFile(stream binarydata.Service_UploadFileServer) error {
 			break
 		}
 		if err != nil {
-			close(chunks)
-			return status.Error(codes.Internal, "failed to receive data")
+			finish()
+			return 0, 0, status.Error(codes.Internal, "failed to receive data")
 		}
 
 		switch data := request.Data.(type) {
 		case *binarydata.UploadFileRequest_Metadata:
+			// Запись о файле создается один раз
+			if metadata != nil {
+				finish()
+				return 0, 0, status.Error(codes.InvalidArgument, "metadata already sent")
+			}
 			metadata = data.Metadata
 
+			// Идентификатор нужен заранее, так как зашифрованные поля файла привязываются к нему
+			fileID, err = s.newFileID(ctx, userID, metadata)
+			if err != nil {
+				finish()
+				return 0, 0, err
+			}
+
 			// Создаем запись о файле
-			fileID, err = s.storage.CreateFileRecord(ctx, userID, metadata)
+			fileID, err = s.storage.CreateFileRecord(ctx, userID, fileID, metadata)
 			if err != nil {
-				close(chunks)
-				return status.Error(codes.Internal, "failed to create file record")
+				finish()
+				return 0, 0, status.Error(codes.Internal, "failed to create file record")
 			}
 
 		case *binarydata.UploadFileRequest_Chunk:
 			if metadata == nil {
-				close(chunks)
-				return status.Error(codes.InvalidArgument, "metadata must be sent first")
+				finish()
+				return 0, 0, status.Error(codes.InvalidArgument, "metadata must be sent first")
+			}
+
+			// Номер чанка входит в связанные данные, поэтому должен быть в пределах файла
+			if data.Chunk.ChunkIndex < 0 || data.Chunk.ChunkIndex >= metadata.TotalChunks {
+				finish()
+				return 0, 0, status.Error(codes.InvalidArgument, "chunk index out of range")
 			}
 
 			// Отправляем чанк в канал для обработки
-			chunks <- &chunkTask{
-				fileID:     fileID,
-				chunk:      data.Chunk,
-				chunkIndex: data.Chunk.ChunkIndex,
+			// пока workers заняты, сохраняем готовые чанки, иначе отправка и workers ждали бы друг друга
+			task := &chunkTask{
+				fileID:          fileID,
+				chunk:           data.Chunk,
+				chunkIndex:      data.Chunk.ChunkIndex,
+				final:           data.Chunk.ChunkIndex == metadata.TotalChunks-1,
+				clientEncrypted: metadata.ClientEncrypted,
+			}
+			for sent := false; !sent; {
+				select {
+				case chunks <- task:
+					sent = true
+				case result := <-results:
+					saveResult(result)
+				}
 			}
 			totalChunks++
 		}
 	}
 
 	// 4. Завершаем обработку
-	close(chunks)  // Сигнализируем workers о завершении
-	wg.Wait()      // Ждем завершения всех workers
-	close(results) // Закрываем results после завершения workers
+	finish()
 
 	// 5. Проверяем ошибки
-	select {
-	case err := <-errors:
-		return status.Error(codes.Internal, fmt.Sprintf("processing failed: %v", err))
-	default:
+	if saveErr != nil {
+		return 0, 0, status.Error(codes.Internal, fmt.Sprintf("processing failed: %v", saveErr))
+	}
+	if metadata == nil {
+		return 0, 0, status.Error(codes.InvalidArgument, "metadata must be sent first")
 	}
 
 	// 6. Валидация - все ли чанки получены?
 	if totalChunks != metadata.TotalChunks {
-		return status.Error(codes.Internal,
+		return 0, 0, status.Error(codes.Internal,
 			fmt.Sprintf("missing chunks: received %d, expected %d",
 				totalChunks, metadata.TotalChunks))
 	}
 
+	// Для файлов, зашифрованных на клиенте, принятый объем больше исходного размера
+	if metadata.ClientEncrypted {
+		totalBytes = metadata.OriginalSize
+	}
+
 	// 7. Обновляем статус файла
-	err := s.storage.MarkFileComplete(ctx, fileID, totalBytes)
-	if err != nil {
-		return status.Error(codes.Internal, "failed to mark file complete")
+	if err := s.storage.MarkFileComplete(ctx, fileID, totalBytes); err != nil {
+		return 0, 0, status.Error(codes.Internal, "failed to mark file complete")
 	}
+	return fileID, totalBytes, nil
+}
 
-	return stream.SendAndClose(&binarydata.UploadFileResponse{
-		FileId:        fileID,
-		BytesReceived: totalBytes,
-		Status:        "success",
-	})
+// newFileID идентификатор нового файла
+// части, зашифрованные на клиенте, привязаны к идентификатору, зарезервированному клиентом (ReserveFileID),
+// он используется один раз, поэтому части нельзя сохранить в другой файл
+func (s *Server) newFileID(ctx context.Context, userID int, metadata *binarydata.FileMetadata) (int64, error) {
+	if !metadata.ClientEncrypted {
+		fileID, err := s.storage.ReserveFileID(ctx)
+		if err != nil {
+			return 0, status.Error(codes.Internal, "failed to reserve file id")
+		}
+		return fileID, nil
+	}
+	if metadata.FileId == 0 {
+		return 0, status.Error(codes.InvalidArgument, "reserved file id is required for client encrypted file")
+	}
+	err := s.storage.ClaimFileID(ctx, int64(userID), metadata.FileId)
+	if status.Code(err) == codes.NotFound {
+		return 0, status.Error(codes.InvalidArgument, "file id is not reserved")
+	}
+	if err != nil {
+		return 0, status.Error(codes.Internal, "failed to claim file id")
+	}
+	return metadata.FileId, nil
 }
 
 func (s *Server) chunkProcessorWorker(
-	ctx context.Context,
+	encryptor crypto.Encryptor,
 	tasks <-chan *chunkTask,
 	results chan<- *chunkResult,
 	wg *sync.WaitGroup,
@@ -171,27 +295,48 @@ func (s *Server) chunkProcessorWorker(
 	defer wg.Done()
 
 	for task := range tasks {
-		// Шифруем чанк
-		encryptedData, algorithm, iv, err := s.Encryptor.Encrypt(task.chunk.Data)
+		// Шифруем чанк, если он не зашифрован на клиенте
+		encryptedData, algorithm, iv, err := encryptChunk(encryptor, task)
 		if err != nil {
 			results <- &chunkResult{err: fmt.Errorf("chunk %d encryption failed: %w", task.chunkIndex, err)}
 			continue
 		}
 
-		// Сохраняем чанк в БД (каждый worker имеет свое соединение)
-		err = s.storage.SaveChunk(ctx, task.fileID, task.chunkIndex, encryptedData, algorithm, iv)
-		if err != nil {
-			results <- &chunkResult{err: fmt.Errorf("chunk %d save failed: %w", task.chunkIndex, err)}
-			continue
-		}
-
 		results <- &chunkResult{
+			fileID:         task.fileID,
 			chunkIndex:     task.chunkIndex,
+			encryptedData:  encryptedData,
+			algorithm:      algorithm,
+			iv:             iv,
 			bytesProcessed: int64(len(task.chunk.Data)),
 		}
 	}
 }
 
+// encryptChunk шифрование чанка
+// чанки, зашифрованные на клиенте, сохраняются как есть
+// шифротекст привязывается к файлу, номеру чанка и признаку последнего чанка,
+// поэтому чанки нельзя переставить, перенести в другой файл или отрезать конец файла
+func encryptChunk(encryptor crypto.Encryptor, task *chunkTask) ([]byte, string, []byte, error) {
+	if task.clientEncrypted {
+		return task.chunk.Data, vault.Algorithm, []byte{}, nil
+	}
+	return encryptor.Encrypt(task.chunk.Data, crypto.StreamChunkAAD(task.fileID, task.chunkIndex, task.final))
+}
+
+// chunkAAD связанные данные, с которыми чанк был сохранен
+// чанки, сохраненные без привязки, расшифровываются без связанных данных, сохраненные до STREAM - только с номером
+func chunkAAD(fileID int64, chunkData *items.ChunkData, final bool) []byte {
+	switch {
+	case chunkData.StreamBound:
+		return crypto.StreamChunkAAD(fileID, chunkData.ChunkIndex, final)
+	case chunkData.AADBound:
+		return crypto.ChunkAAD(fileID, chunkData.ChunkIndex)
+	default:
+		return nil
+	}
+}
+
 // DownloadFile скачивание файла с сервера
 func (s *Server) DownloadFile(req *binarydata.DownloadFileRequest, stream binarydata.Service_DownloadFileServer) error {
 	ctx := stream.Context()
@@ -207,12 +352,13 @@ func (s *Server) DownloadFile(req *binarydata.DownloadFileRequest, stream binary
 	if err = stream.Send(&binarydata.DownloadFileResponse{
 		Data: &binarydata.DownloadFileResponse_Metadata{
 			Metadata: &binarydata.FileMetadata{
-				Filename:     fileInfo.Filename,
-				MimeType:     fileInfo.MimeType,
-				OriginalSize: fileInfo.OriginalSize,
-				Description:  fileInfo.Description,
-				ChunkSize:    fileInfo.ChunkSize,
-				TotalChunks:  fileInfo.TotalChunks,
+				Filename:        fileInfo.Filename,
+				MimeType:        fileInfo.MimeType,
+				OriginalSize:    fileInfo.OriginalSize,
+				Description:     fileInfo.Description,
+				ChunkSize:       fileInfo.ChunkSize,
+				TotalChunks:     fileInfo.TotalChunks,
+				ClientEncrypted: fileInfo.ClientEncrypted,
 			},
 		},
 	}); err != nil {
@@ -220,6 +366,9 @@ func (s *Server) DownloadFile(req *binarydata.DownloadFileRequest, stream binary
 	}
 
 	// 3. Многопоточное получение чанков
+	// при ошибке отправки воркеры останавливаются отменой контекста, а не ждут чтения канала
+	workersCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
 	chunks := make(chan *binarydata.FileChunk, 10)
 	errors := make(chan error, 1)
 	var wg sync.WaitGroup
@@ -229,7 +378,7 @@ func (s *Server) DownloadFile(req *binarydata.DownloadFileRequest, stream binary
 	ranges := calculateChunkRanges(fileInfo.TotalChunks, int32(s.workersCount))
 	for _, r := range ranges {
 		wg.Add(1)
-		go s.downloadChunkWorker(ctx, req.FileId, r.start, r.end, chunks, errors, &wg)
+		go s.downloadChunkWorker(workersCtx, req.FileId, fileInfo.TotalChunks, r.start, r.end, chunks, errors, &wg)
 	}
 
 	// 5. Важно: закрываем канал chunks после завершения всех воркеров
@@ -258,9 +407,13 @@ func (s *Server) DownloadFile(req *binarydata.DownloadFileRequest, stream binary
 	}
 }
 
+// downloadChunkWorker получение и расшифровка чанков диапазона [startChunk, endChunk)
+// пропущенный чанк или чанк, не прошедший проверку подлинности, прерывают скачивание с кодом DataLoss,
+// при отмене ctx воркер завершается, не дожидаясь отправки чанков
 func (s *Server) downloadChunkWorker(
 	ctx context.Context,
 	fileID int64,
+	totalChunks int32,
 	startChunk, endChunk int32,
 	chunks chan<- *binarydata.FileChunk,
 	errors chan<- error,
@@ -271,24 +424,62 @@ func (s *Server) downloadChunkWorker(
 	// Получаем все чанки диапазона одним запросом
 	chunkDataList, err := s.storage.GetChunksInRange(ctx, fileID, startChunk, endChunk)
 	if err != nil {
-		errors <- fmt.Errorf("failed to get chunks %d-%d: %w", startChunk, endChunk-1, err)
+		reportError(errors, status.Error(codes.Internal, fmt.Sprintf("failed to get chunks %d-%d", startChunk, endChunk-1)))
 		return
 	}
 
-	// Обрабатываем каждый чанк
+	// Обрабатываем каждый чанк, номера должны идти подряд без пропусков
+	expectedIndex := startChunk
 	for _, chunkData := range chunkDataList {
-		decryptedData, err := s.Decryptor.Decrypt(chunkData.EncryptedData, chunkData.IV)
-		if err != nil {
-			errors <- fmt.Errorf("decryption failed for chunk: %w", err)
+		if chunkData.ChunkIndex != expectedIndex {
+			reportError(errors, chunkMissingError(expectedIndex))
 			return
 		}
+		expectedIndex++
+		final := chunkData.ChunkIndex == totalChunks-1
 
-		chunks <- &binarydata.FileChunk{
+		decryptedData := chunkData.EncryptedData
+		if !vault.IsClientEncrypted(chunkData.EncryptionAlgorithm) {
+			// Во время ротации ключа чанки одного файла могут быть зашифрованы разными версиями ключа
+			decryptor, err := s.keys.GetDecryptor(ctx, chunkData.KeyVersion)
+			if err != nil {
+				reportError(errors, status.Error(codes.Internal, "failed to get decryption key"))
+				return
+			}
+			decryptedData, err = decryptor.Decrypt(chunkData.EncryptedData, chunkData.IV, chunkData.EncryptionAlgorithm, chunkAAD(fileID, chunkData, final))
+			if err != nil {
+				reportError(errors, status.Error(codes.DataLoss,
+					fmt.Sprintf("file integrity check failed: chunk %d was modified, reordered or truncated", chunkData.ChunkIndex)))
+				return
+			}
+		}
+
+		select {
+		case chunks <- &binarydata.FileChunk{
 			Data:       decryptedData,
-			ChunkIndex: chunkData.ChunkIndex, // Нужно добавить index в ChunkData
-			IsLast:     chunkData.ChunkIndex == endChunk-1,
+			ChunkIndex: chunkData.ChunkIndex,
+			IsLast:     final,
+		}:
+		case <-ctx.Done():
+			return
 		}
 	}
+	if expectedIndex != endChunk {
+		reportError(errors, chunkMissingError(expectedIndex))
+	}
+}
+
+func chunkMissingError(chunkIndex int32) error {
+	return status.Error(codes.DataLoss, fmt.Sprintf("file integrity check failed: chunk %d is missing", chunkIndex))
+}
+
+// reportError передача ошибки воркера без блокировки
+// достаточно первой ошибки, остальные отбрасываются
+func reportError(errors chan<- error, err error) {
+	select {
+	case errors <- err:
+	default:
+	}
 }
 
 // Динамическое распределение чанков по воркерам
@@ -362,6 +553,8 @@ func (s *Server) ListFiles(ctx context.Context, req *binarydata.ListFilesRequest
 			Size:        p.OriginalSize,
 			Description: p.Description,
 			CreatedAt:   p.CreatedAt.String(),
+
+			ClientEncrypted: p.ClientEncrypted,
 		})
 	}
 	totalPages := int32(math.Ceil(float64(totalCount) / float64(req.PerPage)))
@@ -406,5 +599,7 @@ func (s *Server) GetFileInfo(ctx context.Context, req *binarydata.GetFileInfoReq
 		Description: fileInfo.Description,
 		CreatedAt:   fileInfo.CreatedAt.String(),
 		MetaData:    metaDataList,
+
+		ClientEncrypted: fileInfo.ClientEncrypted,
 	}, nil
 }
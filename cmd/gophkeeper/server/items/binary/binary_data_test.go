@@ -0,0 +1,516 @@
+package binary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	binaryMock "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/items/binary/mocks"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/items"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/binarydata"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto"
+	cryptoMock "github.com/ramil063/secondgodiplom/internal/security/crypto/mocks"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/vault"
+)
+
+const testFileID int64 = 5
+
+var testKey = []byte("12345678901234567890123456789012")
+
+// downloadStream заглушка потока скачивания, собирает отправленные чанки
+// при заданной sendErr отправка чанков завершается ошибкой, как при отключении клиента
+type downloadStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	chunks  []*binarydata.FileChunk
+	sendErr error
+}
+
+func (s *downloadStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *downloadStream) Send(response *binarydata.DownloadFileResponse) error {
+	if chunk := response.GetChunk(); chunk != nil {
+		if s.sendErr != nil {
+			return s.sendErr
+		}
+		s.chunks = append(s.chunks, chunk)
+	}
+	return nil
+}
+
+// uploadStream заглушка потока загрузки, отдает подготовленные сообщения и запоминает ответ
+type uploadStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	requests []*binarydata.UploadFileRequest
+	response *binarydata.UploadFileResponse
+}
+
+func (s *uploadStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *uploadStream) Recv() (*binarydata.UploadFileRequest, error) {
+	if len(s.requests) == 0 {
+		return nil, io.EOF
+	}
+	request := s.requests[0]
+	s.requests = s.requests[1:]
+	return request, nil
+}
+
+func (s *uploadStream) SendAndClose(response *binarydata.UploadFileResponse) error {
+	s.response = response
+	return nil
+}
+
+// encryptTestChunks чанки файла, зашифрованные так же, как при загрузке
+func encryptTestChunks(t *testing.T, fileID int64, data ...string) []*items.ChunkData {
+	encryptor, err := crypto.NewEncryptor(testKey, crypto.DefaultAlgorithm)
+	require.NoError(t, err)
+
+	chunks := make([]*items.ChunkData, len(data))
+	for i, chunk := range data {
+		task := &chunkTask{
+			fileID:     fileID,
+			chunk:      &binarydata.FileChunk{Data: []byte(chunk)},
+			chunkIndex: int32(i),
+			final:      i == len(data)-1,
+		}
+		encryptedData, algorithm, iv, err := encryptChunk(encryptor, task)
+		require.NoError(t, err)
+		chunks[i] = &items.ChunkData{
+			ChunkIndex:          int32(i),
+			EncryptedData:       encryptedData,
+			EncryptionAlgorithm: algorithm,
+			IV:                  iv,
+			KeyVersion:          1,
+			AADBound:            true,
+			StreamBound:         true,
+		}
+	}
+	return chunks
+}
+
+func TestServer_DownloadFile_Integrity(t *testing.T) {
+	tests := []struct {
+		name        string
+		totalChunks int32
+		chunks      func(t *testing.T) []*items.ChunkData
+		want        []string
+		wantCode    codes.Code
+	}{
+		{
+			name:        "whole file",
+			totalChunks: 3,
+			chunks: func(t *testing.T) []*items.ChunkData {
+				return encryptTestChunks(t, testFileID, "a", "b", "c")
+			},
+			want: []string{"a", "b", "c"},
+		},
+		{
+			name:        "last chunk dropped",
+			totalChunks: 3,
+			chunks: func(t *testing.T) []*items.ChunkData {
+				return encryptTestChunks(t, testFileID, "a", "b", "c")[:2]
+			},
+			wantCode: codes.DataLoss,
+		},
+		{
+			name:        "truncated with total chunks",
+			totalChunks: 2,
+			chunks: func(t *testing.T) []*items.ChunkData {
+				return encryptTestChunks(t, testFileID, "a", "b", "c")[:2]
+			},
+			wantCode: codes.DataLoss,
+		},
+		{
+			name:        "reordered",
+			totalChunks: 3,
+			chunks: func(t *testing.T) []*items.ChunkData {
+				chunks := encryptTestChunks(t, testFileID, "a", "b", "c")
+				chunks[0].EncryptedData, chunks[1].EncryptedData = chunks[1].EncryptedData, chunks[0].EncryptedData
+				chunks[0].IV, chunks[1].IV = chunks[1].IV, chunks[0].IV
+				return chunks
+			},
+			wantCode: codes.DataLoss,
+		},
+		{
+			name:        "substituted from another file",
+			totalChunks: 3,
+			chunks: func(t *testing.T) []*items.ChunkData {
+				chunks := encryptTestChunks(t, testFileID, "a", "b", "c")
+				chunks[1] = encryptTestChunks(t, testFileID+1, "x", "y", "z")[1]
+				return chunks
+			},
+			wantCode: codes.DataLoss,
+		},
+		{
+			name:        "chunk in the middle missing",
+			totalChunks: 3,
+			chunks: func(t *testing.T) []*items.ChunkData {
+				chunks := encryptTestChunks(t, testFileID, "a", "b", "c")
+				return []*items.ChunkData{chunks[0], chunks[2]}
+			},
+			wantCode: codes.DataLoss,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			decryptor, err := crypto.NewDecryptor(testKey)
+			require.NoError(t, err)
+			keys := cryptoMock.NewMockKeyResolver(ctrl)
+			keys.EXPECT().GetDecryptor(gomock.Any(), 1).Return(decryptor, nil).AnyTimes()
+
+			storage := binaryMock.NewMockFiler(ctrl)
+			storage.EXPECT().
+				GetFileInfo(gomock.Any(), testFileID, int64(1)).
+				Return(&items.FileInfo{ID: testFileID, TotalChunks: tt.totalChunks}, nil)
+			storage.EXPECT().
+				GetChunksInRange(gomock.Any(), testFileID, int32(0), tt.totalChunks).
+				Return(tt.chunks(t), nil)
+
+			server := &Server{storage: storage, keys: keys, workersCount: 1}
+			stream := &downloadStream{ctx: context.WithValue(context.Background(), "userID", 1)}
+			err = server.DownloadFile(&binarydata.DownloadFileRequest{FileId: testFileID}, stream)
+			if tt.wantCode != codes.OK {
+				assert.Equal(t, tt.wantCode, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+
+			var got []string
+			for _, chunk := range stream.chunks {
+				got = append(got, string(chunk.Data))
+			}
+			assert.Equal(t, tt.want, got)
+			assert.True(t, stream.chunks[len(stream.chunks)-1].IsLast)
+			assert.False(t, stream.chunks[0].IsLast)
+		})
+	}
+}
+
+func TestServer_DownloadFile_ClientDisconnected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	decryptor, err := crypto.NewDecryptor(testKey)
+	require.NoError(t, err)
+	keys := cryptoMock.NewMockKeyResolver(ctrl)
+	keys.EXPECT().GetDecryptor(gomock.Any(), 1).Return(decryptor, nil).AnyTimes()
+
+	// чанков больше, чем помещается в буфер канала
+	data := make([]string, 30)
+	for i := range data {
+		data[i] = fmt.Sprintf("chunk %d", i)
+	}
+	storage := binaryMock.NewMockFiler(ctrl)
+	storage.EXPECT().
+		GetFileInfo(gomock.Any(), testFileID, int64(1)).
+		Return(&items.FileInfo{ID: testFileID, TotalChunks: int32(len(data))}, nil)
+	storage.EXPECT().
+		GetChunksInRange(gomock.Any(), testFileID, int32(0), int32(len(data))).
+		Return(encryptTestChunks(t, testFileID, data...), nil)
+
+	goroutines := runtime.NumGoroutine()
+	server := &Server{storage: storage, keys: keys, workersCount: 1}
+	stream := &downloadStream{
+		ctx:     context.WithValue(context.Background(), "userID", 1),
+		sendErr: status.Error(codes.Unavailable, "client disconnected"),
+	}
+	err = server.DownloadFile(&binarydata.DownloadFileRequest{FileId: testFileID}, stream)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	// воркер не остается заблокированным на заполненном канале
+	for deadline := time.Now().Add(time.Second); runtime.NumGoroutine() > goroutines && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), goroutines)
+}
+
+func TestServer_DownloadFile_PreviousChunkScheme(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	encryptor, err := crypto.NewEncryptor(testKey, crypto.DefaultAlgorithm)
+	require.NoError(t, err)
+	encryptedData, algorithm, iv, err := encryptor.Encrypt([]byte("old"), crypto.ChunkAAD(testFileID, 0))
+	require.NoError(t, err)
+
+	decryptor, err := crypto.NewDecryptor(testKey)
+	require.NoError(t, err)
+	keys := cryptoMock.NewMockKeyResolver(ctrl)
+	keys.EXPECT().GetDecryptor(gomock.Any(), 1).Return(decryptor, nil)
+
+	storage := binaryMock.NewMockFiler(ctrl)
+	storage.EXPECT().
+		GetFileInfo(gomock.Any(), testFileID, int64(1)).
+		Return(&items.FileInfo{ID: testFileID, TotalChunks: 1}, nil)
+	storage.EXPECT().
+		GetChunksInRange(gomock.Any(), testFileID, int32(0), int32(1)).
+		Return([]*items.ChunkData{{
+			EncryptedData:       encryptedData,
+			EncryptionAlgorithm: algorithm,
+			IV:                  iv,
+			KeyVersion:          1,
+			AADBound:            true,
+		}}, nil)
+
+	server := &Server{storage: storage, keys: keys, workersCount: 1}
+	stream := &downloadStream{ctx: context.WithValue(context.Background(), "userID", 1)}
+	require.NoError(t, server.DownloadFile(&binarydata.DownloadFileRequest{FileId: testFileID}, stream))
+	require.Len(t, stream.chunks, 1)
+	assert.Equal(t, []byte("old"), stream.chunks[0].Data)
+}
+
+// TestServer_ForeignFile файл другого пользователя не находится хранилищем,
+// сервер запрашивает его от имени пользователя из контекста и отвечает NotFound
+func TestServer_ForeignFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	notFound := errors.New("no rows in result set")
+	ctx := context.WithValue(context.Background(), "userID", 2)
+
+	tests := []struct {
+		name   string
+		expect func(storage *binaryMock.MockFiler)
+		call   func(s *Server) error
+	}{
+		{
+			name: "info",
+			expect: func(storage *binaryMock.MockFiler) {
+				storage.EXPECT().GetFileInfo(ctx, testFileID, int64(2)).Return(nil, notFound)
+			},
+			call: func(s *Server) error {
+				_, err := s.GetFileInfo(ctx, &binarydata.GetFileInfoRequest{FileId: testFileID})
+				return err
+			},
+		},
+		{
+			name: "download",
+			expect: func(storage *binaryMock.MockFiler) {
+				storage.EXPECT().GetFileInfo(ctx, testFileID, int64(2)).Return(nil, notFound)
+			},
+			call: func(s *Server) error {
+				return s.DownloadFile(&binarydata.DownloadFileRequest{FileId: testFileID}, &downloadStream{ctx: ctx})
+			},
+		},
+		{
+			name: "delete",
+			expect: func(storage *binaryMock.MockFiler) {
+				storage.EXPECT().DeleteFile(ctx, int64(2), testFileID).Return(notFound)
+			},
+			call: func(s *Server) error {
+				_, err := s.DeleteFile(ctx, &binarydata.DeleteFileRequest{FileId: testFileID})
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := binaryMock.NewMockFiler(ctrl)
+			tt.expect(storage)
+			server := &Server{storage: storage, keys: cryptoMock.NewMockKeyResolver(ctrl), workersCount: 1}
+
+			assert.Equal(t, codes.NotFound, status.Code(tt.call(server)))
+		})
+	}
+}
+
+// TestServer_UploadFile_Tx запись о файле, чанки и отметка о завершении сохраняются в одной транзакции,
+// при ошибке сохранения чанка транзакция откатывается и файл не отмечается загруженным
+func TestServer_UploadFile_Tx(t *testing.T) {
+	metadata := &binarydata.FileMetadata{Filename: "notes.txt", TotalChunks: 3}
+	requests := func() []*binarydata.UploadFileRequest {
+		requests := []*binarydata.UploadFileRequest{
+			{Data: &binarydata.UploadFileRequest_Metadata{Metadata: metadata}},
+		}
+		for i, data := range []string{"ab", "cd", "e"} {
+			requests = append(requests, &binarydata.UploadFileRequest{
+				Data: &binarydata.UploadFileRequest_Chunk{Chunk: &binarydata.FileChunk{ChunkIndex: int32(i), Data: []byte(data)}},
+			})
+		}
+		return requests
+	}
+
+	tests := []struct {
+		name     string
+		chunkErr error
+		wantCode codes.Code
+	}{
+		{
+			name:     "uploaded",
+			wantCode: codes.OK,
+		},
+		{
+			name:     "chunk not saved",
+			chunkErr: errors.New("connection reset"),
+			wantCode: codes.Internal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			encryptor, err := crypto.NewEncryptor(testKey, crypto.DefaultAlgorithm)
+			require.NoError(t, err)
+			keys := cryptoMock.NewMockKeyResolver(ctrl)
+			keys.EXPECT().GetEncryptor(gomock.Any()).Return(encryptor, nil)
+			keys.EXPECT().KeyVersion().Return(1).AnyTimes()
+
+			ctx := context.WithValue(context.Background(), "userID", 1)
+			var txErr error
+			storage := binaryMock.NewMockFiler(ctrl)
+			storage.EXPECT().WithTx(ctx, gomock.Any()).
+				DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					txErr = fn(ctx)
+					return txErr
+				})
+			storage.EXPECT().ReserveFileID(ctx).Return(testFileID, nil)
+			storage.EXPECT().CreateFileRecord(ctx, 1, testFileID, metadata).Return(testFileID, nil)
+			if tt.chunkErr == nil {
+				storage.EXPECT().
+					SaveChunk(ctx, testFileID, gomock.Any(), gomock.Any(), crypto.DefaultAlgorithm, gomock.Any(), 1, true, true).
+					Return(nil).
+					Times(3)
+				storage.EXPECT().MarkFileComplete(ctx, testFileID, int64(5)).Return(nil)
+			} else {
+				storage.EXPECT().
+					SaveChunk(ctx, testFileID, gomock.Any(), gomock.Any(), crypto.DefaultAlgorithm, gomock.Any(), 1, true, true).
+					Return(tt.chunkErr)
+			}
+
+			server := &Server{storage: storage, keys: keys, workersCount: 2}
+			stream := &uploadStream{ctx: ctx, requests: requests()}
+			err = server.UploadFile(stream)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode != codes.OK {
+				assert.Error(t, txErr)
+				assert.Nil(t, stream.response)
+				return
+			}
+			require.NotNil(t, stream.response)
+			assert.Equal(t, testFileID, stream.response.FileId)
+			assert.Equal(t, int64(5), stream.response.BytesReceived)
+		})
+	}
+}
+
+// TestServer_UploadFile_ClientEncrypted части, зашифрованные на клиенте, сохраняются только
+// в файл с идентификатором, который клиент зарезервировал и к которому привязал части
+func TestServer_UploadFile_ClientEncrypted(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileID   int64
+		claimErr error
+		wantCode codes.Code
+	}{
+		{
+			name:     "reserved",
+			fileID:   testFileID,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "no reserved id",
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			// чужой, просроченный или уже использованный идентификатор
+			name:     "not reserved",
+			fileID:   testFileID,
+			claimErr: status.Error(codes.NotFound, "reserved id not found"),
+			wantCode: codes.InvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			encryptor, err := crypto.NewEncryptor(testKey, crypto.DefaultAlgorithm)
+			require.NoError(t, err)
+			keys := cryptoMock.NewMockKeyResolver(ctrl)
+			keys.EXPECT().GetEncryptor(gomock.Any()).Return(encryptor, nil)
+			keys.EXPECT().KeyVersion().Return(1).AnyTimes()
+
+			ctx := context.WithValue(context.Background(), "userID", 1)
+			metadata := &binarydata.FileMetadata{Filename: "notes.txt", OriginalSize: 2, TotalChunks: 1, ClientEncrypted: true, FileId: tt.fileID}
+			storage := binaryMock.NewMockFiler(ctrl)
+			storage.EXPECT().WithTx(ctx, gomock.Any()).
+				DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+			if tt.fileID != 0 {
+				storage.EXPECT().ClaimFileID(ctx, int64(1), tt.fileID).Return(tt.claimErr)
+			}
+			if tt.wantCode == codes.OK {
+				storage.EXPECT().CreateFileRecord(ctx, 1, testFileID, metadata).Return(testFileID, nil)
+				storage.EXPECT().
+					SaveChunk(ctx, testFileID, int32(0), []byte("sealed"), vault.Algorithm, []byte{}, 1, true, true).
+					Return(nil)
+				storage.EXPECT().MarkFileComplete(ctx, testFileID, int64(2)).Return(nil)
+			}
+
+			server := &Server{storage: storage, keys: keys, workersCount: 2}
+			stream := &uploadStream{ctx: ctx, requests: []*binarydata.UploadFileRequest{
+				{Data: &binarydata.UploadFileRequest_Metadata{Metadata: metadata}},
+				{Data: &binarydata.UploadFileRequest_Chunk{Chunk: &binarydata.FileChunk{Data: []byte("sealed"), IsLast: true}}},
+			}}
+			err = server.UploadFile(stream)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+// TestServer_UploadFile_RepeatedMetadata повторные метаданные не создают вторую запись о файле в той же транзакции
+func TestServer_UploadFile_RepeatedMetadata(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	encryptor, err := crypto.NewEncryptor(testKey, crypto.DefaultAlgorithm)
+	require.NoError(t, err)
+	keys := cryptoMock.NewMockKeyResolver(ctrl)
+	keys.EXPECT().GetEncryptor(gomock.Any()).Return(encryptor, nil)
+	keys.EXPECT().KeyVersion().Return(1).AnyTimes()
+
+	ctx := context.WithValue(context.Background(), "userID", 1)
+	metadata := &binarydata.FileMetadata{Filename: "notes.txt", TotalChunks: 2}
+	storage := binaryMock.NewMockFiler(ctrl)
+	storage.EXPECT().WithTx(ctx, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		})
+	storage.EXPECT().ReserveFileID(ctx).Return(testFileID, nil).Times(1)
+	storage.EXPECT().CreateFileRecord(ctx, 1, testFileID, metadata).Return(testFileID, nil).Times(1)
+	storage.EXPECT().
+		SaveChunk(ctx, testFileID, int32(0), gomock.Any(), crypto.DefaultAlgorithm, gomock.Any(), 1, true, true).
+		Return(nil)
+
+	server := &Server{storage: storage, keys: keys, workersCount: 2}
+	stream := &uploadStream{ctx: ctx, requests: []*binarydata.UploadFileRequest{
+		{Data: &binarydata.UploadFileRequest_Metadata{Metadata: metadata}},
+		{Data: &binarydata.UploadFileRequest_Chunk{Chunk: &binarydata.FileChunk{ChunkIndex: 0, Data: []byte("ab")}}},
+		{Data: &binarydata.UploadFileRequest_Metadata{Metadata: metadata}},
+	}}
+	err = server.UploadFile(stream)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Nil(t, stream.response)
+}
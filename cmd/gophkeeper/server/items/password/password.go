@@ -10,6 +10,7 @@ import (
 	itemsConstants "github.com/ramil063/secondgodiplom/internal/constants/items"
 	passwordPb "github.com/ramil063/secondgodiplom/internal/proto/gen/items/password"
 	"github.com/ramil063/secondgodiplom/internal/security/crypto"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/vault"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -18,20 +19,32 @@ import (
 type Server struct {
 	passwordPb.UnimplementedServiceServer
 
-	storage   items.Itemer
-	Encryptor crypto.Encryptor
-	Decryptor crypto.Decryptor
+	storage items.Itemer
+	keys    crypto.KeyResolver
 }
 
-// NewServer инициализация сервера, шифровальщика, дешифровщика и структуры для работы с хранилищем
-func NewServer(storage items.Itemer, encryptor crypto.Encryptor, decryptor crypto.Decryptor) *Server {
+// NewServer инициализация сервера, получателя ключей шифрования пользователей и структуры для работы с хранилищем
+func NewServer(storage items.Itemer, keys crypto.KeyResolver) *Server {
 	return &Server{
-		storage:   storage,
-		Encryptor: encryptor,
-		Decryptor: decryptor,
+		storage: storage,
+		keys:    keys,
 	}
 }
 
+// ReservePasswordID резервирование идентификатора записи для пароля, зашифрованных на клиенте
+// клиент привязывает шифротекст к идентификатору до создания записи (vault.ItemAAD)
+func (s *Server) ReservePasswordID(ctx context.Context, _ *empty.Empty) (*passwordPb.ReservedID, error) {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
+	}
+	itemID, err := s.storage.ReserveClientItemID(ctx, int64(userID))
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to reserve item id")
+	}
+	return &passwordPb.ReservedID{Id: itemID}, nil
+}
+
 // CreatePassword создание записи о пароле
 // так же сохранение метаданных о нем
 // все специфичные данные шифруются
@@ -40,43 +53,51 @@ func (s *Server) CreatePassword(ctx context.Context, req *passwordPb.CreatePassw
 	if !ok {
 		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
 	}
-	// 1. Создаем структуру для шифрования
-	sensitiveData := &passwordsModel.SensitivePasswordData{
-		Login:    req.Login,
-		Password: req.Password,
-		Target:   req.Target,
-	}
-
-	// 2. Сериализуем в JSON
-	jsonData, err := sensitiveData.ToJSON()
+	// Идентификатор нужен заранее, так как шифротекст привязывается к записи
+	itemID, err := s.newItemID(ctx, req.Id, req.EncryptedPayload)
 	if err != nil {
-		return nil, status.Error(codes.Internal, "failed to serialize data")
+		return nil, err
 	}
 
-	// 3. Шифруем всю структуру
-	encryptedData, algorithm, iv, err := s.Encryptor.Encrypt(jsonData)
+	// 1-3. Шифруем данные, если они не зашифрованы на клиенте
+	aad := crypto.ItemAAD(int64(userID), itemID, itemsConstants.TypePasswords)
+	encryptedData, algorithm, iv, err := s.encryptSensitiveData(ctx, aad, req.Login, req.Password, req.Target, req.EncryptedPayload)
 	if err != nil {
-		return nil, status.Error(codes.Internal, "failed to encrypt data")
+		return nil, err
 	}
 
-	// 4. Сохраняем в основную таблицу
-	itemID, err := s.storage.SaveEncryptedData(ctx, &passwordsModel.EncryptedItem{
-		UserID:              int64(userID),
-		Type:                itemsConstants.TypePasswords,
-		Data:                encryptedData,
-		Description:         req.Description,
-		EncryptionAlgorithm: algorithm,
-		Iv:                  iv,
-	})
+	// 4. Сохраняем запись и метаданные в одной транзакции
+	err = s.storage.WithTx(ctx, func(ctx context.Context) error {
+		if err = s.claimItemID(ctx, userID, itemID, req.EncryptedPayload); err != nil {
+			return err
+		}
+		itemID, err = s.storage.SaveEncryptedData(ctx, &passwordsModel.EncryptedItem{
+			ID:                  itemID,
+			UserID:              int64(userID),
+			Type:                itemsConstants.TypePasswords,
+			Data:                encryptedData,
+			Description:         req.Description,
+			EncryptionAlgorithm: algorithm,
+			Iv:                  iv,
+			KeyVersion:          s.keys.KeyVersion(),
+			AADBound:            true,
+		})
+		if err != nil {
+			return status.Error(codes.Internal, "failed to save password")
+		}
 
-	// 4. Сохраняем метаданные в отдельную таблицу
-	err = s.storage.SaveMetadata(ctx, &passwordsModel.MetaData{
-		ItemID: itemID,
-		Name:   req.MetaDataName,
-		Value:  req.MetaDataValue,
+		err = s.storage.SaveMetadata(ctx, int64(userID), itemsConstants.TypePasswords, &passwordsModel.MetaData{
+			ItemID: itemID,
+			Name:   req.MetaDataName,
+			Value:  req.MetaDataValue,
+		})
+		if err != nil {
+			return status.Error(codes.Internal, "failed to save meta data")
+		}
+		return nil
 	})
 	if err != nil {
-		return nil, status.Error(codes.Internal, "failed to save meta data")
+		return nil, err
 	}
 
 	// 5. Возвращаем ответ
@@ -92,9 +113,85 @@ func (s *Server) CreatePassword(ctx context.Context, req *passwordPb.CreatePassw
 				Value: req.MetaDataValue,
 			},
 		},
+		EncryptedPayload: req.EncryptedPayload,
 	}, nil
 }
 
+// encryptSensitiveData шифрование чувствительных данных пароля
+// данные, зашифрованные на клиенте, сохраняются как есть
+// aad связанные данные записи (crypto.ItemAAD)
+func (s *Server) encryptSensitiveData(
+	ctx context.Context,
+	aad []byte,
+	login, password, target string,
+	encryptedPayload []byte,
+) ([]byte, string, []byte, error) {
+	if len(encryptedPayload) > 0 {
+		return encryptedPayload, vault.Algorithm, nil, nil
+	}
+
+	// 1. Создаем структуру для шифрования
+	sensitiveData := &passwordsModel.SensitivePasswordData{
+		Login:    login,
+		Password: password,
+		Target:   target,
+	}
+
+	// 2. Сериализуем в JSON
+	jsonData, err := sensitiveData.ToJSON()
+	if err != nil {
+		return nil, "", nil, status.Error(codes.Internal, "failed to serialize data")
+	}
+
+	// 3. Шифруем всю структуру
+	encryptor, err := s.keys.GetEncryptor(ctx)
+	if err != nil {
+		return nil, "", nil, status.Error(codes.Internal, "failed to get encryption key")
+	}
+	encryptedData, algorithm, iv, err := encryptor.Encrypt(jsonData, aad)
+	if err != nil {
+		return nil, "", nil, status.Error(codes.Internal, "failed to encrypt data")
+	}
+	return encryptedData, algorithm, iv, nil
+}
+
+// decryptSensitiveData расшифровка чувствительных данных пароля в элемент ответа
+// данные, зашифрованные на клиенте, возвращаются как есть
+// записи, сохраненные без привязки, расшифровываются без связанных данных
+func (s *Server) decryptSensitiveData(
+	ctx context.Context,
+	userID int,
+	item *passwordsModel.ItemData,
+	pbItem *passwordPb.PasswordItem,
+) error {
+	if vault.IsClientEncrypted(item.EncryptionAlgorithm) {
+		pbItem.EncryptedPayload = item.Data
+		return nil
+	}
+
+	decryptor, err := s.keys.GetDecryptor(ctx, item.KeyVersion)
+	if err != nil {
+		return status.Error(codes.Internal, "failed to get decryption key")
+	}
+	var aad []byte
+	if item.AADBound {
+		aad = crypto.ItemAAD(int64(userID), item.ID, itemsConstants.TypePasswords)
+	}
+	decryptedData, err := decryptor.Decrypt(item.Data, item.IV, item.EncryptionAlgorithm, aad)
+	if err != nil {
+		return status.Error(codes.Internal, "failed to decrypt password")
+	}
+	sensitiveData, err := passwordsModel.SensitivePasswordDataFromJSON(decryptedData)
+	if err != nil {
+		return status.Error(codes.Internal, "failed to parse sensitive password")
+	}
+
+	pbItem.Login = sensitiveData.Login
+	pbItem.Password = sensitiveData.Password
+	pbItem.Target = sensitiveData.Target
+	return nil
+}
+
 // ListPasswords листинг данных о паролях
 // так же показываются метаданные по каждому
 func (s *Server) ListPasswords(
@@ -130,12 +227,12 @@ func (s *Server) ListPasswords(
 	for i := range passwordsList {
 		p := passwordsList[i]
 
-		decryptedData, err := s.Decryptor.Decrypt(p.Data, p.IV)
-		if err != nil {
-			continue
+		pbPassword := &passwordPb.PasswordItem{
+			Id:          p.ID,
+			Description: p.Description,
+			CreatedAt:   p.CreatedAt.String(),
 		}
-		sensitiveData, err := passwordsModel.SensitivePasswordDataFromJSON(decryptedData)
-		if err != nil {
+		if err = s.decryptSensitiveData(ctx, userID, p, pbPassword); err != nil {
 			continue
 		}
 
@@ -147,16 +244,9 @@ func (s *Server) ListPasswords(
 			}
 			pbMetaData = append(pbMetaData, &pbMetaDataItem)
 		}
+		pbPassword.MetaData = pbMetaData
 
-		pbPasswords = append(pbPasswords, &passwordPb.PasswordItem{
-			Id:          p.ID,
-			Login:       sensitiveData.Login,
-			Password:    sensitiveData.Password,
-			Target:      sensitiveData.Target,
-			Description: p.Description,
-			CreatedAt:   p.CreatedAt.String(),
-			MetaData:    pbMetaData,
-		})
+		pbPasswords = append(pbPasswords, pbPassword)
 	}
 	totalPages := int32(math.Ceil(float64(totalCount) / float64(req.PerPage)))
 
@@ -172,54 +262,52 @@ func (s *Server) ListPasswords(
 // так же возвращаются и метаданные
 func (s *Server) GetPassword(ctx context.Context, req *passwordPb.GetPasswordRequest) (*passwordPb.PasswordItem, error) {
 	// Извлекаем userID из контекста
-	if _, ok := ctx.Value("userID").(int); !ok {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
 		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
 	}
 
 	// Получаем данные из репозитория
-	password, err := s.storage.GetItem(ctx, req.Id)
+	password, err := s.storage.GetItem(ctx, int64(userID), itemsConstants.TypePasswords, req.Id)
+	if status.Code(err) == codes.NotFound {
+		return nil, status.Error(codes.NotFound, "password not found")
+	}
 	if err != nil || password == nil {
 		return nil, status.Error(codes.Internal, "failed to get password")
 	}
 
-	decryptedData, err := s.Decryptor.Decrypt(password.Data, password.IV)
-	if err != nil {
-		return nil, status.Error(codes.Internal, "failed to decrypt password")
+	pbPassword := &passwordPb.PasswordItem{
+		Id:          password.ID,
+		Description: password.Description,
+		CreatedAt:   password.CreatedAt.String(),
 	}
-	sensitiveData, err := passwordsModel.SensitivePasswordDataFromJSON(decryptedData)
-	if err != nil {
-		return nil, status.Error(codes.Internal, "failed to parse sensitive password")
+	if err = s.decryptSensitiveData(ctx, userID, password, pbPassword); err != nil {
+		return nil, err
 	}
 
-	var metaDataList []*passwordPb.MetaData
-
 	for _, val := range password.MetaDataItems {
-		metaDataList = append(metaDataList, &passwordPb.MetaData{
+		pbPassword.MetaData = append(pbPassword.MetaData, &passwordPb.MetaData{
 			Id:    val.ID,
 			Name:  val.Name,
 			Value: val.Value,
 		})
 	}
 
-	return &passwordPb.PasswordItem{
-		Id:          password.ID,
-		Login:       sensitiveData.Login,
-		Password:    sensitiveData.Password,
-		Target:      sensitiveData.Target,
-		Description: password.Description,
-		CreatedAt:   password.CreatedAt.String(),
-		MetaData:    metaDataList,
-	}, nil
+	return pbPassword, nil
 }
 
 // DeletePassword удаление данных о пароле
 // используется мягкое удаление
 func (s *Server) DeletePassword(ctx context.Context, req *passwordPb.DeletePasswordRequest) (*empty.Empty, error) {
 	// Извлекаем userID из контекста
-	if _, ok := ctx.Value("userID").(int); !ok {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
 		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
 	}
-	err := s.storage.DeleteItem(ctx, req.Id)
+	err := s.storage.DeleteItem(ctx, int64(userID), itemsConstants.TypePasswords, req.Id)
+	if status.Code(err) == codes.NotFound {
+		return nil, status.Error(codes.NotFound, "password not found")
+	}
 	if err != nil {
 		return nil, status.Error(codes.Internal, "failed to delete password")
 	}
@@ -231,51 +319,92 @@ func (s *Server) UpdatePassword(
 	ctx context.Context,
 	req *passwordPb.UpdatePasswordRequest,
 ) (*passwordPb.PasswordItem, error) {
-	if _, ok := ctx.Value("userID").(int); !ok {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
 		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
 	}
-	password, err := s.storage.GetItem(ctx, req.Id)
-	if err != nil || password == nil {
-		return nil, status.Error(codes.Internal, "failed to get password")
-	}
 
-	// 1. Создаем структуру для шифрования
-	sensitiveData := &passwordsModel.SensitivePasswordData{
-		Login:    req.Login,
-		Password: req.Password,
-		Target:   req.Target,
-	}
+	// Запись проверяется и обновляется в одной транзакции
+	var itemID int64
+	err := s.storage.WithTx(ctx, func(ctx context.Context) error {
+		password, err := s.storage.GetItem(ctx, int64(userID), itemsConstants.TypePasswords, req.Id)
+		if status.Code(err) == codes.NotFound {
+			return status.Error(codes.NotFound, "password not found")
+		}
+		if err != nil || password == nil {
+			return status.Error(codes.Internal, "failed to get password")
+		}
 
-	// 2. Сериализуем в JSON
-	jsonData, err := sensitiveData.ToJSON()
-	if err != nil {
-		return nil, status.Error(codes.Internal, "failed to serialize data")
-	}
+		// 1-3. Шифруем данные, если они не зашифрованы на клиенте
+		aad := crypto.ItemAAD(int64(userID), req.Id, itemsConstants.TypePasswords)
+		encryptedData, algorithm, iv, err := s.encryptSensitiveData(ctx, aad, req.Login, req.Password, req.Target, req.EncryptedPayload)
+		if err != nil {
+			return err
+		}
 
-	// 3. Шифруем всю структуру
-	encryptedData, algorithm, iv, err := s.Encryptor.Encrypt(jsonData)
+		// 4. Сохраняем в основную таблицу
+		itemID, err = s.storage.UpdateItem(ctx, int64(userID), itemsConstants.TypePasswords, req.Id, &passwordsModel.EncryptedItem{
+			Data:                encryptedData,
+			Description:         req.Description,
+			EncryptionAlgorithm: algorithm,
+			Iv:                  iv,
+			KeyVersion:          s.keys.KeyVersion(),
+			AADBound:            true,
+		})
+		if status.Code(err) == codes.NotFound {
+			return status.Error(codes.NotFound, "password not found")
+		}
+		if err != nil {
+			return status.Error(codes.Internal, "failed to update password")
+		}
+
+		// TODO Сохранять метаданные в отдельном реквесте
+		//err = s.storage.SaveMetadata(ctx, int64(userID), itemsConstants.TypePasswords, &passwordsModel.MetaData{
+		//	ItemID: itemID,
+		//	Name:   req.MetaDataName,
+		//	Value:  req.MetaDataValue,
+		//})
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// 4. Сохраняем в основную таблицу
-	itemID, err := s.storage.UpdateItem(ctx, req.Id, &passwordsModel.EncryptedItem{
-		Data:                encryptedData,
-		Description:         req.Description,
-		EncryptionAlgorithm: algorithm,
-		Iv:                  iv,
-	})
-
-	// TODO Сохранять метаданные в отдельном реквесте
-	//err = s.storage.SaveMetadata(ctx, &passwordsModel.MetaData{
-	//	ItemID: itemID,
-	//	Name:   req.MetaDataName,
-	//	Value:  req.MetaDataValue,
-	//})
-
 	// 5. Возвращаем ответ
 	return &passwordPb.PasswordItem{
 		Id:          itemID,
 		Description: req.Description,
 	}, nil
 }
+
+// newItemID идентификатор новой записи
+// данные, зашифрованные на клиенте, привязаны к идентификатору, зарезервированному клиентом (ReservePasswordID)
+func (s *Server) newItemID(ctx context.Context, reservedID int64, encryptedPayload []byte) (int64, error) {
+	if len(encryptedPayload) == 0 {
+		itemID, err := s.storage.ReserveItemID(ctx)
+		if err != nil {
+			return 0, status.Error(codes.Internal, "failed to reserve item id")
+		}
+		return itemID, nil
+	}
+	if reservedID == 0 {
+		return 0, status.Error(codes.InvalidArgument, "reserved item id is required for encrypted payload")
+	}
+	return reservedID, nil
+}
+
+// claimItemID использование идентификатора, зарезервированного клиентом, для данных, зашифрованных на клиенте
+// идентификатор используется один раз, поэтому шифротекст нельзя сохранить в другую запись
+func (s *Server) claimItemID(ctx context.Context, userID int, itemID int64, encryptedPayload []byte) error {
+	if len(encryptedPayload) == 0 {
+		return nil
+	}
+	err := s.storage.ClaimItemID(ctx, int64(userID), itemID)
+	if status.Code(err) == codes.NotFound {
+		return status.Error(codes.InvalidArgument, "item id is not reserved")
+	}
+	if err != nil {
+		return status.Error(codes.Internal, "failed to claim item id")
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package password
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	itemsMock "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/items/mocks"
+	itemModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/items"
+	itemsConstants "github.com/ramil063/secondgodiplom/internal/constants/items"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/password"
+	cryptoMock "github.com/ramil063/secondgodiplom/internal/security/crypto/mocks"
+)
+
+// runTx выполнение функции транзакции без бд
+func runTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// TestServer_ForeignItem запись другого пользователя или другого типа не находится хранилищем,
+// сервер запрашивает ее от имени пользователя из контекста и отвечает NotFound
+func TestServer_ForeignItem(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	notFound := status.Error(codes.NotFound, "item not found")
+	ctx := context.WithValue(context.Background(), "userID", 2)
+
+	tests := []struct {
+		name   string
+		expect func(storage *itemsMock.MockItemer)
+		call   func(s *Server) error
+	}{
+		{
+			name: "get",
+			expect: func(storage *itemsMock.MockItemer) {
+				storage.EXPECT().GetItem(ctx, int64(2), itemsConstants.TypePasswords, int64(1)).Return(nil, notFound)
+			},
+			call: func(s *Server) error {
+				_, err := s.GetPassword(ctx, &password.GetPasswordRequest{Id: 1})
+				return err
+			},
+		},
+		{
+			name: "update",
+			expect: func(storage *itemsMock.MockItemer) {
+				storage.EXPECT().WithTx(ctx, gomock.Any()).DoAndReturn(runTx)
+				storage.EXPECT().GetItem(ctx, int64(2), itemsConstants.TypePasswords, int64(1)).Return(nil, notFound)
+			},
+			call: func(s *Server) error {
+				_, err := s.UpdatePassword(ctx, &password.UpdatePasswordRequest{Id: 1, Description: "changed"})
+				return err
+			},
+		},
+		{
+			name: "delete",
+			expect: func(storage *itemsMock.MockItemer) {
+				storage.EXPECT().DeleteItem(ctx, int64(2), itemsConstants.TypePasswords, int64(1)).Return(notFound)
+			},
+			call: func(s *Server) error {
+				_, err := s.DeletePassword(ctx, &password.DeletePasswordRequest{Id: 1})
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storageMock := itemsMock.NewMockItemer(ctrl)
+			s := NewServer(storageMock, cryptoMock.NewMockKeyResolver(ctrl))
+			tt.expect(storageMock)
+
+			assert.Equal(t, codes.NotFound, status.Code(tt.call(s)))
+		})
+	}
+}
+
+// TestServer_CreatePassword_Tx запись и метаданные сохраняются в одной транзакции,
+// ошибка любого шага возвращается из WithTx, и транзакция откатывается
+func TestServer_CreatePassword_Tx(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), "userID", 1)
+	errSQL := errors.New("connection reset")
+
+	tests := []struct {
+		name        string
+		saveErr     error
+		metadataErr error
+		wantCode    codes.Code
+	}{
+		{
+			name:     "saved",
+			wantCode: codes.OK,
+		},
+		{
+			name:     "item not saved",
+			saveErr:  errSQL,
+			wantCode: codes.Internal,
+		},
+		{
+			name:        "metadata not saved",
+			metadataErr: errSQL,
+			wantCode:    codes.Internal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storageMock := itemsMock.NewMockItemer(ctrl)
+			keysMock := cryptoMock.NewMockKeyResolver(ctrl)
+			encryptorMock := cryptoMock.NewMockEncryptor(ctrl)
+			s := NewServer(storageMock, keysMock)
+
+			keysMock.EXPECT().GetEncryptor(ctx).Return(encryptorMock, nil)
+			keysMock.EXPECT().KeyVersion().Return(1)
+			encryptorMock.EXPECT().Encrypt(gomock.Any(), gomock.Any()).Return([]byte("data"), "A256GCM", []byte("iv"), nil)
+			storageMock.EXPECT().ReserveItemID(ctx).Return(int64(7), nil)
+
+			var txErr error
+			storageMock.EXPECT().WithTx(ctx, gomock.Any()).
+				DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					txErr = fn(ctx)
+					return txErr
+				})
+			storageMock.EXPECT().SaveEncryptedData(ctx, gomock.Any()).Return(int64(7), tt.saveErr)
+			if tt.saveErr == nil {
+				storageMock.EXPECT().
+					SaveMetadata(ctx, int64(1), itemsConstants.TypePasswords, &itemModel.MetaData{ItemID: 7, Name: "site", Value: "example.com"}).
+					Return(tt.metadataErr)
+			}
+
+			got, err := s.CreatePassword(ctx, &password.CreatePasswordRequest{
+				Login:         "alice",
+				Password:      "secret",
+				MetaDataName:  "site",
+				MetaDataValue: "example.com",
+			})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode != codes.OK {
+				assert.Nil(t, got)
+				assert.Error(t, txErr)
+				return
+			}
+			assert.Equal(t, int64(7), got.Id)
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	itemsConstants "github.com/ramil063/secondgodiplom/internal/constants/items"
 	textDataPb "github.com/ramil063/secondgodiplom/internal/proto/gen/items/textdata"
 	"github.com/ramil063/secondgodiplom/internal/security/crypto"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/vault"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -18,20 +19,32 @@ import (
 type Server struct {
 	textDataPb.UnimplementedServiceServer
 
-	storage   items.Itemer
-	Encryptor crypto.Encryptor
-	Decryptor crypto.Decryptor
+	storage items.Itemer
+	keys    crypto.KeyResolver
 }
 
-// NewServer инициализация сервера, шифровальщика, дешифровщика и структуры для работы с хранилищем
-func NewServer(storage items.Itemer, encryptor crypto.Encryptor, decryptor crypto.Decryptor) *Server {
+// NewServer инициализация сервера, получателя ключей шифрования пользователей и структуры для работы с хранилищем
+func NewServer(storage items.Itemer, keys crypto.KeyResolver) *Server {
 	return &Server{
-		storage:   storage,
-		Encryptor: encryptor,
-		Decryptor: decryptor,
+		storage: storage,
+		keys:    keys,
 	}
 }
 
+// ReserveTextDataID резервирование идентификатора записи для текстовых данных, зашифрованных на клиенте
+// клиент привязывает шифротекст к идентификатору до создания записи (vault.ItemAAD)
+func (s *Server) ReserveTextDataID(ctx context.Context, _ *empty.Empty) (*textDataPb.ReservedID, error) {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
+	}
+	itemID, err := s.storage.ReserveClientItemID(ctx, int64(userID))
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to reserve item id")
+	}
+	return &textDataPb.ReservedID{Id: itemID}, nil
+}
+
 // CreateTextData создание записи о текстовых данных
 // так же сохранение метаданных о них
 // весь текст шифруется
@@ -41,30 +54,51 @@ func (s *Server) CreateTextData(ctx context.Context, req *textDataPb.CreateTextD
 		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
 	}
 
-	// 3. Шифруем всю структуру
-	encryptedData, algorithm, iv, err := s.Encryptor.Encrypt([]byte(req.TextData))
+	// Идентификатор нужен заранее, так как шифротекст привязывается к записи
+	itemID, err := s.newItemID(ctx, req.Id, req.EncryptedPayload)
 	if err != nil {
-		return nil, status.Error(codes.Internal, "failed to encrypt data")
+		return nil, err
 	}
 
-	// 4. Сохраняем в основную таблицу
-	itemID, err := s.storage.SaveEncryptedData(ctx, &itemModel.EncryptedItem{
-		UserID:              int64(userID),
-		Type:                itemsConstants.TypeText,
-		Data:                encryptedData,
-		Description:         req.Description,
-		EncryptionAlgorithm: algorithm,
-		Iv:                  iv,
-	})
+	// 3. Шифруем всю структуру, если данные не зашифрованы на клиенте
+	aad := crypto.ItemAAD(int64(userID), itemID, itemsConstants.TypeText)
+	encryptedData, algorithm, iv, err := s.encryptTextData(ctx, aad, req.TextData, req.EncryptedPayload)
+	if err != nil {
+		return nil, err
+	}
 
-	// 4. Сохраняем метаданные в отдельную таблицу
-	err = s.storage.SaveMetadata(ctx, &itemModel.MetaData{
-		ItemID: itemID,
-		Name:   req.MetaDataName,
-		Value:  req.MetaDataValue,
+	// 4. Сохраняем запись и метаданные в одной транзакции
+	err = s.storage.WithTx(ctx, func(ctx context.Context) error {
+		if err = s.claimItemID(ctx, userID, itemID, req.EncryptedPayload); err != nil {
+			return err
+		}
+		itemID, err = s.storage.SaveEncryptedData(ctx, &itemModel.EncryptedItem{
+			ID:                  itemID,
+			UserID:              int64(userID),
+			Type:                itemsConstants.TypeText,
+			Data:                encryptedData,
+			Description:         req.Description,
+			EncryptionAlgorithm: algorithm,
+			Iv:                  iv,
+			KeyVersion:          s.keys.KeyVersion(),
+			AADBound:            true,
+		})
+		if err != nil {
+			return status.Error(codes.Internal, "failed to save text data")
+		}
+
+		err = s.storage.SaveMetadata(ctx, int64(userID), itemsConstants.TypeText, &itemModel.MetaData{
+			ItemID: itemID,
+			Name:   req.MetaDataName,
+			Value:  req.MetaDataValue,
+		})
+		if err != nil {
+			return status.Error(codes.Internal, "failed to save meta data")
+		}
+		return nil
 	})
 	if err != nil {
-		return nil, status.Error(codes.Internal, "failed to save meta data")
+		return nil, err
 	}
 
 	// 5. Возвращаем ответ
@@ -78,9 +112,64 @@ func (s *Server) CreateTextData(ctx context.Context, req *textDataPb.CreateTextD
 				Value: req.MetaDataValue,
 			},
 		},
+		EncryptedPayload: req.EncryptedPayload,
 	}, nil
 }
 
+// encryptTextData шифрование текстовых данных
+// данные, зашифрованные на клиенте, сохраняются как есть
+// aad связанные данные записи (crypto.ItemAAD)
+func (s *Server) encryptTextData(
+	ctx context.Context,
+	aad []byte,
+	textData string,
+	encryptedPayload []byte,
+) ([]byte, string, []byte, error) {
+	if len(encryptedPayload) > 0 {
+		return encryptedPayload, vault.Algorithm, nil, nil
+	}
+
+	encryptor, err := s.keys.GetEncryptor(ctx)
+	if err != nil {
+		return nil, "", nil, status.Error(codes.Internal, "failed to get encryption key")
+	}
+	encryptedData, algorithm, iv, err := encryptor.Encrypt([]byte(textData), aad)
+	if err != nil {
+		return nil, "", nil, status.Error(codes.Internal, "failed to encrypt data")
+	}
+	return encryptedData, algorithm, iv, nil
+}
+
+// decryptTextData расшифровка текстовых данных в элемент ответа
+// данные, зашифрованные на клиенте, возвращаются как есть
+// записи, сохраненные без привязки, расшифровываются без связанных данных
+func (s *Server) decryptTextData(
+	ctx context.Context,
+	userID int,
+	item *itemModel.ItemData,
+	pbItem *textDataPb.TextDataItem,
+) error {
+	if vault.IsClientEncrypted(item.EncryptionAlgorithm) {
+		pbItem.EncryptedPayload = item.Data
+		return nil
+	}
+
+	decryptor, err := s.keys.GetDecryptor(ctx, item.KeyVersion)
+	if err != nil {
+		return status.Error(codes.Internal, "failed to get decryption key")
+	}
+	var aad []byte
+	if item.AADBound {
+		aad = crypto.ItemAAD(int64(userID), item.ID, itemsConstants.TypeText)
+	}
+	decryptedData, err := decryptor.Decrypt(item.Data, item.IV, item.EncryptionAlgorithm, aad)
+	if err != nil {
+		return status.Error(codes.Internal, "failed to decrypt text data")
+	}
+	pbItem.TextData = string(decryptedData)
+	return nil
+}
+
 // ListTextDataItems листинг текстовых данных
 func (s *Server) ListTextDataItems(
 	ctx context.Context,
@@ -115,8 +204,12 @@ func (s *Server) ListTextDataItems(
 	for i := range passwordsList {
 		p := passwordsList[i]
 
-		decryptedData, err := s.Decryptor.Decrypt(p.Data, p.IV)
-		if err != nil {
+		pbTextData := &textDataPb.TextDataItem{
+			Id:          p.ID,
+			Description: p.Description,
+			CreatedAt:   p.CreatedAt.String(),
+		}
+		if err = s.decryptTextData(ctx, userID, p, pbTextData); err != nil {
 			continue
 		}
 
@@ -128,14 +221,9 @@ func (s *Server) ListTextDataItems(
 			}
 			pbMetaData = append(pbMetaData, &pbMetaDataItem)
 		}
+		pbTextData.MetaData = pbMetaData
 
-		pbPasswords = append(pbPasswords, &textDataPb.TextDataItem{
-			Id:          p.ID,
-			TextData:    string(decryptedData),
-			Description: p.Description,
-			CreatedAt:   p.CreatedAt.String(),
-			MetaData:    pbMetaData,
-		})
+		pbPasswords = append(pbPasswords, pbTextData)
 	}
 	totalPages := int32(math.Ceil(float64(totalCount) / float64(req.PerPage)))
 
@@ -150,47 +238,51 @@ func (s *Server) ListTextDataItems(
 // GetTextData получение 1 текстовых данных
 func (s *Server) GetTextData(ctx context.Context, req *textDataPb.GetTextDataRequest) (*textDataPb.TextDataItem, error) {
 	// Извлекаем userID из контекста
-	if _, ok := ctx.Value("userID").(int); !ok {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
 		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
 	}
 
 	// Получаем данные из репозитория
-	password, err := s.storage.GetItem(ctx, req.Id)
+	password, err := s.storage.GetItem(ctx, int64(userID), itemsConstants.TypeText, req.Id)
+	if status.Code(err) == codes.NotFound {
+		return nil, status.Error(codes.NotFound, "text data not found")
+	}
 	if err != nil || password == nil {
 		return nil, status.Error(codes.Internal, "failed to get password")
 	}
 
-	decryptedData, err := s.Decryptor.Decrypt(password.Data, password.IV)
-	if err != nil {
-		return nil, status.Error(codes.Internal, "failed to decrypt password")
+	pbTextData := &textDataPb.TextDataItem{
+		Id:          password.ID,
+		Description: password.Description,
+		CreatedAt:   password.CreatedAt.String(),
+	}
+	if err = s.decryptTextData(ctx, userID, password, pbTextData); err != nil {
+		return nil, err
 	}
-
-	var metaDataList []*textDataPb.MetaData
 
 	for _, val := range password.MetaDataItems {
-		metaDataList = append(metaDataList, &textDataPb.MetaData{
+		pbTextData.MetaData = append(pbTextData.MetaData, &textDataPb.MetaData{
 			Id:    val.ID,
 			Name:  val.Name,
 			Value: val.Value,
 		})
 	}
 
-	return &textDataPb.TextDataItem{
-		Id:          password.ID,
-		TextData:    string(decryptedData),
-		Description: password.Description,
-		CreatedAt:   password.CreatedAt.String(),
-		MetaData:    metaDataList,
-	}, nil
+	return pbTextData, nil
 }
 
 // DeleteTextData удаление текстовых данных
 func (s *Server) DeleteTextData(ctx context.Context, req *textDataPb.DeleteTextDataRequest) (*empty.Empty, error) {
 	// Извлекаем userID из контекста
-	if _, ok := ctx.Value("userID").(int); !ok {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
 		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
 	}
-	err := s.storage.DeleteItem(ctx, req.Id)
+	err := s.storage.DeleteItem(ctx, int64(userID), itemsConstants.TypeText, req.Id)
+	if status.Code(err) == codes.NotFound {
+		return nil, status.Error(codes.NotFound, "text data not found")
+	}
 	if err != nil {
 		return nil, status.Error(codes.Internal, "failed to delete password")
 	}
@@ -202,31 +294,85 @@ func (s *Server) UpdateTextData(
 	ctx context.Context,
 	req *textDataPb.UpdateTextDataRequest,
 ) (*textDataPb.TextDataItem, error) {
-	if _, ok := ctx.Value("userID").(int); !ok {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
 		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
 	}
-	password, err := s.storage.GetItem(ctx, req.Id)
-	if err != nil || password == nil {
-		return nil, status.Error(codes.Internal, "failed to get password")
-	}
 
-	// 3. Шифруем всю структуру
-	encryptedData, algorithm, iv, err := s.Encryptor.Encrypt([]byte(req.TextData))
+	// Запись проверяется и обновляется в одной транзакции
+	var itemID int64
+	err := s.storage.WithTx(ctx, func(ctx context.Context) error {
+		password, err := s.storage.GetItem(ctx, int64(userID), itemsConstants.TypeText, req.Id)
+		if status.Code(err) == codes.NotFound {
+			return status.Error(codes.NotFound, "text data not found")
+		}
+		if err != nil || password == nil {
+			return status.Error(codes.Internal, "failed to get password")
+		}
+
+		// 3. Шифруем всю структуру, если данные не зашифрованы на клиенте
+		aad := crypto.ItemAAD(int64(userID), req.Id, itemsConstants.TypeText)
+		encryptedData, algorithm, iv, err := s.encryptTextData(ctx, aad, req.TextData, req.EncryptedPayload)
+		if err != nil {
+			return err
+		}
+
+		// 4. Сохраняем в основную таблицу
+		itemID, err = s.storage.UpdateItem(ctx, int64(userID), itemsConstants.TypeText, req.Id, &itemModel.EncryptedItem{
+			Data:                encryptedData,
+			Description:         req.Description,
+			EncryptionAlgorithm: algorithm,
+			Iv:                  iv,
+			KeyVersion:          s.keys.KeyVersion(),
+			AADBound:            true,
+		})
+		if status.Code(err) == codes.NotFound {
+			return status.Error(codes.NotFound, "text data not found")
+		}
+		if err != nil {
+			return status.Error(codes.Internal, "failed to update text data")
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// 4. Сохраняем в основную таблицу
-	itemID, err := s.storage.UpdateItem(ctx, req.Id, &itemModel.EncryptedItem{
-		Data:                encryptedData,
-		Description:         req.Description,
-		EncryptionAlgorithm: algorithm,
-		Iv:                  iv,
-	})
-
 	// 5. Возвращаем ответ
 	return &textDataPb.TextDataItem{
 		Id:          itemID,
 		Description: req.Description,
 	}, nil
 }
+
+// newItemID идентификатор новой записи
+// данные, зашифрованные на клиенте, привязаны к идентификатору, зарезервированному клиентом (ReserveTextDataID)
+func (s *Server) newItemID(ctx context.Context, reservedID int64, encryptedPayload []byte) (int64, error) {
+	if len(encryptedPayload) == 0 {
+		itemID, err := s.storage.ReserveItemID(ctx)
+		if err != nil {
+			return 0, status.Error(codes.Internal, "failed to reserve item id")
+		}
+		return itemID, nil
+	}
+	if reservedID == 0 {
+		return 0, status.Error(codes.InvalidArgument, "reserved item id is required for encrypted payload")
+	}
+	return reservedID, nil
+}
+
+// claimItemID использование идентификатора, зарезервированного клиентом, для данных, зашифрованных на клиенте
+// идентификатор используется один раз, поэтому шифротекст нельзя сохранить в другую запись
+func (s *Server) claimItemID(ctx context.Context, userID int, itemID int64, encryptedPayload []byte) error {
+	if len(encryptedPayload) == 0 {
+		return nil
+	}
+	err := s.storage.ClaimItemID(ctx, int64(userID), itemID)
+	if status.Code(err) == codes.NotFound {
+		return status.Error(codes.InvalidArgument, "item id is not reserved")
+	}
+	if err != nil {
+		return status.Error(codes.Internal, "failed to claim item id")
+	}
+	return nil
+}
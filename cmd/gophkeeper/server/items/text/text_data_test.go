@@ -0,0 +1,78 @@
+package text
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	itemsMock "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/items/mocks"
+	itemsConstants "github.com/ramil063/secondgodiplom/internal/constants/items"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/textdata"
+	cryptoMock "github.com/ramil063/secondgodiplom/internal/security/crypto/mocks"
+)
+
+// runTx выполнение функции транзакции без бд
+func runTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// TestServer_ForeignItem запись другого пользователя или другого типа не находится хранилищем,
+// сервер запрашивает ее от имени пользователя из контекста и отвечает NotFound
+func TestServer_ForeignItem(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	notFound := status.Error(codes.NotFound, "item not found")
+	ctx := context.WithValue(context.Background(), "userID", 2)
+
+	tests := []struct {
+		name   string
+		expect func(storage *itemsMock.MockItemer)
+		call   func(s *Server) error
+	}{
+		{
+			name: "get",
+			expect: func(storage *itemsMock.MockItemer) {
+				storage.EXPECT().GetItem(ctx, int64(2), itemsConstants.TypeText, int64(1)).Return(nil, notFound)
+			},
+			call: func(s *Server) error {
+				_, err := s.GetTextData(ctx, &textdata.GetTextDataRequest{Id: 1})
+				return err
+			},
+		},
+		{
+			name: "update",
+			expect: func(storage *itemsMock.MockItemer) {
+				storage.EXPECT().WithTx(ctx, gomock.Any()).DoAndReturn(runTx)
+				storage.EXPECT().GetItem(ctx, int64(2), itemsConstants.TypeText, int64(1)).Return(nil, notFound)
+			},
+			call: func(s *Server) error {
+				_, err := s.UpdateTextData(ctx, &textdata.UpdateTextDataRequest{Id: 1, Description: "changed"})
+				return err
+			},
+		},
+		{
+			name: "delete",
+			expect: func(storage *itemsMock.MockItemer) {
+				storage.EXPECT().DeleteItem(ctx, int64(2), itemsConstants.TypeText, int64(1)).Return(notFound)
+			},
+			call: func(s *Server) error {
+				_, err := s.DeleteTextData(ctx, &textdata.DeleteTextDataRequest{Id: 1})
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storageMock := itemsMock.NewMockItemer(ctrl)
+			s := NewServer(storageMock, cryptoMock.NewMockKeyResolver(ctrl))
+			tt.expect(storageMock)
+
+			assert.Equal(t, codes.NotFound, status.Code(tt.call(s)))
+		})
+	}
+}
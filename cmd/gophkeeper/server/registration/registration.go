@@ -3,7 +3,11 @@ package registration
 import (
 	"context"
 	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -11,6 +15,13 @@ import (
 	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/user"
 	"github.com/ramil063/secondgodiplom/internal/hash"
 	"github.com/ramil063/secondgodiplom/internal/proto/gen/auth"
+	"github.com/ramil063/secondgodiplom/internal/security/passpolicy"
+)
+
+// Ограничения длины логина, users.login - VARCHAR(64)
+const (
+	minLoginLength = 3
+	maxLoginLength = 64
 )
 
 // RegServer надстройка над стандартным gRPC сервером(регистрация)
@@ -18,19 +29,26 @@ type RegServer struct {
 	auth.UnimplementedRegistrationServiceServer
 
 	storage storage.Registerer
+	policy  passpolicy.Policy
 }
 
 // NewRegistrationServer инициализация сервера регистрации и его хранилища
-func NewRegistrationServer(storage storage.Registerer) *RegServer {
+// policy - требования к паролю новых пользователей
+func NewRegistrationServer(storage storage.Registerer, policy passpolicy.Policy) *RegServer {
 	return &RegServer{
 		storage: storage,
+		policy:  policy,
 	}
 }
 
 // Register зарегистрировать пользователя
 // сохранение основных данных о пользователе
 // применяется хеширование пароля
+// логин и пароль, не соответствующие требованиям, отклоняются с InvalidArgument и списком нарушений в google.rpc.BadRequest
 func (s *RegServer) Register(ctx context.Context, req *auth.RegisterRequest) (*auth.RegisterResponse, error) {
+	if err := s.validate(req); err != nil {
+		return nil, err
+	}
 	// 1. Хеширование пароля
 	hashedPassword, err := hash.GetPasswordHash(req.Password)
 	if err != nil {
@@ -42,6 +60,9 @@ func (s *RegServer) Register(ctx context.Context, req *auth.RegisterRequest) (*a
 		PasswordHash: hashedPassword,
 		FirstName:    req.FirstName,
 		LastName:     req.LastName,
+
+		KdfSalt:         req.KdfSalt,
+		WrappedVaultKey: req.WrappedVaultKey,
 	})
 
 	if err != nil {
@@ -52,3 +73,40 @@ func (s *RegServer) Register(ctx context.Context, req *auth.RegisterRequest) (*a
 		UserId: strconv.Itoa(userID),
 	}, nil
 }
+
+// validate проверка логина и пароля, все нарушения возвращаются одной ошибкой
+func (s *RegServer) validate(req *auth.RegisterRequest) error {
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, description := range validateLogin(req.Login) {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: "login", Description: description})
+	}
+	for _, description := range s.policy.Check(req.Login, req.Password, req.FirstName, req.LastName) {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: "password", Description: description})
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+
+	st := status.New(codes.InvalidArgument, "registration data does not meet the requirements")
+	detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// validateLogin проверка логина: длина и отсутствие пробельных и управляющих символов
+func validateLogin(login string) []string {
+	if login == "" {
+		return []string{"login is required"}
+	}
+
+	var violations []string
+	if length := utf8.RuneCountInString(login); length < minLoginLength || length > maxLoginLength {
+		violations = append(violations, "login must be between 3 and 64 characters long")
+	}
+	if strings.IndexFunc(login, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) }) >= 0 {
+		violations = append(violations, "login must not contain spaces or control characters")
+	}
+	return violations
+}
@@ -0,0 +1,103 @@
+package registration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	storageMock "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/mocks"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/user"
+	"github.com/ramil063/secondgodiplom/internal/hash"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/auth"
+	"github.com/ramil063/secondgodiplom/internal/security/passpolicy"
+)
+
+func TestRegServer_Register(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := storageMock.NewMockRegisterer(ctrl)
+	s := NewRegistrationServer(mockStorage, passpolicy.DefaultPolicy())
+	ctx := context.Background()
+
+	mockStorage.EXPECT().
+		RegisterUser(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, u *user.User) (int, error) {
+			assert.Equal(t, "alice", u.Login)
+			assert.True(t, hash.CheckPasswordHash("correct horse battery staple", u.PasswordHash))
+			return 5, nil
+		})
+	got, err := s.Register(ctx, &auth.RegisterRequest{Login: "alice", Password: "correct horse battery staple"})
+	require.NoError(t, err)
+	assert.Equal(t, "5", got.UserId)
+
+	mockStorage.EXPECT().RegisterUser(ctx, gomock.Any()).Return(0, errors.New("connection refused"))
+	_, err = s.Register(ctx, &auth.RegisterRequest{Login: "bob", Password: "correct horse battery staple"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestRegServer_Register_Violations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// пользователь с нарушениями не сохраняется
+	s := NewRegistrationServer(storageMock.NewMockRegisterer(ctrl), passpolicy.DefaultPolicy())
+
+	tests := []struct {
+		name string
+		req  *auth.RegisterRequest
+		want map[string]int
+	}{
+		{
+			name: "empty login and password",
+			req:  &auth.RegisterRequest{},
+			want: map[string]int{"login": 1, "password": 1},
+		},
+		{
+			name: "login with spaces",
+			req:  &auth.RegisterRequest{Login: "alice smith", Password: "correct horse battery staple"},
+			want: map[string]int{"login": 1},
+		},
+		{
+			name: "common password",
+			req:  &auth.RegisterRequest{Login: "alice", Password: "qwerty"},
+			want: map[string]int{"password": 3},
+		},
+		{
+			name: "password with login",
+			req:  &auth.RegisterRequest{Login: "alice", Password: "kx7alicefpq2mzw"},
+			want: map[string]int{"password": 1},
+		},
+		{
+			name: "password from user name",
+			req:  &auth.RegisterRequest{Login: "alice", Password: "Wonderland", FirstName: "wonderland"},
+			want: map[string]int{"password": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Register(context.Background(), tt.req)
+			assert.Nil(t, got)
+			st, ok := status.FromError(err)
+			require.True(t, ok)
+			assert.Equal(t, codes.InvalidArgument, st.Code())
+
+			require.Len(t, st.Details(), 1)
+			badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+			require.True(t, ok)
+			fields := make(map[string]int)
+			for _, violation := range badRequest.FieldViolations {
+				assert.NotEmpty(t, violation.Description)
+				fields[violation.Field]++
+			}
+			assert.Equal(t, tt.want, fields)
+		})
+	}
+}
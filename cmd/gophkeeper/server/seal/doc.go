@@ -0,0 +1,5 @@
+// Package seal запечатанный режим сервера
+// - до распечатывания мастер-ключа нет ни в памяти, ни в конфигурации
+// - операторы передают доли ключа по схеме Шамира через Unseal
+// - при достижении порога ключ восстанавливается, проверяется по контрольному значению и загружается в связку ключей
+package seal
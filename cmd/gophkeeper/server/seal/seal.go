@@ -0,0 +1,64 @@
+package seal
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/ramil063/secondgodiplom/internal/logger"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/seal"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/shamir"
+)
+
+// Server надстройка над стандартным gRPC сервером(распечатывание)
+type Server struct {
+	seal.UnimplementedSealServiceServer
+
+	sealer *Sealer
+}
+
+// NewServer инициализация сервера распечатывания
+func NewServer(sealer *Sealer) *Server {
+	return &Server{
+		sealer: sealer,
+	}
+}
+
+// Unseal прием доли мастер-ключа от оператора
+func (s *Server) Unseal(ctx context.Context, req *seal.UnsealRequest) (*seal.SealStatus, error) {
+	current, err := s.sealer.SubmitShare(req.Share)
+	switch {
+	case err == nil:
+		if !current.Sealed {
+			logger.WriteInfoLog("server unsealed")
+		}
+		return toProto(current), nil
+	case errors.Is(err, ErrNotSealed):
+		return nil, status.Error(codes.FailedPrecondition, "server is not sealed")
+	case errors.Is(err, shamir.ErrDuplicateShare):
+		return nil, status.Error(codes.AlreadyExists, "share already submitted")
+	case errors.Is(err, shamir.ErrInvalidShare):
+		return nil, status.Error(codes.InvalidArgument, "invalid share")
+	case errors.Is(err, ErrKeyMismatch):
+		return nil, status.Error(codes.InvalidArgument, "shares do not match the master key, unseal progress reset")
+	default:
+		logger.WriteErrorLog(err.Error())
+		return nil, status.Error(codes.Internal, "failed to unseal")
+	}
+}
+
+// Status текущее состояние печати
+func (s *Server) Status(ctx context.Context, _ *emptypb.Empty) (*seal.SealStatus, error) {
+	return toProto(s.sealer.Status()), nil
+}
+
+func toProto(current Status) *seal.SealStatus {
+	return &seal.SealStatus{
+		Sealed:    current.Sealed,
+		Threshold: int32(current.Threshold),
+		Progress:  int32(current.Progress),
+	}
+}
@@ -0,0 +1,83 @@
+package seal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/seal"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/shamir"
+)
+
+func TestServer_Unseal(t *testing.T) {
+	key := []byte("12345678901234567890123456789012")
+	shares, err := shamir.Split(key, 3, 2)
+	require.NoError(t, err)
+	otherShares, err := shamir.Split([]byte("other key other key other key 12"), 3, 2)
+	require.NoError(t, err)
+
+	server := NewServer(NewSealer(2, KeyCheck(key), func(k []byte) error { return nil }))
+
+	tests := []struct {
+		name     string
+		share    []byte
+		want     *seal.SealStatus
+		wantCode codes.Code
+	}{
+		{
+			name:  "first share",
+			share: shares[0],
+			want:  &seal.SealStatus{Sealed: true, Threshold: 2, Progress: 1},
+		},
+		{
+			name:     "duplicate share",
+			share:    shares[0],
+			wantCode: codes.AlreadyExists,
+		},
+		{
+			name:     "invalid share",
+			share:    []byte{1},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "share of another key",
+			share:    otherShares[1],
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:  "first share again",
+			share: shares[0],
+			want:  &seal.SealStatus{Sealed: true, Threshold: 2, Progress: 1},
+		},
+		{
+			name:  "threshold reached",
+			share: shares[2],
+			want:  &seal.SealStatus{Sealed: false, Threshold: 2},
+		},
+		{
+			name:     "already unsealed",
+			share:    shares[1],
+			wantCode: codes.FailedPrecondition,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := server.Unseal(context.Background(), &seal.UnsealRequest{Share: tt.share})
+			if tt.wantCode != codes.OK {
+				assert.Equal(t, tt.wantCode, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	got, err := server.Status(context.Background(), &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.False(t, got.Sealed)
+}
@@ -0,0 +1,131 @@
+package seal
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/shamir"
+)
+
+// keyCheckLabel метка, от которой считается контрольное значение мастер-ключа
+const keyCheckLabel = "gophkeeper/unseal-check/v1"
+
+var (
+	// ErrNotSealed сервер уже распечатан
+	ErrNotSealed = errors.New("server is not sealed")
+	// ErrKeyMismatch доли собрали ключ, не совпадающий с контрольным значением
+	ErrKeyMismatch = errors.New("reconstructed key does not match key check")
+)
+
+// KeyCheck контрольное значение мастер-ключа
+// позволяет проверить собранный из долей ключ, не раскрывая сам ключ
+func KeyCheck(key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(keyCheckLabel))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Status состояние печати
+type Status struct {
+	Sealed    bool
+	Threshold int
+	Progress  int
+}
+
+// Sealer накопление долей мастер-ключа до порога
+type Sealer struct {
+	mu        sync.RWMutex
+	sealed    bool
+	threshold int
+	keyCheck  string
+	shares    [][]byte
+	unseal    func(key []byte) error
+}
+
+// NewSealer инициализация запечатанного состояния
+// unseal вызывается с восстановленным ключом, после успешного вызова сервер считается распечатанным
+func NewSealer(threshold int, keyCheck string, unseal func(key []byte) error) *Sealer {
+	return &Sealer{
+		sealed:    true,
+		threshold: threshold,
+		keyCheck:  keyCheck,
+		unseal:    unseal,
+	}
+}
+
+// Sealed признак запечатанного состояния
+func (s *Sealer) Sealed() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sealed
+}
+
+// Status текущее состояние печати
+func (s *Sealer) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.status()
+}
+
+func (s *Sealer) status() Status {
+	return Status{
+		Sealed:    s.sealed,
+		Threshold: s.threshold,
+		Progress:  len(s.shares),
+	}
+}
+
+// SubmitShare прием доли мастер-ключа
+// при достижении порога ключ восстанавливается и загружается,
+// если ключ не совпал с контрольным значением, накопленные доли сбрасываются
+func (s *Sealer) SubmitShare(share []byte) (Status, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.sealed {
+		return s.status(), ErrNotSealed
+	}
+	if len(share) < 2 {
+		return s.status(), shamir.ErrInvalidShare
+	}
+	for _, submitted := range s.shares {
+		if len(submitted) != len(share) {
+			return s.status(), shamir.ErrInvalidShare
+		}
+		if submitted[len(submitted)-1] == share[len(share)-1] {
+			return s.status(), shamir.ErrDuplicateShare
+		}
+	}
+
+	s.shares = append(s.shares, append([]byte(nil), share...))
+	if len(s.shares) < s.threshold {
+		return s.status(), nil
+	}
+
+	key, err := shamir.Combine(s.shares)
+	s.reset()
+	if err != nil {
+		return s.status(), err
+	}
+
+	if !hmac.Equal([]byte(KeyCheck(key)), []byte(s.keyCheck)) {
+		return s.status(), ErrKeyMismatch
+	}
+	if err = s.unseal(key); err != nil {
+		return s.status(), fmt.Errorf("failed to load master key: %w", err)
+	}
+	s.sealed = false
+	return s.status(), nil
+}
+
+// reset сброс накопленных долей
+func (s *Sealer) reset() {
+	for _, share := range s.shares {
+		clear(share)
+	}
+	s.shares = nil
+}
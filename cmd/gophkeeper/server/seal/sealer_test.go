@@ -0,0 +1,89 @@
+package seal
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/shamir"
+)
+
+func TestSealer_SubmitShare(t *testing.T) {
+	key := []byte("12345678901234567890123456789012")
+	shares, err := shamir.Split(key, 5, 3)
+	require.NoError(t, err)
+
+	var unsealedKey []byte
+	sealer := NewSealer(3, KeyCheck(key), func(k []byte) error {
+		unsealedKey = k
+		return nil
+	})
+	assert.True(t, sealer.Sealed())
+
+	status, err := sealer.SubmitShare(shares[0])
+	require.NoError(t, err)
+	assert.Equal(t, Status{Sealed: true, Threshold: 3, Progress: 1}, status)
+
+	_, err = sealer.SubmitShare(shares[0])
+	assert.ErrorIs(t, err, shamir.ErrDuplicateShare)
+
+	_, err = sealer.SubmitShare(shares[1][1:])
+	assert.ErrorIs(t, err, shamir.ErrInvalidShare)
+
+	status, err = sealer.SubmitShare(shares[3])
+	require.NoError(t, err)
+	assert.Equal(t, 2, status.Progress)
+
+	status, err = sealer.SubmitShare(shares[4])
+	require.NoError(t, err)
+	assert.Equal(t, Status{Sealed: false, Threshold: 3}, status)
+	assert.False(t, sealer.Sealed())
+	assert.Equal(t, key, unsealedKey)
+
+	_, err = sealer.SubmitShare(shares[2])
+	assert.ErrorIs(t, err, ErrNotSealed)
+}
+
+func TestSealer_SubmitShare_KeyMismatch(t *testing.T) {
+	shares, err := shamir.Split([]byte("12345678901234567890123456789012"), 3, 2)
+	require.NoError(t, err)
+	otherShares, err := shamir.Split([]byte("other key other key other key 12"), 3, 2)
+	require.NoError(t, err)
+
+	unsealCalls := 0
+	sealer := NewSealer(2, KeyCheck([]byte("12345678901234567890123456789012")), func(k []byte) error {
+		unsealCalls++
+		return nil
+	})
+
+	_, err = sealer.SubmitShare(shares[0])
+	require.NoError(t, err)
+	status, err := sealer.SubmitShare(otherShares[1])
+	assert.ErrorIs(t, err, ErrKeyMismatch)
+	assert.Equal(t, Status{Sealed: true, Threshold: 2}, status, "доли сбрасываются после неудачной попытки")
+	assert.Zero(t, unsealCalls)
+
+	_, err = sealer.SubmitShare(shares[0])
+	require.NoError(t, err)
+	_, err = sealer.SubmitShare(shares[1])
+	require.NoError(t, err)
+	assert.False(t, sealer.Sealed())
+	assert.Equal(t, 1, unsealCalls)
+}
+
+func TestSealer_SubmitShare_UnsealError(t *testing.T) {
+	key := []byte("12345678901234567890123456789012")
+	shares, err := shamir.Split(key, 2, 2)
+	require.NoError(t, err)
+
+	sealer := NewSealer(2, KeyCheck(key), func(k []byte) error {
+		return errors.New("invalid key size")
+	})
+	_, err = sealer.SubmitShare(shares[0])
+	require.NoError(t, err)
+	_, err = sealer.SubmitShare(shares[1])
+	assert.Error(t, err)
+	assert.True(t, sealer.Sealed())
+}
@@ -1,34 +1,47 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net"
+	"time"
 
 	"google.golang.org/grpc"
 
 	serverConfig "github.com/ramil063/secondgodiplom/cmd/gophkeeper/config"
 	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/interceptors"
+	accountServer "github.com/ramil063/secondgodiplom/cmd/gophkeeper/server/account"
+	auditServer "github.com/ramil063/secondgodiplom/cmd/gophkeeper/server/audit"
 	authServer "github.com/ramil063/secondgodiplom/cmd/gophkeeper/server/auth"
 	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/server/items/bankcard"
 	binaryItemServer "github.com/ramil063/secondgodiplom/cmd/gophkeeper/server/items/binary"
 	passwordServer "github.com/ramil063/secondgodiplom/cmd/gophkeeper/server/items/password"
 	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/server/items/text"
 	regServer "github.com/ramil063/secondgodiplom/cmd/gophkeeper/server/registration"
+	sealServer "github.com/ramil063/secondgodiplom/cmd/gophkeeper/server/seal"
+	tokenServer "github.com/ramil063/secondgodiplom/cmd/gophkeeper/server/token"
 	localStorage "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage"
 	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/items"
 	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/items/binary"
+	"github.com/ramil063/secondgodiplom/internal/hash"
 	"github.com/ramil063/secondgodiplom/internal/logger"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/account"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/audit"
 	"github.com/ramil063/secondgodiplom/internal/proto/gen/auth"
 	itemsBankcard "github.com/ramil063/secondgodiplom/internal/proto/gen/items/bankcard"
 	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/binarydata"
 	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/password"
 	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/textdata"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/seal"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/token"
 	"github.com/ramil063/secondgodiplom/internal/security/crypto"
-	"github.com/ramil063/secondgodiplom/internal/storage/db"
-	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
+	"github.com/ramil063/secondgodiplom/internal/security/jwt"
 )
 
+// keyProviderTimeout ограничение времени получения мастер-ключей при старте
+const keyProviderTimeout = 30 * time.Second
+
 // PrepareServerEnvironment подготавливает окружение для работы сервера
 func PrepareServerEnvironment() (*serverConfig.ServerConfig, localStorage.Storager, *crypto.Manager, error) {
 	config, err := serverConfig.GetConfig()
@@ -39,43 +52,134 @@ func PrepareServerEnvironment() (*serverConfig.ServerConfig, localStorage.Storag
 		return nil, nil, nil, fmt.Errorf("error in getting config")
 	}
 
-	var grpcStorage = localStorage.NewDBStorage()
+	grpcStorage, err := localStorage.NewDBStorage(context.Background(), config)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, nil, nil, err
+	}
 
-	if config.DatabaseURI != "" {
-		rep, errRepo := repository.NewRepository(config)
-		if errRepo != nil {
-			logger.WriteErrorLog(errRepo.Error())
-			return nil, nil, nil, errRepo
-		}
+	manager, err := prepareCryptoManager(config)
+	if err != nil {
+		logger.WriteErrorLog(err.Error())
+		grpcStorage.Close()
+		return nil, nil, nil, err
+	}
 
-		grpcStorage.SetRepository(rep)
-		err = db.Init(*rep)
-		if err != nil {
-			log.Println(err.Error())
-			logger.WriteErrorLog("Init DB: " + err.Error())
-			return nil, nil, nil, err
+	// новые хеши паролей считаются с параметрами из конфигурации
+	hash.SetArgon2Params(config.Argon2Params())
+	return config, grpcStorage, manager, nil
+}
+
+// prepareCryptoManager загрузка связки мастер-ключей
+// новые данные шифруются текущим ключом, ключи предыдущих версий нужны до окончания ротации
+// ключи получаются из настроенных источников (файл, переменная окружения, хранилище ключа, Vault)
+func prepareCryptoManager(config *serverConfig.ServerConfig) (*crypto.Manager, error) {
+	manager := crypto.NewCryptoManager()
+	provider, err := config.MasterKeyProvider()
+	if err != nil {
+		return nil, err
+	}
+	if provider == nil && !config.Seal.Enabled {
+		return manager, nil
+	}
+
+	if config.CryptoAlgorithm != "" {
+		if err = manager.SetAlgorithm(config.CryptoAlgorithm); err != nil {
+			return nil, err
 		}
 	}
 
-	manager := crypto.NewCryptoManager()
-	if config.CryptoKey != "" {
-		decryptor, err := crypto.NewAes256gcmDecryptor([]byte(config.CryptoKey))
+	ctx, cancel := context.WithTimeout(context.Background(), keyProviderTimeout)
+	defer cancel()
+
+	for _, previousKey := range config.PreviousCryptoKeys {
+		previousProvider, err := previousKey.KeyProvider()
 		if err != nil {
-			logger.WriteErrorLog(err.Error())
+			return nil, err
+		}
+		if err = manager.AddMasterKeyFromProvider(ctx, previousKey.Version, previousProvider); err != nil {
+			return nil, err
 		}
-		manager.SetGRPCDecryptor(decryptor)
+	}
+	// в запечатанном режиме текущий ключ собирается из долей через Unseal
+	if config.Seal.Enabled {
+		return manager, nil
+	}
+	if err = manager.AddMasterKeyFromProvider(ctx, config.CryptoKeyVersion, provider); err != nil {
+		return nil, err
+	}
+	if err = manager.UseMasterKey(config.CryptoKeyVersion); err != nil {
+		return nil, err
+	}
+	return manager, nil
+}
+
+// PrepareJWTKeys загрузка ключей подписи токенов доступа
+// без ключа в конфигурации генерируется временный ключ: после перезапуска сервера клиенты обновляют токены через Refresh
+func PrepareJWTKeys(config *serverConfig.ServerConfig) (*jwt.KeySet, error) {
+	provider, err := config.JWTKeyProvider()
+	if err != nil {
+		return nil, err
+	}
+	if provider == nil {
+		logger.WriteInfoLog("jwt signing key is not configured, using an ephemeral key")
+		return jwt.NewEphemeralKeySet()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), keyProviderTimeout)
+	defer cancel()
 
-		encryptor, err := crypto.NewAes256gcmEncryptor([]byte(config.CryptoKey))
+	seed, err := provider.GetKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get jwt signing key: %w", err)
+	}
+	signingKey, err := jwt.ParsePrivateKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := jwt.NewKeySet(config.JWTSigning.KeyID, signingKey)
+	if err != nil {
+		return nil, err
+	}
+	for _, previousKey := range config.JWTSigning.PreviousKeys {
+		publicKey, err := jwt.ParsePublicKey(previousKey.PublicKey)
 		if err != nil {
-			logger.WriteErrorLog(err.Error())
+			return nil, err
+		}
+		if err = keys.AddVerificationKey(previousKey.KeyID, publicKey); err != nil {
+			return nil, err
 		}
-		manager.SetGRPCEncryptor(encryptor)
 	}
-	return config, grpcStorage, manager, nil
+	return keys, nil
+}
+
+// PrepareSealer подготовка запечатанного режима, nil если сервер запускается распечатанным
+// восстановленный из долей ключ становится текущим мастер-ключом
+func PrepareSealer(config *serverConfig.ServerConfig, manager *crypto.Manager) *sealServer.Sealer {
+	if !config.Seal.Enabled {
+		return nil
+	}
+	return sealServer.NewSealer(config.Seal.Threshold, config.Seal.KeyCheck, func(key []byte) error {
+		if err := manager.AddMasterKey(config.CryptoKeyVersion, key); err != nil {
+			return err
+		}
+		return manager.UseMasterKey(config.CryptoKeyVersion)
+	})
 }
 
 // GetGRPCServer возвращает настроенный и запущенный gRPC сервер
-func GetGRPCServer(config *serverConfig.ServerConfig) (*grpc.Server, net.Listener, error) {
+// в запечатанном режиме до распечатывания все вызовы, кроме сервиса распечатывания, отклоняются
+// tokens - кеш проверки отзыва токенов, общий для интерсептора и сервера авторизации
+// keys - ключи проверки подписи токенов доступа
+// personal - хранилище персональных токенов доступа
+func GetGRPCServer(
+	config *serverConfig.ServerConfig,
+	sealer *sealServer.Sealer,
+	tokens *interceptors.TokenCache,
+	keys *jwt.KeySet,
+	personal interceptors.PersonalTokenResolver,
+	recorder interceptors.AuditRecorder,
+) (*grpc.Server, net.Listener, error) {
 	var err error
 
 	lis, err := net.Listen("tcp", config.Address)
@@ -84,36 +188,90 @@ func GetGRPCServer(config *serverConfig.ServerConfig) (*grpc.Server, net.Listene
 		return nil, nil, err
 	}
 
-	authInterceptor := interceptors.NewAuthInterceptors(config.Secret)
+	authInterceptor := interceptors.NewAuthInterceptors(keys, tokens, personal)
+	// журнал аудита пишется после авторизации, когда пользователь вызова уже известен
+	auditInterceptor := interceptors.NewAuditInterceptors(recorder)
+	unaryInterceptors := []grpc.UnaryServerInterceptor{authInterceptor.Unary, auditInterceptor.Unary}
+	streamInterceptors := []grpc.StreamServerInterceptor{authInterceptor.Stream, auditInterceptor.Stream}
+
+	if sealer != nil {
+		sealInterceptor := interceptors.NewSealInterceptors(sealer)
+		unaryInterceptors = append([]grpc.UnaryServerInterceptor{sealInterceptor.Unary}, unaryInterceptors...)
+		streamInterceptors = append([]grpc.StreamServerInterceptor{sealInterceptor.Stream}, streamInterceptors...)
+	}
 
 	grpcServer := grpc.NewServer(
-		grpc.UnaryInterceptor(authInterceptor.Unary),
-		grpc.StreamInterceptor(authInterceptor.Stream),
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
 	)
 	return grpcServer, lis, nil
 }
 
+// NewTokenCache кеш проверки отзыва токенов авторизации по базе данных
+func NewTokenCache(storage localStorage.Storager) *interceptors.TokenCache {
+	return interceptors.NewTokenCache(storage.Auth(), interceptors.DefaultTokenCacheTTL)
+}
+
+// NewPersonalTokenStorage хранилище персональных токенов доступа для интерсептора авторизации
+func NewPersonalTokenStorage(storage localStorage.Storager) localStorage.PersonalTokenManager {
+	return storage.PersonalTokens()
+}
+
+// NewAuditStorage хранилище журнала аудита для интерсептора записи событий
+func NewAuditStorage(storage localStorage.Storager) localStorage.AuditLogger {
+	return storage.Audit()
+}
+
+// StartAccountPurge запуск фонового удаления учетных записей, у которых истек срок восстановления
+// останавливается при отмене контекста
+func StartAccountPurge(ctx context.Context, storage localStorage.Storager) {
+	accountStorage := storage.Account()
+	go accountServer.RunPurge(ctx, accountStorage, accountServer.DefaultPurgeInterval)
+}
+
 // RegisterServiceServers регистрация сервисов в сервере
 func RegisterServiceServers(
 	grpcServer *grpc.Server,
 	storage localStorage.Storager,
 	config *serverConfig.ServerConfig,
 	manager *crypto.Manager,
+	sealer *sealServer.Sealer,
+	tokens *interceptors.TokenCache,
+	keys *jwt.KeySet,
 ) {
-	regStorage := localStorage.NewRegistrationStorage(storage.GetRepository())
-	authStorage := localStorage.NewAuthStorage(storage.GetRepository())
-	newStorage := items.NewStorage(storage.GetRepository())
-	newBinaryStorage := binary.NewStorage(storage.GetRepository())
-
-	passServer := passwordServer.NewServer(newStorage, manager.GetGRPCEncryptor(), manager.GetGRPCDecryptor())
-	textDataServer := text.NewServer(newStorage, manager.GetGRPCEncryptor(), manager.GetGRPCDecryptor())
-	bankcardServer := bankcard.NewServer(newStorage, manager.GetGRPCEncryptor(), manager.GetGRPCDecryptor())
-	binaryServer := binaryItemServer.NewServer(newBinaryStorage, manager.GetGRPCEncryptor(), manager.GetGRPCDecryptor(), config)
-
-	auth.RegisterRegistrationServiceServer(grpcServer, regServer.NewRegistrationServer(regStorage))
-	auth.RegisterAuthServiceServer(grpcServer, authServer.NewAuthServer(authStorage, config.Secret))
+	regStorage := storage.Registration()
+	authStorage := storage.Auth()
+	accountStorage := storage.Account()
+	personalTokenStorage := storage.PersonalTokens()
+	auditStorage := storage.Audit()
+	// Описание, метаданные и имена файлов шифруются ключом пользователя так же, как сами данные
+	newStorage := items.NewEncryptedStorage(storage.Items(), manager)
+	newBinaryStorage := binary.NewEncryptedStorage(storage.Files(), manager)
+
+	// Данные каждого пользователя шифруются его ключом, обернутым мастер-ключом
+	manager.SetKeyStore(storage.DataKeys())
+
+	passServer := passwordServer.NewServer(newStorage, manager)
+	textDataServer := text.NewServer(newStorage, manager)
+	bankcardServer := bankcard.NewServer(newStorage, manager)
+	binaryServer := binaryItemServer.NewServer(newBinaryStorage, manager, config)
+
+	auth.RegisterRegistrationServiceServer(grpcServer, regServer.NewRegistrationServer(regStorage, config.PasswordPolicy()))
+	// Вход по паролю и восстановление аккаунта ограничиваются одними счетчиками неудачных попыток
+	guard := authServer.NewLoginGuard(authStorage, config.LoginPolicy())
+
+	auth.RegisterAuthServiceServer(grpcServer, authServer.NewAuthServer(authStorage, keys, tokens, manager, guard))
+	account.RegisterAccountServiceServer(
+		grpcServer,
+		accountServer.NewServer(accountStorage, tokens, guard, config.DeletionGracePeriod()))
+	token.RegisterTokenServiceServer(grpcServer, tokenServer.NewServer(personalTokenStorage))
+	audit.RegisterAuditServiceServer(grpcServer, auditServer.NewServer(auditStorage))
 	password.RegisterServiceServer(grpcServer, passServer)
 	textdata.RegisterServiceServer(grpcServer, textDataServer)
 	itemsBankcard.RegisterServiceServer(grpcServer, bankcardServer)
 	binarydata.RegisterServiceServer(grpcServer, binaryServer)
+
+	if sealer != nil {
+		seal.RegisterSealServiceServer(grpcServer, sealServer.NewServer(sealer))
+	}
 }
@@ -0,0 +1,2 @@
+// Package token логика выдачи и отзыва персональных токенов доступа на сервере
+package token
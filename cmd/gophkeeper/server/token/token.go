@@ -0,0 +1,157 @@
+package token
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage"
+	modelAuth "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/token"
+	"github.com/ramil063/secondgodiplom/internal/security/jwt"
+	"github.com/ramil063/secondgodiplom/internal/security/scope"
+)
+
+// maxNameLength максимальная длина названия токена
+const maxNameLength = 255
+
+// Server надстройка над стандартным gRPC сервером(персональные токены)
+type Server struct {
+	token.UnimplementedTokenServiceServer
+
+	storage storage.PersonalTokenManager
+	now     func() time.Time
+}
+
+// NewServer инициализация сервера персональных токенов
+func NewServer(storage storage.PersonalTokenManager) *Server {
+	return &Server{
+		storage: storage,
+		now:     time.Now,
+	}
+}
+
+// CreatePersonalToken выпуск персонального токена с ограниченными правами
+// значение токена возвращается один раз, в базе хранится только его хеш
+func (s *Server) CreatePersonalToken(
+	ctx context.Context,
+	req *token.CreatePersonalTokenRequest,
+) (*token.CreatePersonalTokenResponse, error) {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, status.Error(codes.InvalidArgument, "token name is required")
+	}
+	if len(name) > maxNameLength {
+		return nil, status.Error(codes.InvalidArgument, "token name is too long")
+	}
+	if err := scope.Validate(req.Scopes); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	personal := &modelAuth.PersonalToken{
+		UserID: userID,
+		Name:   name,
+		Scopes: req.Scopes,
+	}
+	if req.ExpiresAt != "" {
+		expiresAt, err := time.Parse(time.RFC3339, req.ExpiresAt)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "expires_at must be in RFC 3339 format")
+		}
+		if !expiresAt.After(s.now()) {
+			return nil, status.Error(codes.InvalidArgument, "expires_at must be in the future")
+		}
+		expiresAt = expiresAt.UTC()
+		personal.ExpiresAt = &expiresAt
+	}
+
+	value, err := jwt.GenerateRefreshToken()
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to generate token")
+	}
+	value = modelAuth.PersonalTokenPrefix + value
+
+	_, err = s.storage.SavePersonalToken(ctx, personal, value)
+	if err != nil {
+		if status.Code(err) == codes.ResourceExhausted {
+			return nil, err
+		}
+		return nil, status.Error(codes.Internal, "failed to save token")
+	}
+
+	return &token.CreatePersonalTokenResponse{
+		Token:         value,
+		PersonalToken: s.personalToken(personal),
+	}, nil
+}
+
+// ListPersonalTokens список неотозванных персональных токенов пользователя
+func (s *Server) ListPersonalTokens(
+	ctx context.Context,
+	_ *token.ListPersonalTokensRequest,
+) (*token.ListPersonalTokensResponse, error) {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
+	}
+
+	tokens, err := s.storage.ListPersonalTokens(ctx, userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get tokens")
+	}
+
+	result := make([]*token.PersonalToken, 0, len(tokens))
+	for i := range tokens {
+		result = append(result, s.personalToken(&tokens[i]))
+	}
+	return &token.ListPersonalTokensResponse{Tokens: result}, nil
+}
+
+// RevokePersonalToken отзыв персонального токена пользователя
+func (s *Server) RevokePersonalToken(
+	ctx context.Context,
+	req *token.RevokePersonalTokenRequest,
+) (*token.RevokePersonalTokenResponse, error) {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid authentication")
+	}
+	if req.Id <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "token id is required")
+	}
+
+	err := s.storage.RevokePersonalToken(ctx, userID, int(req.Id))
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, err
+		}
+		return nil, status.Error(codes.Internal, "failed to revoke token")
+	}
+	return &token.RevokePersonalTokenResponse{Success: true}, nil
+}
+
+// personalToken описание токена для ответа
+func (s *Server) personalToken(personal *modelAuth.PersonalToken) *token.PersonalToken {
+	result := &token.PersonalToken{
+		Id:        int64(personal.ID),
+		Name:      personal.Name,
+		Scopes:    personal.Scopes,
+		CreatedAt: personal.CreatedAt.String(),
+	}
+	if personal.ExpiresAt != nil {
+		result.ExpiresAt = personal.ExpiresAt.String()
+		result.Expired = !personal.ExpiresAt.After(s.now())
+	}
+	if personal.LastUsedAt != nil {
+		result.LastUsedAt = personal.LastUsedAt.String()
+	}
+	return result
+}
@@ -0,0 +1,203 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	storageMock "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/mocks"
+	modelAuth "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/token"
+)
+
+func TestServer_CreatePersonalToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	ctx := context.WithValue(context.Background(), "userID", 1)
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		req      *token.CreatePersonalTokenRequest
+		saveErr  error
+		save     bool
+		wantCode codes.Code
+	}{
+		{
+			name: "success",
+			ctx:  ctx,
+			req: &token.CreatePersonalTokenRequest{
+				Name:      " backup ",
+				Scopes:    []string{"passwords:read", "item:5:read"},
+				ExpiresAt: "2027-01-01T00:00:00Z",
+			},
+			save:     true,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "without expiration",
+			ctx:      ctx,
+			req:      &token.CreatePersonalTokenRequest{Name: "ci", Scopes: []string{"files:write"}},
+			save:     true,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "unauthenticated",
+			ctx:      context.Background(),
+			req:      &token.CreatePersonalTokenRequest{Name: "ci", Scopes: []string{"files:write"}},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "empty name",
+			ctx:      ctx,
+			req:      &token.CreatePersonalTokenRequest{Name: " ", Scopes: []string{"files:write"}},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "no scopes",
+			ctx:      ctx,
+			req:      &token.CreatePersonalTokenRequest{Name: "ci"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "unknown scope",
+			ctx:      ctx,
+			req:      &token.CreatePersonalTokenRequest{Name: "ci", Scopes: []string{"account:write"}},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "expiration in the past",
+			ctx:  ctx,
+			req: &token.CreatePersonalTokenRequest{
+				Name:      "ci",
+				Scopes:    []string{"files:write"},
+				ExpiresAt: "2026-10-01T00:00:00Z",
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "invalid expiration",
+			ctx:  ctx,
+			req: &token.CreatePersonalTokenRequest{
+				Name:      "ci",
+				Scopes:    []string{"files:write"},
+				ExpiresAt: "tomorrow",
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "too many tokens",
+			ctx:      ctx,
+			req:      &token.CreatePersonalTokenRequest{Name: "ci", Scopes: []string{"files:write"}},
+			save:     true,
+			saveErr:  status.Error(codes.ResourceExhausted, "too many personal tokens"),
+			wantCode: codes.ResourceExhausted,
+		},
+		{
+			name:     "storage error",
+			ctx:      ctx,
+			req:      &token.CreatePersonalTokenRequest{Name: "ci", Scopes: []string{"files:write"}},
+			save:     true,
+			saveErr:  errors.New("connection refused"),
+			wantCode: codes.Internal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storageMock.NewMockPersonalTokenManager(ctrl)
+			s := NewServer(mockStorage)
+			s.now = func() time.Time { return now }
+
+			var saved string
+			if tt.save {
+				mockStorage.EXPECT().
+					SavePersonalToken(tt.ctx, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, personal *modelAuth.PersonalToken, value string) (int, error) {
+						assert.Equal(t, 1, personal.UserID)
+						assert.Equal(t, strings.TrimSpace(tt.req.Name), personal.Name)
+						assert.Equal(t, tt.req.Scopes, personal.Scopes)
+						saved = value
+						personal.ID = 7
+						personal.CreatedAt = now
+						return personal.ID, tt.saveErr
+					})
+			}
+
+			got, err := s.CreatePersonalToken(tt.ctx, tt.req)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode != codes.OK {
+				assert.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			assert.True(t, strings.HasPrefix(got.Token, modelAuth.PersonalTokenPrefix))
+			assert.Equal(t, saved, got.Token)
+			assert.Equal(t, int64(7), got.PersonalToken.Id)
+			assert.Equal(t, tt.req.Scopes, got.PersonalToken.Scopes)
+			assert.Equal(t, tt.req.ExpiresAt == "", got.PersonalToken.ExpiresAt == "")
+		})
+	}
+}
+
+func TestServer_ListPersonalTokens(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := storageMock.NewMockPersonalTokenManager(ctrl)
+	s := NewServer(mockStorage)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	ctx := context.WithValue(context.Background(), "userID", 1)
+
+	expired := now.Add(-time.Hour)
+	lastUsedAt := now.Add(-2 * time.Hour)
+	mockStorage.EXPECT().ListPersonalTokens(ctx, 1).Return([]modelAuth.PersonalToken{
+		{ID: 8, UserID: 1, Name: "ci", Scopes: []string{"files:write"}, CreatedAt: now},
+		{ID: 7, UserID: 1, Name: "backup", Scopes: []string{"passwords:read"}, ExpiresAt: &expired, LastUsedAt: &lastUsedAt, CreatedAt: now},
+	}, nil)
+
+	got, err := s.ListPersonalTokens(ctx, &token.ListPersonalTokensRequest{})
+	require.NoError(t, err)
+	require.Len(t, got.Tokens, 2)
+	assert.Equal(t, int64(8), got.Tokens[0].Id)
+	assert.Empty(t, got.Tokens[0].ExpiresAt)
+	assert.False(t, got.Tokens[0].Expired)
+	assert.Equal(t, expired.String(), got.Tokens[1].ExpiresAt)
+	assert.Equal(t, lastUsedAt.String(), got.Tokens[1].LastUsedAt)
+	assert.True(t, got.Tokens[1].Expired)
+
+	mockStorage.EXPECT().ListPersonalTokens(ctx, 1).Return(nil, errors.New("connection refused"))
+	_, err = s.ListPersonalTokens(ctx, &token.ListPersonalTokensRequest{})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestServer_RevokePersonalToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := storageMock.NewMockPersonalTokenManager(ctrl)
+	s := NewServer(mockStorage)
+	ctx := context.WithValue(context.Background(), "userID", 1)
+
+	mockStorage.EXPECT().RevokePersonalToken(ctx, 1, 7).Return(nil)
+	got, err := s.RevokePersonalToken(ctx, &token.RevokePersonalTokenRequest{Id: 7})
+	assert.NoError(t, err)
+	assert.True(t, got.Success)
+
+	mockStorage.EXPECT().RevokePersonalToken(ctx, 1, 8).Return(status.Error(codes.NotFound, "token not found"))
+	_, err = s.RevokePersonalToken(ctx, &token.RevokePersonalTokenRequest{Id: 8})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = s.RevokePersonalToken(ctx, &token.RevokePersonalTokenRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
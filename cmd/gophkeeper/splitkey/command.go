@@ -0,0 +1,87 @@
+package splitkey
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/server/seal"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/keyprovider"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/shamir"
+)
+
+const (
+	// CommandName название подкоманды сервера
+	CommandName = "split-key"
+	// generatedKeySize размер нового мастер-ключа
+	generatedKeySize = 32
+)
+
+// options параметры подкоманды
+type options struct {
+	shares    int
+	threshold int
+	generate  bool
+}
+
+func parseOptions(args []string, out io.Writer) (*options, error) {
+	var opts options
+
+	flags := flag.NewFlagSet(CommandName, flag.ContinueOnError)
+	flags.SetOutput(out)
+	flags.IntVar(&opts.shares, "shares", 5, "число долей")
+	flags.IntVar(&opts.threshold, "threshold", 3, "сколько долей нужно для восстановления ключа")
+	flags.BoolVar(&opts.generate, "generate", false, "сгенерировать новый мастер-ключ вместо чтения из стандартного ввода")
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	return &opts, nil
+}
+
+// readKey чтение ключа из входного потока, перевод строки в конце отбрасывается
+func readKey(in io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	key := bytes.TrimSpace(data)
+	if len(key) == 0 {
+		return nil, keyprovider.ErrEmptyKey
+	}
+	return key, nil
+}
+
+// Run разделение мастер-ключа на доли
+// каждая доля передается своему оператору, сам сгенерированный ключ нигде не сохраняется
+func Run(args []string, in io.Reader, out io.Writer) error {
+	opts, err := parseOptions(args, out)
+	if err != nil {
+		return err
+	}
+
+	var key []byte
+	if opts.generate {
+		key = make([]byte, generatedKeySize)
+		if _, err = rand.Read(key); err != nil {
+			return fmt.Errorf("failed to generate key: %w", err)
+		}
+	} else if key, err = readKey(in); err != nil {
+		return err
+	}
+
+	shares, err := shamir.Split(key, opts.shares, opts.threshold)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Ключ разделен на %d долей, для распечатывания нужно %d\n", opts.shares, opts.threshold)
+	for i, share := range shares {
+		fmt.Fprintf(out, "Доля %d: %s\n", i+1, base64.StdEncoding.EncodeToString(share))
+	}
+	fmt.Fprintf(out, "key_check: %s\n", seal.KeyCheck(key))
+	return nil
+}
@@ -0,0 +1,88 @@
+package splitkey
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/server/seal"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/shamir"
+)
+
+// parseOutput доли и контрольное значение из вывода подкоманды
+func parseOutput(t *testing.T, output string) ([][]byte, string) {
+	t.Helper()
+	var shares [][]byte
+	var keyCheck string
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "Доля "):
+			share, err := base64.StdEncoding.DecodeString(line[strings.Index(line, ": ")+2:])
+			require.NoError(t, err)
+			shares = append(shares, share)
+		case strings.HasPrefix(line, "key_check: "):
+			keyCheck = strings.TrimPrefix(line, "key_check: ")
+		}
+	}
+	return shares, keyCheck
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		in         string
+		wantShares int
+		wantKey    []byte
+		wantErr    bool
+	}{
+		{
+			name:       "key from stdin",
+			args:       []string{"-shares", "4", "-threshold", "2"},
+			in:         "123456789012345678901234\n",
+			wantShares: 4,
+			wantKey:    []byte("123456789012345678901234"),
+		},
+		{
+			name:       "generated key",
+			args:       []string{"-generate"},
+			wantShares: 5,
+		},
+		{
+			name:    "empty key",
+			in:      "\n",
+			wantErr: true,
+		},
+		{
+			name:    "threshold greater than shares",
+			args:    []string{"-shares", "2", "-threshold", "3"},
+			in:      "123456789012345678901234\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			err := Run(tt.args, strings.NewReader(tt.in), out)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			shares, keyCheck := parseOutput(t, out.String())
+			require.Len(t, shares, tt.wantShares)
+
+			key, err := shamir.Combine(shares)
+			require.NoError(t, err)
+			assert.Equal(t, seal.KeyCheck(key), keyCheck)
+			if tt.wantKey != nil {
+				assert.Equal(t, tt.wantKey, key)
+			}
+		})
+	}
+}
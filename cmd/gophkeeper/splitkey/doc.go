@@ -0,0 +1,4 @@
+// Package splitkey разделение мастер-ключа на доли для запечатанного режима сервера
+// - ключ читается из стандартного ввода или генерируется заново
+// - печатаются доли и контрольное значение ключа для конфигурации сервера
+package splitkey
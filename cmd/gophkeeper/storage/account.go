@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"context"
+
+	accountModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/account"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/user"
+	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/account"
+	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
+)
+
+// AccountManager интерфейс описывающий управление учетной записью пользователя
+type AccountManager interface {
+	GetAccount(ctx context.Context, userID int) (*user.User, error)
+	ChangePassword(ctx context.Context, change *accountModel.PasswordChange) (int64, error)
+	GetAccountByLogin(ctx context.Context, login string) (*user.User, error)
+	DeleteAccount(ctx context.Context, userID int) error
+	DeactivateAccount(ctx context.Context, deactivation *accountModel.Deactivation) error
+	RestoreAccount(ctx context.Context, userID int, event accountModel.SecurityEvent) error
+	PurgeDeletedAccounts(ctx context.Context) (int, error)
+}
+
+// NewAccountStorage инициализация структуры для управления учетной записью
+// в структуре есть указатель на репозиторий
+func NewAccountStorage(rep repository.Repository) AccountManager {
+	return &account.Account{
+		Repository: &rep,
+	}
+}
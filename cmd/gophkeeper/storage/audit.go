@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"context"
+
+	auditModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/audit"
+	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/audit"
+	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
+)
+
+// AuditRecorder интерфейс описывающий запись событий в журнал аудита
+type AuditRecorder interface {
+	SaveAuditEvent(ctx context.Context, event *auditModel.Event) error
+}
+
+// AuditLogger интерфейс описывающий работу с журналом аудита
+type AuditLogger interface {
+	AuditRecorder
+	ListAuditEvents(ctx context.Context, filter *auditModel.Filter) ([]auditModel.Event, error)
+}
+
+// NewAuditStorage инициализация хранилища журнала аудита
+// в структуре есть указатель на репозиторий
+func NewAuditStorage(rep repository.Repository) AuditLogger {
+	return &audit.Audit{
+		Repository: &rep,
+	}
+}
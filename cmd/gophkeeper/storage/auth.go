@@ -2,7 +2,9 @@ package storage
 
 import (
 	"context"
+	"time"
 
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/account"
 	authModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
 	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/user"
 	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/auth"
@@ -12,6 +14,12 @@ import (
 // UserGetter интерфейс описывающий работу с получением пользователя
 type UserGetter interface {
 	GetUserByLogin(ctx context.Context, login string) (*user.User, error)
+	GetUserByID(ctx context.Context, userID int) (*user.User, error)
+}
+
+// PasswordRehasher интерфейс описывающий замену хеша пароля, полученного устаревшим алгоритмом
+type PasswordRehasher interface {
+	RehashPassword(ctx context.Context, userID int, oldHash, newHash string) error
 }
 
 // Loginer интерфейс описывающий работу с авторизацией пользователя
@@ -21,8 +29,8 @@ type Loginer interface {
 
 // TokenSaver интерфейс описывающий работу с сохранением токенов
 type TokenSaver interface {
-	SaveAccessToken(ctx context.Context, userID int, token string) (int, error)
-	SaveRefreshToken(ctx context.Context, accessTokenId int, token string) error
+	SaveAccessToken(ctx context.Context, userID int, token string, session authModel.SessionInfo) (int, error)
+	SaveRefreshToken(ctx context.Context, accessTokenId int, token string, familyID string) error
 }
 
 // TokenGetter интерфейс описывающий работу с получением токена
@@ -33,14 +41,65 @@ type TokenGetter interface {
 // TokenRevoker интерфейс описывающий работу с отзыванием токена
 type TokenRevoker interface {
 	RevokeRefreshToken(ctx context.Context, refreshToken string) error
+	RevokeAccessToken(ctx context.Context, userID int, accessToken string) error
+	RevokeUserTokens(ctx context.Context, userID int) (int64, error)
+	RevokeTokenFamily(ctx context.Context, familyID string, event account.SecurityEvent) (int64, error)
+}
+
+// TokenChecker интерфейс описывающий проверку, что токен авторизации не отозван
+type TokenChecker interface {
+	IsAccessTokenActive(ctx context.Context, accessToken string) (bool, error)
+}
+
+// SessionManager интерфейс описывающий работу с сессиями пользователя на устройствах
+type SessionManager interface {
+	ListSessions(ctx context.Context, userID int, currentAccessToken string) ([]authModel.Session, error)
+	// RevokeSession возвращает хеш отозванного токена авторизации
+	RevokeSession(ctx context.Context, userID int, sessionID int) (string, error)
+}
+
+// TOTPStorer интерфейс описывающий работу со вторым фактором авторизации
+type TOTPStorer interface {
+	SaveTOTP(ctx context.Context, userID int, totp *authModel.TOTP, recoveryCodes []string) error
+	GetTOTP(ctx context.Context, userID int) (*authModel.TOTP, error)
+	ConfirmTOTP(ctx context.Context, userID int, step int64) error
+	UseTOTPStep(ctx context.Context, userID int, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userID int, code string) (bool, error)
+}
+
+// LoginChallenger интерфейс описывающий работу со входами, ожидающими второй фактор
+type LoginChallenger interface {
+	SaveLoginChallenge(ctx context.Context, userID int, challenge string) error
+	UseLoginChallengeAttempt(ctx context.Context, challenge string) (int, error)
+	CompleteLoginChallenge(ctx context.Context, challenge string) error
+}
+
+// LoginThrottler интерфейс описывающий учет неудачных попыток входа для защиты от подбора пароля
+type LoginThrottler interface {
+	GetLoginAttempts(ctx context.Context, subjects []string) ([]authModel.LoginAttempts, error)
+	ReserveLoginAttempt(ctx context.Context, subject string, maxFailures int, lockout time.Duration) (bool, error)
+	ReleaseLoginAttempt(ctx context.Context, subject string) error
+	ResetLoginFailures(ctx context.Context, subject string) error
+}
+
+// ClientKeySaver интерфейс описывающий сохранение параметров сквозного шифрования пользователя
+type ClientKeySaver interface {
+	SaveClientKeys(ctx context.Context, userID int, kdfSalt, wrappedVaultKey []byte) error
 }
 
 // Authenticator интерфейс описывающий полный спектр работ по авторизации
 type Authenticator interface {
 	UserGetter
+	PasswordRehasher
 	TokenSaver
 	TokenGetter
 	TokenRevoker
+	TokenChecker
+	SessionManager
+	TOTPStorer
+	LoginChallenger
+	LoginThrottler
+	ClientKeySaver
 }
 
 // NewAuthStorage инициализация структуры для работы авторизации
@@ -0,0 +1,15 @@
+package storage
+
+import (
+	"github.com/ramil063/secondgodiplom/internal/security/crypto"
+	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/datakey"
+	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
+)
+
+// NewDataKeyStorage инициализация хранилища ключей шифрования данных пользователей
+// в структуре есть указатель на репозиторий
+func NewDataKeyStorage(rep repository.Repository) crypto.KeyStore {
+	return &datakey.DataKey{
+		Repository: &rep,
+	}
+}
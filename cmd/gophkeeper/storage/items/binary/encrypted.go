@@ -0,0 +1,205 @@
+package binary
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/items"
+	"github.com/ramil063/secondgodiplom/internal/logger"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/binarydata"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto"
+)
+
+// fieldOwner вид владельца зашифрованных полей файла (crypto.FieldAAD)
+const fieldOwner = "file"
+
+// Поля файла, которые шифруются вместе с его частями
+const (
+	fieldFilename      = "filename"
+	fieldMimeType      = "mime_type"
+	fieldDescription   = "description"
+	fieldMetadataName  = "metadata.name"
+	fieldMetadataValue = "metadata.value"
+)
+
+// filterBatchSize количество файлов, читаемых из базы за раз при поиске по фильтру
+const filterBatchSize = 100
+
+// encryptedStorage хранилище, шифрующее имя, тип, описание и метаданные файлов ключом пользователя
+// список с фильтром читается пачками по filterBatchSize по возрастанию идентификатора, расшифровывается и фильтруется здесь же
+type encryptedStorage struct {
+	Filer
+	keys crypto.KeyResolver
+}
+
+// NewEncryptedStorage обертка над хранилищем с шифрованием имени, типа, описания и метаданных файлов
+// значения, сохраненные до шифрования полей, читаются как есть
+func NewEncryptedStorage(storage Filer, keys crypto.KeyResolver) Filer {
+	return &encryptedStorage{
+		Filer: storage,
+		keys:  keys,
+	}
+}
+
+// CreateFileRecord создание записи о файле с зашифрованными полями
+// идентификатор файла должен быть зарезервирован заранее (ReserveFileID)
+func (s *encryptedStorage) CreateFileRecord(ctx context.Context, userID int, fileID int64, metadata *binarydata.FileMetadata) (int64, error) {
+	encryptor, err := s.keys.GetEncryptor(ctx)
+	if err != nil {
+		return 0, err
+	}
+	seal := func(field string, value string) (string, error) {
+		aad := crypto.FieldAAD(int64(userID), fieldOwner, fileID, field)
+		return crypto.SealField(encryptor, s.keys.KeyVersion(), value, aad)
+	}
+
+	sealedMetadata := &binarydata.FileMetadata{
+		OriginalSize:    metadata.OriginalSize,
+		ChunkSize:       metadata.ChunkSize,
+		TotalChunks:     metadata.TotalChunks,
+		ClientEncrypted: metadata.ClientEncrypted,
+	}
+	if sealedMetadata.Filename, err = seal(fieldFilename, metadata.Filename); err != nil {
+		return 0, err
+	}
+	if sealedMetadata.MimeType, err = seal(fieldMimeType, metadata.MimeType); err != nil {
+		return 0, err
+	}
+	if sealedMetadata.Description, err = seal(fieldDescription, metadata.Description); err != nil {
+		return 0, err
+	}
+	return s.Filer.CreateFileRecord(ctx, userID, fileID, sealedMetadata)
+}
+
+// GetFileInfo данные файла с расшифрованными полями
+func (s *encryptedStorage) GetFileInfo(ctx context.Context, fileID int64, userID int64) (*items.FileInfo, error) {
+	fileInfo, err := s.Filer.GetFileInfo(ctx, fileID, userID)
+	if err != nil || fileInfo == nil {
+		return fileInfo, err
+	}
+	if err = s.openFileInfo(ctx, userID, fileInfo); err != nil {
+		return nil, err
+	}
+	return fileInfo, nil
+}
+
+// GetListFiles список файлов с расшифрованными полями
+// фильтр ищет подстроку без учета регистра в имени, типе и описании файла
+func (s *encryptedStorage) GetListFiles(
+	ctx context.Context,
+	userID int64,
+	page int32,
+	perPage int32,
+	filter string,
+) ([]*items.FileInfo, int32, error) {
+	if filter == "" {
+		list, totalCount, err := s.Filer.GetListFiles(ctx, userID, page, perPage, "")
+		if err != nil {
+			return nil, 0, err
+		}
+		// Файлы, которые не удалось расшифровать, не показываются и не учитываются в общем количестве
+		opened := s.openFiles(ctx, userID, list)
+		return opened, totalCount - int32(len(list)-len(opened)), nil
+	}
+
+	filter = strings.ToLower(filter)
+	var found []*items.FileInfo
+	for afterID := int64(0); ; {
+		list, err := s.Filer.GetListFilesAfter(ctx, userID, afterID, filterBatchSize)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, fileInfo := range s.openFiles(ctx, userID, list) {
+			if fileMatches(fileInfo, filter) {
+				found = append(found, fileInfo)
+			}
+		}
+		if len(list) < filterBatchSize {
+			break
+		}
+		afterID = list[len(list)-1].ID
+	}
+
+	// Пачки читаются по возрастанию идентификатора, страницы отдаются как без фильтра - сначала новые
+	sort.SliceStable(found, func(i, j int) bool {
+		if !found[i].CreatedAt.Equal(found[j].CreatedAt) {
+			return found[i].CreatedAt.After(found[j].CreatedAt)
+		}
+		return found[i].ID > found[j].ID
+	})
+	return paginate(found, page, perPage), int32(len(found)), nil
+}
+
+// openFiles расшифровка списка файлов
+// файл, который не удалось расшифровать, пропускается, чтобы не терять весь список
+func (s *encryptedStorage) openFiles(ctx context.Context, userID int64, list []*items.FileInfo) []*items.FileInfo {
+	opened := make([]*items.FileInfo, 0, len(list))
+	for _, fileInfo := range list {
+		if err := s.openFileInfo(ctx, userID, fileInfo); err != nil {
+			logger.WriteErrorLog(fmt.Sprintf("failed to decrypt file %d: %v", fileInfo.ID, err))
+			continue
+		}
+		opened = append(opened, fileInfo)
+	}
+	return opened
+}
+
+func (s *encryptedStorage) openFileInfo(ctx context.Context, userID int64, fileInfo *items.FileInfo) error {
+	open := func(field string, value *string) error {
+		opened, err := crypto.OpenField(ctx, s.keys, *value, crypto.FieldAAD(userID, fieldOwner, fileInfo.ID, field))
+		if err != nil {
+			return err
+		}
+		*value = opened
+		return nil
+	}
+
+	if err := open(fieldFilename, &fileInfo.Filename); err != nil {
+		return err
+	}
+	if err := open(fieldMimeType, &fileInfo.MimeType); err != nil {
+		return err
+	}
+	if err := open(fieldDescription, &fileInfo.Description); err != nil {
+		return err
+	}
+	for _, m := range fileInfo.MetaDataItems {
+		if err := open(fieldMetadataName, &m.Name); err != nil {
+			return err
+		}
+		if err := open(fieldMetadataValue, &m.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileMatches файл содержит подстроку filter (в нижнем регистре)
+func fileMatches(fileInfo *items.FileInfo, filter string) bool {
+	for _, value := range []string{fileInfo.Filename, fileInfo.MimeType, fileInfo.Description} {
+		if strings.Contains(strings.ToLower(value), filter) {
+			return true
+		}
+	}
+	for _, m := range fileInfo.MetaDataItems {
+		if strings.Contains(strings.ToLower(m.Name), filter) || strings.Contains(strings.ToLower(m.Value), filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// paginate страница page по perPage файлов
+func paginate(list []*items.FileInfo, page int32, perPage int32) []*items.FileInfo {
+	if page < 1 || perPage < 1 {
+		return list
+	}
+	start := int64(page-1) * int64(perPage)
+	if start >= int64(len(list)) {
+		return nil
+	}
+	end := min(start+int64(perPage), int64(len(list)))
+	return list[start:end]
+}
@@ -0,0 +1,114 @@
+package binary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	binaryMock "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/items/binary/mocks"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/items"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/binarydata"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto"
+	cryptoMock "github.com/ramil063/secondgodiplom/internal/security/crypto/mocks"
+)
+
+var testKey = []byte("12345678901234567890123456789012")
+
+// newTestKeys ключи пользователя на постоянном тестовом ключе
+func newTestKeys(t *testing.T, ctrl *gomock.Controller) *cryptoMock.MockKeyResolver {
+	encryptor, err := crypto.NewEncryptor(testKey, crypto.DefaultAlgorithm)
+	require.NoError(t, err)
+	decryptor, err := crypto.NewDecryptor(testKey)
+	require.NoError(t, err)
+
+	keys := cryptoMock.NewMockKeyResolver(ctrl)
+	keys.EXPECT().GetEncryptor(gomock.Any()).Return(encryptor, nil).AnyTimes()
+	keys.EXPECT().GetDecryptor(gomock.Any(), 1).Return(decryptor, nil).AnyTimes()
+	keys.EXPECT().KeyVersion().Return(1).AnyTimes()
+	return keys
+}
+
+func TestEncryptedStorage_CreateAndGetFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	inner := binaryMock.NewMockFiler(ctrl)
+	s := NewEncryptedStorage(inner, newTestKeys(t, ctrl))
+	ctx := context.WithValue(context.Background(), "userID", 1)
+
+	var saved *binarydata.FileMetadata
+	inner.EXPECT().CreateFileRecord(ctx, 1, int64(5), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ int, fileID int64, metadata *binarydata.FileMetadata) (int64, error) {
+			saved = metadata
+			return fileID, nil
+		})
+
+	metadata := &binarydata.FileMetadata{
+		Filename:     "паспорт.pdf",
+		MimeType:     "application/pdf",
+		Description:  "скан паспорта",
+		OriginalSize: 100,
+		ChunkSize:    50,
+		TotalChunks:  2,
+	}
+	fileID, err := s.CreateFileRecord(ctx, 1, 5, metadata)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), fileID)
+
+	// в хранилище попадает только шифротекст, размеры сохраняются как есть
+	assert.True(t, crypto.IsSealedField(saved.Filename))
+	assert.True(t, crypto.IsSealedField(saved.MimeType))
+	assert.True(t, crypto.IsSealedField(saved.Description))
+	assert.NotContains(t, saved.Filename, "паспорт")
+	assert.Equal(t, int32(2), saved.TotalChunks)
+	assert.Equal(t, "паспорт.pdf", metadata.Filename)
+
+	inner.EXPECT().GetFileInfo(ctx, int64(5), int64(1)).Return(&items.FileInfo{
+		ID:          5,
+		Filename:    saved.Filename,
+		MimeType:    saved.MimeType,
+		Description: saved.Description,
+	}, nil)
+
+	got, err := s.GetFileInfo(ctx, 5, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "паспорт.pdf", got.Filename)
+	assert.Equal(t, "application/pdf", got.MimeType)
+	assert.Equal(t, "скан паспорта", got.Description)
+}
+
+func TestEncryptedStorage_GetListFiles_Filter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	keys := newTestKeys(t, ctrl)
+	encryptor, err := keys.GetEncryptor(context.Background())
+	require.NoError(t, err)
+	seal := func(fileID int64, field string, value string) string {
+		sealed, err := crypto.SealField(encryptor, 1, value, crypto.FieldAAD(1, fieldOwner, fileID, field))
+		require.NoError(t, err)
+		return sealed
+	}
+
+	inner := binaryMock.NewMockFiler(ctrl)
+	s := NewEncryptedStorage(inner, keys)
+	ctx := context.WithValue(context.Background(), "userID", 1)
+
+	// файл, который не расшифровывается, пропускается, остальные возвращаются
+	inner.EXPECT().GetListFilesAfter(ctx, int64(1), int64(0), int32(filterBatchSize)).Return([]*items.FileInfo{
+		{ID: 4, Filename: seal(5, fieldFilename, "чужой.pdf")},
+		{ID: 3, Filename: seal(3, fieldFilename, "отпуск.jpg"), MimeType: seal(3, fieldMimeType, "image/jpeg")},
+		{ID: 2, Filename: seal(2, fieldFilename, "договор.PDF"), MimeType: seal(2, fieldMimeType, "application/pdf")},
+		{ID: 1, Filename: "старый.pdf"},
+	}, nil)
+
+	got, total, err := s.GetListFiles(ctx, 1, 1, 10, "pdf")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), total)
+	require.Len(t, got, 2)
+	assert.Equal(t, "договор.PDF", got[0].Filename)
+	assert.Equal(t, "старый.pdf", got[1].Filename)
+}
@@ -10,14 +10,20 @@ import (
 )
 
 // Filer интерфейс для работы с АПИ сервера связанной с файлами
+// методы, вызванные с контекстом из WithTx, выполняются в одной транзакции
 type Filer interface {
-	CreateFileRecord(ctx context.Context, userID int, metadata *binarydata.FileMetadata) (int64, error)
-	SaveChunk(ctx context.Context, fileID int64, chunkIndex int32, encryptedData []byte, algorithm string, iv []byte) error
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+	ReserveFileID(ctx context.Context) (int64, error)
+	ReserveClientFileID(ctx context.Context, userID int64) (int64, error)
+	ClaimFileID(ctx context.Context, userID int64, fileID int64) error
+	CreateFileRecord(ctx context.Context, userID int, fileID int64, metadata *binarydata.FileMetadata) (int64, error)
+	SaveChunk(ctx context.Context, fileID int64, chunkIndex int32, encryptedData []byte, algorithm string, iv []byte, keyVersion int, aadBound bool, streamBound bool) error
 	MarkFileComplete(ctx context.Context, fileID int64, totalBytes int64) error
 	GetFileInfo(ctx context.Context, fileID int64, userID int64) (*items.FileInfo, error)
 	GetChunksInRange(ctx context.Context, fileID int64, start, end int32) ([]*items.ChunkData, error)
 	DeleteFile(ctx context.Context, userID, fileID int64) error
 	GetListFiles(ctx context.Context, userID int64, page int32, perPage int32, filter string) ([]*items.FileInfo, int32, error)
+	GetListFilesAfter(ctx context.Context, userID int64, afterID int64, limit int32) ([]*items.FileInfo, error)
 	GetTotalCount(ctx context.Context, query string, userID int64, filter string) (int32, error)
 }
 
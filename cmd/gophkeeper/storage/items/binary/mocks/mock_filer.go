@@ -36,19 +36,33 @@ func (m *MockFiler) EXPECT() *MockFilerMockRecorder {
 	return m.recorder
 }
 
+// ClaimFileID mocks base method.
+func (m *MockFiler) ClaimFileID(arg0 context.Context, arg1, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimFileID", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClaimFileID indicates an expected call of ClaimFileID.
+func (mr *MockFilerMockRecorder) ClaimFileID(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimFileID", reflect.TypeOf((*MockFiler)(nil).ClaimFileID), arg0, arg1, arg2)
+}
+
 // CreateFileRecord mocks base method.
-func (m *MockFiler) CreateFileRecord(arg0 context.Context, arg1 int, arg2 *binarydata.FileMetadata) (int64, error) {
+func (m *MockFiler) CreateFileRecord(arg0 context.Context, arg1 int, arg2 int64, arg3 *binarydata.FileMetadata) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateFileRecord", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "CreateFileRecord", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateFileRecord indicates an expected call of CreateFileRecord.
-func (mr *MockFilerMockRecorder) CreateFileRecord(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockFilerMockRecorder) CreateFileRecord(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFileRecord", reflect.TypeOf((*MockFiler)(nil).CreateFileRecord), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFileRecord", reflect.TypeOf((*MockFiler)(nil).CreateFileRecord), arg0, arg1, arg2, arg3)
 }
 
 // DeleteFile mocks base method.
@@ -111,6 +125,21 @@ func (mr *MockFilerMockRecorder) GetListFiles(arg0, arg1, arg2, arg3, arg4 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetListFiles", reflect.TypeOf((*MockFiler)(nil).GetListFiles), arg0, arg1, arg2, arg3, arg4)
 }
 
+// GetListFilesAfter mocks base method.
+func (m *MockFiler) GetListFilesAfter(arg0 context.Context, arg1, arg2 int64, arg3 int32) ([]*items.FileInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetListFilesAfter", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*items.FileInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetListFilesAfter indicates an expected call of GetListFilesAfter.
+func (mr *MockFilerMockRecorder) GetListFilesAfter(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetListFilesAfter", reflect.TypeOf((*MockFiler)(nil).GetListFilesAfter), arg0, arg1, arg2, arg3)
+}
+
 // GetTotalCount mocks base method.
 func (m *MockFiler) GetTotalCount(arg0 context.Context, arg1 string, arg2 int64, arg3 string) (int32, error) {
 	m.ctrl.T.Helper()
@@ -140,16 +169,60 @@ func (mr *MockFilerMockRecorder) MarkFileComplete(arg0, arg1, arg2 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkFileComplete", reflect.TypeOf((*MockFiler)(nil).MarkFileComplete), arg0, arg1, arg2)
 }
 
+// ReserveClientFileID mocks base method.
+func (m *MockFiler) ReserveClientFileID(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveClientFileID", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveClientFileID indicates an expected call of ReserveClientFileID.
+func (mr *MockFilerMockRecorder) ReserveClientFileID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveClientFileID", reflect.TypeOf((*MockFiler)(nil).ReserveClientFileID), arg0, arg1)
+}
+
+// ReserveFileID mocks base method.
+func (m *MockFiler) ReserveFileID(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveFileID", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveFileID indicates an expected call of ReserveFileID.
+func (mr *MockFilerMockRecorder) ReserveFileID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveFileID", reflect.TypeOf((*MockFiler)(nil).ReserveFileID), arg0)
+}
+
 // SaveChunk mocks base method.
-func (m *MockFiler) SaveChunk(arg0 context.Context, arg1 int64, arg2 int32, arg3 []byte, arg4 string, arg5 []byte) error {
+func (m *MockFiler) SaveChunk(arg0 context.Context, arg1 int64, arg2 int32, arg3 []byte, arg4 string, arg5 []byte, arg6 int, arg7, arg8 bool) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveChunk", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret := m.ctrl.Call(m, "SaveChunk", arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SaveChunk indicates an expected call of SaveChunk.
-func (mr *MockFilerMockRecorder) SaveChunk(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+func (mr *MockFilerMockRecorder) SaveChunk(arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveChunk", reflect.TypeOf((*MockFiler)(nil).SaveChunk), arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8)
+}
+
+// WithTx mocks base method.
+func (m *MockFiler) WithTx(arg0 context.Context, arg1 func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockFilerMockRecorder) WithTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveChunk", reflect.TypeOf((*MockFiler)(nil).SaveChunk), arg0, arg1, arg2, arg3, arg4, arg5)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockFiler)(nil).WithTx), arg0, arg1)
 }
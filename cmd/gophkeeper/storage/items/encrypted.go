@@ -0,0 +1,241 @@
+package items
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	itemModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/items"
+	"github.com/ramil063/secondgodiplom/internal/logger"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto"
+)
+
+// fieldOwner вид владельца зашифрованных полей записи (crypto.FieldAAD)
+const fieldOwner = "item"
+
+// Поля записи, которые шифруются вместе с данными
+const (
+	fieldDescription   = "description"
+	fieldMetadataName  = "metadata.name"
+	fieldMetadataValue = "metadata.value"
+)
+
+// filterBatchSize количество записей, читаемых из базы за раз при поиске по фильтру
+const filterBatchSize = 100
+
+// encryptedStorage хранилище, шифрующее описание и метаданные записей ключом пользователя
+// поиск по зашифрованным полям в базе невозможен, поэтому список с фильтром
+// читается пачками по filterBatchSize по возрастанию идентификатора, расшифровывается и фильтруется здесь же
+type encryptedStorage struct {
+	Itemer
+	keys crypto.KeyResolver
+}
+
+// NewEncryptedStorage обертка над хранилищем с шифрованием описания и метаданных записей
+// значения, сохраненные до шифрования полей, читаются как есть и шифруются при следующем изменении
+func NewEncryptedStorage(storage Itemer, keys crypto.KeyResolver) Itemer {
+	return &encryptedStorage{
+		Itemer: storage,
+		keys:   keys,
+	}
+}
+
+// SaveEncryptedData сохранение записи с зашифрованным описанием
+// идентификатор записи должен быть зарезервирован заранее (ReserveItemID)
+func (s *encryptedStorage) SaveEncryptedData(ctx context.Context, encryptedItem *itemModel.EncryptedItem) (int64, error) {
+	description, err := s.seal(ctx, encryptedItem.UserID, encryptedItem.ID, fieldDescription, encryptedItem.Description)
+	if err != nil {
+		return 0, err
+	}
+	sealedItem := *encryptedItem
+	sealedItem.Description = description
+	return s.Itemer.SaveEncryptedData(ctx, &sealedItem)
+}
+
+// SaveMetadata сохранение зашифрованных метаданных записи
+func (s *encryptedStorage) SaveMetadata(ctx context.Context, userID int64, itemType string, metadata *itemModel.MetaData) error {
+	name, err := s.seal(ctx, userID, metadata.ItemID, fieldMetadataName, metadata.Name)
+	if err != nil {
+		return err
+	}
+	value, err := s.seal(ctx, userID, metadata.ItemID, fieldMetadataValue, metadata.Value)
+	if err != nil {
+		return err
+	}
+	sealedMetadata := *metadata
+	sealedMetadata.Name = name
+	sealedMetadata.Value = value
+	return s.Itemer.SaveMetadata(ctx, userID, itemType, &sealedMetadata)
+}
+
+// GetListItems список записей с расшифрованными описанием и метаданными
+// фильтр ищет подстроку без учета регистра в описании, названиях и значениях метаданных
+func (s *encryptedStorage) GetListItems(
+	ctx context.Context,
+	userID int64,
+	page int32,
+	perPage int32,
+	itemType string,
+	filter string,
+) ([]*itemModel.ItemData, int32, error) {
+	if filter == "" {
+		list, totalCount, err := s.Itemer.GetListItems(ctx, userID, page, perPage, itemType, "")
+		if err != nil {
+			return nil, 0, err
+		}
+		// Записи, которые не удалось расшифровать, не показываются и не учитываются в общем количестве
+		opened := s.openItems(ctx, userID, list)
+		return opened, totalCount - int32(len(list)-len(opened)), nil
+	}
+
+	filter = strings.ToLower(filter)
+	var found []*itemModel.ItemData
+	for afterID := int64(0); ; {
+		list, err := s.Itemer.GetListItemsAfter(ctx, userID, itemType, afterID, filterBatchSize)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, item := range s.openItems(ctx, userID, list) {
+			if itemMatches(item, filter) {
+				found = append(found, item)
+			}
+		}
+		if len(list) < filterBatchSize {
+			break
+		}
+		afterID = list[len(list)-1].ID
+	}
+
+	// Пачки читаются по возрастанию идентификатора, страницы отдаются как без фильтра - сначала новые
+	sort.SliceStable(found, func(i, j int) bool {
+		if !found[i].CreatedAt.Equal(found[j].CreatedAt) {
+			return found[i].CreatedAt.After(found[j].CreatedAt)
+		}
+		return found[i].ID > found[j].ID
+	})
+	return paginate(found, page, perPage), int32(len(found)), nil
+}
+
+// GetItem запись с расшифрованными описанием и метаданными
+func (s *encryptedStorage) GetItem(ctx context.Context, userID int64, itemType string, itemID int64) (*itemModel.ItemData, error) {
+	item, err := s.Itemer.GetItem(ctx, userID, itemType, itemID)
+	if err != nil || item == nil {
+		return item, err
+	}
+	if err = s.openItem(ctx, userID, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// UpdateItem обновление записи с зашифрованным описанием
+func (s *encryptedStorage) UpdateItem(
+	ctx context.Context,
+	userID int64,
+	itemType string,
+	itemID int64,
+	encryptedItem *itemModel.EncryptedItem,
+) (int64, error) {
+	description, err := s.seal(ctx, userID, itemID, fieldDescription, encryptedItem.Description)
+	if err != nil {
+		return 0, err
+	}
+	sealedItem := *encryptedItem
+	sealedItem.Description = description
+	return s.Itemer.UpdateItem(ctx, userID, itemType, itemID, &sealedItem)
+}
+
+// GetMetaDataList расшифрованные метаданные записи
+func (s *encryptedStorage) GetMetaDataList(ctx context.Context, userID int64, itemType string, itemID int64) ([]*itemModel.MetaData, error) {
+	metadata, err := s.Itemer.GetMetaDataList(ctx, userID, itemType, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.openMetadata(ctx, userID, itemID, metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// seal шифрование поля записи ключом пользователя из контекста
+func (s *encryptedStorage) seal(ctx context.Context, userID int64, itemID int64, field string, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	encryptor, err := s.keys.GetEncryptor(ctx)
+	if err != nil {
+		return "", err
+	}
+	return crypto.SealField(encryptor, s.keys.KeyVersion(), value, crypto.FieldAAD(userID, fieldOwner, itemID, field))
+}
+
+// open расшифровка поля записи
+func (s *encryptedStorage) open(ctx context.Context, userID int64, itemID int64, field string, value string) (string, error) {
+	return crypto.OpenField(ctx, s.keys, value, crypto.FieldAAD(userID, fieldOwner, itemID, field))
+}
+
+// openItems расшифровка списка записей
+// запись, которую не удалось расшифровать, пропускается, чтобы не терять весь список
+func (s *encryptedStorage) openItems(ctx context.Context, userID int64, list []*itemModel.ItemData) []*itemModel.ItemData {
+	opened := make([]*itemModel.ItemData, 0, len(list))
+	for _, item := range list {
+		if err := s.openItem(ctx, userID, item); err != nil {
+			logger.WriteErrorLog(fmt.Sprintf("failed to decrypt item %d: %v", item.ID, err))
+			continue
+		}
+		opened = append(opened, item)
+	}
+	return opened
+}
+
+func (s *encryptedStorage) openItem(ctx context.Context, userID int64, item *itemModel.ItemData) error {
+	description, err := s.open(ctx, userID, item.ID, fieldDescription, item.Description)
+	if err != nil {
+		return err
+	}
+	item.Description = description
+	return s.openMetadata(ctx, userID, item.ID, item.MetaDataItems)
+}
+
+func (s *encryptedStorage) openMetadata(ctx context.Context, userID int64, itemID int64, metadata []*itemModel.MetaData) error {
+	for _, m := range metadata {
+		name, err := s.open(ctx, userID, itemID, fieldMetadataName, m.Name)
+		if err != nil {
+			return err
+		}
+		value, err := s.open(ctx, userID, itemID, fieldMetadataValue, m.Value)
+		if err != nil {
+			return err
+		}
+		m.Name = name
+		m.Value = value
+	}
+	return nil
+}
+
+// itemMatches запись содержит подстроку filter (в нижнем регистре)
+func itemMatches(item *itemModel.ItemData, filter string) bool {
+	if strings.Contains(strings.ToLower(item.Description), filter) {
+		return true
+	}
+	for _, m := range item.MetaDataItems {
+		if strings.Contains(strings.ToLower(m.Name), filter) || strings.Contains(strings.ToLower(m.Value), filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// paginate страница page по perPage записей
+func paginate(list []*itemModel.ItemData, page int32, perPage int32) []*itemModel.ItemData {
+	if page < 1 || perPage < 1 {
+		return list
+	}
+	start := int64(page-1) * int64(perPage)
+	if start >= int64(len(list)) {
+		return nil
+	}
+	end := min(start+int64(perPage), int64(len(list)))
+	return list[start:end]
+}
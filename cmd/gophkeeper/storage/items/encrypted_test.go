@@ -0,0 +1,216 @@
+package items
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	itemsMock "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/items/mocks"
+	itemModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/items"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto"
+	cryptoMock "github.com/ramil063/secondgodiplom/internal/security/crypto/mocks"
+)
+
+var testKey = []byte("12345678901234567890123456789012")
+
+// newTestKeys ключи пользователя на постоянном тестовом ключе
+func newTestKeys(t *testing.T, ctrl *gomock.Controller) *cryptoMock.MockKeyResolver {
+	encryptor, err := crypto.NewEncryptor(testKey, crypto.DefaultAlgorithm)
+	require.NoError(t, err)
+	decryptor, err := crypto.NewDecryptor(testKey)
+	require.NoError(t, err)
+
+	keys := cryptoMock.NewMockKeyResolver(ctrl)
+	keys.EXPECT().GetEncryptor(gomock.Any()).Return(encryptor, nil).AnyTimes()
+	keys.EXPECT().GetDecryptor(gomock.Any(), 1).Return(decryptor, nil).AnyTimes()
+	keys.EXPECT().KeyVersion().Return(1).AnyTimes()
+	return keys
+}
+
+func TestEncryptedStorage_SaveAndGet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	inner := itemsMock.NewMockItemer(ctrl)
+	s := NewEncryptedStorage(inner, newTestKeys(t, ctrl))
+	ctx := context.WithValue(context.Background(), "userID", 1)
+
+	var saved *itemModel.EncryptedItem
+	inner.EXPECT().SaveEncryptedData(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, item *itemModel.EncryptedItem) (int64, error) {
+			saved = item
+			return item.ID, nil
+		})
+	var savedMetadata *itemModel.MetaData
+	inner.EXPECT().SaveMetadata(ctx, int64(1), "card", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ int64, _ string, metadata *itemModel.MetaData) error {
+			savedMetadata = metadata
+			return nil
+		})
+
+	item := &itemModel.EncryptedItem{ID: 7, UserID: 1, Type: "card", Description: "Тинькофф зарплатная"}
+	_, err := s.SaveEncryptedData(ctx, item)
+	require.NoError(t, err)
+	err = s.SaveMetadata(ctx, 1, "card", &itemModel.MetaData{ItemID: 7, Name: "код активации", Value: "4711"})
+	require.NoError(t, err)
+
+	// в хранилище попадает только шифротекст, переданная структура не меняется
+	assert.True(t, crypto.IsSealedField(saved.Description))
+	assert.NotContains(t, saved.Description, "Тинькофф")
+	assert.Equal(t, "Тинькофф зарплатная", item.Description)
+	assert.True(t, crypto.IsSealedField(savedMetadata.Name))
+	assert.True(t, crypto.IsSealedField(savedMetadata.Value))
+
+	inner.EXPECT().GetItem(ctx, int64(1), "card", int64(7)).Return(&itemModel.ItemData{
+		ID:            7,
+		Description:   saved.Description,
+		MetaDataItems: []*itemModel.MetaData{{Name: savedMetadata.Name, Value: savedMetadata.Value}},
+	}, nil)
+
+	got, err := s.GetItem(ctx, 1, "card", 7)
+	require.NoError(t, err)
+	assert.Equal(t, "Тинькофф зарплатная", got.Description)
+	assert.Equal(t, "код активации", got.MetaDataItems[0].Name)
+	assert.Equal(t, "4711", got.MetaDataItems[0].Value)
+
+	// описание, перенесенное в другую запись, не расшифровывается
+	inner.EXPECT().GetItem(ctx, int64(1), "card", int64(8)).Return(&itemModel.ItemData{
+		ID:          8,
+		Description: saved.Description,
+	}, nil)
+	_, err = s.GetItem(ctx, 1, "card", 8)
+	assert.Error(t, err)
+}
+
+func TestEncryptedStorage_GetListItems_Filter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	keys := newTestKeys(t, ctrl)
+	encryptor, err := keys.GetEncryptor(context.Background())
+	require.NoError(t, err)
+	seal := func(itemID int64, field string, value string) string {
+		sealed, err := crypto.SealField(encryptor, 1, value, crypto.FieldAAD(1, fieldOwner, itemID, field))
+		require.NoError(t, err)
+		return sealed
+	}
+	list := func() []*itemModel.ItemData {
+		return []*itemModel.ItemData{
+			{ID: 3, Description: seal(3, fieldDescription, "Почта")},
+			{ID: 2, Description: seal(2, fieldDescription, "Сбербанк онлайн")},
+			{
+				ID:          1,
+				Description: "старая запись",
+				MetaDataItems: []*itemModel.MetaData{
+					{Name: seal(1, fieldMetadataName, "банк"), Value: seal(1, fieldMetadataValue, "СберБанк")},
+				},
+			},
+		}
+	}
+
+	inner := itemsMock.NewMockItemer(ctrl)
+	s := NewEncryptedStorage(inner, keys)
+	ctx := context.WithValue(context.Background(), "userID", 1)
+
+	tests := []struct {
+		name      string
+		filter    string
+		page      int32
+		want      []int64
+		wantTotal int32
+	}{
+		{
+			name:      "first page",
+			filter:    "сбер",
+			page:      1,
+			want:      []int64{2},
+			wantTotal: 2,
+		},
+		{
+			name:      "second page",
+			filter:    "СБЕР",
+			page:      2,
+			want:      []int64{1},
+			wantTotal: 2,
+		},
+		{
+			name:      "nothing found",
+			filter:    "альфа",
+			page:      1,
+			wantTotal: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// фильтр в базу не передается, записи читаются пачками
+			inner.EXPECT().GetListItemsAfter(ctx, int64(1), "passwords", int64(0), int32(filterBatchSize)).Return(list(), nil)
+
+			got, total, err := s.GetListItems(ctx, 1, tt.page, 1, "passwords", tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTotal, total)
+			var ids []int64
+			for _, item := range got {
+				ids = append(ids, item.ID)
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+
+	// без фильтра страница запрашивается у базы
+	inner.EXPECT().GetListItems(ctx, int64(1), int32(1), int32(10), "passwords", "").Return(list(), int32(3), nil)
+	got, total, err := s.GetListItems(ctx, 1, 1, 10, "passwords", "")
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), total)
+	assert.Equal(t, "Сбербанк онлайн", got[1].Description)
+	assert.Equal(t, "старая запись", got[2].Description)
+}
+
+func TestEncryptedStorage_GetListItems_Batches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	keys := newTestKeys(t, ctrl)
+	encryptor, err := keys.GetEncryptor(context.Background())
+	require.NoError(t, err)
+	// описание, зашифрованное для другой записи, не расшифровывается
+	broken, err := crypto.SealField(encryptor, 1, "сбер", crypto.FieldAAD(1, fieldOwner, 1000, fieldDescription))
+	require.NoError(t, err)
+
+	firstBatch := make([]*itemModel.ItemData, 0, filterBatchSize)
+	for i := 0; i < filterBatchSize; i++ {
+		firstBatch = append(firstBatch, &itemModel.ItemData{ID: int64(i + 1), Description: fmt.Sprintf("запись %d", i+1)})
+	}
+	firstBatch[0].Description = "Сбербанк"
+	firstBatch[1].Description = broken
+
+	inner := itemsMock.NewMockItemer(ctrl)
+	s := NewEncryptedStorage(inner, keys)
+	ctx := context.WithValue(context.Background(), "userID", 1)
+
+	// следующая пачка начинается после последней записи предыдущей, а не со смещения
+	inner.EXPECT().GetListItemsAfter(ctx, int64(1), "passwords", int64(0), int32(filterBatchSize)).
+		Return(firstBatch, nil)
+	inner.EXPECT().GetListItemsAfter(ctx, int64(1), "passwords", int64(filterBatchSize), int32(filterBatchSize)).
+		Return([]*itemModel.ItemData{{ID: 101, Description: "сбер вклад"}}, nil)
+
+	got, total, err := s.GetListItems(ctx, 1, 1, 10, "passwords", "сбер")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), total)
+	require.Len(t, got, 2)
+	// сначала новые, как без фильтра
+	assert.Equal(t, int64(101), got[0].ID)
+	assert.Equal(t, int64(1), got[1].ID)
+
+	// без фильтра запись, которую не удалось расшифровать, пропускается и не входит в общее количество
+	inner.EXPECT().GetListItems(ctx, int64(1), int32(1), int32(10), "passwords", "").
+		Return([]*itemModel.ItemData{{ID: 2, Description: broken}, {ID: 3, Description: "почта"}}, int32(2), nil)
+	got, total, err = s.GetListItems(ctx, 1, 1, 10, "passwords", "")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), total)
+	require.Len(t, got, 1)
+	assert.Equal(t, int64(3), got[0].ID)
+}
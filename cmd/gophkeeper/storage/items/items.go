@@ -9,14 +9,22 @@ import (
 )
 
 // Itemer интерфейс для работы с АПИ зашифрованных данных на сервере
+// методы с записью ограничены записями пользователя userID с типом itemType,
+// чужая, удаленная или запись другого типа не находится (codes.NotFound)
+// методы, вызванные с контекстом из WithTx, выполняются в одной транзакции
 type Itemer interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+	ReserveItemID(ctx context.Context) (int64, error)
+	ReserveClientItemID(ctx context.Context, userID int64) (int64, error)
+	ClaimItemID(ctx context.Context, userID int64, itemID int64) error
 	SaveEncryptedData(ctx context.Context, encryptedPassword *itemModel.EncryptedItem) (int64, error)
-	SaveMetadata(ctx context.Context, metadata *itemModel.MetaData) error
+	SaveMetadata(ctx context.Context, userID int64, itemType string, metadata *itemModel.MetaData) error
 	GetListItems(ctx context.Context, userID int64, page int32, perPage int32, itemType, filter string) ([]*itemModel.ItemData, int32, error)
-	GetItem(ctx context.Context, passwordID int64) (*itemModel.ItemData, error)
-	DeleteItem(ctx context.Context, passwordID int64) error
-	UpdateItem(ctx context.Context, itemId int64, encryptedPassword *itemModel.EncryptedItem) (int64, error)
-	GetMetaDataList(ctx context.Context, itemId int64) ([]*itemModel.MetaData, error)
+	GetListItemsAfter(ctx context.Context, userID int64, itemType string, afterID int64, limit int32) ([]*itemModel.ItemData, error)
+	GetItem(ctx context.Context, userID int64, itemType string, itemID int64) (*itemModel.ItemData, error)
+	DeleteItem(ctx context.Context, userID int64, itemType string, itemID int64) error
+	UpdateItem(ctx context.Context, userID int64, itemType string, itemID int64, encryptedItem *itemModel.EncryptedItem) (int64, error)
+	GetMetaDataList(ctx context.Context, userID int64, itemType string, itemID int64) ([]*itemModel.MetaData, error)
 }
 
 // NewStorage инициализация хранилища вместе с переданным репозиторием
@@ -35,33 +35,47 @@ func (m *MockItemer) EXPECT() *MockItemerMockRecorder {
 	return m.recorder
 }
 
+// ClaimItemID mocks base method.
+func (m *MockItemer) ClaimItemID(arg0 context.Context, arg1, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimItemID", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClaimItemID indicates an expected call of ClaimItemID.
+func (mr *MockItemerMockRecorder) ClaimItemID(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimItemID", reflect.TypeOf((*MockItemer)(nil).ClaimItemID), arg0, arg1, arg2)
+}
+
 // DeleteItem mocks base method.
-func (m *MockItemer) DeleteItem(arg0 context.Context, arg1 int64) error {
+func (m *MockItemer) DeleteItem(arg0 context.Context, arg1 int64, arg2 string, arg3 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteItem", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteItem", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteItem indicates an expected call of DeleteItem.
-func (mr *MockItemerMockRecorder) DeleteItem(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockItemerMockRecorder) DeleteItem(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteItem", reflect.TypeOf((*MockItemer)(nil).DeleteItem), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteItem", reflect.TypeOf((*MockItemer)(nil).DeleteItem), arg0, arg1, arg2, arg3)
 }
 
 // GetItem mocks base method.
-func (m *MockItemer) GetItem(arg0 context.Context, arg1 int64) (*items.ItemData, error) {
+func (m *MockItemer) GetItem(arg0 context.Context, arg1 int64, arg2 string, arg3 int64) (*items.ItemData, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetItem", arg0, arg1)
+	ret := m.ctrl.Call(m, "GetItem", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(*items.ItemData)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetItem indicates an expected call of GetItem.
-func (mr *MockItemerMockRecorder) GetItem(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockItemerMockRecorder) GetItem(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItem", reflect.TypeOf((*MockItemer)(nil).GetItem), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItem", reflect.TypeOf((*MockItemer)(nil).GetItem), arg0, arg1, arg2, arg3)
 }
 
 // GetListItems mocks base method.
@@ -80,19 +94,64 @@ func (mr *MockItemerMockRecorder) GetListItems(arg0, arg1, arg2, arg3, arg4, arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetListItems", reflect.TypeOf((*MockItemer)(nil).GetListItems), arg0, arg1, arg2, arg3, arg4, arg5)
 }
 
+// GetListItemsAfter mocks base method.
+func (m *MockItemer) GetListItemsAfter(arg0 context.Context, arg1 int64, arg2 string, arg3 int64, arg4 int32) ([]*items.ItemData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetListItemsAfter", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]*items.ItemData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetListItemsAfter indicates an expected call of GetListItemsAfter.
+func (mr *MockItemerMockRecorder) GetListItemsAfter(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetListItemsAfter", reflect.TypeOf((*MockItemer)(nil).GetListItemsAfter), arg0, arg1, arg2, arg3, arg4)
+}
+
 // GetMetaDataList mocks base method.
-func (m *MockItemer) GetMetaDataList(arg0 context.Context, arg1 int64) ([]*items.MetaData, error) {
+func (m *MockItemer) GetMetaDataList(arg0 context.Context, arg1 int64, arg2 string, arg3 int64) ([]*items.MetaData, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetMetaDataList", arg0, arg1)
+	ret := m.ctrl.Call(m, "GetMetaDataList", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].([]*items.MetaData)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetMetaDataList indicates an expected call of GetMetaDataList.
-func (mr *MockItemerMockRecorder) GetMetaDataList(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockItemerMockRecorder) GetMetaDataList(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMetaDataList", reflect.TypeOf((*MockItemer)(nil).GetMetaDataList), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMetaDataList", reflect.TypeOf((*MockItemer)(nil).GetMetaDataList), arg0, arg1, arg2, arg3)
+}
+
+// ReserveClientItemID mocks base method.
+func (m *MockItemer) ReserveClientItemID(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveClientItemID", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveClientItemID indicates an expected call of ReserveClientItemID.
+func (mr *MockItemerMockRecorder) ReserveClientItemID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveClientItemID", reflect.TypeOf((*MockItemer)(nil).ReserveClientItemID), arg0, arg1)
+}
+
+// ReserveItemID mocks base method.
+func (m *MockItemer) ReserveItemID(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveItemID", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveItemID indicates an expected call of ReserveItemID.
+func (mr *MockItemerMockRecorder) ReserveItemID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveItemID", reflect.TypeOf((*MockItemer)(nil).ReserveItemID), arg0)
 }
 
 // SaveEncryptedData mocks base method.
@@ -111,30 +170,44 @@ func (mr *MockItemerMockRecorder) SaveEncryptedData(arg0, arg1 interface{}) *gom
 }
 
 // SaveMetadata mocks base method.
-func (m *MockItemer) SaveMetadata(arg0 context.Context, arg1 *items.MetaData) error {
+func (m *MockItemer) SaveMetadata(arg0 context.Context, arg1 int64, arg2 string, arg3 *items.MetaData) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveMetadata", arg0, arg1)
+	ret := m.ctrl.Call(m, "SaveMetadata", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SaveMetadata indicates an expected call of SaveMetadata.
-func (mr *MockItemerMockRecorder) SaveMetadata(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockItemerMockRecorder) SaveMetadata(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMetadata", reflect.TypeOf((*MockItemer)(nil).SaveMetadata), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMetadata", reflect.TypeOf((*MockItemer)(nil).SaveMetadata), arg0, arg1, arg2, arg3)
 }
 
 // UpdateItem mocks base method.
-func (m *MockItemer) UpdateItem(arg0 context.Context, arg1 int64, arg2 *items.EncryptedItem) (int64, error) {
+func (m *MockItemer) UpdateItem(arg0 context.Context, arg1 int64, arg2 string, arg3 int64, arg4 *items.EncryptedItem) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateItem", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "UpdateItem", arg0, arg1, arg2, arg3, arg4)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UpdateItem indicates an expected call of UpdateItem.
-func (mr *MockItemerMockRecorder) UpdateItem(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockItemerMockRecorder) UpdateItem(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateItem", reflect.TypeOf((*MockItemer)(nil).UpdateItem), arg0, arg1, arg2, arg3, arg4)
+}
+
+// WithTx mocks base method.
+func (m *MockItemer) WithTx(arg0 context.Context, arg1 func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockItemerMockRecorder) WithTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateItem", reflect.TypeOf((*MockItemer)(nil).UpdateItem), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockItemer)(nil).WithTx), arg0, arg1)
 }
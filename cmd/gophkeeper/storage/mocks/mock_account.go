@@ -0,0 +1,139 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage (interfaces: AccountManager)
+
+// Package storage is a generated GoMock package.
+package storage
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	account "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/account"
+	user "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/user"
+)
+
+// MockAccountManager is a mock of AccountManager interface.
+type MockAccountManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccountManagerMockRecorder
+}
+
+// MockAccountManagerMockRecorder is the mock recorder for MockAccountManager.
+type MockAccountManagerMockRecorder struct {
+	mock *MockAccountManager
+}
+
+// NewMockAccountManager creates a new mock instance.
+func NewMockAccountManager(ctrl *gomock.Controller) *MockAccountManager {
+	mock := &MockAccountManager{ctrl: ctrl}
+	mock.recorder = &MockAccountManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccountManager) EXPECT() *MockAccountManagerMockRecorder {
+	return m.recorder
+}
+
+// ChangePassword mocks base method.
+func (m *MockAccountManager) ChangePassword(arg0 context.Context, arg1 *account.PasswordChange) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockAccountManagerMockRecorder) ChangePassword(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockAccountManager)(nil).ChangePassword), arg0, arg1)
+}
+
+// DeactivateAccount mocks base method.
+func (m *MockAccountManager) DeactivateAccount(arg0 context.Context, arg1 *account.Deactivation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeactivateAccount", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeactivateAccount indicates an expected call of DeactivateAccount.
+func (mr *MockAccountManagerMockRecorder) DeactivateAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateAccount", reflect.TypeOf((*MockAccountManager)(nil).DeactivateAccount), arg0, arg1)
+}
+
+// DeleteAccount mocks base method.
+func (m *MockAccountManager) DeleteAccount(arg0 context.Context, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockAccountManagerMockRecorder) DeleteAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockAccountManager)(nil).DeleteAccount), arg0, arg1)
+}
+
+// GetAccount mocks base method.
+func (m *MockAccountManager) GetAccount(arg0 context.Context, arg1 int) (*user.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccount", arg0, arg1)
+	ret0, _ := ret[0].(*user.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccount indicates an expected call of GetAccount.
+func (mr *MockAccountManagerMockRecorder) GetAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockAccountManager)(nil).GetAccount), arg0, arg1)
+}
+
+// GetAccountByLogin mocks base method.
+func (m *MockAccountManager) GetAccountByLogin(arg0 context.Context, arg1 string) (*user.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountByLogin", arg0, arg1)
+	ret0, _ := ret[0].(*user.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountByLogin indicates an expected call of GetAccountByLogin.
+func (mr *MockAccountManagerMockRecorder) GetAccountByLogin(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByLogin", reflect.TypeOf((*MockAccountManager)(nil).GetAccountByLogin), arg0, arg1)
+}
+
+// PurgeDeletedAccounts mocks base method.
+func (m *MockAccountManager) PurgeDeletedAccounts(arg0 context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedAccounts", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedAccounts indicates an expected call of PurgeDeletedAccounts.
+func (mr *MockAccountManagerMockRecorder) PurgeDeletedAccounts(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedAccounts", reflect.TypeOf((*MockAccountManager)(nil).PurgeDeletedAccounts), arg0)
+}
+
+// RestoreAccount mocks base method.
+func (m *MockAccountManager) RestoreAccount(arg0 context.Context, arg1 int, arg2 account.SecurityEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreAccount", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreAccount indicates an expected call of RestoreAccount.
+func (mr *MockAccountManagerMockRecorder) RestoreAccount(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreAccount", reflect.TypeOf((*MockAccountManager)(nil).RestoreAccount), arg0, arg1, arg2)
+}
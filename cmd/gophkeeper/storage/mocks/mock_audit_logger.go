@@ -0,0 +1,102 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage (interfaces: AuditLogger,AuditRecorder)
+
+// Package storage is a generated GoMock package.
+package storage
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	audit "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/audit"
+)
+
+// MockAuditLogger is a mock of AuditLogger interface.
+type MockAuditLogger struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditLoggerMockRecorder
+}
+
+// MockAuditLoggerMockRecorder is the mock recorder for MockAuditLogger.
+type MockAuditLoggerMockRecorder struct {
+	mock *MockAuditLogger
+}
+
+// NewMockAuditLogger creates a new mock instance.
+func NewMockAuditLogger(ctrl *gomock.Controller) *MockAuditLogger {
+	mock := &MockAuditLogger{ctrl: ctrl}
+	mock.recorder = &MockAuditLoggerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditLogger) EXPECT() *MockAuditLoggerMockRecorder {
+	return m.recorder
+}
+
+// ListAuditEvents mocks base method.
+func (m *MockAuditLogger) ListAuditEvents(arg0 context.Context, arg1 *audit.Filter) ([]audit.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditEvents", arg0, arg1)
+	ret0, _ := ret[0].([]audit.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditEvents indicates an expected call of ListAuditEvents.
+func (mr *MockAuditLoggerMockRecorder) ListAuditEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditEvents", reflect.TypeOf((*MockAuditLogger)(nil).ListAuditEvents), arg0, arg1)
+}
+
+// SaveAuditEvent mocks base method.
+func (m *MockAuditLogger) SaveAuditEvent(arg0 context.Context, arg1 *audit.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAuditEvent", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAuditEvent indicates an expected call of SaveAuditEvent.
+func (mr *MockAuditLoggerMockRecorder) SaveAuditEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAuditEvent", reflect.TypeOf((*MockAuditLogger)(nil).SaveAuditEvent), arg0, arg1)
+}
+
+// MockAuditRecorder is a mock of AuditRecorder interface.
+type MockAuditRecorder struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditRecorderMockRecorder
+}
+
+// MockAuditRecorderMockRecorder is the mock recorder for MockAuditRecorder.
+type MockAuditRecorderMockRecorder struct {
+	mock *MockAuditRecorder
+}
+
+// NewMockAuditRecorder creates a new mock instance.
+func NewMockAuditRecorder(ctrl *gomock.Controller) *MockAuditRecorder {
+	mock := &MockAuditRecorder{ctrl: ctrl}
+	mock.recorder = &MockAuditRecorderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRecorder) EXPECT() *MockAuditRecorderMockRecorder {
+	return m.recorder
+}
+
+// SaveAuditEvent mocks base method.
+func (m *MockAuditRecorder) SaveAuditEvent(arg0 context.Context, arg1 *audit.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAuditEvent", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAuditEvent indicates an expected call of SaveAuditEvent.
+func (mr *MockAuditRecorderMockRecorder) SaveAuditEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAuditEvent", reflect.TypeOf((*MockAuditRecorder)(nil).SaveAuditEvent), arg0, arg1)
+}
@@ -7,8 +7,10 @@ package storage
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
+	account "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/account"
 	auth "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
 	user "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/user"
 )
@@ -36,6 +38,49 @@ func (m *MockAuthenticator) EXPECT() *MockAuthenticatorMockRecorder {
 	return m.recorder
 }
 
+// CompleteLoginChallenge mocks base method.
+func (m *MockAuthenticator) CompleteLoginChallenge(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteLoginChallenge", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteLoginChallenge indicates an expected call of CompleteLoginChallenge.
+func (mr *MockAuthenticatorMockRecorder) CompleteLoginChallenge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteLoginChallenge", reflect.TypeOf((*MockAuthenticator)(nil).CompleteLoginChallenge), arg0, arg1)
+}
+
+// ConfirmTOTP mocks base method.
+func (m *MockAuthenticator) ConfirmTOTP(arg0 context.Context, arg1 int, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTP", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmTOTP indicates an expected call of ConfirmTOTP.
+func (mr *MockAuthenticatorMockRecorder) ConfirmTOTP(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockAuthenticator)(nil).ConfirmTOTP), arg0, arg1, arg2)
+}
+
+// GetLoginAttempts mocks base method.
+func (m *MockAuthenticator) GetLoginAttempts(arg0 context.Context, arg1 []string) ([]auth.LoginAttempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginAttempts", arg0, arg1)
+	ret0, _ := ret[0].([]auth.LoginAttempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginAttempts indicates an expected call of GetLoginAttempts.
+func (mr *MockAuthenticatorMockRecorder) GetLoginAttempts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginAttempts", reflect.TypeOf((*MockAuthenticator)(nil).GetLoginAttempts), arg0, arg1)
+}
+
 // GetRefreshToken mocks base method.
 func (m *MockAuthenticator) GetRefreshToken(arg0 context.Context, arg1 string) (*auth.RefreshToken, error) {
 	m.ctrl.T.Helper()
@@ -51,6 +96,36 @@ func (mr *MockAuthenticatorMockRecorder) GetRefreshToken(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshToken", reflect.TypeOf((*MockAuthenticator)(nil).GetRefreshToken), arg0, arg1)
 }
 
+// GetTOTP mocks base method.
+func (m *MockAuthenticator) GetTOTP(arg0 context.Context, arg1 int) (*auth.TOTP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTOTP", arg0, arg1)
+	ret0, _ := ret[0].(*auth.TOTP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTOTP indicates an expected call of GetTOTP.
+func (mr *MockAuthenticatorMockRecorder) GetTOTP(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTOTP", reflect.TypeOf((*MockAuthenticator)(nil).GetTOTP), arg0, arg1)
+}
+
+// GetUserByID mocks base method.
+func (m *MockAuthenticator) GetUserByID(arg0 context.Context, arg1 int) (*user.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", arg0, arg1)
+	ret0, _ := ret[0].(*user.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockAuthenticatorMockRecorder) GetUserByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockAuthenticator)(nil).GetUserByID), arg0, arg1)
+}
+
 // GetUserByLogin mocks base method.
 func (m *MockAuthenticator) GetUserByLogin(arg0 context.Context, arg1 string) (*user.User, error) {
 	m.ctrl.T.Helper()
@@ -66,6 +141,107 @@ func (mr *MockAuthenticatorMockRecorder) GetUserByLogin(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByLogin", reflect.TypeOf((*MockAuthenticator)(nil).GetUserByLogin), arg0, arg1)
 }
 
+// IsAccessTokenActive mocks base method.
+func (m *MockAuthenticator) IsAccessTokenActive(arg0 context.Context, arg1 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsAccessTokenActive", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsAccessTokenActive indicates an expected call of IsAccessTokenActive.
+func (mr *MockAuthenticatorMockRecorder) IsAccessTokenActive(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAccessTokenActive", reflect.TypeOf((*MockAuthenticator)(nil).IsAccessTokenActive), arg0, arg1)
+}
+
+// ListSessions mocks base method.
+func (m *MockAuthenticator) ListSessions(arg0 context.Context, arg1 int, arg2 string) ([]auth.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessions", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]auth.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MockAuthenticatorMockRecorder) ListSessions(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MockAuthenticator)(nil).ListSessions), arg0, arg1, arg2)
+}
+
+// ReleaseLoginAttempt mocks base method.
+func (m *MockAuthenticator) ReleaseLoginAttempt(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseLoginAttempt", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseLoginAttempt indicates an expected call of ReleaseLoginAttempt.
+func (mr *MockAuthenticatorMockRecorder) ReleaseLoginAttempt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseLoginAttempt", reflect.TypeOf((*MockAuthenticator)(nil).ReleaseLoginAttempt), arg0, arg1)
+}
+
+// ReserveLoginAttempt mocks base method.
+func (m *MockAuthenticator) ReserveLoginAttempt(arg0 context.Context, arg1 string, arg2 int, arg3 time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveLoginAttempt", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveLoginAttempt indicates an expected call of ReserveLoginAttempt.
+func (mr *MockAuthenticatorMockRecorder) ReserveLoginAttempt(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveLoginAttempt", reflect.TypeOf((*MockAuthenticator)(nil).ReserveLoginAttempt), arg0, arg1, arg2, arg3)
+}
+
+// RehashPassword mocks base method.
+func (m *MockAuthenticator) RehashPassword(arg0 context.Context, arg1 int, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RehashPassword", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RehashPassword indicates an expected call of RehashPassword.
+func (mr *MockAuthenticatorMockRecorder) RehashPassword(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RehashPassword", reflect.TypeOf((*MockAuthenticator)(nil).RehashPassword), arg0, arg1, arg2, arg3)
+}
+
+// ResetLoginFailures mocks base method.
+func (m *MockAuthenticator) ResetLoginFailures(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetLoginFailures", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetLoginFailures indicates an expected call of ResetLoginFailures.
+func (mr *MockAuthenticatorMockRecorder) ResetLoginFailures(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetLoginFailures", reflect.TypeOf((*MockAuthenticator)(nil).ResetLoginFailures), arg0, arg1)
+}
+
+// RevokeAccessToken mocks base method.
+func (m *MockAuthenticator) RevokeAccessToken(arg0 context.Context, arg1 int, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAccessToken", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAccessToken indicates an expected call of RevokeAccessToken.
+func (mr *MockAuthenticatorMockRecorder) RevokeAccessToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAccessToken", reflect.TypeOf((*MockAuthenticator)(nil).RevokeAccessToken), arg0, arg1, arg2)
+}
+
 // RevokeRefreshToken mocks base method.
 func (m *MockAuthenticator) RevokeRefreshToken(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -80,31 +256,163 @@ func (mr *MockAuthenticatorMockRecorder) RevokeRefreshToken(arg0, arg1 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshToken", reflect.TypeOf((*MockAuthenticator)(nil).RevokeRefreshToken), arg0, arg1)
 }
 
+// RevokeSession mocks base method.
+func (m *MockAuthenticator) RevokeSession(arg0 context.Context, arg1, arg2 int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockAuthenticatorMockRecorder) RevokeSession(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockAuthenticator)(nil).RevokeSession), arg0, arg1, arg2)
+}
+
+// RevokeTokenFamily mocks base method.
+func (m *MockAuthenticator) RevokeTokenFamily(arg0 context.Context, arg1 string, arg2 account.SecurityEvent) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeTokenFamily", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeTokenFamily indicates an expected call of RevokeTokenFamily.
+func (mr *MockAuthenticatorMockRecorder) RevokeTokenFamily(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeTokenFamily", reflect.TypeOf((*MockAuthenticator)(nil).RevokeTokenFamily), arg0, arg1, arg2)
+}
+
+// RevokeUserTokens mocks base method.
+func (m *MockAuthenticator) RevokeUserTokens(arg0 context.Context, arg1 int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserTokens", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeUserTokens indicates an expected call of RevokeUserTokens.
+func (mr *MockAuthenticatorMockRecorder) RevokeUserTokens(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserTokens", reflect.TypeOf((*MockAuthenticator)(nil).RevokeUserTokens), arg0, arg1)
+}
+
 // SaveAccessToken mocks base method.
-func (m *MockAuthenticator) SaveAccessToken(arg0 context.Context, arg1 int, arg2 string) (int, error) {
+func (m *MockAuthenticator) SaveAccessToken(arg0 context.Context, arg1 int, arg2 string, arg3 auth.SessionInfo) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveAccessToken", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "SaveAccessToken", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SaveAccessToken indicates an expected call of SaveAccessToken.
-func (mr *MockAuthenticatorMockRecorder) SaveAccessToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockAuthenticatorMockRecorder) SaveAccessToken(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAccessToken", reflect.TypeOf((*MockAuthenticator)(nil).SaveAccessToken), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAccessToken", reflect.TypeOf((*MockAuthenticator)(nil).SaveAccessToken), arg0, arg1, arg2, arg3)
+}
+
+// SaveClientKeys mocks base method.
+func (m *MockAuthenticator) SaveClientKeys(arg0 context.Context, arg1 int, arg2, arg3 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveClientKeys", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveClientKeys indicates an expected call of SaveClientKeys.
+func (mr *MockAuthenticatorMockRecorder) SaveClientKeys(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveClientKeys", reflect.TypeOf((*MockAuthenticator)(nil).SaveClientKeys), arg0, arg1, arg2, arg3)
+}
+
+// SaveLoginChallenge mocks base method.
+func (m *MockAuthenticator) SaveLoginChallenge(arg0 context.Context, arg1 int, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveLoginChallenge", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveLoginChallenge indicates an expected call of SaveLoginChallenge.
+func (mr *MockAuthenticatorMockRecorder) SaveLoginChallenge(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveLoginChallenge", reflect.TypeOf((*MockAuthenticator)(nil).SaveLoginChallenge), arg0, arg1, arg2)
 }
 
 // SaveRefreshToken mocks base method.
-func (m *MockAuthenticator) SaveRefreshToken(arg0 context.Context, arg1 int, arg2 string) error {
+func (m *MockAuthenticator) SaveRefreshToken(arg0 context.Context, arg1 int, arg2, arg3 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveRefreshToken", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "SaveRefreshToken", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SaveRefreshToken indicates an expected call of SaveRefreshToken.
-func (mr *MockAuthenticatorMockRecorder) SaveRefreshToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockAuthenticatorMockRecorder) SaveRefreshToken(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRefreshToken", reflect.TypeOf((*MockAuthenticator)(nil).SaveRefreshToken), arg0, arg1, arg2, arg3)
+}
+
+// SaveTOTP mocks base method.
+func (m *MockAuthenticator) SaveTOTP(arg0 context.Context, arg1 int, arg2 *auth.TOTP, arg3 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTOTP", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTOTP indicates an expected call of SaveTOTP.
+func (mr *MockAuthenticatorMockRecorder) SaveTOTP(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTOTP", reflect.TypeOf((*MockAuthenticator)(nil).SaveTOTP), arg0, arg1, arg2, arg3)
+}
+
+// UseLoginChallengeAttempt mocks base method.
+func (m *MockAuthenticator) UseLoginChallengeAttempt(arg0 context.Context, arg1 string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseLoginChallengeAttempt", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseLoginChallengeAttempt indicates an expected call of UseLoginChallengeAttempt.
+func (mr *MockAuthenticatorMockRecorder) UseLoginChallengeAttempt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseLoginChallengeAttempt", reflect.TypeOf((*MockAuthenticator)(nil).UseLoginChallengeAttempt), arg0, arg1)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockAuthenticator) UseRecoveryCode(arg0 context.Context, arg1 int, arg2 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockAuthenticatorMockRecorder) UseRecoveryCode(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockAuthenticator)(nil).UseRecoveryCode), arg0, arg1, arg2)
+}
+
+// UseTOTPStep mocks base method.
+func (m *MockAuthenticator) UseTOTPStep(arg0 context.Context, arg1 int, arg2 int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep.
+func (mr *MockAuthenticatorMockRecorder) UseTOTPStep(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRefreshToken", reflect.TypeOf((*MockAuthenticator)(nil).SaveRefreshToken), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockAuthenticator)(nil).UseTOTPStep), arg0, arg1, arg2)
 }
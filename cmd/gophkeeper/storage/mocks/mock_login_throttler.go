@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage (interfaces: LoginThrottler)
+
+// Package storage is a generated GoMock package.
+package storage
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	auth "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+)
+
+// MockLoginThrottler is a mock of LoginThrottler interface.
+type MockLoginThrottler struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginThrottlerMockRecorder
+}
+
+// MockLoginThrottlerMockRecorder is the mock recorder for MockLoginThrottler.
+type MockLoginThrottlerMockRecorder struct {
+	mock *MockLoginThrottler
+}
+
+// NewMockLoginThrottler creates a new mock instance.
+func NewMockLoginThrottler(ctrl *gomock.Controller) *MockLoginThrottler {
+	mock := &MockLoginThrottler{ctrl: ctrl}
+	mock.recorder = &MockLoginThrottlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginThrottler) EXPECT() *MockLoginThrottlerMockRecorder {
+	return m.recorder
+}
+
+// GetLoginAttempts mocks base method.
+func (m *MockLoginThrottler) GetLoginAttempts(arg0 context.Context, arg1 []string) ([]auth.LoginAttempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginAttempts", arg0, arg1)
+	ret0, _ := ret[0].([]auth.LoginAttempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginAttempts indicates an expected call of GetLoginAttempts.
+func (mr *MockLoginThrottlerMockRecorder) GetLoginAttempts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginAttempts", reflect.TypeOf((*MockLoginThrottler)(nil).GetLoginAttempts), arg0, arg1)
+}
+
+// ReleaseLoginAttempt mocks base method.
+func (m *MockLoginThrottler) ReleaseLoginAttempt(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseLoginAttempt", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseLoginAttempt indicates an expected call of ReleaseLoginAttempt.
+func (mr *MockLoginThrottlerMockRecorder) ReleaseLoginAttempt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseLoginAttempt", reflect.TypeOf((*MockLoginThrottler)(nil).ReleaseLoginAttempt), arg0, arg1)
+}
+
+// ReserveLoginAttempt mocks base method.
+func (m *MockLoginThrottler) ReserveLoginAttempt(arg0 context.Context, arg1 string, arg2 int, arg3 time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveLoginAttempt", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveLoginAttempt indicates an expected call of ReserveLoginAttempt.
+func (mr *MockLoginThrottlerMockRecorder) ReserveLoginAttempt(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveLoginAttempt", reflect.TypeOf((*MockLoginThrottler)(nil).ReserveLoginAttempt), arg0, arg1, arg2, arg3)
+}
+
+// ResetLoginFailures mocks base method.
+func (m *MockLoginThrottler) ResetLoginFailures(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetLoginFailures", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetLoginFailures indicates an expected call of ResetLoginFailures.
+func (mr *MockLoginThrottlerMockRecorder) ResetLoginFailures(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetLoginFailures", reflect.TypeOf((*MockLoginThrottler)(nil).ResetLoginFailures), arg0, arg1)
+}
@@ -0,0 +1,133 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage (interfaces: PersonalTokenManager,PersonalTokenResolver)
+
+// Package storage is a generated GoMock package.
+package storage
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	auth "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+)
+
+// MockPersonalTokenManager is a mock of PersonalTokenManager interface.
+type MockPersonalTokenManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockPersonalTokenManagerMockRecorder
+}
+
+// MockPersonalTokenManagerMockRecorder is the mock recorder for MockPersonalTokenManager.
+type MockPersonalTokenManagerMockRecorder struct {
+	mock *MockPersonalTokenManager
+}
+
+// NewMockPersonalTokenManager creates a new mock instance.
+func NewMockPersonalTokenManager(ctrl *gomock.Controller) *MockPersonalTokenManager {
+	mock := &MockPersonalTokenManager{ctrl: ctrl}
+	mock.recorder = &MockPersonalTokenManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPersonalTokenManager) EXPECT() *MockPersonalTokenManagerMockRecorder {
+	return m.recorder
+}
+
+// GetPersonalToken mocks base method.
+func (m *MockPersonalTokenManager) GetPersonalToken(arg0 context.Context, arg1 string) (*auth.PersonalToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPersonalToken", arg0, arg1)
+	ret0, _ := ret[0].(*auth.PersonalToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPersonalToken indicates an expected call of GetPersonalToken.
+func (mr *MockPersonalTokenManagerMockRecorder) GetPersonalToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonalToken", reflect.TypeOf((*MockPersonalTokenManager)(nil).GetPersonalToken), arg0, arg1)
+}
+
+// ListPersonalTokens mocks base method.
+func (m *MockPersonalTokenManager) ListPersonalTokens(arg0 context.Context, arg1 int) ([]auth.PersonalToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPersonalTokens", arg0, arg1)
+	ret0, _ := ret[0].([]auth.PersonalToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPersonalTokens indicates an expected call of ListPersonalTokens.
+func (mr *MockPersonalTokenManagerMockRecorder) ListPersonalTokens(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPersonalTokens", reflect.TypeOf((*MockPersonalTokenManager)(nil).ListPersonalTokens), arg0, arg1)
+}
+
+// RevokePersonalToken mocks base method.
+func (m *MockPersonalTokenManager) RevokePersonalToken(arg0 context.Context, arg1, arg2 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokePersonalToken", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokePersonalToken indicates an expected call of RevokePersonalToken.
+func (mr *MockPersonalTokenManagerMockRecorder) RevokePersonalToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokePersonalToken", reflect.TypeOf((*MockPersonalTokenManager)(nil).RevokePersonalToken), arg0, arg1, arg2)
+}
+
+// SavePersonalToken mocks base method.
+func (m *MockPersonalTokenManager) SavePersonalToken(arg0 context.Context, arg1 *auth.PersonalToken, arg2 string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePersonalToken", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SavePersonalToken indicates an expected call of SavePersonalToken.
+func (mr *MockPersonalTokenManagerMockRecorder) SavePersonalToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePersonalToken", reflect.TypeOf((*MockPersonalTokenManager)(nil).SavePersonalToken), arg0, arg1, arg2)
+}
+
+// MockPersonalTokenResolver is a mock of PersonalTokenResolver interface.
+type MockPersonalTokenResolver struct {
+	ctrl     *gomock.Controller
+	recorder *MockPersonalTokenResolverMockRecorder
+}
+
+// MockPersonalTokenResolverMockRecorder is the mock recorder for MockPersonalTokenResolver.
+type MockPersonalTokenResolverMockRecorder struct {
+	mock *MockPersonalTokenResolver
+}
+
+// NewMockPersonalTokenResolver creates a new mock instance.
+func NewMockPersonalTokenResolver(ctrl *gomock.Controller) *MockPersonalTokenResolver {
+	mock := &MockPersonalTokenResolver{ctrl: ctrl}
+	mock.recorder = &MockPersonalTokenResolverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPersonalTokenResolver) EXPECT() *MockPersonalTokenResolverMockRecorder {
+	return m.recorder
+}
+
+// GetPersonalToken mocks base method.
+func (m *MockPersonalTokenResolver) GetPersonalToken(arg0 context.Context, arg1 string) (*auth.PersonalToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPersonalToken", arg0, arg1)
+	ret0, _ := ret[0].(*auth.PersonalToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPersonalToken indicates an expected call of GetPersonalToken.
+func (mr *MockPersonalTokenResolverMockRecorder) GetPersonalToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonalToken", reflect.TypeOf((*MockPersonalTokenResolver)(nil).GetPersonalToken), arg0, arg1)
+}
@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage (interfaces: Rotator)
+
+// Package storage is a generated GoMock package.
+package storage
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	rotation "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/rotation"
+)
+
+// MockRotator is a mock of Rotator interface.
+type MockRotator struct {
+	ctrl     *gomock.Controller
+	recorder *MockRotatorMockRecorder
+}
+
+// MockRotatorMockRecorder is the mock recorder for MockRotator.
+type MockRotatorMockRecorder struct {
+	mock *MockRotator
+}
+
+// NewMockRotator creates a new mock instance.
+func NewMockRotator(ctrl *gomock.Controller) *MockRotator {
+	mock := &MockRotator{ctrl: ctrl}
+	mock.recorder = &MockRotatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRotator) EXPECT() *MockRotatorMockRecorder {
+	return m.recorder
+}
+
+// CountRows mocks base method.
+func (m *MockRotator) CountRows(arg0 context.Context, arg1 string, arg2 int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountRows", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountRows indicates an expected call of CountRows.
+func (mr *MockRotatorMockRecorder) CountRows(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountRows", reflect.TypeOf((*MockRotator)(nil).CountRows), arg0, arg1, arg2)
+}
+
+// GetRows mocks base method.
+func (m *MockRotator) GetRows(arg0 context.Context, arg1 string, arg2 int, arg3 int64, arg4 int) ([]*rotation.Row, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRows", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]*rotation.Row)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRows indicates an expected call of GetRows.
+func (mr *MockRotatorMockRecorder) GetRows(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRows", reflect.TypeOf((*MockRotator)(nil).GetRows), arg0, arg1, arg2, arg3, arg4)
+}
+
+// UpdateRow mocks base method.
+func (m *MockRotator) UpdateRow(arg0 context.Context, arg1 string, arg2 *rotation.Row, arg3 int, arg4 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRow", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRow indicates an expected call of UpdateRow.
+func (mr *MockRotatorMockRecorder) UpdateRow(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRow", reflect.TypeOf((*MockRotator)(nil).UpdateRow), arg0, arg1, arg2, arg3, arg4)
+}
@@ -8,7 +8,10 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
-	repository "github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
+	storage "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage"
+	items "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/items"
+	binary "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/items/binary"
+	crypto "github.com/ramil063/secondgodiplom/internal/security/crypto"
 )
 
 // MockStorager is a mock of Storager interface.
@@ -34,28 +37,140 @@ func (m *MockStorager) EXPECT() *MockStoragerMockRecorder {
 	return m.recorder
 }
 
-// GetRepository mocks base method.
-func (m *MockStorager) GetRepository() repository.Repository {
+// Account mocks base method.
+func (m *MockStorager) Account() storage.AccountManager {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRepository")
-	ret0, _ := ret[0].(repository.Repository)
+	ret := m.ctrl.Call(m, "Account")
+	ret0, _ := ret[0].(storage.AccountManager)
 	return ret0
 }
 
-// GetRepository indicates an expected call of GetRepository.
-func (mr *MockStoragerMockRecorder) GetRepository() *gomock.Call {
+// Account indicates an expected call of Account.
+func (mr *MockStoragerMockRecorder) Account() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRepository", reflect.TypeOf((*MockStorager)(nil).GetRepository))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Account", reflect.TypeOf((*MockStorager)(nil).Account))
 }
 
-// SetRepository mocks base method.
-func (m *MockStorager) SetRepository(arg0 *repository.Repository) {
+// Audit mocks base method.
+func (m *MockStorager) Audit() storage.AuditLogger {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "SetRepository", arg0)
+	ret := m.ctrl.Call(m, "Audit")
+	ret0, _ := ret[0].(storage.AuditLogger)
+	return ret0
+}
+
+// Audit indicates an expected call of Audit.
+func (mr *MockStoragerMockRecorder) Audit() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Audit", reflect.TypeOf((*MockStorager)(nil).Audit))
+}
+
+// Auth mocks base method.
+func (m *MockStorager) Auth() storage.Authenticator {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Auth")
+	ret0, _ := ret[0].(storage.Authenticator)
+	return ret0
+}
+
+// Auth indicates an expected call of Auth.
+func (mr *MockStoragerMockRecorder) Auth() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Auth", reflect.TypeOf((*MockStorager)(nil).Auth))
+}
+
+// Close mocks base method.
+func (m *MockStorager) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockStoragerMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockStorager)(nil).Close))
+}
+
+// DataKeys mocks base method.
+func (m *MockStorager) DataKeys() crypto.KeyStore {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DataKeys")
+	ret0, _ := ret[0].(crypto.KeyStore)
+	return ret0
+}
+
+// DataKeys indicates an expected call of DataKeys.
+func (mr *MockStoragerMockRecorder) DataKeys() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DataKeys", reflect.TypeOf((*MockStorager)(nil).DataKeys))
+}
+
+// Files mocks base method.
+func (m *MockStorager) Files() binary.Filer {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Files")
+	ret0, _ := ret[0].(binary.Filer)
+	return ret0
+}
+
+// Files indicates an expected call of Files.
+func (mr *MockStoragerMockRecorder) Files() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Files", reflect.TypeOf((*MockStorager)(nil).Files))
+}
+
+// Items mocks base method.
+func (m *MockStorager) Items() items.Itemer {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Items")
+	ret0, _ := ret[0].(items.Itemer)
+	return ret0
+}
+
+// Items indicates an expected call of Items.
+func (mr *MockStoragerMockRecorder) Items() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Items", reflect.TypeOf((*MockStorager)(nil).Items))
+}
+
+// PersonalTokens mocks base method.
+func (m *MockStorager) PersonalTokens() storage.PersonalTokenManager {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PersonalTokens")
+	ret0, _ := ret[0].(storage.PersonalTokenManager)
+	return ret0
+}
+
+// PersonalTokens indicates an expected call of PersonalTokens.
+func (mr *MockStoragerMockRecorder) PersonalTokens() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PersonalTokens", reflect.TypeOf((*MockStorager)(nil).PersonalTokens))
+}
+
+// Registration mocks base method.
+func (m *MockStorager) Registration() storage.Registerer {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Registration")
+	ret0, _ := ret[0].(storage.Registerer)
+	return ret0
+}
+
+// Registration indicates an expected call of Registration.
+func (mr *MockStoragerMockRecorder) Registration() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Registration", reflect.TypeOf((*MockStorager)(nil).Registration))
+}
+
+// Rotation mocks base method.
+func (m *MockStorager) Rotation() storage.Rotator {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rotation")
+	ret0, _ := ret[0].(storage.Rotator)
+	return ret0
 }
 
-// SetRepository indicates an expected call of SetRepository.
-func (mr *MockStoragerMockRecorder) SetRepository(arg0 interface{}) *gomock.Call {
+// Rotation indicates an expected call of Rotation.
+func (mr *MockStoragerMockRecorder) Rotation() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRepository", reflect.TypeOf((*MockStorager)(nil).SetRepository), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rotation", reflect.TypeOf((*MockStorager)(nil).Rotation))
 }
@@ -0,0 +1,40 @@
+package account
+
+import "time"
+
+const (
+	EventPasswordChanged    = "password_changed"    // событие безопасности: пароль изменен
+	EventAccountDeactivated = "account_deactivated" // событие безопасности: учетная запись деактивирована до удаления
+	EventAccountRestored    = "account_restored"    // событие безопасности: удаление учетной записи отменено
+	EventRefreshTokenReuse  = "refresh_token_reuse" // событие безопасности: повторно предъявлен обмененный refresh token
+)
+
+// DefaultDeletionGracePeriod срок, в течение которого деактивированную учетную запись можно восстановить
+const DefaultDeletionGracePeriod = 30 * 24 * time.Hour
+
+// SecurityEvent описывает событие безопасности учетной записи
+type SecurityEvent struct {
+	UserID    int    `json:"user_id"`    // Пользователь
+	Type      string `json:"type"`       // Тип события
+	ClientIP  string `json:"client_ip"`  // IP адрес клиента
+	UserAgent string `json:"user_agent"` // User agent клиента
+}
+
+// PasswordChange описывает смену пароля пользователя
+// KdfSalt и WrappedVaultKey заполняются, если включено сквозное шифрование
+type PasswordChange struct {
+	UserID             int           `json:"user_id"`              // Пользователь
+	OldPasswordHash    string        `json:"old_password_hash"`    // Хеш текущего пароля, защищает от одновременной смены
+	NewPasswordHash    string        `json:"new_password_hash"`    // Хеш нового пароля
+	KdfSalt            []byte        `json:"kdf_salt"`             // Новая соль для вывода ключа из пароля
+	WrappedVaultKey    []byte        `json:"wrapped_vault_key"`    // Ключ хранилища, обернутый ключом из нового пароля
+	CurrentAccessToken string        `json:"current_access_token"` // Токен сессии, которая остается активной
+	Event              SecurityEvent `json:"event"`                // Событие безопасности
+}
+
+// Deactivation описывает отложенное удаление учетной записи
+type Deactivation struct {
+	UserID  int           `json:"user_id"`  // Пользователь
+	PurgeAt time.Time     `json:"purge_at"` // Дата окончательного удаления данных
+	Event   SecurityEvent `json:"event"`    // Событие безопасности
+}
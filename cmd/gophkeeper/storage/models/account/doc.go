@@ -0,0 +1,2 @@
+// Package account в пакете находятся модели для управления учетной записью пользователя
+package account
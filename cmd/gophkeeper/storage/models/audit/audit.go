@@ -0,0 +1,63 @@
+package audit
+
+import "time"
+
+const (
+	EventLogin               = "login"                 // вход по паролю
+	EventLoginSecondFactor   = "login_second_factor"   // пароль принят, ожидается второй фактор
+	EventLoginTOTP           = "login_totp"            // вход завершен вторым фактором
+	EventTokenRefresh        = "token_refresh"         // обновление токенов
+	EventLogout              = "logout"                // выход
+	EventLogoutAll           = "logout_all"            // выход на всех устройствах
+	EventSessionRevoke       = "session_revoke"        // завершение сессии на другом устройстве
+	EventTOTPEnable          = "totp_enable"           // включение второго фактора
+	EventPasswordChange      = "password_change"       // смена пароля
+	EventAccountDelete       = "account_delete"        // удаление учетной записи
+	EventAccountRestore      = "account_restore"       // восстановление учетной записи
+	EventPersonalTokenCreate = "personal_token_create" // выпуск персонального токена
+	EventPersonalTokenRevoke = "personal_token_revoke" // отзыв персонального токена
+	EventItemCreate          = "item_create"           // создание записи
+	EventItemRead            = "item_read"             // чтение записи
+	EventItemList            = "item_list"             // чтение списка записей
+	EventItemUpdate          = "item_update"           // изменение записи
+	EventItemDelete          = "item_delete"           // удаление записи
+	EventFileUpload          = "file_upload"           // загрузка файла
+	EventFileDownload        = "file_download"         // скачивание файла
+	EventFileRead            = "file_read"             // чтение описания файла
+	EventFileList            = "file_list"             // чтение списка файлов
+	EventFileDelete          = "file_delete"           // удаление файла
+)
+
+// Ограничения размера страницы журнала
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// Event описывает событие журнала аудита
+type Event struct {
+	ID              int64     `json:"id"`                // Идентификатор события
+	UserID          *int      `json:"user_id"`           // Пользователь, nil - неизвестен (вход с неизвестным логином)
+	Login           string    `json:"login"`             // Логин из запроса входа
+	Type            string    `json:"type"`              // Тип события
+	Method          string    `json:"method"`            // Вызванный метод gRPC
+	TargetID        *int64    `json:"target_id"`         // Объект действия: запись, файл, сессия или токен
+	PersonalTokenID *int      `json:"personal_token_id"` // Персональный токен, с которым выполнен вызов
+	Success         bool      `json:"success"`           // Вызов завершился успешно
+	StatusCode      string    `json:"status_code"`       // Код ответа gRPC
+	ClientIP        string    `json:"client_ip"`         // IP адрес клиента
+	UserAgent       string    `json:"user_agent"`        // User agent клиента
+	CreatedAt       time.Time `json:"created_at"`        // Время события
+}
+
+// Filter условия выборки событий пользователя, постранично от новых к старым
+type Filter struct {
+	UserID     int        `json:"user_id"`     // Пользователь
+	Types      []string   `json:"types"`       // Типы событий, пусто - все
+	TargetID   int64      `json:"target_id"`   // Объект действия, 0 - любой
+	Since      *time.Time `json:"since"`       // События не раньше
+	Until      *time.Time `json:"until"`       // События раньше
+	FailedOnly bool       `json:"failed_only"` // Только неуспешные вызовы
+	BeforeID   int64      `json:"before_id"`   // События с ID меньше, 0 - с последнего
+	Limit      int        `json:"limit"`       // Размер страницы
+}
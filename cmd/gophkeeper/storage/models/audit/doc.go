@@ -0,0 +1,2 @@
+// Package audit в пакете находятся модели журнала аудита действий пользователей
+package audit
@@ -0,0 +1,20 @@
+package auth
+
+import "time"
+
+// PersonalTokenPrefix начало персонального токена доступа, по нему интерсептор отличает токен от JWT
+const PersonalTokenPrefix = "gkp_"
+
+// MaxPersonalTokens сколько действующих персональных токенов может быть у пользователя
+const MaxPersonalTokens = 20
+
+// PersonalToken описывает персональный токен доступа для автоматизации
+type PersonalToken struct {
+	ID         int        `json:"id"`           // Идентификатор токена
+	UserID     int        `json:"user_id"`      // Владелец токена
+	Name       string     `json:"name"`         // Название токена
+	Scopes     []string   `json:"scopes"`       // Права токена
+	ExpiresAt  *time.Time `json:"expires_at"`   // Срок действия, nil - бессрочный
+	LastUsedAt *time.Time `json:"last_used_at"` // Дата последнего использования
+	CreatedAt  time.Time  `json:"created_at"`   // Дата создания
+}
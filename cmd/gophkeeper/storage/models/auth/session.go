@@ -0,0 +1,20 @@
+package auth
+
+import "time"
+
+// SessionInfo описывает устройство, на котором выполнен вход
+type SessionInfo struct {
+	ClientIP  string    `json:"client_ip"`  // IP адрес клиента
+	UserAgent string    `json:"user_agent"` // User agent клиента
+	CreatedAt time.Time `json:"created_at"` // Дата входа, при обновлении токенов переносится в новую пару
+}
+
+// Session описывает активную сессию пользователя: пару токенов авторизации и обновления
+type Session struct {
+	ID         int        `json:"id"`           // Идентификатор токена авторизации
+	ClientIP   string     `json:"client_ip"`    // IP адрес клиента
+	UserAgent  string     `json:"user_agent"`   // User agent клиента
+	CreatedAt  time.Time  `json:"created_at"`   // Дата входа на устройстве
+	LastUsedAt *time.Time `json:"last_used_at"` // Дата последнего обращения, nil если обращений не было
+	Current    bool       `json:"current"`      // Сессия, из которой выполнен запрос
+}
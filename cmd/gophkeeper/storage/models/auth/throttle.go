@@ -0,0 +1,58 @@
+package auth
+
+import "time"
+
+// Ограничение подбора пароля по умолчанию
+const (
+	DefaultMaxLoginFailures = 5
+	DefaultMaxIPFailures    = 50
+	DefaultLoginBaseDelay   = time.Second
+	DefaultLoginMaxDelay    = time.Minute
+	DefaultLoginLockout     = 15 * time.Minute
+)
+
+// LoginPolicy ограничение подбора пароля
+// после каждой неудачной попытки по логину следующая разрешается не раньше, чем через задержку,
+// задержка удваивается с каждой неудачей; после MaxFailures неудач логин блокируется на Lockout.
+// Попытки с одного IP адреса не задерживаются, а только блокируются после MaxIPFailures неудач
+type LoginPolicy struct {
+	MaxFailures   int           `json:"max_failures"`    // Неудачных попыток по логину до блокировки
+	MaxIPFailures int           `json:"max_ip_failures"` // Неудачных попыток с одного IP адреса до блокировки
+	BaseDelay     time.Duration `json:"base_delay"`      // Задержка после первой неудачи
+	MaxDelay      time.Duration `json:"max_delay"`       // Наибольшая задержка
+	Lockout       time.Duration `json:"lockout"`         // Срок блокировки, за это же время неудачи забываются
+}
+
+// DefaultLoginPolicy ограничение подбора пароля по умолчанию
+func DefaultLoginPolicy() LoginPolicy {
+	return LoginPolicy{
+		MaxFailures:   DefaultMaxLoginFailures,
+		MaxIPFailures: DefaultMaxIPFailures,
+		BaseDelay:     DefaultLoginBaseDelay,
+		MaxDelay:      DefaultLoginMaxDelay,
+		Lockout:       DefaultLoginLockout,
+	}
+}
+
+// Delay задержка перед следующей попыткой после failures неудач подряд
+func (p LoginPolicy) Delay(failures int) time.Duration {
+	if failures <= 0 || p.BaseDelay <= 0 {
+		return 0
+	}
+	delay := p.BaseDelay
+	for i := 1; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// LoginAttempts неудачные попытки входа по логину или с IP адреса
+type LoginAttempts struct {
+	Subject       string     `json:"subject"`         // Логин или IP адрес с префиксом
+	Failures      int        `json:"failures"`        // Неудач подряд
+	LastFailureAt time.Time  `json:"last_failure_at"` // Время последней неудачи
+	LockedUntil   *time.Time `json:"locked_until"`    // Время окончания блокировки
+}
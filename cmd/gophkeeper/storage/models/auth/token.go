@@ -23,4 +23,8 @@ type RefreshToken struct {
 	AccessTokenHash string    `json:"access_token_hash"` // Хеш токена авторизации
 	UserID          int       `json:"user_id"`           // Пользователь
 	ExpiresAt       time.Time `json:"expires_at"`        // Время истечения токена
+	SessionCreated  time.Time `json:"session_created"`   // Дата входа на устройстве
+	FamilyID        string    `json:"family_id"`         // Семейство токенов: цепочка обновлений от одного входа
+	IsRevoked       bool      `json:"is_revoked"`        // Отозван ли токен
+	IsRotated       bool      `json:"is_rotated"`        // Обменян ли токен на новый через Refresh
 }
@@ -0,0 +1,17 @@
+package auth
+
+// TOTPChallengeExpiredSeconds сколько действует вход, ожидающий второй фактор
+var TOTPChallengeExpiredSeconds int64 = 5 * 60
+
+// TOTPChallengeMaxAttempts сколько раз можно ввести код для одного входа
+const TOTPChallengeMaxAttempts = 5
+
+// TOTP описывает подключенный второй фактор пользователя
+type TOTP struct {
+	EncryptedSecret []byte `json:"encrypted_secret"` // Секрет, зашифрованный ключом пользователя
+	IV              []byte `json:"iv"`               // Вектор инициализации
+	Algorithm       string `json:"algorithm"`        // Алгоритм шифрования секрета
+	KeyVersion      int    `json:"key_version"`      // Версия мастер-ключа
+	Confirmed       bool   `json:"confirmed"`        // Подключение подтверждено кодом из приложения
+	LastUsedStep    int64  `json:"last_used_step"`   // Номер периода последнего принятого кода
+}
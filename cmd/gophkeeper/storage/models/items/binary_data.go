@@ -13,6 +13,8 @@ type FileInfo struct {
 	TotalChunks   int32     `json:"total_chunks"`
 	CreatedAt     time.Time `json:"created_at"`
 	MetaDataItems []*MetaData
+
+	ClientEncrypted bool `json:"client_encrypted"`
 }
 
 // ChunkData структура для хранения разделенных частей зашифрованного файла
@@ -23,5 +25,8 @@ type ChunkData struct {
 	EncryptedData       []byte    `json:"encrypted_data"`
 	EncryptionAlgorithm string    `json:"encryption_algorithm"`
 	IV                  []byte    `json:"iv"`
+	KeyVersion          int       `json:"key_version"`
+	AADBound            bool      `json:"aad_bound"`
+	StreamBound         bool      `json:"stream_bound"`
 	CreatedAt           time.Time `json:"created_at"`
 }
@@ -4,14 +4,28 @@ import (
 	"time"
 )
 
+// Виды идентификаторов, зарезервированных клиентом (таблица reserved_id)
+const (
+	ReservedKindItem = "item"
+	ReservedKindFile = "file"
+)
+
+// ReservedIDTTL срок действия идентификатора, зарезервированного клиентом для сквозного шифрования
+const ReservedIDTTL = time.Hour
+
 // EncryptedItem структура работы с зашифрованными данными
+// ID заранее зарезервированный идентификатор записи, нужен для привязки шифротекста к записи
+// AADBound данные зашифрованы со связанными данными (crypto.ItemAAD)
 type EncryptedItem struct {
+	ID                  int64
 	UserID              int64
 	Type                string
 	Data                []byte
 	Description         string
 	EncryptionAlgorithm string
 	Iv                  []byte
+	KeyVersion          int
+	AADBound            bool
 }
 
 // MetaData структура для работы с метаданными
@@ -31,5 +45,7 @@ type ItemData struct {
 	CreatedAt           time.Time
 	EncryptionAlgorithm string
 	IV                  []byte
+	KeyVersion          int
+	AADBound            bool
 	MetaDataItems       []*MetaData
 }
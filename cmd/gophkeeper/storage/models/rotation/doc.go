@@ -0,0 +1,2 @@
+// Package rotation в пакете находятся модели нужные для ротации мастер-ключа
+package rotation
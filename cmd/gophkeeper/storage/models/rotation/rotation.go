@@ -0,0 +1,34 @@
+package rotation
+
+import "errors"
+
+// Таблицы с данными, зашифрованными мастер-ключом напрямую или через ключ пользователя
+const (
+	TableDataKey = "user_data_key"
+	TableItem    = "encrypted_item"
+	TableChunk   = "binary_file_chunk"
+)
+
+// ErrRowChanged запись изменилась между чтением и сохранением перешифрованных данных
+var ErrRowChanged = errors.New("row changed concurrently")
+
+// Tables таблицы в порядке ротации
+// ключи пользователей перешифровываются первыми, так как через них читаются остальные данные
+var Tables = []string{TableDataKey, TableItem, TableChunk}
+
+// Row зашифрованная запись, которую нужно перешифровать новым ключом
+// ItemType, FileID, ChunkIndex и FinalChunk нужны для восстановления связанных данных (AAD) записи
+type Row struct {
+	ID                  int64
+	UserID              int
+	Data                []byte
+	IV                  []byte
+	EncryptionAlgorithm string
+	KeyVersion          int
+	ItemType            string
+	FileID              int64
+	ChunkIndex          int32
+	FinalChunk          bool
+	AADBound            bool
+	StreamBound         bool
+}
@@ -1,5 +1,7 @@
 package user
 
+import "time"
+
 // User описывает данные о пользователя
 type User struct {
 	ID           int    `json:"id,omitempty"`
@@ -7,4 +9,10 @@ type User struct {
 	PasswordHash string `json:"password"`             // Пароль
 	FirstName    string `json:"first_name,omitempty"` // Имя
 	LastName     string `json:"last_name,omitempty"`  // Фамилия
+
+	IsActive            bool       `json:"is_active"`                       // Учетная запись активна
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"` // Дата окончательного удаления деактивированной учетной записи
+
+	KdfSalt         []byte `json:"kdf_salt,omitempty"`          // Соль для вывода ключа из мастер-пароля
+	WrappedVaultKey []byte `json:"wrapped_vault_key,omitempty"` // Ключ хранилища, зашифрованный на клиенте
 }
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	serverConfig "github.com/ramil063/secondgodiplom/cmd/gophkeeper/config"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/items"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/items/binary"
+	"github.com/ramil063/secondgodiplom/internal/logger"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto"
+	"github.com/ramil063/secondgodiplom/internal/storage/db"
+	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
+)
+
+// postgresStorage хранилища в PostgreSQL, запросы выполняются через пул соединений
+type postgresStorage struct {
+	rep repository.Repository
+}
+
+// newPostgresStorage подключение к PostgreSQL по database_uri
+func newPostgresStorage(config *serverConfig.ServerConfig) (*postgresStorage, error) {
+	rep, err := repository.NewRepository(config)
+	if err != nil {
+		logger.WriteErrorLog(err.Error())
+		return nil, err
+	}
+
+	if err = db.Init(*rep); err != nil {
+		logger.WriteErrorLog("Init DB: " + err.Error())
+		rep.Pool.Close()
+		return nil, err
+	}
+	return &postgresStorage{rep: *rep}, nil
+}
+
+func (s *postgresStorage) Registration() Registerer {
+	return NewRegistrationStorage(s.rep)
+}
+
+func (s *postgresStorage) Auth() Authenticator {
+	return NewAuthStorage(s.rep)
+}
+
+func (s *postgresStorage) Account() AccountManager {
+	return NewAccountStorage(s.rep)
+}
+
+func (s *postgresStorage) PersonalTokens() PersonalTokenManager {
+	return NewPersonalTokenStorage(s.rep)
+}
+
+func (s *postgresStorage) Audit() AuditLogger {
+	return NewAuditStorage(s.rep)
+}
+
+func (s *postgresStorage) DataKeys() crypto.KeyStore {
+	return NewDataKeyStorage(s.rep)
+}
+
+func (s *postgresStorage) Rotation() Rotator {
+	return NewRotationStorage(s.rep)
+}
+
+func (s *postgresStorage) Items() items.Itemer {
+	return items.NewStorage(s.rep)
+}
+
+func (s *postgresStorage) Files() binary.Filer {
+	return binary.NewStorage(s.rep)
+}
+
+// Close закрытие пула соединений
+func (s *postgresStorage) Close() {
+	s.rep.Pool.Close()
+}
@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"context"
+
+	rotationModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/rotation"
+	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
+	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/rotation"
+)
+
+// Rotator интерфейс описывающий работу с записями при ротации мастер-ключа
+type Rotator interface {
+	CountRows(ctx context.Context, table string, keyVersion int) (int64, error)
+	GetRows(ctx context.Context, table string, keyVersion int, afterID int64, limit int) ([]*rotationModel.Row, error)
+	UpdateRow(ctx context.Context, table string, row *rotationModel.Row, oldKeyVersion int, oldIV []byte) error
+}
+
+// NewRotationStorage инициализация хранилища для ротации мастер-ключа
+// в структуре есть указатель на репозиторий
+func NewRotationStorage(rep repository.Repository) Rotator {
+	return &rotation.Rotation{
+		Repository: &rep,
+	}
+}
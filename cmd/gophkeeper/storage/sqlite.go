@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/items"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/items/binary"
+	"github.com/ramil063/secondgodiplom/internal/logger"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto"
+	"github.com/ramil063/secondgodiplom/internal/storage/sqlite"
+)
+
+// sqliteStorage хранилища во встроенной бд SQLite, для запуска сервера без PostgreSQL
+type sqliteStorage struct {
+	db *sqlite.DB
+}
+
+// newSQLiteStorage открытие файла бд path, файл создается при первом запуске
+func newSQLiteStorage(ctx context.Context, path string) (*sqliteStorage, error) {
+	sqliteDB, err := sqlite.Open(ctx, path)
+	if err != nil {
+		logger.WriteErrorLog("Init DB: " + err.Error())
+		return nil, err
+	}
+	return &sqliteStorage{db: sqliteDB}, nil
+}
+
+func (s *sqliteStorage) Registration() Registerer {
+	return &sqlite.Reg{DB: s.db}
+}
+
+func (s *sqliteStorage) Auth() Authenticator {
+	return &sqlite.Auth{DB: s.db}
+}
+
+func (s *sqliteStorage) Account() AccountManager {
+	return &sqlite.Account{DB: s.db}
+}
+
+func (s *sqliteStorage) PersonalTokens() PersonalTokenManager {
+	return &sqlite.Token{DB: s.db}
+}
+
+func (s *sqliteStorage) Audit() AuditLogger {
+	return &sqlite.Audit{DB: s.db}
+}
+
+func (s *sqliteStorage) DataKeys() crypto.KeyStore {
+	return &sqlite.DataKey{DB: s.db}
+}
+
+func (s *sqliteStorage) Rotation() Rotator {
+	return &sqlite.Rotation{DB: s.db}
+}
+
+func (s *sqliteStorage) Items() items.Itemer {
+	return &sqlite.Item{DB: s.db}
+}
+
+func (s *sqliteStorage) Files() binary.Filer {
+	return &sqlite.File{DB: s.db}
+}
+
+// Close закрытие бд
+func (s *sqliteStorage) Close() {
+	if err := s.db.Close(); err != nil {
+		logger.WriteErrorLog("Close DB: " + err.Error())
+	}
+}
@@ -1,22 +1,43 @@
 package storage
 
 import (
-	"github.com/ramil063/secondgodiplom/internal/storage/db"
-	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
-)
+	"context"
+	"errors"
+	"fmt"
 
-// Repositorier интерфейс описывающий функции для получения и возврата репозитория
-type Repositorier interface {
-	SetRepository(repository *repository.Repository)
-	GetRepository() repository.Repository
-}
+	serverConfig "github.com/ramil063/secondgodiplom/cmd/gophkeeper/config"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/items"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/items/binary"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto"
+)
 
-// Storager интерфейс центрального хранилища
+// Storager интерфейс центрального хранилища: хранилища данных сервера в бд выбранного драйвера
 type Storager interface {
-	Repositorier
+	Registration() Registerer
+	Auth() Authenticator
+	Account() AccountManager
+	PersonalTokens() PersonalTokenManager
+	Audit() AuditLogger
+	DataKeys() crypto.KeyStore
+	Rotation() Rotator
+	Items() items.Itemer
+	Files() binary.Filer
+	Close()
 }
 
-// NewDBStorage инициализация центрального хранилища
-func NewDBStorage() Storager {
-	return &db.Storage{}
+// NewDBStorage инициализация центрального хранилища драйвера storage_driver
+// бд открывается и ее схема обновляется до встроенных в сервер миграций
+func NewDBStorage(ctx context.Context, config *serverConfig.ServerConfig) (Storager, error) {
+	if config.DatabaseURI == "" {
+		return nil, errors.New("database_uri is not set in config")
+	}
+
+	switch config.Driver() {
+	case serverConfig.StorageDriverPostgres:
+		return newPostgresStorage(config)
+	case serverConfig.StorageDriverSQLite:
+		return newSQLiteStorage(ctx, config.DatabaseURI)
+	default:
+		return nil, fmt.Errorf("unknown storage_driver %q", config.StorageDriver)
+	}
 }
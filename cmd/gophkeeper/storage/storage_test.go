@@ -1,27 +1,80 @@
 package storage
 
 import (
+	"context"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
-	"github.com/ramil063/secondgodiplom/internal/storage/db"
+	serverConfig "github.com/ramil063/secondgodiplom/cmd/gophkeeper/config"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/user"
+	"github.com/ramil063/secondgodiplom/internal/storage/sqlite"
 )
 
 func TestNewDBStorage(t *testing.T) {
 	tests := []struct {
-		name string
-		want Storager
+		name    string
+		config  *serverConfig.ServerConfig
+		wantErr bool
 	}{
 		{
-			name: "test 1",
-			want: &db.Storage{},
+			name:    "no database uri",
+			config:  &serverConfig.ServerConfig{StorageDriver: serverConfig.StorageDriverSQLite},
+			wantErr: true,
+		},
+		{
+			name: "unknown driver",
+			config: &serverConfig.ServerConfig{
+				DatabaseURI:   filepath.Join(t.TempDir(), "gophkeeper.db"),
+				StorageDriver: "mysql",
+			},
+			wantErr: true,
+		},
+		{
+			name: "sqlite",
+			config: &serverConfig.ServerConfig{
+				DatabaseURI:   filepath.Join(t.TempDir(), "gophkeeper.db"),
+				StorageDriver: serverConfig.StorageDriverSQLite,
+			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := NewDBStorage()
-			assert.Equal(t, tt.want, got)
+			got, err := NewDBStorage(context.Background(), tt.config)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer got.Close()
+
+			assert.IsType(t, &sqlite.Auth{}, got.Auth())
+			assert.IsType(t, &sqlite.Item{}, got.Items())
+			assert.IsType(t, &sqlite.File{}, got.Files())
 		})
 	}
 }
+
+func TestNewDBStorage_SQLiteRegistration(t *testing.T) {
+	ctx := context.Background()
+	config := &serverConfig.ServerConfig{
+		DatabaseURI:   filepath.Join(t.TempDir(), "gophkeeper.db"),
+		StorageDriver: serverConfig.StorageDriverSQLite,
+	}
+
+	storage, err := NewDBStorage(ctx, config)
+	require.NoError(t, err)
+	userID, err := storage.Registration().RegisterUser(ctx, &user.User{Login: "user", PasswordHash: "hash"})
+	require.NoError(t, err)
+	storage.Close()
+
+	// данные сохраняются в файле бд между запусками сервера
+	storage, err = NewDBStorage(ctx, config)
+	require.NoError(t, err)
+	defer storage.Close()
+	got, err := storage.Auth().GetUserByLogin(ctx, "user")
+	require.NoError(t, err)
+	assert.Equal(t, userID, got.ID)
+}
@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"context"
+
+	authModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
+	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/token"
+)
+
+// PersonalTokenResolver интерфейс описывающий получение действующего персонального токена по его значению
+type PersonalTokenResolver interface {
+	GetPersonalToken(ctx context.Context, token string) (*authModel.PersonalToken, error)
+}
+
+// PersonalTokenManager интерфейс описывающий управление персональными токенами доступа
+type PersonalTokenManager interface {
+	PersonalTokenResolver
+	SavePersonalToken(ctx context.Context, personal *authModel.PersonalToken, token string) (int, error)
+	ListPersonalTokens(ctx context.Context, userID int) ([]authModel.PersonalToken, error)
+	RevokePersonalToken(ctx context.Context, userID int, tokenID int) error
+}
+
+// NewPersonalTokenStorage инициализация хранилища персональных токенов доступа
+// в структуре есть указатель на репозиторий
+func NewPersonalTokenStorage(rep repository.Repository) PersonalTokenManager {
+	return &token.Token{
+		Repository: &rep,
+	}
+}
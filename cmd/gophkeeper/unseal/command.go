@@ -0,0 +1,91 @@
+package unseal
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/seal"
+)
+
+const (
+	// CommandName название подкоманды сервера
+	CommandName = "unseal"
+	// requestTimeout ограничение времени запроса к серверу
+	requestTimeout = 10 * time.Second
+)
+
+// options параметры подкоманды
+type options struct {
+	address string
+}
+
+func parseOptions(args []string, out io.Writer) (*options, error) {
+	var opts options
+
+	flags := flag.NewFlagSet(CommandName, flag.ContinueOnError)
+	flags.SetOutput(out)
+	flags.StringVar(&opts.address, "address", "localhost:3202", "адрес запечатанного сервера")
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	return &opts, nil
+}
+
+// readShare чтение доли в base64 из входного потока
+func readShare(in io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read share: %w", err)
+	}
+	share, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil || len(share) == 0 {
+		return nil, fmt.Errorf("share must be base64 encoded")
+	}
+	return share, nil
+}
+
+// Run передача доли мастер-ключа серверу
+func Run(ctx context.Context, args []string, in io.Reader, out io.Writer) error {
+	opts, err := parseOptions(args, out)
+	if err != nil {
+		return err
+	}
+	share, err := readShare(in)
+	if err != nil {
+		return err
+	}
+
+	conn, err := grpc.NewClient(opts.address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return fmt.Errorf("failed to create gRPC client: %w", err)
+	}
+	defer conn.Close()
+
+	return submit(ctx, seal.NewSealServiceClient(conn), share, out)
+}
+
+// submit отправка доли и вывод состояния печати
+func submit(ctx context.Context, client seal.SealServiceClient, share []byte, out io.Writer) error {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	status, err := client.Unseal(ctx, &seal.UnsealRequest{Share: share})
+	if err != nil {
+		return fmt.Errorf("failed to unseal: %w", err)
+	}
+	if status.Sealed {
+		fmt.Fprintf(out, "Доля принята: %d из %d\n", status.Progress, status.Threshold)
+		return nil
+	}
+	fmt.Fprintln(out, "Сервер распечатан")
+	return nil
+}
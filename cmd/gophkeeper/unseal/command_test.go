@@ -0,0 +1,83 @@
+package unseal
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/seal"
+)
+
+// sealClient заглушка клиента сервиса распечатывания
+type sealClient struct {
+	share  []byte
+	status *seal.SealStatus
+	err    error
+}
+
+func (c *sealClient) Unseal(ctx context.Context, in *seal.UnsealRequest, opts ...grpc.CallOption) (*seal.SealStatus, error) {
+	c.share = in.Share
+	return c.status, c.err
+}
+
+func (c *sealClient) Status(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*seal.SealStatus, error) {
+	return c.status, c.err
+}
+
+func TestReadShare(t *testing.T) {
+	share, err := readShare(strings.NewReader("AQID\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 3}, share)
+
+	_, err = readShare(strings.NewReader("not base64!\n"))
+	assert.Error(t, err)
+
+	_, err = readShare(strings.NewReader("\n"))
+	assert.Error(t, err)
+}
+
+func Test_submit(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  *sealClient
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "share accepted",
+			client: &sealClient{status: &seal.SealStatus{Sealed: true, Threshold: 3, Progress: 1}},
+			want:   "Доля принята: 1 из 3\n",
+		},
+		{
+			name:   "unsealed",
+			client: &sealClient{status: &seal.SealStatus{Threshold: 3}},
+			want:   "Сервер распечатан\n",
+		},
+		{
+			name:    "rejected",
+			client:  &sealClient{err: status.Error(codes.InvalidArgument, "invalid share")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			err := submit(context.Background(), tt.client, []byte{1, 2, 3}, out)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []byte{1, 2, 3}, tt.client.share)
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
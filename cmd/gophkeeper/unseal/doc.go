@@ -0,0 +1,4 @@
+// Package unseal передача доли мастер-ключа запечатанному серверу
+// - доля читается из стандартного ввода, чтобы не оставаться в истории команд
+// - печатается, сколько долей принято и распечатан ли сервер
+package unseal
@@ -1,3 +1,6 @@
 package cookie
 
 const FileToSaveCookie = ".tokens.json"
+
+// FileToSaveVault файл с параметрами ключа хранилища для сквозного шифрования
+const FileToSaveVault = ".vault.json"
@@ -1,15 +1,146 @@
 package hash
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
 
-// GetPasswordHash получение хеша пароля
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// argon2idPrefix начало хеша Argon2id в формате PHC
+const argon2idPrefix = "$argon2id$"
+
+// Argon2Params параметры Argon2id
+type Argon2Params struct {
+	Memory      uint32 `json:"memory"`      // Объем памяти в KiB
+	Iterations  uint32 `json:"iterations"`  // Число проходов
+	Parallelism uint8  `json:"parallelism"` // Число потоков
+	SaltLength  uint32 `json:"salt_length"` // Длина соли в байтах
+	KeyLength   uint32 `json:"key_length"`  // Длина хеша в байтах
+}
+
+// DefaultArgon2Params параметры Argon2id по умолчанию (RFC 9106, второй рекомендуемый вариант)
+var DefaultArgon2Params = Argon2Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 4,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+var (
+	paramsMu sync.RWMutex
+	params   = DefaultArgon2Params
+)
+
+// SetArgon2Params установка параметров для новых хешей паролей
+// хеши с другими параметрами продолжают проверяться, NeedsRehash для них возвращает true
+func SetArgon2Params(p Argon2Params) {
+	paramsMu.Lock()
+	defer paramsMu.Unlock()
+	params = p
+}
+
+// GetArgon2Params текущие параметры для новых хешей паролей
+func GetArgon2Params() Argon2Params {
+	paramsMu.RLock()
+	defer paramsMu.RUnlock()
+	return params
+}
+
+// GetPasswordHash получение хеша пароля Argon2id в формате PHC
+// $argon2id$v=19$m=65536,t=3,p=4$<соль>$<хеш>
 func GetPasswordHash(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
-	return string(bytes), err
+	p := GetArgon2Params()
+
+	salt := make([]byte, p.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+
+	return fmt.Sprintf(
+		"%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix,
+		argon2.Version,
+		p.Memory,
+		p.Iterations,
+		p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
 }
 
 // CheckPasswordHash проверка пароля с хешем
+// кроме Argon2id поддерживаются хеши bcrypt, сохраненные до перехода на Argon2id
 func CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+	if !strings.HasPrefix(hash, argon2idPrefix) {
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		return err == nil
+	}
+
+	p, salt, key, err := decodeArgon2Hash(hash)
+	if err != nil {
+		return false
+	}
+	actual := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return subtle.ConstantTimeCompare(actual, key) == 1
+}
+
+// NeedsRehash хеш нужно пересчитать: он получен bcrypt или с параметрами, отличными от текущих
+func NeedsRehash(hash string) bool {
+	p, _, _, err := decodeArgon2Hash(hash)
+	if err != nil {
+		return true
+	}
+	current := GetArgon2Params()
+	return p.Memory != current.Memory ||
+		p.Iterations != current.Iterations ||
+		p.Parallelism != current.Parallelism ||
+		p.KeyLength != current.KeyLength
+}
+
+// decodeArgon2Hash разбор хеша Argon2id в формате PHC
+func decodeArgon2Hash(hash string) (Argon2Params, []byte, []byte, error) {
+	var p Argon2Params
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, nil, nil, errors.New("not an argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return p, nil, nil, fmt.Errorf("invalid argon2id version: %w", err)
+	}
+	if version != argon2.Version {
+		return p, nil, nil, fmt.Errorf("unsupported argon2id version %d", version)
+	}
+
+	_, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism)
+	if err != nil {
+		return p, nil, nil, fmt.Errorf("invalid argon2id parameters: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, fmt.Errorf("invalid argon2id salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return p, nil, nil, fmt.Errorf("invalid argon2id hash: %w", err)
+	}
+	if len(key) == 0 {
+		return p, nil, nil, errors.New("empty argon2id hash")
+	}
+
+	p.SaltLength = uint32(len(salt))
+	p.KeyLength = uint32(len(key))
+	return p, salt, key, nil
 }
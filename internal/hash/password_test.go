@@ -1,9 +1,12 @@
 package hash
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestCheckPasswordHash(t *testing.T) {
@@ -65,9 +68,10 @@ func TestGetPasswordHash(t *testing.T) {
 			wantErr:  false,
 		},
 		{
+			// bcrypt отклонял пароли длиннее 72 байт, Argon2id длину не ограничивает
 			name:     "long password",
 			password: "verylongpasswordtestingmorethan72bytes_verylongpasswordtestingmorethan72bytes",
-			wantErr:  true,
+			wantErr:  false,
 		},
 	}
 
@@ -86,3 +90,58 @@ func TestGetPasswordHash(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPasswordHash_PHC(t *testing.T) {
+	hash, err := GetPasswordHash("test123")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=65536,t=3,p=4$"))
+
+	// соль случайная, хеши одного пароля различаются
+	other, err := GetPasswordHash("test123")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other)
+}
+
+func TestCheckPasswordHash_Legacy(t *testing.T) {
+	legacy, err := bcrypt.GenerateFromPassword([]byte("test123"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	assert.True(t, CheckPasswordHash("test123", string(legacy)))
+	assert.False(t, CheckPasswordHash("wrongpass", string(legacy)))
+	assert.True(t, NeedsRehash(string(legacy)))
+}
+
+func TestCheckPasswordHash_Malformed(t *testing.T) {
+	tests := []string{
+		"",
+		"$argon2id$v=19$m=65536,t=3,p=4$c2FsdA",
+		"$argon2id$v=18$m=65536,t=3,p=4$c2FsdHNhbHQ$aGFzaA",
+		"$argon2id$v=19$m=x,t=3,p=4$c2FsdHNhbHQ$aGFzaA",
+		"$argon2id$v=19$m=65536,t=3,p=4$!!!$aGFzaA",
+		"$argon2id$v=19$m=65536,t=3,p=4$c2FsdHNhbHQ$",
+	}
+	for _, hash := range tests {
+		assert.False(t, CheckPasswordHash("test123", hash), hash)
+		assert.True(t, NeedsRehash(hash), hash)
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	hash, err := GetPasswordHash("test123")
+	require.NoError(t, err)
+	assert.False(t, NeedsRehash(hash))
+
+	defer SetArgon2Params(GetArgon2Params())
+	stronger := DefaultArgon2Params
+	stronger.Iterations = 4
+	SetArgon2Params(stronger)
+
+	// старые параметры по-прежнему проверяются, но хеш нужно пересчитать
+	assert.True(t, CheckPasswordHash("test123", hash))
+	assert.True(t, NeedsRehash(hash))
+
+	rehashed, err := GetPasswordHash("test123")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(rehashed, "$argon2id$v=19$m=65536,t=4,p=4$"))
+	assert.False(t, NeedsRehash(rehashed))
+}
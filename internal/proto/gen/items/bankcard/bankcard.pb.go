@@ -24,17 +24,19 @@ const (
 
 // Запросы
 type CreateCardDataRequest struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	Number          string                 `protobuf:"bytes,1,opt,name=number,proto3" json:"number,omitempty"`                                             // Номер (будет зашифрован)
-	ValidUntilYear  int32                  `protobuf:"varint,2,opt,name=valid_until_year,json=validUntilYear,proto3" json:"valid_until_year,omitempty"`    // Годен до(год) (будет зашифрован)
-	ValidUntilMonth int32                  `protobuf:"varint,3,opt,name=valid_until_month,json=validUntilMonth,proto3" json:"valid_until_month,omitempty"` // Годен до(месяц)
-	Cvv             int32                  `protobuf:"varint,4,opt,name=cvv,proto3" json:"cvv,omitempty"`                                                  // Код
-	Holder          string                 `protobuf:"bytes,5,opt,name=holder,proto3" json:"holder,omitempty"`                                             // Держатель
-	Description     string                 `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`                                   // Описание
-	MetaDataName    string                 `protobuf:"bytes,7,opt,name=meta_data_name,json=metaDataName,proto3" json:"meta_data_name,omitempty"`           // Название метаданных
-	MetaDataValue   string                 `protobuf:"bytes,8,opt,name=meta_data_value,json=metaDataValue,proto3" json:"meta_data_value,omitempty"`        // Значение метаданных
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Number           string                 `protobuf:"bytes,1,opt,name=number,proto3" json:"number,omitempty"`                                             // Номер (будет зашифрован)
+	ValidUntilYear   int32                  `protobuf:"varint,2,opt,name=valid_until_year,json=validUntilYear,proto3" json:"valid_until_year,omitempty"`    // Годен до(год) (будет зашифрован)
+	ValidUntilMonth  int32                  `protobuf:"varint,3,opt,name=valid_until_month,json=validUntilMonth,proto3" json:"valid_until_month,omitempty"` // Годен до(месяц)
+	Cvv              int32                  `protobuf:"varint,4,opt,name=cvv,proto3" json:"cvv,omitempty"`                                                  // Код
+	Holder           string                 `protobuf:"bytes,5,opt,name=holder,proto3" json:"holder,omitempty"`                                             // Держатель
+	Description      string                 `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`                                   // Описание
+	MetaDataName     string                 `protobuf:"bytes,7,opt,name=meta_data_name,json=metaDataName,proto3" json:"meta_data_name,omitempty"`           // Название метаданных
+	MetaDataValue    string                 `protobuf:"bytes,8,opt,name=meta_data_value,json=metaDataValue,proto3" json:"meta_data_value,omitempty"`        // Значение метаданных
+	EncryptedPayload []byte                 `protobuf:"bytes,9,opt,name=encrypted_payload,json=encryptedPayload,proto3" json:"encrypted_payload,omitempty"` // Данные, зашифрованные на клиенте (вместо реквизитов карты)
+	Id               int64                  `protobuf:"varint,10,opt,name=id,proto3" json:"id,omitempty"`                                                   // Зарезервированный идентификатор, обязателен вместе с encrypted_payload
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *CreateCardDataRequest) Reset() {
@@ -123,6 +125,20 @@ func (x *CreateCardDataRequest) GetMetaDataValue() string {
 	return ""
 }
 
+func (x *CreateCardDataRequest) GetEncryptedPayload() []byte {
+	if x != nil {
+		return x.EncryptedPayload
+	}
+	return nil
+}
+
+func (x *CreateCardDataRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
 type ListCardsDataRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Page          int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`                      // Какая страница
@@ -228,16 +244,17 @@ func (x *GetCardDataRequest) GetId() int64 {
 }
 
 type UpdateCardDataRequest struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	Id              int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Number          string                 `protobuf:"bytes,2,opt,name=number,proto3" json:"number,omitempty"`                                             // Номер (будет зашифрован)
-	ValidUntilYear  int32                  `protobuf:"varint,3,opt,name=valid_until_year,json=validUntilYear,proto3" json:"valid_until_year,omitempty"`    // Годен до(год) (будет зашифрован)
-	ValidUntilMonth int32                  `protobuf:"varint,4,opt,name=valid_until_month,json=validUntilMonth,proto3" json:"valid_until_month,omitempty"` // Годен до(месяц)
-	Cvv             int32                  `protobuf:"varint,5,opt,name=cvv,proto3" json:"cvv,omitempty"`                                                  // Код
-	Holder          string                 `protobuf:"bytes,6,opt,name=holder,proto3" json:"holder,omitempty"`                                             // Держатель
-	Description     string                 `protobuf:"bytes,7,opt,name=description,proto3" json:"description,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Number           string                 `protobuf:"bytes,2,opt,name=number,proto3" json:"number,omitempty"`                                             // Номер (будет зашифрован)
+	ValidUntilYear   int32                  `protobuf:"varint,3,opt,name=valid_until_year,json=validUntilYear,proto3" json:"valid_until_year,omitempty"`    // Годен до(год) (будет зашифрован)
+	ValidUntilMonth  int32                  `protobuf:"varint,4,opt,name=valid_until_month,json=validUntilMonth,proto3" json:"valid_until_month,omitempty"` // Годен до(месяц)
+	Cvv              int32                  `protobuf:"varint,5,opt,name=cvv,proto3" json:"cvv,omitempty"`                                                  // Код
+	Holder           string                 `protobuf:"bytes,6,opt,name=holder,proto3" json:"holder,omitempty"`                                             // Держатель
+	Description      string                 `protobuf:"bytes,7,opt,name=description,proto3" json:"description,omitempty"`
+	EncryptedPayload []byte                 `protobuf:"bytes,8,opt,name=encrypted_payload,json=encryptedPayload,proto3" json:"encrypted_payload,omitempty"` // Данные, зашифрованные на клиенте (вместо реквизитов карты)
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *UpdateCardDataRequest) Reset() {
@@ -319,6 +336,13 @@ func (x *UpdateCardDataRequest) GetDescription() string {
 	return ""
 }
 
+func (x *UpdateCardDataRequest) GetEncryptedPayload() []byte {
+	if x != nil {
+		return x.EncryptedPayload
+	}
+	return nil
+}
+
 type DeleteCardDataRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -434,18 +458,19 @@ func (x *ListCardsDataResponse) GetCurrentPage() int32 {
 
 // Основная сущность
 type CardDataItem struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	Id              int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`                                                    // Идентификатор
-	Number          string                 `protobuf:"bytes,2,opt,name=number,proto3" json:"number,omitempty"`                                             // Номер (будет зашифрован)
-	ValidUntilYear  int32                  `protobuf:"varint,3,opt,name=valid_until_year,json=validUntilYear,proto3" json:"valid_until_year,omitempty"`    // Годен до(год) (будет зашифрован)
-	ValidUntilMonth int32                  `protobuf:"varint,4,opt,name=valid_until_month,json=validUntilMonth,proto3" json:"valid_until_month,omitempty"` // Годен до(месяц)
-	Cvv             int32                  `protobuf:"varint,5,opt,name=cvv,proto3" json:"cvv,omitempty"`                                                  // Код
-	Holder          string                 `protobuf:"bytes,6,opt,name=holder,proto3" json:"holder,omitempty"`                                             // Держатель
-	Description     string                 `protobuf:"bytes,7,opt,name=description,proto3" json:"description,omitempty"`                                   // Описание
-	CreatedAt       string                 `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`                      // Дата создания
-	MetaData        []*MetaData            `protobuf:"bytes,9,rep,name=meta_data,json=metaData,proto3" json:"meta_data,omitempty"`                         // Список метаданных
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`                                                     // Идентификатор
+	Number           string                 `protobuf:"bytes,2,opt,name=number,proto3" json:"number,omitempty"`                                              // Номер (будет зашифрован)
+	ValidUntilYear   int32                  `protobuf:"varint,3,opt,name=valid_until_year,json=validUntilYear,proto3" json:"valid_until_year,omitempty"`     // Годен до(год) (будет зашифрован)
+	ValidUntilMonth  int32                  `protobuf:"varint,4,opt,name=valid_until_month,json=validUntilMonth,proto3" json:"valid_until_month,omitempty"`  // Годен до(месяц)
+	Cvv              int32                  `protobuf:"varint,5,opt,name=cvv,proto3" json:"cvv,omitempty"`                                                   // Код
+	Holder           string                 `protobuf:"bytes,6,opt,name=holder,proto3" json:"holder,omitempty"`                                              // Держатель
+	Description      string                 `protobuf:"bytes,7,opt,name=description,proto3" json:"description,omitempty"`                                    // Описание
+	CreatedAt        string                 `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`                       // Дата создания
+	MetaData         []*MetaData            `protobuf:"bytes,9,rep,name=meta_data,json=metaData,proto3" json:"meta_data,omitempty"`                          // Список метаданных
+	EncryptedPayload []byte                 `protobuf:"bytes,10,opt,name=encrypted_payload,json=encryptedPayload,proto3" json:"encrypted_payload,omitempty"` // Данные, зашифрованные на клиенте
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *CardDataItem) Reset() {
@@ -541,6 +566,13 @@ func (x *CardDataItem) GetMetaData() []*MetaData {
 	return nil
 }
 
+func (x *CardDataItem) GetEncryptedPayload() []byte {
+	if x != nil {
+		return x.EncryptedPayload
+	}
+	return nil
+}
+
 type MetaData struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`      // Идентификатор
@@ -601,11 +633,56 @@ func (x *MetaData) GetValue() string {
 	return ""
 }
 
+// Зарезервированный идентификатор записи
+type ReservedID struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReservedID) Reset() {
+	*x = ReservedID{}
+	mi := &file_internal_proto_items_bankcard_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReservedID) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReservedID) ProtoMessage() {}
+
+func (x *ReservedID) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_items_bankcard_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReservedID.ProtoReflect.Descriptor instead.
+func (*ReservedID) Descriptor() ([]byte, []int) {
+	return file_internal_proto_items_bankcard_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ReservedID) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
 var File_internal_proto_items_bankcard_proto protoreflect.FileDescriptor
 
 const file_internal_proto_items_bankcard_proto_rawDesc = "" +
 	"\n" +
-	"#internal/proto/items/bankcard.proto\x12\x0eitems.bankcard\x1a\x1bgoogle/protobuf/empty.proto\"\x9f\x02\n" +
+	"#internal/proto/items/bankcard.proto\x12\x0eitems.bankcard\x1a\x1bgoogle/protobuf/empty.proto\"\xdc\x02\n" +
 	"\x15CreateCardDataRequest\x12\x16\n" +
 	"\x06number\x18\x01 \x01(\tR\x06number\x12(\n" +
 	"\x10valid_until_year\x18\x02 \x01(\x05R\x0evalidUntilYear\x12*\n" +
@@ -614,13 +691,16 @@ const file_internal_proto_items_bankcard_proto_rawDesc = "" +
 	"\x06holder\x18\x05 \x01(\tR\x06holder\x12 \n" +
 	"\vdescription\x18\x06 \x01(\tR\vdescription\x12$\n" +
 	"\x0emeta_data_name\x18\a \x01(\tR\fmetaDataName\x12&\n" +
-	"\x0fmeta_data_value\x18\b \x01(\tR\rmetaDataValue\"]\n" +
+	"\x0fmeta_data_value\x18\b \x01(\tR\rmetaDataValue\x12+\n" +
+	"\x11encrypted_payload\x18\t \x01(\fR\x10encryptedPayload\x12\x0e\n" +
+	"\x02id\x18\n" +
+	" \x01(\x03R\x02id\"]\n" +
 	"\x14ListCardsDataRequest\x12\x12\n" +
 	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x19\n" +
 	"\bper_page\x18\x02 \x01(\x05R\aperPage\x12\x16\n" +
 	"\x06filter\x18\x03 \x01(\tR\x06filter\"$\n" +
 	"\x12GetCardDataRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x03R\x02id\"\xe1\x01\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x8e\x02\n" +
 	"\x15UpdateCardDataRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x16\n" +
 	"\x06number\x18\x02 \x01(\tR\x06number\x12(\n" +
@@ -628,7 +708,8 @@ const file_internal_proto_items_bankcard_proto_rawDesc = "" +
 	"\x11valid_until_month\x18\x04 \x01(\x05R\x0fvalidUntilMonth\x12\x10\n" +
 	"\x03cvv\x18\x05 \x01(\x05R\x03cvv\x12\x16\n" +
 	"\x06holder\x18\x06 \x01(\tR\x06holder\x12 \n" +
-	"\vdescription\x18\a \x01(\tR\vdescription\"'\n" +
+	"\vdescription\x18\a \x01(\tR\vdescription\x12+\n" +
+	"\x11encrypted_payload\x18\b \x01(\fR\x10encryptedPayload\"'\n" +
 	"\x15DeleteCardDataRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\"\xb0\x01\n" +
 	"\x15ListCardsDataResponse\x122\n" +
@@ -637,7 +718,7 @@ const file_internal_proto_items_bankcard_proto_rawDesc = "" +
 	"totalCount\x12\x1f\n" +
 	"\vtotal_pages\x18\x03 \x01(\x05R\n" +
 	"totalPages\x12!\n" +
-	"\fcurrent_page\x18\x04 \x01(\x05R\vcurrentPage\"\xae\x02\n" +
+	"\fcurrent_page\x18\x04 \x01(\x05R\vcurrentPage\"\xdb\x02\n" +
 	"\fCardDataItem\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x16\n" +
 	"\x06number\x18\x02 \x01(\tR\x06number\x12(\n" +
@@ -648,13 +729,19 @@ const file_internal_proto_items_bankcard_proto_rawDesc = "" +
 	"\vdescription\x18\a \x01(\tR\vdescription\x12\x1d\n" +
 	"\n" +
 	"created_at\x18\b \x01(\tR\tcreatedAt\x125\n" +
-	"\tmeta_data\x18\t \x03(\v2\x18.items.bankcard.MetaDataR\bmetaData\"D\n" +
+	"\tmeta_data\x18\t \x03(\v2\x18.items.bankcard.MetaDataR\bmetaData\x12+\n" +
+	"\x11encrypted_payload\x18\n" +
+	" \x01(\fR\x10encryptedPayload\"D\n" +
 	"\bMetaData\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
-	"\x05value\x18\x03 \x01(\tR\x05value2\xb7\x03\n" +
+	"\x05value\x18\x03 \x01(\tR\x05value\"\x1c\n" +
+	"\n" +
+	"ReservedID\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id2\x80\x04\n" +
 	"\aService\x12U\n" +
-	"\x0eCreateCardData\x12%.items.bankcard.CreateCardDataRequest\x1a\x1c.items.bankcard.CardDataItem\x12O\n" +
+	"\x0eCreateCardData\x12%.items.bankcard.CreateCardDataRequest\x1a\x1c.items.bankcard.CardDataItem\x12G\n" +
+	"\x11ReserveCardDataID\x12\x16.google.protobuf.Empty\x1a\x1a.items.bankcard.ReservedID\x12O\n" +
 	"\vGetCardData\x12\".items.bankcard.GetCardDataRequest\x1a\x1c.items.bankcard.CardDataItem\x12\\\n" +
 	"\rListCardsData\x12$.items.bankcard.ListCardsDataRequest\x1a%.items.bankcard.ListCardsDataResponse\x12U\n" +
 	"\x0eUpdateCardData\x12%.items.bankcard.UpdateCardDataRequest\x1a\x1c.items.bankcard.CardDataItem\x12O\n" +
@@ -672,7 +759,7 @@ func file_internal_proto_items_bankcard_proto_rawDescGZIP() []byte {
 	return file_internal_proto_items_bankcard_proto_rawDescData
 }
 
-var file_internal_proto_items_bankcard_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_internal_proto_items_bankcard_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_internal_proto_items_bankcard_proto_goTypes = []any{
 	(*CreateCardDataRequest)(nil), // 0: items.bankcard.CreateCardDataRequest
 	(*ListCardsDataRequest)(nil),  // 1: items.bankcard.ListCardsDataRequest
@@ -682,23 +769,26 @@ var file_internal_proto_items_bankcard_proto_goTypes = []any{
 	(*ListCardsDataResponse)(nil), // 5: items.bankcard.ListCardsDataResponse
 	(*CardDataItem)(nil),          // 6: items.bankcard.CardDataItem
 	(*MetaData)(nil),              // 7: items.bankcard.MetaData
-	(*emptypb.Empty)(nil),         // 8: google.protobuf.Empty
+	(*ReservedID)(nil),            // 8: items.bankcard.ReservedID
+	(*emptypb.Empty)(nil),         // 9: google.protobuf.Empty
 }
 var file_internal_proto_items_bankcard_proto_depIdxs = []int32{
 	6, // 0: items.bankcard.ListCardsDataResponse.cards:type_name -> items.bankcard.CardDataItem
 	7, // 1: items.bankcard.CardDataItem.meta_data:type_name -> items.bankcard.MetaData
 	0, // 2: items.bankcard.Service.CreateCardData:input_type -> items.bankcard.CreateCardDataRequest
-	2, // 3: items.bankcard.Service.GetCardData:input_type -> items.bankcard.GetCardDataRequest
-	1, // 4: items.bankcard.Service.ListCardsData:input_type -> items.bankcard.ListCardsDataRequest
-	3, // 5: items.bankcard.Service.UpdateCardData:input_type -> items.bankcard.UpdateCardDataRequest
-	4, // 6: items.bankcard.Service.DeleteCardData:input_type -> items.bankcard.DeleteCardDataRequest
-	6, // 7: items.bankcard.Service.CreateCardData:output_type -> items.bankcard.CardDataItem
-	6, // 8: items.bankcard.Service.GetCardData:output_type -> items.bankcard.CardDataItem
-	5, // 9: items.bankcard.Service.ListCardsData:output_type -> items.bankcard.ListCardsDataResponse
-	6, // 10: items.bankcard.Service.UpdateCardData:output_type -> items.bankcard.CardDataItem
-	8, // 11: items.bankcard.Service.DeleteCardData:output_type -> google.protobuf.Empty
-	7, // [7:12] is the sub-list for method output_type
-	2, // [2:7] is the sub-list for method input_type
+	9, // 3: items.bankcard.Service.ReserveCardDataID:input_type -> google.protobuf.Empty
+	2, // 4: items.bankcard.Service.GetCardData:input_type -> items.bankcard.GetCardDataRequest
+	1, // 5: items.bankcard.Service.ListCardsData:input_type -> items.bankcard.ListCardsDataRequest
+	3, // 6: items.bankcard.Service.UpdateCardData:input_type -> items.bankcard.UpdateCardDataRequest
+	4, // 7: items.bankcard.Service.DeleteCardData:input_type -> items.bankcard.DeleteCardDataRequest
+	6, // 8: items.bankcard.Service.CreateCardData:output_type -> items.bankcard.CardDataItem
+	8, // 9: items.bankcard.Service.ReserveCardDataID:output_type -> items.bankcard.ReservedID
+	6, // 10: items.bankcard.Service.GetCardData:output_type -> items.bankcard.CardDataItem
+	5, // 11: items.bankcard.Service.ListCardsData:output_type -> items.bankcard.ListCardsDataResponse
+	6, // 12: items.bankcard.Service.UpdateCardData:output_type -> items.bankcard.CardDataItem
+	9, // 13: items.bankcard.Service.DeleteCardData:output_type -> google.protobuf.Empty
+	8, // [8:14] is the sub-list for method output_type
+	2, // [2:8] is the sub-list for method input_type
 	2, // [2:2] is the sub-list for extension type_name
 	2, // [2:2] is the sub-list for extension extendee
 	0, // [0:2] is the sub-list for field type_name
@@ -715,7 +805,7 @@ func file_internal_proto_items_bankcard_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_proto_items_bankcard_proto_rawDesc), len(file_internal_proto_items_bankcard_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   8,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -20,11 +20,12 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Service_CreateCardData_FullMethodName = "/items.bankcard.Service/CreateCardData"
-	Service_GetCardData_FullMethodName    = "/items.bankcard.Service/GetCardData"
-	Service_ListCardsData_FullMethodName  = "/items.bankcard.Service/ListCardsData"
-	Service_UpdateCardData_FullMethodName = "/items.bankcard.Service/UpdateCardData"
-	Service_DeleteCardData_FullMethodName = "/items.bankcard.Service/DeleteCardData"
+	Service_CreateCardData_FullMethodName    = "/items.bankcard.Service/CreateCardData"
+	Service_ReserveCardDataID_FullMethodName = "/items.bankcard.Service/ReserveCardDataID"
+	Service_GetCardData_FullMethodName       = "/items.bankcard.Service/GetCardData"
+	Service_ListCardsData_FullMethodName     = "/items.bankcard.Service/ListCardsData"
+	Service_UpdateCardData_FullMethodName    = "/items.bankcard.Service/UpdateCardData"
+	Service_DeleteCardData_FullMethodName    = "/items.bankcard.Service/DeleteCardData"
 )
 
 // ServiceClient is the client API for Service service.
@@ -33,6 +34,8 @@ const (
 type ServiceClient interface {
 	// Создание
 	CreateCardData(ctx context.Context, in *CreateCardDataRequest, opts ...grpc.CallOption) (*CardDataItem, error)
+	// Резервирование идентификатора записи для данных, зашифрованных на клиенте
+	ReserveCardDataID(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ReservedID, error)
 	// Получение по ID
 	GetCardData(ctx context.Context, in *GetCardDataRequest, opts ...grpc.CallOption) (*CardDataItem, error)
 	// Получение списка
@@ -61,6 +64,16 @@ func (c *serviceClient) CreateCardData(ctx context.Context, in *CreateCardDataRe
 	return out, nil
 }
 
+func (c *serviceClient) ReserveCardDataID(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ReservedID, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReservedID)
+	err := c.cc.Invoke(ctx, Service_ReserveCardDataID_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *serviceClient) GetCardData(ctx context.Context, in *GetCardDataRequest, opts ...grpc.CallOption) (*CardDataItem, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(CardDataItem)
@@ -107,6 +120,8 @@ func (c *serviceClient) DeleteCardData(ctx context.Context, in *DeleteCardDataRe
 type ServiceServer interface {
 	// Создание
 	CreateCardData(context.Context, *CreateCardDataRequest) (*CardDataItem, error)
+	// Резервирование идентификатора записи для данных, зашифрованных на клиенте
+	ReserveCardDataID(context.Context, *emptypb.Empty) (*ReservedID, error)
 	// Получение по ID
 	GetCardData(context.Context, *GetCardDataRequest) (*CardDataItem, error)
 	// Получение списка
@@ -128,6 +143,9 @@ type UnimplementedServiceServer struct{}
 func (UnimplementedServiceServer) CreateCardData(context.Context, *CreateCardDataRequest) (*CardDataItem, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateCardData not implemented")
 }
+func (UnimplementedServiceServer) ReserveCardDataID(context.Context, *emptypb.Empty) (*ReservedID, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReserveCardDataID not implemented")
+}
 func (UnimplementedServiceServer) GetCardData(context.Context, *GetCardDataRequest) (*CardDataItem, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetCardData not implemented")
 }
@@ -179,6 +197,24 @@ func _Service_CreateCardData_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Service_ReserveCardDataID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).ReserveCardDataID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_ReserveCardDataID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).ReserveCardDataID(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Service_GetCardData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetCardDataRequest)
 	if err := dec(in); err != nil {
@@ -262,6 +298,10 @@ var Service_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreateCardData",
 			Handler:    _Service_CreateCardData_Handler,
 		},
+		{
+			MethodName: "ReserveCardDataID",
+			Handler:    _Service_ReserveCardDataID_Handler,
+		},
 		{
 			MethodName: "GetCardData",
 			Handler:    _Service_GetCardData_Handler,
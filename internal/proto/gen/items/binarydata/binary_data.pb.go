@@ -9,6 +9,7 @@ package binarydata
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -105,15 +106,17 @@ func (*UploadFileRequest_Metadata) isUploadFileRequest_Data() {}
 func (*UploadFileRequest_Chunk) isUploadFileRequest_Data() {}
 
 type FileMetadata struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
-	MimeType      string                 `protobuf:"bytes,2,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
-	OriginalSize  int64                  `protobuf:"varint,3,opt,name=original_size,json=originalSize,proto3" json:"original_size,omitempty"`
-	Description   string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
-	ChunkSize     int32                  `protobuf:"varint,5,opt,name=chunk_size,json=chunkSize,proto3" json:"chunk_size,omitempty"`
-	TotalChunks   int32                  `protobuf:"varint,6,opt,name=total_chunks,json=totalChunks,proto3" json:"total_chunks,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Filename        string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	MimeType        string                 `protobuf:"bytes,2,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	OriginalSize    int64                  `protobuf:"varint,3,opt,name=original_size,json=originalSize,proto3" json:"original_size,omitempty"`
+	Description     string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	ChunkSize       int32                  `protobuf:"varint,5,opt,name=chunk_size,json=chunkSize,proto3" json:"chunk_size,omitempty"`
+	TotalChunks     int32                  `protobuf:"varint,6,opt,name=total_chunks,json=totalChunks,proto3" json:"total_chunks,omitempty"`
+	ClientEncrypted bool                   `protobuf:"varint,7,opt,name=client_encrypted,json=clientEncrypted,proto3" json:"client_encrypted,omitempty"` // Части файла зашифрованы на клиенте
+	FileId          int64                  `protobuf:"varint,8,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`                            // Зарезервированный идентификатор, обязателен вместе с client_encrypted
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *FileMetadata) Reset() {
@@ -188,6 +191,20 @@ func (x *FileMetadata) GetTotalChunks() int32 {
 	return 0
 }
 
+func (x *FileMetadata) GetClientEncrypted() bool {
+	if x != nil {
+		return x.ClientEncrypted
+	}
+	return false
+}
+
+func (x *FileMetadata) GetFileId() int64 {
+	if x != nil {
+		return x.FileId
+	}
+	return 0
+}
+
 type FileChunk struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
@@ -497,15 +514,16 @@ func (x *ListFilesRequest) GetFilter() string {
 }
 
 type FileListItem struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Filename      string                 `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`
-	MimeType      string                 `protobuf:"bytes,3,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
-	Size          int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
-	CreatedAt     string                 `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	Description   string                 `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Filename        string                 `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`
+	MimeType        string                 `protobuf:"bytes,3,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	Size            int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	CreatedAt       string                 `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Description     string                 `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`
+	ClientEncrypted bool                   `protobuf:"varint,7,opt,name=client_encrypted,json=clientEncrypted,proto3" json:"client_encrypted,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *FileListItem) Reset() {
@@ -580,6 +598,13 @@ func (x *FileListItem) GetDescription() string {
 	return ""
 }
 
+func (x *FileListItem) GetClientEncrypted() bool {
+	if x != nil {
+		return x.ClientEncrypted
+	}
+	return false
+}
+
 type ListFilesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Files         []*FileListItem        `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
@@ -842,16 +867,17 @@ func (x *GetFileInfoRequest) GetFileId() int64 {
 }
 
 type FileInfoItem struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Filename      string                 `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`
-	MimeType      string                 `protobuf:"bytes,3,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
-	Size          int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
-	CreatedAt     string                 `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	Description   string                 `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`
-	MetaData      []*MetaData            `protobuf:"bytes,7,rep,name=meta_data,json=metaData,proto3" json:"meta_data,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Filename        string                 `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`
+	MimeType        string                 `protobuf:"bytes,3,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	Size            int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	CreatedAt       string                 `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Description     string                 `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`
+	MetaData        []*MetaData            `protobuf:"bytes,7,rep,name=meta_data,json=metaData,proto3" json:"meta_data,omitempty"`
+	ClientEncrypted bool                   `protobuf:"varint,8,opt,name=client_encrypted,json=clientEncrypted,proto3" json:"client_encrypted,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *FileInfoItem) Reset() {
@@ -933,15 +959,67 @@ func (x *FileInfoItem) GetMetaData() []*MetaData {
 	return nil
 }
 
+func (x *FileInfoItem) GetClientEncrypted() bool {
+	if x != nil {
+		return x.ClientEncrypted
+	}
+	return false
+}
+
+// Зарезервированный идентификатор файла
+type ReservedFileID struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        int64                  `protobuf:"varint,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReservedFileID) Reset() {
+	*x = ReservedFileID{}
+	mi := &file_internal_proto_items_binary_data_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReservedFileID) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReservedFileID) ProtoMessage() {}
+
+func (x *ReservedFileID) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_items_binary_data_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReservedFileID.ProtoReflect.Descriptor instead.
+func (*ReservedFileID) Descriptor() ([]byte, []int) {
+	return file_internal_proto_items_binary_data_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ReservedFileID) GetFileId() int64 {
+	if x != nil {
+		return x.FileId
+	}
+	return 0
+}
+
 var File_internal_proto_items_binary_data_proto protoreflect.FileDescriptor
 
 const file_internal_proto_items_binary_data_proto_rawDesc = "" +
 	"\n" +
-	"&internal/proto/items/binary_data.proto\x12\x10items.binarydata\"\x8e\x01\n" +
+	"&internal/proto/items/binary_data.proto\x12\x10items.binarydata\x1a\x1bgoogle/protobuf/empty.proto\"\x8e\x01\n" +
 	"\x11UploadFileRequest\x12<\n" +
 	"\bmetadata\x18\x01 \x01(\v2\x1e.items.binarydata.FileMetadataH\x00R\bmetadata\x123\n" +
 	"\x05chunk\x18\x02 \x01(\v2\x1b.items.binarydata.FileChunkH\x00R\x05chunkB\x06\n" +
-	"\x04data\"\xd0\x01\n" +
+	"\x04data\"\x94\x02\n" +
 	"\fFileMetadata\x12\x1a\n" +
 	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x1b\n" +
 	"\tmime_type\x18\x02 \x01(\tR\bmimeType\x12#\n" +
@@ -949,7 +1027,9 @@ const file_internal_proto_items_binary_data_proto_rawDesc = "" +
 	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x1d\n" +
 	"\n" +
 	"chunk_size\x18\x05 \x01(\x05R\tchunkSize\x12!\n" +
-	"\ftotal_chunks\x18\x06 \x01(\x05R\vtotalChunks\"Y\n" +
+	"\ftotal_chunks\x18\x06 \x01(\x05R\vtotalChunks\x12)\n" +
+	"\x10client_encrypted\x18\a \x01(\bR\x0fclientEncrypted\x12\x17\n" +
+	"\afile_id\x18\b \x01(\x03R\x06fileId\"Y\n" +
 	"\tFileChunk\x12\x12\n" +
 	"\x04data\x18\x01 \x01(\fR\x04data\x12\x1f\n" +
 	"\vchunk_index\x18\x02 \x01(\x05R\n" +
@@ -968,7 +1048,7 @@ const file_internal_proto_items_binary_data_proto_rawDesc = "" +
 	"\x10ListFilesRequest\x12\x12\n" +
 	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x19\n" +
 	"\bper_page\x18\x02 \x01(\x05R\aperPage\x12\x16\n" +
-	"\x06filter\x18\x03 \x01(\tR\x06filter\"\xac\x01\n" +
+	"\x06filter\x18\x03 \x01(\tR\x06filter\"\xd7\x01\n" +
 	"\fFileListItem\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1a\n" +
 	"\bfilename\x18\x02 \x01(\tR\bfilename\x12\x1b\n" +
@@ -976,7 +1056,8 @@ const file_internal_proto_items_binary_data_proto_rawDesc = "" +
 	"\x04size\x18\x04 \x01(\x03R\x04size\x12\x1d\n" +
 	"\n" +
 	"created_at\x18\x05 \x01(\tR\tcreatedAt\x12 \n" +
-	"\vdescription\x18\x06 \x01(\tR\vdescription\"\xae\x01\n" +
+	"\vdescription\x18\x06 \x01(\tR\vdescription\x12)\n" +
+	"\x10client_encrypted\x18\a \x01(\bR\x0fclientEncrypted\"\xae\x01\n" +
 	"\x11ListFilesResponse\x124\n" +
 	"\x05files\x18\x01 \x03(\v2\x1e.items.binarydata.FileListItemR\x05files\x12\x1f\n" +
 	"\vtotal_count\x18\x02 \x01(\x05R\n" +
@@ -993,7 +1074,7 @@ const file_internal_proto_items_binary_data_proto_rawDesc = "" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
 	"\x05value\x18\x03 \x01(\tR\x05value\"-\n" +
 	"\x12GetFileInfoRequest\x12\x17\n" +
-	"\afile_id\x18\x01 \x01(\x03R\x06fileId\"\xe5\x01\n" +
+	"\afile_id\x18\x01 \x01(\x03R\x06fileId\"\x90\x02\n" +
 	"\fFileInfoItem\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1a\n" +
 	"\bfilename\x18\x02 \x01(\tR\bfilename\x12\x1b\n" +
@@ -1002,10 +1083,14 @@ const file_internal_proto_items_binary_data_proto_rawDesc = "" +
 	"\n" +
 	"created_at\x18\x05 \x01(\tR\tcreatedAt\x12 \n" +
 	"\vdescription\x18\x06 \x01(\tR\vdescription\x127\n" +
-	"\tmeta_data\x18\a \x03(\v2\x1a.items.binarydata.MetaDataR\bmetaData2\xc9\x03\n" +
+	"\tmeta_data\x18\a \x03(\v2\x1a.items.binarydata.MetaDataR\bmetaData\x12)\n" +
+	"\x10client_encrypted\x18\b \x01(\bR\x0fclientEncrypted\")\n" +
+	"\x0eReservedFileID\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\x03R\x06fileId2\x94\x04\n" +
 	"\aService\x12Y\n" +
 	"\n" +
-	"UploadFile\x12#.items.binarydata.UploadFileRequest\x1a$.items.binarydata.UploadFileResponse(\x01\x12_\n" +
+	"UploadFile\x12#.items.binarydata.UploadFileRequest\x1a$.items.binarydata.UploadFileResponse(\x01\x12I\n" +
+	"\rReserveFileID\x12\x16.google.protobuf.Empty\x1a .items.binarydata.ReservedFileID\x12_\n" +
 	"\fDownloadFile\x12%.items.binarydata.DownloadFileRequest\x1a&.items.binarydata.DownloadFileResponse0\x01\x12S\n" +
 	"\vGetFileInfo\x12$.items.binarydata.GetFileInfoRequest\x1a\x1e.items.binarydata.FileInfoItem\x12T\n" +
 	"\tListFiles\x12\".items.binarydata.ListFilesRequest\x1a#.items.binarydata.ListFilesResponse\x12W\n" +
@@ -1024,7 +1109,7 @@ func file_internal_proto_items_binary_data_proto_rawDescGZIP() []byte {
 	return file_internal_proto_items_binary_data_proto_rawDescData
 }
 
-var file_internal_proto_items_binary_data_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_internal_proto_items_binary_data_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
 var file_internal_proto_items_binary_data_proto_goTypes = []any{
 	(*UploadFileRequest)(nil),    // 0: items.binarydata.UploadFileRequest
 	(*FileMetadata)(nil),         // 1: items.binarydata.FileMetadata
@@ -1040,6 +1125,8 @@ var file_internal_proto_items_binary_data_proto_goTypes = []any{
 	(*MetaData)(nil),             // 11: items.binarydata.MetaData
 	(*GetFileInfoRequest)(nil),   // 12: items.binarydata.GetFileInfoRequest
 	(*FileInfoItem)(nil),         // 13: items.binarydata.FileInfoItem
+	(*ReservedFileID)(nil),       // 14: items.binarydata.ReservedFileID
+	(*emptypb.Empty)(nil),        // 15: google.protobuf.Empty
 }
 var file_internal_proto_items_binary_data_proto_depIdxs = []int32{
 	1,  // 0: items.binarydata.UploadFileRequest.metadata:type_name -> items.binarydata.FileMetadata
@@ -1049,17 +1136,19 @@ var file_internal_proto_items_binary_data_proto_depIdxs = []int32{
 	7,  // 4: items.binarydata.ListFilesResponse.files:type_name -> items.binarydata.FileListItem
 	11, // 5: items.binarydata.FileInfoItem.meta_data:type_name -> items.binarydata.MetaData
 	0,  // 6: items.binarydata.Service.UploadFile:input_type -> items.binarydata.UploadFileRequest
-	4,  // 7: items.binarydata.Service.DownloadFile:input_type -> items.binarydata.DownloadFileRequest
-	12, // 8: items.binarydata.Service.GetFileInfo:input_type -> items.binarydata.GetFileInfoRequest
-	6,  // 9: items.binarydata.Service.ListFiles:input_type -> items.binarydata.ListFilesRequest
-	9,  // 10: items.binarydata.Service.DeleteFile:input_type -> items.binarydata.DeleteFileRequest
-	3,  // 11: items.binarydata.Service.UploadFile:output_type -> items.binarydata.UploadFileResponse
-	5,  // 12: items.binarydata.Service.DownloadFile:output_type -> items.binarydata.DownloadFileResponse
-	13, // 13: items.binarydata.Service.GetFileInfo:output_type -> items.binarydata.FileInfoItem
-	8,  // 14: items.binarydata.Service.ListFiles:output_type -> items.binarydata.ListFilesResponse
-	10, // 15: items.binarydata.Service.DeleteFile:output_type -> items.binarydata.DeleteFileResponse
-	11, // [11:16] is the sub-list for method output_type
-	6,  // [6:11] is the sub-list for method input_type
+	15, // 7: items.binarydata.Service.ReserveFileID:input_type -> google.protobuf.Empty
+	4,  // 8: items.binarydata.Service.DownloadFile:input_type -> items.binarydata.DownloadFileRequest
+	12, // 9: items.binarydata.Service.GetFileInfo:input_type -> items.binarydata.GetFileInfoRequest
+	6,  // 10: items.binarydata.Service.ListFiles:input_type -> items.binarydata.ListFilesRequest
+	9,  // 11: items.binarydata.Service.DeleteFile:input_type -> items.binarydata.DeleteFileRequest
+	3,  // 12: items.binarydata.Service.UploadFile:output_type -> items.binarydata.UploadFileResponse
+	14, // 13: items.binarydata.Service.ReserveFileID:output_type -> items.binarydata.ReservedFileID
+	5,  // 14: items.binarydata.Service.DownloadFile:output_type -> items.binarydata.DownloadFileResponse
+	13, // 15: items.binarydata.Service.GetFileInfo:output_type -> items.binarydata.FileInfoItem
+	8,  // 16: items.binarydata.Service.ListFiles:output_type -> items.binarydata.ListFilesResponse
+	10, // 17: items.binarydata.Service.DeleteFile:output_type -> items.binarydata.DeleteFileResponse
+	12, // [12:18] is the sub-list for method output_type
+	6,  // [6:12] is the sub-list for method input_type
 	6,  // [6:6] is the sub-list for extension type_name
 	6,  // [6:6] is the sub-list for extension extendee
 	0,  // [0:6] is the sub-list for field type_name
@@ -1084,7 +1173,7 @@ func file_internal_proto_items_binary_data_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_proto_items_binary_data_proto_rawDesc), len(file_internal_proto_items_binary_data_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   14,
+			NumMessages:   15,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
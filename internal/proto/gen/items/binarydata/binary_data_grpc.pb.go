@@ -11,6 +11,7 @@ import (
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
 )
 
 // This is a compile-time assertion to ensure that this generated file
@@ -19,11 +20,12 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Service_UploadFile_FullMethodName   = "/items.binarydata.Service/UploadFile"
-	Service_DownloadFile_FullMethodName = "/items.binarydata.Service/DownloadFile"
-	Service_GetFileInfo_FullMethodName  = "/items.binarydata.Service/GetFileInfo"
-	Service_ListFiles_FullMethodName    = "/items.binarydata.Service/ListFiles"
-	Service_DeleteFile_FullMethodName   = "/items.binarydata.Service/DeleteFile"
+	Service_UploadFile_FullMethodName    = "/items.binarydata.Service/UploadFile"
+	Service_ReserveFileID_FullMethodName = "/items.binarydata.Service/ReserveFileID"
+	Service_DownloadFile_FullMethodName  = "/items.binarydata.Service/DownloadFile"
+	Service_GetFileInfo_FullMethodName   = "/items.binarydata.Service/GetFileInfo"
+	Service_ListFiles_FullMethodName     = "/items.binarydata.Service/ListFiles"
+	Service_DeleteFile_FullMethodName    = "/items.binarydata.Service/DeleteFile"
 )
 
 // ServiceClient is the client API for Service service.
@@ -32,6 +34,8 @@ const (
 type ServiceClient interface {
 	// Stream для загрузки файла (клиент -> сервер)
 	UploadFile(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadFileRequest, UploadFileResponse], error)
+	// Резервирование идентификатора файла, части которого зашифрованы на клиенте
+	ReserveFileID(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ReservedFileID, error)
 	// Stream для скачивания файла (сервер -> клиент)
 	DownloadFile(ctx context.Context, in *DownloadFileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DownloadFileResponse], error)
 	// Получение информации
@@ -63,6 +67,16 @@ func (c *serviceClient) UploadFile(ctx context.Context, opts ...grpc.CallOption)
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type Service_UploadFileClient = grpc.ClientStreamingClient[UploadFileRequest, UploadFileResponse]
 
+func (c *serviceClient) ReserveFileID(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ReservedFileID, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReservedFileID)
+	err := c.cc.Invoke(ctx, Service_ReserveFileID_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *serviceClient) DownloadFile(ctx context.Context, in *DownloadFileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DownloadFileResponse], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	stream, err := c.cc.NewStream(ctx, &Service_ServiceDesc.Streams[1], Service_DownloadFile_FullMethodName, cOpts...)
@@ -118,6 +132,8 @@ func (c *serviceClient) DeleteFile(ctx context.Context, in *DeleteFileRequest, o
 type ServiceServer interface {
 	// Stream для загрузки файла (клиент -> сервер)
 	UploadFile(grpc.ClientStreamingServer[UploadFileRequest, UploadFileResponse]) error
+	// Резервирование идентификатора файла, части которого зашифрованы на клиенте
+	ReserveFileID(context.Context, *emptypb.Empty) (*ReservedFileID, error)
 	// Stream для скачивания файла (сервер -> клиент)
 	DownloadFile(*DownloadFileRequest, grpc.ServerStreamingServer[DownloadFileResponse]) error
 	// Получение информации
@@ -139,6 +155,9 @@ type UnimplementedServiceServer struct{}
 func (UnimplementedServiceServer) UploadFile(grpc.ClientStreamingServer[UploadFileRequest, UploadFileResponse]) error {
 	return status.Errorf(codes.Unimplemented, "method UploadFile not implemented")
 }
+func (UnimplementedServiceServer) ReserveFileID(context.Context, *emptypb.Empty) (*ReservedFileID, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReserveFileID not implemented")
+}
 func (UnimplementedServiceServer) DownloadFile(*DownloadFileRequest, grpc.ServerStreamingServer[DownloadFileResponse]) error {
 	return status.Errorf(codes.Unimplemented, "method DownloadFile not implemented")
 }
@@ -179,6 +198,24 @@ func _Service_UploadFile_Handler(srv interface{}, stream grpc.ServerStream) erro
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type Service_UploadFileServer = grpc.ClientStreamingServer[UploadFileRequest, UploadFileResponse]
 
+func _Service_ReserveFileID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).ReserveFileID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_ReserveFileID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).ReserveFileID(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Service_DownloadFile_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(DownloadFileRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -251,6 +288,10 @@ var Service_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "items.binarydata.Service",
 	HandlerType: (*ServiceServer)(nil),
 	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ReserveFileID",
+			Handler:    _Service_ReserveFileID_Handler,
+		},
 		{
 			MethodName: "GetFileInfo",
 			Handler:    _Service_GetFileInfo_Handler,
@@ -24,15 +24,17 @@ const (
 
 // Запросы
 type CreatePasswordRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Login         string                 `protobuf:"bytes,1,opt,name=login,proto3" json:"login,omitempty"`                                        // Логин (будет зашифрован)
-	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`                                  // Пароль (будет зашифрован)
-	Target        string                 `protobuf:"bytes,3,opt,name=target,proto3" json:"target,omitempty"`                                      // От какой системы/сайта логин/пароль
-	Description   string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`                            // Описание
-	MetaDataName  string                 `protobuf:"bytes,5,opt,name=meta_data_name,json=metaDataName,proto3" json:"meta_data_name,omitempty"`    // Название метаданных
-	MetaDataValue string                 `protobuf:"bytes,6,opt,name=meta_data_value,json=metaDataValue,proto3" json:"meta_data_value,omitempty"` // Значение метаданных
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Login            string                 `protobuf:"bytes,1,opt,name=login,proto3" json:"login,omitempty"`                                               // Логин (будет зашифрован)
+	Password         string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`                                         // Пароль (будет зашифрован)
+	Target           string                 `protobuf:"bytes,3,opt,name=target,proto3" json:"target,omitempty"`                                             // От какой системы/сайта логин/пароль
+	Description      string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`                                   // Описание
+	MetaDataName     string                 `protobuf:"bytes,5,opt,name=meta_data_name,json=metaDataName,proto3" json:"meta_data_name,omitempty"`           // Название метаданных
+	MetaDataValue    string                 `protobuf:"bytes,6,opt,name=meta_data_value,json=metaDataValue,proto3" json:"meta_data_value,omitempty"`        // Значение метаданных
+	EncryptedPayload []byte                 `protobuf:"bytes,7,opt,name=encrypted_payload,json=encryptedPayload,proto3" json:"encrypted_payload,omitempty"` // Данные, зашифрованные на клиенте (вместо login/password/target)
+	Id               int64                  `protobuf:"varint,8,opt,name=id,proto3" json:"id,omitempty"`                                                    // Зарезервированный идентификатор, обязателен вместе с encrypted_payload
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *CreatePasswordRequest) Reset() {
@@ -107,6 +109,20 @@ func (x *CreatePasswordRequest) GetMetaDataValue() string {
 	return ""
 }
 
+func (x *CreatePasswordRequest) GetEncryptedPayload() []byte {
+	if x != nil {
+		return x.EncryptedPayload
+	}
+	return nil
+}
+
+func (x *CreatePasswordRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
 type ListPasswordsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Page          int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`                      // Какая страница
@@ -212,14 +228,15 @@ func (x *GetPasswordRequest) GetId() int64 {
 }
 
 type UpdatePasswordRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Login         string                 `protobuf:"bytes,2,opt,name=login,proto3" json:"login,omitempty"`
-	Password      string                 `protobuf:"bytes,3,opt,name=password,proto3" json:"password,omitempty"`
-	Target        string                 `protobuf:"bytes,4,opt,name=target,proto3" json:"target,omitempty"`
-	Description   string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Login            string                 `protobuf:"bytes,2,opt,name=login,proto3" json:"login,omitempty"`
+	Password         string                 `protobuf:"bytes,3,opt,name=password,proto3" json:"password,omitempty"`
+	Target           string                 `protobuf:"bytes,4,opt,name=target,proto3" json:"target,omitempty"`
+	Description      string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	EncryptedPayload []byte                 `protobuf:"bytes,6,opt,name=encrypted_payload,json=encryptedPayload,proto3" json:"encrypted_payload,omitempty"` // Данные, зашифрованные на клиенте (вместо login/password/target)
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *UpdatePasswordRequest) Reset() {
@@ -287,6 +304,13 @@ func (x *UpdatePasswordRequest) GetDescription() string {
 	return ""
 }
 
+func (x *UpdatePasswordRequest) GetEncryptedPayload() []byte {
+	if x != nil {
+		return x.EncryptedPayload
+	}
+	return nil
+}
+
 type DeletePasswordRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -402,16 +426,17 @@ func (x *ListPasswordsResponse) GetCurrentPage() int32 {
 
 // Основная сущность
 type PasswordItem struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`                               // Идентификатор
-	Login         string                 `protobuf:"bytes,2,opt,name=login,proto3" json:"login,omitempty"`                          // Логин (будет зашифрован)
-	Password      string                 `protobuf:"bytes,3,opt,name=password,proto3" json:"password,omitempty"`                    // Пароль (будет зашифрован)
-	Target        string                 `protobuf:"bytes,4,opt,name=target,proto3" json:"target,omitempty"`                        // От какой системы/сайта логин/пароль
-	Description   string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`              // Описание
-	CreatedAt     string                 `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // Дата создания
-	MetaData      []*MetaData            `protobuf:"bytes,7,rep,name=meta_data,json=metaData,proto3" json:"meta_data,omitempty"`    // Список метаданных
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`                                                    // Идентификатор
+	Login            string                 `protobuf:"bytes,2,opt,name=login,proto3" json:"login,omitempty"`                                               // Логин (будет зашифрован)
+	Password         string                 `protobuf:"bytes,3,opt,name=password,proto3" json:"password,omitempty"`                                         // Пароль (будет зашифрован)
+	Target           string                 `protobuf:"bytes,4,opt,name=target,proto3" json:"target,omitempty"`                                             // От какой системы/сайта логин/пароль
+	Description      string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`                                   // Описание
+	CreatedAt        string                 `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`                      // Дата создания
+	MetaData         []*MetaData            `protobuf:"bytes,7,rep,name=meta_data,json=metaData,proto3" json:"meta_data,omitempty"`                         // Список метаданных
+	EncryptedPayload []byte                 `protobuf:"bytes,8,opt,name=encrypted_payload,json=encryptedPayload,proto3" json:"encrypted_payload,omitempty"` // Данные, зашифрованные на клиенте
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *PasswordItem) Reset() {
@@ -493,6 +518,13 @@ func (x *PasswordItem) GetMetaData() []*MetaData {
 	return nil
 }
 
+func (x *PasswordItem) GetEncryptedPayload() []byte {
+	if x != nil {
+		return x.EncryptedPayload
+	}
+	return nil
+}
+
 type MetaData struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`      // Идентификатор
@@ -553,30 +585,78 @@ func (x *MetaData) GetValue() string {
 	return ""
 }
 
+// Зарезервированный идентификатор записи
+type ReservedID struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReservedID) Reset() {
+	*x = ReservedID{}
+	mi := &file_internal_proto_items_password_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReservedID) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReservedID) ProtoMessage() {}
+
+func (x *ReservedID) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_items_password_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReservedID.ProtoReflect.Descriptor instead.
+func (*ReservedID) Descriptor() ([]byte, []int) {
+	return file_internal_proto_items_password_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ReservedID) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
 var File_internal_proto_items_password_proto protoreflect.FileDescriptor
 
 const file_internal_proto_items_password_proto_rawDesc = "" +
 	"\n" +
-	"#internal/proto/items/password.proto\x12\x0eitems.password\x1a\x1bgoogle/protobuf/empty.proto\"\xd1\x01\n" +
+	"#internal/proto/items/password.proto\x12\x0eitems.password\x1a\x1bgoogle/protobuf/empty.proto\"\x8e\x02\n" +
 	"\x15CreatePasswordRequest\x12\x14\n" +
 	"\x05login\x18\x01 \x01(\tR\x05login\x12\x1a\n" +
 	"\bpassword\x18\x02 \x01(\tR\bpassword\x12\x16\n" +
 	"\x06target\x18\x03 \x01(\tR\x06target\x12 \n" +
 	"\vdescription\x18\x04 \x01(\tR\vdescription\x12$\n" +
 	"\x0emeta_data_name\x18\x05 \x01(\tR\fmetaDataName\x12&\n" +
-	"\x0fmeta_data_value\x18\x06 \x01(\tR\rmetaDataValue\"]\n" +
+	"\x0fmeta_data_value\x18\x06 \x01(\tR\rmetaDataValue\x12+\n" +
+	"\x11encrypted_payload\x18\a \x01(\fR\x10encryptedPayload\x12\x0e\n" +
+	"\x02id\x18\b \x01(\x03R\x02id\"]\n" +
 	"\x14ListPasswordsRequest\x12\x12\n" +
 	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x19\n" +
 	"\bper_page\x18\x02 \x01(\x05R\aperPage\x12\x16\n" +
 	"\x06filter\x18\x03 \x01(\tR\x06filter\"$\n" +
 	"\x12GetPasswordRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x03R\x02id\"\x93\x01\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\xc0\x01\n" +
 	"\x15UpdatePasswordRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
 	"\x05login\x18\x02 \x01(\tR\x05login\x12\x1a\n" +
 	"\bpassword\x18\x03 \x01(\tR\bpassword\x12\x16\n" +
 	"\x06target\x18\x04 \x01(\tR\x06target\x12 \n" +
-	"\vdescription\x18\x05 \x01(\tR\vdescription\"'\n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\x12+\n" +
+	"\x11encrypted_payload\x18\x06 \x01(\fR\x10encryptedPayload\"'\n" +
 	"\x15DeletePasswordRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\"\xb8\x01\n" +
 	"\x15ListPasswordsResponse\x12:\n" +
@@ -585,7 +665,7 @@ const file_internal_proto_items_password_proto_rawDesc = "" +
 	"totalCount\x12\x1f\n" +
 	"\vtotal_pages\x18\x03 \x01(\x05R\n" +
 	"totalPages\x12!\n" +
-	"\fcurrent_page\x18\x04 \x01(\x05R\vcurrentPage\"\xe0\x01\n" +
+	"\fcurrent_page\x18\x04 \x01(\x05R\vcurrentPage\"\x8d\x02\n" +
 	"\fPasswordItem\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
 	"\x05login\x18\x02 \x01(\tR\x05login\x12\x1a\n" +
@@ -594,13 +674,18 @@ const file_internal_proto_items_password_proto_rawDesc = "" +
 	"\vdescription\x18\x05 \x01(\tR\vdescription\x12\x1d\n" +
 	"\n" +
 	"created_at\x18\x06 \x01(\tR\tcreatedAt\x125\n" +
-	"\tmeta_data\x18\a \x03(\v2\x18.items.password.MetaDataR\bmetaData\"D\n" +
+	"\tmeta_data\x18\a \x03(\v2\x18.items.password.MetaDataR\bmetaData\x12+\n" +
+	"\x11encrypted_payload\x18\b \x01(\fR\x10encryptedPayload\"D\n" +
 	"\bMetaData\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
-	"\x05value\x18\x03 \x01(\tR\x05value2\xb7\x03\n" +
+	"\x05value\x18\x03 \x01(\tR\x05value\"\x1c\n" +
+	"\n" +
+	"ReservedID\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id2\x80\x04\n" +
 	"\aService\x12U\n" +
-	"\x0eCreatePassword\x12%.items.password.CreatePasswordRequest\x1a\x1c.items.password.PasswordItem\x12O\n" +
+	"\x0eCreatePassword\x12%.items.password.CreatePasswordRequest\x1a\x1c.items.password.PasswordItem\x12G\n" +
+	"\x11ReservePasswordID\x12\x16.google.protobuf.Empty\x1a\x1a.items.password.ReservedID\x12O\n" +
 	"\vGetPassword\x12\".items.password.GetPasswordRequest\x1a\x1c.items.password.PasswordItem\x12\\\n" +
 	"\rListPasswords\x12$.items.password.ListPasswordsRequest\x1a%.items.password.ListPasswordsResponse\x12U\n" +
 	"\x0eUpdatePassword\x12%.items.password.UpdatePasswordRequest\x1a\x1c.items.password.PasswordItem\x12O\n" +
@@ -618,7 +703,7 @@ func file_internal_proto_items_password_proto_rawDescGZIP() []byte {
 	return file_internal_proto_items_password_proto_rawDescData
 }
 
-var file_internal_proto_items_password_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_internal_proto_items_password_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_internal_proto_items_password_proto_goTypes = []any{
 	(*CreatePasswordRequest)(nil), // 0: items.password.CreatePasswordRequest
 	(*ListPasswordsRequest)(nil),  // 1: items.password.ListPasswordsRequest
@@ -628,23 +713,26 @@ var file_internal_proto_items_password_proto_goTypes = []any{
 	(*ListPasswordsResponse)(nil), // 5: items.password.ListPasswordsResponse
 	(*PasswordItem)(nil),          // 6: items.password.PasswordItem
 	(*MetaData)(nil),              // 7: items.password.MetaData
-	(*emptypb.Empty)(nil),         // 8: google.protobuf.Empty
+	(*ReservedID)(nil),            // 8: items.password.ReservedID
+	(*emptypb.Empty)(nil),         // 9: google.protobuf.Empty
 }
 var file_internal_proto_items_password_proto_depIdxs = []int32{
 	6, // 0: items.password.ListPasswordsResponse.passwords:type_name -> items.password.PasswordItem
 	7, // 1: items.password.PasswordItem.meta_data:type_name -> items.password.MetaData
 	0, // 2: items.password.Service.CreatePassword:input_type -> items.password.CreatePasswordRequest
-	2, // 3: items.password.Service.GetPassword:input_type -> items.password.GetPasswordRequest
-	1, // 4: items.password.Service.ListPasswords:input_type -> items.password.ListPasswordsRequest
-	3, // 5: items.password.Service.UpdatePassword:input_type -> items.password.UpdatePasswordRequest
-	4, // 6: items.password.Service.DeletePassword:input_type -> items.password.DeletePasswordRequest
-	6, // 7: items.password.Service.CreatePassword:output_type -> items.password.PasswordItem
-	6, // 8: items.password.Service.GetPassword:output_type -> items.password.PasswordItem
-	5, // 9: items.password.Service.ListPasswords:output_type -> items.password.ListPasswordsResponse
-	6, // 10: items.password.Service.UpdatePassword:output_type -> items.password.PasswordItem
-	8, // 11: items.password.Service.DeletePassword:output_type -> google.protobuf.Empty
-	7, // [7:12] is the sub-list for method output_type
-	2, // [2:7] is the sub-list for method input_type
+	9, // 3: items.password.Service.ReservePasswordID:input_type -> google.protobuf.Empty
+	2, // 4: items.password.Service.GetPassword:input_type -> items.password.GetPasswordRequest
+	1, // 5: items.password.Service.ListPasswords:input_type -> items.password.ListPasswordsRequest
+	3, // 6: items.password.Service.UpdatePassword:input_type -> items.password.UpdatePasswordRequest
+	4, // 7: items.password.Service.DeletePassword:input_type -> items.password.DeletePasswordRequest
+	6, // 8: items.password.Service.CreatePassword:output_type -> items.password.PasswordItem
+	8, // 9: items.password.Service.ReservePasswordID:output_type -> items.password.ReservedID
+	6, // 10: items.password.Service.GetPassword:output_type -> items.password.PasswordItem
+	5, // 11: items.password.Service.ListPasswords:output_type -> items.password.ListPasswordsResponse
+	6, // 12: items.password.Service.UpdatePassword:output_type -> items.password.PasswordItem
+	9, // 13: items.password.Service.DeletePassword:output_type -> google.protobuf.Empty
+	8, // [8:14] is the sub-list for method output_type
+	2, // [2:8] is the sub-list for method input_type
 	2, // [2:2] is the sub-list for extension type_name
 	2, // [2:2] is the sub-list for extension extendee
 	0, // [0:2] is the sub-list for field type_name
@@ -661,7 +749,7 @@ func file_internal_proto_items_password_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_proto_items_password_proto_rawDesc), len(file_internal_proto_items_password_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   8,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -20,11 +20,12 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Service_CreatePassword_FullMethodName = "/items.password.Service/CreatePassword"
-	Service_GetPassword_FullMethodName    = "/items.password.Service/GetPassword"
-	Service_ListPasswords_FullMethodName  = "/items.password.Service/ListPasswords"
-	Service_UpdatePassword_FullMethodName = "/items.password.Service/UpdatePassword"
-	Service_DeletePassword_FullMethodName = "/items.password.Service/DeletePassword"
+	Service_CreatePassword_FullMethodName    = "/items.password.Service/CreatePassword"
+	Service_ReservePasswordID_FullMethodName = "/items.password.Service/ReservePasswordID"
+	Service_GetPassword_FullMethodName       = "/items.password.Service/GetPassword"
+	Service_ListPasswords_FullMethodName     = "/items.password.Service/ListPasswords"
+	Service_UpdatePassword_FullMethodName    = "/items.password.Service/UpdatePassword"
+	Service_DeletePassword_FullMethodName    = "/items.password.Service/DeletePassword"
 )
 
 // ServiceClient is the client API for Service service.
@@ -33,6 +34,8 @@ const (
 type ServiceClient interface {
 	// Создание нового пароля
 	CreatePassword(ctx context.Context, in *CreatePasswordRequest, opts ...grpc.CallOption) (*PasswordItem, error)
+	// Резервирование идентификатора записи для данных, зашифрованных на клиенте
+	ReservePasswordID(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ReservedID, error)
 	// Получение пароля по ID
 	GetPassword(ctx context.Context, in *GetPasswordRequest, opts ...grpc.CallOption) (*PasswordItem, error)
 	// Получение списка всех паролей пользователя
@@ -61,6 +64,16 @@ func (c *serviceClient) CreatePassword(ctx context.Context, in *CreatePasswordRe
 	return out, nil
 }
 
+func (c *serviceClient) ReservePasswordID(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ReservedID, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReservedID)
+	err := c.cc.Invoke(ctx, Service_ReservePasswordID_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *serviceClient) GetPassword(ctx context.Context, in *GetPasswordRequest, opts ...grpc.CallOption) (*PasswordItem, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(PasswordItem)
@@ -107,6 +120,8 @@ func (c *serviceClient) DeletePassword(ctx context.Context, in *DeletePasswordRe
 type ServiceServer interface {
 	// Создание нового пароля
 	CreatePassword(context.Context, *CreatePasswordRequest) (*PasswordItem, error)
+	// Резервирование идентификатора записи для данных, зашифрованных на клиенте
+	ReservePasswordID(context.Context, *emptypb.Empty) (*ReservedID, error)
 	// Получение пароля по ID
 	GetPassword(context.Context, *GetPasswordRequest) (*PasswordItem, error)
 	// Получение списка всех паролей пользователя
@@ -128,6 +143,9 @@ type UnimplementedServiceServer struct{}
 func (UnimplementedServiceServer) CreatePassword(context.Context, *CreatePasswordRequest) (*PasswordItem, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreatePassword not implemented")
 }
+func (UnimplementedServiceServer) ReservePasswordID(context.Context, *emptypb.Empty) (*ReservedID, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReservePasswordID not implemented")
+}
 func (UnimplementedServiceServer) GetPassword(context.Context, *GetPasswordRequest) (*PasswordItem, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetPassword not implemented")
 }
@@ -179,6 +197,24 @@ func _Service_CreatePassword_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Service_ReservePasswordID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).ReservePasswordID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_ReservePasswordID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).ReservePasswordID(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Service_GetPassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetPasswordRequest)
 	if err := dec(in); err != nil {
@@ -262,6 +298,10 @@ var Service_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreatePassword",
 			Handler:    _Service_CreatePassword_Handler,
 		},
+		{
+			MethodName: "ReservePasswordID",
+			Handler:    _Service_ReservePasswordID_Handler,
+		},
 		{
 			MethodName: "GetPassword",
 			Handler:    _Service_GetPassword_Handler,
@@ -24,13 +24,15 @@ const (
 
 // Запросы
 type CreateTextDataRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	TextData      string                 `protobuf:"bytes,1,opt,name=text_data,json=textData,proto3" json:"text_data,omitempty"`                  // Данные (будут зашифрованы)
-	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`                            // Описание
-	MetaDataName  string                 `protobuf:"bytes,3,opt,name=meta_data_name,json=metaDataName,proto3" json:"meta_data_name,omitempty"`    // Название метаданных
-	MetaDataValue string                 `protobuf:"bytes,4,opt,name=meta_data_value,json=metaDataValue,proto3" json:"meta_data_value,omitempty"` // Значение метаданных
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	TextData         string                 `protobuf:"bytes,1,opt,name=text_data,json=textData,proto3" json:"text_data,omitempty"`                         // Данные (будут зашифрованы)
+	Description      string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`                                   // Описание
+	MetaDataName     string                 `protobuf:"bytes,3,opt,name=meta_data_name,json=metaDataName,proto3" json:"meta_data_name,omitempty"`           // Название метаданных
+	MetaDataValue    string                 `protobuf:"bytes,4,opt,name=meta_data_value,json=metaDataValue,proto3" json:"meta_data_value,omitempty"`        // Значение метаданных
+	EncryptedPayload []byte                 `protobuf:"bytes,5,opt,name=encrypted_payload,json=encryptedPayload,proto3" json:"encrypted_payload,omitempty"` // Данные, зашифрованные на клиенте (вместо text_data)
+	Id               int64                  `protobuf:"varint,6,opt,name=id,proto3" json:"id,omitempty"`                                                    // Зарезервированный идентификатор, обязателен вместе с encrypted_payload
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *CreateTextDataRequest) Reset() {
@@ -91,6 +93,20 @@ func (x *CreateTextDataRequest) GetMetaDataValue() string {
 	return ""
 }
 
+func (x *CreateTextDataRequest) GetEncryptedPayload() []byte {
+	if x != nil {
+		return x.EncryptedPayload
+	}
+	return nil
+}
+
+func (x *CreateTextDataRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
 type ListTextDataRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Page          int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`                      // Какая страница
@@ -196,14 +212,15 @@ func (x *GetTextDataRequest) GetId() int64 {
 }
 
 type UpdateTextDataRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	TextData      string                 `protobuf:"bytes,2,opt,name=text_data,json=textData,proto3" json:"text_data,omitempty"`
-	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	MetaDataName  string                 `protobuf:"bytes,4,opt,name=meta_data_name,json=metaDataName,proto3" json:"meta_data_name,omitempty"`    // Название метаданных
-	MetaDataValue string                 `protobuf:"bytes,5,opt,name=meta_data_value,json=metaDataValue,proto3" json:"meta_data_value,omitempty"` // Значение метаданных
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	TextData         string                 `protobuf:"bytes,2,opt,name=text_data,json=textData,proto3" json:"text_data,omitempty"`
+	Description      string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	MetaDataName     string                 `protobuf:"bytes,4,opt,name=meta_data_name,json=metaDataName,proto3" json:"meta_data_name,omitempty"`           // Название метаданных
+	MetaDataValue    string                 `protobuf:"bytes,5,opt,name=meta_data_value,json=metaDataValue,proto3" json:"meta_data_value,omitempty"`        // Значение метаданных
+	EncryptedPayload []byte                 `protobuf:"bytes,6,opt,name=encrypted_payload,json=encryptedPayload,proto3" json:"encrypted_payload,omitempty"` // Данные, зашифрованные на клиенте (вместо text_data)
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *UpdateTextDataRequest) Reset() {
@@ -271,6 +288,13 @@ func (x *UpdateTextDataRequest) GetMetaDataValue() string {
 	return ""
 }
 
+func (x *UpdateTextDataRequest) GetEncryptedPayload() []byte {
+	if x != nil {
+		return x.EncryptedPayload
+	}
+	return nil
+}
+
 type DeleteTextDataRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -386,14 +410,15 @@ func (x *ListTextDataResponse) GetCurrentPage() int32 {
 
 // Основная сущность
 type TextDataItem struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`                               // Идентификатор
-	TextData      string                 `protobuf:"bytes,2,opt,name=text_data,json=textData,proto3" json:"text_data,omitempty"`    // Данные (будут зашифрованы)
-	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`              // Описание
-	CreatedAt     string                 `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // Дата создания
-	MetaData      []*MetaData            `protobuf:"bytes,5,rep,name=meta_data,json=metaData,proto3" json:"meta_data,omitempty"`    // Список метаданных
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`                                                    // Идентификатор
+	TextData         string                 `protobuf:"bytes,2,opt,name=text_data,json=textData,proto3" json:"text_data,omitempty"`                         // Данные (будут зашифрованы)
+	Description      string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`                                   // Описание
+	CreatedAt        string                 `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`                      // Дата создания
+	MetaData         []*MetaData            `protobuf:"bytes,5,rep,name=meta_data,json=metaData,proto3" json:"meta_data,omitempty"`                         // Список метаданных
+	EncryptedPayload []byte                 `protobuf:"bytes,6,opt,name=encrypted_payload,json=encryptedPayload,proto3" json:"encrypted_payload,omitempty"` // Данные, зашифрованные на клиенте
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *TextDataItem) Reset() {
@@ -461,6 +486,13 @@ func (x *TextDataItem) GetMetaData() []*MetaData {
 	return nil
 }
 
+func (x *TextDataItem) GetEncryptedPayload() []byte {
+	if x != nil {
+		return x.EncryptedPayload
+	}
+	return nil
+}
+
 type MetaData struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`      // Идентификатор
@@ -521,28 +553,76 @@ func (x *MetaData) GetValue() string {
 	return ""
 }
 
+// Зарезервированный идентификатор записи
+type ReservedID struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReservedID) Reset() {
+	*x = ReservedID{}
+	mi := &file_internal_proto_items_text_data_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReservedID) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReservedID) ProtoMessage() {}
+
+func (x *ReservedID) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_items_text_data_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReservedID.ProtoReflect.Descriptor instead.
+func (*ReservedID) Descriptor() ([]byte, []int) {
+	return file_internal_proto_items_text_data_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ReservedID) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
 var File_internal_proto_items_text_data_proto protoreflect.FileDescriptor
 
 const file_internal_proto_items_text_data_proto_rawDesc = "" +
 	"\n" +
-	"$internal/proto/items/text_data.proto\x12\x0eitems.textdata\x1a\x1bgoogle/protobuf/empty.proto\"\xa4\x01\n" +
+	"$internal/proto/items/text_data.proto\x12\x0eitems.textdata\x1a\x1bgoogle/protobuf/empty.proto\"\xe1\x01\n" +
 	"\x15CreateTextDataRequest\x12\x1b\n" +
 	"\ttext_data\x18\x01 \x01(\tR\btextData\x12 \n" +
 	"\vdescription\x18\x02 \x01(\tR\vdescription\x12$\n" +
 	"\x0emeta_data_name\x18\x03 \x01(\tR\fmetaDataName\x12&\n" +
-	"\x0fmeta_data_value\x18\x04 \x01(\tR\rmetaDataValue\"\\\n" +
+	"\x0fmeta_data_value\x18\x04 \x01(\tR\rmetaDataValue\x12+\n" +
+	"\x11encrypted_payload\x18\x05 \x01(\fR\x10encryptedPayload\x12\x0e\n" +
+	"\x02id\x18\x06 \x01(\x03R\x02id\"\\\n" +
 	"\x13ListTextDataRequest\x12\x12\n" +
 	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x19\n" +
 	"\bper_page\x18\x02 \x01(\x05R\aperPage\x12\x16\n" +
 	"\x06filter\x18\x03 \x01(\tR\x06filter\"$\n" +
 	"\x12GetTextDataRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x03R\x02id\"\xb4\x01\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\xe1\x01\n" +
 	"\x15UpdateTextDataRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1b\n" +
 	"\ttext_data\x18\x02 \x01(\tR\btextData\x12 \n" +
 	"\vdescription\x18\x03 \x01(\tR\vdescription\x12$\n" +
 	"\x0emeta_data_name\x18\x04 \x01(\tR\fmetaDataName\x12&\n" +
-	"\x0fmeta_data_value\x18\x05 \x01(\tR\rmetaDataValue\"'\n" +
+	"\x0fmeta_data_value\x18\x05 \x01(\tR\rmetaDataValue\x12+\n" +
+	"\x11encrypted_payload\x18\x06 \x01(\fR\x10encryptedPayload\"'\n" +
 	"\x15DeleteTextDataRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\"\xbf\x01\n" +
 	"\x14ListTextDataResponse\x12B\n" +
@@ -551,20 +631,25 @@ const file_internal_proto_items_text_data_proto_rawDesc = "" +
 	"totalCount\x12\x1f\n" +
 	"\vtotal_pages\x18\x03 \x01(\x05R\n" +
 	"totalPages\x12!\n" +
-	"\fcurrent_page\x18\x04 \x01(\x05R\vcurrentPage\"\xb3\x01\n" +
+	"\fcurrent_page\x18\x04 \x01(\x05R\vcurrentPage\"\xe0\x01\n" +
 	"\fTextDataItem\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1b\n" +
 	"\ttext_data\x18\x02 \x01(\tR\btextData\x12 \n" +
 	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x1d\n" +
 	"\n" +
 	"created_at\x18\x04 \x01(\tR\tcreatedAt\x125\n" +
-	"\tmeta_data\x18\x05 \x03(\v2\x18.items.textdata.MetaDataR\bmetaData\"D\n" +
+	"\tmeta_data\x18\x05 \x03(\v2\x18.items.textdata.MetaDataR\bmetaData\x12+\n" +
+	"\x11encrypted_payload\x18\x06 \x01(\fR\x10encryptedPayload\"D\n" +
 	"\bMetaData\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
-	"\x05value\x18\x03 \x01(\tR\x05value2\xb9\x03\n" +
+	"\x05value\x18\x03 \x01(\tR\x05value\"\x1c\n" +
+	"\n" +
+	"ReservedID\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id2\x82\x04\n" +
 	"\aService\x12U\n" +
-	"\x0eCreateTextData\x12%.items.textdata.CreateTextDataRequest\x1a\x1c.items.textdata.TextDataItem\x12O\n" +
+	"\x0eCreateTextData\x12%.items.textdata.CreateTextDataRequest\x1a\x1c.items.textdata.TextDataItem\x12G\n" +
+	"\x11ReserveTextDataID\x12\x16.google.protobuf.Empty\x1a\x1a.items.textdata.ReservedID\x12O\n" +
 	"\vGetTextData\x12\".items.textdata.GetTextDataRequest\x1a\x1c.items.textdata.TextDataItem\x12^\n" +
 	"\x11ListTextDataItems\x12#.items.textdata.ListTextDataRequest\x1a$.items.textdata.ListTextDataResponse\x12U\n" +
 	"\x0eUpdateTextData\x12%.items.textdata.UpdateTextDataRequest\x1a\x1c.items.textdata.TextDataItem\x12O\n" +
@@ -582,7 +667,7 @@ func file_internal_proto_items_text_data_proto_rawDescGZIP() []byte {
 	return file_internal_proto_items_text_data_proto_rawDescData
 }
 
-var file_internal_proto_items_text_data_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_internal_proto_items_text_data_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_internal_proto_items_text_data_proto_goTypes = []any{
 	(*CreateTextDataRequest)(nil), // 0: items.textdata.CreateTextDataRequest
 	(*ListTextDataRequest)(nil),   // 1: items.textdata.ListTextDataRequest
@@ -592,23 +677,26 @@ var file_internal_proto_items_text_data_proto_goTypes = []any{
 	(*ListTextDataResponse)(nil),  // 5: items.textdata.ListTextDataResponse
 	(*TextDataItem)(nil),          // 6: items.textdata.TextDataItem
 	(*MetaData)(nil),              // 7: items.textdata.MetaData
-	(*emptypb.Empty)(nil),         // 8: google.protobuf.Empty
+	(*ReservedID)(nil),            // 8: items.textdata.ReservedID
+	(*emptypb.Empty)(nil),         // 9: google.protobuf.Empty
 }
 var file_internal_proto_items_text_data_proto_depIdxs = []int32{
 	6, // 0: items.textdata.ListTextDataResponse.TextDataItems:type_name -> items.textdata.TextDataItem
 	7, // 1: items.textdata.TextDataItem.meta_data:type_name -> items.textdata.MetaData
 	0, // 2: items.textdata.Service.CreateTextData:input_type -> items.textdata.CreateTextDataRequest
-	2, // 3: items.textdata.Service.GetTextData:input_type -> items.textdata.GetTextDataRequest
-	1, // 4: items.textdata.Service.ListTextDataItems:input_type -> items.textdata.ListTextDataRequest
-	3, // 5: items.textdata.Service.UpdateTextData:input_type -> items.textdata.UpdateTextDataRequest
-	4, // 6: items.textdata.Service.DeleteTextData:input_type -> items.textdata.DeleteTextDataRequest
-	6, // 7: items.textdata.Service.CreateTextData:output_type -> items.textdata.TextDataItem
-	6, // 8: items.textdata.Service.GetTextData:output_type -> items.textdata.TextDataItem
-	5, // 9: items.textdata.Service.ListTextDataItems:output_type -> items.textdata.ListTextDataResponse
-	6, // 10: items.textdata.Service.UpdateTextData:output_type -> items.textdata.TextDataItem
-	8, // 11: items.textdata.Service.DeleteTextData:output_type -> google.protobuf.Empty
-	7, // [7:12] is the sub-list for method output_type
-	2, // [2:7] is the sub-list for method input_type
+	9, // 3: items.textdata.Service.ReserveTextDataID:input_type -> google.protobuf.Empty
+	2, // 4: items.textdata.Service.GetTextData:input_type -> items.textdata.GetTextDataRequest
+	1, // 5: items.textdata.Service.ListTextDataItems:input_type -> items.textdata.ListTextDataRequest
+	3, // 6: items.textdata.Service.UpdateTextData:input_type -> items.textdata.UpdateTextDataRequest
+	4, // 7: items.textdata.Service.DeleteTextData:input_type -> items.textdata.DeleteTextDataRequest
+	6, // 8: items.textdata.Service.CreateTextData:output_type -> items.textdata.TextDataItem
+	8, // 9: items.textdata.Service.ReserveTextDataID:output_type -> items.textdata.ReservedID
+	6, // 10: items.textdata.Service.GetTextData:output_type -> items.textdata.TextDataItem
+	5, // 11: items.textdata.Service.ListTextDataItems:output_type -> items.textdata.ListTextDataResponse
+	6, // 12: items.textdata.Service.UpdateTextData:output_type -> items.textdata.TextDataItem
+	9, // 13: items.textdata.Service.DeleteTextData:output_type -> google.protobuf.Empty
+	8, // [8:14] is the sub-list for method output_type
+	2, // [2:8] is the sub-list for method input_type
 	2, // [2:2] is the sub-list for extension type_name
 	2, // [2:2] is the sub-list for extension extendee
 	0, // [0:2] is the sub-list for field type_name
@@ -625,7 +713,7 @@ func file_internal_proto_items_text_data_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_proto_items_text_data_proto_rawDesc), len(file_internal_proto_items_text_data_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   8,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -21,6 +21,7 @@ const _ = grpc.SupportPackageIsVersion9
 
 const (
 	Service_CreateTextData_FullMethodName    = "/items.textdata.Service/CreateTextData"
+	Service_ReserveTextDataID_FullMethodName = "/items.textdata.Service/ReserveTextDataID"
 	Service_GetTextData_FullMethodName       = "/items.textdata.Service/GetTextData"
 	Service_ListTextDataItems_FullMethodName = "/items.textdata.Service/ListTextDataItems"
 	Service_UpdateTextData_FullMethodName    = "/items.textdata.Service/UpdateTextData"
@@ -33,6 +34,8 @@ const (
 type ServiceClient interface {
 	// Создание новых текстовых данных
 	CreateTextData(ctx context.Context, in *CreateTextDataRequest, opts ...grpc.CallOption) (*TextDataItem, error)
+	// Резервирование идентификатора записи для данных, зашифрованных на клиенте
+	ReserveTextDataID(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ReservedID, error)
 	// Получение данных по ID
 	GetTextData(ctx context.Context, in *GetTextDataRequest, opts ...grpc.CallOption) (*TextDataItem, error)
 	// Получение списка всех паролей пользователя
@@ -61,6 +64,16 @@ func (c *serviceClient) CreateTextData(ctx context.Context, in *CreateTextDataRe
 	return out, nil
 }
 
+func (c *serviceClient) ReserveTextDataID(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ReservedID, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReservedID)
+	err := c.cc.Invoke(ctx, Service_ReserveTextDataID_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *serviceClient) GetTextData(ctx context.Context, in *GetTextDataRequest, opts ...grpc.CallOption) (*TextDataItem, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(TextDataItem)
@@ -107,6 +120,8 @@ func (c *serviceClient) DeleteTextData(ctx context.Context, in *DeleteTextDataRe
 type ServiceServer interface {
 	// Создание новых текстовых данных
 	CreateTextData(context.Context, *CreateTextDataRequest) (*TextDataItem, error)
+	// Резервирование идентификатора записи для данных, зашифрованных на клиенте
+	ReserveTextDataID(context.Context, *emptypb.Empty) (*ReservedID, error)
 	// Получение данных по ID
 	GetTextData(context.Context, *GetTextDataRequest) (*TextDataItem, error)
 	// Получение списка всех паролей пользователя
@@ -128,6 +143,9 @@ type UnimplementedServiceServer struct{}
 func (UnimplementedServiceServer) CreateTextData(context.Context, *CreateTextDataRequest) (*TextDataItem, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateTextData not implemented")
 }
+func (UnimplementedServiceServer) ReserveTextDataID(context.Context, *emptypb.Empty) (*ReservedID, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReserveTextDataID not implemented")
+}
 func (UnimplementedServiceServer) GetTextData(context.Context, *GetTextDataRequest) (*TextDataItem, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetTextData not implemented")
 }
@@ -179,6 +197,24 @@ func _Service_CreateTextData_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Service_ReserveTextDataID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).ReserveTextDataID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_ReserveTextDataID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).ReserveTextDataID(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Service_GetTextData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetTextDataRequest)
 	if err := dec(in); err != nil {
@@ -262,6 +298,10 @@ var Service_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreateTextData",
 			Handler:    _Service_CreateTextData_Handler,
 		},
+		{
+			MethodName: "ReserveTextDataID",
+			Handler:    _Service_ReserveTextDataID_Handler,
+		},
 		{
 			MethodName: "GetTextData",
 			Handler:    _Service_GetTextData_Handler,
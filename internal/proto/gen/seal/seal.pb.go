@@ -0,0 +1,198 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: internal/proto/seal/seal.proto
+
+package seal
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type UnsealRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Share         []byte                 `protobuf:"bytes,1,opt,name=share,proto3" json:"share,omitempty"` // Доля мастер-ключа по схеме Шамира
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnsealRequest) Reset() {
+	*x = UnsealRequest{}
+	mi := &file_internal_proto_seal_seal_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnsealRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnsealRequest) ProtoMessage() {}
+
+func (x *UnsealRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_seal_seal_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnsealRequest.ProtoReflect.Descriptor instead.
+func (*UnsealRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_seal_seal_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *UnsealRequest) GetShare() []byte {
+	if x != nil {
+		return x.Share
+	}
+	return nil
+}
+
+type SealStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sealed        bool                   `protobuf:"varint,1,opt,name=sealed,proto3" json:"sealed,omitempty"`
+	Threshold     int32                  `protobuf:"varint,2,opt,name=threshold,proto3" json:"threshold,omitempty"` // Сколько долей нужно для восстановления ключа
+	Progress      int32                  `protobuf:"varint,3,opt,name=progress,proto3" json:"progress,omitempty"`   // Сколько долей уже принято
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SealStatus) Reset() {
+	*x = SealStatus{}
+	mi := &file_internal_proto_seal_seal_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SealStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SealStatus) ProtoMessage() {}
+
+func (x *SealStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_seal_seal_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SealStatus.ProtoReflect.Descriptor instead.
+func (*SealStatus) Descriptor() ([]byte, []int) {
+	return file_internal_proto_seal_seal_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SealStatus) GetSealed() bool {
+	if x != nil {
+		return x.Sealed
+	}
+	return false
+}
+
+func (x *SealStatus) GetThreshold() int32 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *SealStatus) GetProgress() int32 {
+	if x != nil {
+		return x.Progress
+	}
+	return 0
+}
+
+var File_internal_proto_seal_seal_proto protoreflect.FileDescriptor
+
+const file_internal_proto_seal_seal_proto_rawDesc = "" +
+	"\n" +
+	"\x1einternal/proto/seal/seal.proto\x12\x04seal\x1a\x1bgoogle/protobuf/empty.proto\"%\n" +
+	"\rUnsealRequest\x12\x14\n" +
+	"\x05share\x18\x01 \x01(\fR\x05share\"^\n" +
+	"\n" +
+	"SealStatus\x12\x16\n" +
+	"\x06sealed\x18\x01 \x01(\bR\x06sealed\x12\x1c\n" +
+	"\tthreshold\x18\x02 \x01(\x05R\tthreshold\x12\x1a\n" +
+	"\bprogress\x18\x03 \x01(\x05R\bprogress2r\n" +
+	"\vSealService\x12/\n" +
+	"\x06Unseal\x12\x13.seal.UnsealRequest\x1a\x10.seal.SealStatus\x122\n" +
+	"\x06Status\x12\x16.google.protobuf.Empty\x1a\x10.seal.SealStatusB\n" +
+	"Z\bgen/sealb\x06proto3"
+
+var (
+	file_internal_proto_seal_seal_proto_rawDescOnce sync.Once
+	file_internal_proto_seal_seal_proto_rawDescData []byte
+)
+
+func file_internal_proto_seal_seal_proto_rawDescGZIP() []byte {
+	file_internal_proto_seal_seal_proto_rawDescOnce.Do(func() {
+		file_internal_proto_seal_seal_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_internal_proto_seal_seal_proto_rawDesc), len(file_internal_proto_seal_seal_proto_rawDesc)))
+	})
+	return file_internal_proto_seal_seal_proto_rawDescData
+}
+
+var file_internal_proto_seal_seal_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_internal_proto_seal_seal_proto_goTypes = []any{
+	(*UnsealRequest)(nil), // 0: seal.UnsealRequest
+	(*SealStatus)(nil),    // 1: seal.SealStatus
+	(*emptypb.Empty)(nil), // 2: google.protobuf.Empty
+}
+var file_internal_proto_seal_seal_proto_depIdxs = []int32{
+	0, // 0: seal.SealService.Unseal:input_type -> seal.UnsealRequest
+	2, // 1: seal.SealService.Status:input_type -> google.protobuf.Empty
+	1, // 2: seal.SealService.Unseal:output_type -> seal.SealStatus
+	1, // 3: seal.SealService.Status:output_type -> seal.SealStatus
+	2, // [2:4] is the sub-list for method output_type
+	0, // [0:2] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_internal_proto_seal_seal_proto_init() }
+func file_internal_proto_seal_seal_proto_init() {
+	if File_internal_proto_seal_seal_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_proto_seal_seal_proto_rawDesc), len(file_internal_proto_seal_seal_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_internal_proto_seal_seal_proto_goTypes,
+		DependencyIndexes: file_internal_proto_seal_seal_proto_depIdxs,
+		MessageInfos:      file_internal_proto_seal_seal_proto_msgTypes,
+	}.Build()
+	File_internal_proto_seal_seal_proto = out.File
+	file_internal_proto_seal_seal_proto_goTypes = nil
+	file_internal_proto_seal_seal_proto_depIdxs = nil
+}
@@ -0,0 +1,168 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: internal/proto/seal/seal.proto
+
+package seal
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SealService_Unseal_FullMethodName = "/seal.SealService/Unseal"
+	SealService_Status_FullMethodName = "/seal.SealService/Status"
+)
+
+// SealServiceClient is the client API for SealService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Сервис распечатывания сервера, запущенного в запечатанном режиме
+type SealServiceClient interface {
+	// Передача доли мастер-ключа, при достижении порога сервер распечатывается
+	Unseal(ctx context.Context, in *UnsealRequest, opts ...grpc.CallOption) (*SealStatus, error)
+	// Текущее состояние печати
+	Status(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*SealStatus, error)
+}
+
+type sealServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSealServiceClient(cc grpc.ClientConnInterface) SealServiceClient {
+	return &sealServiceClient{cc}
+}
+
+func (c *sealServiceClient) Unseal(ctx context.Context, in *UnsealRequest, opts ...grpc.CallOption) (*SealStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SealStatus)
+	err := c.cc.Invoke(ctx, SealService_Unseal_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sealServiceClient) Status(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*SealStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SealStatus)
+	err := c.cc.Invoke(ctx, SealService_Status_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SealServiceServer is the server API for SealService service.
+// All implementations must embed UnimplementedSealServiceServer
+// for forward compatibility.
+//
+// Сервис распечатывания сервера, запущенного в запечатанном режиме
+type SealServiceServer interface {
+	// Передача доли мастер-ключа, при достижении порога сервер распечатывается
+	Unseal(context.Context, *UnsealRequest) (*SealStatus, error)
+	// Текущее состояние печати
+	Status(context.Context, *emptypb.Empty) (*SealStatus, error)
+	mustEmbedUnimplementedSealServiceServer()
+}
+
+// UnimplementedSealServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSealServiceServer struct{}
+
+func (UnimplementedSealServiceServer) Unseal(context.Context, *UnsealRequest) (*SealStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unseal not implemented")
+}
+func (UnimplementedSealServiceServer) Status(context.Context, *emptypb.Empty) (*SealStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (UnimplementedSealServiceServer) mustEmbedUnimplementedSealServiceServer() {}
+func (UnimplementedSealServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeSealServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SealServiceServer will
+// result in compilation errors.
+type UnsafeSealServiceServer interface {
+	mustEmbedUnimplementedSealServiceServer()
+}
+
+func RegisterSealServiceServer(s grpc.ServiceRegistrar, srv SealServiceServer) {
+	// If the following call pancis, it indicates UnimplementedSealServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SealService_ServiceDesc, srv)
+}
+
+func _SealService_Unseal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnsealRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SealServiceServer).Unseal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SealService_Unseal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SealServiceServer).Unseal(ctx, req.(*UnsealRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SealService_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SealServiceServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SealService_Status_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SealServiceServer).Status(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SealService_ServiceDesc is the grpc.ServiceDesc for SealService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SealService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "seal.SealService",
+	HandlerType: (*SealServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Unseal",
+			Handler:    _SealService_Unseal_Handler,
+		},
+		{
+			MethodName: "Status",
+			Handler:    _SealService_Status_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "internal/proto/seal/seal.proto",
+}
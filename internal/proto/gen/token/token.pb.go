@@ -0,0 +1,492 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: internal/proto/token/token.proto
+
+package token
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type PersonalToken struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Scopes        []string               `protobuf:"bytes,3,rep,name=scopes,proto3" json:"scopes,omitempty"`                             // Права: passwords:read, files:write, item:<id>:read, file:<id>:read
+	ExpiresAt     string                 `protobuf:"bytes,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`      // Срок действия (пусто, если токен бессрочный)
+	LastUsedAt    string                 `protobuf:"bytes,5,opt,name=last_used_at,json=lastUsedAt,proto3" json:"last_used_at,omitempty"` // Дата последнего использования (пусто, если токен не использовался)
+	CreatedAt     string                 `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Expired       bool                   `protobuf:"varint,7,opt,name=expired,proto3" json:"expired,omitempty"` // Срок действия истек
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PersonalToken) Reset() {
+	*x = PersonalToken{}
+	mi := &file_internal_proto_token_token_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PersonalToken) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PersonalToken) ProtoMessage() {}
+
+func (x *PersonalToken) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_token_token_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PersonalToken.ProtoReflect.Descriptor instead.
+func (*PersonalToken) Descriptor() ([]byte, []int) {
+	return file_internal_proto_token_token_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PersonalToken) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *PersonalToken) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PersonalToken) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
+func (x *PersonalToken) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+func (x *PersonalToken) GetLastUsedAt() string {
+	if x != nil {
+		return x.LastUsedAt
+	}
+	return ""
+}
+
+func (x *PersonalToken) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *PersonalToken) GetExpired() bool {
+	if x != nil {
+		return x.Expired
+	}
+	return false
+}
+
+type CreatePersonalTokenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Scopes        []string               `protobuf:"bytes,2,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	ExpiresAt     string                 `protobuf:"bytes,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // Срок действия в формате RFC 3339, пусто - бессрочный
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreatePersonalTokenRequest) Reset() {
+	*x = CreatePersonalTokenRequest{}
+	mi := &file_internal_proto_token_token_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreatePersonalTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatePersonalTokenRequest) ProtoMessage() {}
+
+func (x *CreatePersonalTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_token_token_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatePersonalTokenRequest.ProtoReflect.Descriptor instead.
+func (*CreatePersonalTokenRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_token_token_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreatePersonalTokenRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreatePersonalTokenRequest) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
+func (x *CreatePersonalTokenRequest) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+type CreatePersonalTokenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"` // Значение токена, на сервере хранится только хеш
+	PersonalToken *PersonalToken         `protobuf:"bytes,2,opt,name=personal_token,json=personalToken,proto3" json:"personal_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreatePersonalTokenResponse) Reset() {
+	*x = CreatePersonalTokenResponse{}
+	mi := &file_internal_proto_token_token_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreatePersonalTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatePersonalTokenResponse) ProtoMessage() {}
+
+func (x *CreatePersonalTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_token_token_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatePersonalTokenResponse.ProtoReflect.Descriptor instead.
+func (*CreatePersonalTokenResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_token_token_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CreatePersonalTokenResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *CreatePersonalTokenResponse) GetPersonalToken() *PersonalToken {
+	if x != nil {
+		return x.PersonalToken
+	}
+	return nil
+}
+
+type ListPersonalTokensRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPersonalTokensRequest) Reset() {
+	*x = ListPersonalTokensRequest{}
+	mi := &file_internal_proto_token_token_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPersonalTokensRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPersonalTokensRequest) ProtoMessage() {}
+
+func (x *ListPersonalTokensRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_token_token_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPersonalTokensRequest.ProtoReflect.Descriptor instead.
+func (*ListPersonalTokensRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_token_token_proto_rawDescGZIP(), []int{3}
+}
+
+type ListPersonalTokensResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tokens        []*PersonalToken       `protobuf:"bytes,1,rep,name=tokens,proto3" json:"tokens,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPersonalTokensResponse) Reset() {
+	*x = ListPersonalTokensResponse{}
+	mi := &file_internal_proto_token_token_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPersonalTokensResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPersonalTokensResponse) ProtoMessage() {}
+
+func (x *ListPersonalTokensResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_token_token_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPersonalTokensResponse.ProtoReflect.Descriptor instead.
+func (*ListPersonalTokensResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_token_token_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListPersonalTokensResponse) GetTokens() []*PersonalToken {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+type RevokePersonalTokenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokePersonalTokenRequest) Reset() {
+	*x = RevokePersonalTokenRequest{}
+	mi := &file_internal_proto_token_token_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokePersonalTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokePersonalTokenRequest) ProtoMessage() {}
+
+func (x *RevokePersonalTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_token_token_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokePersonalTokenRequest.ProtoReflect.Descriptor instead.
+func (*RevokePersonalTokenRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_token_token_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RevokePersonalTokenRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type RevokePersonalTokenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokePersonalTokenResponse) Reset() {
+	*x = RevokePersonalTokenResponse{}
+	mi := &file_internal_proto_token_token_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokePersonalTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokePersonalTokenResponse) ProtoMessage() {}
+
+func (x *RevokePersonalTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_token_token_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokePersonalTokenResponse.ProtoReflect.Descriptor instead.
+func (*RevokePersonalTokenResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_token_token_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RevokePersonalTokenResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+var File_internal_proto_token_token_proto protoreflect.FileDescriptor
+
+const file_internal_proto_token_token_proto_rawDesc = "" +
+	"\n" +
+	" internal/proto/token/token.proto\x12\x05token\"\xc5\x01\n" +
+	"\rPersonalToken\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06scopes\x18\x03 \x03(\tR\x06scopes\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\tR\texpiresAt\x12 \n" +
+	"\flast_used_at\x18\x05 \x01(\tR\n" +
+	"lastUsedAt\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\tR\tcreatedAt\x12\x18\n" +
+	"\aexpired\x18\a \x01(\bR\aexpired\"g\n" +
+	"\x1aCreatePersonalTokenRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06scopes\x18\x02 \x03(\tR\x06scopes\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\tR\texpiresAt\"p\n" +
+	"\x1bCreatePersonalTokenResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12;\n" +
+	"\x0epersonal_token\x18\x02 \x01(\v2\x14.token.PersonalTokenR\rpersonalToken\"\x1b\n" +
+	"\x19ListPersonalTokensRequest\"J\n" +
+	"\x1aListPersonalTokensResponse\x12,\n" +
+	"\x06tokens\x18\x01 \x03(\v2\x14.token.PersonalTokenR\x06tokens\",\n" +
+	"\x1aRevokePersonalTokenRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"7\n" +
+	"\x1bRevokePersonalTokenResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess2\xa5\x02\n" +
+	"\fTokenService\x12\\\n" +
+	"\x13CreatePersonalToken\x12!.token.CreatePersonalTokenRequest\x1a\".token.CreatePersonalTokenResponse\x12Y\n" +
+	"\x12ListPersonalTokens\x12 .token.ListPersonalTokensRequest\x1a!.token.ListPersonalTokensResponse\x12\\\n" +
+	"\x13RevokePersonalToken\x12!.token.RevokePersonalTokenRequest\x1a\".token.RevokePersonalTokenResponseB\vZ\tgen/tokenb\x06proto3"
+
+var (
+	file_internal_proto_token_token_proto_rawDescOnce sync.Once
+	file_internal_proto_token_token_proto_rawDescData []byte
+)
+
+func file_internal_proto_token_token_proto_rawDescGZIP() []byte {
+	file_internal_proto_token_token_proto_rawDescOnce.Do(func() {
+		file_internal_proto_token_token_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_internal_proto_token_token_proto_rawDesc), len(file_internal_proto_token_token_proto_rawDesc)))
+	})
+	return file_internal_proto_token_token_proto_rawDescData
+}
+
+var file_internal_proto_token_token_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_internal_proto_token_token_proto_goTypes = []any{
+	(*PersonalToken)(nil),               // 0: token.PersonalToken
+	(*CreatePersonalTokenRequest)(nil),  // 1: token.CreatePersonalTokenRequest
+	(*CreatePersonalTokenResponse)(nil), // 2: token.CreatePersonalTokenResponse
+	(*ListPersonalTokensRequest)(nil),   // 3: token.ListPersonalTokensRequest
+	(*ListPersonalTokensResponse)(nil),  // 4: token.ListPersonalTokensResponse
+	(*RevokePersonalTokenRequest)(nil),  // 5: token.RevokePersonalTokenRequest
+	(*RevokePersonalTokenResponse)(nil), // 6: token.RevokePersonalTokenResponse
+}
+var file_internal_proto_token_token_proto_depIdxs = []int32{
+	0, // 0: token.CreatePersonalTokenResponse.personal_token:type_name -> token.PersonalToken
+	0, // 1: token.ListPersonalTokensResponse.tokens:type_name -> token.PersonalToken
+	1, // 2: token.TokenService.CreatePersonalToken:input_type -> token.CreatePersonalTokenRequest
+	3, // 3: token.TokenService.ListPersonalTokens:input_type -> token.ListPersonalTokensRequest
+	5, // 4: token.TokenService.RevokePersonalToken:input_type -> token.RevokePersonalTokenRequest
+	2, // 5: token.TokenService.CreatePersonalToken:output_type -> token.CreatePersonalTokenResponse
+	4, // 6: token.TokenService.ListPersonalTokens:output_type -> token.ListPersonalTokensResponse
+	6, // 7: token.TokenService.RevokePersonalToken:output_type -> token.RevokePersonalTokenResponse
+	5, // [5:8] is the sub-list for method output_type
+	2, // [2:5] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_internal_proto_token_token_proto_init() }
+func file_internal_proto_token_token_proto_init() {
+	if File_internal_proto_token_token_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_proto_token_token_proto_rawDesc), len(file_internal_proto_token_token_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_internal_proto_token_token_proto_goTypes,
+		DependencyIndexes: file_internal_proto_token_token_proto_depIdxs,
+		MessageInfos:      file_internal_proto_token_token_proto_msgTypes,
+	}.Build()
+	File_internal_proto_token_token_proto = out.File
+	file_internal_proto_token_token_proto_goTypes = nil
+	file_internal_proto_token_token_proto_depIdxs = nil
+}
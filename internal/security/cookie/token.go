@@ -34,3 +34,12 @@ func LoadTokens(filename string) (string, string, int64, error) {
 
 	return tokens.AccessToken, tokens.RefreshToken, tokens.ExpiresIn, nil
 }
+
+// RemoveTokens удаление файла с токенами при выходе, отсутствие файла ошибкой не считается
+func RemoveTokens(filename string) error {
+	err := os.Remove(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package cookie
 import (
 	"encoding/json"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -181,3 +182,22 @@ func TestLoadTokens_TableDriven(t *testing.T) {
 		})
 	}
 }
+
+func TestRemoveTokens(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), ".tokens.json")
+	if err := SaveTokens("access", "refresh", filename, 3600); err != nil {
+		t.Fatalf("SaveTokens failed: %v", err)
+	}
+
+	if err := RemoveTokens(filename); err != nil {
+		t.Fatalf("RemoveTokens failed: %v", err)
+	}
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Errorf("Token file still exists: %v", err)
+	}
+
+	// Повторный выход без файла не ошибка
+	if err := RemoveTokens(filename); err != nil {
+		t.Errorf("RemoveTokens without file failed: %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package crypto
+
+import "fmt"
+
+// Связанные данные (AAD) привязывают шифротекст к владельцу и месту хранения,
+// поэтому перенесенный в другую запись шифротекст не расшифруется
+
+// ItemAAD связанные данные записи хранилища
+func ItemAAD(userID int64, itemID int64, itemType string) []byte {
+	return fmt.Appendf(nil, "gophkeeper/item/v1|user:%d|item:%d|type:%s", userID, itemID, itemType)
+}
+
+// ChunkAAD связанные данные части бинарного файла
+// используется только для чтения частей, сохраненных до StreamChunkAAD
+func ChunkAAD(fileID int64, chunkIndex int32) []byte {
+	return fmt.Appendf(nil, "gophkeeper/chunk/v1|file:%d|index:%d", fileID, chunkIndex)
+}
+
+// StreamChunkAAD связанные данные части бинарного файла в потоковой схеме (STREAM)
+// номер части и признак последней части не дают переставить части или отрезать конец файла
+func StreamChunkAAD(fileID int64, chunkIndex int32, final bool) []byte {
+	return fmt.Appendf(nil, "gophkeeper/chunk/v2|file:%d|index:%d|final:%t", fileID, chunkIndex, final)
+}
+
+// DataKeyAAD связанные данные ключа шифрования данных пользователя
+func DataKeyAAD(userID int) []byte {
+	return fmt.Appendf(nil, "gophkeeper/data-key/v1|user:%d", userID)
+}
+
+// TOTPSecretAAD связанные данные секрета второго фактора пользователя
+func TOTPSecretAAD(userID int) []byte {
+	return fmt.Appendf(nil, "gophkeeper/totp/v1|user:%d", userID)
+}
+
+// FieldAAD связанные данные текстового поля записи или файла (описание, метаданные, имя файла)
+// owner вид владельца поля (item, file), ownerID его идентификатор
+func FieldAAD(userID int64, owner string, ownerID int64, field string) []byte {
+	return fmt.Appendf(nil, "gophkeeper/field/v1|user:%d|%s:%d|field:%s", userID, owner, ownerID, field)
+}
@@ -0,0 +1,180 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAAD_Binding(t *testing.T) {
+	key := []byte("12345678901234567890123456789012")
+	encryptor, err := NewEncryptor(key, DefaultAlgorithm)
+	require.NoError(t, err)
+	decryptor, err := NewDecryptor(key)
+	require.NoError(t, err)
+
+	aad := ItemAAD(1, 10, "passwords")
+	encryptedData, algorithm, iv, err := encryptor.Encrypt([]byte("secret"), aad)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		aad     []byte
+		wantErr bool
+	}{
+		{
+			name: "same record",
+			aad:  ItemAAD(1, 10, "passwords"),
+		},
+		{
+			name:    "other user",
+			aad:     ItemAAD(2, 10, "passwords"),
+			wantErr: true,
+		},
+		{
+			name:    "other item",
+			aad:     ItemAAD(1, 11, "passwords"),
+			wantErr: true,
+		},
+		{
+			name:    "other type",
+			aad:     ItemAAD(1, 10, "text"),
+			wantErr: true,
+		},
+		{
+			name:    "without aad",
+			aad:     nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := decryptor.Decrypt(encryptedData, iv, algorithm, tt.aad)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []byte("secret"), data)
+		})
+	}
+}
+
+func TestChunkAAD(t *testing.T) {
+	key := []byte("12345678901234567890123456789012")
+	encryptor, err := NewEncryptor(key, AlgorithmXChaCha20Poly1305)
+	require.NoError(t, err)
+	decryptor, err := NewDecryptor(key)
+	require.NoError(t, err)
+
+	encryptedData, algorithm, iv, err := encryptor.Encrypt([]byte("chunk"), ChunkAAD(5, 0))
+	require.NoError(t, err)
+
+	// Перестановка чанков и перенос в другой файл обнаруживаются
+	_, err = decryptor.Decrypt(encryptedData, iv, algorithm, ChunkAAD(5, 1))
+	assert.Error(t, err)
+	_, err = decryptor.Decrypt(encryptedData, iv, algorithm, ChunkAAD(6, 0))
+	assert.Error(t, err)
+
+	data, err := decryptor.Decrypt(encryptedData, iv, algorithm, ChunkAAD(5, 0))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("chunk"), data)
+}
+
+func TestStreamChunkAAD(t *testing.T) {
+	key := []byte("12345678901234567890123456789012")
+	encryptor, err := NewEncryptor(key, DefaultAlgorithm)
+	require.NoError(t, err)
+	decryptor, err := NewDecryptor(key)
+	require.NoError(t, err)
+
+	encryptedData, algorithm, iv, err := encryptor.Encrypt([]byte("chunk"), StreamChunkAAD(5, 2, false))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		aad     []byte
+		wantErr bool
+	}{
+		{
+			name: "same position",
+			aad:  StreamChunkAAD(5, 2, false),
+		},
+		{
+			// файл обрезан, и часть стала последней
+			name:    "truncated",
+			aad:     StreamChunkAAD(5, 2, true),
+			wantErr: true,
+		},
+		{
+			name:    "reordered",
+			aad:     StreamChunkAAD(5, 3, false),
+			wantErr: true,
+		},
+		{
+			name:    "other file",
+			aad:     StreamChunkAAD(6, 2, false),
+			wantErr: true,
+		},
+		{
+			name:    "previous scheme",
+			aad:     ChunkAAD(5, 2),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := decryptor.Decrypt(encryptedData, iv, algorithm, tt.aad)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []byte("chunk"), data)
+		})
+	}
+}
+
+func TestLegacyAES_AAD(t *testing.T) {
+	key := []byte("123456789012345678901234")
+
+	_, _, err := legacyAES{}.Encrypt(key, []byte("secret"), ItemAAD(1, 1, "text"))
+	assert.ErrorIs(t, err, ErrAADNotSupported)
+
+	_, err = legacyAES{}.Decrypt(key, []byte("secret"), make([]byte, 12), ItemAAD(1, 1, "text"))
+	assert.ErrorIs(t, err, ErrAADNotSupported)
+}
+
+func TestManager_DataKeyBoundToUser(t *testing.T) {
+	keyStore := &memoryKeyStore{keys: map[int]*WrappedKey{}}
+	cm := newTestManager(t, keyStore)
+
+	_, err := cm.GetEncryptor(userContext(1))
+	require.NoError(t, err)
+	require.True(t, keyStore.keys[1].AADBound)
+
+	// Ключ, перенесенный другому пользователю, не разворачивается
+	keyStore.keys[2] = keyStore.keys[1]
+	_, err = cm.GetEncryptor(userContext(2))
+	assert.Error(t, err)
+}
+
+func TestTOTPSecretAAD(t *testing.T) {
+	key := []byte("12345678901234567890123456789012")
+	encryptor, err := NewEncryptor(key, DefaultAlgorithm)
+	require.NoError(t, err)
+	decryptor, err := NewDecryptor(key)
+	require.NoError(t, err)
+
+	encryptedData, algorithm, iv, err := encryptor.Encrypt([]byte("totp secret"), TOTPSecretAAD(1))
+	require.NoError(t, err)
+
+	// Секрет, перенесенный другому пользователю, не расшифровывается
+	_, err = decryptor.Decrypt(encryptedData, iv, algorithm, TOTPSecretAAD(2))
+	assert.Error(t, err)
+
+	data, err := decryptor.Decrypt(encryptedData, iv, algorithm, TOTPSecretAAD(1))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("totp secret"), data)
+}
@@ -1,3 +1,6 @@
+// Package aes256gcm исторический алгоритм шифрования
+// ключ длиной 24 байта, поэтому фактически используется AES-192-GCM,
+// метка сохранена для совместимости с уже записанными данными
 package aes256gcm
 
 const (
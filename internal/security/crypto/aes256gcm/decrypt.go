@@ -38,6 +38,10 @@ func (d *Decryptor) Decrypt(encryptedData []byte, iv []byte) ([]byte, error) {
 		return nil, err
 	}
 
+	if len(iv) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid IV length")
+	}
+
 	// Дешифруем данные
 	decryptedData, err := gcm.Open(nil, iv, encryptedData, nil)
 	if err != nil {
@@ -1,27 +1,43 @@
 package crypto
 
 import (
-	"github.com/ramil063/secondgodiplom/internal/security/crypto/aes256gcm"
+	"errors"
+	"sync"
 )
 
 // Encryptor общий интерфейс для шифрования
+// aad связанные данные (владелец, позиция записи), привязывающие шифротекст к месту хранения
 type Encryptor interface {
-	Encrypt(data []byte) ([]byte, string, []byte, error)
+	Encrypt(data []byte, aad []byte) ([]byte, string, []byte, error)
 }
 
 // Decryptor общий интерфейс для шифрования
+// algorithm алгоритм, сохраненный вместе с зашифрованными данными
+// aad те же связанные данные, что и при шифровании, для старых записей nil
 type Decryptor interface {
-	Decrypt(encryptedData []byte, iv []byte) ([]byte, error)
+	Decrypt(encryptedData []byte, iv []byte, algorithm string, aad []byte) ([]byte, error)
 }
 
 // Manager содержит все шифровальщики и дешифровщики
+// а так же связку мастер-ключей и ключи шифрования данных пользователей
 type Manager struct {
 	grpcEncryptor Encryptor
 	grpcDecryptor Decryptor
+
+	algorithm  string
+	keyVersion int
+	masterKeys map[int]*masterKey
+
+	keyStore KeyStore
+	mu       sync.RWMutex
+	userKeys map[int]*userKeys
 }
 
 func NewCryptoManager() *Manager {
-	return &Manager{}
+	return &Manager{
+		algorithm:  DefaultAlgorithm,
+		keyVersion: DefaultKeyVersion,
+	}
 }
 
 func (cm *Manager) SetGRPCEncryptor(enc Encryptor) {
@@ -40,22 +56,66 @@ func (cm *Manager) GetGRPCDecryptor() Decryptor {
 	return cm.grpcDecryptor
 }
 
-// NewAes256gcmEncryptor фабрика для алгоритма Aes256gcm
-func NewAes256gcmEncryptor(encryptionKey []byte) (Encryptor, error) {
-	encryptor := &aes256gcm.Encryptor{}
-	var err error
+// SetAlgorithm выбор алгоритма шифрования новых данных
+// должен вызываться до UseMasterKey
+func (cm *Manager) SetAlgorithm(algorithm string) error {
+	if _, err := GetAlgorithm(algorithm); err != nil {
+		return err
+	}
+	cm.algorithm = algorithm
+	return nil
+}
+
+// keyEncryptor шифровальщик на ключе для алгоритма из реестра
+type keyEncryptor struct {
+	key       []byte
+	algorithm Algorithm
+	name      string
+}
 
-	encryptor.SetEncryptionKey(encryptionKey)
+// Encrypt функция шифрования
+func (e *keyEncryptor) Encrypt(data []byte, aad []byte) ([]byte, string, []byte, error) {
+	encryptedData, iv, err := e.algorithm.Encrypt(e.key, data, aad)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return encryptedData, e.name, iv, nil
+}
 
-	return encryptor, err
+// keyDecryptor дешифровщик на ключе, алгоритм выбирается из реестра
+type keyDecryptor struct {
+	key []byte
 }
 
-// NewAes256gcmDecryptor фабрика для алгоритма Aes256gcm
-func NewAes256gcmDecryptor(encryptionKey []byte) (Decryptor, error) {
-	decryptor := &aes256gcm.Decryptor{}
-	var err error
+// Decrypt функция дешифровки
+func (d *keyDecryptor) Decrypt(encryptedData []byte, iv []byte, algorithm string, aad []byte) ([]byte, error) {
+	alg, err := GetAlgorithm(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	return alg.Decrypt(d.key, encryptedData, iv, aad)
+}
 
-	decryptor.SetDecryptionKey(encryptionKey)
+// NewEncryptor фабрика шифровальщика для алгоритма из реестра
+func NewEncryptor(encryptionKey []byte, algorithm string) (Encryptor, error) {
+	alg, err := GetAlgorithm(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	return &keyEncryptor{
+		key:       encryptionKey,
+		algorithm: alg,
+		name:      algorithm,
+	}, nil
+}
 
-	return decryptor, err
+// NewDecryptor фабрика дешифровщика
+// алгоритм каждой записи определяется при расшифровке
+func NewDecryptor(encryptionKey []byte) (Decryptor, error) {
+	if len(encryptionKey) == 0 {
+		return nil, errors.New("empty decryption key")
+	}
+	return &keyDecryptor{
+		key: encryptionKey,
+	}, nil
 }
@@ -54,7 +54,7 @@ func TestNewCryptoManager(t *testing.T) {
 	}{
 		{
 			name: "NewCryptoManager",
-			want: &Manager{},
+			want: &Manager{algorithm: DefaultAlgorithm, keyVersion: DefaultKeyVersion},
 		},
 	}
 	for _, tt := range tests {
@@ -64,54 +64,44 @@ func TestNewCryptoManager(t *testing.T) {
 	}
 }
 
-func TestNewAes256gcmEncryptor(t *testing.T) {
-	type args struct {
-		encryptionKey []byte
-	}
+func TestNewEncryptor(t *testing.T) {
 	tests := []struct {
-		name string
-		args args
-		want string
+		name      string
+		algorithm string
+		wantErr   bool
 	}{
 		{
-			name: "NewAes256gcmEncryptor",
-			args: args{
-				encryptionKey: []byte("test"),
-			},
-			want: "*aes256gcm.Encryptor",
+			name:      "AES-256-GCM",
+			algorithm: AlgorithmAES256GCM,
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, err := NewAes256gcmEncryptor(tt.args.encryptionKey)
-			assert.NoError(t, err, "NewAes256gcmEncryptor")
-			assert.Equalf(t, tt.want, reflect.ValueOf(got).Type().String(), "NewAes256gcmEncryptor(%v)", tt.args.encryptionKey)
-		})
-	}
-}
-
-func TestNewAes256gcmDecryptor(t *testing.T) {
-	type args struct {
-		encryptionKey []byte
-	}
-	tests := []struct {
-		name string
-		args args
-		want string
-	}{
 		{
-			name: "NewAes256gcmDecryptor",
-			args: args{
-				encryptionKey: []byte("test"),
-			},
-			want: "*aes256gcm.Decryptor",
+			name:      "XChaCha20-Poly1305",
+			algorithm: AlgorithmXChaCha20Poly1305,
+		},
+		{
+			name:      "unknown algorithm",
+			algorithm: "DES",
+			wantErr:   true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := NewAes256gcmDecryptor(tt.args.encryptionKey)
-			assert.NoError(t, err, "NewAes256gcmDecryptor")
-			assert.Equalf(t, tt.want, reflect.ValueOf(got).Type().String(), "NewAes256gcmDecryptor(%v)", tt.args.encryptionKey)
+			got, err := NewEncryptor([]byte("test"), tt.algorithm)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrUnknownAlgorithm)
+				return
+			}
+			assert.NoError(t, err, "NewEncryptor")
+			assert.Equalf(t, "*crypto.keyEncryptor", reflect.ValueOf(got).Type().String(), "NewEncryptor(%v)", tt.algorithm)
 		})
 	}
 }
+
+func TestNewDecryptor(t *testing.T) {
+	got, err := NewDecryptor([]byte("test"))
+	assert.NoError(t, err, "NewDecryptor")
+	assert.Equalf(t, "*crypto.keyDecryptor", reflect.ValueOf(got).Type().String(), "NewDecryptor()")
+
+	_, err = NewDecryptor(nil)
+	assert.Error(t, err)
+}
@@ -1,5 +1,7 @@
 // Package crypto обеспечивает унифицированный интерфейс для операций шифрования.
 //
-// Поддерживаемые алгоритмы:
-// - RSA (OAEP)
+// Поддерживаемые алгоритмы (реестр по значению колонки encryption_algorithm):
+// - A256GCM (AES-256-GCM, ключ выводится через HKDF)
+// - XCHACHA20-POLY1305
+// - AES-256-GCM (исторические записи с 24-байтовым ключом, фактически AES-192)
 package crypto
@@ -0,0 +1,73 @@
+package crypto
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// sealedFieldPrefix начало зашифрованного текстового поля
+// поле хранится строкой gkf1$<версия мастер-ключа>$<алгоритм>$<iv>$<шифротекст>, iv и шифротекст в base64
+const sealedFieldPrefix = "gkf1$"
+
+// ErrInvalidSealedField поле начинается с sealedFieldPrefix, но не разбирается
+var ErrInvalidSealedField = errors.New("invalid sealed field")
+
+// SealField шифрование текстового поля для хранения в текстовой колонке
+// пустое значение не шифруется, чтобы пустые поля оставались пустыми
+func SealField(encryptor Encryptor, keyVersion int, value string, aad []byte) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	encryptedData, algorithm, iv, err := encryptor.Encrypt([]byte(value), aad)
+	if err != nil {
+		return "", err
+	}
+	return sealedFieldPrefix + strings.Join([]string{
+		strconv.Itoa(keyVersion),
+		algorithm,
+		base64.RawStdEncoding.EncodeToString(iv),
+		base64.RawStdEncoding.EncodeToString(encryptedData),
+	}, "$"), nil
+}
+
+// IsSealedField поле зашифровано SealField
+func IsSealedField(value string) bool {
+	return strings.HasPrefix(value, sealedFieldPrefix)
+}
+
+// OpenField расшифровка текстового поля ключом пользователя из контекста
+// значения, сохраненные до шифрования полей, возвращаются как есть
+func OpenField(ctx context.Context, keys KeyResolver, value string, aad []byte) (string, error) {
+	if !IsSealedField(value) {
+		return value, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(value, sealedFieldPrefix), "$")
+	if len(parts) != 4 {
+		return "", ErrInvalidSealedField
+	}
+	keyVersion, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return "", ErrInvalidSealedField
+	}
+	iv, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", ErrInvalidSealedField
+	}
+	encryptedData, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return "", ErrInvalidSealedField
+	}
+
+	decryptor, err := keys.GetDecryptor(ctx, keyVersion)
+	if err != nil {
+		return "", err
+	}
+	data, err := decryptor.Decrypt(encryptedData, iv, parts[1], aad)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,80 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealField(t *testing.T) {
+	cm := newTestManager(t, &memoryKeyStore{keys: map[int]*WrappedKey{}})
+	ctx := userContext(1)
+	encryptor, err := cm.GetEncryptor(ctx)
+	require.NoError(t, err)
+
+	aad := FieldAAD(1, "item", 10, "description")
+	sealed, err := SealField(encryptor, cm.KeyVersion(), "Сбербанк, код активации 4711", aad)
+	require.NoError(t, err)
+	assert.True(t, IsSealedField(sealed))
+	assert.NotContains(t, sealed, "4711")
+
+	tests := []struct {
+		name    string
+		value   string
+		aad     []byte
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "same field",
+			value: sealed,
+			aad:   aad,
+			want:  "Сбербанк, код активации 4711",
+		},
+		{
+			// шифротекст, перенесенный в другую запись, не расшифровывается
+			name:    "other item",
+			value:   sealed,
+			aad:     FieldAAD(1, "item", 11, "description"),
+			wantErr: true,
+		},
+		{
+			name:    "other field",
+			value:   sealed,
+			aad:     FieldAAD(1, "item", 10, "metadata.value"),
+			wantErr: true,
+		},
+		{
+			name:  "plaintext before field encryption",
+			value: "Сбербанк",
+			aad:   aad,
+			want:  "Сбербанк",
+		},
+		{
+			name:    "malformed",
+			value:   "gkf1$1$A256GCM",
+			aad:     aad,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := OpenField(ctx, cm, tt.value, tt.aad)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	// данные другого пользователя не расшифровываются его ключом
+	_, err = OpenField(userContext(2), cm, sealed, aad)
+	assert.Error(t, err)
+
+	empty, err := SealField(encryptor, cm.KeyVersion(), "", aad)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
@@ -0,0 +1,9 @@
+// Package keyprovider источники мастер-ключа сервера.
+//
+// Поддерживаемые источники (поле type в конфигурации):
+// - static - ключ в конфигурации (для обратной совместимости с crypto_key)
+// - file - файл с ключом
+// - env - переменная окружения
+// - keystore - файл хранилища ключа, защищенный паролем (Argon2id + XChaCha20-Poly1305)
+// - vault-transit - расшифровка обернутого ключа через HTTP API Vault transit
+package keyprovider
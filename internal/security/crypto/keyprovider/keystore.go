@@ -0,0 +1,132 @@
+package keyprovider
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/argon2"
+
+	"github.com/ramil063/secondgodiplom/internal/security/crypto"
+)
+
+// Параметры вывода ключа из пароля для новых хранилищ
+// параметры сохраняются в файле, поэтому их можно менять без потери старых хранилищ
+const (
+	keystoreVersion   = 1
+	keystoreKDF       = "argon2id"
+	argonTime         = 3
+	argonMemory       = 64 * 1024
+	argonThreads      = 4
+	keystoreKeyLen    = 32
+	keystoreSaltLen   = 16
+	keystoreAlgorithm = crypto.AlgorithmXChaCha20Poly1305
+)
+
+// keystoreAAD связанные данные шифротекста хранилища
+var keystoreAAD = []byte("gophkeeper/keystore/v1")
+
+// ErrEmptyPassphrase пароль хранилища не задан
+var ErrEmptyPassphrase = errors.New("keystore passphrase is empty")
+
+// keystoreKDFParams параметры Argon2id
+type keystoreKDFParams struct {
+	Name    string `json:"name"`
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+	Salt    []byte `json:"salt"`
+}
+
+// keystoreFile формат файла хранилища ключа
+type keystoreFile struct {
+	Version    int               `json:"version"`
+	KDF        keystoreKDFParams `json:"kdf"`
+	Algorithm  string            `json:"algorithm"`
+	IV         []byte            `json:"iv"`
+	Ciphertext []byte            `json:"ciphertext"`
+}
+
+// KeystoreProvider ключ из файла хранилища, защищенного паролем
+type KeystoreProvider struct {
+	Path       string
+	Passphrase string
+}
+
+// GetKey расшифровка ключа из хранилища
+func (p *KeystoreProvider) GetKey(_ context.Context) ([]byte, error) {
+	if p.Passphrase == "" {
+		return nil, ErrEmptyPassphrase
+	}
+
+	data, err := os.ReadFile(p.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+	var file keystoreFile
+	if err = json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse keystore: %w", err)
+	}
+	if file.Version != keystoreVersion || file.KDF.Name != keystoreKDF {
+		return nil, fmt.Errorf("unsupported keystore version %d (%s)", file.Version, file.KDF.Name)
+	}
+
+	kek := argon2.IDKey([]byte(p.Passphrase), file.KDF.Salt, file.KDF.Time, file.KDF.Memory, file.KDF.Threads, keystoreKeyLen)
+	decryptor, err := crypto.NewDecryptor(kek)
+	if err != nil {
+		return nil, err
+	}
+	key, err := decryptor.Decrypt(file.Ciphertext, file.IV, file.Algorithm, keystoreAAD)
+	if err != nil {
+		return nil, errors.New("failed to decrypt keystore: wrong passphrase or damaged file")
+	}
+	return key, nil
+}
+
+// WriteKeystore сохранение ключа в файл хранилища, защищенного паролем
+// файл создается с правами только для владельца
+func WriteKeystore(path string, key []byte, passphrase string) error {
+	if passphrase == "" {
+		return ErrEmptyPassphrase
+	}
+	if len(key) == 0 {
+		return ErrEmptyKey
+	}
+
+	salt := make([]byte, keystoreSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	params := keystoreKDFParams{
+		Name:    keystoreKDF,
+		Time:    argonTime,
+		Memory:  argonMemory,
+		Threads: argonThreads,
+		Salt:    salt,
+	}
+
+	kek := argon2.IDKey([]byte(passphrase), params.Salt, params.Time, params.Memory, params.Threads, keystoreKeyLen)
+	encryptor, err := crypto.NewEncryptor(kek, keystoreAlgorithm)
+	if err != nil {
+		return err
+	}
+	ciphertext, algorithm, iv, err := encryptor.Encrypt(key, keystoreAAD)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt keystore: %w", err)
+	}
+
+	data, err := json.MarshalIndent(&keystoreFile{
+		Version:    keystoreVersion,
+		KDF:        params,
+		Algorithm:  algorithm,
+		IV:         iv,
+		Ciphertext: ciphertext,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
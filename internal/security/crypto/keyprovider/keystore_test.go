@@ -0,0 +1,71 @@
+package keyprovider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeystoreProvider_GetKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "master.keystore")
+	key := []byte("123456789012345678901234")
+	require.NoError(t, WriteKeystore(path, key, "correct horse"))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	tests := []struct {
+		name       string
+		passphrase string
+		wantErr    bool
+	}{
+		{
+			name:       "correct passphrase",
+			passphrase: "correct horse",
+		},
+		{
+			name:       "wrong passphrase",
+			passphrase: "battery staple",
+			wantErr:    true,
+		},
+		{
+			name:       "empty passphrase",
+			passphrase: "",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&KeystoreProvider{Path: path, Passphrase: tt.passphrase}).GetKey(context.Background())
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, key, got)
+		})
+	}
+}
+
+func TestKeystoreProvider_FromConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "master.keystore")
+	require.NoError(t, WriteKeystore(path, []byte("master key"), "secret"))
+	t.Setenv("GOPHKEEPER_TEST_PASSPHRASE", "secret")
+
+	provider, err := New(Config{Type: TypeKeystore, Path: path, PassphraseEnv: "GOPHKEEPER_TEST_PASSPHRASE"})
+	require.NoError(t, err)
+	got, err := provider.GetKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []byte("master key"), got)
+}
+
+func TestWriteKeystore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "master.keystore")
+	assert.ErrorIs(t, WriteKeystore(path, []byte("key"), ""), ErrEmptyPassphrase)
+	assert.ErrorIs(t, WriteKeystore(path, nil, "secret"), ErrEmptyKey)
+}
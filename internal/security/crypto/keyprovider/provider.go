@@ -0,0 +1,138 @@
+package keyprovider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ramil063/secondgodiplom/internal/security/crypto"
+)
+
+// Типы источников мастер-ключа
+const (
+	TypeStatic       = "static"
+	TypeFile         = "file"
+	TypeEnv          = "env"
+	TypeKeystore     = "keystore"
+	TypeVaultTransit = "vault-transit"
+)
+
+// Значения по умолчанию для переменных окружения с секретами источников
+const (
+	DefaultPassphraseEnv = "GOPHKEEPER_KEYSTORE_PASSPHRASE"
+	DefaultVaultTokenEnv = "VAULT_TOKEN"
+	DefaultVaultMount    = "transit"
+)
+
+// ErrEmptyKey источник вернул пустой ключ
+var ErrEmptyKey = errors.New("master key is empty")
+
+// Config настройки источника мастер-ключа
+// секреты (пароль хранилища, токен Vault) берутся из переменных окружения, а не из конфигурации
+type Config struct {
+	Type            string `json:"type"`
+	Key             string `json:"key"`
+	Path            string `json:"path"`
+	Env             string `json:"env"`
+	PassphraseEnv   string `json:"passphrase_env"`
+	VaultAddress    string `json:"vault_address"`
+	VaultTokenEnv   string `json:"vault_token_env"`
+	VaultMount      string `json:"vault_mount"`
+	VaultKeyName    string `json:"vault_key_name"`
+	VaultCiphertext string `json:"vault_ciphertext"`
+}
+
+// New создание источника мастер-ключа по настройкам
+func New(cfg Config) (crypto.KeyProvider, error) {
+	switch cfg.Type {
+	case TypeStatic:
+		return &StaticProvider{Key: []byte(cfg.Key)}, nil
+	case TypeFile:
+		if cfg.Path == "" {
+			return nil, errors.New("path is required for file key provider")
+		}
+		return &FileProvider{Path: cfg.Path}, nil
+	case TypeEnv:
+		if cfg.Env == "" {
+			return nil, errors.New("env is required for env key provider")
+		}
+		return &EnvProvider{Name: cfg.Env}, nil
+	case TypeKeystore:
+		if cfg.Path == "" {
+			return nil, errors.New("path is required for keystore key provider")
+		}
+		passphraseEnv := cfg.PassphraseEnv
+		if passphraseEnv == "" {
+			passphraseEnv = DefaultPassphraseEnv
+		}
+		return &KeystoreProvider{Path: cfg.Path, Passphrase: os.Getenv(passphraseEnv)}, nil
+	case TypeVaultTransit:
+		if cfg.VaultAddress == "" || cfg.VaultKeyName == "" || cfg.VaultCiphertext == "" {
+			return nil, errors.New("vault_address, vault_key_name and vault_ciphertext are required for vault-transit key provider")
+		}
+		tokenEnv := cfg.VaultTokenEnv
+		if tokenEnv == "" {
+			tokenEnv = DefaultVaultTokenEnv
+		}
+		mount := cfg.VaultMount
+		if mount == "" {
+			mount = DefaultVaultMount
+		}
+		return &TransitProvider{
+			Address:    cfg.VaultAddress,
+			Token:      os.Getenv(tokenEnv),
+			Mount:      mount,
+			KeyName:    cfg.VaultKeyName,
+			Ciphertext: cfg.VaultCiphertext,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown key provider type: %q", cfg.Type)
+	}
+}
+
+// StaticProvider ключ, переданный напрямую
+type StaticProvider struct {
+	Key []byte
+}
+
+// GetKey получение ключа
+func (p *StaticProvider) GetKey(_ context.Context) ([]byte, error) {
+	if len(p.Key) == 0 {
+		return nil, ErrEmptyKey
+	}
+	return p.Key, nil
+}
+
+// FileProvider ключ из файла, пробелы и перевод строки по краям отбрасываются
+type FileProvider struct {
+	Path string
+}
+
+// GetKey получение ключа
+func (p *FileProvider) GetKey(_ context.Context) ([]byte, error) {
+	data, err := os.ReadFile(p.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	key := bytes.TrimSpace(data)
+	if len(key) == 0 {
+		return nil, ErrEmptyKey
+	}
+	return key, nil
+}
+
+// EnvProvider ключ из переменной окружения
+type EnvProvider struct {
+	Name string
+}
+
+// GetKey получение ключа
+func (p *EnvProvider) GetKey(_ context.Context) ([]byte, error) {
+	key := os.Getenv(p.Name)
+	if key == "" {
+		return nil, fmt.Errorf("%w: env %s is not set", ErrEmptyKey, p.Name)
+	}
+	return []byte(key), nil
+}
@@ -0,0 +1,136 @@
+package keyprovider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "static",
+			cfg:  Config{Type: TypeStatic, Key: "key"},
+			want: &StaticProvider{Key: []byte("key")},
+		},
+		{
+			name: "file",
+			cfg:  Config{Type: TypeFile, Path: "/etc/gophkeeper/key"},
+			want: &FileProvider{Path: "/etc/gophkeeper/key"},
+		},
+		{
+			name:    "file without path",
+			cfg:     Config{Type: TypeFile},
+			wantErr: true,
+		},
+		{
+			name: "env",
+			cfg:  Config{Type: TypeEnv, Env: "MASTER_KEY"},
+			want: &EnvProvider{Name: "MASTER_KEY"},
+		},
+		{
+			name: "vault transit defaults",
+			cfg: Config{
+				Type:            TypeVaultTransit,
+				VaultAddress:    "http://vault:8200",
+				VaultKeyName:    "gophkeeper",
+				VaultCiphertext: "vault:v1:abc",
+			},
+			want: &TransitProvider{
+				Address:    "http://vault:8200",
+				Mount:      DefaultVaultMount,
+				KeyName:    "gophkeeper",
+				Ciphertext: "vault:v1:abc",
+			},
+		},
+		{
+			name:    "vault transit without ciphertext",
+			cfg:     Config{Type: TypeVaultTransit, VaultAddress: "http://vault:8200", VaultKeyName: "gophkeeper"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown type",
+			cfg:     Config{Type: "hsm"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(DefaultVaultTokenEnv, "")
+			got, err := New(tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFileProvider_GetKey(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "key")
+	require.NoError(t, os.WriteFile(keyPath, []byte("123456789012345678901234\n"), 0o600))
+	emptyPath := filepath.Join(dir, "empty")
+	require.NoError(t, os.WriteFile(emptyPath, []byte("\n"), 0o600))
+
+	tests := []struct {
+		name    string
+		path    string
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name: "key file",
+			path: keyPath,
+			want: []byte("123456789012345678901234"),
+		},
+		{
+			name:    "empty file",
+			path:    emptyPath,
+			wantErr: true,
+		},
+		{
+			name:    "no file",
+			path:    filepath.Join(dir, "missing"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&FileProvider{Path: tt.path}).GetKey(context.Background())
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEnvProvider_GetKey(t *testing.T) {
+	t.Setenv("GOPHKEEPER_TEST_MASTER_KEY", "123456789012345678901234")
+
+	got, err := (&EnvProvider{Name: "GOPHKEEPER_TEST_MASTER_KEY"}).GetKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []byte("123456789012345678901234"), got)
+
+	_, err = (&EnvProvider{Name: "GOPHKEEPER_TEST_MISSING_KEY"}).GetKey(context.Background())
+	assert.ErrorIs(t, err, ErrEmptyKey)
+}
+
+func TestStaticProvider_GetKey(t *testing.T) {
+	_, err := (&StaticProvider{}).GetKey(context.Background())
+	assert.ErrorIs(t, err, ErrEmptyKey)
+}
@@ -0,0 +1,96 @@
+package keyprovider
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// transitTimeout ограничение времени запроса к Vault, если клиент не передан
+const transitTimeout = 10 * time.Second
+
+// ErrEmptyToken токен Vault не задан
+var ErrEmptyToken = errors.New("vault token is empty")
+
+// TransitProvider мастер-ключ, обернутый ключом Vault transit
+// в конфигурации хранится только шифротекст, ключ расшифровывается запросом к Vault при старте сервера
+type TransitProvider struct {
+	Address    string
+	Token      string
+	Mount      string
+	KeyName    string
+	Ciphertext string
+	Client     *http.Client
+}
+
+type transitDecryptRequest struct {
+	Ciphertext string `json:"ciphertext"`
+}
+
+type transitDecryptResponse struct {
+	Data struct {
+		Plaintext string `json:"plaintext"`
+	} `json:"data"`
+	Errors []string `json:"errors"`
+}
+
+// GetKey расшифровка мастер-ключа через POST /v1/{mount}/decrypt/{key}
+func (p *TransitProvider) GetKey(ctx context.Context) ([]byte, error) {
+	if p.Token == "" {
+		return nil, ErrEmptyToken
+	}
+
+	body, err := json.Marshal(&transitDecryptRequest{Ciphertext: p.Ciphertext})
+	if err != nil {
+		return nil, err
+	}
+	endpoint := strings.TrimRight(p.Address, "/") +
+		"/v1/" + url.PathEscape(p.Mount) + "/decrypt/" + url.PathEscape(p.KeyName)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", p.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := p.Client
+	if client == nil {
+		client = &http.Client{Timeout: transitTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault response: %w", err)
+	}
+
+	var result transitDecryptResponse
+	if err = json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse vault response (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault returned status %d: %s", resp.StatusCode, strings.Join(result.Errors, "; "))
+	}
+
+	key, err := base64.StdEncoding.DecodeString(result.Data.Plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode vault plaintext: %w", err)
+	}
+	if len(key) == 0 {
+		return nil, ErrEmptyKey
+	}
+	return key, nil
+}
@@ -0,0 +1,105 @@
+package keyprovider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTransitServer локальная замена Vault transit: расшифровывает только известный шифротекст
+func newTransitServer(t *testing.T, token, ciphertext string, key []byte) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/transit/decrypt/gophkeeper" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":["no handler for route"]}`))
+			return
+		}
+		if r.Header.Get("X-Vault-Token") != token {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+
+		var req transitDecryptRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Ciphertext != ciphertext {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":["invalid ciphertext"]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"plaintext":"` + base64.StdEncoding.EncodeToString(key) + `"}}`))
+	}))
+}
+
+func TestTransitProvider_GetKey(t *testing.T) {
+	key := []byte("123456789012345678901234")
+	server := newTransitServer(t, "s.token", "vault:v1:wrapped", key)
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		token      string
+		keyName    string
+		ciphertext string
+		wantErr    bool
+	}{
+		{
+			name:       "decrypted",
+			token:      "s.token",
+			keyName:    "gophkeeper",
+			ciphertext: "vault:v1:wrapped",
+		},
+		{
+			name:       "wrong token",
+			token:      "s.other",
+			keyName:    "gophkeeper",
+			ciphertext: "vault:v1:wrapped",
+			wantErr:    true,
+		},
+		{
+			name:       "no token",
+			keyName:    "gophkeeper",
+			ciphertext: "vault:v1:wrapped",
+			wantErr:    true,
+		},
+		{
+			name:       "unknown key",
+			token:      "s.token",
+			keyName:    "other",
+			ciphertext: "vault:v1:wrapped",
+			wantErr:    true,
+		},
+		{
+			name:       "wrong ciphertext",
+			token:      "s.token",
+			keyName:    "gophkeeper",
+			ciphertext: "vault:v1:other",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &TransitProvider{
+				Address:    server.URL + "/",
+				Token:      tt.token,
+				Mount:      DefaultVaultMount,
+				KeyName:    tt.keyName,
+				Ciphertext: tt.ciphertext,
+				Client:     server.Client(),
+			}
+			got, err := provider.GetKey(context.Background())
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, key, got)
+		})
+	}
+}
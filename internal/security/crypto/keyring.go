@@ -0,0 +1,91 @@
+package crypto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// DefaultKeyVersion версия мастер-ключа, если версия не указана в конфигурации
+const DefaultKeyVersion = 1
+
+// ErrUnknownKeyVersion мастер-ключ нужной версии не загружен
+var ErrUnknownKeyVersion = errors.New("unknown master key version")
+
+// masterKey мастер-ключ одной версии и дешифровщик на нем
+type masterKey struct {
+	key       []byte
+	decryptor Decryptor
+}
+
+// AddMasterKey добавление мастер-ключа в связку ключей
+// ключи предыдущих версий нужны для расшифровки данных до окончания ротации
+func (cm *Manager) AddMasterKey(version int, key []byte) error {
+	decryptor, err := NewDecryptor(key)
+	if err != nil {
+		return err
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if cm.masterKeys == nil {
+		cm.masterKeys = make(map[int]*masterKey)
+	}
+	cm.masterKeys[version] = &masterKey{
+		key:       key,
+		decryptor: decryptor,
+	}
+	return nil
+}
+
+// KeyProvider источник мастер-ключа (файл, переменная окружения, хранилище ключей, KMS)
+type KeyProvider interface {
+	GetKey(ctx context.Context) ([]byte, error)
+}
+
+// AddMasterKeyFromProvider добавление в связку мастер-ключа, полученного из источника
+func (cm *Manager) AddMasterKeyFromProvider(ctx context.Context, version int, provider KeyProvider) error {
+	key, err := provider.GetKey(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get master key version %d: %w", version, err)
+	}
+	return cm.AddMasterKey(version, key)
+}
+
+// UseMasterKey выбор текущего мастер-ключа, которым шифруются новые данные
+func (cm *Manager) UseMasterKey(version int) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	key, ok := cm.masterKeys[version]
+	if !ok {
+		return fmt.Errorf("%w: %d", ErrUnknownKeyVersion, version)
+	}
+	encryptor, err := NewEncryptor(key.key, cm.algorithm)
+	if err != nil {
+		return err
+	}
+	cm.grpcEncryptor = encryptor
+	cm.grpcDecryptor = key.decryptor
+	cm.keyVersion = version
+	return nil
+}
+
+// KeyVersion версия текущего мастер-ключа
+// сохраняется вместе с каждой зашифрованной записью
+func (cm *Manager) KeyVersion() int {
+	return cm.keyVersion
+}
+
+// GetMasterDecryptor получение дешифровщика на мастер-ключе указанной версии
+func (cm *Manager) GetMasterDecryptor(version int) (Decryptor, error) {
+	cm.mu.RLock()
+	key, ok := cm.masterKeys[version]
+	cm.mu.RUnlock()
+	if ok {
+		return key.decryptor, nil
+	}
+	if version == cm.keyVersion && cm.grpcDecryptor != nil {
+		return cm.grpcDecryptor, nil
+	}
+	return nil, fmt.Errorf("%w: %d", ErrUnknownKeyVersion, version)
+}
@@ -0,0 +1,115 @@
+package crypto
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_UseMasterKey(t *testing.T) {
+	cm := NewCryptoManager()
+	require.NoError(t, cm.AddMasterKey(2, []byte("abcdefghijklmnopqrstuvwx")))
+
+	err := cm.UseMasterKey(3)
+	assert.ErrorIs(t, err, ErrUnknownKeyVersion)
+	assert.Equal(t, DefaultKeyVersion, cm.KeyVersion())
+
+	require.NoError(t, cm.UseMasterKey(2))
+	assert.Equal(t, 2, cm.KeyVersion())
+	assert.NotNil(t, cm.GetGRPCEncryptor())
+}
+
+func TestManager_GetMasterDecryptor(t *testing.T) {
+	cm := NewCryptoManager()
+	require.NoError(t, cm.AddMasterKey(1, []byte("123456789012345678901234")))
+	require.NoError(t, cm.AddMasterKey(2, []byte("abcdefghijklmnopqrstuvwx")))
+	require.NoError(t, cm.UseMasterKey(1))
+
+	encryptedData, algorithm, iv, err := cm.GetGRPCEncryptor().Encrypt([]byte("secret"), nil)
+	require.NoError(t, err)
+
+	// Новые данные шифруются новым ключом, старые продолжают читаться старым
+	require.NoError(t, cm.UseMasterKey(2))
+
+	tests := []struct {
+		name    string
+		version int
+		wantErr bool
+	}{
+		{
+			name:    "previous key",
+			version: 1,
+		},
+		{
+			name:    "current key can not decrypt old data",
+			version: 2,
+			wantErr: true,
+		},
+		{
+			name:    "unknown key",
+			version: 3,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decryptor, err := cm.GetMasterDecryptor(tt.version)
+			if err == nil {
+				_, err = decryptor.Decrypt(encryptedData, iv, algorithm, nil)
+			}
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestManager_DataKeyWrappedWithPreviousKey(t *testing.T) {
+	keyStore := &memoryKeyStore{keys: map[int]*WrappedKey{}}
+	cm := NewCryptoManager()
+	require.NoError(t, cm.AddMasterKey(1, []byte("123456789012345678901234")))
+	require.NoError(t, cm.UseMasterKey(1))
+	cm.SetKeyStore(keyStore)
+
+	encryptor, err := cm.GetEncryptor(userContext(1))
+	require.NoError(t, err)
+	encryptedData, algorithm, iv, err := encryptor.Encrypt([]byte("secret"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, keyStore.keys[1].KeyVersion)
+
+	// После смены мастер-ключа ключ пользователя разворачивается ключом своей версии
+	require.NoError(t, cm.AddMasterKey(2, []byte("abcdefghijklmnopqrstuvwx")))
+	require.NoError(t, cm.UseMasterKey(2))
+	cm.ForgetUser(1)
+
+	decryptor, err := cm.GetDecryptor(userContext(1), 2)
+	require.NoError(t, err)
+	data, err := decryptor.Decrypt(encryptedData, iv, algorithm, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret"), data)
+}
+
+// staticKeyProvider источник ключа для тестов
+type staticKeyProvider struct {
+	key []byte
+	err error
+}
+
+func (p *staticKeyProvider) GetKey(_ context.Context) ([]byte, error) {
+	return p.key, p.err
+}
+
+func TestManager_AddMasterKeyFromProvider(t *testing.T) {
+	cm := NewCryptoManager()
+	require.NoError(t, cm.AddMasterKeyFromProvider(context.Background(), 2, &staticKeyProvider{key: []byte("abcdefghijklmnopqrstuvwx")}))
+	require.NoError(t, cm.UseMasterKey(2))
+
+	err := cm.AddMasterKeyFromProvider(context.Background(), 3, &staticKeyProvider{err: errors.New("vault is sealed")})
+	assert.Error(t, err)
+	assert.ErrorIs(t, cm.UseMasterKey(3), ErrUnknownKeyVersion)
+}
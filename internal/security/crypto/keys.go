@@ -0,0 +1,218 @@
+package crypto
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// dataKeyLength длина ключа шифрования данных пользователя
+// ключи, созданные раньше, имеют длину 24 байта и продолжают работать
+const dataKeyLength = 32
+
+// ErrNoUserInContext в контексте нет идентификатора пользователя
+var ErrNoUserInContext = errors.New("user id not found in context")
+
+// ErrNoMasterKey текущий мастер-ключ не задан, шифровать новые данные нечем
+var ErrNoMasterKey = status.Error(codes.FailedPrecondition, "master key not configured")
+
+// WrappedKey ключ шифрования данных пользователя, зашифрованный мастер-ключом сервера
+// AADBound ключ обернут с привязкой к пользователю (DataKeyAAD)
+type WrappedKey struct {
+	Key        []byte
+	IV         []byte
+	Algorithm  string
+	KeyVersion int
+	AADBound   bool
+}
+
+// AAD связанные данные, с которыми был обернут ключ
+// ключи, обернутые до появления привязки, разворачиваются без связанных данных
+func (k *WrappedKey) AAD(userID int) []byte {
+	if !k.AADBound {
+		return nil
+	}
+	return DataKeyAAD(userID)
+}
+
+// KeyStore хранилище обернутых ключей шифрования данных пользователей
+type KeyStore interface {
+	// GetDataKey получение ключа пользователя, если ключа нет возвращается nil без ошибки
+	GetDataKey(ctx context.Context, userID int) (*WrappedKey, error)
+	// SaveDataKey сохранение ключа пользователя, если ключ уже есть он не перезаписывается
+	SaveDataKey(ctx context.Context, userID int, key *WrappedKey) error
+}
+
+// KeyResolver получение шифровальщика и дешифровщика пользователя из контекста запроса
+type KeyResolver interface {
+	GetEncryptor(ctx context.Context) (Encryptor, error)
+	// GetDecryptor keyVersion версия мастер-ключа, записанная вместе с данными
+	GetDecryptor(ctx context.Context, keyVersion int) (Decryptor, error)
+	// KeyVersion версия мастер-ключа, которую нужно сохранить вместе с новыми данными
+	KeyVersion() int
+}
+
+// userKeys шифровальщик и дешифровщик на ключе пользователя
+type userKeys struct {
+	encryptor Encryptor
+	decryptor Decryptor
+}
+
+// legacyDecryptor дешифровщик на ключе пользователя
+// данные, сохраненные до появления ключей пользователей, расшифровываются мастер-ключом
+type legacyDecryptor struct {
+	userDecryptor   Decryptor
+	masterDecryptor Decryptor
+}
+
+// Decrypt функция дешифровки
+func (d *legacyDecryptor) Decrypt(encryptedData []byte, iv []byte, algorithm string, aad []byte) ([]byte, error) {
+	data, err := d.userDecryptor.Decrypt(encryptedData, iv, algorithm, aad)
+	if err == nil || d.masterDecryptor == nil {
+		return data, err
+	}
+	return d.masterDecryptor.Decrypt(encryptedData, iv, algorithm, aad)
+}
+
+// SetKeyStore установка хранилища ключей пользователей
+// без хранилища все данные шифруются мастер-ключом
+func (cm *Manager) SetKeyStore(keyStore KeyStore) {
+	cm.keyStore = keyStore
+}
+
+// GetEncryptor получение шифровальщика на ключе пользователя из контекста
+// без текущего мастер-ключа возвращается ErrNoMasterKey
+func (cm *Manager) GetEncryptor(ctx context.Context) (Encryptor, error) {
+	if cm.keyStore == nil {
+		if cm.grpcEncryptor == nil {
+			return nil, ErrNoMasterKey
+		}
+		return cm.grpcEncryptor, nil
+	}
+	keys, err := cm.getUserKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return keys.encryptor, nil
+}
+
+// GetDecryptor получение дешифровщика на ключе пользователя из контекста
+// данные, зашифрованные напрямую мастер-ключом, расшифровываются ключом версии keyVersion
+func (cm *Manager) GetDecryptor(ctx context.Context, keyVersion int) (Decryptor, error) {
+	masterDecryptor, err := cm.GetMasterDecryptor(keyVersion)
+	if cm.keyStore == nil {
+		return masterDecryptor, err
+	}
+	keys, err := cm.getUserKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &legacyDecryptor{
+		userDecryptor:   keys.decryptor,
+		masterDecryptor: masterDecryptor,
+	}, nil
+}
+
+// ForgetUser удаление ключа пользователя из кеша
+// применяется при удалении ключа из хранилища
+func (cm *Manager) ForgetUser(userID int) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	delete(cm.userKeys, userID)
+}
+
+func (cm *Manager) getUserKeys(ctx context.Context) (*userKeys, error) {
+	userID, ok := ctx.Value("userID").(int)
+	if !ok {
+		return nil, ErrNoUserInContext
+	}
+
+	cm.mu.RLock()
+	keys, ok := cm.userKeys[userID]
+	cm.mu.RUnlock()
+	if ok {
+		return keys, nil
+	}
+
+	dataKey, err := cm.loadDataKey(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	encryptor, err := NewEncryptor(dataKey, cm.algorithm)
+	if err != nil {
+		return nil, err
+	}
+	decryptor, err := NewDecryptor(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	keys = &userKeys{
+		encryptor: encryptor,
+		decryptor: decryptor,
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if cm.userKeys == nil {
+		cm.userKeys = make(map[int]*userKeys)
+	}
+	cm.userKeys[userID] = keys
+	return keys, nil
+}
+
+// loadDataKey получение и расшифровка ключа пользователя
+// если ключа еще нет, он создается и сохраняется в обернутом виде
+func (cm *Manager) loadDataKey(ctx context.Context, userID int) ([]byte, error) {
+	wrappedKey, err := cm.keyStore.GetDataKey(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data key: %w", err)
+	}
+
+	if wrappedKey == nil {
+		// Новый ключ оборачивается текущим мастер-ключом
+		if cm.grpcEncryptor == nil {
+			return nil, ErrNoMasterKey
+		}
+		dataKey := make([]byte, dataKeyLength)
+		if _, err = rand.Read(dataKey); err != nil {
+			return nil, err
+		}
+		encryptedKey, algorithm, iv, err := cm.grpcEncryptor.Encrypt(dataKey, DataKeyAAD(userID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to wrap data key: %w", err)
+		}
+		err = cm.keyStore.SaveDataKey(ctx, userID, &WrappedKey{
+			Key:        encryptedKey,
+			IV:         iv,
+			Algorithm:  algorithm,
+			KeyVersion: cm.keyVersion,
+			AADBound:   true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to save data key: %w", err)
+		}
+
+		// Перечитываем ключ, так как параллельный запрос мог сохранить свой
+		wrappedKey, err = cm.keyStore.GetDataKey(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get data key: %w", err)
+		}
+		if wrappedKey == nil {
+			return nil, errors.New("data key was not saved")
+		}
+	}
+
+	masterDecryptor, err := cm.GetMasterDecryptor(wrappedKey.KeyVersion)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := masterDecryptor.Decrypt(wrappedKey.Key, wrappedKey.IV, wrappedKey.Algorithm, wrappedKey.AAD(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	return dataKey, nil
+}
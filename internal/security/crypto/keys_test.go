@@ -0,0 +1,158 @@
+package crypto
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type memoryKeyStore struct {
+	mu   sync.Mutex
+	keys map[int]*WrappedKey
+}
+
+func (m *memoryKeyStore) GetDataKey(_ context.Context, userID int) (*WrappedKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.keys[userID], nil
+}
+
+func (m *memoryKeyStore) SaveDataKey(_ context.Context, userID int, key *WrappedKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.keys[userID]; !ok {
+		m.keys[userID] = key
+	}
+	return nil
+}
+
+func newTestManager(t *testing.T, keyStore KeyStore) *Manager {
+	masterKey := []byte("123456789012345678901234")
+	encryptor, err := NewEncryptor(masterKey, DefaultAlgorithm)
+	require.NoError(t, err)
+	decryptor, err := NewDecryptor(masterKey)
+	require.NoError(t, err)
+
+	cm := NewCryptoManager()
+	cm.SetGRPCEncryptor(encryptor)
+	cm.SetGRPCDecryptor(decryptor)
+	if keyStore != nil {
+		cm.SetKeyStore(keyStore)
+	}
+	return cm
+}
+
+func userContext(userID int) context.Context {
+	return context.WithValue(context.Background(), "userID", userID)
+}
+
+func TestManager_GetEncryptor_WithoutKeyStore(t *testing.T) {
+	cm := newTestManager(t, nil)
+
+	encryptor, err := cm.GetEncryptor(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, cm.GetGRPCEncryptor(), encryptor)
+
+	decryptor, err := cm.GetDecryptor(context.Background(), DefaultKeyVersion)
+	require.NoError(t, err)
+	assert.Equal(t, cm.GetGRPCDecryptor(), decryptor)
+}
+
+func TestManager_UserKeys(t *testing.T) {
+	keyStore := &memoryKeyStore{keys: map[int]*WrappedKey{}}
+	cm := newTestManager(t, keyStore)
+
+	encryptor, err := cm.GetEncryptor(userContext(1))
+	require.NoError(t, err)
+	assert.NotEqual(t, cm.GetGRPCEncryptor(), encryptor)
+	require.Contains(t, keyStore.keys, 1)
+
+	encryptedData, algorithm, iv, err := encryptor.Encrypt([]byte("secret"), nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		userID  int
+		wantErr bool
+	}{
+		{
+			name:   "owner decrypts",
+			userID: 1,
+		},
+		{
+			name:    "other user can not decrypt",
+			userID:  2,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decryptor, err := cm.GetDecryptor(userContext(tt.userID), DefaultKeyVersion)
+			require.NoError(t, err)
+
+			data, err := decryptor.Decrypt(encryptedData, iv, algorithm, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []byte("secret"), data)
+		})
+	}
+}
+
+func TestManager_LegacyData(t *testing.T) {
+	cm := newTestManager(t, &memoryKeyStore{keys: map[int]*WrappedKey{}})
+
+	encryptedData, algorithm, iv, err := cm.GetGRPCEncryptor().Encrypt([]byte("legacy"), nil)
+	require.NoError(t, err)
+
+	decryptor, err := cm.GetDecryptor(userContext(1), DefaultKeyVersion)
+	require.NoError(t, err)
+	data, err := decryptor.Decrypt(encryptedData, iv, algorithm, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("legacy"), data)
+}
+
+func TestManager_CryptoShredding(t *testing.T) {
+	keyStore := &memoryKeyStore{keys: map[int]*WrappedKey{}}
+	cm := newTestManager(t, keyStore)
+
+	encryptor, err := cm.GetEncryptor(userContext(1))
+	require.NoError(t, err)
+	encryptedData, algorithm, iv, err := encryptor.Encrypt([]byte("secret"), nil)
+	require.NoError(t, err)
+
+	// Удаление ключа пользователя делает его данные нечитаемыми
+	delete(keyStore.keys, 1)
+	cm.ForgetUser(1)
+
+	decryptor, err := cm.GetDecryptor(userContext(1), DefaultKeyVersion)
+	require.NoError(t, err)
+	_, err = decryptor.Decrypt(encryptedData, iv, algorithm, nil)
+	assert.Error(t, err)
+}
+
+func TestManager_GetEncryptor_NoUser(t *testing.T) {
+	cm := newTestManager(t, &memoryKeyStore{keys: map[int]*WrappedKey{}})
+
+	_, err := cm.GetEncryptor(context.Background())
+	assert.ErrorIs(t, err, ErrNoUserInContext)
+}
+
+func TestManager_GetEncryptor_NoMasterKey(t *testing.T) {
+	// без мастер-ключа запрос получает ошибку, а не панику
+	cm := NewCryptoManager()
+	_, err := cm.GetEncryptor(userContext(1))
+	assert.ErrorIs(t, err, ErrNoMasterKey)
+
+	cm.SetKeyStore(&memoryKeyStore{keys: map[int]*WrappedKey{}})
+	_, err = cm.GetEncryptor(userContext(1))
+	assert.ErrorIs(t, err, ErrNoMasterKey)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
@@ -1,13 +1,15 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/ramil063/secondgodiplom/internal/security/crypto (interfaces: Encryptor,Decryptor)
+// Source: github.com/ramil063/secondgodiplom/internal/security/crypto (interfaces: Encryptor,Decryptor,KeyResolver,KeyStore)
 
 // Package crypto is a generated GoMock package.
 package crypto
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	crypto "github.com/ramil063/secondgodiplom/internal/security/crypto"
 )
 
 // MockEncryptor is a mock of Encryptor interface.
@@ -34,9 +36,9 @@ func (m *MockEncryptor) EXPECT() *MockEncryptorMockRecorder {
 }
 
 // Encrypt mocks base method.
-func (m *MockEncryptor) Encrypt(arg0 []byte) ([]byte, string, []byte, error) {
+func (m *MockEncryptor) Encrypt(arg0, arg1 []byte) ([]byte, string, []byte, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Encrypt", arg0)
+	ret := m.ctrl.Call(m, "Encrypt", arg0, arg1)
 	ret0, _ := ret[0].([]byte)
 	ret1, _ := ret[1].(string)
 	ret2, _ := ret[2].([]byte)
@@ -45,9 +47,9 @@ func (m *MockEncryptor) Encrypt(arg0 []byte) ([]byte, string, []byte, error) {
 }
 
 // Encrypt indicates an expected call of Encrypt.
-func (mr *MockEncryptorMockRecorder) Encrypt(arg0 interface{}) *gomock.Call {
+func (mr *MockEncryptorMockRecorder) Encrypt(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Encrypt", reflect.TypeOf((*MockEncryptor)(nil).Encrypt), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Encrypt", reflect.TypeOf((*MockEncryptor)(nil).Encrypt), arg0, arg1)
 }
 
 // MockDecryptor is a mock of Decryptor interface.
@@ -74,16 +76,135 @@ func (m *MockDecryptor) EXPECT() *MockDecryptorMockRecorder {
 }
 
 // Decrypt mocks base method.
-func (m *MockDecryptor) Decrypt(arg0, arg1 []byte) ([]byte, error) {
+func (m *MockDecryptor) Decrypt(arg0, arg1 []byte, arg2 string, arg3 []byte) ([]byte, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Decrypt", arg0, arg1)
+	ret := m.ctrl.Call(m, "Decrypt", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].([]byte)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Decrypt indicates an expected call of Decrypt.
-func (mr *MockDecryptorMockRecorder) Decrypt(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockDecryptorMockRecorder) Decrypt(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Decrypt", reflect.TypeOf((*MockDecryptor)(nil).Decrypt), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Decrypt", reflect.TypeOf((*MockDecryptor)(nil).Decrypt), arg0, arg1, arg2, arg3)
+}
+
+// MockKeyResolver is a mock of KeyResolver interface.
+type MockKeyResolver struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeyResolverMockRecorder
+}
+
+// MockKeyResolverMockRecorder is the mock recorder for MockKeyResolver.
+type MockKeyResolverMockRecorder struct {
+	mock *MockKeyResolver
+}
+
+// NewMockKeyResolver creates a new mock instance.
+func NewMockKeyResolver(ctrl *gomock.Controller) *MockKeyResolver {
+	mock := &MockKeyResolver{ctrl: ctrl}
+	mock.recorder = &MockKeyResolverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKeyResolver) EXPECT() *MockKeyResolverMockRecorder {
+	return m.recorder
+}
+
+// GetDecryptor mocks base method.
+func (m *MockKeyResolver) GetDecryptor(arg0 context.Context, arg1 int) (crypto.Decryptor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDecryptor", arg0, arg1)
+	ret0, _ := ret[0].(crypto.Decryptor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDecryptor indicates an expected call of GetDecryptor.
+func (mr *MockKeyResolverMockRecorder) GetDecryptor(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDecryptor", reflect.TypeOf((*MockKeyResolver)(nil).GetDecryptor), arg0, arg1)
+}
+
+// GetEncryptor mocks base method.
+func (m *MockKeyResolver) GetEncryptor(arg0 context.Context) (crypto.Encryptor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEncryptor", arg0)
+	ret0, _ := ret[0].(crypto.Encryptor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEncryptor indicates an expected call of GetEncryptor.
+func (mr *MockKeyResolverMockRecorder) GetEncryptor(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEncryptor", reflect.TypeOf((*MockKeyResolver)(nil).GetEncryptor), arg0)
+}
+
+// KeyVersion mocks base method.
+func (m *MockKeyResolver) KeyVersion() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KeyVersion")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// KeyVersion indicates an expected call of KeyVersion.
+func (mr *MockKeyResolverMockRecorder) KeyVersion() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeyVersion", reflect.TypeOf((*MockKeyResolver)(nil).KeyVersion))
+}
+
+// MockKeyStore is a mock of KeyStore interface.
+type MockKeyStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeyStoreMockRecorder
+}
+
+// MockKeyStoreMockRecorder is the mock recorder for MockKeyStore.
+type MockKeyStoreMockRecorder struct {
+	mock *MockKeyStore
+}
+
+// NewMockKeyStore creates a new mock instance.
+func NewMockKeyStore(ctrl *gomock.Controller) *MockKeyStore {
+	mock := &MockKeyStore{ctrl: ctrl}
+	mock.recorder = &MockKeyStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKeyStore) EXPECT() *MockKeyStoreMockRecorder {
+	return m.recorder
+}
+
+// GetDataKey mocks base method.
+func (m *MockKeyStore) GetDataKey(arg0 context.Context, arg1 int) (*crypto.WrappedKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDataKey", arg0, arg1)
+	ret0, _ := ret[0].(*crypto.WrappedKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDataKey indicates an expected call of GetDataKey.
+func (mr *MockKeyStoreMockRecorder) GetDataKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDataKey", reflect.TypeOf((*MockKeyStore)(nil).GetDataKey), arg0, arg1)
+}
+
+// SaveDataKey mocks base method.
+func (m *MockKeyStore) SaveDataKey(arg0 context.Context, arg1 int, arg2 *crypto.WrappedKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveDataKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveDataKey indicates an expected call of SaveDataKey.
+func (mr *MockKeyStoreMockRecorder) SaveDataKey(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDataKey", reflect.TypeOf((*MockKeyStore)(nil).SaveDataKey), arg0, arg1, arg2)
 }
@@ -0,0 +1,159 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/aes256gcm"
+)
+
+// Алгоритмы шифрования, название сохраняется в колонке encryption_algorithm
+const (
+	// AlgorithmLegacyAES исторические записи: AES-GCM с 24-байтовым ключом (фактически AES-192)
+	// используется только для чтения старых данных
+	AlgorithmLegacyAES = "AES-256-GCM"
+	// AlgorithmAES256GCM AES-GCM с 32-байтовым ключом
+	AlgorithmAES256GCM = "A256GCM"
+	// AlgorithmXChaCha20Poly1305 XChaCha20-Poly1305 с 24-байтовым nonce
+	AlgorithmXChaCha20Poly1305 = "XCHACHA20-POLY1305"
+)
+
+// DefaultAlgorithm алгоритм шифрования новых данных по умолчанию
+const DefaultAlgorithm = AlgorithmAES256GCM
+
+// derivedKeyLength длина ключа, выводимого из ключевого материала для алгоритма
+const derivedKeyLength = 32
+
+// ErrUnknownAlgorithm алгоритм не зарегистрирован в реестре
+var ErrUnknownAlgorithm = errors.New("unknown encryption algorithm")
+
+// ErrAADNotSupported алгоритм не поддерживает связанные данные
+var ErrAADNotSupported = errors.New("algorithm does not support associated data")
+
+// Algorithm алгоритм шифрования с аутентификацией
+// key - ключевой материал (мастер-ключ или ключ пользователя)
+// aad - связанные данные, которые не шифруются, но должны совпасть при расшифровке
+type Algorithm interface {
+	Encrypt(key []byte, data []byte, aad []byte) ([]byte, []byte, error)
+	Decrypt(key []byte, encryptedData []byte, iv []byte, aad []byte) ([]byte, error)
+}
+
+var (
+	algorithmsMu sync.RWMutex
+	algorithms   = map[string]Algorithm{
+		AlgorithmLegacyAES: legacyAES{},
+		AlgorithmAES256GCM: &aeadAlgorithm{
+			name:    AlgorithmAES256GCM,
+			newAEAD: newAESGCM,
+		},
+		AlgorithmXChaCha20Poly1305: &aeadAlgorithm{
+			name:    AlgorithmXChaCha20Poly1305,
+			newAEAD: chacha20poly1305.NewX,
+		},
+	}
+)
+
+// RegisterAlgorithm регистрация алгоритма в реестре
+func RegisterAlgorithm(name string, algorithm Algorithm) {
+	algorithmsMu.Lock()
+	defer algorithmsMu.Unlock()
+	algorithms[name] = algorithm
+}
+
+// GetAlgorithm получение алгоритма по названию, сохраненному вместе с данными
+func GetAlgorithm(name string) (Algorithm, error) {
+	algorithmsMu.RLock()
+	defer algorithmsMu.RUnlock()
+	algorithm, ok := algorithms[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAlgorithm, name)
+	}
+	return algorithm, nil
+}
+
+// aeadAlgorithm алгоритм на основе cipher.AEAD
+// ключ алгоритма выводится из ключевого материала через HKDF, поэтому длина исходного ключа не важна
+type aeadAlgorithm struct {
+	name    string
+	newAEAD func(key []byte) (cipher.AEAD, error)
+}
+
+func (a *aeadAlgorithm) aead(key []byte) (cipher.AEAD, error) {
+	derivedKey := make([]byte, derivedKeyLength)
+	reader := hkdf.New(sha256.New, key, nil, []byte("gophkeeper/"+a.name))
+	if _, err := io.ReadFull(reader, derivedKey); err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return a.newAEAD(derivedKey)
+}
+
+// Encrypt шифрование со случайным nonce
+func (a *aeadAlgorithm) Encrypt(key []byte, data []byte, aad []byte) ([]byte, []byte, error) {
+	aead, err := a.aead(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	iv := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate IV: %w", err)
+	}
+	return aead.Seal(nil, iv, data, aad), iv, nil
+}
+
+// Decrypt расшифровка
+func (a *aeadAlgorithm) Decrypt(key []byte, encryptedData []byte, iv []byte, aad []byte) ([]byte, error) {
+	aead, err := a.aead(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid IV length for %s", a.name)
+	}
+	data, err := aead.Open(nil, iv, encryptedData, aad)
+	if err != nil {
+		return nil, fmt.Errorf("decryption failed: %w", err)
+	}
+	return data, nil
+}
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// legacyAES алгоритм исторических записей, ключ используется как есть
+// исторические записи сохранялись без связанных данных
+type legacyAES struct{}
+
+// Encrypt шифрование
+func (legacyAES) Encrypt(key []byte, data []byte, aad []byte) ([]byte, []byte, error) {
+	if len(aad) > 0 {
+		return nil, nil, ErrAADNotSupported
+	}
+	encryptor := &aes256gcm.Encryptor{}
+	encryptor.SetEncryptionKey(key)
+	encryptedData, _, iv, err := encryptor.Encrypt(data)
+	return encryptedData, iv, err
+}
+
+// Decrypt расшифровка
+func (legacyAES) Decrypt(key []byte, encryptedData []byte, iv []byte, aad []byte) ([]byte, error) {
+	if len(aad) > 0 {
+		return nil, ErrAADNotSupported
+	}
+	decryptor := &aes256gcm.Decryptor{}
+	decryptor.SetDecryptionKey(key)
+	return decryptor.Decrypt(encryptedData, iv)
+}
@@ -0,0 +1,110 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/aes256gcm"
+)
+
+func TestAlgorithms_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+		key       []byte
+		ivLength  int
+	}{
+		{
+			name:      "legacy AES",
+			algorithm: AlgorithmLegacyAES,
+			key:       []byte("123456789012345678901234"),
+			ivLength:  12,
+		},
+		{
+			name:      "AES-256-GCM with master key",
+			algorithm: AlgorithmAES256GCM,
+			key:       []byte("123456789012345678901234"),
+			ivLength:  12,
+		},
+		{
+			name:      "AES-256-GCM with data key",
+			algorithm: AlgorithmAES256GCM,
+			key:       []byte("12345678901234567890123456789012"),
+			ivLength:  12,
+		},
+		{
+			name:      "XChaCha20-Poly1305",
+			algorithm: AlgorithmXChaCha20Poly1305,
+			key:       []byte("123456789012345678901234"),
+			ivLength:  24,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encryptor, err := NewEncryptor(tt.key, tt.algorithm)
+			require.NoError(t, err)
+			decryptor, err := NewDecryptor(tt.key)
+			require.NoError(t, err)
+
+			encryptedData, algorithm, iv, err := encryptor.Encrypt([]byte("secret"), nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.algorithm, algorithm)
+			assert.Len(t, iv, tt.ivLength)
+
+			data, err := decryptor.Decrypt(encryptedData, iv, algorithm, nil)
+			require.NoError(t, err)
+			assert.Equal(t, []byte("secret"), data)
+
+			// Алгоритм записи определяет способ расшифровки
+			for _, other := range []string{AlgorithmLegacyAES, AlgorithmAES256GCM, AlgorithmXChaCha20Poly1305} {
+				if other == algorithm {
+					continue
+				}
+				_, err = decryptor.Decrypt(encryptedData, iv, other, nil)
+				assert.Error(t, err, other)
+			}
+		})
+	}
+}
+
+func TestAlgorithms_LegacyRows(t *testing.T) {
+	key := []byte("123456789012345678901234")
+
+	// Запись, сохраненная до появления реестра алгоритмов
+	legacyEncryptor := &aes256gcm.Encryptor{}
+	legacyEncryptor.SetEncryptionKey(key)
+	encryptedData, algorithm, iv, err := legacyEncryptor.Encrypt([]byte("legacy"))
+	require.NoError(t, err)
+	assert.Equal(t, AlgorithmLegacyAES, algorithm)
+
+	decryptor, err := NewDecryptor(key)
+	require.NoError(t, err)
+	data, err := decryptor.Decrypt(encryptedData, iv, algorithm, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("legacy"), data)
+}
+
+func TestGetAlgorithm(t *testing.T) {
+	_, err := GetAlgorithm("DES")
+	assert.ErrorIs(t, err, ErrUnknownAlgorithm)
+
+	RegisterAlgorithm("TEST", legacyAES{})
+	got, err := GetAlgorithm("TEST")
+	assert.NoError(t, err)
+	assert.Equal(t, legacyAES{}, got)
+}
+
+func TestManager_SetAlgorithm(t *testing.T) {
+	cm := NewCryptoManager()
+	assert.ErrorIs(t, cm.SetAlgorithm("DES"), ErrUnknownAlgorithm)
+
+	require.NoError(t, cm.SetAlgorithm(AlgorithmXChaCha20Poly1305))
+	require.NoError(t, cm.AddMasterKey(1, []byte("123456789012345678901234")))
+	require.NoError(t, cm.UseMasterKey(1))
+
+	_, algorithm, _, err := cm.GetGRPCEncryptor().Encrypt([]byte("secret"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, AlgorithmXChaCha20Poly1305, algorithm)
+}
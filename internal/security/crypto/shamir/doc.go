@@ -0,0 +1,4 @@
+// Package shamir разделение секрета на доли по схеме Шамира над полем GF(2^8)
+// - секрет делится на n долей, любые k из них восстанавливают секрет
+// - меньше k долей не дают о секрете никакой информации
+package shamir
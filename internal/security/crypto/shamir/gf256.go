@@ -0,0 +1,49 @@
+package shamir
+
+// таблицы логарифмов и степеней генератора 3 в поле GF(2^8) с многочленом x^8+x^4+x^3+x+1
+var (
+	expTable [255]byte
+	logTable [256]byte
+)
+
+func init() {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		expTable[i] = x
+		logTable[x] = byte(i)
+		// умножение на генератор 3: x*2 + x
+		x ^= xtime(x)
+	}
+}
+
+// xtime умножение на 2 с приведением по модулю многочлена поля
+func xtime(x byte) byte {
+	if x&0x80 != 0 {
+		return x<<1 ^ 0x1b
+	}
+	return x << 1
+}
+
+// add сложение в поле, оно же вычитание
+func add(a, b byte) byte {
+	return a ^ b
+}
+
+// mul умножение в поле
+func mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return expTable[(int(logTable[a])+int(logTable[b]))%255]
+}
+
+// div деление в поле, делитель не равен нулю
+func div(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	if b == 0 {
+		panic("shamir: division by zero")
+	}
+	return expTable[(int(logTable[a])-int(logTable[b])+255)%255]
+}
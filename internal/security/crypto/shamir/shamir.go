@@ -0,0 +1,126 @@
+package shamir
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+const (
+	// MaxShares максимальное число долей, x-координата доли занимает один байт
+	MaxShares = 255
+	// MinThreshold минимальный порог восстановления секрета
+	MinThreshold = 2
+)
+
+var (
+	// ErrEmptySecret пустой секрет
+	ErrEmptySecret = errors.New("secret is empty")
+	// ErrInvalidShare доля повреждена или не подходит к остальным
+	ErrInvalidShare = errors.New("invalid share")
+	// ErrDuplicateShare доля передана повторно
+	ErrDuplicateShare = errors.New("duplicate share")
+	// ErrNotEnoughShares долей меньше минимального порога
+	ErrNotEnoughShares = errors.New("not enough shares")
+)
+
+// Split разделение секрета на parts долей, из которых любые threshold восстанавливают секрет
+// доля содержит значения многочленов для каждого байта секрета и x-координату последним байтом
+func Split(secret []byte, parts, threshold int) ([][]byte, error) {
+	if len(secret) == 0 {
+		return nil, ErrEmptySecret
+	}
+	if threshold < MinThreshold {
+		return nil, fmt.Errorf("threshold must be at least %d", MinThreshold)
+	}
+	if parts < threshold || parts > MaxShares {
+		return nil, fmt.Errorf("parts must be between threshold and %d", MaxShares)
+	}
+
+	shares := make([][]byte, parts)
+	for i := range shares {
+		shares[i] = make([]byte, len(secret)+1)
+		shares[i][len(secret)] = byte(i + 1)
+	}
+
+	// для каждого байта секрета свой случайный многочлен степени threshold-1,
+	// свободный член многочлена - байт секрета
+	coefficients := make([]byte, threshold)
+	for idx, secretByte := range secret {
+		if _, err := rand.Read(coefficients[1:]); err != nil {
+			return nil, fmt.Errorf("failed to generate polynomial: %w", err)
+		}
+		coefficients[0] = secretByte
+		for _, share := range shares {
+			share[idx] = evaluate(coefficients, share[len(secret)])
+		}
+	}
+	clear(coefficients)
+	return shares, nil
+}
+
+// Combine восстановление секрета из долей интерполяцией Лагранжа в точке 0
+// долей должно быть не меньше порога, с которым секрет был разделен,
+// иначе результат будет случайным
+func Combine(shares [][]byte) ([]byte, error) {
+	if len(shares) < MinThreshold {
+		return nil, ErrNotEnoughShares
+	}
+
+	shareLen := len(shares[0])
+	if shareLen < 2 {
+		return nil, ErrInvalidShare
+	}
+	xs := make([]byte, len(shares))
+	seen := make(map[byte]bool, len(shares))
+	for i, share := range shares {
+		if len(share) != shareLen {
+			return nil, ErrInvalidShare
+		}
+		x := share[shareLen-1]
+		if x == 0 {
+			return nil, ErrInvalidShare
+		}
+		if seen[x] {
+			return nil, ErrDuplicateShare
+		}
+		seen[x] = true
+		xs[i] = x
+	}
+
+	secret := make([]byte, shareLen-1)
+	ys := make([]byte, len(shares))
+	for idx := range secret {
+		for i, share := range shares {
+			ys[i] = share[idx]
+		}
+		secret[idx] = interpolateAtZero(xs, ys)
+	}
+	return secret, nil
+}
+
+// evaluate значение многочлена в точке x по схеме Горнера
+func evaluate(coefficients []byte, x byte) byte {
+	result := coefficients[len(coefficients)-1]
+	for i := len(coefficients) - 2; i >= 0; i-- {
+		result = add(mul(result, x), coefficients[i])
+	}
+	return result
+}
+
+// interpolateAtZero значение в точке 0 многочлена, проходящего через точки (xs, ys)
+func interpolateAtZero(xs, ys []byte) byte {
+	var result byte
+	for i := range xs {
+		basis := byte(1)
+		for j := range xs {
+			if i == j {
+				continue
+			}
+			// в GF(2^8) вычитание совпадает со сложением: (0 - xj) / (xi - xj) = xj / (xi ^ xj)
+			basis = mul(basis, div(xs[j], add(xs[i], xs[j])))
+		}
+		result = add(result, mul(ys[i], basis))
+	}
+	return result
+}
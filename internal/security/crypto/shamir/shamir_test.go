@@ -0,0 +1,115 @@
+package shamir
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitCombine(t *testing.T) {
+	secret := []byte("12345678901234567890123456789012")
+	shares, err := Split(secret, 5, 3)
+	require.NoError(t, err)
+	require.Len(t, shares, 5)
+
+	tests := []struct {
+		name   string
+		shares [][]byte
+		want   []byte
+	}{
+		{
+			name:   "first three",
+			shares: [][]byte{shares[0], shares[1], shares[2]},
+			want:   secret,
+		},
+		{
+			name:   "last three in other order",
+			shares: [][]byte{shares[4], shares[2], shares[3]},
+			want:   secret,
+		},
+		{
+			name:   "all shares",
+			shares: shares,
+			want:   secret,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Combine(tt.shares)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	got, err := Combine([][]byte{shares[0], shares[1]})
+	require.NoError(t, err)
+	assert.NotEqual(t, secret, got, "меньше порога долей не восстанавливают секрет")
+}
+
+func TestSplit_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    []byte
+		parts     int
+		threshold int
+	}{
+		{name: "empty secret", parts: 3, threshold: 2},
+		{name: "threshold too small", secret: []byte("key"), parts: 3, threshold: 1},
+		{name: "parts less than threshold", secret: []byte("key"), parts: 2, threshold: 3},
+		{name: "too many parts", secret: []byte("key"), parts: 256, threshold: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Split(tt.secret, tt.parts, tt.threshold)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCombine_Errors(t *testing.T) {
+	shares, err := Split([]byte("key"), 3, 2)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		shares  [][]byte
+		wantErr error
+	}{
+		{
+			name:    "one share",
+			shares:  [][]byte{shares[0]},
+			wantErr: ErrNotEnoughShares,
+		},
+		{
+			name:    "duplicate share",
+			shares:  [][]byte{shares[0], shares[0]},
+			wantErr: ErrDuplicateShare,
+		},
+		{
+			name:    "different length",
+			shares:  [][]byte{shares[0], shares[1][1:]},
+			wantErr: ErrInvalidShare,
+		},
+		{
+			name:    "zero x coordinate",
+			shares:  [][]byte{shares[0], {1, 2, 3, 0}},
+			wantErr: ErrInvalidShare,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Combine(tt.shares)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestField(t *testing.T) {
+	for a := 1; a < 256; a++ {
+		for b := 1; b < 256; b++ {
+			assert.Equal(t, byte(a), div(mul(byte(a), byte(b)), byte(b)))
+		}
+	}
+	assert.Equal(t, byte(0xc1), mul(0x57, 0x83))
+}
@@ -0,0 +1,17 @@
+package vault
+
+import "fmt"
+
+// Связанные данные (AAD) привязывают шифротекст клиента к записи или части файла,
+// поэтому сервер не может незаметно подменить данные одной записи данными другой
+
+// ItemAAD связанные данные записи, зашифрованной на клиенте
+func ItemAAD(itemID int64, itemType string) []byte {
+	return fmt.Appendf(nil, "gophkeeper/e2e-item/v1|item:%d|type:%s", itemID, itemType)
+}
+
+// ChunkAAD связанные данные части файла, зашифрованной на клиенте
+// номер части и признак последней части не дают переставить части или отрезать конец файла
+func ChunkAAD(fileID int64, chunkIndex int32, final bool) []byte {
+	return fmt.Appendf(nil, "gophkeeper/e2e-chunk/v1|file:%d|index:%d|final:%t", fileID, chunkIndex, final)
+}
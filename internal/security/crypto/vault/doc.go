@@ -0,0 +1,6 @@
+// Package vault реализует сквозное (клиентское) шифрование данных хранилища.
+//
+// Ключ хранилища генерируется на клиенте и хранится на сервере только в зашифрованном виде:
+// он обернут ключом, выведенным из мастер-пароля пользователя через Argon2id с персональной солью.
+// Сервер получает от клиента только непрозрачные зашифрованные блоки и не может их прочитать.
+package vault
@@ -0,0 +1,53 @@
+package vault
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+)
+
+// KeyFile описывает сохраненные на клиенте параметры ключа хранилища
+// сам ключ хранится только в обернутом виде, для разблокировки нужен мастер-пароль
+type KeyFile struct {
+	KdfSalt         []byte `json:"kdf_salt"`
+	WrappedVaultKey []byte `json:"wrapped_vault_key"`
+}
+
+// SaveKeyFile сохранение параметров ключа хранилища в файл
+func SaveKeyFile(filename string, kdfSalt, wrappedVaultKey []byte) error {
+	data, err := json.MarshalIndent(KeyFile{
+		KdfSalt:         kdfSalt,
+		WrappedVaultKey: wrappedVaultKey,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0600)
+}
+
+// LoadKeyFile загрузка параметров ключа хранилища из файла
+// если файла нет, возвращается nil без ошибки
+func LoadKeyFile(filename string) (*KeyFile, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var keyFile KeyFile
+	if err = json.Unmarshal(data, &keyFile); err != nil {
+		return nil, err
+	}
+	return &keyFile, nil
+}
+
+// RemoveKeyFile удаление файла с параметрами ключа хранилища
+func RemoveKeyFile(filename string) error {
+	err := os.Remove(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
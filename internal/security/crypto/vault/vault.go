@@ -0,0 +1,183 @@
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Algorithm метка алгоритма для данных, зашифрованных на клиенте
+const Algorithm = "E2E-AES-256-GCM"
+
+const (
+	argonTime    = 3
+	argonMemory  = 64 * 1024
+	argonThreads = 4
+	keyLength    = 32
+	saltLength   = 16
+	nonceLength  = 12
+)
+
+// ErrInvalidCiphertext данные слишком короткие или повреждены
+var ErrInvalidCiphertext = errors.New("invalid ciphertext")
+
+// Vault шифровальщик данных ключом хранилища
+type Vault struct {
+	key []byte
+}
+
+// New инициализация хранилища с ключом хранилища
+func New(key []byte) (*Vault, error) {
+	if len(key) != keyLength {
+		return nil, fmt.Errorf("vault key must be %d bytes", keyLength)
+	}
+	return &Vault{key: key}, nil
+}
+
+// NewSalt генерация персональной соли пользователя
+func NewSalt() ([]byte, error) {
+	return randomBytes(saltLength)
+}
+
+// NewKey генерация случайного ключа хранилища
+func NewKey() ([]byte, error) {
+	return randomBytes(keyLength)
+}
+
+// DeriveKey вывод ключа шифрования ключа (KEK) из мастер-пароля и соли через Argon2id
+func DeriveKey(password string, salt []byte) []byte {
+	return argon2.IDKey([]byte(password), salt, argonTime, argonMemory, argonThreads, keyLength)
+}
+
+// WrapKey шифрование ключа хранилища ключом, выведенным из мастер-пароля
+func WrapKey(kek, vaultKey []byte) ([]byte, error) {
+	return seal(kek, vaultKey, nil)
+}
+
+// UnwrapKey расшифровка ключа хранилища ключом, выведенным из мастер-пароля
+func UnwrapKey(kek, wrappedKey []byte) ([]byte, error) {
+	key, err := open(kek, wrappedKey, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap vault key: %w", err)
+	}
+	return key, nil
+}
+
+// Unlock получение хранилища по мастер-паролю, соли и обернутому ключу
+func Unlock(password string, salt, wrappedKey []byte) (*Vault, error) {
+	key, err := UnwrapKey(DeriveKey(password, salt), wrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	return New(key)
+}
+
+// Rewrap обертывание ключа хранилища ключом из нового мастер-пароля со свежей солью
+// сам ключ хранилища не меняется, поэтому данные перешифровывать не нужно
+func Rewrap(oldPassword, newPassword string, salt, wrappedKey []byte) ([]byte, []byte, error) {
+	key, err := UnwrapKey(DeriveKey(oldPassword, salt), wrappedKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	newSalt, err := NewSalt()
+	if err != nil {
+		return nil, nil, err
+	}
+	newWrappedKey, err := WrapKey(DeriveKey(newPassword, newSalt), key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return newSalt, newWrappedKey, nil
+}
+
+// Generate создание нового хранилища для мастер-пароля
+// возвращает хранилище, соль и обернутый ключ для сохранения на сервере
+func Generate(password string) (*Vault, []byte, []byte, error) {
+	salt, err := NewSalt()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	key, err := NewKey()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	wrappedKey, err := WrapKey(DeriveKey(password, salt), key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	v, err := New(key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return v, salt, wrappedKey, nil
+}
+
+// Seal шифрование данных, результат содержит nonce и шифротекст
+// aad связанные данные (ItemAAD, ChunkAAD), без них шифротекст не расшифруется
+func (v *Vault) Seal(data, aad []byte) ([]byte, error) {
+	return seal(v.key, data, aad)
+}
+
+// Open расшифровка данных, полученных из Seal с теми же связанными данными
+func (v *Vault) Open(data, aad []byte) ([]byte, error) {
+	return open(v.key, data, aad)
+}
+
+func seal(key, data, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := randomBytes(nonceLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return gcm.Seal(nonce, nonce, data, aad), nil
+}
+
+func open(key, data, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < nonceLength+gcm.Overhead() {
+		return nil, ErrInvalidCiphertext
+	}
+
+	decryptedData, err := gcm.Open(nil, data[:nonceLength], data[nonceLength:], aad)
+	if err != nil {
+		return nil, fmt.Errorf("decryption failed: %w", err)
+	}
+	return decryptedData, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != keyLength {
+		return nil, fmt.Errorf("key must be %d bytes", keyLength)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// IsClientEncrypted данные с таким алгоритмом зашифрованы на клиенте и сервер не может их расшифровать
+func IsClientEncrypted(algorithm string) bool {
+	return algorithm == Algorithm
+}
@@ -0,0 +1,166 @@
+package vault
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnlock(t *testing.T) {
+	salt, err := NewSalt()
+	require.NoError(t, err)
+	vaultKey, err := NewKey()
+	require.NoError(t, err)
+
+	wrappedKey, err := WrapKey(DeriveKey("master password", salt), vaultKey)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{
+			name:     "correct password",
+			password: "master password",
+		},
+		{
+			name:     "wrong password",
+			password: "wrong password",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := Unlock(tt.password, salt, wrappedKey)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, vaultKey, v.key)
+		})
+	}
+}
+
+func TestVault_SealOpen(t *testing.T) {
+	key, err := NewKey()
+	require.NoError(t, err)
+	v, err := New(key)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"text", []byte(`{"login":"user","password":"secret"}`)},
+		{"empty", []byte{}},
+		{"binary", []byte{0x00, 0x01, 0xFF}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aad := ItemAAD(1, "text")
+			sealed, err := v.Seal(tt.data, aad)
+			require.NoError(t, err)
+			assert.NotEqual(t, tt.data, sealed)
+
+			opened, err := v.Open(sealed, aad)
+			require.NoError(t, err)
+			assert.Equal(t, string(tt.data), string(opened))
+
+			// шифротекст другой записи не расшифровывается
+			_, err = v.Open(sealed, ItemAAD(2, "text"))
+			assert.Error(t, err)
+			_, err = v.Open(sealed, ItemAAD(1, "password"))
+			assert.Error(t, err)
+
+			sealed[len(sealed)-1] ^= 0xFF
+			_, err = v.Open(sealed, aad)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestVault_OpenShortData(t *testing.T) {
+	key, err := NewKey()
+	require.NoError(t, err)
+	v, err := New(key)
+	require.NoError(t, err)
+
+	_, err = v.Open([]byte("short"), nil)
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+}
+
+func TestNew_InvalidKey(t *testing.T) {
+	_, err := New([]byte("short key"))
+	assert.Error(t, err)
+}
+
+func TestKeyFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), ".vault.json")
+
+	keyFile, err := LoadKeyFile(filename)
+	assert.NoError(t, err)
+	assert.Nil(t, keyFile)
+
+	err = SaveKeyFile(filename, []byte("salt"), []byte("wrapped"))
+	require.NoError(t, err)
+
+	keyFile, err = LoadKeyFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("salt"), keyFile.KdfSalt)
+	assert.Equal(t, []byte("wrapped"), keyFile.WrappedVaultKey)
+
+	assert.NoError(t, RemoveKeyFile(filename))
+	assert.NoError(t, RemoveKeyFile(filename))
+}
+
+func TestGenerate(t *testing.T) {
+	v, salt, wrappedKey, err := Generate("master password")
+	require.NoError(t, err)
+
+	unlocked, err := Unlock("master password", salt, wrappedKey)
+	require.NoError(t, err)
+	assert.Equal(t, v.key, unlocked.key)
+}
+
+func TestRewrap(t *testing.T) {
+	v, salt, wrappedKey, err := Generate("old password")
+	require.NoError(t, err)
+
+	newSalt, newWrappedKey, err := Rewrap("old password", "new password", salt, wrappedKey)
+	require.NoError(t, err)
+	assert.NotEqual(t, salt, newSalt)
+
+	// Ключ хранилища прежний, открывается только новым паролем
+	unlocked, err := Unlock("new password", newSalt, newWrappedKey)
+	require.NoError(t, err)
+	assert.Equal(t, v.key, unlocked.key)
+	_, err = Unlock("old password", newSalt, newWrappedKey)
+	assert.Error(t, err)
+
+	_, _, err = Rewrap("wrong password", "new password", salt, wrappedKey)
+	assert.Error(t, err)
+}
+
+func TestVault_OpenChunk(t *testing.T) {
+	key, err := NewKey()
+	require.NoError(t, err)
+	v, err := New(key)
+	require.NoError(t, err)
+
+	sealed, err := v.Seal([]byte("chunk"), ChunkAAD(5, 1, true))
+	require.NoError(t, err)
+
+	opened, err := v.Open(sealed, ChunkAAD(5, 1, true))
+	require.NoError(t, err)
+	assert.Equal(t, "chunk", string(opened))
+
+	// часть другого файла, с другим номером или без признака последней части не расшифровывается
+	for _, aad := range [][]byte{ChunkAAD(6, 1, true), ChunkAAD(5, 0, true), ChunkAAD(5, 1, false)} {
+		_, err = v.Open(sealed, aad)
+		assert.Error(t, err)
+	}
+}
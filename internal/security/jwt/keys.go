@@ -0,0 +1,160 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// SigningAlgorithm алгоритм подписи токенов доступа (заголовок alg)
+const SigningAlgorithm = "EdDSA"
+
+// ErrUnknownKey ключ с таким идентификатором не найден среди ключей проверки
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// PublicKey открытый ключ проверки подписи токенов
+type PublicKey struct {
+	ID  string
+	Key ed25519.PublicKey
+}
+
+// KeySet ключи подписи токенов доступа
+// новые токены подписываются текущим закрытым ключом, а проверяются любым из открытых ключей по заголовку kid,
+// поэтому при ротации токены, подписанные предыдущим ключом, остаются действительными до истечения срока
+type KeySet struct {
+	mu         sync.RWMutex
+	signingID  string
+	signingKey ed25519.PrivateKey
+	publicKeys map[string]ed25519.PublicKey
+}
+
+// NewKeySet инициализация набора ключей с текущим ключом подписи
+// если идентификатор не задан, он вычисляется по открытому ключу
+func NewKeySet(keyID string, signingKey ed25519.PrivateKey) (*KeySet, error) {
+	if len(signingKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid ed25519 private key size %d", len(signingKey))
+	}
+	publicKey := signingKey.Public().(ed25519.PublicKey)
+	if keyID == "" {
+		keyID = KeyID(publicKey)
+	}
+	return &KeySet{
+		signingID:  keyID,
+		signingKey: signingKey,
+		publicKeys: map[string]ed25519.PublicKey{keyID: publicKey},
+	}, nil
+}
+
+// NewEphemeralKeySet набор со случайным ключом подписи, который нигде не сохраняется
+// после перезапуска сервера выданные токены доступа не проверяются, их нужно обновить через Refresh
+func NewEphemeralKeySet() (*KeySet, error) {
+	_, signingKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeySet("", signingKey)
+}
+
+// AddVerificationKey добавление открытого ключа предыдущего ключа подписи
+func (k *KeySet) AddVerificationKey(keyID string, publicKey ed25519.PublicKey) error {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid ed25519 public key size %d", len(publicKey))
+	}
+	if keyID == "" {
+		keyID = KeyID(publicKey)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if existing, ok := k.publicKeys[keyID]; ok {
+		if bytes.Equal(existing, publicKey) {
+			return nil
+		}
+		return fmt.Errorf("signing key %s already added", keyID)
+	}
+	k.publicKeys[keyID] = publicKey
+	return nil
+}
+
+// SigningKeyID идентификатор текущего ключа подписи
+func (k *KeySet) SigningKeyID() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.signingID
+}
+
+// VerificationKey открытый ключ по идентификатору из заголовка kid
+func (k *KeySet) VerificationKey(keyID string) (ed25519.PublicKey, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	publicKey, ok := k.publicKeys[keyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return publicKey, nil
+}
+
+// PublicKeys все открытые ключи проверки, текущий ключ подписи первым
+func (k *KeySet) PublicKeys() []PublicKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	keys := make([]PublicKey, 0, len(k.publicKeys))
+	for id, key := range k.publicKeys {
+		keys = append(keys, PublicKey{ID: id, Key: key})
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if (keys[i].ID == k.signingID) != (keys[j].ID == k.signingID) {
+			return keys[i].ID == k.signingID
+		}
+		return keys[i].ID < keys[j].ID
+	})
+	return keys
+}
+
+// signer текущий ключ подписи
+func (k *KeySet) signer() (string, ed25519.PrivateKey) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.signingID, k.signingKey
+}
+
+// KeyID идентификатор ключа по умолчанию: начало SHA-256 открытого ключа в base64url
+func KeyID(publicKey ed25519.PublicKey) string {
+	sum := sha256.Sum256(publicKey)
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
+
+// ParsePrivateKey разбор закрытого ключа: seed Ed25519 (32 байта) в исходном виде или в base64
+func ParsePrivateKey(data []byte) (ed25519.PrivateKey, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) != ed25519.SeedSize {
+		decoded, err := base64.StdEncoding.DecodeString(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode ed25519 seed: %w", err)
+		}
+		data = decoded
+	}
+	if len(data) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid ed25519 seed size %d", len(data))
+	}
+	return ed25519.NewKeyFromSeed(data), nil
+}
+
+// ParsePublicKey разбор открытого ключа Ed25519 в base64
+func ParsePublicKey(data string) (ed25519.PublicKey, error) {
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ed25519 public key: %w", err)
+	}
+	if len(decoded) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid ed25519 public key size %d", len(decoded))
+	}
+	return decoded, nil
+}
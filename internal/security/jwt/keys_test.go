@@ -0,0 +1,102 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeySet_Rotation(t *testing.T) {
+	previous, err := NewEphemeralKeySet()
+	require.NoError(t, err)
+	oldToken, err := GenerateAccessToken(1, previous)
+	require.NoError(t, err)
+
+	_, signingKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	current, err := NewKeySet("2026-10", signingKey)
+	require.NoError(t, err)
+
+	previousKey, err := previous.VerificationKey(previous.SigningKeyID())
+	require.NoError(t, err)
+	require.NoError(t, current.AddVerificationKey("", previousKey))
+	// повторное добавление того же ключа не ошибка, другой ключ с тем же kid - ошибка
+	assert.NoError(t, current.AddVerificationKey(previous.SigningKeyID(), previousKey))
+	assert.Error(t, current.AddVerificationKey("2026-10", previousKey))
+
+	keys := current.PublicKeys()
+	require.Len(t, keys, 2)
+	assert.Equal(t, "2026-10", keys[0].ID)
+	assert.Equal(t, previous.SigningKeyID(), keys[1].ID)
+
+	// токен, подписанный предыдущим ключом, проверяется по его kid
+	token, err := jwt.Parse(oldToken, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return current.VerificationKey(kid)
+	})
+	require.NoError(t, err)
+	assert.True(t, token.Valid)
+
+	_, err = current.VerificationKey("unknown")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+	want := ed25519.NewKeyFromSeed(seed)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{
+			name: "raw seed",
+			data: seed,
+		},
+		{
+			name: "base64 seed with newline",
+			data: []byte(base64.StdEncoding.EncodeToString(seed) + "\n"),
+		},
+		{
+			name:    "short seed",
+			data:    []byte(base64.StdEncoding.EncodeToString(seed[:16])),
+			wantErr: true,
+		},
+		{
+			name:    "not base64",
+			data:    []byte("not a key"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePrivateKey(tt.data)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	publicKey, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	got, err := ParsePublicKey(base64.StdEncoding.EncodeToString(publicKey))
+	require.NoError(t, err)
+	assert.Equal(t, publicKey, got)
+
+	_, err = ParsePublicKey(base64.StdEncoding.EncodeToString(publicKey[:10]))
+	assert.Error(t, err)
+}
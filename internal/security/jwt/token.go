@@ -9,16 +9,20 @@ import (
 	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
 )
 
-func GenerateAccessToken(userID int, secret string) (string, error) {
+// GenerateAccessToken токен доступа, подписанный текущим ключом набора (Ed25519)
+// идентификатор ключа передается в заголовке kid
+func GenerateAccessToken(userID int, keys *KeySet) (string, error) {
+	keyID, signingKey := keys.signer()
 	expired := time.Duration(auth.TokenExpiredSeconds)
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
 		"user_id": userID,
 		"sub":     userID,
 		"exp":     time.Now().Add(expired * time.Second).Unix(),
 		"iat":     time.Now().Unix(),
 		"type":    "access",
 	})
-	return token.SignedString([]byte(secret))
+	token.Header["kid"] = keyID
+	return token.SignedString(signingKey)
 }
 
 func GenerateRefreshToken() (string, error) {
@@ -28,3 +32,12 @@ func GenerateRefreshToken() (string, error) {
 	}
 	return hex.EncodeToString(token), nil
 }
+
+// GenerateTokenFamilyID идентификатор семейства refresh token'ов, создается при входе
+func GenerateTokenFamilyID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
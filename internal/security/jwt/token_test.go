@@ -1,6 +1,7 @@
 package jwt
 
 import (
+	"crypto/ed25519"
 	"encoding/hex"
 	"fmt"
 	"strings"
@@ -13,21 +14,27 @@ import (
 func TestGenerateAccessToken_Integration(t *testing.T) {
 	// Тестовые данные
 	testUserID := 123
-	testSecret := "my_super_secret_key_123456"
+	keys, err := NewEphemeralKeySet()
+	if err != nil {
+		t.Fatalf("NewEphemeralKeySet failed: %v", err)
+	}
 
 	// Генерируем токен
-	tokenString, err := GenerateAccessToken(testUserID, testSecret)
+	tokenString, err := GenerateAccessToken(testUserID, keys)
 	if err != nil {
 		t.Fatalf("GenerateAccessToken failed: %v", err)
 	}
 
 	// Верифицируем токен
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Проверяем алгоритм
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		// Проверяем алгоритм и идентификатор ключа
+		if _, ok := token.Method.(*jwt.SigningMethodEd25519); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(testSecret), nil
+		if token.Header["kid"] != keys.SigningKeyID() {
+			return nil, fmt.Errorf("unexpected kid: %v", token.Header["kid"])
+		}
+		return keys.VerificationKey(keys.SigningKeyID())
 	})
 
 	if err != nil {
@@ -69,87 +76,60 @@ func TestGenerateAccessToken_Integration(t *testing.T) {
 }
 
 func TestGenerateAccessToken_TableDriven(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	namedKeys, err := NewKeySet("2026-10", ed25519.NewKeyFromSeed(seed))
+	if err != nil {
+		t.Fatalf("NewKeySet failed: %v", err)
+	}
+	ephemeralKeys, err := NewEphemeralKeySet()
+	if err != nil {
+		t.Fatalf("NewEphemeralKeySet failed: %v", err)
+	}
+
 	testCases := []struct {
-		name          string
-		userID        int
-		secret        string
-		expectError   bool
-		errorContains string
-		setup         func() // Дополнительная настройка
+		name   string
+		userID int
+		keys   *KeySet
 	}{
 		{
-			name:        "valid token generation",
-			userID:      1,
-			secret:      "valid_secret_key_1234567890",
-			expectError: false,
-		},
-		{
-			name:        "another valid user",
-			userID:      999,
-			secret:      "different_secret_123456",
-			expectError: false,
+			name:   "valid token generation",
+			userID: 1,
+			keys:   namedKeys,
 		},
 		{
-			name:        "zero user id",
-			userID:      0,
-			secret:      "secret_key",
-			expectError: false,
+			name:   "another valid user",
+			userID: 999,
+			keys:   ephemeralKeys,
 		},
 		{
-			name:        "negative user id",
-			userID:      -1,
-			secret:      "secret_key",
-			expectError: false,
+			name:   "zero user id",
+			userID: 0,
+			keys:   namedKeys,
 		},
 		{
-			name:        "very long secret",
-			userID:      1,
-			secret:      strings.Repeat("a", 1000), // Длинный секрет
-			expectError: false,
-		},
-		{
-			name:        "special chars in secret",
-			userID:      1,
-			secret:      "secret!@#$%^&*()_+-=[]{}|;:,.<>?",
-			expectError: false,
+			name:   "negative user id",
+			userID: -1,
+			keys:   namedKeys,
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			if tc.setup != nil {
-				tc.setup()
-			}
-
-			tokenString, err := GenerateAccessToken(tc.userID, tc.secret)
-
-			if tc.expectError {
-				if err == nil {
-					t.Error("Expected error, got nil")
-				} else if tc.errorContains != "" && !strings.Contains(err.Error(), tc.errorContains) {
-					t.Errorf("Error should contain '%s', got: %v", tc.errorContains, err)
-				}
-				return
-			}
-
+			tokenString, err := GenerateAccessToken(tc.userID, tc.keys)
 			if err != nil {
 				t.Fatalf("Unexpected error: %v", err)
 			}
 
-			// Basic validation of the token
-			if tokenString == "" {
-				t.Error("Generated token should not be empty")
-			}
-
 			// Check if it looks like a JWT token (3 parts separated by dots)
 			parts := strings.Split(tokenString, ".")
 			if len(parts) != 3 {
 				t.Errorf("JWT token should have 3 parts, got %d", len(parts))
 			}
 
-			// Verify we can parse it back with the same secret
+			// Verify we can parse it back with the public key from kid
 			token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-				return []byte(tc.secret), nil
+				kid, _ := token.Header["kid"].(string)
+				return tc.keys.VerificationKey(kid)
 			})
 
 			if err != nil {
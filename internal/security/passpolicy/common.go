@@ -0,0 +1,38 @@
+package passpolicy
+
+import (
+	_ "embed"
+	"strings"
+)
+
+// commonPasswordsList распространенные пароли, отсортированные по частоте использования
+//
+//go:embed common_passwords.txt
+var commonPasswordsList string
+
+// commonPasswords ранг распространенного пароля: 1 - самый частый
+var commonPasswords = loadCommonPasswords(commonPasswordsList)
+
+func loadCommonPasswords(list string) map[string]int {
+	ranks := make(map[string]int)
+	for _, line := range strings.Split(list, "\n") {
+		word := strings.ToLower(strings.TrimSpace(line))
+		if word == "" {
+			continue
+		}
+		if _, ok := ranks[word]; !ok {
+			ranks[word] = len(ranks) + 1
+		}
+	}
+	return ranks
+}
+
+// IsCommon входит ли пароль в список распространенных без учета регистра и замен букв похожими символами
+func IsCommon(password string) bool {
+	lower := strings.ToLower(password)
+	if _, ok := commonPasswords[lower]; ok {
+		return true
+	}
+	_, ok := commonPasswords[unleet([]rune(lower))]
+	return ok
+}
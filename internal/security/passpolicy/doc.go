@@ -0,0 +1,5 @@
+// Package passpolicy политика паролей пользователей и оценка их надежности
+// оценка построена по принципу zxcvbn: пароль разбивается на известные шаблоны (распространенные пароли,
+// последовательности, повторы, ряды клавиатуры, годы) и остаток, для каждого шаблона оценивается число попыток
+// подбора, а итоговая оценка - наименьшее число попыток по всем разбиениям
+package passpolicy
@@ -0,0 +1,63 @@
+package passpolicy
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Значения политики по умолчанию
+const (
+	DefaultMinLength = 10
+	DefaultMinScore  = ScoreGood
+)
+
+// minLoginInPassword логин короче не ищется в пароле, иначе запрещались бы случайные совпадения
+const minLoginInPassword = 3
+
+// Policy требования к паролю пользователя
+type Policy struct {
+	MinLength    int  `json:"min_length"`    // Наименьшая длина в символах
+	MinScore     int  `json:"min_score"`     // Наименьшая оценка надежности от 0 до 4
+	ForbidLogin  bool `json:"forbid_login"`  // Запрет пароля, содержащего логин
+	ForbidCommon bool `json:"forbid_common"` // Запрет распространенных паролей
+}
+
+// DefaultPolicy требования к паролю по умолчанию
+func DefaultPolicy() Policy {
+	return Policy{
+		MinLength:    DefaultMinLength,
+		MinScore:     DefaultMinScore,
+		ForbidLogin:  true,
+		ForbidCommon: true,
+	}
+}
+
+// Check проверка пароля, возвращает описания всех нарушенных требований
+// userInputs - другие данные пользователя (имя, фамилия), которые снижают оценку надежности
+func (p Policy) Check(login, password string, userInputs ...string) []string {
+	if password == "" {
+		return []string{"password is required"}
+	}
+
+	var violations []string
+	if length := utf8.RuneCountInString(password); length < p.MinLength {
+		violations = append(violations, fmt.Sprintf("password must be at least %d characters long", p.MinLength))
+	}
+	if p.ForbidLogin && utf8.RuneCountInString(login) >= minLoginInPassword &&
+		strings.Contains(strings.ToLower(password), strings.ToLower(login)) {
+		violations = append(violations, "password must not contain the login")
+	}
+	if p.ForbidCommon && IsCommon(password) {
+		violations = append(violations, "password is too common")
+	}
+	if p.MinScore > ScoreVeryWeak {
+		strength := Estimate(password, append([]string{login}, userInputs...)...)
+		if strength.Score < p.MinScore {
+			violations = append(violations, fmt.Sprintf(
+				"password is too easy to guess: strength %d of %d, at least %d is required",
+				strength.Score, ScoreStrong, p.MinScore))
+		}
+	}
+	return violations
+}
@@ -0,0 +1,72 @@
+package passpolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   Policy
+		login    string
+		password string
+		want     []string
+	}{
+		{
+			name:     "strong password",
+			policy:   DefaultPolicy(),
+			login:    "alice",
+			password: "correct horse battery staple",
+		},
+		{
+			name:     "empty password",
+			policy:   DefaultPolicy(),
+			login:    "alice",
+			password: "",
+			want:     []string{"password is required"},
+		},
+		{
+			name:     "common short password",
+			policy:   DefaultPolicy(),
+			login:    "alice",
+			password: "qwerty",
+			want: []string{
+				"password must be at least 10 characters long",
+				"password is too common",
+				"password is too easy to guess: strength 0 of 4, at least 3 is required",
+			},
+		},
+		{
+			name:     "contains login",
+			policy:   DefaultPolicy(),
+			login:    "Alice",
+			password: "kx7alicefpq2mzw",
+			want:     []string{"password must not contain the login"},
+		},
+		{
+			name:     "login allowed",
+			policy:   Policy{MinLength: 8},
+			login:    "alice",
+			password: "kx7alicefpq2mzw",
+		},
+		{
+			name:     "short login is not searched",
+			policy:   DefaultPolicy(),
+			login:    "kx",
+			password: "kx7fpq2mzwrt",
+		},
+		{
+			name:     "only length",
+			policy:   Policy{MinLength: 6},
+			login:    "alice",
+			password: "qwerty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.policy.Check(tt.login, tt.password))
+		})
+	}
+}
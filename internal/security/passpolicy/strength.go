@@ -0,0 +1,323 @@
+package passpolicy
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Оценки надежности пароля
+const (
+	ScoreVeryWeak = iota
+	ScoreWeak
+	ScoreFair
+	ScoreGood
+	ScoreStrong
+)
+
+const (
+	// bruteforceCardinality попыток на один символ, не входящий ни в один шаблон
+	bruteforceCardinality = 10
+	// maxPatternLength наибольшая длина шаблона, длиннее в списках слов и рядах клавиатуры не бывает
+	maxPatternLength = 32
+	// minPatternLength наименьшая длина шаблона
+	minPatternLength = 3
+)
+
+// scoreThresholds число попыток подбора, начиная с которого пароль получает следующую оценку
+// (10^3 - онлайн-подбор без ограничений, 10^10 - офлайн-подбор медленного хеша)
+var scoreThresholds = []float64{1e3 + 5, 1e6 + 5, 1e8 + 5, 1e10 + 5}
+
+// keyboardRows ряды клавиатуры в английской и русской раскладках
+var keyboardRows = []string{
+	"1234567890-=",
+	"qwertyuiop[]",
+	"asdfghjkl;'",
+	"zxcvbnm,./",
+	"йцукенгшщзхъ",
+	"фывапролджэ",
+	"ячсмитьбю",
+	"1qaz2wsx3edc4rfv5tgb6yhn7ujm8ik9ol0p",
+}
+
+// leetSubstitutions замены символов, которыми обычно "усиливают" слова
+var leetSubstitutions = map[rune]rune{
+	'0': 'o',
+	'1': 'i',
+	'3': 'e',
+	'4': 'a',
+	'5': 's',
+	'7': 't',
+	'@': 'a',
+	'$': 's',
+	'!': 'i',
+}
+
+// Strength оценка надежности пароля
+type Strength struct {
+	Score   int     // Оценка от ScoreVeryWeak до ScoreStrong
+	Guesses float64 // Оценка числа попыток для подбора
+}
+
+// Estimate оценка надежности пароля
+// userInputs - данные пользователя (логин, имя), которые подбирающий пароль знает заранее
+func Estimate(password string, userInputs ...string) Strength {
+	dictionary := make(map[string]int, len(userInputs))
+	for _, input := range userInputs {
+		input = strings.ToLower(strings.TrimSpace(input))
+		if len([]rune(input)) >= minPatternLength {
+			dictionary[input] = 1
+		}
+	}
+
+	guesses := math.Pow(10, minGuessesLog10([]rune(password), dictionary))
+	score := ScoreStrong
+	for i, threshold := range scoreThresholds {
+		if guesses < threshold {
+			score = i
+			break
+		}
+	}
+	return Strength{Score: score, Guesses: guesses}
+}
+
+// minGuessesLog10 наименьшее число попыток (log10) по всем разбиениям пароля на шаблоны и отдельные символы
+func minGuessesLog10(password []rune, dictionary map[string]int) float64 {
+	n := len(password)
+	if n == 0 {
+		return 0
+	}
+	lower := []rune(strings.ToLower(string(password)))
+
+	// best[i] - наименьшее число попыток (log10) для первых i символов
+	best := make([]float64, n+1)
+	for i := 1; i <= n; i++ {
+		best[i] = math.Inf(1)
+	}
+	for i := 0; i < n; i++ {
+		if brute := best[i] + math.Log10(bruteforceCardinality); brute < best[i+1] {
+			best[i+1] = brute
+		}
+		for j := i + minPatternLength; j <= n && j-i <= maxPatternLength; j++ {
+			g, ok := patternGuesses(password[i:j], lower[i:j], dictionary)
+			if !ok {
+				continue
+			}
+			// каждый следующий шаблон удваивает число вариантов разбиения
+			if total := best[i] + math.Log10(g) + patternPenalty(i); total < best[j] {
+				best[j] = total
+			}
+		}
+	}
+	return best[n]
+}
+
+// patternPenalty надбавка за шаблон, стоящий не в начале пароля
+func patternPenalty(start int) float64 {
+	if start == 0 {
+		return 0
+	}
+	return math.Log10(2)
+}
+
+// patternGuesses наименьшее число попыток для фрагмента, если он совпадает с одним из шаблонов
+func patternGuesses(token, lower []rune, dictionary map[string]int) (float64, bool) {
+	found := false
+	best := math.Inf(1)
+	try := func(g float64, ok bool) {
+		if ok && g < best {
+			best, found = g, true
+		}
+	}
+	try(dictionaryGuesses(token, lower, dictionary))
+	try(repeatGuesses(lower))
+	try(sequenceGuesses(lower))
+	try(keyboardGuesses(lower))
+	try(yearGuesses(lower))
+	return best, found
+}
+
+// dictionaryGuesses фрагмент - распространенный пароль или данные пользователя, в том числе задом наперед
+// и с заменой букв похожими символами; учитываются варианты написания заглавными буквами
+func dictionaryGuesses(token, lower []rune, dictionary map[string]int) (float64, bool) {
+	rank, ok := lookup(string(lower), dictionary)
+	multiplier := 1.0
+	if !ok {
+		rank, ok = lookup(reverse(lower), dictionary)
+		multiplier = 2
+	}
+	if !ok {
+		unleet := unleet(lower)
+		if unleet == string(lower) {
+			return 0, false
+		}
+		rank, ok = lookup(unleet, dictionary)
+		multiplier = 2
+	}
+	if !ok {
+		return 0, false
+	}
+	return float64(rank) * multiplier * uppercaseVariations(token), true
+}
+
+func lookup(word string, dictionary map[string]int) (int, bool) {
+	if rank, ok := dictionary[word]; ok {
+		return rank, true
+	}
+	rank, ok := commonPasswords[word]
+	return rank, ok
+}
+
+// uppercaseVariations число вариантов написания слова заглавными буквами
+// первая заглавная или все заглавные - 2 варианта, иначе число сочетаний заглавных и строчных
+func uppercaseVariations(token []rune) float64 {
+	upper, lower := 0, 0
+	for _, r := range token {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		}
+	}
+	if upper == 0 {
+		return 1
+	}
+	if lower == 0 || (upper == 1 && unicode.IsUpper(token[0])) {
+		return 2
+	}
+	variations := 0.0
+	for i := 1; i <= min(upper, lower); i++ {
+		variations += binomial(upper+lower, i)
+	}
+	return variations
+}
+
+func binomial(n, k int) float64 {
+	result := 1.0
+	for i := 1; i <= k; i++ {
+		result = result * float64(n-k+i) / float64(i)
+	}
+	return result
+}
+
+// repeatGuesses фрагмент - повтор одного символа или нескольких символов (aaaa, abcabc)
+func repeatGuesses(lower []rune) (float64, bool) {
+	n := len(lower)
+	for size := 1; size <= n/2; size++ {
+		if n%size != 0 || !isRepeat(lower, size) {
+			continue
+		}
+		block := lower[:size]
+		blockGuesses := charsetSize(block)
+		if size > 1 {
+			blockGuesses = math.Pow(10, minGuessesLog10(block, nil))
+		}
+		return blockGuesses * float64(n/size), true
+	}
+	return 0, false
+}
+
+func isRepeat(lower []rune, size int) bool {
+	for i := size; i < len(lower); i++ {
+		if lower[i] != lower[i-size] {
+			return false
+		}
+	}
+	return true
+}
+
+// sequenceGuesses фрагмент - последовательность символов с шагом 1 (abcd, 4321)
+func sequenceGuesses(lower []rune) (float64, bool) {
+	delta := lower[1] - lower[0]
+	if delta != 1 && delta != -1 {
+		return 0, false
+	}
+	for i := 2; i < len(lower); i++ {
+		if lower[i]-lower[i-1] != delta {
+			return 0, false
+		}
+	}
+
+	first := lower[0]
+	var base float64
+	switch {
+	case strings.ContainsRune("a1zя9а", first):
+		base = 4
+	case unicode.IsDigit(first):
+		base = 10
+	default:
+		base = 26
+	}
+	if delta < 0 {
+		base *= 2
+	}
+	return base * float64(len(lower)), true
+}
+
+// keyboardGuesses фрагмент - соседние клавиши одного ряда (qwerty, фыва), в том числе справа налево
+func keyboardGuesses(lower []rune) (float64, bool) {
+	token := string(lower)
+	reversed := reverse(lower)
+	for _, row := range keyboardRows {
+		if strings.Contains(row, token) {
+			return float64(len(keyboardRows) * len(lower)), true
+		}
+		if strings.Contains(row, reversed) {
+			return float64(2 * len(keyboardRows) * len(lower)), true
+		}
+	}
+	return 0, false
+}
+
+// yearGuesses фрагмент - год с 1900 по 2099
+func yearGuesses(lower []rune) (float64, bool) {
+	if len(lower) != 4 {
+		return 0, false
+	}
+	year := 0
+	for _, r := range lower {
+		if r < '0' || r > '9' {
+			return 0, false
+		}
+		year = year*10 + int(r-'0')
+	}
+	if year < 1900 || year > 2099 {
+		return 0, false
+	}
+	return 200, true
+}
+
+// charsetSize число символов того же вида, что и первый символ фрагмента
+func charsetSize(token []rune) float64 {
+	r := token[0]
+	switch {
+	case unicode.IsDigit(r):
+		return 10
+	case unicode.Is(unicode.Cyrillic, r):
+		return 33
+	case unicode.IsLetter(r):
+		return 26
+	default:
+		return 33
+	}
+}
+
+func reverse(runes []rune) string {
+	reversed := make([]rune, len(runes))
+	for i, r := range runes {
+		reversed[len(runes)-1-i] = r
+	}
+	return string(reversed)
+}
+
+func unleet(lower []rune) string {
+	result := make([]rune, len(lower))
+	for i, r := range lower {
+		if sub, ok := leetSubstitutions[r]; ok {
+			r = sub
+		}
+		result[i] = r
+	}
+	return string(result)
+}
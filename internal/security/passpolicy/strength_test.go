@@ -0,0 +1,53 @@
+package passpolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimate(t *testing.T) {
+	tests := []struct {
+		name       string
+		password   string
+		userInputs []string
+		wantScore  int
+	}{
+		{name: "empty", password: "", wantScore: ScoreVeryWeak},
+		{name: "common", password: "password", wantScore: ScoreVeryWeak},
+		{name: "common with capital letter", password: "Password", wantScore: ScoreVeryWeak},
+		{name: "common with substitutions", password: "p@ssw0rd", wantScore: ScoreVeryWeak},
+		{name: "common reversed", password: "drowssap", wantScore: ScoreVeryWeak},
+		{name: "digits sequence", password: "123456789", wantScore: ScoreVeryWeak},
+		{name: "letters sequence", password: "abcdefghij", wantScore: ScoreVeryWeak},
+		{name: "repeat", password: "aaaaaaaaaaaa", wantScore: ScoreVeryWeak},
+		{name: "repeated block", password: "abcabcabcabc", wantScore: ScoreVeryWeak},
+		{name: "keyboard row", password: "asdfghjkl;", wantScore: ScoreVeryWeak},
+		{name: "russian keyboard row", password: "фывапролджэ", wantScore: ScoreVeryWeak},
+		{name: "common with year", password: "monkey1990", wantScore: ScoreWeak},
+		{name: "user login", password: "alice_smith", userInputs: []string{"alice_smith"}, wantScore: ScoreVeryWeak},
+		{name: "short random", password: "k7#Qx", wantScore: ScoreWeak},
+		{name: "random", password: "kx7fpq2mzw", wantScore: ScoreGood},
+		{name: "passphrase", password: "correct horse battery staple", wantScore: ScoreStrong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantScore, Estimate(tt.password, tt.userInputs...).Score)
+		})
+	}
+}
+
+func TestEstimate_PatternsWeakerThanRandom(t *testing.T) {
+	random := Estimate("qz8vbt3wkp").Guesses
+	for _, password := range []string{"qwerty1234", "1234567890", "qqqqqqqqqq", "Dragon2024"} {
+		assert.Less(t, Estimate(password).Guesses, random, password)
+	}
+}
+
+func TestIsCommon(t *testing.T) {
+	assert.True(t, IsCommon("123456"))
+	assert.True(t, IsCommon("QWERTY"))
+	assert.True(t, IsCommon("p4ssw0rd"))
+	assert.True(t, IsCommon("йцукен"))
+	assert.False(t, IsCommon("kx7fpq2mzw"))
+}
@@ -0,0 +1,5 @@
+// Package scope права персональных токенов доступа
+// - <раздел>:<доступ> - доступ ко всем данным раздела: passwords, texts, cards, files
+// - item:<id>:<доступ> - доступ к одной записи (пароль, текст, карта), file:<id>:<доступ> - к одному файлу
+// - доступ read - чтение, write - создание, изменение и удаление; write не включает read
+package scope
@@ -0,0 +1,118 @@
+package scope
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Виды доступа
+const (
+	AccessRead  = "read"
+	AccessWrite = "write"
+)
+
+// Разделы данных пользователя
+const (
+	ResourcePasswords = "passwords"
+	ResourceTexts     = "texts"
+	ResourceCards     = "cards"
+	ResourceFiles     = "files"
+)
+
+// Виды отдельных объектов: записи (пароли, тексты, карты) и файлы нумеруются независимо
+const (
+	KindItem = "item"
+	KindFile = "file"
+)
+
+// MaxScopes сколько прав можно выдать одному токену
+const MaxScopes = 50
+
+var resources = map[string]bool{
+	ResourcePasswords: true,
+	ResourceTexts:     true,
+	ResourceCards:     true,
+	ResourceFiles:     true,
+}
+
+// Scope разобранное право токена
+// для права на раздел Resource - раздел, для права на объект - вид объекта (item, file) и его ID
+type Scope struct {
+	Resource string
+	ID       int64
+	Access   string
+}
+
+// Parse разбор права вида passwords:read или item:42:read
+func Parse(value string) (Scope, error) {
+	parts := strings.Split(value, ":")
+	var s Scope
+	switch len(parts) {
+	case 2:
+		if !resources[parts[0]] {
+			return s, fmt.Errorf("unknown scope resource %q", parts[0])
+		}
+		s = Scope{Resource: parts[0], Access: parts[1]}
+	case 3:
+		if parts[0] != KindItem && parts[0] != KindFile {
+			return s, fmt.Errorf("unknown scope kind %q", parts[0])
+		}
+		id, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil || id <= 0 {
+			return s, fmt.Errorf("invalid scope id %q", parts[1])
+		}
+		s = Scope{Resource: parts[0], ID: id, Access: parts[2]}
+	default:
+		return s, fmt.Errorf("invalid scope %q", value)
+	}
+	if s.Access != AccessRead && s.Access != AccessWrite {
+		return s, fmt.Errorf("unknown scope access %q", s.Access)
+	}
+	return s, nil
+}
+
+// Validate проверка списка прав при выдаче токена
+func Validate(scopes []string) error {
+	if len(scopes) == 0 {
+		return fmt.Errorf("at least one scope is required")
+	}
+	if len(scopes) > MaxScopes {
+		return fmt.Errorf("too many scopes, maximum is %d", MaxScopes)
+	}
+	for _, value := range scopes {
+		if _, err := Parse(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Allows есть ли среди прав доступ ко всему разделу
+func Allows(scopes []string, resource, access string) bool {
+	return contains(scopes, Scope{Resource: resource, Access: access})
+}
+
+// AllowsObject есть ли среди прав доступ к одному объекту
+func AllowsObject(scopes []string, kind string, id int64, access string) bool {
+	return contains(scopes, Scope{Resource: kind, ID: id, Access: access})
+}
+
+// HasObjects есть ли среди прав права на отдельные объекты этого вида
+func HasObjects(scopes []string, kind string) bool {
+	for _, value := range scopes {
+		if s, err := Parse(value); err == nil && s.Resource == kind && s.ID != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(scopes []string, want Scope) bool {
+	for _, value := range scopes {
+		if s, err := Parse(value); err == nil && s == want {
+			return true
+		}
+	}
+	return false
+}
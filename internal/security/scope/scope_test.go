@@ -0,0 +1,63 @@
+package scope
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Scope
+		wantErr bool
+	}{
+		{value: "passwords:read", want: Scope{Resource: ResourcePasswords, Access: AccessRead}},
+		{value: "files:write", want: Scope{Resource: ResourceFiles, Access: AccessWrite}},
+		{value: "item:42:read", want: Scope{Resource: KindItem, ID: 42, Access: AccessRead}},
+		{value: "file:7:write", want: Scope{Resource: KindFile, ID: 7, Access: AccessWrite}},
+		{value: "passwords:delete", wantErr: true},
+		{value: "secrets:read", wantErr: true},
+		{value: "item:abc:read", wantErr: true},
+		{value: "item:0:read", wantErr: true},
+		{value: "card:1:read", wantErr: true},
+		{value: "passwords", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := Parse(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate([]string{"passwords:read", "item:3:read"}))
+	assert.Error(t, Validate(nil))
+	assert.Error(t, Validate([]string{"passwords:read", "all"}))
+	assert.Error(t, Validate(make([]string, MaxScopes+1)))
+}
+
+func TestAllows(t *testing.T) {
+	scopes := []string{"passwords:read", "item:42:write", "file:7:read"}
+
+	assert.True(t, Allows(scopes, ResourcePasswords, AccessRead))
+	// write не включает read и наоборот
+	assert.False(t, Allows(scopes, ResourcePasswords, AccessWrite))
+	assert.False(t, Allows(scopes, ResourceFiles, AccessRead))
+
+	assert.True(t, AllowsObject(scopes, KindItem, 42, AccessWrite))
+	assert.False(t, AllowsObject(scopes, KindItem, 42, AccessRead))
+	// записи и файлы нумеруются независимо
+	assert.False(t, AllowsObject(scopes, KindFile, 42, AccessWrite))
+	assert.True(t, AllowsObject(scopes, KindFile, 7, AccessRead))
+
+	assert.True(t, HasObjects(scopes, KindFile))
+	assert.False(t, HasObjects([]string{"passwords:read"}, KindItem))
+}
@@ -0,0 +1,4 @@
+// Package totp одноразовые пароли по времени (RFC 6238) для второго фактора авторизации
+// - код из 6 цифр меняется каждые 30 секунд, HMAC-SHA1 как в приложениях-аутентификаторах
+// - коды восстановления заменяют код, если приложение-аутентификатор недоступно
+package totp
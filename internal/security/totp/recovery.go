@@ -0,0 +1,43 @@
+package totp
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+)
+
+const (
+	// RecoveryCodesCount сколько кодов восстановления выдается при подключении
+	RecoveryCodesCount = 10
+	// recoveryCodeBytes случайных байт в одном коде (80 бит)
+	recoveryCodeBytes = 10
+)
+
+// recoveryEncoding base32 в нижнем регистре без выравнивания
+var recoveryEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// GenerateRecoveryCodes одноразовые коды восстановления вида xxxx-xxxx-xxxx-xxxx
+func GenerateRecoveryCodes(count int) ([]string, error) {
+	codes := make([]string, 0, count)
+	for range count {
+		raw := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		encoded := recoveryEncoding.EncodeToString(raw)
+		codes = append(codes, encoded[0:4]+"-"+encoded[4:8]+"-"+encoded[8:12]+"-"+encoded[12:16])
+	}
+	return codes, nil
+}
+
+// NormalizeRecoveryCode код восстановления без разделителей и в нижнем регистре
+// в таком виде код хешируется при сохранении и проверке
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// IsRecoveryCode похоже ли введенное значение на код восстановления, а не на код из приложения
+func IsRecoveryCode(code string) bool {
+	return len(NormalizeRecoveryCode(code)) == 16
+}
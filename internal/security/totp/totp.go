@@ -0,0 +1,110 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Period сколько секунд действует один код
+	Period = 30
+	// Digits число цифр в коде
+	Digits = 6
+	// SecretLength длина секрета в байтах (160 бит, как рекомендует RFC 4226)
+	SecretLength = 20
+	// Skew сколько соседних периодов принимается из-за расхождения часов
+	Skew = 1
+)
+
+// encoding base32 без выравнивания, в таком виде секрет вводится в приложение-аутентификатор
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret новый случайный секрет
+func GenerateSecret() ([]byte, error) {
+	secret := make([]byte, SecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// EncodeSecret секрет в base32 для ручного ввода в приложение
+func EncodeSecret(secret []byte) string {
+	return encoding.EncodeToString(secret)
+}
+
+// URI ссылка otpauth:// для QR-кода приложения-аутентификатора
+func URI(issuer, account string, secret []byte) string {
+	query := url.Values{}
+	query.Set("secret", EncodeSecret(secret))
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(Digits))
+	query.Set("period", fmt.Sprint(Period))
+
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
+
+// Step номер периода для момента времени
+func Step(t time.Time) int64 {
+	return t.Unix() / Period
+}
+
+// Code код для номера периода
+func Code(secret []byte, step int64) string {
+	return code(secret, uint64(step), Digits)
+}
+
+// Validate проверка кода на момент времени с допуском Skew периодов
+// периоды не позже lastStep не принимаются, чтобы один код нельзя было использовать дважды
+// возвращается номер периода, которому соответствует код
+func Validate(secret []byte, passcode string, t time.Time, lastStep int64) (int64, bool) {
+	passcode = strings.TrimSpace(passcode)
+	if len(passcode) != Digits {
+		return 0, false
+	}
+
+	current := Step(t)
+	for step := current - Skew; step <= current+Skew; step++ {
+		if step <= lastStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(Code(secret, step)), []byte(passcode)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// code HOTP (RFC 4226): динамическое усечение HMAC-SHA1 от счетчика
+func code(secret []byte, counter uint64, digits int) string {
+	var message [8]byte
+	binary.BigEndian.PutUint64(message[:], counter)
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(message[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for range digits {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, value%modulo)
+}
@@ -0,0 +1,133 @@
+package totp
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret секрет из тестовых векторов RFC 6238 для SHA1
+var rfcSecret = []byte("12345678901234567890")
+
+func Test_code_RFC6238(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "94287082"},
+		{unix: 1111111109, want: "07081804"},
+		{unix: 1111111111, want: "14050471"},
+		{unix: 1234567890, want: "89005924"},
+		{unix: 2000000000, want: "69279037"},
+		{unix: 20000000000, want: "65353130"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			step := Step(time.Unix(tt.unix, 0))
+			assert.Equal(t, tt.want, code(rfcSecret, uint64(step), 8))
+			assert.Equal(t, tt.want[2:], Code(rfcSecret, step))
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := Step(now)
+
+	tests := []struct {
+		name     string
+		passcode string
+		lastStep int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{
+			name:     "current code",
+			passcode: Code(rfcSecret, current),
+			wantStep: current,
+			wantOK:   true,
+		},
+		{
+			name:     "previous code within skew",
+			passcode: Code(rfcSecret, current-1),
+			wantStep: current - 1,
+			wantOK:   true,
+		},
+		{
+			name:     "next code within skew",
+			passcode: " " + Code(rfcSecret, current+1) + "\n",
+			wantStep: current + 1,
+			wantOK:   true,
+		},
+		{
+			name:     "outdated code",
+			passcode: Code(rfcSecret, current-2),
+		},
+		{
+			name:     "replayed code",
+			passcode: Code(rfcSecret, current),
+			lastStep: current,
+		},
+		{
+			name:     "wrong length",
+			passcode: "12345",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.passcode, now, tt.lastStep)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantStep, step)
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	first, err := GenerateSecret()
+	require.NoError(t, err)
+	second, err := GenerateSecret()
+	require.NoError(t, err)
+
+	assert.Len(t, first, SecretLength)
+	assert.NotEqual(t, first, second)
+	assert.Len(t, EncodeSecret(first), 32)
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("GophKeeper", "alice", rfcSecret)
+
+	u, err := url.Parse(uri)
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/GophKeeper:alice", u.Path)
+	assert.Equal(t, EncodeSecret(rfcSecret), u.Query().Get("secret"))
+	assert.Equal(t, "GophKeeper", u.Query().Get("issuer"))
+	assert.Equal(t, "6", u.Query().Get("digits"))
+	assert.Equal(t, "30", u.Query().Get("period"))
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(RecoveryCodesCount)
+	require.NoError(t, err)
+	assert.Len(t, codes, RecoveryCodesCount)
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		assert.Len(t, code, 19)
+		assert.Equal(t, 3, strings.Count(code, "-"))
+		assert.True(t, IsRecoveryCode(code))
+		assert.False(t, seen[code])
+		seen[code] = true
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	assert.Equal(t, "abcdefghijklmnop", NormalizeRecoveryCode(" ABCD-efgh ijkl-MNOP\n"))
+	assert.True(t, IsRecoveryCode("abcd efgh ijkl mnop"))
+	assert.False(t, IsRecoveryCode("123456"))
+}
@@ -1,15 +1,15 @@
 package db
 
 import (
-	"context"
-	"reflect"
 	"testing"
 
 	"github.com/golang/mock/gomock"
-	"github.com/jackc/pgconn"
+	"github.com/pashagolub/pgxmock"
 	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
 	repositoryMock "github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository/mocks"
+	migrationsPkg "github.com/ramil063/secondgodiplom/internal/storage/db/migrations"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCheckPing(t *testing.T) {
@@ -38,128 +38,53 @@ func TestCheckPing(t *testing.T) {
 	}
 }
 
-func TestCreateTables(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
+func TestInit(t *testing.T) {
+	migrations, err := migrationsPkg.Load()
+	require.NoError(t, err)
+	latest := migrations[len(migrations)-1].Version
 
 	tests := []struct {
-		name string
+		name    string
+		version int
+		wantErr error
 	}{
 		{
-			name: "CreateTables",
+			name:    "schema is up to date",
+			version: latest,
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			poolMock := repositoryMock.NewMockPooler(ctrl)
-			rep := &repository.Repository{Pool: poolMock}
-
-			expectedCommandTag := pgconn.CommandTag("INSERT 0 1")
-			poolMock.EXPECT().
-				Exec(
-					context.Background(),
-					gomock.Any()).
-				Return(expectedCommandTag, nil)
-
-			err := CreateTables(*rep)
-			assert.NoError(t, err)
-		})
-	}
-}
-
-func TestInit(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	tests := []struct {
-		name string
-	}{
 		{
-			name: "Init",
+			name:    "schema is newer than server",
+			version: latest + 1,
+			wantErr: migrationsPkg.ErrSchemaTooNew,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			poolMock := repositoryMock.NewMockPooler(ctrl)
+			poolMock, err := pgxmock.NewPool()
+			require.NoError(t, err)
 			rep := &repository.Repository{Pool: poolMock}
 
-			poolMock.EXPECT().
-				Ping(gomock.Any()).
-				Return(nil)
-
-			expectedCommandTag := pgconn.CommandTag("INSERT 0 1")
-			poolMock.EXPECT().
-				Exec(
-					context.Background(),
-					gomock.Any()).
-				Return(expectedCommandTag, nil)
-
-			err := Init(*rep)
-			assert.NoError(t, err)
-		})
-	}
-}
-
-func TestStorage_GetRepository(t *testing.T) {
-	type fields struct {
-		Repository *repository.Repository
-	}
-	tests := []struct {
-		name   string
-		fields fields
-		want   repository.Repository
-	}{
-		{
-			name: "GetRepository",
-			fields: fields{
-				Repository: &repository.Repository{},
-			},
-			want: repository.Repository{},
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			s := &Storage{
-				Repository: tt.fields.Repository,
+			poolMock.ExpectBegin()
+			poolMock.ExpectExec("SELECT pg_advisory_xact_lock").
+				WithArgs(pgxmock.AnyArg()).
+				WillReturnResult(pgxmock.NewResult("SELECT", 1))
+			poolMock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").
+				WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+			poolMock.ExpectQuery("SELECT COALESCE\\(MAX\\(version\\), 0\\) FROM schema_migrations").
+				WillReturnRows(poolMock.NewRows([]string{"version"}).AddRow(tt.version))
+			if tt.wantErr == nil {
+				poolMock.ExpectCommit()
+			} else {
+				poolMock.ExpectRollback()
 			}
-			if got := s.GetRepository(); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("GetRepository() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
 
-func TestStorage_SetRepository(t *testing.T) {
-	type fields struct {
-		Repository *repository.Repository
-	}
-	type args struct {
-		repository *repository.Repository
-	}
-	tests := []struct {
-		name   string
-		fields fields
-		args   args
-	}{
-		{
-			name: "SetRepository",
-			fields: fields{
-				Repository: &repository.Repository{},
-			},
-			args: args{
-				repository: &repository.Repository{},
-			},
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			s := &Storage{
-				Repository: tt.fields.Repository,
-			}
-			s.SetRepository(tt.args.repository)
-			if got := s.GetRepository(); !reflect.DeepEqual(got, *tt.fields.Repository) {
-				t.Errorf("GetRepository() = %v, want %v", got, *tt.fields.Repository)
+			err = Init(*rep)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
 			}
+			assert.NoError(t, poolMock.ExpectationsWereMet())
 		})
 	}
 }
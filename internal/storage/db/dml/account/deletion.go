@@ -0,0 +1,221 @@
+package account
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/account"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/user"
+	"github.com/ramil063/secondgodiplom/internal/logger"
+)
+
+// purgeQueries удаление данных пользователя, параметр $1 - идентификатор пользователя
+// последним удаляется сам пользователь, вместе с ним каскадно удаляются ключ данных, второй фактор и события безопасности
+var purgeQueries = []string{
+	"DELETE FROM binary_file_chunk WHERE file_id IN (SELECT id FROM binary_file WHERE user_id = $1)",
+	"DELETE FROM binary_file_metadata WHERE file_id IN (SELECT id FROM binary_file WHERE user_id = $1)",
+	"DELETE FROM binary_file WHERE user_id = $1",
+	"DELETE FROM item_metadata WHERE item_id IN (SELECT id FROM encrypted_item WHERE user_id = $1)",
+	"DELETE FROM encrypted_item WHERE user_id = $1",
+	"DELETE FROM oauth_refresh_token WHERE access_token_id IN (SELECT id FROM oauth_access_token WHERE user_id = $1)",
+	"DELETE FROM oauth_access_token WHERE user_id = $1",
+	"DELETE FROM users WHERE id = $1",
+}
+
+// GetAccountByLogin получение учетных данных пользователя по логину, в том числе деактивированного
+func (s *Account) GetAccountByLogin(ctx context.Context, login string) (*user.User, error) {
+	row := s.Repository.Pool.QueryRow(
+		ctx,
+		"SELECT id, login, password_hash, is_active, deletion_scheduled_at FROM users WHERE login = $1",
+		login)
+
+	var passwordHash []byte
+	u := &user.User{}
+	err := row.Scan(&u.ID, &u.Login, &passwordHash, &u.IsActive, &u.DeletionScheduledAt)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	u.PasswordHash = string(passwordHash)
+	return u, nil
+}
+
+// DeleteAccount немедленное удаление пользователя со всеми его данными и токенами
+func (s *Account) DeleteAccount(ctx context.Context, userID int) error {
+	return s.purge(ctx, userID, "SELECT id FROM users WHERE id = $1 FOR UPDATE")
+}
+
+// DeactivateAccount отложенное удаление: учетная запись деактивируется до даты удаления,
+// токены всех сессий отзываются, записывается событие безопасности
+func (s *Account) DeactivateAccount(ctx context.Context, deactivation *account.Deactivation) error {
+	tx, err := s.Repository.Pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return errors.New("DeactivateAccount error in begin transaction")
+	}
+	defer func() {
+		// После Commit откат ничего не делает
+		_ = tx.Rollback(ctx)
+	}()
+
+	exec, err := tx.Exec(
+		ctx,
+		`UPDATE users SET is_active = FALSE, deletion_scheduled_at = $2, updated_at = NOW()
+			WHERE id = $1 AND is_active = TRUE`,
+		deactivation.UserID,
+		deactivation.PurgeAt)
+	if err != nil {
+		return errors.New("DeactivateAccount error in update user")
+	}
+	if exec.RowsAffected() != 1 {
+		logger.WriteErrorLog("DeactivateAccount error expected to affect 1 row")
+		return status.Error(codes.FailedPrecondition, "account is already deactivated")
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		`WITH revoked AS (
+				UPDATE oauth_access_token SET is_revoked = TRUE
+				WHERE user_id = $1 AND is_revoked = FALSE
+				RETURNING id
+			)
+			UPDATE oauth_refresh_token SET is_revoked = TRUE
+			WHERE access_token_id IN (SELECT id FROM revoked)`,
+		deactivation.UserID)
+	if err != nil {
+		return errors.New("DeactivateAccount error in revoke tokens")
+	}
+
+	if err = saveSecurityEvent(ctx, tx, deactivation.Event); err != nil {
+		return errors.New("DeactivateAccount error in save security event")
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return errors.New("DeactivateAccount error in commit")
+	}
+	return nil
+}
+
+// RestoreAccount отмена отложенного удаления, пока не наступила дата удаления
+func (s *Account) RestoreAccount(ctx context.Context, userID int, event account.SecurityEvent) error {
+	tx, err := s.Repository.Pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return errors.New("RestoreAccount error in begin transaction")
+	}
+	defer func() {
+		// После Commit откат ничего не делает
+		_ = tx.Rollback(ctx)
+	}()
+
+	exec, err := tx.Exec(
+		ctx,
+		`UPDATE users SET is_active = TRUE, deletion_scheduled_at = NULL, updated_at = NOW()
+			WHERE id = $1 AND is_active = FALSE AND deletion_scheduled_at > NOW()`,
+		userID)
+	if err != nil {
+		return errors.New("RestoreAccount error in update user")
+	}
+	if exec.RowsAffected() != 1 {
+		logger.WriteErrorLog("RestoreAccount error expected to affect 1 row")
+		return status.Error(codes.FailedPrecondition, "account is not scheduled for deletion")
+	}
+
+	if err = saveSecurityEvent(ctx, tx, event); err != nil {
+		return errors.New("RestoreAccount error in save security event")
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return errors.New("RestoreAccount error in commit")
+	}
+	return nil
+}
+
+// PurgeDeletedAccounts удаление данных учетных записей, у которых истек срок восстановления
+// возвращает число удаленных учетных записей
+func (s *Account) PurgeDeletedAccounts(ctx context.Context) (int, error) {
+	rows, err := s.Repository.Pool.Query(
+		ctx,
+		"SELECT id FROM users WHERE is_active = FALSE AND deletion_scheduled_at <= NOW()")
+	if err != nil {
+		return 0, errors.New("PurgeDeletedAccounts error in select users")
+	}
+
+	var userIDs []int
+	for rows.Next() {
+		var userID int
+		if err = rows.Scan(&userID); err != nil {
+			rows.Close()
+			return 0, errors.New("PurgeDeletedAccounts error in scan user")
+		}
+		userIDs = append(userIDs, userID)
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return 0, errors.New("PurgeDeletedAccounts error in rows")
+	}
+
+	purged := 0
+	for _, userID := range userIDs {
+		// Пользователь мог восстановить учетную запись после выборки
+		err = s.purge(
+			ctx,
+			userID,
+			"SELECT id FROM users WHERE id = $1 AND is_active = FALSE AND deletion_scheduled_at <= NOW() FOR UPDATE")
+		if status.Code(err) == codes.NotFound {
+			continue
+		}
+		if err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// purge удаление данных пользователя в одной транзакции
+// lockQuery блокирует строку пользователя и проверяет, что его можно удалить
+func (s *Account) purge(ctx context.Context, userID int, lockQuery string) error {
+	tx, err := s.Repository.Pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return errors.New("purge account error in begin transaction")
+	}
+	defer func() {
+		// После Commit откат ничего не делает
+		_ = tx.Rollback(ctx)
+	}()
+
+	var id int
+	err = tx.QueryRow(ctx, lockQuery, userID).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return status.Error(codes.NotFound, "account not found")
+	}
+	if err != nil {
+		return errors.New("purge account error in lock user")
+	}
+
+	for _, query := range purgeQueries {
+		if _, err = tx.Exec(ctx, query, userID); err != nil {
+			logger.WriteErrorLog(err.Error())
+			return errors.New("purge account error in delete data")
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return errors.New("purge account error in commit")
+	}
+	return nil
+}
+
+// saveSecurityEvent запись события безопасности учетной записи
+func saveSecurityEvent(ctx context.Context, tx pgx.Tx, event account.SecurityEvent) error {
+	_, err := tx.Exec(
+		ctx,
+		"INSERT INTO security_event (user_id, event_type, client_ip, user_agent) VALUES ($1, $2, $3, $4)",
+		event.UserID,
+		event.Type,
+		event.ClientIP,
+		event.UserAgent)
+	return err
+}
@@ -0,0 +1,261 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pashagolub/pgxmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/account"
+	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
+)
+
+// expectPurge ожидание удаления всех данных пользователя
+func expectPurge(poolMock pgxmock.PgxPoolIface, userID int) {
+	for _, query := range purgeQueries {
+		poolMock.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(userID).
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	}
+}
+
+func TestAccount_DeleteAccount(t *testing.T) {
+	tests := []struct {
+		name     string
+		prepare  func(poolMock pgxmock.PgxPoolIface)
+		wantCode codes.Code
+	}{
+		{
+			name: "success",
+			prepare: func(poolMock pgxmock.PgxPoolIface) {
+				poolMock.ExpectBegin()
+				poolMock.ExpectQuery("SELECT id FROM users WHERE id = \\$1 FOR UPDATE").
+					WithArgs(1).
+					WillReturnRows(poolMock.NewRows([]string{"id"}).AddRow(1))
+				expectPurge(poolMock, 1)
+				poolMock.ExpectCommit()
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "not found",
+			prepare: func(poolMock pgxmock.PgxPoolIface) {
+				poolMock.ExpectBegin()
+				poolMock.ExpectQuery("SELECT id FROM users").
+					WithArgs(1).
+					WillReturnError(pgx.ErrNoRows)
+				poolMock.ExpectRollback()
+			},
+			wantCode: codes.NotFound,
+		},
+		{
+			// файлы удалены, ошибка на записях: транзакция откатывается целиком
+			name: "delete error",
+			prepare: func(poolMock pgxmock.PgxPoolIface) {
+				poolMock.ExpectBegin()
+				poolMock.ExpectQuery("SELECT id FROM users").
+					WithArgs(1).
+					WillReturnRows(poolMock.NewRows([]string{"id"}).AddRow(1))
+				for _, query := range purgeQueries[:3] {
+					poolMock.ExpectExec(regexp.QuoteMeta(query)).
+						WithArgs(1).
+						WillReturnResult(pgxmock.NewResult("DELETE", 1))
+				}
+				poolMock.ExpectExec("DELETE FROM item_metadata").
+					WithArgs(1).
+					WillReturnError(errors.New("connection refused"))
+				poolMock.ExpectRollback()
+			},
+			wantCode: codes.Unknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poolMock, err := pgxmock.NewPool()
+			require.NoError(t, err)
+			s := &Account{
+				Repository: &repository.Repository{Pool: poolMock},
+			}
+			tt.prepare(poolMock)
+
+			err = s.DeleteAccount(context.Background(), 1)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.NoError(t, poolMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAccount_DeactivateAccount(t *testing.T) {
+	purgeAt := time.Date(2026, 11, 17, 0, 0, 0, 0, time.UTC)
+	deactivation := &account.Deactivation{
+		UserID:  1,
+		PurgeAt: purgeAt,
+		Event: account.SecurityEvent{
+			UserID:    1,
+			Type:      account.EventAccountDeactivated,
+			ClientIP:  "10.0.0.7",
+			UserAgent: "laptop",
+		},
+	}
+
+	tests := []struct {
+		name     string
+		prepare  func(poolMock pgxmock.PgxPoolIface)
+		wantCode codes.Code
+	}{
+		{
+			name: "success",
+			prepare: func(poolMock pgxmock.PgxPoolIface) {
+				poolMock.ExpectBegin()
+				poolMock.ExpectExec("UPDATE users SET is_active = FALSE").
+					WithArgs(1, purgeAt).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				poolMock.ExpectExec("WITH revoked AS").
+					WithArgs(1).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+				poolMock.ExpectExec("INSERT INTO security_event").
+					WithArgs(1, account.EventAccountDeactivated, "10.0.0.7", "laptop").
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				poolMock.ExpectCommit()
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "already deactivated",
+			prepare: func(poolMock pgxmock.PgxPoolIface) {
+				poolMock.ExpectBegin()
+				poolMock.ExpectExec("UPDATE users SET is_active = FALSE").
+					WithArgs(1, purgeAt).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+				poolMock.ExpectRollback()
+			},
+			wantCode: codes.FailedPrecondition,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poolMock, err := pgxmock.NewPool()
+			require.NoError(t, err)
+			s := &Account{
+				Repository: &repository.Repository{Pool: poolMock},
+			}
+			tt.prepare(poolMock)
+
+			err = s.DeactivateAccount(context.Background(), deactivation)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.NoError(t, poolMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAccount_RestoreAccount(t *testing.T) {
+	event := account.SecurityEvent{UserID: 1, Type: account.EventAccountRestored}
+
+	tests := []struct {
+		name     string
+		prepare  func(poolMock pgxmock.PgxPoolIface)
+		wantCode codes.Code
+	}{
+		{
+			name: "success",
+			prepare: func(poolMock pgxmock.PgxPoolIface) {
+				poolMock.ExpectBegin()
+				poolMock.ExpectExec("UPDATE users SET is_active = TRUE.*deletion_scheduled_at > NOW\\(\\)").
+					WithArgs(1).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				poolMock.ExpectExec("INSERT INTO security_event").
+					WithArgs(1, account.EventAccountRestored, "", "").
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				poolMock.ExpectCommit()
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "grace period expired",
+			prepare: func(poolMock pgxmock.PgxPoolIface) {
+				poolMock.ExpectBegin()
+				poolMock.ExpectExec("UPDATE users SET is_active = TRUE").
+					WithArgs(1).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+				poolMock.ExpectRollback()
+			},
+			wantCode: codes.FailedPrecondition,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poolMock, err := pgxmock.NewPool()
+			require.NoError(t, err)
+			s := &Account{
+				Repository: &repository.Repository{Pool: poolMock},
+			}
+			tt.prepare(poolMock)
+
+			err = s.RestoreAccount(context.Background(), 1, event)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.NoError(t, poolMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAccount_PurgeDeletedAccounts(t *testing.T) {
+	poolMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	s := &Account{
+		Repository: &repository.Repository{Pool: poolMock},
+	}
+
+	poolMock.ExpectQuery("SELECT id FROM users WHERE is_active = FALSE AND deletion_scheduled_at <= NOW\\(\\)").
+		WillReturnRows(poolMock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	// первого пользователя удаляем
+	poolMock.ExpectBegin()
+	poolMock.ExpectQuery("SELECT id FROM users WHERE id = \\$1 AND is_active = FALSE.*FOR UPDATE").
+		WithArgs(1).
+		WillReturnRows(poolMock.NewRows([]string{"id"}).AddRow(1))
+	expectPurge(poolMock, 1)
+	poolMock.ExpectCommit()
+	// второй успел восстановить учетную запись
+	poolMock.ExpectBegin()
+	poolMock.ExpectQuery("SELECT id FROM users WHERE id = \\$1 AND is_active = FALSE.*FOR UPDATE").
+		WithArgs(2).
+		WillReturnError(pgx.ErrNoRows)
+	poolMock.ExpectRollback()
+
+	got, err := s.PurgeDeletedAccounts(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, got)
+	assert.NoError(t, poolMock.ExpectationsWereMet())
+}
+
+func TestAccount_GetAccountByLogin(t *testing.T) {
+	poolMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	s := &Account{
+		Repository: &repository.Repository{Pool: poolMock},
+	}
+
+	purgeAt := time.Date(2026, 11, 17, 0, 0, 0, 0, time.UTC)
+	poolMock.ExpectQuery("SELECT id, login, password_hash, is_active, deletion_scheduled_at FROM users WHERE login = \\$1").
+		WithArgs("alice").
+		WillReturnRows(poolMock.NewRows([]string{"id", "login", "password_hash", "is_active", "deletion_scheduled_at"}).
+			AddRow(1, "alice", []byte("hash"), false, &purgeAt))
+	got, err := s.GetAccountByLogin(context.Background(), "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "hash", got.PasswordHash)
+	assert.False(t, got.IsActive)
+	assert.Equal(t, &purgeAt, got.DeletionScheduledAt)
+
+	poolMock.ExpectQuery("SELECT id, login, password_hash").
+		WithArgs("bob").
+		WillReturnError(errors.New("no rows in result set"))
+	_, err = s.GetAccountByLogin(context.Background(), "bob")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
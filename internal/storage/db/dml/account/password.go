@@ -0,0 +1,105 @@
+package account
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/account"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/user"
+	"github.com/ramil063/secondgodiplom/internal/hash"
+	"github.com/ramil063/secondgodiplom/internal/logger"
+)
+
+// GetAccount получение учетных данных пользователя
+func (s *Account) GetAccount(ctx context.Context, userID int) (*user.User, error) {
+	row := s.Repository.Pool.QueryRow(
+		ctx,
+		"SELECT id, login, password_hash, kdf_salt, wrapped_vault_key FROM users WHERE id = $1",
+		userID)
+
+	var passwordHash []byte
+	u := &user.User{}
+	err := row.Scan(&u.ID, &u.Login, &passwordHash, &u.KdfSalt, &u.WrappedVaultKey)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	u.PasswordHash = string(passwordHash)
+	return u, nil
+}
+
+// ChangePassword смена пароля в одной транзакции:
+// новый хеш пароля и обернутый ключ хранилища, отзыв токенов остальных сессий и событие безопасности
+// возвращает число отозванных сессий
+func (s *Account) ChangePassword(ctx context.Context, change *account.PasswordChange) (int64, error) {
+	tx, err := s.Repository.Pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, errors.New("ChangePassword error in begin transaction")
+	}
+	defer func() {
+		// После Commit откат ничего не делает
+		_ = tx.Rollback(ctx)
+	}()
+
+	err = updatePassword(ctx, tx, change)
+	if err != nil {
+		return 0, err
+	}
+
+	row := tx.QueryRow(
+		ctx,
+		`WITH revoked AS (
+				UPDATE oauth_access_token SET is_revoked = TRUE
+				WHERE user_id = $1 AND is_revoked = FALSE AND token_hash <> $2
+				RETURNING id
+			), revoked_refresh AS (
+				UPDATE oauth_refresh_token SET is_revoked = TRUE
+				WHERE access_token_id IN (SELECT id FROM revoked)
+			)
+			SELECT COUNT(*) FROM revoked`,
+		change.UserID,
+		hash.GetTokenHash(change.CurrentAccessToken))
+
+	var revoked int64
+	if err = row.Scan(&revoked); err != nil {
+		return 0, errors.New("ChangePassword error in revoke tokens")
+	}
+
+	if err = saveSecurityEvent(ctx, tx, change.Event); err != nil {
+		return 0, errors.New("ChangePassword error in save security event")
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, errors.New("ChangePassword error in commit")
+	}
+	return revoked, nil
+}
+
+// updatePassword запись нового хеша пароля
+// если включено сквозное шифрование, вместе с ним меняется обернутый ключ хранилища
+// пароль не меняется, если его успели сменить или изменилось состояние сквозного шифрования
+func updatePassword(ctx context.Context, tx pgx.Tx, change *account.PasswordChange) error {
+	var query string
+	args := []interface{}{change.UserID, change.OldPasswordHash, change.NewPasswordHash}
+	if len(change.WrappedVaultKey) > 0 {
+		query = `UPDATE users SET password_hash = $3, kdf_salt = $4, wrapped_vault_key = $5, updated_at = NOW()
+			WHERE id = $1 AND password_hash = $2 AND wrapped_vault_key IS NOT NULL`
+		args = append(args, change.KdfSalt, change.WrappedVaultKey)
+	} else {
+		query = `UPDATE users SET password_hash = $3, updated_at = NOW()
+			WHERE id = $1 AND password_hash = $2 AND wrapped_vault_key IS NULL`
+	}
+
+	exec, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return errors.New("ChangePassword error in update password")
+	}
+	if exec.RowsAffected() != 1 {
+		logger.WriteErrorLog("ChangePassword error expected to affect 1 row")
+		return status.Error(codes.Aborted, "account changed concurrently")
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pashagolub/pgxmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/account"
+	"github.com/ramil063/secondgodiplom/internal/hash"
+	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
+)
+
+func TestAccount_ChangePassword(t *testing.T) {
+	change := &account.PasswordChange{
+		UserID:             1,
+		OldPasswordHash:    "old hash",
+		NewPasswordHash:    "new hash",
+		CurrentAccessToken: "token",
+		Event: account.SecurityEvent{
+			UserID:    1,
+			Type:      account.EventPasswordChanged,
+			ClientIP:  "10.0.0.7",
+			UserAgent: "laptop",
+		},
+	}
+	rewrapped := *change
+	rewrapped.KdfSalt = []byte("new salt")
+	rewrapped.WrappedVaultKey = []byte("rewrapped")
+
+	tests := []struct {
+		name     string
+		change   *account.PasswordChange
+		prepare  func(poolMock pgxmock.PgxPoolIface)
+		want     int64
+		wantCode codes.Code
+	}{
+		{
+			name:   "success",
+			change: change,
+			prepare: func(poolMock pgxmock.PgxPoolIface) {
+				poolMock.ExpectBegin()
+				poolMock.ExpectExec("UPDATE users SET password_hash.*wrapped_vault_key IS NULL").
+					WithArgs(1, "old hash", "new hash").
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				poolMock.ExpectQuery("WITH revoked AS.*token_hash <> \\$2").
+					WithArgs(1, hash.GetTokenHash("token")).
+					WillReturnRows(poolMock.NewRows([]string{"count"}).AddRow(int64(3)))
+				poolMock.ExpectExec("INSERT INTO security_event").
+					WithArgs(1, account.EventPasswordChanged, "10.0.0.7", "laptop").
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				poolMock.ExpectCommit()
+			},
+			want:     3,
+			wantCode: codes.OK,
+		},
+		{
+			name:   "rewrapped vault key",
+			change: &rewrapped,
+			prepare: func(poolMock pgxmock.PgxPoolIface) {
+				poolMock.ExpectBegin()
+				poolMock.ExpectExec("UPDATE users SET password_hash.*wrapped_vault_key IS NOT NULL").
+					WithArgs(1, "old hash", "new hash", []byte("new salt"), []byte("rewrapped")).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				poolMock.ExpectQuery("WITH revoked AS").
+					WithArgs(1, hash.GetTokenHash("token")).
+					WillReturnRows(poolMock.NewRows([]string{"count"}).AddRow(int64(0)))
+				poolMock.ExpectExec("INSERT INTO security_event").
+					WithArgs(1, account.EventPasswordChanged, "10.0.0.7", "laptop").
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				poolMock.ExpectCommit()
+			},
+			wantCode: codes.OK,
+		},
+		{
+			// пароль успели сменить: токены не отзываются, транзакция откатывается
+			name:   "changed concurrently",
+			change: change,
+			prepare: func(poolMock pgxmock.PgxPoolIface) {
+				poolMock.ExpectBegin()
+				poolMock.ExpectExec("UPDATE users SET password_hash").
+					WithArgs(1, "old hash", "new hash").
+					WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+				poolMock.ExpectRollback()
+			},
+			wantCode: codes.Aborted,
+		},
+		{
+			name:   "security event error",
+			change: change,
+			prepare: func(poolMock pgxmock.PgxPoolIface) {
+				poolMock.ExpectBegin()
+				poolMock.ExpectExec("UPDATE users SET password_hash").
+					WithArgs(1, "old hash", "new hash").
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				poolMock.ExpectQuery("WITH revoked AS").
+					WithArgs(1, hash.GetTokenHash("token")).
+					WillReturnRows(poolMock.NewRows([]string{"count"}).AddRow(int64(1)))
+				poolMock.ExpectExec("INSERT INTO security_event").
+					WithArgs(1, account.EventPasswordChanged, "10.0.0.7", "laptop").
+					WillReturnError(errors.New("connection refused"))
+				poolMock.ExpectRollback()
+			},
+			wantCode: codes.Unknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poolMock, err := pgxmock.NewPool()
+			require.NoError(t, err)
+			s := &Account{
+				Repository: &repository.Repository{Pool: poolMock},
+			}
+			tt.prepare(poolMock)
+
+			got, err := s.ChangePassword(context.Background(), tt.change)
+			if tt.wantCode != codes.OK {
+				assert.Error(t, err)
+				assert.Equal(t, tt.wantCode, status.Code(err))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, poolMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAccount_GetAccount(t *testing.T) {
+	poolMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	s := &Account{
+		Repository: &repository.Repository{Pool: poolMock},
+	}
+
+	poolMock.ExpectQuery("SELECT id, login, password_hash.*FROM users").
+		WithArgs(1).
+		WillReturnRows(poolMock.NewRows([]string{"id", "login", "password_hash", "kdf_salt", "wrapped_vault_key"}).
+			AddRow(1, "alice", []byte("hash"), []byte("salt"), []byte("wrapped")))
+	got, err := s.GetAccount(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", got.Login)
+	assert.Equal(t, "hash", got.PasswordHash)
+	assert.Equal(t, []byte("wrapped"), got.WrappedVaultKey)
+
+	poolMock.ExpectQuery("SELECT id, login, password_hash.*FROM users").
+		WithArgs(2).
+		WillReturnError(errors.New("no rows in result set"))
+	_, err = s.GetAccount(context.Background(), 2)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,7 @@
+package account
+
+import "github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
+
+type Account struct {
+	Repository *repository.Repository
+}
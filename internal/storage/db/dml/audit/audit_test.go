@@ -0,0 +1,134 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/audit"
+	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
+)
+
+func TestAudit_SaveAuditEvent(t *testing.T) {
+	poolMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	s := &Audit{
+		Repository: &repository.Repository{Pool: poolMock},
+	}
+	userID := 1
+	targetID := int64(42)
+	event := &audit.Event{
+		UserID:     &userID,
+		Type:       audit.EventItemRead,
+		Method:     "/items.password.Service/GetPassword",
+		TargetID:   &targetID,
+		Success:    true,
+		StatusCode: "OK",
+		ClientIP:   "10.0.0.7",
+		UserAgent:  "laptop",
+	}
+
+	poolMock.ExpectExec("INSERT INTO audit_event").
+		WithArgs(&userID, "", audit.EventItemRead, "/items.password.Service/GetPassword", &targetID, (*int)(nil),
+			true, "OK", "10.0.0.7", "laptop").
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	assert.NoError(t, s.SaveAuditEvent(context.Background(), event))
+
+	// неудачный вход с неизвестным логином
+	failed := &audit.Event{Login: "mallory", Type: audit.EventLogin, Method: "/auth.AuthService/Login", StatusCode: "Unauthenticated"}
+	poolMock.ExpectExec("INSERT INTO audit_event").
+		WithArgs((*int)(nil), "mallory", audit.EventLogin, "/auth.AuthService/Login", (*int64)(nil), (*int)(nil),
+			false, "Unauthenticated", "", "").
+		WillReturnError(errors.New("connection refused"))
+	assert.Error(t, s.SaveAuditEvent(context.Background(), failed))
+	assert.NoError(t, poolMock.ExpectationsWereMet())
+}
+
+func TestAudit_ListAuditEvents(t *testing.T) {
+	createdAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	since := createdAt.Add(-24 * time.Hour)
+	userID := 1
+	targetID := int64(42)
+	columns := []string{"id", "user_id", "login", "event_type", "method", "target_id", "personal_token_id",
+		"success", "status_code", "client_ip", "user_agent", "created_at"}
+
+	tests := []struct {
+		name     string
+		filter   *audit.Filter
+		query    string
+		args     []interface{}
+		want     []audit.Event
+		queryErr error
+	}{
+		{
+			name:   "first page",
+			filter: &audit.Filter{UserID: 1, Limit: 50},
+			query:  "WHERE user_id = \\$1\\s+ORDER BY id DESC\\s+LIMIT \\$2",
+			args:   []interface{}{1, 50},
+			want: []audit.Event{
+				{ID: 11, UserID: &userID, Type: audit.EventItemRead, Method: "/items.password.Service/GetPassword",
+					TargetID: &targetID, Success: true, StatusCode: "OK", ClientIP: "10.0.0.7", CreatedAt: createdAt},
+				{ID: 10, UserID: &userID, Login: "alice", Type: audit.EventLogin, Method: "/auth.AuthService/Login",
+					StatusCode: "Unauthenticated", CreatedAt: createdAt},
+			},
+		},
+		{
+			name: "all filters",
+			filter: &audit.Filter{
+				UserID:     1,
+				Types:      []string{audit.EventItemRead, audit.EventItemUpdate},
+				TargetID:   42,
+				Since:      &since,
+				Until:      &createdAt,
+				FailedOnly: true,
+				BeforeID:   100,
+				Limit:      20,
+			},
+			query: "WHERE user_id = \\$1 AND event_type = ANY\\(\\$2\\) AND target_id = \\$3 AND created_at >= \\$4 " +
+				"AND created_at < \\$5 AND success = FALSE AND id < \\$6\\s+ORDER BY id DESC\\s+LIMIT \\$7",
+			args: []interface{}{1, []string{audit.EventItemRead, audit.EventItemUpdate}, int64(42), since, createdAt, int64(100), 20},
+		},
+		{
+			name:     "sql error",
+			filter:   &audit.Filter{UserID: 1, Limit: 50},
+			query:    "FROM audit_event",
+			args:     []interface{}{1, 50},
+			queryErr: errors.New("connection refused"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poolMock, err := pgxmock.NewPool()
+			require.NoError(t, err)
+			s := &Audit{
+				Repository: &repository.Repository{Pool: poolMock},
+			}
+
+			expectation := poolMock.ExpectQuery(tt.query).WithArgs(tt.args...)
+			if tt.queryErr != nil {
+				expectation.WillReturnError(tt.queryErr)
+			} else {
+				rows := poolMock.NewRows(columns)
+				for _, e := range tt.want {
+					rows.AddRow(e.ID, e.UserID, e.Login, e.Type, e.Method, e.TargetID, e.PersonalTokenID,
+						e.Success, e.StatusCode, e.ClientIP, e.UserAgent, e.CreatedAt)
+				}
+				expectation.WillReturnRows(rows)
+			}
+
+			got, err := s.ListAuditEvents(context.Background(), tt.filter)
+			if tt.queryErr != nil {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, poolMock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package audit
+
+import "github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
+
+type Audit struct {
+	Repository *repository.Repository
+}
@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+
+	modelAuth "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+)
+
+// GetLoginAttempts неудачные попытки входа по логину и IP адресу, записи без неудач не возвращаются
+func (s *Auth) GetLoginAttempts(ctx context.Context, subjects []string) ([]modelAuth.LoginAttempts, error) {
+	rows, err := s.Repository.Pool.Query(
+		ctx,
+		"SELECT subject, failures, last_failure_at, locked_until FROM login_attempt WHERE subject = ANY($1)",
+		subjects)
+	if err != nil {
+		return nil, errors.New("GetLoginAttempts error in sql query")
+	}
+	defer rows.Close()
+
+	var attempts []modelAuth.LoginAttempts
+	for rows.Next() {
+		var attempt modelAuth.LoginAttempts
+		err = rows.Scan(&attempt.Subject, &attempt.Failures, &attempt.LastFailureAt, &attempt.LockedUntil)
+		if err != nil {
+			return nil, errors.New("GetLoginAttempts error in scan")
+		}
+		attempts = append(attempts, attempt)
+	}
+	if rows.Err() != nil {
+		return nil, errors.New("GetLoginAttempts error in rows")
+	}
+	return attempts, nil
+}
+
+// ReserveLoginAttempt учет попытки входа до проверки пароля, попытка считается неудачной,
+// пока вход не завершится успешно; возвращает false, если попытки исчерпаны и вход заблокирован
+// неудачи старше lockout забываются, после maxFailures попыток подряд вход блокируется на lockout
+func (s *Auth) ReserveLoginAttempt(ctx context.Context, subject string, maxFailures int, lockout time.Duration) (bool, error) {
+	var failures int
+	err := s.Repository.Pool.QueryRow(
+		ctx,
+		`INSERT INTO login_attempt (subject, failures, last_failure_at, locked_until)
+			VALUES ($1, 1, NOW(), CASE WHEN $2 <= 1 THEN NOW() + $3 * INTERVAL '1 second' END)
+			ON CONFLICT (subject) DO UPDATE SET
+				failures = CASE
+					WHEN login_attempt.last_failure_at < NOW() - $3 * INTERVAL '1 second' THEN 1
+					ELSE login_attempt.failures + 1
+				END,
+				last_failure_at = NOW(),
+				locked_until = CASE
+					WHEN login_attempt.last_failure_at >= NOW() - $3 * INTERVAL '1 second'
+						AND login_attempt.failures + 1 >= $2 THEN NOW() + $3 * INTERVAL '1 second'
+					ELSE login_attempt.locked_until
+				END
+				WHERE login_attempt.locked_until IS NULL OR login_attempt.locked_until <= NOW()
+			RETURNING failures`,
+		subject,
+		maxFailures,
+		int64(lockout.Seconds())).Scan(&failures)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.New("ReserveLoginAttempt error in sql query")
+	}
+	return true, nil
+}
+
+// ReleaseLoginAttempt снятие учтенной попытки после успешного входа
+// вместе с попыткой снимается и блокировка: без нее предел неудач не достигнут
+func (s *Auth) ReleaseLoginAttempt(ctx context.Context, subject string) error {
+	_, err := s.Repository.Pool.Exec(
+		ctx,
+		"UPDATE login_attempt SET failures = failures - 1, locked_until = NULL WHERE subject = $1 AND failures > 0",
+		subject)
+	if err != nil {
+		return errors.New("ReleaseLoginAttempt error in sql query")
+	}
+	return nil
+}
+
+// ResetLoginFailures сброс неудачных попыток после успешного входа
+func (s *Auth) ResetLoginFailures(ctx context.Context, subject string) error {
+	_, err := s.Repository.Pool.Exec(ctx, "DELETE FROM login_attempt WHERE subject = $1", subject)
+	if err != nil {
+		return errors.New("ResetLoginFailures error in sql query")
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pashagolub/pgxmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
+)
+
+func TestAuth_GetLoginAttempts(t *testing.T) {
+	lastFailureAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	lockedUntil := lastFailureAt.Add(15 * time.Minute)
+	subjects := []string{"login:alice", "ip:10.0.0.7"}
+
+	tests := []struct {
+		name     string
+		want     []auth.LoginAttempts
+		queryErr error
+		wantErr  bool
+	}{
+		{
+			name: "login locked",
+			want: []auth.LoginAttempts{
+				{Subject: "login:alice", Failures: 5, LastFailureAt: lastFailureAt, LockedUntil: &lockedUntil},
+				{Subject: "ip:10.0.0.7", Failures: 2, LastFailureAt: lastFailureAt},
+			},
+		},
+		{
+			name: "no failures",
+		},
+		{
+			name:     "sql error",
+			queryErr: errors.New("connection refused"),
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poolMock, err := pgxmock.NewPool()
+			require.NoError(t, err)
+			s := &Auth{
+				Repository: &repository.Repository{Pool: poolMock},
+			}
+
+			expectation := poolMock.ExpectQuery("SELECT subject, failures, last_failure_at, locked_until FROM login_attempt").
+				WithArgs(subjects)
+			if tt.queryErr != nil {
+				expectation.WillReturnError(tt.queryErr)
+			} else {
+				rows := poolMock.NewRows([]string{"subject", "failures", "last_failure_at", "locked_until"})
+				for _, attempt := range tt.want {
+					rows.AddRow(attempt.Subject, attempt.Failures, attempt.LastFailureAt, attempt.LockedUntil)
+				}
+				expectation.WillReturnRows(rows)
+			}
+
+			got, err := s.GetLoginAttempts(context.Background(), subjects)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, poolMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAuth_ReserveLoginAttempt(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int
+		queryErr     error
+		wantReserved bool
+		wantErr      bool
+	}{
+		{
+			name:         "reserved",
+			failures:     3,
+			wantReserved: true,
+		},
+		{
+			// заблокированная запись не обновляется и не возвращается
+			name:     "locked",
+			queryErr: pgx.ErrNoRows,
+		},
+		{
+			name:     "sql error",
+			queryErr: errors.New("connection refused"),
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poolMock, err := pgxmock.NewPool()
+			require.NoError(t, err)
+			s := &Auth{
+				Repository: &repository.Repository{Pool: poolMock},
+			}
+
+			expectation := poolMock.ExpectQuery("INSERT INTO login_attempt.*ON CONFLICT \\(subject\\) DO UPDATE.*RETURNING failures").
+				WithArgs("login:alice", 5, int64(900))
+			if tt.queryErr != nil {
+				expectation.WillReturnError(tt.queryErr)
+			} else {
+				expectation.WillReturnRows(poolMock.NewRows([]string{"failures"}).AddRow(tt.failures))
+			}
+
+			reserved, err := s.ReserveLoginAttempt(context.Background(), "login:alice", 5, 15*time.Minute)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantReserved, reserved)
+			assert.NoError(t, poolMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAuth_ReleaseLoginAttempt(t *testing.T) {
+	poolMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	s := &Auth{
+		Repository: &repository.Repository{Pool: poolMock},
+	}
+
+	poolMock.ExpectExec("UPDATE login_attempt SET failures = failures - 1, locked_until = NULL WHERE subject = \\$1").
+		WithArgs("ip:10.0.0.7").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	assert.NoError(t, s.ReleaseLoginAttempt(context.Background(), "ip:10.0.0.7"))
+
+	poolMock.ExpectExec("UPDATE login_attempt").
+		WithArgs("ip:10.0.0.7").
+		WillReturnError(errors.New("connection refused"))
+	assert.Error(t, s.ReleaseLoginAttempt(context.Background(), "ip:10.0.0.7"))
+	assert.NoError(t, poolMock.ExpectationsWereMet())
+}
+
+func TestAuth_ResetLoginFailures(t *testing.T) {
+	poolMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	s := &Auth{
+		Repository: &repository.Repository{Pool: poolMock},
+	}
+
+	poolMock.ExpectExec("DELETE FROM login_attempt WHERE subject = \\$1").
+		WithArgs("login:alice").
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	assert.NoError(t, s.ResetLoginFailures(context.Background(), "login:alice"))
+	assert.NoError(t, poolMock.ExpectationsWereMet())
+}
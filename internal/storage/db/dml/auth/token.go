@@ -8,21 +8,26 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/account"
 	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
 	"github.com/ramil063/secondgodiplom/internal/hash"
 	"github.com/ramil063/secondgodiplom/internal/logger"
 )
 
-func (s *Auth) SaveAccessToken(ctx context.Context, userID int, token string) (int, error) {
+func (s *Auth) SaveAccessToken(ctx context.Context, userID int, token string, session auth.SessionInfo) (int, error) {
 	tokenHash := hash.GetTokenHash(token)
 
 	expiresAt := time.Now().Add(time.Duration(auth.TokenExpiredSeconds) * time.Second)
 	row := s.Repository.Pool.QueryRow(
 		ctx,
-		"INSERT INTO oauth_access_token (token_hash, user_id, expires_at) VALUES ($1, $2, $3) RETURNING id",
+		`INSERT INTO oauth_access_token (token_hash, user_id, expires_at, client_ip, user_agent, session_created_at)
+			VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
 		tokenHash,
 		userID,
-		expiresAt)
+		expiresAt,
+		session.ClientIP,
+		session.UserAgent,
+		session.CreatedAt)
 
 	var accessTokenId int
 	err := row.Scan(&accessTokenId)
@@ -34,16 +39,19 @@ func (s *Auth) SaveAccessToken(ctx context.Context, userID int, token string) (i
 	return accessTokenId, nil
 }
 
-func (s *Auth) SaveRefreshToken(ctx context.Context, accessTokenId int, token string) error {
+// SaveRefreshToken сохранение refresh token'а в семействе токенов
+// семейство создается при входе и передается от старого токена новому при каждом обновлении
+func (s *Auth) SaveRefreshToken(ctx context.Context, accessTokenId int, token string, familyID string) error {
 	tokenHash := hash.GetTokenHash(token)
 
 	expiresAt := time.Now().Add(time.Duration(auth.TokenExpiredSeconds) * time.Second)
 	exec, err := s.Repository.Pool.Exec(
 		ctx,
-		"INSERT INTO oauth_refresh_token (token_hash, access_token_id, expires_at) VALUES ($1, $2, $3)",
+		"INSERT INTO oauth_refresh_token (token_hash, access_token_id, expires_at, family_id) VALUES ($1, $2, $3, $4)",
 		tokenHash,
 		accessTokenId,
-		expiresAt)
+		expiresAt,
+		familyID)
 
 	if err != nil {
 		return errors.New("RegisterUser error in sql empty result")
@@ -63,6 +71,8 @@ func (s *Auth) SaveRefreshToken(ctx context.Context, accessTokenId int, token st
 	return nil
 }
 
+// GetRefreshToken получение refresh token'а, в том числе отозванного: повторное использование
+// обмененного токена означает его кражу. Для токенов, выданных до учета семейств, семейство - сам токен
 func (s *Auth) GetRefreshToken(ctx context.Context, refreshToken string) (*auth.RefreshToken, error) {
 	refreshTokenHash := hash.GetTokenHash(refreshToken)
 
@@ -72,10 +82,14 @@ func (s *Auth) GetRefreshToken(ctx context.Context, refreshToken string) (*auth.
 				ort.token_hash,
 				oat.token_hash AS access_token_hash,
 				oat.user_id,
-				ort.expires_at
+				ort.expires_at,
+				COALESCE(oat.session_created_at, oat.created_at),
+				COALESCE(ort.family_id, ort.id::TEXT),
+				COALESCE(ort.is_revoked, FALSE),
+				ort.rotated_at IS NOT NULL
 			FROM oauth_refresh_token ort
 			    LEFT JOIN oauth_access_token oat on oat.id = ort.access_token_id
-			WHERE ort.is_revoked=FALSE AND ort.token_hash = $1
+			WHERE ort.token_hash = $1
 			ORDER BY ort.created_at DESC
 			LIMIT 1`,
 		refreshTokenHash)
@@ -84,8 +98,12 @@ func (s *Auth) GetRefreshToken(ctx context.Context, refreshToken string) (*auth.
 	var accessTokenHash []byte
 	var userID int
 	var expiresAt time.Time
+	var sessionCreated time.Time
+	var familyID string
+	var isRevoked bool
+	var isRotated bool
 
-	err := row.Scan(&tokenHash, &accessTokenHash, &userID, &expiresAt)
+	err := row.Scan(&tokenHash, &accessTokenHash, &userID, &expiresAt, &sessionCreated, &familyID, &isRevoked, &isRotated)
 	if err != nil {
 		return nil, status.Error(codes.NotFound, "token not found")
 	}
@@ -95,16 +113,29 @@ func (s *Auth) GetRefreshToken(ctx context.Context, refreshToken string) (*auth.
 		TokenHash:       string(tokenHash),
 		AccessTokenHash: string(accessTokenHash),
 		ExpiresAt:       expiresAt,
+		SessionCreated:  sessionCreated,
+		FamilyID:        familyID,
+		IsRevoked:       isRevoked,
+		IsRotated:       isRotated,
 	}
 	return token, nil
 }
 
+// RevokeRefreshToken отзыв refresh token'а вместе с выданным с ним токеном авторизации при обмене на новую пару
+// после обновления токенов у сессии остается только новая пара
+// если токен уже отозван (например, параллельный обмен), возвращается FailedPrecondition
 func (s *Auth) RevokeRefreshToken(ctx context.Context, refreshToken string) error {
 	refreshTokenHash := hash.GetTokenHash(refreshToken)
 
 	exec, err := s.Repository.Pool.Exec(
 		ctx,
-		"UPDATE oauth_refresh_token SET is_revoked=TRUE WHERE token_hash = $1",
+		`WITH revoked AS (
+				UPDATE oauth_refresh_token SET is_revoked = TRUE, rotated_at = NOW()
+				WHERE token_hash = $1 AND is_revoked = FALSE
+				RETURNING access_token_id
+			)
+			UPDATE oauth_access_token SET is_revoked = TRUE
+			WHERE id IN (SELECT access_token_id FROM revoked)`,
 		refreshTokenHash)
 
 	if err != nil {
@@ -118,7 +149,187 @@ func (s *Auth) RevokeRefreshToken(ctx context.Context, refreshToken string) erro
 	rows := exec.RowsAffected()
 	if rows != 1 {
 		logger.WriteErrorLog("RevokeRefreshToken error expected to affect 1 row")
-		return errors.New("RevokeRefreshToken expected to affect 1 row")
+		return status.Error(codes.FailedPrecondition, "refresh token already revoked")
 	}
 	return nil
 }
+
+// RevokeTokenFamily отзыв всех refresh token'ов семейства и выданных с ними токенов авторизации
+// вместе с отзывом записывается событие безопасности, возвращает число отозванных токенов авторизации
+func (s *Auth) RevokeTokenFamily(ctx context.Context, familyID string, event account.SecurityEvent) (int64, error) {
+	row := s.Repository.Pool.QueryRow(
+		ctx,
+		`WITH family AS (
+				UPDATE oauth_refresh_token ort SET is_revoked = TRUE
+				FROM oauth_access_token oat
+				WHERE oat.id = ort.access_token_id AND oat.user_id = $1
+					AND COALESCE(ort.family_id, ort.id::TEXT) = $2
+				RETURNING ort.access_token_id
+			), revoked AS (
+				UPDATE oauth_access_token SET is_revoked = TRUE
+				WHERE id IN (SELECT access_token_id FROM family) AND is_revoked = FALSE
+				RETURNING id
+			), event AS (
+				INSERT INTO security_event (user_id, event_type, client_ip, user_agent) VALUES ($1, $3, $4, $5)
+			)
+			SELECT COUNT(*) FROM revoked`,
+		event.UserID,
+		familyID,
+		event.Type,
+		event.ClientIP,
+		event.UserAgent)
+
+	var revoked int64
+	if err := row.Scan(&revoked); err != nil {
+		return 0, errors.New("RevokeTokenFamily error in sql empty result")
+	}
+	return revoked, nil
+}
+
+// IsAccessTokenActive проверка, что токен авторизации выдан сервером, не отозван, не истек
+// и принадлежит активному пользователю
+// у действующего токена отмечается время последнего использования
+func (s *Auth) IsAccessTokenActive(ctx context.Context, accessToken string) (bool, error) {
+	row := s.Repository.Pool.QueryRow(
+		ctx,
+		`WITH used AS (
+				UPDATE oauth_access_token oat SET last_used_at = NOW()
+				FROM users u
+				WHERE oat.token_hash = $1 AND oat.is_revoked = FALSE AND oat.expires_at > NOW()
+					AND u.id = oat.user_id AND u.is_active = TRUE
+				RETURNING oat.id
+			)
+			SELECT EXISTS(SELECT 1 FROM used)`,
+		hash.GetTokenHash(accessToken))
+
+	var active bool
+	if err := row.Scan(&active); err != nil {
+		return false, errors.New("IsAccessTokenActive error in sql empty result")
+	}
+	return active, nil
+}
+
+// RevokeAccessToken отзыв токена авторизации пользователя вместе с его refresh token'ами
+func (s *Auth) RevokeAccessToken(ctx context.Context, userID int, accessToken string) error {
+	row := s.Repository.Pool.QueryRow(
+		ctx,
+		`WITH revoked AS (
+				UPDATE oauth_access_token SET is_revoked = TRUE
+				WHERE token_hash = $1 AND user_id = $2 AND is_revoked = FALSE
+				RETURNING id
+			), revoked_refresh AS (
+				UPDATE oauth_refresh_token SET is_revoked = TRUE
+				WHERE access_token_id IN (SELECT id FROM revoked)
+			)
+			SELECT COUNT(*) FROM revoked`,
+		hash.GetTokenHash(accessToken),
+		userID)
+
+	var revoked int64
+	if err := row.Scan(&revoked); err != nil {
+		return errors.New("RevokeAccessToken error in sql empty result")
+	}
+	if revoked != 1 {
+		logger.WriteErrorLog("RevokeAccessToken error expected to affect 1 row")
+		return status.Error(codes.NotFound, "token not found")
+	}
+	return nil
+}
+
+// RevokeUserTokens отзыв всех токенов пользователя, возвращает число отозванных сессий
+func (s *Auth) RevokeUserTokens(ctx context.Context, userID int) (int64, error) {
+	row := s.Repository.Pool.QueryRow(
+		ctx,
+		`WITH revoked AS (
+				UPDATE oauth_access_token SET is_revoked = TRUE
+				WHERE user_id = $1 AND is_revoked = FALSE
+				RETURNING id
+			), revoked_refresh AS (
+				UPDATE oauth_refresh_token SET is_revoked = TRUE
+				WHERE access_token_id IN (SELECT id FROM revoked)
+			)
+			SELECT COUNT(*) FROM revoked`,
+		userID)
+
+	var revoked int64
+	if err := row.Scan(&revoked); err != nil {
+		return 0, errors.New("RevokeUserTokens error in sql empty result")
+	}
+	return revoked, nil
+}
+
+// ListSessions активные сессии пользователя, сначала недавно использованные
+// сессия активна, пока не отозваны и не истекли ее токен авторизации и refresh token
+func (s *Auth) ListSessions(ctx context.Context, userID int, currentAccessToken string) ([]auth.Session, error) {
+	rows, err := s.Repository.Pool.Query(
+		ctx,
+		`SELECT
+				oat.id,
+				oat.client_ip,
+				oat.user_agent,
+				COALESCE(oat.session_created_at, oat.created_at),
+				oat.last_used_at,
+				oat.token_hash = $2
+			FROM oauth_access_token oat
+			WHERE oat.user_id = $1 AND oat.is_revoked = FALSE AND oat.expires_at > NOW()
+				AND EXISTS(
+					SELECT 1 FROM oauth_refresh_token ort
+					WHERE ort.access_token_id = oat.id AND ort.is_revoked = FALSE AND ort.expires_at > NOW()
+				)
+			ORDER BY COALESCE(oat.last_used_at, oat.created_at) DESC`,
+		userID,
+		hash.GetTokenHash(currentAccessToken))
+	if err != nil {
+		return nil, errors.New("ListSessions error in sql")
+	}
+	defer rows.Close()
+
+	var sessions []auth.Session
+	for rows.Next() {
+		var session auth.Session
+		err = rows.Scan(
+			&session.ID,
+			&session.ClientIP,
+			&session.UserAgent,
+			&session.CreatedAt,
+			&session.LastUsedAt,
+			&session.Current,
+		)
+		if err != nil {
+			return nil, errors.New("ListSessions error in scan")
+		}
+		sessions = append(sessions, session)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.New("ListSessions error in rows")
+	}
+	return sessions, nil
+}
+
+// RevokeSession отзыв одной сессии пользователя: токена авторизации и его refresh token'ов
+// возвращает хеш отозванного токена авторизации
+func (s *Auth) RevokeSession(ctx context.Context, userID int, sessionID int) (string, error) {
+	row := s.Repository.Pool.QueryRow(
+		ctx,
+		`WITH revoked AS (
+				UPDATE oauth_access_token SET is_revoked = TRUE
+				WHERE id = $1 AND user_id = $2 AND is_revoked = FALSE
+				RETURNING id, token_hash
+			), revoked_refresh AS (
+				UPDATE oauth_refresh_token SET is_revoked = TRUE
+				WHERE access_token_id IN (SELECT id FROM revoked)
+			)
+			SELECT COUNT(*), (SELECT token_hash FROM revoked LIMIT 1) FROM revoked`,
+		sessionID,
+		userID)
+
+	var revoked int64
+	var tokenHash []byte
+	if err := row.Scan(&revoked, &tokenHash); err != nil {
+		return "", errors.New("RevokeSession error in sql empty result")
+	}
+	if revoked != 1 {
+		return "", status.Error(codes.NotFound, "session not found")
+	}
+	return string(tokenHash), nil
+}
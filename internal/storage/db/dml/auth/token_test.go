@@ -2,13 +2,18 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/jackc/pgconn"
+	"github.com/pashagolub/pgxmock"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/account"
 	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
 	"github.com/ramil063/secondgodiplom/internal/hash"
 	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
@@ -39,6 +44,25 @@ func TestAuth_GetRefreshToken(t *testing.T) {
 				AccessTokenHash: "access_token_hash",
 				UserID:          1,
 				ExpiresAt:       time.Now(),
+				SessionCreated:  time.Now().Add(-time.Hour),
+				FamilyID:        "family-1",
+			},
+		},
+		{
+			name: "rotated token",
+			args: args{
+				ctx:          context.Background(),
+				refreshToken: "refresh_token_hash",
+			},
+			want: &auth.RefreshToken{
+				TokenHash:       "refresh_token_hash",
+				AccessTokenHash: "access_token_hash",
+				UserID:          1,
+				ExpiresAt:       time.Now(),
+				SessionCreated:  time.Now().Add(-time.Hour),
+				FamilyID:        "family-1",
+				IsRevoked:       true,
+				IsRotated:       true,
 			},
 		},
 	}
@@ -59,6 +83,10 @@ func TestAuth_GetRefreshToken(t *testing.T) {
 						[]byte(tt.want.AccessTokenHash),
 						tt.want.UserID,
 						tt.want.ExpiresAt,
+						tt.want.SessionCreated,
+						tt.want.FamilyID,
+						tt.want.IsRevoked,
+						tt.want.IsRotated,
 					},
 				})
 
@@ -66,8 +94,9 @@ func TestAuth_GetRefreshToken(t *testing.T) {
 				Repository: &repository.Repository{Pool: poolMock},
 			}
 
-			_, err := s.GetRefreshToken(tt.args.ctx, tt.args.refreshToken)
+			got, err := s.GetRefreshToken(tt.args.ctx, tt.args.refreshToken)
 			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
 		})
 	}
 }
@@ -88,8 +117,14 @@ func TestAuth_RevokeRefreshToken(t *testing.T) {
 		{
 			name: "test 1",
 			args: args{
-				ctx:          context.Background(),
-				query:        `UPDATE oauth_refresh_token SET is_revoked=TRUE WHERE token_hash = $1`,
+				ctx: context.Background(),
+				query: `WITH revoked AS (
+				UPDATE oauth_refresh_token SET is_revoked = TRUE, rotated_at = NOW()
+				WHERE token_hash = $1 AND is_revoked = FALSE
+				RETURNING access_token_id
+			)
+			UPDATE oauth_access_token SET is_revoked = TRUE
+			WHERE id IN (SELECT access_token_id FROM revoked)`,
 				refreshToken: "refresh_token_hash",
 			},
 		},
@@ -115,15 +150,61 @@ func TestAuth_RevokeRefreshToken(t *testing.T) {
 	}
 }
 
+func TestAuth_RevokeRefreshTokenAlreadyRevoked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	poolMock := repositoryMock.NewMockPooler(ctrl)
+	s := &Auth{
+		Repository: &repository.Repository{Pool: poolMock},
+	}
+	ctx := context.Background()
+
+	poolMock.EXPECT().
+		Exec(ctx, gomock.Any(), hash.GetTokenHash("refresh_token")).
+		Return(pgconn.CommandTag("UPDATE 0"), nil)
+	err := s.RevokeRefreshToken(ctx, "refresh_token")
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestAuth_RevokeTokenFamily(t *testing.T) {
+	poolMock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	s := &Auth{
+		Repository: &repository.Repository{Pool: poolMock},
+	}
+	event := account.SecurityEvent{
+		UserID:    1,
+		Type:      account.EventRefreshTokenReuse,
+		ClientIP:  "10.0.0.7",
+		UserAgent: "grpc-go",
+	}
+
+	poolMock.ExpectQuery("UPDATE oauth_refresh_token ort SET is_revoked = TRUE.*INSERT INTO security_event").
+		WithArgs(1, "family-1", account.EventRefreshTokenReuse, "10.0.0.7", "grpc-go").
+		WillReturnRows(poolMock.NewRows([]string{"count"}).AddRow(int64(2)))
+	got, err := s.RevokeTokenFamily(context.Background(), "family-1", event)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), got)
+
+	poolMock.ExpectQuery("UPDATE oauth_refresh_token ort").
+		WithArgs(1, "family-1", account.EventRefreshTokenReuse, "10.0.0.7", "grpc-go").
+		WillReturnError(errors.New("connection refused"))
+	_, err = s.RevokeTokenFamily(context.Background(), "family-1", event)
+	assert.Error(t, err)
+	assert.NoError(t, poolMock.ExpectationsWereMet())
+}
+
 func TestAuth_SaveAccessToken(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	type args struct {
-		ctx    context.Context
-		userID int
-		query  string
-		token  string
+		ctx     context.Context
+		userID  int
+		query   string
+		token   string
+		session auth.SessionInfo
 	}
 	tests := []struct {
 		name string
@@ -133,10 +214,16 @@ func TestAuth_SaveAccessToken(t *testing.T) {
 		{
 			name: "test 1",
 			args: args{
-				ctx:    context.Background(),
-				query:  "INSERT INTO oauth_access_token (token_hash, user_id, expires_at) VALUES ($1, $2, $3) RETURNING id",
+				ctx: context.Background(),
+				query: `INSERT INTO oauth_access_token (token_hash, user_id, expires_at, client_ip, user_agent, session_created_at)
+			VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
 				userID: 1,
 				token:  "access_token_hash",
+				session: auth.SessionInfo{
+					ClientIP:  "10.0.0.7",
+					UserAgent: "gophkeeper-client",
+					CreatedAt: time.Now(),
+				},
 			},
 			want: 0,
 		},
@@ -152,6 +239,11 @@ func TestAuth_SaveAccessToken(t *testing.T) {
 					tt.args.ctx,
 					tt.args.query,
 					hash.GetTokenHash(tt.args.token),
+					tt.args.userID,
+					gomock.Any(),
+					tt.args.session.ClientIP,
+					tt.args.session.UserAgent,
+					tt.args.session.CreatedAt,
 				).
 				Return(&mockRow{
 					values: []interface{}{
@@ -159,7 +251,7 @@ func TestAuth_SaveAccessToken(t *testing.T) {
 					},
 				})
 
-			got, err := s.SaveAccessToken(tt.args.ctx, tt.args.userID, tt.args.token)
+			got, err := s.SaveAccessToken(tt.args.ctx, tt.args.userID, tt.args.token, tt.args.session)
 			assert.NoError(t, err)
 			if got != tt.want {
 				t.Errorf("SaveAccessToken() got = %v, want %v", got, tt.want)
@@ -177,6 +269,7 @@ func TestAuth_SaveRefreshToken(t *testing.T) {
 		query         string
 		accessTokenId int
 		token         string
+		familyID      string
 	}
 	tests := []struct {
 		name    string
@@ -187,9 +280,10 @@ func TestAuth_SaveRefreshToken(t *testing.T) {
 			name: "test 1",
 			args: args{
 				ctx:           context.Background(),
-				query:         "INSERT INTO oauth_refresh_token (token_hash, access_token_id, expires_at) VALUES ($1, $2, $3)",
+				query:         "INSERT INTO oauth_refresh_token (token_hash, access_token_id, expires_at, family_id) VALUES ($1, $2, $3, $4)",
 				accessTokenId: 1,
 				token:         "refresh_token_hash",
+				familyID:      "family-1",
 			},
 		},
 	}
@@ -206,11 +300,237 @@ func TestAuth_SaveRefreshToken(t *testing.T) {
 					tt.args.query,
 					hash.GetTokenHash(tt.args.token),
 					tt.args.accessTokenId,
-					gomock.Any()).
+					gomock.Any(),
+					tt.args.familyID).
 				Return(expectedCommandTag, nil)
-			if err := s.SaveRefreshToken(tt.args.ctx, tt.args.accessTokenId, tt.args.token); (err != nil) != tt.wantErr {
+			if err := s.SaveRefreshToken(tt.args.ctx, tt.args.accessTokenId, tt.args.token, tt.args.familyID); (err != nil) != tt.wantErr {
 				t.Errorf("SaveRefreshToken() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func TestAuth_IsAccessTokenActive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name    string
+		row     *mockRow
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "active",
+			row:  &mockRow{values: []interface{}{true}},
+			want: true,
+		},
+		{
+			name: "revoked or unknown",
+			row:  &mockRow{values: []interface{}{false}},
+			want: false,
+		},
+		{
+			name:    "sql error",
+			row:     &mockRow{err: errors.New("connection refused")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poolMock := repositoryMock.NewMockPooler(ctrl)
+			s := &Auth{
+				Repository: &repository.Repository{Pool: poolMock},
+			}
+
+			poolMock.EXPECT().
+				QueryRow(context.Background(), gomock.Any(), hash.GetTokenHash("access_token")).
+				Return(tt.row)
+
+			got, err := s.IsAccessTokenActive(context.Background(), "access_token")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAuth_RevokeAccessToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name     string
+		row      *mockRow
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name: "revoked",
+			row:  &mockRow{values: []interface{}{int64(1)}},
+		},
+		{
+			name:     "already revoked",
+			row:      &mockRow{values: []interface{}{int64(0)}},
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "sql error",
+			row:      &mockRow{err: errors.New("connection refused")},
+			wantErr:  true,
+			wantCode: codes.Unknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poolMock := repositoryMock.NewMockPooler(ctrl)
+			s := &Auth{
+				Repository: &repository.Repository{Pool: poolMock},
+			}
+
+			poolMock.EXPECT().
+				QueryRow(context.Background(), gomock.Any(), hash.GetTokenHash("access_token"), 1).
+				Return(tt.row)
+
+			err := s.RevokeAccessToken(context.Background(), 1, "access_token")
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.wantCode, status.Code(err))
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestAuth_RevokeUserTokens(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	poolMock := repositoryMock.NewMockPooler(ctrl)
+	s := &Auth{
+		Repository: &repository.Repository{Pool: poolMock},
+	}
+
+	poolMock.EXPECT().
+		QueryRow(context.Background(), gomock.Any(), 1).
+		Return(&mockRow{values: []interface{}{int64(2)}})
+	got, err := s.RevokeUserTokens(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), got)
+
+	poolMock.EXPECT().
+		QueryRow(context.Background(), gomock.Any(), 1).
+		Return(&mockRow{err: errors.New("connection refused")})
+	_, err = s.RevokeUserTokens(context.Background(), 1)
+	assert.Error(t, err)
+}
+
+func TestAuth_ListSessions(t *testing.T) {
+	createdAt := time.Now().Add(-time.Hour)
+	lastUsedAt := time.Now()
+
+	tests := []struct {
+		name     string
+		queryErr error
+		want     []auth.Session
+		wantErr  bool
+	}{
+		{
+			name: "sessions",
+			want: []auth.Session{
+				{ID: 2, ClientIP: "10.0.0.7", UserAgent: "laptop", CreatedAt: createdAt, LastUsedAt: &lastUsedAt, Current: true},
+				{ID: 1, ClientIP: "10.0.0.8", UserAgent: "old laptop", CreatedAt: createdAt},
+			},
+		},
+		{
+			name:     "sql error",
+			queryErr: errors.New("connection refused"),
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poolMock, err := pgxmock.NewPool()
+			assert.NoError(t, err)
+			s := &Auth{
+				Repository: &repository.Repository{Pool: poolMock},
+			}
+
+			expectation := poolMock.ExpectQuery("SELECT.*oat.id.*FROM oauth_access_token oat.*").
+				WithArgs(1, hash.GetTokenHash("token"))
+			if tt.queryErr != nil {
+				expectation.WillReturnError(tt.queryErr)
+			} else {
+				rows := poolMock.NewRows([]string{"id", "client_ip", "user_agent", "created_at", "last_used_at", "current"})
+				for _, session := range tt.want {
+					rows.AddRow(session.ID, session.ClientIP, session.UserAgent, session.CreatedAt, session.LastUsedAt, session.Current)
+				}
+				expectation.WillReturnRows(rows)
+			}
+
+			got, err := s.ListSessions(context.Background(), 1, "token")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, poolMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAuth_RevokeSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name     string
+		row      *mockRow
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name: "revoked",
+			row:  &mockRow{values: []interface{}{int64(1), []byte("hash")}},
+		},
+		{
+			name:     "foreign or already revoked session",
+			row:      &mockRow{values: []interface{}{int64(0), []byte(nil)}},
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "sql error",
+			row:      &mockRow{err: errors.New("connection refused")},
+			wantErr:  true,
+			wantCode: codes.Unknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poolMock := repositoryMock.NewMockPooler(ctrl)
+			s := &Auth{
+				Repository: &repository.Repository{Pool: poolMock},
+			}
+
+			poolMock.EXPECT().
+				QueryRow(context.Background(), gomock.Any(), 5, 1).
+				Return(tt.row)
+
+			tokenHash, err := s.RevokeSession(context.Background(), 1, 5)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.wantCode, status.Code(err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "hash", tokenHash)
+		})
+	}
+}
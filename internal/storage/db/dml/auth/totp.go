@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	"github.com/ramil063/secondgodiplom/internal/hash"
+)
+
+// SaveTOTP сохранение секрета второго фактора и кодов восстановления, коды хранятся хешами
+// неподтвержденный секрет перезаписывается вместе с кодами, подтвержденный не меняется
+func (s *Auth) SaveTOTP(ctx context.Context, userID int, totp *auth.TOTP, recoveryCodes []string) error {
+	recoveryCodeHashes := make([][]byte, 0, len(recoveryCodes))
+	for _, code := range recoveryCodes {
+		recoveryCodeHashes = append(recoveryCodeHashes, []byte(hash.GetTokenHash(code)))
+	}
+
+	row := s.Repository.Pool.QueryRow(
+		ctx,
+		`WITH saved AS (
+				INSERT INTO user_totp (user_id, encrypted_secret, iv, encryption_algorithm, key_version)
+				VALUES ($1, $2, $3, $4, $5)
+				ON CONFLICT (user_id) DO UPDATE SET
+					encrypted_secret = EXCLUDED.encrypted_secret,
+					iv = EXCLUDED.iv,
+					encryption_algorithm = EXCLUDED.encryption_algorithm,
+					key_version = EXCLUDED.key_version,
+					last_used_step = 0,
+					created_at = NOW()
+				WHERE user_totp.is_confirmed = FALSE
+				RETURNING user_id
+			), deleted AS (
+				DELETE FROM user_recovery_code WHERE user_id IN (SELECT user_id FROM saved)
+			), inserted AS (
+				INSERT INTO user_recovery_code (user_id, code_hash)
+				SELECT saved.user_id, code_hash FROM saved, unnest($6::bytea[]) AS code_hash
+			)
+			SELECT COUNT(*) FROM saved`,
+		userID,
+		totp.EncryptedSecret,
+		totp.IV,
+		totp.Algorithm,
+		totp.KeyVersion,
+		recoveryCodeHashes)
+
+	var saved int64
+	if err := row.Scan(&saved); err != nil {
+		return errors.New("SaveTOTP error in sql empty result")
+	}
+	if saved != 1 {
+		return status.Error(codes.FailedPrecondition, "totp already enabled")
+	}
+	return nil
+}
+
+// GetTOTP получение второго фактора пользователя, если он не подключался возвращается nil без ошибки
+func (s *Auth) GetTOTP(ctx context.Context, userID int) (*auth.TOTP, error) {
+	rows, err := s.Repository.Pool.Query(
+		ctx,
+		`SELECT encrypted_secret, iv, encryption_algorithm, key_version, is_confirmed, last_used_step
+			FROM user_totp WHERE user_id = $1`,
+		userID)
+	if err != nil {
+		return nil, errors.New("GetTOTP error in sql")
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return nil, errors.New("GetTOTP error in rows")
+		}
+		return nil, nil
+	}
+
+	totp := &auth.TOTP{}
+	err = rows.Scan(
+		&totp.EncryptedSecret,
+		&totp.IV,
+		&totp.Algorithm,
+		&totp.KeyVersion,
+		&totp.Confirmed,
+		&totp.LastUsedStep,
+	)
+	if err != nil {
+		return nil, errors.New("GetTOTP error in scan")
+	}
+	return totp, nil
+}
+
+// ConfirmTOTP подтверждение подключения второго фактора, step - период принятого кода
+func (s *Auth) ConfirmTOTP(ctx context.Context, userID int, step int64) error {
+	exec, err := s.Repository.Pool.Exec(
+		ctx,
+		`UPDATE user_totp SET is_confirmed = TRUE, confirmed_at = NOW(), last_used_step = $2
+			WHERE user_id = $1 AND is_confirmed = FALSE`,
+		userID,
+		step)
+	if err != nil {
+		return errors.New("ConfirmTOTP error in sql")
+	}
+	if exec.RowsAffected() != 1 {
+		return status.Error(codes.FailedPrecondition, "totp not enrolled or already enabled")
+	}
+	return nil
+}
+
+// UseTOTPStep отметка кода использованным
+// false, если код этого или более позднего периода уже принимался (повтор кода)
+func (s *Auth) UseTOTPStep(ctx context.Context, userID int, step int64) (bool, error) {
+	exec, err := s.Repository.Pool.Exec(
+		ctx,
+		`UPDATE user_totp SET last_used_step = $2
+			WHERE user_id = $1 AND is_confirmed = TRUE AND last_used_step < $2`,
+		userID,
+		step)
+	if err != nil {
+		return false, errors.New("UseTOTPStep error in sql")
+	}
+	return exec.RowsAffected() == 1, nil
+}
+
+// UseRecoveryCode погашение кода восстановления, false если кода нет или он уже использован
+func (s *Auth) UseRecoveryCode(ctx context.Context, userID int, code string) (bool, error) {
+	exec, err := s.Repository.Pool.Exec(
+		ctx,
+		`UPDATE user_recovery_code SET used_at = NOW()
+			WHERE id = (
+				SELECT id FROM user_recovery_code
+				WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+				LIMIT 1
+			)`,
+		userID,
+		[]byte(hash.GetTokenHash(code)))
+	if err != nil {
+		return false, errors.New("UseRecoveryCode error in sql")
+	}
+	return exec.RowsAffected() == 1, nil
+}
+
+// SaveLoginChallenge сохранение входа, ожидающего второй фактор
+func (s *Auth) SaveLoginChallenge(ctx context.Context, userID int, challenge string) error {
+	expiresAt := time.Now().Add(time.Duration(auth.TOTPChallengeExpiredSeconds) * time.Second)
+	exec, err := s.Repository.Pool.Exec(
+		ctx,
+		"INSERT INTO login_challenge (token_hash, user_id, expires_at) VALUES ($1, $2, $3)",
+		hash.GetTokenHash(challenge),
+		userID,
+		expiresAt)
+	if err != nil {
+		return errors.New("SaveLoginChallenge error in sql")
+	}
+	if exec.RowsAffected() != 1 {
+		return errors.New("SaveLoginChallenge expected to affect 1 row")
+	}
+	return nil
+}
+
+// UseLoginChallengeAttempt учет попытки ввода кода, возвращает пользователя входа
+// истекший, завершенный или исчерпавший попытки вход не принимается
+func (s *Auth) UseLoginChallengeAttempt(ctx context.Context, challenge string) (int, error) {
+	row := s.Repository.Pool.QueryRow(
+		ctx,
+		`UPDATE login_challenge SET attempts = attempts + 1
+			WHERE token_hash = $1 AND is_used = FALSE AND expires_at > NOW() AND attempts < $2
+			RETURNING user_id`,
+		hash.GetTokenHash(challenge),
+		auth.TOTPChallengeMaxAttempts)
+
+	var userID int
+	if err := row.Scan(&userID); err != nil {
+		return 0, status.Error(codes.NotFound, "challenge not found")
+	}
+	return userID, nil
+}
+
+// CompleteLoginChallenge завершение входа, повторно завершить вход нельзя
+func (s *Auth) CompleteLoginChallenge(ctx context.Context, challenge string) error {
+	exec, err := s.Repository.Pool.Exec(
+		ctx,
+		"UPDATE login_challenge SET is_used = TRUE WHERE token_hash = $1 AND is_used = FALSE",
+		hash.GetTokenHash(challenge))
+	if err != nil {
+		return errors.New("CompleteLoginChallenge error in sql")
+	}
+	if exec.RowsAffected() != 1 {
+		return status.Error(codes.NotFound, "challenge not found")
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgconn"
+	"github.com/pashagolub/pgxmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	"github.com/ramil063/secondgodiplom/internal/hash"
+	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
+	repositoryMock "github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository/mocks"
+)
+
+func TestAuth_SaveTOTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	totp := &auth.TOTP{
+		EncryptedSecret: []byte("secret"),
+		IV:              []byte("iv"),
+		Algorithm:       "A256GCM",
+		KeyVersion:      2,
+	}
+	codeHashes := [][]byte{[]byte(hash.GetTokenHash("code1")), []byte(hash.GetTokenHash("code2"))}
+
+	tests := []struct {
+		name     string
+		row      *mockRow
+		wantCode codes.Code
+	}{
+		{
+			name:     "saved",
+			row:      &mockRow{values: []interface{}{int64(1)}},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "already confirmed",
+			row:      &mockRow{values: []interface{}{int64(0)}},
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name:     "sql error",
+			row:      &mockRow{err: errors.New("connection refused")},
+			wantCode: codes.Unknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poolMock := repositoryMock.NewMockPooler(ctrl)
+			s := &Auth{
+				Repository: &repository.Repository{Pool: poolMock},
+			}
+
+			// Коды восстановления сохраняются только хешами
+			poolMock.EXPECT().
+				QueryRow(context.Background(), gomock.Any(), 1, totp.EncryptedSecret, totp.IV, totp.Algorithm, totp.KeyVersion, codeHashes).
+				Return(tt.row)
+
+			err := s.SaveTOTP(context.Background(), 1, totp, []string{"code1", "code2"})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+func TestAuth_GetTOTP(t *testing.T) {
+	poolMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	s := &Auth{
+		Repository: &repository.Repository{Pool: poolMock},
+	}
+	columns := []string{"encrypted_secret", "iv", "encryption_algorithm", "key_version", "is_confirmed", "last_used_step"}
+
+	poolMock.ExpectQuery("SELECT.*FROM user_totp.*").
+		WithArgs(1).
+		WillReturnRows(poolMock.NewRows(columns).AddRow([]byte("secret"), []byte("iv"), "A256GCM", 2, true, int64(100)))
+	got, err := s.GetTOTP(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, &auth.TOTP{
+		EncryptedSecret: []byte("secret"),
+		IV:              []byte("iv"),
+		Algorithm:       "A256GCM",
+		KeyVersion:      2,
+		Confirmed:       true,
+		LastUsedStep:    100,
+	}, got)
+
+	// Второй фактор не подключался
+	poolMock.ExpectQuery("SELECT.*FROM user_totp.*").
+		WithArgs(2).
+		WillReturnRows(poolMock.NewRows(columns))
+	got, err = s.GetTOTP(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	assert.NoError(t, poolMock.ExpectationsWereMet())
+}
+
+func TestAuth_UseTOTPStep(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	poolMock := repositoryMock.NewMockPooler(ctrl)
+	s := &Auth{
+		Repository: &repository.Repository{Pool: poolMock},
+	}
+
+	poolMock.EXPECT().
+		Exec(context.Background(), gomock.Any(), 1, int64(100)).
+		Return(pgconn.CommandTag("UPDATE 1"), nil)
+	used, err := s.UseTOTPStep(context.Background(), 1, 100)
+	assert.NoError(t, err)
+	assert.True(t, used)
+
+	// Код этого периода уже принимался
+	poolMock.EXPECT().
+		Exec(context.Background(), gomock.Any(), 1, int64(100)).
+		Return(pgconn.CommandTag("UPDATE 0"), nil)
+	used, err = s.UseTOTPStep(context.Background(), 1, 100)
+	assert.NoError(t, err)
+	assert.False(t, used)
+}
+
+func TestAuth_UseRecoveryCode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	poolMock := repositoryMock.NewMockPooler(ctrl)
+	s := &Auth{
+		Repository: &repository.Repository{Pool: poolMock},
+	}
+
+	poolMock.EXPECT().
+		Exec(context.Background(), gomock.Any(), 1, []byte(hash.GetTokenHash("abcdefghijklmnop"))).
+		Return(pgconn.CommandTag("UPDATE 1"), nil)
+	used, err := s.UseRecoveryCode(context.Background(), 1, "abcdefghijklmnop")
+	assert.NoError(t, err)
+	assert.True(t, used)
+
+	poolMock.EXPECT().
+		Exec(context.Background(), gomock.Any(), 1, gomock.Any()).
+		Return(nil, errors.New("connection refused"))
+	_, err = s.UseRecoveryCode(context.Background(), 1, "abcdefghijklmnop")
+	assert.Error(t, err)
+}
+
+func TestAuth_UseLoginChallengeAttempt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	poolMock := repositoryMock.NewMockPooler(ctrl)
+	s := &Auth{
+		Repository: &repository.Repository{Pool: poolMock},
+	}
+
+	poolMock.EXPECT().
+		QueryRow(context.Background(), gomock.Any(), hash.GetTokenHash("challenge"), auth.TOTPChallengeMaxAttempts).
+		Return(&mockRow{values: []interface{}{1}})
+	userID, err := s.UseLoginChallengeAttempt(context.Background(), "challenge")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, userID)
+
+	// Вход истек, завершен или попытки исчерпаны
+	poolMock.EXPECT().
+		QueryRow(context.Background(), gomock.Any(), hash.GetTokenHash("challenge"), auth.TOTPChallengeMaxAttempts).
+		Return(&mockRow{err: errors.New("no rows in result set")})
+	_, err = s.UseLoginChallengeAttempt(context.Background(), "challenge")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestAuth_CompleteLoginChallenge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	poolMock := repositoryMock.NewMockPooler(ctrl)
+	s := &Auth{
+		Repository: &repository.Repository{Pool: poolMock},
+	}
+
+	poolMock.EXPECT().
+		Exec(context.Background(), gomock.Any(), hash.GetTokenHash("challenge")).
+		Return(pgconn.CommandTag("UPDATE 1"), nil)
+	assert.NoError(t, s.CompleteLoginChallenge(context.Background(), "challenge"))
+
+	// Повторное завершение того же входа
+	poolMock.EXPECT().
+		Exec(context.Background(), gomock.Any(), hash.GetTokenHash("challenge")).
+		Return(pgconn.CommandTag("UPDATE 0"), nil)
+	err := s.CompleteLoginChallenge(context.Background(), "challenge")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -2,26 +2,35 @@ package auth
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/user"
+	"github.com/ramil063/secondgodiplom/internal/logger"
 )
 
 func (s *Auth) GetUserByLogin(ctx context.Context, login string) (*user.User, error) {
 	row := s.Repository.Pool.QueryRow(
 		ctx,
-		"SELECT id, password_hash FROM users WHERE login = $1",
+		"SELECT id, password_hash, is_active, kdf_salt, wrapped_vault_key FROM users WHERE login = $1",
 		login)
 
 	var id int
 	var passwordHash []byte
+	var isActive bool
+	var kdfSalt []byte
+	var wrappedVaultKey []byte
 
-	err := row.Scan(&id, &passwordHash)
+	err := row.Scan(&id, &passwordHash, &isActive, &kdfSalt, &wrappedVaultKey)
 	u := &user.User{
-		ID:           id,
-		PasswordHash: string(passwordHash),
+		ID:              id,
+		PasswordHash:    string(passwordHash),
+		IsActive:        isActive,
+		KdfSalt:         kdfSalt,
+		WrappedVaultKey: wrappedVaultKey,
 	}
 
 	if err != nil {
@@ -29,3 +38,59 @@ func (s *Auth) GetUserByLogin(ctx context.Context, login string) (*user.User, er
 	}
 	return u, nil
 }
+
+func (s *Auth) SaveClientKeys(ctx context.Context, userID int, kdfSalt, wrappedVaultKey []byte) error {
+	exec, err := s.Repository.Pool.Exec(
+		ctx,
+		`UPDATE users SET kdf_salt = $1, wrapped_vault_key = $2, updated_at = NOW()
+			WHERE id = $3 AND wrapped_vault_key IS NULL`,
+		kdfSalt,
+		wrappedVaultKey,
+		userID)
+
+	if err != nil {
+		return fmt.Errorf("SaveClientKeys error in sql: %w", err)
+	}
+	if exec == nil {
+		logger.WriteErrorLog("SaveClientKeys error in sql empty result")
+		return errors.New("SaveClientKeys error in sql empty result")
+	}
+
+	rows := exec.RowsAffected()
+	if rows != 1 {
+		return status.Error(codes.FailedPrecondition, "client encryption already enabled")
+	}
+	return nil
+}
+
+// RehashPassword замена хеша пароля пересчитанным после успешного входа
+// хеш обновляется, только если пароль не сменили параллельно
+func (s *Auth) RehashPassword(ctx context.Context, userID int, oldHash, newHash string) error {
+	_, err := s.Repository.Pool.Exec(
+		ctx,
+		`UPDATE users SET password_hash = $3, updated_at = NOW()
+			WHERE id = $1 AND password_hash = $2`,
+		userID,
+		oldHash,
+		newHash)
+	if err != nil {
+		logger.WriteErrorLog(err.Error())
+		return errors.New("RehashPassword error in sql")
+	}
+	return nil
+}
+
+// GetUserByID получение пользователя по идентификатору
+func (s *Auth) GetUserByID(ctx context.Context, userID int) (*user.User, error) {
+	row := s.Repository.Pool.QueryRow(
+		ctx,
+		"SELECT id, login, kdf_salt, wrapped_vault_key FROM users WHERE id = $1",
+		userID)
+
+	u := &user.User{}
+	err := row.Scan(&u.ID, &u.Login, &u.KdfSalt, &u.WrappedVaultKey)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	return u, nil
+}
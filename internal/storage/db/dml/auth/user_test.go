@@ -2,11 +2,13 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgconn"
 	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/user"
 	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
 	repositoryMock "github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository/mocks"
@@ -28,14 +30,17 @@ func TestAuth_GetUserByLogin(t *testing.T) {
 	}{
 		{
 			name:  "success",
-			query: `SELECT id, password_hash FROM users WHERE login = $1`,
+			query: `SELECT id, password_hash, is_active, kdf_salt, wrapped_vault_key FROM users WHERE login = $1`,
 			args: args{
 				ctx:   context.Background(),
 				login: "test",
 			},
 			want: &user.User{
-				ID:           1,
-				PasswordHash: "test",
+				ID:              1,
+				PasswordHash:    "test",
+				IsActive:        true,
+				KdfSalt:         []byte("salt"),
+				WrappedVaultKey: []byte("wrapped"),
 			},
 		},
 	}
@@ -56,6 +61,9 @@ func TestAuth_GetUserByLogin(t *testing.T) {
 					values: []interface{}{
 						tt.want.ID,
 						[]byte(tt.want.PasswordHash),
+						tt.want.IsActive,
+						tt.want.KdfSalt,
+						tt.want.WrappedVaultKey,
 					},
 				})
 			got, err := s.GetUserByLogin(tt.args.ctx, tt.args.login)
@@ -64,3 +72,105 @@ func TestAuth_GetUserByLogin(t *testing.T) {
 		})
 	}
 }
+
+func TestAuth_SaveClientKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type args struct {
+		ctx             context.Context
+		userID          int
+		kdfSalt         []byte
+		wrappedVaultKey []byte
+	}
+	tests := []struct {
+		name       string
+		args       args
+		commandTag pgconn.CommandTag
+		execErr    error
+		wantErr    bool
+	}{
+		{
+			name: "success",
+			args: args{
+				ctx:             context.Background(),
+				userID:          1,
+				kdfSalt:         []byte("salt"),
+				wrappedVaultKey: []byte("wrapped"),
+			},
+			commandTag: pgconn.CommandTag("UPDATE 1"),
+		},
+		{
+			// исходная ошибка бд не теряется
+			name: "sql error",
+			args: args{
+				ctx:             context.Background(),
+				userID:          1,
+				kdfSalt:         []byte("salt"),
+				wrappedVaultKey: []byte("wrapped"),
+			},
+			execErr: context.DeadlineExceeded,
+			wantErr: true,
+		},
+		{
+			name: "already enabled",
+			args: args{
+				ctx:             context.Background(),
+				userID:          1,
+				kdfSalt:         []byte("salt"),
+				wrappedVaultKey: []byte("wrapped"),
+			},
+			commandTag: pgconn.CommandTag("UPDATE 0"),
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poolMock := repositoryMock.NewMockPooler(ctrl)
+			s := &Auth{
+				Repository: &repository.Repository{Pool: poolMock},
+			}
+
+			poolMock.EXPECT().
+				Exec(
+					tt.args.ctx,
+					gomock.Any(),
+					tt.args.kdfSalt,
+					tt.args.wrappedVaultKey,
+					tt.args.userID,
+				).
+				Return(tt.commandTag, tt.execErr)
+
+			err := s.SaveClientKeys(tt.args.ctx, tt.args.userID, tt.args.kdfSalt, tt.args.wrappedVaultKey)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.execErr != nil {
+					assert.ErrorIs(t, err, tt.execErr)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAuth_RehashPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	poolMock := repositoryMock.NewMockPooler(ctrl)
+	s := &Auth{
+		Repository: &repository.Repository{Pool: poolMock},
+	}
+	ctx := context.Background()
+
+	poolMock.EXPECT().
+		Exec(ctx, gomock.Any(), 1, "$2a$14$old", "$argon2id$new").
+		Return(pgconn.CommandTag("UPDATE 1"), nil)
+	assert.NoError(t, s.RehashPassword(ctx, 1, "$2a$14$old", "$argon2id$new"))
+
+	poolMock.EXPECT().
+		Exec(ctx, gomock.Any(), 1, "$2a$14$old", "$argon2id$new").
+		Return(nil, errors.New("connection refused"))
+	assert.Error(t, s.RehashPassword(ctx, 1, "$2a$14$old", "$argon2id$new"))
+}
@@ -0,0 +1,52 @@
+package datakey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/ramil063/secondgodiplom/internal/logger"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto"
+)
+
+func (d *DataKey) GetDataKey(ctx context.Context, userID int) (*crypto.WrappedKey, error) {
+	row := d.Repository.Pool.QueryRow(
+		ctx,
+		"SELECT wrapped_key, iv, encryption_algorithm, key_version, is_aad_bound FROM user_data_key WHERE user_id = $1",
+		userID)
+
+	var key crypto.WrappedKey
+	err := row.Scan(&key.Key, &key.IV, &key.Algorithm, &key.KeyVersion, &key.AADBound)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data key: %w", err)
+	}
+	return &key, nil
+}
+
+func (d *DataKey) SaveDataKey(ctx context.Context, userID int, key *crypto.WrappedKey) error {
+	exec, err := d.Repository.Pool.Exec(
+		ctx,
+		`INSERT INTO user_data_key (user_id, wrapped_key, iv, encryption_algorithm, key_version, is_aad_bound)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (user_id) DO NOTHING`,
+		userID,
+		key.Key,
+		key.IV,
+		key.Algorithm,
+		key.KeyVersion,
+		key.AADBound)
+
+	if err != nil {
+		return fmt.Errorf("failed to save data key: %w", err)
+	}
+	if exec == nil {
+		logger.WriteErrorLog("SaveDataKey error in sql empty result")
+		return errors.New("SaveDataKey error in sql empty result")
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package datakey
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ramil063/secondgodiplom/internal/security/crypto"
+	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/mock"
+	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
+	repositoryMock "github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository/mocks"
+)
+
+func TestDataKey_GetDataKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name string
+		row  *mock.Row
+		want *crypto.WrappedKey
+	}{
+		{
+			name: "key exists",
+			row: &mock.Row{
+				Values: []interface{}{[]byte("key"), []byte("iv"), "AES-256-GCM", 2, true},
+			},
+			want: &crypto.WrappedKey{
+				Key:        []byte("key"),
+				IV:         []byte("iv"),
+				Algorithm:  "AES-256-GCM",
+				KeyVersion: 2,
+				AADBound:   true,
+			},
+		},
+		{
+			name: "no key",
+			row:  &mock.Row{Err: pgx.ErrNoRows},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poolMock := repositoryMock.NewMockPooler(ctrl)
+			d := &DataKey{
+				Repository: &repository.Repository{Pool: poolMock},
+			}
+
+			poolMock.EXPECT().
+				QueryRow(context.Background(), gomock.Any(), 1).
+				Return(tt.row)
+
+			got, err := d.GetDataKey(context.Background(), 1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDataKey_SaveDataKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	poolMock := repositoryMock.NewMockPooler(ctrl)
+	d := &DataKey{
+		Repository: &repository.Repository{Pool: poolMock},
+	}
+	key := &crypto.WrappedKey{
+		Key:        []byte("key"),
+		IV:         []byte("iv"),
+		Algorithm:  "AES-256-GCM",
+		KeyVersion: 1,
+		AADBound:   true,
+	}
+
+	poolMock.EXPECT().
+		Exec(context.Background(), gomock.Any(), 1, key.Key, key.IV, key.Algorithm, key.KeyVersion, key.AADBound).
+		Return(pgconn.CommandTag("INSERT 0 1"), nil)
+
+	err := d.SaveDataKey(context.Background(), 1, key)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,7 @@
+package datakey
+
+import "github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
+
+type DataKey struct {
+	Repository *repository.Repository
+}
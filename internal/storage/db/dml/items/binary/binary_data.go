@@ -6,6 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
 
 	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/items"
 	"github.com/ramil063/secondgodiplom/internal/logger"
@@ -17,19 +20,63 @@ type Item struct {
 	Repository *repository.Repository
 }
 
+// WithTx выполнение fn в транзакции, методы хранилища с контекстом fn работают в ней
+// используется для атомарного сохранения записи о файле и его частей
+func (i *Item) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return i.Repository.WithTx(ctx, fn)
+}
+
+// ReserveFileID резервирование идентификатора нового файла
+// идентификатор нужен до сохранения, так как входит в связанные данные зашифрованных полей
+func (i *Item) ReserveFileID(ctx context.Context) (int64, error) {
+	row := i.Repository.Conn(ctx).QueryRow(
+		ctx,
+		`SELECT nextval(pg_get_serial_sequence('binary_file', 'id'))`)
+
+	var fileID int64
+	if err := row.Scan(&fileID); err != nil {
+		return 0, fmt.Errorf("failed to reserve file id: %w", err)
+	}
+	return fileID, nil
+}
+
+// ReserveClientFileID резервирование идентификатора файла, части которого зашифрованы на клиенте
+// клиент привязывает части к идентификатору до загрузки файла
+func (i *Item) ReserveClientFileID(ctx context.Context, userID int64) (int64, error) {
+	fileID, err := i.ReserveFileID(ctx)
+	if err != nil {
+		return 0, err
+	}
+	err = i.Repository.SaveReservedID(ctx, items.ReservedKindFile, fileID, userID, time.Now().Add(items.ReservedIDTTL))
+	if err != nil {
+		return 0, err
+	}
+	return fileID, nil
+}
+
+// ClaimFileID использование идентификатора, зарезервированного пользователем userID
+// идентификатор используется один раз, чужой, просроченный или незарезервированный не находится (codes.NotFound)
+func (i *Item) ClaimFileID(ctx context.Context, userID int64, fileID int64) error {
+	return i.Repository.ClaimReservedID(ctx, items.ReservedKindFile, fileID, userID)
+}
+
+// CreateFileRecord создание записи о файле с зарезервированным идентификатором reservedID
+// если идентификатор не зарезервирован (0), он выдается базой
 func (i *Item) CreateFileRecord(
 	ctx context.Context,
 	userID int,
+	reservedID int64,
 	metadata *binarydata.FileMetadata,
 ) (int64, error) {
 	var fileID int64
 
-	err := i.Repository.Pool.QueryRow(ctx, `
+	err := i.Repository.Conn(ctx).QueryRow(ctx, `
         INSERT INTO binary_file (
-            user_id, filename, mime_type, original_size, 
-            description, chunk_size, total_chunks
-        ) VALUES ($1, $2, $3, $4, $5, $6, $7)
+            id, user_id, filename, mime_type, original_size, 
+            description, chunk_size, total_chunks, client_encrypted
+        ) VALUES (COALESCE(NULLIF($1, 0), nextval(pg_get_serial_sequence('binary_file', 'id'))), $2, $3, $4, $5, $6, $7, $8, $9)
         RETURNING id`,
+		reservedID,
 		userID,
 		metadata.Filename,
 		metadata.MimeType,
@@ -37,6 +84,7 @@ func (i *Item) CreateFileRecord(
 		metadata.Description,
 		metadata.ChunkSize,
 		metadata.TotalChunks,
+		metadata.ClientEncrypted,
 	).Scan(&fileID)
 
 	return fileID, err
@@ -49,16 +97,22 @@ func (i *Item) SaveChunk(
 	encryptedData []byte,
 	algorithm string,
 	iv []byte,
+	keyVersion int,
+	aadBound bool,
+	streamBound bool,
 ) error {
-	result, err := i.Repository.Pool.Exec(
+	result, err := i.Repository.Conn(ctx).Exec(
 		ctx,
-		`INSERT INTO binary_file_chunk (file_id, chunk_index, encrypted_data, encryption_algorithm, iv)
-        VALUES ($1, $2, $3, $4, $5)`,
+		`INSERT INTO binary_file_chunk (file_id, chunk_index, encrypted_data, encryption_algorithm, iv, key_version, is_aad_bound, is_stream_bound)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
 		fileID,
 		chunkIndex,
 		encryptedData,
 		algorithm,
 		iv,
+		keyVersion,
+		aadBound,
+		streamBound,
 	)
 
 	if err != nil {
@@ -76,7 +130,7 @@ func (i *Item) SaveChunk(
 func (i *Item) MarkFileComplete(ctx context.Context, fileID int64, totalBytes int64) error {
 
 	// Выполняем запрос на обновление
-	result, err := i.Repository.Pool.Exec(ctx, `
+	result, err := i.Repository.Conn(ctx).Exec(ctx, `
         UPDATE binary_file 
         SET 
             is_complete = TRUE,
@@ -111,6 +165,7 @@ func (i *Item) GetFileInfo(ctx context.Context, fileID int64, userID int64) (*it
 				bf.chunk_size,
 				bf.total_chunks,
 				bf.created_at,
+				bf.client_encrypted,
 				COALESCE(
 					json_agg(
 						json_build_object(
@@ -131,7 +186,7 @@ func (i *Item) GetFileInfo(ctx context.Context, fileID int64, userID int64) (*it
 			GROUP BY bf.id
 			`
 
-	row := i.Repository.Pool.QueryRow(ctx, query, fileID, userID)
+	row := i.Repository.Conn(ctx).QueryRow(ctx, query, fileID, userID)
 	var fileInfo items.FileInfo
 	var metadataJSON []byte
 
@@ -144,6 +199,7 @@ func (i *Item) GetFileInfo(ctx context.Context, fileID int64, userID int64) (*it
 		&fileInfo.ChunkSize,
 		&fileInfo.TotalChunks,
 		&fileInfo.CreatedAt,
+		&fileInfo.ClientEncrypted,
 		&metadataJSON,
 	)
 	if err != nil {
@@ -159,8 +215,8 @@ func (i *Item) GetFileInfo(ctx context.Context, fileID int64, userID int64) (*it
 func (r *Item) GetChunksInRange(ctx context.Context, fileID int64, start, end int32) ([]*items.ChunkData, error) {
 	var chunks []*items.ChunkData
 
-	rows, err := r.Repository.Pool.Query(ctx, `
-        SELECT chunk_index, encrypted_data, encryption_algorithm, iv 
+	rows, err := r.Repository.Conn(ctx).Query(ctx, `
+        SELECT chunk_index, encrypted_data, encryption_algorithm, iv, key_version, is_aad_bound, is_stream_bound
         FROM binary_file_chunk
         WHERE file_id = $1 AND chunk_index BETWEEN $2 AND $3
         ORDER BY chunk_index`,
@@ -173,7 +229,7 @@ func (r *Item) GetChunksInRange(ctx context.Context, fileID int64, start, end in
 
 	for rows.Next() {
 		var chunk items.ChunkData
-		err = rows.Scan(&chunk.ChunkIndex, &chunk.EncryptedData, &chunk.EncryptionAlgorithm, &chunk.IV)
+		err = rows.Scan(&chunk.ChunkIndex, &chunk.EncryptedData, &chunk.EncryptionAlgorithm, &chunk.IV, &chunk.KeyVersion, &chunk.AADBound, &chunk.StreamBound)
 		if err != nil {
 			return nil, err
 		}
@@ -184,7 +240,7 @@ func (r *Item) GetChunksInRange(ctx context.Context, fileID int64, start, end in
 }
 
 func (i *Item) DeleteFile(ctx context.Context, userID, fileID int64) error {
-	exec, err := i.Repository.Pool.Exec(
+	exec, err := i.Repository.Conn(ctx).Exec(
 		ctx,
 		`UPDATE binary_file SET is_deleted=TRUE WHERE id = $1 AND user_id = $2`,
 		fileID,
@@ -211,13 +267,6 @@ func (i *Item) GetListFiles(ctx context.Context, userID int64, page int32, perPa
 	args := []interface{}{userID}
 	paramCounter := 2
 
-	mainSelectFields := `bf.id,
-            bf.filename,
-            bf.mime_type,
-			bf.original_size,
-            bf.description,
-            bf.created_at`
-
 	countSelectFields := `COUNT(*)`
 
 	// Базовый запрос
@@ -253,14 +302,59 @@ func (i *Item) GetListFiles(ctx context.Context, userID int64, page int32, perPa
 
 	args = append(args, perPage, offset)
 
-	commonQuery = strings.Replace(commonQuery, "{{select}}", mainSelectFields, 1)
+	commonQuery = strings.Replace(commonQuery, "{{select}}", listFileFields, 1)
 	commonQuery += limitOffset
 
 	// Выполняем запрос
-	rows, err := i.Repository.Pool.Query(ctx, commonQuery, args...)
+	rows, err := i.Repository.Conn(ctx).Query(ctx, commonQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to query items: %w", err)
 	}
+	filesInfo, err := scanListFiles(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Получаем общее количество
+	totalCount, err := i.GetTotalCount(ctx, countCommonQuery, userID, filter)
+	if err != nil {
+		logger.WriteErrorLog("GetTotalCount error: " + err.Error())
+		totalCount = 0
+	}
+
+	return filesInfo, totalCount, nil
+}
+
+// GetListFilesAfter файлы пользователя с идентификатором больше afterID, по возрастанию идентификатора
+// используется для чтения всех файлов пачками: добавленные и удаленные файлы не сдвигают следующие пачки
+func (i *Item) GetListFilesAfter(ctx context.Context, userID int64, afterID int64, limit int32) ([]*items.FileInfo, error) {
+	rows, err := i.Repository.Conn(ctx).Query(
+		ctx,
+		`SELECT `+listFileFields+`
+			FROM binary_file bf
+			WHERE bf.user_id = $1 AND bf.is_deleted = FALSE AND bf.id > $2
+			ORDER BY bf.id
+			LIMIT $3`,
+		userID,
+		afterID,
+		limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query items: %w", err)
+	}
+	return scanListFiles(rows)
+}
+
+// listFileFields поля файла списка в порядке scanListFiles
+const listFileFields = `bf.id,
+            bf.filename,
+            bf.mime_type,
+			bf.original_size,
+            bf.description,
+            bf.created_at,
+            bf.client_encrypted`
+
+// scanListFiles чтение файлов списка, rows закрывается
+func scanListFiles(rows pgx.Rows) ([]*items.FileInfo, error) {
 	defer rows.Close()
 
 	var filesInfo []*items.FileInfo
@@ -274,22 +368,15 @@ func (i *Item) GetListFiles(ctx context.Context, userID int64, page int32, perPa
 			&fi.OriginalSize,
 			&fi.Description,
 			&fi.CreatedAt,
+			&fi.ClientEncrypted,
 		)
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan items: %w", err)
+			return nil, fmt.Errorf("failed to scan items: %w", err)
 		}
 
 		filesInfo = append(filesInfo, &fi)
 	}
-
-	// Получаем общее количество
-	totalCount, err := i.GetTotalCount(ctx, countCommonQuery, userID, filter)
-	if err != nil {
-		logger.WriteErrorLog("GetTotalCount error: " + err.Error())
-		totalCount = 0
-	}
-
-	return filesInfo, totalCount, nil
+	return filesInfo, nil
 }
 
 func (i *Item) GetTotalCount(ctx context.Context, query string, userID int64, filter string) (int32, error) {
@@ -303,7 +390,7 @@ func (i *Item) GetTotalCount(ctx context.Context, query string, userID int64, fi
 	}
 	query = strings.Replace(query, "{{filterConditions}}", filterCondition, 1)
 
-	err := i.Repository.Pool.QueryRow(ctx, query, args...).Scan(&totalCount)
+	err := i.Repository.Conn(ctx).QueryRow(ctx, query, args...).Scan(&totalCount)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count items: %w", err)
 	}
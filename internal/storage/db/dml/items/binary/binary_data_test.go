@@ -3,6 +3,7 @@ package binary
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"reflect"
 	"testing"
 	"time"
@@ -11,6 +12,7 @@ import (
 	"github.com/jackc/pgconn"
 	"github.com/pashagolub/pgxmock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/items"
 	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/binarydata"
@@ -52,6 +54,24 @@ func TestItem_CreateFileRecord(t *testing.T) {
 			fileID: 1,
 			want:   1,
 		},
+		{
+			name: "client encrypted",
+			args: args{
+				ctx:    context.Background(),
+				userID: 1,
+				metadata: &binarydata.FileMetadata{
+					Filename:        "filename",
+					MimeType:        "mime_type",
+					OriginalSize:    1,
+					Description:     "description",
+					ChunkSize:       1,
+					TotalChunks:     1,
+					ClientEncrypted: true,
+				},
+			},
+			fileID: 1,
+			want:   1,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -64,6 +84,7 @@ func TestItem_CreateFileRecord(t *testing.T) {
 				QueryRow(
 					tt.args.ctx,
 					gomock.Any(),
+					tt.fileID,
 					tt.args.userID,
 					tt.args.metadata.Filename,
 					tt.args.metadata.MimeType,
@@ -71,6 +92,7 @@ func TestItem_CreateFileRecord(t *testing.T) {
 					tt.args.metadata.Description,
 					tt.args.metadata.ChunkSize,
 					tt.args.metadata.TotalChunks,
+					tt.args.metadata.ClientEncrypted,
 				).
 				Return(&mock.Row{
 					Values: []interface{}{
@@ -78,7 +100,7 @@ func TestItem_CreateFileRecord(t *testing.T) {
 					},
 				})
 
-			got, err := i.CreateFileRecord(tt.args.ctx, tt.args.userID, tt.args.metadata)
+			got, err := i.CreateFileRecord(tt.args.ctx, tt.args.userID, tt.fileID, tt.args.metadata)
 			assert.NoError(t, err)
 			if got != tt.want {
 				t.Errorf("CreateFileRecord() got = %v, want %v", got, tt.want)
@@ -87,6 +109,49 @@ func TestItem_CreateFileRecord(t *testing.T) {
 	}
 }
 
+func TestItem_ReserveFileID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name    string
+		row     *mock.Row
+		want    int64
+		wantErr bool
+	}{
+		{
+			name: "reserved",
+			row:  &mock.Row{Values: []interface{}{int64(5)}},
+			want: 5,
+		},
+		{
+			name:    "error",
+			row:     &mock.Row{Err: errors.New("db error")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poolMock := repositoryMock.NewMockPooler(ctrl)
+			i := &Item{
+				Repository: &repository.Repository{Pool: poolMock},
+			}
+
+			poolMock.EXPECT().
+				QueryRow(context.Background(), gomock.Any()).
+				Return(tt.row)
+
+			got, err := i.ReserveFileID(context.Background())
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestItem_DeleteFile(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -158,6 +223,9 @@ func TestItem_GetChunksInRange(t *testing.T) {
 					EncryptedData:       []byte("test"),
 					EncryptionAlgorithm: "AES-256-GCM",
 					IV:                  []byte("test"),
+					KeyVersion:          1,
+					AADBound:            true,
+					StreamBound:         true,
 				},
 			},
 		},
@@ -174,11 +242,17 @@ func TestItem_GetChunksInRange(t *testing.T) {
 				"encrypted_data",
 				"encryption_algorithm",
 				"iv",
+				"key_version",
+				"is_aad_bound",
+				"is_stream_bound",
 			}).AddRow(
 				tt.want[0].ChunkIndex,
 				tt.want[0].EncryptedData,
 				tt.want[0].EncryptionAlgorithm,
 				tt.want[0].IV,
+				tt.want[0].KeyVersion,
+				tt.want[0].AADBound,
+				tt.want[0].StreamBound,
 			)
 
 			mock.ExpectQuery("SELECT.*chunk_index.*encrypted_data").
@@ -249,6 +323,7 @@ func TestItem_GetFileInfo(t *testing.T) {
 						tt.want.ChunkSize,
 						tt.want.TotalChunks,
 						tt.want.CreatedAt,
+						tt.want.ClientEncrypted,
 						tt.metaDataJSON,
 					},
 				})
@@ -312,6 +387,7 @@ func TestItem_GetListFiles(t *testing.T) {
 				"original_size",
 				"description",
 				"created_at",
+				"client_encrypted",
 			}).AddRow(
 				tt.want[0].ID,
 				tt.want[0].Filename,
@@ -319,6 +395,7 @@ func TestItem_GetListFiles(t *testing.T) {
 				tt.want[0].OriginalSize,
 				tt.want[0].Description,
 				tt.want[0].CreatedAt,
+				tt.want[0].ClientEncrypted,
 			)
 
 			rows1 := poolMock.NewRows([]string{
@@ -346,6 +423,33 @@ func TestItem_GetListFiles(t *testing.T) {
 	}
 }
 
+func TestItem_GetListFilesAfter(t *testing.T) {
+	poolMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	i := &Item{
+		Repository: &repository.Repository{Pool: poolMock},
+	}
+
+	rows := poolMock.NewRows([]string{
+		"id", "filename", "mime_type", "original_size", "description", "created_at", "client_encrypted",
+	}).AddRow(int64(7), "test1.txt", "text/plain", int64(0), "", time.Now(), false)
+	poolMock.ExpectQuery("SELECT.*bf.id.*FROM binary_file bf.*bf.id > \\$2.*ORDER BY bf.id.*LIMIT \\$3").
+		WithArgs(int64(1), int64(5), int32(100)).
+		WillReturnRows(rows)
+
+	got, err := i.GetListFilesAfter(context.Background(), 1, 5, 100)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "test1.txt", got[0].Filename)
+
+	poolMock.ExpectQuery("SELECT.*FROM binary_file").
+		WithArgs(int64(1), int64(7), int32(100)).
+		WillReturnError(errors.New("connection refused"))
+	_, err = i.GetListFilesAfter(context.Background(), 1, 7, 100)
+	assert.Error(t, err)
+	assert.NoError(t, poolMock.ExpectationsWereMet())
+}
+
 func TestItem_GetTotalCount(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -457,6 +561,9 @@ func TestItem_SaveChunk(t *testing.T) {
 		encryptedData []byte
 		algorithm     string
 		iv            []byte
+		keyVersion    int
+		aadBound      bool
+		streamBound   bool
 	}
 	tests := []struct {
 		name   string
@@ -472,6 +579,9 @@ func TestItem_SaveChunk(t *testing.T) {
 				encryptedData: []byte("test"),
 				algorithm:     "AES-256-GCM",
 				iv:            nil,
+				keyVersion:    1,
+				aadBound:      true,
+				streamBound:   true,
 			},
 		},
 	}
@@ -491,9 +601,12 @@ func TestItem_SaveChunk(t *testing.T) {
 					tt.args.chunkIndex,
 					tt.args.encryptedData,
 					tt.args.algorithm,
-					tt.args.iv).
+					tt.args.iv,
+					tt.args.keyVersion,
+					tt.args.aadBound,
+					tt.args.streamBound).
 				Return(expectedCommandTag, nil)
-			err := i.SaveChunk(tt.args.ctx, tt.args.fileID, tt.args.chunkIndex, tt.args.encryptedData, tt.args.algorithm, tt.args.iv)
+			err := i.SaveChunk(tt.args.ctx, tt.args.fileID, tt.args.chunkIndex, tt.args.encryptedData, tt.args.algorithm, tt.args.iv, tt.args.keyVersion, tt.args.aadBound, tt.args.streamBound)
 			assert.NoError(t, err)
 		})
 	}
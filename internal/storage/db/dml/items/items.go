@@ -7,6 +7,11 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	itemModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/items"
 	"github.com/ramil063/secondgodiplom/internal/logger"
@@ -17,12 +22,55 @@ type Item struct {
 	Repository *repository.Repository
 }
 
+// errItemNotFound запись не найдена, удалена, принадлежит другому пользователю или имеет другой тип
+var errItemNotFound = status.Error(codes.NotFound, "item not found")
+
+// WithTx выполнение fn в транзакции, методы хранилища с контекстом fn работают в ней
+// используется для атомарного сохранения записи и ее метаданных
+func (pi *Item) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return pi.Repository.WithTx(ctx, fn)
+}
+
+// ReserveItemID резервирование идентификатора новой записи
+// идентификатор нужен до сохранения, так как входит в связанные данные шифротекста
+func (pi *Item) ReserveItemID(ctx context.Context) (int64, error) {
+	row := pi.Repository.Conn(ctx).QueryRow(
+		ctx,
+		`SELECT nextval(pg_get_serial_sequence('encrypted_item', 'id'))`)
+
+	var itemID int64
+	if err := row.Scan(&itemID); err != nil {
+		return 0, fmt.Errorf("failed to reserve item id: %w", err)
+	}
+	return itemID, nil
+}
+
+// ReserveClientItemID резервирование идентификатора записи для данных, зашифрованных на клиенте
+// клиент привязывает шифротекст к идентификатору до создания записи
+func (pi *Item) ReserveClientItemID(ctx context.Context, userID int64) (int64, error) {
+	itemID, err := pi.ReserveItemID(ctx)
+	if err != nil {
+		return 0, err
+	}
+	err = pi.Repository.SaveReservedID(ctx, itemModel.ReservedKindItem, itemID, userID, time.Now().Add(itemModel.ReservedIDTTL))
+	if err != nil {
+		return 0, err
+	}
+	return itemID, nil
+}
+
+// ClaimItemID использование идентификатора, зарезервированного пользователем userID
+// идентификатор используется один раз, чужой, просроченный или незарезервированный не находится (codes.NotFound)
+func (pi *Item) ClaimItemID(ctx context.Context, userID int64, itemID int64) error {
+	return pi.Repository.ClaimReservedID(ctx, itemModel.ReservedKindItem, itemID, userID)
+}
+
 func (pi *Item) SaveEncryptedData(
 	ctx context.Context,
 	encryptedItem *itemModel.EncryptedItem,
 ) (int64, error) {
 
-	typeRow := pi.Repository.Pool.QueryRow(
+	typeRow := pi.Repository.Conn(ctx).QueryRow(
 		ctx,
 		`SELECT id FROM item_type WHERE alias = $1`,
 		encryptedItem.Type)
@@ -32,16 +80,20 @@ func (pi *Item) SaveEncryptedData(
 		return 0, err
 	}
 
-	row := pi.Repository.Pool.QueryRow(
+	row := pi.Repository.Conn(ctx).QueryRow(
 		ctx,
-		`INSERT INTO encrypted_item (encrypted_data, description, user_id, item_type_id, encryption_algorithm, iv)
-				VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+		`INSERT INTO encrypted_item (id, encrypted_data, description, user_id, item_type_id, encryption_algorithm, iv, key_version, is_aad_bound)
+				VALUES (COALESCE(NULLIF($1, 0), nextval(pg_get_serial_sequence('encrypted_item', 'id'))), $2, $3, $4, $5, $6, $7, $8, $9)
+				RETURNING id`,
+		encryptedItem.ID,
 		encryptedItem.Data,
 		encryptedItem.Description,
 		encryptedItem.UserID,
 		typeId,
 		encryptedItem.EncryptionAlgorithm,
-		encryptedItem.Iv)
+		encryptedItem.Iv,
+		encryptedItem.KeyVersion,
+		encryptedItem.AADBound)
 
 	var itemId int64
 	err := row.Scan(&itemId)
@@ -53,13 +105,20 @@ func (pi *Item) SaveEncryptedData(
 	return itemId, nil
 }
 
-func (pi *Item) SaveMetadata(ctx context.Context, metadata *itemModel.MetaData) error {
-	exec, err := pi.Repository.Pool.Exec(
+// SaveMetadata сохранение метаданных записи пользователя с типом itemType
+func (pi *Item) SaveMetadata(ctx context.Context, userID int64, itemType string, metadata *itemModel.MetaData) error {
+	exec, err := pi.Repository.Conn(ctx).Exec(
 		ctx,
-		`INSERT INTO item_metadata (item_id, name, value) VALUES ($1, $2, $3)`,
+		`INSERT INTO item_metadata (item_id, name, value)
+				SELECT ei.id, $2, $3
+				FROM encrypted_item ei
+				JOIN item_type it ON ei.item_type_id = it.id
+				WHERE ei.id = $1 AND ei.user_id = $4 AND it.alias = $5 AND ei.is_deleted = FALSE`,
 		metadata.ItemID,
 		metadata.Name,
-		metadata.Value)
+		metadata.Value,
+		userID,
+		itemType)
 
 	if err != nil {
 		return err
@@ -71,8 +130,7 @@ func (pi *Item) SaveMetadata(ctx context.Context, metadata *itemModel.MetaData)
 
 	rows := exec.RowsAffected()
 	if rows != 1 {
-		logger.WriteErrorLog("save metadata error expected to affect 1 row")
-		return errors.New("expected to affect 1 row")
+		return errItemNotFound
 	}
 	return nil
 }
@@ -90,25 +148,6 @@ func (pi *Item) GetListItems(
 	args = append(args, itemType)
 	paramCounter := 3
 
-	mainSelectFields := `ei.id,
-            ei.encrypted_data,
-            ei.description,
-            ei.created_at,
-            ei.encryption_algorithm,
-            ei.iv,
-            COALESCE(
-                json_agg(
-                    json_build_object(
-                        'id', im.id,
-                        'name', im.name,
-                        'value', im.value,
-                        'created_at', im.created_at
-                    ) 
-                    ORDER BY im.created_at
-                ) FILTER (WHERE im.id IS NOT NULL),
-                '[]'
-            ) as metadata`
-
 	countSelectFields := `COUNT(*)`
 
 	// Базовый запрос
@@ -143,14 +182,82 @@ func (pi *Item) GetListItems(
 
 	args = append(args, perPage, offset)
 
-	commonQuery = strings.Replace(commonQuery, "{{select}}", mainSelectFields, 1)
+	commonQuery = strings.Replace(commonQuery, "{{select}}", listSelectFields, 1)
 	commonQuery += limitOffset
 
 	// Выполняем запрос
-	rows, err := pi.Repository.Pool.Query(ctx, commonQuery, args...)
+	rows, err := pi.Repository.Conn(ctx).Query(ctx, commonQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to query items: %w", err)
 	}
+	items, err := scanListItems(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Получаем общее количество
+	totalCount, err := pi.GetTotalCount(ctx, countCommonQuery, userID, itemType, filter)
+	if err != nil {
+		logger.WriteErrorLog("GetTotalCount error: " + err.Error())
+		totalCount = 0
+	}
+
+	return items, totalCount, nil
+}
+
+// GetListItemsAfter записи пользователя с типом itemType и идентификатором больше afterID, по возрастанию идентификатора
+// используется для чтения всех записей пачками: добавленные и удаленные записи не сдвигают следующие пачки
+func (pi *Item) GetListItemsAfter(
+	ctx context.Context,
+	userID int64,
+	itemType string,
+	afterID int64,
+	limit int32,
+) ([]*itemModel.ItemData, error) {
+	rows, err := pi.Repository.Conn(ctx).Query(
+		ctx,
+		`SELECT `+listSelectFields+`
+			FROM encrypted_item ei
+			LEFT JOIN item_metadata im ON ei.id = im.item_id
+			LEFT JOIN item_type it ON ei.item_type_id = it.id
+			WHERE ei.user_id = $1 AND ei.is_deleted = FALSE AND it.alias = $2 AND ei.id > $3
+			GROUP BY ei.id
+			ORDER BY ei.id
+			LIMIT $4`,
+		userID,
+		itemType,
+		afterID,
+		limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query items: %w", err)
+	}
+	return scanListItems(rows)
+}
+
+// listSelectFields поля записи списка вместе с метаданными, в порядке scanListItems
+const listSelectFields = `ei.id,
+            ei.encrypted_data,
+            ei.description,
+            ei.created_at,
+            ei.encryption_algorithm,
+            ei.iv,
+            ei.key_version,
+            ei.is_aad_bound,
+            COALESCE(
+                json_agg(
+                    json_build_object(
+                        'id', im.id,
+                        'name', im.name,
+                        'value', im.value,
+                        'created_at', im.created_at
+                    ) 
+                    ORDER BY im.created_at
+                ) FILTER (WHERE im.id IS NOT NULL),
+                '[]'
+            ) as metadata`
+
+// scanListItems чтение записей списка, rows закрывается
+func scanListItems(rows pgx.Rows) ([]*itemModel.ItemData, error) {
 	defer rows.Close()
 
 	var items []*itemModel.ItemData
@@ -158,35 +265,29 @@ func (pi *Item) GetListItems(
 		var pwd itemModel.ItemData
 		var metadataJSON []byte
 
-		err = rows.Scan(
+		err := rows.Scan(
 			&pwd.ID,
 			&pwd.Data,
 			&pwd.Description,
 			&pwd.CreatedAt,
 			&pwd.EncryptionAlgorithm,
 			&pwd.IV,
+			&pwd.KeyVersion,
+			&pwd.AADBound,
 			&metadataJSON,
 		)
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan items: %w", err)
+			return nil, fmt.Errorf("failed to scan items: %w", err)
 		}
 
 		// Парсим JSON с метаданными
 		if err = json.Unmarshal(metadataJSON, &pwd.MetaDataItems); err != nil {
-			return nil, 0, fmt.Errorf("failed to parse metadata: %w", err)
+			return nil, fmt.Errorf("failed to parse metadata: %w", err)
 		}
 
 		items = append(items, &pwd)
 	}
-
-	// Получаем общее количество
-	totalCount, err := pi.GetTotalCount(ctx, countCommonQuery, userID, itemType, filter)
-	if err != nil {
-		logger.WriteErrorLog("GetTotalCount error: " + err.Error())
-		totalCount = 0
-	}
-
-	return items, totalCount, nil
+	return items, nil
 }
 
 func (pi *Item) GetTotalCount(ctx context.Context, query string, userID int64, itemType, filter string) (int32, error) {
@@ -201,14 +302,15 @@ func (pi *Item) GetTotalCount(ctx context.Context, query string, userID int64, i
 	}
 	query = strings.Replace(query, "{{filterConditions}}", filterCondition, 1)
 
-	err := pi.Repository.Pool.QueryRow(ctx, query, args...).Scan(&totalCount)
+	err := pi.Repository.Conn(ctx).QueryRow(ctx, query, args...).Scan(&totalCount)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count items: %w", err)
 	}
 	return totalCount, nil
 }
 
-func (pi *Item) GetItem(ctx context.Context, itemID int64) (*itemModel.ItemData, error) {
+// GetItem запись пользователя с типом itemType
+func (pi *Item) GetItem(ctx context.Context, userID int64, itemType string, itemID int64) (*itemModel.ItemData, error) {
 
 	query := `SELECT
 				ei.id,
@@ -217,6 +319,8 @@ func (pi *Item) GetItem(ctx context.Context, itemID int64) (*itemModel.ItemData,
 				ei.created_at,
 				ei.encryption_algorithm,
 				ei.iv,
+				ei.key_version,
+				ei.is_aad_bound,
 				COALESCE(
 					json_agg(
 						json_build_object(
@@ -230,12 +334,13 @@ func (pi *Item) GetItem(ctx context.Context, itemID int64) (*itemModel.ItemData,
 					'[]'
 				) as metadata
 			FROM encrypted_item ei
+			JOIN item_type it ON ei.item_type_id = it.id
 			LEFT JOIN public.item_metadata im on ei.id = im.item_id
-			WHERE ei.is_deleted = FALSE AND ei.id = $1
+			WHERE ei.is_deleted = FALSE AND ei.id = $1 AND ei.user_id = $2 AND it.alias = $3
 			GROUP BY ei.id
 			`
 
-	row := pi.Repository.Pool.QueryRow(ctx, query, itemID)
+	row := pi.Repository.Conn(ctx).QueryRow(ctx, query, itemID, userID, itemType)
 	var pwd itemModel.ItemData
 	var metadataJSON []byte
 
@@ -246,10 +351,15 @@ func (pi *Item) GetItem(ctx context.Context, itemID int64) (*itemModel.ItemData,
 		&pwd.CreatedAt,
 		&pwd.EncryptionAlgorithm,
 		&pwd.IV,
+		&pwd.KeyVersion,
+		&pwd.AADBound,
 		&metadataJSON,
 	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errItemNotFound
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to count items: %w", err)
+		return nil, fmt.Errorf("failed to get item: %w", err)
 	}
 	// Парсим JSON с метаданными
 	if err := json.Unmarshal(metadataJSON, &pwd.MetaDataItems); err != nil {
@@ -262,15 +372,22 @@ func (pi *Item) GetItem(ctx context.Context, itemID int64) (*itemModel.ItemData,
 		CreatedAt:           pwd.CreatedAt,
 		EncryptionAlgorithm: pwd.EncryptionAlgorithm,
 		IV:                  pwd.IV,
+		KeyVersion:          pwd.KeyVersion,
+		AADBound:            pwd.AADBound,
 		MetaDataItems:       pwd.MetaDataItems,
 	}, nil
 }
 
-func (pi *Item) DeleteItem(ctx context.Context, itemID int64) error {
-	exec, err := pi.Repository.Pool.Exec(
+// DeleteItem мягкое удаление записи пользователя с типом itemType
+func (pi *Item) DeleteItem(ctx context.Context, userID int64, itemType string, itemID int64) error {
+	exec, err := pi.Repository.Conn(ctx).Exec(
 		ctx,
-		`UPDATE encrypted_item SET is_deleted=TRUE WHERE id = $1`,
-		itemID)
+		`UPDATE encrypted_item SET is_deleted=TRUE
+				WHERE id = $1 AND user_id = $2 AND is_deleted = FALSE
+					AND item_type_id = (SELECT id FROM item_type WHERE alias = $3)`,
+		itemID,
+		userID,
+		itemType)
 
 	if err != nil {
 		return errors.New("DeleteItem error in sql empty result")
@@ -282,26 +399,32 @@ func (pi *Item) DeleteItem(ctx context.Context, itemID int64) error {
 
 	rows := exec.RowsAffected()
 	if rows != 1 {
-		logger.WriteErrorLog("DeleteItem error expected to affect 1 row")
-		return errors.New("DeleteItem expected to affect 1 row")
+		return errItemNotFound
 	}
 	return nil
 }
 
+// UpdateItem обновление записи пользователя с типом itemType
 func (pi *Item) UpdateItem(
 	ctx context.Context,
+	userID int64,
+	itemType string,
 	itemId int64,
 	encryptedItem *itemModel.EncryptedItem,
 ) (int64, error) {
 
 	setQuery := ``
 	args := make([]interface{}, 0)
-	args = append(args, itemId)
+	args = append(args, itemId, userID, itemType)
 
 	if len(encryptedItem.Data) > 0 {
 		num := len(args) + 1
 		setQuery += `encrypted_data=$` + strconv.Itoa(num) + `,`
 		args = append(args, encryptedItem.Data)
+		// Признак привязки меняется только вместе с шифротекстом
+		num = len(args) + 1
+		setQuery += `is_aad_bound=$` + strconv.Itoa(num) + `,`
+		args = append(args, encryptedItem.AADBound)
 	}
 	if encryptedItem.Description != "" {
 		num := len(args) + 1
@@ -318,51 +441,67 @@ func (pi *Item) UpdateItem(
 		setQuery += `iv=$` + strconv.Itoa(num) + `,`
 		args = append(args, encryptedItem.Iv)
 	}
+	if encryptedItem.KeyVersion > 0 {
+		num := len(args) + 1
+		setQuery += `key_version=$` + strconv.Itoa(num) + `,`
+		args = append(args, encryptedItem.KeyVersion)
+	}
 	setQuery = strings.Trim(setQuery, ",")
 
 	if setQuery == "" {
 		return 0, errors.New("UpdateItem empty data in update")
 	}
 
-	row := pi.Repository.Pool.QueryRow(
+	exec, err := pi.Repository.Conn(ctx).Exec(
 		ctx,
 		`UPDATE encrypted_item 
 				SET `+setQuery+` 
-				WHERE is_deleted = FALSE AND id = $1
+				WHERE is_deleted = FALSE AND id = $1 AND user_id = $2
+					AND item_type_id = (SELECT id FROM item_type WHERE alias = $3)
 		`,
 		args...)
 
-	if row == nil {
-		logger.WriteErrorLog("UpdateItem error in sql empty result")
-		return 0, errors.New("UpdateItem error in sql empty result")
+	if err != nil {
+		logger.WriteErrorLog(err.Error())
+		return 0, errors.New("UpdateItem error in sql")
+	}
+	if exec.RowsAffected() != 1 {
+		return 0, errItemNotFound
 	}
 
 	return itemId, nil
 }
 
 // GetMetaDataList убрать нигде не используется
-func (pi *Item) GetMetaDataList(ctx context.Context, itemId int64) ([]*itemModel.MetaData, error) {
-	var metadataItem itemModel.MetaData
+func (pi *Item) GetMetaDataList(ctx context.Context, userID int64, itemType string, itemId int64) ([]*itemModel.MetaData, error) {
 	var metadata []*itemModel.MetaData
 
-	rowsMetaData, err := pi.Repository.Pool.Query(
+	rowsMetaData, err := pi.Repository.Conn(ctx).Query(
 		ctx,
 		`SELECT
-				id,
-				name,
-				value,
-				created_at
-			FROM item_metadata
-			WHERE item_id=$1;`,
+				im.id,
+				im.name,
+				im.value,
+				im.created_at
+			FROM item_metadata im
+			JOIN encrypted_item ei ON im.item_id = ei.id
+			JOIN item_type it ON ei.item_type_id = it.id
+			WHERE im.item_id = $1 AND ei.user_id = $2 AND it.alias = $3 AND ei.is_deleted = FALSE;`,
 		itemId,
+		userID,
+		itemType,
 	)
 
 	if err != nil {
 		return nil, errors.New("GetMetaDataList error on sql empty result")
 	}
-	rowsMetaData.Close()
+	defer rowsMetaData.Close()
 	for rowsMetaData.Next() {
-		rowsMetaData.Scan(&metadataItem.ID, &metadataItem.Name, &metadataItem.Value, &metadataItem.CreatedAt)
+		var metadataItem itemModel.MetaData
+		err = rowsMetaData.Scan(&metadataItem.ID, &metadataItem.Name, &metadataItem.Value, &metadataItem.CreatedAt)
+		if err != nil {
+			return nil, errors.New("GetMetaDataList error in scan")
+		}
 		metadata = append(metadata, &metadataItem)
 	}
 	return metadata, nil
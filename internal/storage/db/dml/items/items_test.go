@@ -3,14 +3,19 @@ package items
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
 	"github.com/pashagolub/pgxmock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	itemModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/items"
 	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/mock"
@@ -23,21 +28,39 @@ func TestItem_DeleteItem(t *testing.T) {
 	defer ctrl.Finish()
 
 	type args struct {
-		ctx    context.Context
-		itemID int64
+		ctx      context.Context
+		userID   int64
+		itemType string
+		itemID   int64
 	}
 	tests := []struct {
-		name  string
-		query string
-		args  args
+		name       string
+		commandTag pgconn.CommandTag
+		args       args
+		wantCode   codes.Code
 	}{
 		{
-			name:  "success",
-			query: "UPDATE encrypted_item SET is_deleted=TRUE WHERE id = $1",
+			name:       "success",
+			commandTag: pgconn.CommandTag("UPDATE 0 1"),
 			args: args{
-				ctx:    context.Background(),
-				itemID: 1,
+				ctx:      context.Background(),
+				userID:   1,
+				itemType: "passwords",
+				itemID:   1,
 			},
+			wantCode: codes.OK,
+		},
+		{
+			// чужая запись, запись другого типа и удаленная запись не обновляются
+			name:       "foreign item",
+			commandTag: pgconn.CommandTag("UPDATE 0 0"),
+			args: args{
+				ctx:      context.Background(),
+				userID:   2,
+				itemType: "passwords",
+				itemID:   1,
+			},
+			wantCode: codes.NotFound,
 		},
 	}
 	for _, tt := range tests {
@@ -48,15 +71,16 @@ func TestItem_DeleteItem(t *testing.T) {
 				Repository: &repository.Repository{Pool: poolMock},
 			}
 
-			expectedCommandTag := pgconn.CommandTag("UPDATE 0 1")
 			poolMock.EXPECT().
 				Exec(
 					tt.args.ctx,
-					tt.query,
-					tt.args.itemID).
-				Return(expectedCommandTag, nil)
-			err := pi.DeleteItem(tt.args.ctx, tt.args.itemID)
-			assert.NoError(t, err)
+					gomock.Any(),
+					tt.args.itemID,
+					tt.args.userID,
+					tt.args.itemType).
+				Return(tt.commandTag, nil)
+			err := pi.DeleteItem(tt.args.ctx, tt.args.userID, tt.args.itemType, tt.args.itemID)
+			assert.Equal(t, tt.wantCode, status.Code(err))
 		})
 	}
 }
@@ -66,8 +90,10 @@ func TestItem_GetItem(t *testing.T) {
 	defer ctrl.Finish()
 
 	type args struct {
-		ctx    context.Context
-		itemID int64
+		ctx      context.Context
+		userID   int64
+		itemType string
+		itemID   int64
 	}
 	tests := []struct {
 		name         string
@@ -78,8 +104,10 @@ func TestItem_GetItem(t *testing.T) {
 		{
 			name: "test 1",
 			args: args{
-				ctx:    context.Background(),
-				itemID: 1,
+				ctx:      context.Background(),
+				userID:   1,
+				itemType: "passwords",
+				itemID:   1,
 			},
 			want:         &itemModel.ItemData{},
 			metaDataJSON: []byte(`[]`),
@@ -101,7 +129,9 @@ func TestItem_GetItem(t *testing.T) {
 				QueryRow(
 					tt.args.ctx,
 					gomock.Any(),
-					tt.args.itemID).
+					tt.args.itemID,
+					tt.args.userID,
+					tt.args.itemType).
 				Return(&mock.Row{
 					Values: []interface{}{
 						tt.want.ID,
@@ -110,11 +140,13 @@ func TestItem_GetItem(t *testing.T) {
 						tt.want.CreatedAt,
 						tt.want.EncryptionAlgorithm,
 						tt.want.IV,
+						tt.want.KeyVersion,
+						tt.want.AADBound,
 						tt.metaDataJSON,
 					},
 				})
 
-			got, err := pi.GetItem(tt.args.ctx, tt.args.itemID)
+			got, err := pi.GetItem(tt.args.ctx, tt.args.userID, tt.args.itemType, tt.args.itemID)
 			assert.NoError(t, err)
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("GetItem() got = %v, want %v", got, tt.want)
@@ -123,6 +155,26 @@ func TestItem_GetItem(t *testing.T) {
 	}
 }
 
+func TestItem_GetItem_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	poolMock := repository2.NewMockPooler(ctrl)
+	pi := &Item{
+		Repository: &repository.Repository{Pool: poolMock},
+	}
+	ctx := context.Background()
+
+	// запись 1 принадлежит другому пользователю или имеет другой тип
+	poolMock.EXPECT().
+		QueryRow(ctx, gomock.Any(), int64(1), int64(2), "card").
+		Return(&mock.Row{Err: pgx.ErrNoRows})
+
+	got, err := pi.GetItem(ctx, 2, "card", 1)
+	assert.Nil(t, got)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
 func TestItem_GetListItems(t *testing.T) {
 	type args struct {
 		ctx      context.Context
@@ -157,6 +209,8 @@ func TestItem_GetListItems(t *testing.T) {
 					CreatedAt:           time.Now(),
 					EncryptionAlgorithm: "AES-256-GCM",
 					IV:                  []byte("1"),
+					KeyVersion:          1,
+					AADBound:            true,
 					MetaDataItems:       []*itemModel.MetaData{},
 				},
 			},
@@ -180,6 +234,8 @@ func TestItem_GetListItems(t *testing.T) {
 				"created_at",
 				"encryption_algorithm",
 				"iv",
+				"key_version",
+				"is_aad_bound",
 				"metadata",
 			}).AddRow(
 				tt.want[0].ID,
@@ -188,6 +244,8 @@ func TestItem_GetListItems(t *testing.T) {
 				tt.want[0].CreatedAt,
 				tt.want[0].EncryptionAlgorithm,
 				tt.want[0].IV,
+				tt.want[0].KeyVersion,
+				tt.want[0].AADBound,
 				tt.metadataJSON,
 			)
 
@@ -222,6 +280,36 @@ func TestItem_GetListItems(t *testing.T) {
 	}
 }
 
+func TestItem_GetListItemsAfter(t *testing.T) {
+	poolMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	pi := &Item{
+		Repository: &repository.Repository{Pool: poolMock},
+	}
+	createdAt := time.Now()
+
+	rows := poolMock.NewRows([]string{
+		"id", "encrypted_data", "description", "created_at", "encryption_algorithm", "iv", "key_version", "is_aad_bound", "metadata",
+	}).AddRow(int64(7), []byte("1"), "", createdAt, "AES-256-GCM", []byte("1"), 1, true, []byte(`[{"id":1,"name":"site","value":"bank"}]`))
+	poolMock.ExpectQuery("SELECT.*ei.id.*FROM encrypted_item ei.*ei.id > \\$3.*ORDER BY ei.id.*LIMIT \\$4").
+		WithArgs(int64(1), "passwords", int64(5), int32(100)).
+		WillReturnRows(rows)
+
+	got, err := pi.GetListItemsAfter(context.Background(), 1, "passwords", 5, 100)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, int64(7), got[0].ID)
+	require.Len(t, got[0].MetaDataItems, 1)
+	assert.Equal(t, "bank", got[0].MetaDataItems[0].Value)
+
+	poolMock.ExpectQuery("SELECT.*FROM encrypted_item").
+		WithArgs(int64(1), "passwords", int64(7), int32(100)).
+		WillReturnError(errors.New("connection refused"))
+	_, err = pi.GetListItemsAfter(context.Background(), 1, "passwords", 7, 100)
+	assert.Error(t, err)
+	assert.NoError(t, poolMock.ExpectationsWereMet())
+}
+
 func TestItem_GetMetaDataList(t *testing.T) {
 	type args struct {
 		ctx    context.Context
@@ -267,11 +355,11 @@ func TestItem_GetMetaDataList(t *testing.T) {
 				tt.want[0].CreatedAt,
 			)
 
-			poolMock.ExpectQuery("SELECT.*id.*item_metadata.*").
-				WithArgs(tt.args.itemId).
+			poolMock.ExpectQuery("SELECT.*id.*item_metadata.*user_id = \\$2.*alias = \\$3").
+				WithArgs(tt.args.itemId, int64(1), "passwords").
 				WillReturnRows(rows)
 
-			got, err := pi.GetMetaDataList(tt.args.ctx, tt.args.itemId)
+			got, err := pi.GetMetaDataList(tt.args.ctx, 1, "passwords", tt.args.itemId)
 			assert.NoError(t, err)
 
 			if !reflect.DeepEqual(got, tt.want) {
@@ -335,6 +423,93 @@ func TestItem_GetTotalCount(t *testing.T) {
 	}
 }
 
+func TestItem_ReserveItemID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name    string
+		row     *mock.Row
+		want    int64
+		wantErr bool
+	}{
+		{
+			name: "reserved",
+			row:  &mock.Row{Values: []interface{}{int64(5)}},
+			want: 5,
+		},
+		{
+			name:    "error",
+			row:     &mock.Row{Err: errors.New("db error")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poolMock := repository2.NewMockPooler(ctrl)
+			pi := &Item{
+				Repository: &repository.Repository{Pool: poolMock},
+			}
+
+			poolMock.EXPECT().
+				QueryRow(context.Background(), gomock.Any()).
+				Return(tt.row)
+
+			got, err := pi.ReserveItemID(context.Background())
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestItem_ClaimItemID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name       string
+		commandTag pgconn.CommandTag
+		err        error
+		wantCode   codes.Code
+	}{
+		{
+			name:       "claimed",
+			commandTag: pgconn.CommandTag("DELETE 1"),
+			wantCode:   codes.OK,
+		},
+		{
+			// чужой, просроченный или уже использованный идентификатор не удаляется
+			name:       "not reserved",
+			commandTag: pgconn.CommandTag("DELETE 0"),
+			wantCode:   codes.NotFound,
+		},
+		{
+			name:     "db error",
+			err:      errors.New("db error"),
+			wantCode: codes.Unknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poolMock := repository2.NewMockPooler(ctrl)
+			pi := &Item{
+				Repository: &repository.Repository{Pool: poolMock},
+			}
+
+			poolMock.EXPECT().
+				Exec(context.Background(), gomock.Any(), itemModel.ReservedKindItem, int64(5), int64(1)).
+				Return(tt.commandTag, tt.err)
+
+			err := pi.ClaimItemID(context.Background(), 1, 5)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
 func TestItem_SaveEncryptedData(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -354,12 +529,15 @@ func TestItem_SaveEncryptedData(t *testing.T) {
 			args: args{
 				ctx: context.Background(),
 				encryptedItem: &itemModel.EncryptedItem{
+					ID:                  1,
 					UserID:              1,
 					Type:                "password",
 					Data:                []byte("password"),
 					Description:         "123",
 					EncryptionAlgorithm: "AES-256-GCM",
 					Iv:                  []byte("iv"),
+					KeyVersion:          1,
+					AADBound:            true,
 				},
 			},
 			wantTypeId: 1,
@@ -388,12 +566,15 @@ func TestItem_SaveEncryptedData(t *testing.T) {
 				QueryRow(
 					tt.args.ctx,
 					gomock.Any(),
+					tt.args.encryptedItem.ID,
 					tt.args.encryptedItem.Data,
 					tt.args.encryptedItem.Description,
 					tt.args.encryptedItem.UserID,
 					tt.wantTypeId,
 					tt.args.encryptedItem.EncryptionAlgorithm,
 					tt.args.encryptedItem.Iv,
+					tt.args.encryptedItem.KeyVersion,
+					tt.args.encryptedItem.AADBound,
 				).
 				Return(&mock.Row{
 					Values: []interface{}{
@@ -415,24 +596,45 @@ func TestItem_SaveMetadata(t *testing.T) {
 
 	type args struct {
 		ctx      context.Context
+		userID   int64
+		itemType string
 		metadata *itemModel.MetaData
 	}
 	tests := []struct {
-		name  string
-		query string
-		args  args
+		name       string
+		commandTag pgconn.CommandTag
+		args       args
+		wantCode   codes.Code
 	}{
 		{
-			name:  "test 1",
-			query: `INSERT INTO item_metadata (item_id, name, value) VALUES ($1, $2, $3)`,
+			name:       "test 1",
+			commandTag: pgconn.CommandTag("INSERT 0 1"),
 			args: args{
-				ctx: context.Background(),
+				ctx:      context.Background(),
+				userID:   1,
+				itemType: "passwords",
+				metadata: &itemModel.MetaData{
+					ItemID: 1,
+					Name:   "name",
+					Value:  "value",
+				},
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name:       "foreign item",
+			commandTag: pgconn.CommandTag("INSERT 0 0"),
+			args: args{
+				ctx:      context.Background(),
+				userID:   2,
+				itemType: "passwords",
 				metadata: &itemModel.MetaData{
 					ItemID: 1,
 					Name:   "name",
 					Value:  "value",
 				},
 			},
+			wantCode: codes.NotFound,
 		},
 	}
 	for _, tt := range tests {
@@ -442,17 +644,18 @@ func TestItem_SaveMetadata(t *testing.T) {
 				Repository: &repository.Repository{Pool: poolMock},
 			}
 
-			expectedCommandTag := pgconn.CommandTag("INSERT 0 1")
 			poolMock.EXPECT().
 				Exec(
 					tt.args.ctx,
-					tt.query,
+					gomock.Any(),
 					tt.args.metadata.ItemID,
 					tt.args.metadata.Name,
-					tt.args.metadata.Value).
-				Return(expectedCommandTag, nil)
-			err := pi.SaveMetadata(tt.args.ctx, tt.args.metadata)
-			assert.NoError(t, err)
+					tt.args.metadata.Value,
+					tt.args.userID,
+					tt.args.itemType).
+				Return(tt.commandTag, nil)
+			err := pi.SaveMetadata(tt.args.ctx, tt.args.userID, tt.args.itemType, tt.args.metadata)
+			assert.Equal(t, tt.wantCode, status.Code(err))
 		})
 	}
 }
@@ -463,29 +666,56 @@ func TestItem_UpdateItem(t *testing.T) {
 
 	type args struct {
 		ctx           context.Context
+		userID        int64
+		itemType      string
 		itemId        int64
 		encryptedItem *itemModel.EncryptedItem
 	}
 	tests := []struct {
-		name string
-		args args
-		want int64
+		name       string
+		commandTag pgconn.CommandTag
+		args       args
+		want       int64
+		wantCode   codes.Code
 	}{
 		{
-			name: "test 1",
+			name:       "test 1",
+			commandTag: pgconn.CommandTag("UPDATE 0 1"),
 			args: args{
-				ctx:    context.Background(),
-				itemId: 1,
+				ctx:      context.Background(),
+				userID:   1,
+				itemType: "passwords",
+				itemId:   1,
 				encryptedItem: &itemModel.EncryptedItem{
-					UserID:              1,
-					Type:                "password",
 					Data:                []byte("password"),
 					Description:         "123",
 					EncryptionAlgorithm: "AES256-GCM",
 					Iv:                  []byte("iv"),
+					KeyVersion:          1,
+					AADBound:            true,
 				},
 			},
-			want: 1,
+			want:     1,
+			wantCode: codes.OK,
+		},
+		{
+			name:       "foreign item",
+			commandTag: pgconn.CommandTag("UPDATE 0 0"),
+			args: args{
+				ctx:      context.Background(),
+				userID:   2,
+				itemType: "passwords",
+				itemId:   1,
+				encryptedItem: &itemModel.EncryptedItem{
+					Data:                []byte("password"),
+					Description:         "123",
+					EncryptionAlgorithm: "AES256-GCM",
+					Iv:                  []byte("iv"),
+					KeyVersion:          1,
+					AADBound:            true,
+				},
+			},
+			wantCode: codes.NotFound,
 		},
 	}
 	for _, tt := range tests {
@@ -496,25 +726,55 @@ func TestItem_UpdateItem(t *testing.T) {
 			}
 
 			poolMock.EXPECT().
-				QueryRow(
+				Exec(
 					tt.args.ctx,
 					gomock.Any(),
 					tt.args.itemId,
+					tt.args.userID,
+					tt.args.itemType,
 					tt.args.encryptedItem.Data,
+					tt.args.encryptedItem.AADBound,
 					tt.args.encryptedItem.Description,
 					tt.args.encryptedItem.EncryptionAlgorithm,
 					tt.args.encryptedItem.Iv,
+					tt.args.encryptedItem.KeyVersion,
 				).
-				Return(&mock.Row{
-					Values: []interface{}{
-						tt.want,
-					},
-				})
-			got, err := pi.UpdateItem(tt.args.ctx, tt.args.itemId, tt.args.encryptedItem)
-			assert.NoError(t, err)
+				Return(tt.commandTag, nil)
+			got, err := pi.UpdateItem(tt.args.ctx, tt.args.userID, tt.args.itemType, tt.args.itemId, tt.args.encryptedItem)
+			assert.Equal(t, tt.wantCode, status.Code(err))
 			if got != tt.want {
 				t.Errorf("UpdateItem() got = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestItem_WithTx(t *testing.T) {
+	poolMock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	pi := &Item{
+		Repository: &repository.Repository{Pool: poolMock},
+	}
+	errMetadata := errors.New("metadata failed")
+
+	// запись сохраняется в транзакции и откатывается вместе с метаданными
+	poolMock.ExpectBegin()
+	poolMock.ExpectQuery("SELECT id FROM item_type WHERE alias = \\$1").
+		WithArgs("passwords").
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int64(1)))
+	poolMock.ExpectQuery("INSERT INTO encrypted_item").
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int64(7)))
+	poolMock.ExpectExec("INSERT INTO item_metadata").
+		WillReturnError(errMetadata)
+	poolMock.ExpectRollback()
+
+	err = pi.WithTx(context.Background(), func(ctx context.Context) error {
+		itemID, err := pi.SaveEncryptedData(ctx, &itemModel.EncryptedItem{ID: 7, UserID: 1, Type: "passwords"})
+		if err != nil {
+			return err
+		}
+		return pi.SaveMetadata(ctx, 1, "passwords", &itemModel.MetaData{ItemID: itemID, Name: "site"})
+	})
+	assert.ErrorIs(t, err, errMetadata)
+	assert.NoError(t, poolMock.ExpectationsWereMet())
+}
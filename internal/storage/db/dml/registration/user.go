@@ -17,14 +17,16 @@ func (s *Reg) RegisterUser(ctx context.Context, user *user.User) (int, error) {
 
 	err := s.Repository.Pool.QueryRow(
 		ctx,
-		`INSERT INTO users (login, password_hash, first_name, last_name, is_active) 
-         VALUES ($1, $2, $3, $4, $5) 
+		`INSERT INTO users (login, password_hash, first_name, last_name, is_active, kdf_salt, wrapped_vault_key) 
+         VALUES ($1, $2, $3, $4, $5, $6, $7) 
          RETURNING id`,
 		user.Login,
 		user.PasswordHash,
 		user.FirstName,
 		user.LastName,
 		true,
+		user.KdfSalt,
+		user.WrappedVaultKey,
 	).Scan(&userID) // Сканируем возвращённый ID
 
 	if err != nil {
@@ -31,10 +31,12 @@ func TestReg_RegisterUser(t *testing.T) {
 			args: args{
 				ctx: context.Background(),
 				user: &user.User{
-					Login:        "test",
-					PasswordHash: "test",
-					FirstName:    "test",
-					LastName:     "test",
+					Login:           "test",
+					PasswordHash:    "test",
+					FirstName:       "test",
+					LastName:        "test",
+					KdfSalt:         []byte("salt"),
+					WrappedVaultKey: []byte("wrapped"),
 				},
 			},
 			want: 1,
@@ -56,6 +58,8 @@ func TestReg_RegisterUser(t *testing.T) {
 					tt.args.user.FirstName,
 					tt.args.user.LastName,
 					true,
+					tt.args.user.KdfSalt,
+					tt.args.user.WrappedVaultKey,
 				).
 				Return(&mock.Row{
 					Values: []interface{}{
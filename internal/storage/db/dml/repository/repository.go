@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
@@ -21,10 +22,54 @@ type Pooler interface {
 	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
 }
 
+// Querier выполнение запросов, общее для пула соединений и транзакции
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+}
+
+// txKey ключ открытой транзакции в контексте
+type txKey struct{}
+
 type Repository struct {
 	Pool Pooler
 }
 
+// WithTx выполнение fn в транзакции
+// запросы через Conn с контекстом fn выполняются в транзакции, при ошибке или панике в fn она откатывается,
+// вложенный вызов выполняется в уже открытой транзакции
+func (dbr *Repository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := dbr.Pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		// После Commit откат ничего не делает
+		_ = tx.Rollback(ctx)
+	}()
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Conn транзакция, открытая WithTx, или пул соединений, если транзакции в контексте нет
+func (dbr *Repository) Conn(ctx context.Context) Querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return dbr.Pool
+}
+
 func (dbr *Repository) ExecContext(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
 	result, err := dbr.Pool.Exec(ctx, query, args...)
 	if err != nil {
@@ -2,11 +2,14 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/jackc/pgconn"
+	"github.com/pashagolub/pgxmock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	repositoryMock "github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository/mocks"
 )
@@ -73,3 +76,76 @@ func TestRepository_PingContext(t *testing.T) {
 		})
 	}
 }
+
+func TestRepository_WithTx(t *testing.T) {
+	t.Run("commit", func(t *testing.T) {
+		poolMock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		dbr := Repository{Pool: poolMock}
+
+		poolMock.ExpectBegin()
+		poolMock.ExpectExec("INSERT INTO encrypted_item").WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		poolMock.ExpectExec("INSERT INTO item_metadata").WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		poolMock.ExpectCommit()
+
+		err = dbr.WithTx(context.Background(), func(ctx context.Context) error {
+			assert.NotEqual(t, poolMock, dbr.Conn(ctx))
+			if _, err := dbr.Conn(ctx).Exec(ctx, "INSERT INTO encrypted_item"); err != nil {
+				return err
+			}
+			// вложенный вызов не открывает новую транзакцию
+			return dbr.WithTx(ctx, func(ctx context.Context) error {
+				_, err := dbr.Conn(ctx).Exec(ctx, "INSERT INTO item_metadata")
+				return err
+			})
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, poolMock.ExpectationsWereMet())
+	})
+
+	t.Run("rollback on error", func(t *testing.T) {
+		poolMock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		dbr := Repository{Pool: poolMock}
+		errMetadata := errors.New("failed to save metadata")
+
+		poolMock.ExpectBegin()
+		poolMock.ExpectExec("INSERT INTO encrypted_item").WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		poolMock.ExpectRollback()
+
+		err = dbr.WithTx(context.Background(), func(ctx context.Context) error {
+			if _, err := dbr.Conn(ctx).Exec(ctx, "INSERT INTO encrypted_item"); err != nil {
+				return err
+			}
+			return errMetadata
+		})
+		assert.ErrorIs(t, err, errMetadata)
+		assert.NoError(t, poolMock.ExpectationsWereMet())
+	})
+
+	t.Run("begin failed", func(t *testing.T) {
+		poolMock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		dbr := Repository{Pool: poolMock}
+
+		poolMock.ExpectBegin().WillReturnError(errors.New("connection refused"))
+
+		called := false
+		err = dbr.WithTx(context.Background(), func(ctx context.Context) error {
+			called = true
+			return nil
+		})
+		assert.Error(t, err)
+		assert.False(t, called)
+		assert.NoError(t, poolMock.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_Conn(t *testing.T) {
+	poolMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	dbr := Repository{Pool: poolMock}
+
+	// без транзакции запросы идут в пул
+	assert.Equal(t, poolMock, dbr.Conn(context.Background()))
+}
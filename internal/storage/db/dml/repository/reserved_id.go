@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errReservedIDNotFound идентификатор не зарезервирован пользователем, уже использован или просрочен
+var errReservedIDNotFound = status.Error(codes.NotFound, "reserved id not found")
+
+// SaveReservedID сохранение идентификатора вида kind, зарезервированного клиентом пользователя userID
+// (таблица reserved_id), просроченные резервы пользователя удаляются
+func (dbr *Repository) SaveReservedID(ctx context.Context, kind string, id int64, userID int64, expiresAt time.Time) error {
+	return dbr.WithTx(ctx, func(ctx context.Context) error {
+		_, err := dbr.Conn(ctx).Exec(
+			ctx,
+			`DELETE FROM reserved_id WHERE user_id = $1 AND expires_at <= NOW()`,
+			userID)
+		if err != nil {
+			return fmt.Errorf("failed to delete expired %s ids: %w", kind, err)
+		}
+		_, err = dbr.Conn(ctx).Exec(
+			ctx,
+			`INSERT INTO reserved_id (kind, id, user_id, expires_at) VALUES ($1, $2, $3, $4)`,
+			kind,
+			id,
+			userID,
+			expiresAt)
+		if err != nil {
+			return fmt.Errorf("failed to save reserved %s id: %w", kind, err)
+		}
+		return nil
+	})
+}
+
+// ClaimReservedID использование идентификатора вида kind, зарезервированного пользователем userID
+// идентификатор используется один раз, чужой, просроченный или незарезервированный не находится (codes.NotFound)
+func (dbr *Repository) ClaimReservedID(ctx context.Context, kind string, id int64, userID int64) error {
+	exec, err := dbr.Conn(ctx).Exec(
+		ctx,
+		`DELETE FROM reserved_id WHERE kind = $1 AND id = $2 AND user_id = $3 AND expires_at > NOW()`,
+		kind,
+		id,
+		userID)
+	if err != nil {
+		return fmt.Errorf("failed to claim %s id: %w", kind, err)
+	}
+	if exec.RowsAffected() != 1 {
+		return errReservedIDNotFound
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package rotation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgconn"
+	"github.com/pashagolub/pgxmock"
+	"github.com/stretchr/testify/assert"
+
+	rotationModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/rotation"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/vault"
+	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/mock"
+	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
+	repositoryMock "github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository/mocks"
+)
+
+func TestRotation_CountRows(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name    string
+		table   string
+		want    int64
+		wantErr bool
+	}{
+		{
+			name:  "items",
+			table: rotationModel.TableItem,
+			want:  3,
+		},
+		{
+			name:    "unknown table",
+			table:   "users",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poolMock := repositoryMock.NewMockPooler(ctrl)
+			r := &Rotation{
+				Repository: &repository.Repository{Pool: poolMock},
+			}
+
+			if !tt.wantErr {
+				poolMock.EXPECT().
+					QueryRow(context.Background(), gomock.Any(), 1, vault.Algorithm).
+					Return(&mock.Row{Values: []interface{}{tt.want}})
+			}
+
+			got, err := r.CountRows(context.Background(), tt.table, 1)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRotation_GetRows(t *testing.T) {
+	poolMock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	r := &Rotation{
+		Repository: &repository.Repository{Pool: poolMock},
+	}
+
+	want := []*rotationModel.Row{
+		{
+			ID:                  5,
+			UserID:              1,
+			Data:                []byte("data"),
+			IV:                  []byte("iv"),
+			EncryptionAlgorithm: "AES-256-GCM",
+			KeyVersion:          1,
+			FileID:              3,
+			ChunkIndex:          2,
+			FinalChunk:          true,
+			AADBound:            true,
+		},
+	}
+	rows := poolMock.NewRows([]string{
+		"id",
+		"user_id",
+		"encrypted_data",
+		"iv",
+		"encryption_algorithm",
+		"key_version",
+		"alias",
+		"file_id",
+		"chunk_index",
+		"final_chunk",
+		"is_aad_bound",
+		"is_stream_bound",
+	}).AddRow(
+		want[0].ID,
+		want[0].UserID,
+		want[0].Data,
+		want[0].IV,
+		want[0].EncryptionAlgorithm,
+		want[0].KeyVersion,
+		want[0].ItemType,
+		want[0].FileID,
+		want[0].ChunkIndex,
+		want[0].FinalChunk,
+		want[0].AADBound,
+		want[0].StreamBound,
+	)
+
+	poolMock.ExpectQuery("SELECT.*FROM binary_file_chunk bfc.*JOIN binary_file").
+		WithArgs(1, vault.Algorithm, int64(4), 10).
+		WillReturnRows(rows)
+
+	got, err := r.GetRows(context.Background(), rotationModel.TableChunk, 1, 4, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.NoError(t, poolMock.ExpectationsWereMet())
+}
+
+func TestRotation_UpdateRow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	row := &rotationModel.Row{
+		ID:                  5,
+		Data:                []byte("data"),
+		IV:                  []byte("iv"),
+		EncryptionAlgorithm: "AES-256-GCM",
+		KeyVersion:          2,
+		AADBound:            true,
+	}
+	tests := []struct {
+		name    string
+		tag     pgconn.CommandTag
+		wantErr error
+	}{
+		{
+			name: "updated",
+			tag:  pgconn.CommandTag("UPDATE 1"),
+		},
+		{
+			name:    "changed concurrently",
+			tag:     pgconn.CommandTag("UPDATE 0"),
+			wantErr: rotationModel.ErrRowChanged,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poolMock := repositoryMock.NewMockPooler(ctrl)
+			r := &Rotation{
+				Repository: &repository.Repository{Pool: poolMock},
+			}
+
+			poolMock.EXPECT().
+				Exec(context.Background(), gomock.Any(), row.Data, row.IV, row.EncryptionAlgorithm, 2, int64(5), 1, true, []byte("old iv")).
+				Return(tt.tag, nil)
+
+			err := r.UpdateRow(context.Background(), rotationModel.TableDataKey, row, 1, []byte("old iv"))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package rotation
+
+import "github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
+
+type Rotation struct {
+	Repository *repository.Repository
+}
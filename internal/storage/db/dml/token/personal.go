@@ -0,0 +1,132 @@
+package token
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	"github.com/ramil063/secondgodiplom/internal/hash"
+	"github.com/ramil063/secondgodiplom/internal/logger"
+)
+
+// SavePersonalToken сохранение хеша персонального токена, возвращает идентификатор
+// токен не сохраняется, если у пользователя уже MaxPersonalTokens действующих токенов
+func (s *Token) SavePersonalToken(ctx context.Context, personal *auth.PersonalToken, token string) (int, error) {
+	row := s.Repository.Pool.QueryRow(
+		ctx,
+		`INSERT INTO personal_access_token (user_id, name, token_hash, scopes, expires_at)
+			SELECT $1, $2, $3, $4, $5
+			WHERE (
+				SELECT COUNT(*) FROM personal_access_token
+				WHERE user_id = $1 AND is_revoked = FALSE AND (expires_at IS NULL OR expires_at > NOW())
+			) < $6
+			RETURNING id, created_at`,
+		personal.UserID,
+		personal.Name,
+		hash.GetTokenHash(token),
+		personal.Scopes,
+		personal.ExpiresAt,
+		auth.MaxPersonalTokens)
+
+	err := row.Scan(&personal.ID, &personal.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, status.Error(codes.ResourceExhausted, "too many personal tokens")
+	}
+	if err != nil {
+		logger.WriteErrorLog(err.Error())
+		return 0, errors.New("SavePersonalToken error in sql")
+	}
+	return personal.ID, nil
+}
+
+// GetPersonalToken действующий персональный токен по его значению
+// токен действует, пока не отозван, не истек и его владелец активен; отмечается время использования
+func (s *Token) GetPersonalToken(ctx context.Context, token string) (*auth.PersonalToken, error) {
+	row := s.Repository.Pool.QueryRow(
+		ctx,
+		`UPDATE personal_access_token pat SET last_used_at = NOW()
+			FROM users u
+			WHERE pat.token_hash = $1 AND pat.is_revoked = FALSE
+				AND (pat.expires_at IS NULL OR pat.expires_at > NOW())
+				AND u.id = pat.user_id AND u.is_active = TRUE
+			RETURNING pat.id, pat.user_id, pat.name, pat.scopes, pat.expires_at, pat.last_used_at, pat.created_at`,
+		hash.GetTokenHash(token))
+
+	personal := &auth.PersonalToken{}
+	err := row.Scan(
+		&personal.ID,
+		&personal.UserID,
+		&personal.Name,
+		&personal.Scopes,
+		&personal.ExpiresAt,
+		&personal.LastUsedAt,
+		&personal.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "token not found")
+	}
+	if err != nil {
+		logger.WriteErrorLog(err.Error())
+		return nil, errors.New("GetPersonalToken error in sql")
+	}
+	return personal, nil
+}
+
+// ListPersonalTokens неотозванные персональные токены пользователя, сначала новые
+// истекшие токены тоже возвращаются, чтобы пользователь видел, что их нужно перевыпустить
+func (s *Token) ListPersonalTokens(ctx context.Context, userID int) ([]auth.PersonalToken, error) {
+	rows, err := s.Repository.Pool.Query(
+		ctx,
+		`SELECT id, user_id, name, scopes, expires_at, last_used_at, created_at
+			FROM personal_access_token
+			WHERE user_id = $1 AND is_revoked = FALSE
+			ORDER BY created_at DESC, id DESC`,
+		userID)
+	if err != nil {
+		return nil, errors.New("ListPersonalTokens error in sql")
+	}
+	defer rows.Close()
+
+	var tokens []auth.PersonalToken
+	for rows.Next() {
+		var personal auth.PersonalToken
+		err = rows.Scan(
+			&personal.ID,
+			&personal.UserID,
+			&personal.Name,
+			&personal.Scopes,
+			&personal.ExpiresAt,
+			&personal.LastUsedAt,
+			&personal.CreatedAt,
+		)
+		if err != nil {
+			return nil, errors.New("ListPersonalTokens error in scan")
+		}
+		tokens = append(tokens, personal)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.New("ListPersonalTokens error in rows")
+	}
+	return tokens, nil
+}
+
+// RevokePersonalToken отзыв персонального токена пользователя
+func (s *Token) RevokePersonalToken(ctx context.Context, userID int, tokenID int) error {
+	exec, err := s.Repository.Pool.Exec(
+		ctx,
+		`UPDATE personal_access_token SET is_revoked = TRUE
+			WHERE id = $1 AND user_id = $2 AND is_revoked = FALSE`,
+		tokenID,
+		userID)
+	if err != nil {
+		return errors.New("RevokePersonalToken error in sql")
+	}
+	if exec.RowsAffected() != 1 {
+		return status.Error(codes.NotFound, "token not found")
+	}
+	return nil
+}
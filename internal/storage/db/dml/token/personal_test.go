@@ -0,0 +1,200 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pashagolub/pgxmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	"github.com/ramil063/secondgodiplom/internal/hash"
+	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
+)
+
+func TestToken_SavePersonalToken(t *testing.T) {
+	expiresAt := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		queryErr error
+		want     int
+		wantCode codes.Code
+		wantErr  bool
+	}{
+		{
+			name: "success",
+			want: 7,
+		},
+		{
+			name:     "limit reached",
+			queryErr: pgx.ErrNoRows,
+			wantCode: codes.ResourceExhausted,
+			wantErr:  true,
+		},
+		{
+			name:     "sql error",
+			queryErr: errors.New("connection refused"),
+			wantCode: codes.Unknown,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poolMock, err := pgxmock.NewPool()
+			require.NoError(t, err)
+			s := &Token{
+				Repository: &repository.Repository{Pool: poolMock},
+			}
+			personal := &auth.PersonalToken{
+				UserID:    1,
+				Name:      "backup",
+				Scopes:    []string{"passwords:read"},
+				ExpiresAt: &expiresAt,
+			}
+
+			expectation := poolMock.ExpectQuery("INSERT INTO personal_access_token").
+				WithArgs(1, "backup", hash.GetTokenHash("gkp_token"), []string{"passwords:read"}, &expiresAt, auth.MaxPersonalTokens)
+			if tt.queryErr != nil {
+				expectation.WillReturnError(tt.queryErr)
+			} else {
+				expectation.WillReturnRows(poolMock.NewRows([]string{"id", "created_at"}).AddRow(tt.want, createdAt))
+			}
+
+			got, err := s.SavePersonalToken(context.Background(), personal, "gkp_token")
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.wantCode, status.Code(err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, createdAt, personal.CreatedAt)
+			assert.NoError(t, poolMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestToken_GetPersonalToken(t *testing.T) {
+	createdAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	lastUsedAt := createdAt.Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		want     *auth.PersonalToken
+		queryErr error
+		wantCode codes.Code
+	}{
+		{
+			name: "active token",
+			want: &auth.PersonalToken{
+				ID:         7,
+				UserID:     1,
+				Name:       "backup",
+				Scopes:     []string{"passwords:read", "item:5:read"},
+				LastUsedAt: &lastUsedAt,
+				CreatedAt:  createdAt,
+			},
+		},
+		{
+			name:     "revoked or expired",
+			queryErr: pgx.ErrNoRows,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "sql error",
+			queryErr: errors.New("connection refused"),
+			wantCode: codes.Unknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poolMock, err := pgxmock.NewPool()
+			require.NoError(t, err)
+			s := &Token{
+				Repository: &repository.Repository{Pool: poolMock},
+			}
+
+			expectation := poolMock.ExpectQuery("UPDATE personal_access_token pat SET last_used_at = NOW\\(\\)").
+				WithArgs(hash.GetTokenHash("gkp_token"))
+			if tt.queryErr != nil {
+				expectation.WillReturnError(tt.queryErr)
+			} else {
+				expectation.WillReturnRows(poolMock.NewRows(
+					[]string{"id", "user_id", "name", "scopes", "expires_at", "last_used_at", "created_at"}).
+					AddRow(tt.want.ID, tt.want.UserID, tt.want.Name, tt.want.Scopes, tt.want.ExpiresAt, tt.want.LastUsedAt, tt.want.CreatedAt))
+			}
+
+			got, err := s.GetPersonalToken(context.Background(), "gkp_token")
+			if tt.want == nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.wantCode, status.Code(err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, poolMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestToken_ListPersonalTokens(t *testing.T) {
+	poolMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	s := &Token{
+		Repository: &repository.Repository{Pool: poolMock},
+	}
+	createdAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	expiresAt := createdAt.Add(24 * time.Hour)
+	want := []auth.PersonalToken{
+		{ID: 8, UserID: 1, Name: "ci", Scopes: []string{"files:write"}, ExpiresAt: &expiresAt, CreatedAt: createdAt},
+		{ID: 7, UserID: 1, Name: "backup", Scopes: []string{"passwords:read"}, CreatedAt: createdAt},
+	}
+
+	rows := poolMock.NewRows([]string{"id", "user_id", "name", "scopes", "expires_at", "last_used_at", "created_at"})
+	for _, personal := range want {
+		rows.AddRow(personal.ID, personal.UserID, personal.Name, personal.Scopes, personal.ExpiresAt, personal.LastUsedAt, personal.CreatedAt)
+	}
+	poolMock.ExpectQuery("SELECT id, user_id, name, scopes, expires_at, last_used_at, created_at").
+		WithArgs(1).
+		WillReturnRows(rows)
+
+	got, err := s.ListPersonalTokens(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	poolMock.ExpectQuery("SELECT id, user_id, name, scopes").
+		WithArgs(2).
+		WillReturnError(errors.New("connection refused"))
+	_, err = s.ListPersonalTokens(context.Background(), 2)
+	assert.Error(t, err)
+	assert.NoError(t, poolMock.ExpectationsWereMet())
+}
+
+func TestToken_RevokePersonalToken(t *testing.T) {
+	poolMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	s := &Token{
+		Repository: &repository.Repository{Pool: poolMock},
+	}
+
+	poolMock.ExpectExec("UPDATE personal_access_token SET is_revoked = TRUE").
+		WithArgs(7, 1).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	assert.NoError(t, s.RevokePersonalToken(context.Background(), 1, 7))
+
+	// чужой или уже отозванный токен
+	poolMock.ExpectExec("UPDATE personal_access_token SET is_revoked = TRUE").
+		WithArgs(7, 2).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	err = s.RevokePersonalToken(context.Background(), 2, 7)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.NoError(t, poolMock.ExpectationsWereMet())
+}
@@ -0,0 +1,7 @@
+package token
+
+import "github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
+
+type Token struct {
+	Repository *repository.Repository
+}
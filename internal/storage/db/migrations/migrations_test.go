@@ -0,0 +1,91 @@
+package migrations
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+
+	assert.Equal(t, 1, migrations[0].Version)
+	assert.Equal(t, "initial", migrations[0].Name)
+	assert.Contains(t, migrations[0].Up, "CREATE TABLE IF NOT EXISTS users")
+	assert.Contains(t, migrations[0].Down, "DROP TABLE IF EXISTS users")
+	assert.Contains(t, migrations[0].Down, "DROP FUNCTION IF EXISTS audit_event_append_only")
+}
+
+func TestParse(t *testing.T) {
+	file := func(data string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(data)}
+	}
+
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		want    []Migration
+		wantErr string
+	}{
+		{
+			name: "sorted by version",
+			fsys: fstest.MapFS{
+				"sql/0002_add_index.up.sql":   file("CREATE INDEX"),
+				"sql/0002_add_index.down.sql": file("DROP INDEX"),
+				"sql/0001_initial.up.sql":     file("CREATE TABLE"),
+				"sql/0001_initial.down.sql":   file("DROP TABLE"),
+			},
+			want: []Migration{
+				{Version: 1, Name: "initial", Up: "CREATE TABLE", Down: "DROP TABLE"},
+				{Version: 2, Name: "add_index", Up: "CREATE INDEX", Down: "DROP INDEX"},
+			},
+		},
+		{
+			name: "invalid file name",
+			fsys: fstest.MapFS{
+				"sql/initial.sql": file("CREATE TABLE"),
+			},
+			wantErr: "invalid migration file name",
+		},
+		{
+			name: "missing down",
+			fsys: fstest.MapFS{
+				"sql/0001_initial.up.sql": file("CREATE TABLE"),
+			},
+			wantErr: "must have up and down files",
+		},
+		{
+			name: "different names",
+			fsys: fstest.MapFS{
+				"sql/0001_initial.up.sql": file("CREATE TABLE"),
+				"sql/0001_first.down.sql": file("DROP TABLE"),
+			},
+			wantErr: "different names",
+		},
+		{
+			name: "gap in versions",
+			fsys: fstest.MapFS{
+				"sql/0001_initial.up.sql":     file("CREATE TABLE"),
+				"sql/0001_initial.down.sql":   file("DROP TABLE"),
+				"sql/0003_add_index.up.sql":   file("CREATE INDEX"),
+				"sql/0003_add_index.down.sql": file("DROP INDEX"),
+			},
+			wantErr: "migration 2 is missing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.fsys, "sql")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
+)
+
+// lockID ключ рекомендательной блокировки миграций, общий для всех экземпляров сервера
+const lockID int64 = 0x676b6d6967726174
+
+// ErrSchemaTooNew версия схемы бд новее последней миграции, известной серверу
+var ErrSchemaTooNew = errors.New("database schema is newer than this server supports")
+
+const createMigrationsTableSQL = `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INT PRIMARY KEY,
+		name VARCHAR(128) NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+	);
+	COMMENT ON COLUMN public.schema_migrations.version IS 'Версия схемы';
+	COMMENT ON COLUMN public.schema_migrations.name IS 'Название миграции';
+	COMMENT ON COLUMN public.schema_migrations.applied_at IS 'Дата применения';`
+
+// Status состояние миграции в бд
+type Status struct {
+	Migration
+	AppliedAt *time.Time // Дата применения, nil для непримененой миграции
+}
+
+// Migrator применение и откат миграций
+// каждая миграция выполняется в своей транзакции под рекомендательной блокировкой,
+// поэтому несколько экземпляров сервера, запущенных одновременно, не применяют одну миграцию дважды
+type Migrator struct {
+	repository repository.Repository
+	migrations []Migration
+}
+
+// NewMigrator миграции, встроенные в сервер
+func NewMigrator(repository repository.Repository) (*Migrator, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{repository: repository, migrations: migrations}, nil
+}
+
+// Latest последняя версия схемы, известная серверу
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up применение всех непримененых миграций, возвращает примененные
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	for {
+		var next *Migration
+		err := m.inLockedTx(ctx, func(tx repository.Querier) error {
+			current, err := currentVersion(ctx, tx)
+			if err != nil {
+				return err
+			}
+			if current > m.Latest() {
+				return fmt.Errorf("%w: version %d, supported %d", ErrSchemaTooNew, current, m.Latest())
+			}
+			if current == m.Latest() {
+				return nil
+			}
+
+			next = &m.migrations[current]
+			if _, err = tx.Exec(ctx, next.Up); err != nil {
+				return fmt.Errorf("apply migration %d_%s: %w", next.Version, next.Name, err)
+			}
+			_, err = tx.Exec(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, next.Version, next.Name)
+			return err
+		})
+		if err != nil {
+			return applied, err
+		}
+		if next == nil {
+			return applied, nil
+		}
+		applied = append(applied, *next)
+	}
+}
+
+// Down откат последних steps примененных миграций, возвращает откаченные
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	for len(reverted) < steps {
+		var last *Migration
+		err := m.inLockedTx(ctx, func(tx repository.Querier) error {
+			current, err := currentVersion(ctx, tx)
+			if err != nil {
+				return err
+			}
+			if current > m.Latest() {
+				return fmt.Errorf("%w: version %d, supported %d", ErrSchemaTooNew, current, m.Latest())
+			}
+			if current == 0 {
+				return nil
+			}
+
+			last = &m.migrations[current-1]
+			if _, err = tx.Exec(ctx, last.Down); err != nil {
+				return fmt.Errorf("revert migration %d_%s: %w", last.Version, last.Name, err)
+			}
+			_, err = tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, last.Version)
+			return err
+		})
+		if err != nil {
+			return reverted, err
+		}
+		if last == nil {
+			break
+		}
+		reverted = append(reverted, *last)
+	}
+	return reverted, nil
+}
+
+// Status все известные серверу миграции с датой применения
+// версии из бд, неизвестные серверу, возвращаются без запросов
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied := make(map[int]Status)
+	err := m.inLockedTx(ctx, func(tx repository.Querier) error {
+		rows, err := tx.Query(ctx, `SELECT version, name, applied_at FROM schema_migrations ORDER BY version`)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var status Status
+			var appliedAt time.Time
+			if err = rows.Scan(&status.Version, &status.Name, &appliedAt); err != nil {
+				return err
+			}
+			status.AppliedAt = &appliedAt
+			applied[status.Version] = status
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if appliedStatus, ok := applied[migration.Version]; ok {
+			status.AppliedAt = appliedStatus.AppliedAt
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	unknown := make([]int, 0, len(applied))
+	for version := range applied {
+		unknown = append(unknown, version)
+	}
+	sort.Ints(unknown)
+	for _, version := range unknown {
+		statuses = append(statuses, applied[version])
+	}
+	return statuses, nil
+}
+
+// inLockedTx выполнение в транзакции под рекомендательной блокировкой миграций
+// блокировка снимается при завершении транзакции
+func (m *Migrator) inLockedTx(ctx context.Context, fn func(tx repository.Querier) error) error {
+	return m.repository.WithTx(ctx, func(ctx context.Context) error {
+		tx := m.repository.Conn(ctx)
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, lockID); err != nil {
+			return fmt.Errorf("lock migrations: %w", err)
+		}
+		if _, err := tx.Exec(ctx, createMigrationsTableSQL); err != nil {
+			return fmt.Errorf("create schema_migrations: %w", err)
+		}
+		return fn(tx)
+	})
+}
+
+// currentVersion последняя примененная версия схемы, 0 для пустой бд
+func currentVersion(ctx context.Context, tx repository.Querier) (int, error) {
+	var version int
+	err := tx.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("get schema version: %w", err)
+	}
+	return version, nil
+}
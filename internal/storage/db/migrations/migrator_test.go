@@ -0,0 +1,154 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ramil063/secondgodiplom/internal/storage/db/dml/repository"
+)
+
+var testMigrations = []Migration{
+	{Version: 1, Name: "initial", Up: "CREATE TABLE users", Down: "DROP TABLE users"},
+	{Version: 2, Name: "add_index", Up: "CREATE INDEX users_login_idx", Down: "DROP INDEX users_login_idx"},
+}
+
+func newTestMigrator(t *testing.T) (*Migrator, pgxmock.PgxPoolIface) {
+	poolMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	return &Migrator{
+		repository: repository.Repository{Pool: poolMock},
+		migrations: testMigrations,
+	}, poolMock
+}
+
+// expectLockedTx начало транзакции миграции с текущей версией схемы
+func expectLockedTx(poolMock pgxmock.PgxPoolIface, version int) {
+	poolMock.ExpectBegin()
+	poolMock.ExpectExec("SELECT pg_advisory_xact_lock\\(\\$1\\)").
+		WithArgs(lockID).
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	poolMock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").
+		WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+	poolMock.ExpectQuery("SELECT COALESCE\\(MAX\\(version\\), 0\\) FROM schema_migrations").
+		WillReturnRows(poolMock.NewRows([]string{"version"}).AddRow(version))
+}
+
+func TestMigrator_Up(t *testing.T) {
+	m, poolMock := newTestMigrator(t)
+
+	// вторая миграция применяется после первой, каждая в своей транзакции
+	expectLockedTx(poolMock, 0)
+	poolMock.ExpectExec("CREATE TABLE users").WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+	poolMock.ExpectExec("INSERT INTO schema_migrations").
+		WithArgs(1, "initial").
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	poolMock.ExpectCommit()
+	expectLockedTx(poolMock, 1)
+	poolMock.ExpectExec("CREATE INDEX users_login_idx").WillReturnResult(pgxmock.NewResult("CREATE INDEX", 0))
+	poolMock.ExpectExec("INSERT INTO schema_migrations").
+		WithArgs(2, "add_index").
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	poolMock.ExpectCommit()
+	expectLockedTx(poolMock, 2)
+	poolMock.ExpectCommit()
+
+	applied, err := m.Up(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, testMigrations, applied)
+	assert.NoError(t, poolMock.ExpectationsWereMet())
+}
+
+func TestMigrator_Up_Errors(t *testing.T) {
+	t.Run("schema too new", func(t *testing.T) {
+		m, poolMock := newTestMigrator(t)
+		expectLockedTx(poolMock, 3)
+		poolMock.ExpectRollback()
+
+		applied, err := m.Up(context.Background())
+		assert.ErrorIs(t, err, ErrSchemaTooNew)
+		assert.Empty(t, applied)
+		assert.NoError(t, poolMock.ExpectationsWereMet())
+	})
+
+	t.Run("migration failed", func(t *testing.T) {
+		m, poolMock := newTestMigrator(t)
+		expectLockedTx(poolMock, 1)
+		poolMock.ExpectExec("CREATE INDEX users_login_idx").WillReturnError(errors.New("syntax error"))
+		poolMock.ExpectRollback()
+
+		applied, err := m.Up(context.Background())
+		assert.ErrorContains(t, err, "apply migration 2_add_index")
+		assert.Empty(t, applied)
+		assert.NoError(t, poolMock.ExpectationsWereMet())
+	})
+
+	t.Run("lock failed", func(t *testing.T) {
+		m, poolMock := newTestMigrator(t)
+		poolMock.ExpectBegin()
+		poolMock.ExpectExec("SELECT pg_advisory_xact_lock").
+			WithArgs(lockID).
+			WillReturnError(errors.New("canceling statement due to lock timeout"))
+		poolMock.ExpectRollback()
+
+		_, err := m.Up(context.Background())
+		assert.ErrorContains(t, err, "lock migrations")
+		assert.NoError(t, poolMock.ExpectationsWereMet())
+	})
+}
+
+func TestMigrator_Down(t *testing.T) {
+	m, poolMock := newTestMigrator(t)
+
+	expectLockedTx(poolMock, 2)
+	poolMock.ExpectExec("DROP INDEX users_login_idx").WillReturnResult(pgxmock.NewResult("DROP INDEX", 0))
+	poolMock.ExpectExec("DELETE FROM schema_migrations WHERE version = \\$1").
+		WithArgs(2).
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	poolMock.ExpectCommit()
+	expectLockedTx(poolMock, 1)
+	poolMock.ExpectExec("DROP TABLE users").WillReturnResult(pgxmock.NewResult("DROP TABLE", 0))
+	poolMock.ExpectExec("DELETE FROM schema_migrations WHERE version = \\$1").
+		WithArgs(1).
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	poolMock.ExpectCommit()
+	// откатывать больше нечего
+	expectLockedTx(poolMock, 0)
+	poolMock.ExpectCommit()
+
+	reverted, err := m.Down(context.Background(), 5)
+	require.NoError(t, err)
+	assert.Equal(t, []Migration{testMigrations[1], testMigrations[0]}, reverted)
+	assert.NoError(t, poolMock.ExpectationsWereMet())
+}
+
+func TestMigrator_Status(t *testing.T) {
+	m, poolMock := newTestMigrator(t)
+	appliedAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	poolMock.ExpectBegin()
+	poolMock.ExpectExec("SELECT pg_advisory_xact_lock").
+		WithArgs(lockID).
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	poolMock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").
+		WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+	poolMock.ExpectQuery("SELECT version, name, applied_at FROM schema_migrations").
+		WillReturnRows(poolMock.NewRows([]string{"version", "name", "applied_at"}).
+			AddRow(1, "initial", appliedAt).
+			AddRow(7, "from_newer_server", appliedAt))
+	poolMock.ExpectCommit()
+
+	statuses, err := m.Status(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Status{
+		{Migration: testMigrations[0], AppliedAt: &appliedAt},
+		{Migration: testMigrations[1]},
+		{Migration: Migration{Version: 7, Name: "from_newer_server"}, AppliedAt: &appliedAt},
+	}, statuses)
+	assert.NoError(t, poolMock.ExpectationsWereMet())
+}
@@ -0,0 +1,19 @@
+-- Удаление исходной схемы хранилища вместе со всеми данными
+DROP TABLE IF EXISTS binary_file_metadata;
+DROP TABLE IF EXISTS binary_file_chunk;
+DROP TABLE IF EXISTS binary_file;
+DROP TABLE IF EXISTS audit_event;
+DROP FUNCTION IF EXISTS audit_event_append_only();
+DROP TABLE IF EXISTS personal_access_token;
+DROP TABLE IF EXISTS login_attempt;
+DROP TABLE IF EXISTS security_event;
+DROP TABLE IF EXISTS login_challenge;
+DROP TABLE IF EXISTS user_recovery_code;
+DROP TABLE IF EXISTS user_totp;
+DROP TABLE IF EXISTS oauth_refresh_token;
+DROP TABLE IF EXISTS oauth_access_token;
+DROP TABLE IF EXISTS item_metadata;
+DROP TABLE IF EXISTS encrypted_item;
+DROP TABLE IF EXISTS item_type;
+DROP TABLE IF EXISTS user_data_key;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,391 @@
+-- Исходная схема хранилища
+-- запросы идемпотентны: базы, созданные до появления миграций, приводятся к этой схеме без потери данных
+
+--USERS
+CREATE TABLE IF NOT EXISTS users (
+	id SERIAL PRIMARY KEY,
+	login VARCHAR(64) UNIQUE NOT NULL,
+	password_hash BYTEA NOT NULL,
+	first_name VARCHAR(64),
+	last_name VARCHAR(64),
+	is_active BOOLEAN DEFAULT TRUE,
+	created_at TIMESTAMP DEFAULT NOW(),
+	updated_at TIMESTAMP DEFAULT NOW()
+);
+COMMENT ON COLUMN public.users.id IS 'Идентификатор пользователя';
+COMMENT ON COLUMN public.users.login IS 'Логин пользователя';
+COMMENT ON COLUMN public.users.password_hash IS 'Хеш пароля (с солью)';
+COMMENT ON COLUMN public.users.first_name IS 'Имя пользователя';
+COMMENT ON COLUMN public.users.last_name IS 'Фамилия пользователя';
+COMMENT ON COLUMN public.users.is_active IS 'Флаг деактивации';
+COMMENT ON COLUMN public.users.created_at IS 'Дата создания';
+COMMENT ON COLUMN public.users.updated_at IS 'Дата обновления';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS kdf_salt BYTEA;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS wrapped_vault_key BYTEA;
+COMMENT ON COLUMN public.users.kdf_salt IS 'Соль для вывода ключа из мастер-пароля (сквозное шифрование)';
+COMMENT ON COLUMN public.users.wrapped_vault_key IS 'Ключ хранилища, зашифрованный на клиенте ключом из мастер-пароля';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deletion_scheduled_at TIMESTAMP;
+COMMENT ON COLUMN public.users.deletion_scheduled_at IS 'Дата окончательного удаления деактивированной учетной записи';
+
+--USER_DATA_KEY
+CREATE TABLE IF NOT EXISTS user_data_key (
+	id SERIAL PRIMARY KEY,
+	user_id INT UNIQUE NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	wrapped_key BYTEA NOT NULL,
+	iv BYTEA NOT NULL,
+	encryption_algorithm VARCHAR(32) NOT NULL,
+	created_at TIMESTAMP DEFAULT NOW()
+);
+COMMENT ON COLUMN public.user_data_key.id IS 'Идентификатор ключа';
+COMMENT ON COLUMN public.user_data_key.user_id IS 'Пользователь';
+COMMENT ON COLUMN public.user_data_key.wrapped_key IS 'Ключ шифрования данных, зашифрованный мастер-ключом';
+COMMENT ON COLUMN public.user_data_key.iv IS 'Вектор инициализации';
+COMMENT ON COLUMN public.user_data_key.encryption_algorithm IS 'Алгоритм шифрования ключа';
+COMMENT ON COLUMN public.user_data_key.created_at IS 'Дата создания';
+ALTER TABLE user_data_key ADD COLUMN IF NOT EXISTS key_version INT NOT NULL DEFAULT 1;
+COMMENT ON COLUMN public.user_data_key.key_version IS 'Версия мастер-ключа';
+ALTER TABLE user_data_key ADD COLUMN IF NOT EXISTS is_aad_bound BOOLEAN NOT NULL DEFAULT FALSE;
+COMMENT ON COLUMN public.user_data_key.is_aad_bound IS 'Ключ обернут с привязкой к пользователю (AAD)';
+
+--ITEM_TYPE
+CREATE TABLE IF NOT EXISTS item_type (
+	id SERIAL PRIMARY KEY,
+	alias VARCHAR(32) UNIQUE NOT NULL,
+	name VARCHAR(64) NOT NULL
+);
+COMMENT ON COLUMN public.item_type.id IS 'Идентификатор типа';
+COMMENT ON COLUMN public.item_type.alias IS 'Псевдоним';
+COMMENT ON COLUMN public.item_type.name IS 'Название';
+
+-- Пароли (логин/пароль)
+INSERT INTO item_type (alias, name)
+VALUES ('passwords', 'Пары логин/пароль')
+ON CONFLICT (alias) DO NOTHING;
+
+-- Произвольные текстовые данные
+INSERT INTO item_type (alias, name)
+VALUES ('text', 'Произвольные текстовые данные')
+ON CONFLICT (alias) DO NOTHING;
+
+-- Произвольные бинарные данные
+INSERT INTO item_type (alias, name)
+VALUES ('binary', 'Произвольные бинарные данные')
+ON CONFLICT (alias) DO NOTHING;
+
+-- Данные банковских карт
+INSERT INTO item_type (alias, name)
+VALUES ('card', 'Данные банковских карт')
+ON CONFLICT (alias) DO NOTHING;
+
+--ENCRYPTED_ITEM
+CREATE TABLE IF NOT EXISTS encrypted_item (
+	id SERIAL PRIMARY KEY,
+	encrypted_data BYTEA NOT NULL,
+	description TEXT,
+	is_deleted BOOLEAN DEFAULT FALSE,
+	user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	item_type_id INT NOT NULL REFERENCES item_type(id),
+	encryption_algorithm VARCHAR(32),
+	iv BYTEA,
+	created_at TIMESTAMP DEFAULT NOW(),
+	updated_at TIMESTAMP DEFAULT NOW()
+);
+COMMENT ON COLUMN public.encrypted_item.id IS 'Идентификатор записи';
+COMMENT ON COLUMN public.encrypted_item.encrypted_data IS 'Зашифрованные данные';
+COMMENT ON COLUMN public.encrypted_item.description IS 'Описание, зашифровано ключом пользователя';
+COMMENT ON COLUMN public.encrypted_item.is_deleted IS 'Мягкое удаление';
+COMMENT ON COLUMN public.encrypted_item.user_id IS 'Пользователь';
+COMMENT ON COLUMN public.encrypted_item.item_type_id IS 'Тип';
+COMMENT ON COLUMN public.encrypted_item.encryption_algorithm IS 'Алгоритм шифрования';
+COMMENT ON COLUMN public.encrypted_item.iv IS 'Вектор инициализации';
+COMMENT ON COLUMN public.encrypted_item.created_at IS 'Дата создания';
+COMMENT ON COLUMN public.encrypted_item.updated_at IS 'Дата обновления';
+ALTER TABLE encrypted_item ADD COLUMN IF NOT EXISTS key_version INT NOT NULL DEFAULT 1;
+COMMENT ON COLUMN public.encrypted_item.key_version IS 'Версия мастер-ключа';
+ALTER TABLE encrypted_item ADD COLUMN IF NOT EXISTS is_aad_bound BOOLEAN NOT NULL DEFAULT FALSE;
+COMMENT ON COLUMN public.encrypted_item.is_aad_bound IS 'Шифротекст привязан к владельцу, записи и типу (AAD)';
+
+--ITEM_METADATA
+CREATE TABLE IF NOT EXISTS item_metadata (
+	id SERIAL PRIMARY KEY,
+	item_id INT NOT NULL REFERENCES encrypted_item(id) ON DELETE CASCADE,
+	name VARCHAR(128) NOT NULL,
+	value TEXT,
+	created_at TIMESTAMP DEFAULT NOW(),
+	updated_at TIMESTAMP DEFAULT NOW()
+);
+COMMENT ON COLUMN public.item_metadata.id IS 'Идентификатор записи';
+COMMENT ON COLUMN public.item_metadata.item_id IS 'Связь с данными';
+COMMENT ON COLUMN public.item_metadata.name IS 'Название метаданных, зашифровано ключом пользователя';
+COMMENT ON COLUMN public.item_metadata.value IS 'Значение метаданных, зашифровано ключом пользователя';
+COMMENT ON COLUMN public.item_metadata.created_at IS 'Дата создания';
+COMMENT ON COLUMN public.item_metadata.updated_at IS 'Дата обновления';
+-- зашифрованное значение длиннее исходного
+ALTER TABLE item_metadata ALTER COLUMN name TYPE TEXT;
+
+--OAUTH_ACCESS_TOKEN
+CREATE TABLE IF NOT EXISTS oauth_access_token (
+	id SERIAL PRIMARY KEY,
+	token_hash BYTEA NOT NULL,
+	user_id INT REFERENCES users(id),
+	expires_at TIMESTAMP NOT NULL,
+	created_at TIMESTAMP DEFAULT NOW()
+);
+COMMENT ON COLUMN public.oauth_access_token.id IS 'Идентификатор токена';
+COMMENT ON COLUMN public.oauth_access_token.token_hash IS 'Хеш токена';
+COMMENT ON COLUMN public.oauth_access_token.user_id IS 'Владелец кода';
+COMMENT ON COLUMN public.oauth_access_token.expires_at IS 'Срок действия';
+COMMENT ON COLUMN public.oauth_access_token.created_at IS 'Дата создания';
+ALTER TABLE oauth_access_token ADD COLUMN IF NOT EXISTS is_revoked BOOLEAN NOT NULL DEFAULT FALSE;
+COMMENT ON COLUMN public.oauth_access_token.is_revoked IS 'Отозван ли токен';
+ALTER TABLE oauth_access_token ADD COLUMN IF NOT EXISTS client_ip VARCHAR(64) NOT NULL DEFAULT '';
+COMMENT ON COLUMN public.oauth_access_token.client_ip IS 'IP адрес клиента';
+ALTER TABLE oauth_access_token ADD COLUMN IF NOT EXISTS user_agent TEXT NOT NULL DEFAULT '';
+COMMENT ON COLUMN public.oauth_access_token.user_agent IS 'User agent клиента';
+ALTER TABLE oauth_access_token ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMP;
+COMMENT ON COLUMN public.oauth_access_token.last_used_at IS 'Дата последнего использования';
+ALTER TABLE oauth_access_token ADD COLUMN IF NOT EXISTS session_created_at TIMESTAMP;
+COMMENT ON COLUMN public.oauth_access_token.session_created_at IS 'Дата входа на устройстве, сохраняется при обновлении токенов';
+
+--OAUTH_REFRESH_TOKEN
+CREATE TABLE IF NOT EXISTS oauth_refresh_token (
+	id SERIAL PRIMARY KEY,
+	token_hash BYTEA NOT NULL,          -- хеш refresh-токена
+	access_token_id INT NOT NULL REFERENCES oauth_access_token(id) ON DELETE CASCADE,
+	is_revoked BOOLEAN DEFAULT FALSE,
+	expires_at TIMESTAMP NOT NULL,    -- срок действия
+	created_at TIMESTAMP DEFAULT NOW()
+);
+COMMENT ON COLUMN public.oauth_refresh_token.id IS 'Идентификатор токена';
+COMMENT ON COLUMN public.oauth_refresh_token.token_hash IS 'Хеш токена';
+COMMENT ON COLUMN public.oauth_refresh_token.access_token_id IS 'Публичный идентификатор';
+COMMENT ON COLUMN public.oauth_refresh_token.is_revoked IS 'отозван ли токен';
+COMMENT ON COLUMN public.oauth_refresh_token.expires_at IS 'Срок действия';
+COMMENT ON COLUMN public.oauth_refresh_token.created_at IS 'Дата создания';
+ALTER TABLE oauth_refresh_token ADD COLUMN IF NOT EXISTS family_id VARCHAR(64);
+COMMENT ON COLUMN public.oauth_refresh_token.family_id IS 'Семейство токенов: цепочка обновлений от одного входа';
+ALTER TABLE oauth_refresh_token ADD COLUMN IF NOT EXISTS rotated_at TIMESTAMP;
+COMMENT ON COLUMN public.oauth_refresh_token.rotated_at IS 'Дата обмена на новый токен через Refresh';
+
+--USER_TOTP
+CREATE TABLE IF NOT EXISTS user_totp (
+	id SERIAL PRIMARY KEY,
+	user_id INT UNIQUE NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	encrypted_secret BYTEA NOT NULL,
+	iv BYTEA NOT NULL,
+	encryption_algorithm VARCHAR(32) NOT NULL,
+	key_version INT NOT NULL DEFAULT 1,
+	is_confirmed BOOLEAN NOT NULL DEFAULT FALSE,
+	last_used_step BIGINT NOT NULL DEFAULT 0,
+	created_at TIMESTAMP DEFAULT NOW(),
+	confirmed_at TIMESTAMP
+);
+COMMENT ON COLUMN public.user_totp.id IS 'Идентификатор';
+COMMENT ON COLUMN public.user_totp.user_id IS 'Пользователь';
+COMMENT ON COLUMN public.user_totp.encrypted_secret IS 'Секрет TOTP, зашифрованный ключом пользователя';
+COMMENT ON COLUMN public.user_totp.iv IS 'Вектор инициализации';
+COMMENT ON COLUMN public.user_totp.encryption_algorithm IS 'Алгоритм шифрования секрета';
+COMMENT ON COLUMN public.user_totp.key_version IS 'Версия мастер-ключа';
+COMMENT ON COLUMN public.user_totp.is_confirmed IS 'Подключение подтверждено кодом из приложения';
+COMMENT ON COLUMN public.user_totp.last_used_step IS 'Номер периода последнего принятого кода';
+COMMENT ON COLUMN public.user_totp.created_at IS 'Дата создания';
+COMMENT ON COLUMN public.user_totp.confirmed_at IS 'Дата подтверждения';
+
+--USER_RECOVERY_CODE
+CREATE TABLE IF NOT EXISTS user_recovery_code (
+	id SERIAL PRIMARY KEY,
+	user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	code_hash BYTEA NOT NULL,
+	used_at TIMESTAMP,
+	created_at TIMESTAMP DEFAULT NOW()
+);
+COMMENT ON COLUMN public.user_recovery_code.id IS 'Идентификатор';
+COMMENT ON COLUMN public.user_recovery_code.user_id IS 'Пользователь';
+COMMENT ON COLUMN public.user_recovery_code.code_hash IS 'Хеш кода восстановления';
+COMMENT ON COLUMN public.user_recovery_code.used_at IS 'Дата использования';
+COMMENT ON COLUMN public.user_recovery_code.created_at IS 'Дата создания';
+
+--LOGIN_CHALLENGE
+CREATE TABLE IF NOT EXISTS login_challenge (
+	id SERIAL PRIMARY KEY,
+	token_hash BYTEA UNIQUE NOT NULL,
+	user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	attempts INT NOT NULL DEFAULT 0,
+	is_used BOOLEAN NOT NULL DEFAULT FALSE,
+	expires_at TIMESTAMP NOT NULL,
+	created_at TIMESTAMP DEFAULT NOW()
+);
+COMMENT ON COLUMN public.login_challenge.id IS 'Идентификатор';
+COMMENT ON COLUMN public.login_challenge.token_hash IS 'Хеш токена входа, ожидающего второй фактор';
+COMMENT ON COLUMN public.login_challenge.user_id IS 'Пользователь';
+COMMENT ON COLUMN public.login_challenge.attempts IS 'Число попыток ввода кода';
+COMMENT ON COLUMN public.login_challenge.is_used IS 'Вход завершен';
+COMMENT ON COLUMN public.login_challenge.expires_at IS 'Срок действия';
+COMMENT ON COLUMN public.login_challenge.created_at IS 'Дата создания';
+
+--SECURITY_EVENT
+CREATE TABLE IF NOT EXISTS security_event (
+	id SERIAL PRIMARY KEY,
+	user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	event_type VARCHAR(64) NOT NULL,
+	client_ip VARCHAR(64) NOT NULL DEFAULT '',
+	user_agent TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP DEFAULT NOW()
+);
+COMMENT ON COLUMN public.security_event.id IS 'Идентификатор события';
+COMMENT ON COLUMN public.security_event.user_id IS 'Пользователь';
+COMMENT ON COLUMN public.security_event.event_type IS 'Тип события';
+COMMENT ON COLUMN public.security_event.client_ip IS 'IP адрес клиента';
+COMMENT ON COLUMN public.security_event.user_agent IS 'User agent клиента';
+COMMENT ON COLUMN public.security_event.created_at IS 'Дата события';
+
+--LOGIN_ATTEMPT
+CREATE TABLE IF NOT EXISTS login_attempt (
+	subject VARCHAR(128) PRIMARY KEY,
+	failures INT NOT NULL DEFAULT 0,
+	last_failure_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	locked_until TIMESTAMP
+);
+COMMENT ON COLUMN public.login_attempt.subject IS 'Логин или IP адрес клиента с префиксом';
+COMMENT ON COLUMN public.login_attempt.failures IS 'Неудачных попыток входа подряд';
+COMMENT ON COLUMN public.login_attempt.last_failure_at IS 'Время последней неудачной попытки';
+COMMENT ON COLUMN public.login_attempt.locked_until IS 'Вход заблокирован до';
+
+--PERSONAL_ACCESS_TOKEN
+CREATE TABLE IF NOT EXISTS personal_access_token (
+	id SERIAL PRIMARY KEY,
+	user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	name VARCHAR(255) NOT NULL,
+	token_hash BYTEA NOT NULL UNIQUE,
+	scopes TEXT[] NOT NULL,
+	expires_at TIMESTAMP,
+	last_used_at TIMESTAMP,
+	is_revoked BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMP DEFAULT NOW()
+);
+COMMENT ON COLUMN public.personal_access_token.id IS 'Идентификатор токена';
+COMMENT ON COLUMN public.personal_access_token.user_id IS 'Владелец токена';
+COMMENT ON COLUMN public.personal_access_token.name IS 'Название токена';
+COMMENT ON COLUMN public.personal_access_token.token_hash IS 'Хеш токена';
+COMMENT ON COLUMN public.personal_access_token.scopes IS 'Права токена';
+COMMENT ON COLUMN public.personal_access_token.expires_at IS 'Срок действия, NULL - бессрочный';
+COMMENT ON COLUMN public.personal_access_token.last_used_at IS 'Дата последнего использования';
+COMMENT ON COLUMN public.personal_access_token.is_revoked IS 'Отозван ли токен';
+COMMENT ON COLUMN public.personal_access_token.created_at IS 'Дата создания';
+
+--AUDIT_EVENT
+CREATE TABLE IF NOT EXISTS audit_event (
+	id BIGSERIAL PRIMARY KEY,
+	user_id INT REFERENCES users(id) ON DELETE CASCADE,
+	login VARCHAR(64),
+	event_type VARCHAR(64) NOT NULL,
+	method VARCHAR(255) NOT NULL,
+	target_id BIGINT,
+	personal_token_id INT,
+	success BOOLEAN NOT NULL,
+	status_code VARCHAR(32) NOT NULL,
+	client_ip VARCHAR(64) NOT NULL DEFAULT '',
+	user_agent TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+COMMENT ON TABLE public.audit_event IS 'Журнал аудита, записи только добавляются и удаляются вместе с пользователем';
+COMMENT ON COLUMN public.audit_event.id IS 'Идентификатор события';
+COMMENT ON COLUMN public.audit_event.user_id IS 'Пользователь, NULL - неизвестен';
+COMMENT ON COLUMN public.audit_event.login IS 'Логин из запроса входа';
+COMMENT ON COLUMN public.audit_event.event_type IS 'Тип события';
+COMMENT ON COLUMN public.audit_event.method IS 'Вызванный метод gRPC';
+COMMENT ON COLUMN public.audit_event.target_id IS 'Объект действия: запись, файл, сессия или токен';
+COMMENT ON COLUMN public.audit_event.personal_token_id IS 'Персональный токен, с которым выполнен вызов';
+COMMENT ON COLUMN public.audit_event.success IS 'Вызов завершился успешно';
+COMMENT ON COLUMN public.audit_event.status_code IS 'Код ответа gRPC';
+COMMENT ON COLUMN public.audit_event.client_ip IS 'IP адрес клиента';
+COMMENT ON COLUMN public.audit_event.user_agent IS 'User agent клиента';
+COMMENT ON COLUMN public.audit_event.created_at IS 'Время события';
+CREATE INDEX IF NOT EXISTS audit_event_user_id_idx ON audit_event (user_id, id);
+-- события не изменяются и не удаляются, кроме каскадного удаления вместе с пользователем:
+-- при каскадном удалении строки пользователя уже нет
+CREATE OR REPLACE FUNCTION audit_event_append_only() RETURNS TRIGGER AS $$
+BEGIN
+	IF TG_OP = 'DELETE' AND OLD.user_id IS NOT NULL
+		AND NOT EXISTS (SELECT 1 FROM users WHERE id = OLD.user_id) THEN
+		RETURN OLD;
+	END IF;
+	RAISE EXCEPTION 'audit_event is append-only';
+END;
+$$ LANGUAGE plpgsql;
+DROP TRIGGER IF EXISTS audit_event_append_only ON audit_event;
+CREATE TRIGGER audit_event_append_only BEFORE UPDATE OR DELETE ON audit_event
+	FOR EACH ROW EXECUTE FUNCTION audit_event_append_only();
+
+--BINARY_FILE
+CREATE TABLE IF NOT EXISTS binary_file (
+	id SERIAL PRIMARY KEY,
+	filename VARCHAR(255) NOT NULL,
+	mime_type VARCHAR(100),
+	original_size BIGINT NOT NULL,
+	chunk_size INTEGER NOT NULL,
+	total_chunks INTEGER NOT NULL,
+	description TEXT,
+	is_deleted BOOLEAN DEFAULT FALSE,
+	is_complete BOOLEAN DEFAULT FALSE,
+	user_id INTEGER NOT NULL REFERENCES users(id),
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	updated_at TIMESTAMP DEFAULT NOW()
+);
+COMMENT ON COLUMN public.binary_file.id IS 'Идентификатор токена';
+COMMENT ON COLUMN public.binary_file.filename IS 'Название, зашифровано ключом пользователя';
+COMMENT ON COLUMN public.binary_file.mime_type IS 'Тип, зашифрован ключом пользователя';
+COMMENT ON COLUMN public.binary_file.original_size IS 'Размер';
+COMMENT ON COLUMN public.binary_file.chunk_size IS 'Размер части';
+COMMENT ON COLUMN public.binary_file.total_chunks IS 'Количество частей';
+COMMENT ON COLUMN public.binary_file.description IS 'Описание, зашифровано ключом пользователя';
+COMMENT ON COLUMN public.binary_file.is_deleted IS 'Мягкое удаление';
+COMMENT ON COLUMN public.binary_file.is_complete IS 'Закачанный файл?';
+COMMENT ON COLUMN public.binary_file.user_id IS 'Пользователь';
+COMMENT ON COLUMN public.binary_file.created_at IS 'Дата создания';
+COMMENT ON COLUMN public.binary_file.updated_at IS 'Дата обновления';
+ALTER TABLE binary_file ADD COLUMN IF NOT EXISTS client_encrypted BOOLEAN DEFAULT FALSE;
+COMMENT ON COLUMN public.binary_file.client_encrypted IS 'Части файла зашифрованы на клиенте';
+ALTER TABLE binary_file ALTER COLUMN filename TYPE TEXT, ALTER COLUMN mime_type TYPE TEXT;
+
+--BINARY_FILE_CHUNK
+CREATE TABLE IF NOT EXISTS binary_file_chunk (
+	id SERIAL PRIMARY KEY,
+	file_id INTEGER NOT NULL REFERENCES binary_file(id) ON DELETE CASCADE,
+	chunk_index INTEGER NOT NULL,
+	encrypted_data BYTEA NOT NULL,
+	encryption_algorithm VARCHAR(32) NOT NULL,
+	iv BYTEA NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	UNIQUE(file_id, chunk_index)
+);
+COMMENT ON COLUMN public.binary_file_chunk.id IS 'Идентификатор токена';
+COMMENT ON COLUMN public.binary_file_chunk.file_id IS 'Хеш токена';
+COMMENT ON COLUMN public.binary_file_chunk.chunk_index IS 'Публичный идентификатор';
+COMMENT ON COLUMN public.binary_file_chunk.encrypted_data IS 'Зашифрованные данные';
+COMMENT ON COLUMN public.binary_file_chunk.encryption_algorithm IS 'Алгоритм шифрования';
+COMMENT ON COLUMN public.binary_file_chunk.iv IS 'Вектор инициализации';
+COMMENT ON COLUMN public.binary_file_chunk.created_at IS 'Дата создания';
+ALTER TABLE binary_file_chunk ADD COLUMN IF NOT EXISTS key_version INT NOT NULL DEFAULT 1;
+COMMENT ON COLUMN public.binary_file_chunk.key_version IS 'Версия мастер-ключа';
+ALTER TABLE binary_file_chunk ADD COLUMN IF NOT EXISTS is_aad_bound BOOLEAN NOT NULL DEFAULT FALSE;
+COMMENT ON COLUMN public.binary_file_chunk.is_aad_bound IS 'Шифротекст привязан к файлу и номеру части (AAD)';
+ALTER TABLE binary_file_chunk ADD COLUMN IF NOT EXISTS is_stream_bound BOOLEAN NOT NULL DEFAULT FALSE;
+COMMENT ON COLUMN public.binary_file_chunk.is_stream_bound IS 'Шифротекст привязан к признаку последней части (STREAM)';
+
+--BINARY_FILE_METADATA
+CREATE TABLE IF NOT EXISTS binary_file_metadata (
+	id SERIAL PRIMARY KEY,
+	file_id INT NOT NULL REFERENCES binary_file(id) ON DELETE CASCADE,
+	name VARCHAR(128) NOT NULL,
+	value TEXT,
+	created_at TIMESTAMP DEFAULT NOW(),
+	updated_at TIMESTAMP DEFAULT NOW()
+);
+COMMENT ON COLUMN public.binary_file_metadata.id IS 'Идентификатор записи';
+COMMENT ON COLUMN public.binary_file_metadata.file_id IS 'Связь с данными';
+COMMENT ON COLUMN public.binary_file_metadata.name IS 'Название метаданных, зашифровано ключом пользователя';
+COMMENT ON COLUMN public.binary_file_metadata.value IS 'Значение метаданных, зашифровано ключом пользователя';
+COMMENT ON COLUMN public.binary_file_metadata.created_at IS 'Дата создания';
+COMMENT ON COLUMN public.binary_file_metadata.updated_at IS 'Дата обновления';
+ALTER TABLE binary_file_metadata ALTER COLUMN name TYPE TEXT;
@@ -0,0 +1,15 @@
+-- Идентификаторы записей и файлов, зарезервированные клиентом
+-- при сквозном шифровании клиент привязывает шифротекст к идентификатору до создания записи
+
+--RESERVED_ID
+CREATE TABLE IF NOT EXISTS reserved_id (
+	kind VARCHAR(16) NOT NULL,
+	id BIGINT NOT NULL,
+	user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	expires_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (kind, id)
+);
+COMMENT ON COLUMN public.reserved_id.kind IS 'Вид объекта: item - запись, file - файл';
+COMMENT ON COLUMN public.reserved_id.id IS 'Зарезервированный идентификатор';
+COMMENT ON COLUMN public.reserved_id.user_id IS 'Пользователь';
+COMMENT ON COLUMN public.reserved_id.expires_at IS 'Срок действия резерва';
@@ -0,0 +1,264 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/account"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/user"
+	"github.com/ramil063/secondgodiplom/internal/hash"
+	"github.com/ramil063/secondgodiplom/internal/logger"
+)
+
+// Account управление учетной записью пользователя
+type Account struct {
+	DB *DB
+}
+
+// purgeQueries удаление данных пользователя, параметр ?1 - идентификатор пользователя
+// последним удаляется сам пользователь, вместе с ним каскадно удаляются ключ данных, второй фактор и события безопасности
+var purgeQueries = []string{
+	"DELETE FROM binary_file_chunk WHERE file_id IN (SELECT id FROM binary_file WHERE user_id = ?1)",
+	"DELETE FROM binary_file_metadata WHERE file_id IN (SELECT id FROM binary_file WHERE user_id = ?1)",
+	"DELETE FROM binary_file WHERE user_id = ?1",
+	"DELETE FROM item_metadata WHERE item_id IN (SELECT id FROM encrypted_item WHERE user_id = ?1)",
+	"DELETE FROM encrypted_item WHERE user_id = ?1",
+	"DELETE FROM oauth_refresh_token WHERE access_token_id IN (SELECT id FROM oauth_access_token WHERE user_id = ?1)",
+	"DELETE FROM oauth_access_token WHERE user_id = ?1",
+	"DELETE FROM users WHERE id = ?1",
+}
+
+// GetAccount получение учетных данных пользователя
+func (s *Account) GetAccount(ctx context.Context, userID int) (*user.User, error) {
+	row := s.DB.conn(ctx).QueryRowContext(
+		ctx,
+		"SELECT id, login, password_hash, kdf_salt, wrapped_vault_key FROM users WHERE id = ?1",
+		userID)
+
+	var passwordHash []byte
+	u := &user.User{}
+	err := row.Scan(&u.ID, &u.Login, &passwordHash, &u.KdfSalt, &u.WrappedVaultKey)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	u.PasswordHash = string(passwordHash)
+	return u, nil
+}
+
+// ChangePassword смена пароля в одной транзакции:
+// новый хеш пароля и обернутый ключ хранилища, отзыв токенов остальных сессий и событие безопасности
+// возвращает число отозванных сессий
+func (s *Account) ChangePassword(ctx context.Context, change *account.PasswordChange) (int64, error) {
+	var revoked int64
+	err := s.DB.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.updatePassword(ctx, change); err != nil {
+			return err
+		}
+
+		err := revokeAccessTokens(
+			ctx,
+			s.DB.conn(ctx),
+			"user_id = ?1 AND token_hash <> ?2",
+			&revoked,
+			change.UserID,
+			hash.GetTokenHash(change.CurrentAccessToken))
+		if err != nil {
+			return errors.New("ChangePassword error in revoke tokens")
+		}
+
+		if err = saveSecurityEvent(ctx, s.DB.conn(ctx), change.Event); err != nil {
+			return errors.New("ChangePassword error in save security event")
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return revoked, nil
+}
+
+// updatePassword запись нового хеша пароля
+// если включено сквозное шифрование, вместе с ним меняется обернутый ключ хранилища
+// пароль не меняется, если его успели сменить или изменилось состояние сквозного шифрования
+func (s *Account) updatePassword(ctx context.Context, change *account.PasswordChange) error {
+	var query string
+	args := []interface{}{change.UserID, change.OldPasswordHash, change.NewPasswordHash, now()}
+	if len(change.WrappedVaultKey) > 0 {
+		query = `UPDATE users SET password_hash = ?3, kdf_salt = ?5, wrapped_vault_key = ?6, updated_at = ?4
+			WHERE id = ?1 AND password_hash = ?2 AND wrapped_vault_key IS NOT NULL`
+		args = append(args, change.KdfSalt, change.WrappedVaultKey)
+	} else {
+		query = `UPDATE users SET password_hash = ?3, updated_at = ?4
+			WHERE id = ?1 AND password_hash = ?2 AND wrapped_vault_key IS NULL`
+	}
+
+	exec, err := s.DB.conn(ctx).ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.New("ChangePassword error in update password")
+	}
+	if rows, err := exec.RowsAffected(); err != nil || rows != 1 {
+		logger.WriteErrorLog("ChangePassword error expected to affect 1 row")
+		return status.Error(codes.Aborted, "account changed concurrently")
+	}
+	return nil
+}
+
+// GetAccountByLogin получение учетных данных пользователя по логину, в том числе деактивированного
+func (s *Account) GetAccountByLogin(ctx context.Context, login string) (*user.User, error) {
+	row := s.DB.conn(ctx).QueryRowContext(
+		ctx,
+		"SELECT id, login, password_hash, is_active, deletion_scheduled_at FROM users WHERE login = ?1",
+		login)
+
+	var passwordHash []byte
+	u := &user.User{}
+	err := row.Scan(&u.ID, &u.Login, &passwordHash, &u.IsActive, &u.DeletionScheduledAt)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	u.PasswordHash = string(passwordHash)
+	return u, nil
+}
+
+// DeleteAccount немедленное удаление пользователя со всеми его данными и токенами
+func (s *Account) DeleteAccount(ctx context.Context, userID int) error {
+	return s.purge(ctx, userID, "SELECT id FROM users WHERE id = ?1")
+}
+
+// DeactivateAccount отложенное удаление: учетная запись деактивируется до даты удаления,
+// токены всех сессий отзываются, записывается событие безопасности
+func (s *Account) DeactivateAccount(ctx context.Context, deactivation *account.Deactivation) error {
+	return s.DB.WithTx(ctx, func(ctx context.Context) error {
+		exec, err := s.DB.conn(ctx).ExecContext(
+			ctx,
+			`UPDATE users SET is_active = FALSE, deletion_scheduled_at = ?2, updated_at = ?3
+				WHERE id = ?1 AND is_active = TRUE`,
+			deactivation.UserID,
+			deactivation.PurgeAt.UTC(),
+			now())
+		if err != nil {
+			return errors.New("DeactivateAccount error in update user")
+		}
+		if rows, err := exec.RowsAffected(); err != nil || rows != 1 {
+			logger.WriteErrorLog("DeactivateAccount error expected to affect 1 row")
+			return status.Error(codes.FailedPrecondition, "account is already deactivated")
+		}
+
+		var revoked int64
+		if err = revokeAccessTokens(ctx, s.DB.conn(ctx), "user_id = ?1", &revoked, deactivation.UserID); err != nil {
+			return errors.New("DeactivateAccount error in revoke tokens")
+		}
+
+		if err = saveSecurityEvent(ctx, s.DB.conn(ctx), deactivation.Event); err != nil {
+			return errors.New("DeactivateAccount error in save security event")
+		}
+		return nil
+	})
+}
+
+// RestoreAccount отмена отложенного удаления, пока не наступила дата удаления
+func (s *Account) RestoreAccount(ctx context.Context, userID int, event account.SecurityEvent) error {
+	return s.DB.WithTx(ctx, func(ctx context.Context) error {
+		exec, err := s.DB.conn(ctx).ExecContext(
+			ctx,
+			`UPDATE users SET is_active = TRUE, deletion_scheduled_at = NULL, updated_at = ?2
+				WHERE id = ?1 AND is_active = FALSE AND deletion_scheduled_at > ?2`,
+			userID,
+			now())
+		if err != nil {
+			return errors.New("RestoreAccount error in update user")
+		}
+		if rows, err := exec.RowsAffected(); err != nil || rows != 1 {
+			logger.WriteErrorLog("RestoreAccount error expected to affect 1 row")
+			return status.Error(codes.FailedPrecondition, "account is not scheduled for deletion")
+		}
+
+		if err = saveSecurityEvent(ctx, s.DB.conn(ctx), event); err != nil {
+			return errors.New("RestoreAccount error in save security event")
+		}
+		return nil
+	})
+}
+
+// PurgeDeletedAccounts удаление данных учетных записей, у которых истек срок восстановления
+// возвращает число удаленных учетных записей
+func (s *Account) PurgeDeletedAccounts(ctx context.Context) (int, error) {
+	rows, err := s.DB.conn(ctx).QueryContext(
+		ctx,
+		"SELECT id FROM users WHERE is_active = FALSE AND deletion_scheduled_at <= ?1",
+		now())
+	if err != nil {
+		return 0, errors.New("PurgeDeletedAccounts error in select users")
+	}
+
+	var userIDs []int
+	for rows.Next() {
+		var userID int
+		if err = rows.Scan(&userID); err != nil {
+			_ = rows.Close()
+			return 0, errors.New("PurgeDeletedAccounts error in scan user")
+		}
+		userIDs = append(userIDs, userID)
+	}
+	_ = rows.Close()
+	if rows.Err() != nil {
+		return 0, errors.New("PurgeDeletedAccounts error in rows")
+	}
+
+	purged := 0
+	for _, userID := range userIDs {
+		// Пользователь мог восстановить учетную запись после выборки
+		err = s.purge(
+			ctx,
+			userID,
+			"SELECT id FROM users WHERE id = ?1 AND is_active = FALSE AND deletion_scheduled_at <= ?2",
+			now())
+		if status.Code(err) == codes.NotFound {
+			continue
+		}
+		if err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// purge удаление данных пользователя в одной транзакции
+// checkQuery проверяет, что пользователя можно удалить, ?1 - идентификатор пользователя, остальные параметры - args
+func (s *Account) purge(ctx context.Context, userID int, checkQuery string, args ...interface{}) error {
+	return s.DB.WithTx(ctx, func(ctx context.Context) error {
+		var id int
+		err := s.DB.conn(ctx).QueryRowContext(ctx, checkQuery, append([]interface{}{userID}, args...)...).Scan(&id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return status.Error(codes.NotFound, "account not found")
+		}
+		if err != nil {
+			return errors.New("purge account error in check user")
+		}
+
+		for _, query := range purgeQueries {
+			if _, err = s.DB.conn(ctx).ExecContext(ctx, query, userID); err != nil {
+				logger.WriteErrorLog(err.Error())
+				return errors.New("purge account error in delete data")
+			}
+		}
+		return nil
+	})
+}
+
+// saveSecurityEvent запись события безопасности учетной записи
+func saveSecurityEvent(ctx context.Context, q querier, event account.SecurityEvent) error {
+	_, err := q.ExecContext(
+		ctx,
+		"INSERT INTO security_event (user_id, event_type, client_ip, user_agent) VALUES (?1, ?2, ?3, ?4)",
+		event.UserID,
+		event.Type,
+		event.ClientIP,
+		event.UserAgent)
+	return err
+}
@@ -0,0 +1,122 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/account"
+)
+
+func TestAccount_ChangePassword(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDB(t)
+	userID := createUser(t, d, "user")
+	s := &Account{DB: d}
+	authStorage := &Auth{DB: d}
+	login(t, authStorage, userID, "current", "refresh1", "family1")
+	login(t, authStorage, userID, "other", "refresh2", "family2")
+
+	change := &account.PasswordChange{
+		UserID:             userID,
+		OldPasswordHash:    "hash",
+		NewPasswordHash:    "new-hash",
+		CurrentAccessToken: "current",
+		Event:              account.SecurityEvent{UserID: userID, Type: account.EventPasswordChanged},
+	}
+	revoked, err := s.ChangePassword(ctx, change)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), revoked)
+
+	active, err := authStorage.IsAccessTokenActive(ctx, "current")
+	require.NoError(t, err)
+	assert.True(t, active)
+	active, err = authStorage.IsAccessTokenActive(ctx, "other")
+	require.NoError(t, err)
+	assert.False(t, active)
+
+	got, err := s.GetAccount(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, "new-hash", got.PasswordHash)
+
+	// пароль уже сменен, событие безопасности не записывается
+	_, err = s.ChangePassword(ctx, change)
+	assert.Equal(t, codes.Aborted, status.Code(err))
+
+	var events int
+	require.NoError(t, d.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM security_event`).Scan(&events))
+	assert.Equal(t, 1, events)
+}
+
+func TestAccount_DeactivateAccount(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDB(t)
+	userID := createUser(t, d, "user")
+	s := &Account{DB: d}
+	authStorage := &Auth{DB: d}
+	login(t, authStorage, userID, "access", "refresh", "family")
+
+	deactivation := &account.Deactivation{
+		UserID:  userID,
+		PurgeAt: time.Now().Add(time.Hour),
+		Event:   account.SecurityEvent{UserID: userID, Type: account.EventAccountDeactivated},
+	}
+	require.NoError(t, s.DeactivateAccount(ctx, deactivation))
+	err := s.DeactivateAccount(ctx, deactivation)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	active, err := authStorage.IsAccessTokenActive(ctx, "access")
+	require.NoError(t, err)
+	assert.False(t, active)
+
+	got, err := s.GetAccountByLogin(ctx, "user")
+	require.NoError(t, err)
+	assert.False(t, got.IsActive)
+	require.NotNil(t, got.DeletionScheduledAt)
+
+	purged, err := s.PurgeDeletedAccounts(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, purged)
+
+	event := account.SecurityEvent{UserID: userID, Type: account.EventAccountRestored}
+	require.NoError(t, s.RestoreAccount(ctx, userID, event))
+	err = s.RestoreAccount(ctx, userID, event)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestAccount_PurgeDeletedAccounts(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDB(t)
+	userID := createUser(t, d, "user")
+	keptID := createUser(t, d, "kept")
+	s := &Account{DB: d}
+	login(t, &Auth{DB: d}, userID, "access", "refresh", "family")
+	_, err := (&Item{DB: d}).SaveEncryptedData(ctx, testItem(userID, 0))
+	require.NoError(t, err)
+
+	deactivation := &account.Deactivation{
+		UserID:  userID,
+		PurgeAt: time.Now().Add(-time.Second),
+		Event:   account.SecurityEvent{UserID: userID, Type: account.EventAccountDeactivated},
+	}
+	require.NoError(t, s.DeactivateAccount(ctx, deactivation))
+
+	purged, err := s.PurgeDeletedAccounts(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	_, err = s.GetAccount(ctx, userID)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	var items int
+	require.NoError(t, d.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM encrypted_item`).Scan(&items))
+	assert.Equal(t, 0, items)
+
+	require.NoError(t, s.DeleteAccount(ctx, keptID))
+	err = s.DeleteAccount(ctx, keptID)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,123 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/audit"
+	"github.com/ramil063/secondgodiplom/internal/logger"
+)
+
+// Audit журнал аудита действий пользователей, записи журнала не изменяются
+type Audit struct {
+	DB *DB
+}
+
+// SaveAuditEvent добавление события в журнал аудита
+// если пользователь уже удален или не передан, он определяется по логину из запроса входа
+func (s *Audit) SaveAuditEvent(ctx context.Context, event *audit.Event) error {
+	_, err := s.DB.conn(ctx).ExecContext(
+		ctx,
+		`INSERT INTO audit_event (user_id, login, event_type, method, target_id, personal_token_id,
+				success, status_code, client_ip, user_agent, created_at)
+			VALUES (
+				COALESCE(
+					(SELECT id FROM users WHERE id = ?1),
+					(SELECT id FROM users WHERE login = NULLIF(?2, ''))
+				),
+				NULLIF(?2, ''), ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10, ?11
+			)`,
+		event.UserID,
+		event.Login,
+		event.Type,
+		event.Method,
+		event.TargetID,
+		event.PersonalTokenID,
+		event.Success,
+		event.StatusCode,
+		event.ClientIP,
+		event.UserAgent,
+		now())
+	if err != nil {
+		logger.WriteErrorLog(err.Error())
+		return errors.New("SaveAuditEvent error in sql")
+	}
+	return nil
+}
+
+// ListAuditEvents события пользователя от новых к старым с учетом фильтра
+func (s *Audit) ListAuditEvents(ctx context.Context, filter *audit.Filter) ([]audit.Event, error) {
+	conditions := []string{"user_id = ?1"}
+	args := []interface{}{filter.UserID}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if len(filter.Types) > 0 {
+		conditions = append(conditions, "event_type IN ("+placeholders(len(args)+1, len(filter.Types))+")")
+		for _, eventType := range filter.Types {
+			args = append(args, eventType)
+		}
+	}
+	if filter.TargetID > 0 {
+		addCondition("target_id = ?%d", filter.TargetID)
+	}
+	if filter.Since != nil {
+		addCondition("created_at >= ?%d", filter.Since.UTC())
+	}
+	if filter.Until != nil {
+		addCondition("created_at < ?%d", filter.Until.UTC())
+	}
+	if filter.FailedOnly {
+		conditions = append(conditions, "success = FALSE")
+	}
+	if filter.BeforeID > 0 {
+		addCondition("id < ?%d", filter.BeforeID)
+	}
+	args = append(args, filter.Limit)
+
+	rows, err := s.DB.conn(ctx).QueryContext(
+		ctx,
+		fmt.Sprintf(`SELECT id, user_id, COALESCE(login, ''), event_type, method, target_id, personal_token_id,
+				success, status_code, client_ip, user_agent, created_at
+			FROM audit_event
+			WHERE %s
+			ORDER BY id DESC
+			LIMIT ?%d`, strings.Join(conditions, " AND "), len(args)),
+		args...)
+	if err != nil {
+		logger.WriteErrorLog(err.Error())
+		return nil, errors.New("ListAuditEvents error in sql")
+	}
+	defer rows.Close()
+
+	var events []audit.Event
+	for rows.Next() {
+		var event audit.Event
+		err = rows.Scan(
+			&event.ID,
+			&event.UserID,
+			&event.Login,
+			&event.Type,
+			&event.Method,
+			&event.TargetID,
+			&event.PersonalTokenID,
+			&event.Success,
+			&event.StatusCode,
+			&event.ClientIP,
+			&event.UserAgent,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, errors.New("ListAuditEvents error in scan")
+		}
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.New("ListAuditEvents error in rows")
+	}
+	return events, nil
+}
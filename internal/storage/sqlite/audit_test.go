@@ -0,0 +1,88 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/audit"
+)
+
+func TestAudit_ListAuditEvents(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDB(t)
+	userID := createUser(t, d, "user")
+	s := &Audit{DB: d}
+	targetID := int64(5)
+
+	events := []*audit.Event{
+		{UserID: &userID, Type: audit.EventLogin, Method: "/auth.Auth/Login", Success: true, StatusCode: "OK"},
+		{Login: "user", Type: audit.EventLogin, Method: "/auth.Auth/Login", StatusCode: "Unauthenticated"},
+		{UserID: &userID, Type: audit.EventItemRead, Method: "/items.Items/Get", TargetID: &targetID, Success: true, StatusCode: "OK"},
+		{Login: "unknown", Type: audit.EventLogin, Method: "/auth.Auth/Login", StatusCode: "Unauthenticated"},
+	}
+	for _, event := range events {
+		require.NoError(t, s.SaveAuditEvent(ctx, event))
+	}
+	since := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name      string
+		filter    audit.Filter
+		wantTypes []string
+	}{
+		{
+			name:      "all",
+			filter:    audit.Filter{Limit: audit.DefaultPageSize},
+			wantTypes: []string{audit.EventItemRead, audit.EventLogin, audit.EventLogin},
+		},
+		{
+			name:      "by type and target",
+			filter:    audit.Filter{Types: []string{audit.EventItemRead, audit.EventLogout}, TargetID: targetID, Limit: 10},
+			wantTypes: []string{audit.EventItemRead},
+		},
+		{
+			name:      "failed only",
+			filter:    audit.Filter{FailedOnly: true, Since: &since, Limit: 10},
+			wantTypes: []string{audit.EventLogin},
+		},
+		{
+			name:      "page",
+			filter:    audit.Filter{BeforeID: 3, Limit: 1},
+			wantTypes: []string{audit.EventLogin},
+		},
+		{
+			name:      "until",
+			filter:    audit.Filter{Until: &since, Limit: 10},
+			wantTypes: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.UserID = userID
+			got, err := s.ListAuditEvents(ctx, &tt.filter)
+			require.NoError(t, err)
+			var gotTypes []string
+			for _, event := range got {
+				assert.Equal(t, userID, *event.UserID)
+				gotTypes = append(gotTypes, event.Type)
+			}
+			assert.Equal(t, tt.wantTypes, gotTypes)
+		})
+	}
+
+	_, err := d.db.ExecContext(ctx, `UPDATE audit_event SET success = TRUE`)
+	assert.Error(t, err)
+	_, err = d.db.ExecContext(ctx, `DELETE FROM audit_event`)
+	assert.Error(t, err)
+
+	// события удаляются только вместе с пользователем, событие неизвестного логина остается
+	_, err = d.db.ExecContext(ctx, `DELETE FROM users WHERE id = ?1`, userID)
+	require.NoError(t, err)
+	var count int
+	require.NoError(t, d.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM audit_event`).Scan(&count))
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,266 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/account"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/user"
+	internalErrors "github.com/ramil063/secondgodiplom/internal/errors"
+	"github.com/ramil063/secondgodiplom/internal/hash"
+)
+
+// login вход пользователя: токен авторизации и refresh token семейства family
+func login(t *testing.T, s *Auth, userID int, accessToken, refreshToken, family string) int {
+	t.Helper()
+	ctx := context.Background()
+	accessTokenID, err := s.SaveAccessToken(ctx, userID, accessToken, auth.SessionInfo{ClientIP: "127.0.0.1", UserAgent: "cli"})
+	require.NoError(t, err)
+	require.NoError(t, s.SaveRefreshToken(ctx, accessTokenID, refreshToken, family))
+	return accessTokenID
+}
+
+func TestReg_RegisterUser(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDB(t)
+	s := &Reg{DB: d}
+
+	userID, err := s.RegisterUser(ctx, &user.User{Login: "user", PasswordHash: "hash", KdfSalt: []byte("salt")})
+	require.NoError(t, err)
+	assert.Positive(t, userID)
+
+	_, err = s.RegisterUser(ctx, &user.User{Login: "user", PasswordHash: "hash"})
+	assert.ErrorIs(t, err, internalErrors.ErrUniqueViolation)
+
+	got, err := (&Auth{DB: d}).GetUserByLogin(ctx, "user")
+	require.NoError(t, err)
+	assert.Equal(t, userID, got.ID)
+	assert.Equal(t, "hash", got.PasswordHash)
+	assert.True(t, got.IsActive)
+	assert.Equal(t, []byte("salt"), got.KdfSalt)
+	assert.Nil(t, got.WrappedVaultKey)
+}
+
+func TestAuth_SaveClientKeys(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDB(t)
+	userID := createUser(t, d, "user")
+	s := &Auth{DB: d}
+
+	require.NoError(t, s.SaveClientKeys(ctx, userID, []byte("salt"), []byte("vault")))
+	err := s.SaveClientKeys(ctx, userID, []byte("salt2"), []byte("vault2"))
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	got, err := s.GetUserByID(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("vault"), got.WrappedVaultKey)
+
+	_, err = s.GetUserByID(ctx, userID+1)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestAuth_RefreshTokenRotation(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDB(t)
+	userID := createUser(t, d, "user")
+	s := &Auth{DB: d}
+	login(t, s, userID, "access1", "refresh1", "family")
+
+	token, err := s.GetRefreshToken(ctx, "refresh1")
+	require.NoError(t, err)
+	assert.Equal(t, userID, token.UserID)
+	assert.Equal(t, "family", token.FamilyID)
+	assert.False(t, token.IsRevoked)
+	assert.False(t, token.SessionCreated.IsZero())
+	assert.True(t, token.ExpiresAt.After(time.Now()))
+
+	require.NoError(t, s.RevokeRefreshToken(ctx, "refresh1"))
+	err = s.RevokeRefreshToken(ctx, "refresh1")
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	token, err = s.GetRefreshToken(ctx, "refresh1")
+	require.NoError(t, err)
+	assert.True(t, token.IsRevoked)
+	assert.True(t, token.IsRotated)
+
+	active, err := s.IsAccessTokenActive(ctx, "access1")
+	require.NoError(t, err)
+	assert.False(t, active)
+
+	login(t, s, userID, "access2", "refresh2", "family")
+	login(t, s, userID, "access3", "refresh3", "other")
+	revoked, err := s.RevokeTokenFamily(ctx, "family", account.SecurityEvent{UserID: userID, Type: account.EventRefreshTokenReuse})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), revoked)
+
+	active, err = s.IsAccessTokenActive(ctx, "access2")
+	require.NoError(t, err)
+	assert.False(t, active)
+	active, err = s.IsAccessTokenActive(ctx, "access3")
+	require.NoError(t, err)
+	assert.True(t, active)
+
+	_, err = s.GetRefreshToken(ctx, "unknown")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestAuth_Sessions(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDB(t)
+	userID := createUser(t, d, "user")
+	s := &Auth{DB: d}
+	current := login(t, s, userID, "access1", "refresh1", "family1")
+	other := login(t, s, userID, "access2", "refresh2", "family2")
+
+	active, err := s.IsAccessTokenActive(ctx, "access2")
+	require.NoError(t, err)
+	require.True(t, active)
+
+	sessions, err := s.ListSessions(ctx, userID, "access1")
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, other, sessions[0].ID)
+	assert.NotNil(t, sessions[0].LastUsedAt)
+	assert.False(t, sessions[0].Current)
+	assert.Equal(t, current, sessions[1].ID)
+	assert.True(t, sessions[1].Current)
+	assert.Equal(t, "cli", sessions[1].UserAgent)
+
+	tokenHash, err := s.RevokeSession(ctx, userID, other)
+	require.NoError(t, err)
+	assert.Equal(t, hash.GetTokenHash("access2"), tokenHash)
+	_, err = s.RevokeSession(ctx, userID, other)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	err = s.RevokeAccessToken(ctx, userID, "access2")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	require.NoError(t, s.RevokeAccessToken(ctx, userID, "access1"))
+
+	login(t, s, userID, "access3", "refresh3", "family3")
+	revoked, err := s.RevokeUserTokens(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), revoked)
+
+	sessions, err = s.ListSessions(ctx, userID, "access3")
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+}
+
+func TestAuth_TOTP(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDB(t)
+	userID := createUser(t, d, "user")
+	s := &Auth{DB: d}
+
+	got, err := s.GetTOTP(ctx, userID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	totp := &auth.TOTP{EncryptedSecret: []byte("secret"), IV: []byte("iv"), Algorithm: "AES-256-GCM", KeyVersion: 1}
+	require.NoError(t, s.SaveTOTP(ctx, userID, totp, []string{"code1", "code2"}))
+	totp.EncryptedSecret = []byte("secret2")
+	require.NoError(t, s.SaveTOTP(ctx, userID, totp, []string{"code3"}))
+	require.NoError(t, s.ConfirmTOTP(ctx, userID, 10))
+
+	err = s.SaveTOTP(ctx, userID, totp, []string{"code4"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	err = s.ConfirmTOTP(ctx, userID, 11)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	got, err = s.GetTOTP(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret2"), got.EncryptedSecret)
+	assert.True(t, got.Confirmed)
+	assert.Equal(t, int64(10), got.LastUsedStep)
+
+	used, err := s.UseTOTPStep(ctx, userID, 10)
+	require.NoError(t, err)
+	assert.False(t, used)
+	used, err = s.UseTOTPStep(ctx, userID, 11)
+	require.NoError(t, err)
+	assert.True(t, used)
+
+	used, err = s.UseRecoveryCode(ctx, userID, "code1")
+	require.NoError(t, err)
+	assert.False(t, used)
+	used, err = s.UseRecoveryCode(ctx, userID, "code3")
+	require.NoError(t, err)
+	assert.True(t, used)
+	used, err = s.UseRecoveryCode(ctx, userID, "code3")
+	require.NoError(t, err)
+	assert.False(t, used)
+}
+
+func TestAuth_LoginChallenge(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDB(t)
+	userID := createUser(t, d, "user")
+	s := &Auth{DB: d}
+
+	require.NoError(t, s.SaveLoginChallenge(ctx, userID, "challenge"))
+	for i := 0; i < auth.TOTPChallengeMaxAttempts; i++ {
+		got, err := s.UseLoginChallengeAttempt(ctx, "challenge")
+		require.NoError(t, err)
+		assert.Equal(t, userID, got)
+	}
+	_, err := s.UseLoginChallengeAttempt(ctx, "challenge")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	require.NoError(t, s.CompleteLoginChallenge(ctx, "challenge"))
+	err = s.CompleteLoginChallenge(ctx, "challenge")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestAuth_LoginAttempts(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDB(t)
+	s := &Auth{DB: d}
+
+	attempts, err := s.GetLoginAttempts(ctx, []string{"login:user"})
+	require.NoError(t, err)
+	assert.Empty(t, attempts)
+
+	for i := 0; i < 3; i++ {
+		reserved, err := s.ReserveLoginAttempt(ctx, "login:user", 3, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, reserved)
+	}
+	// после исчерпания попыток вход заблокирован, счетчик не растет
+	reserved, err := s.ReserveLoginAttempt(ctx, "login:user", 3, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, reserved)
+	for i := 0; i < 3; i++ {
+		reserved, err = s.ReserveLoginAttempt(ctx, "ip:127.0.0.1", 3, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, reserved)
+	}
+	// снятая попытка успешного входа снимает и блокировку
+	require.NoError(t, s.ReleaseLoginAttempt(ctx, "ip:127.0.0.1"))
+
+	attempts, err = s.GetLoginAttempts(ctx, []string{"login:user", "ip:127.0.0.1"})
+	require.NoError(t, err)
+	require.Len(t, attempts, 2)
+	for _, attempt := range attempts {
+		switch attempt.Subject {
+		case "login:user":
+			assert.Equal(t, 3, attempt.Failures)
+			require.NotNil(t, attempt.LockedUntil)
+			assert.True(t, attempt.LockedUntil.After(time.Now()))
+		case "ip:127.0.0.1":
+			assert.Equal(t, 2, attempt.Failures)
+			assert.Nil(t, attempt.LockedUntil)
+		}
+	}
+
+	require.NoError(t, s.ResetLoginFailures(ctx, "login:user"))
+	attempts, err = s.GetLoginAttempts(ctx, []string{"login:user"})
+	require.NoError(t, err)
+	assert.Empty(t, attempts)
+}
@@ -0,0 +1,104 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	modelAuth "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+)
+
+// GetLoginAttempts неудачные попытки входа по логину и IP адресу, записи без неудач не возвращаются
+func (s *Auth) GetLoginAttempts(ctx context.Context, subjects []string) ([]modelAuth.LoginAttempts, error) {
+	if len(subjects) == 0 {
+		return nil, nil
+	}
+
+	args := make([]interface{}, 0, len(subjects))
+	for _, subject := range subjects {
+		args = append(args, subject)
+	}
+	rows, err := s.DB.conn(ctx).QueryContext(
+		ctx,
+		`SELECT subject, failures, last_failure_at, locked_until FROM login_attempt
+			WHERE subject IN (`+placeholders(1, len(subjects))+`)`,
+		args...)
+	if err != nil {
+		return nil, errors.New("GetLoginAttempts error in sql query")
+	}
+	defer rows.Close()
+
+	var attempts []modelAuth.LoginAttempts
+	for rows.Next() {
+		var attempt modelAuth.LoginAttempts
+		err = rows.Scan(&attempt.Subject, &attempt.Failures, &attempt.LastFailureAt, &attempt.LockedUntil)
+		if err != nil {
+			return nil, errors.New("GetLoginAttempts error in scan")
+		}
+		attempts = append(attempts, attempt)
+	}
+	if rows.Err() != nil {
+		return nil, errors.New("GetLoginAttempts error in rows")
+	}
+	return attempts, nil
+}
+
+// ReserveLoginAttempt учет попытки входа до проверки пароля, попытка считается неудачной,
+// пока вход не завершится успешно; возвращает false, если попытки исчерпаны и вход заблокирован
+// неудачи старше lockout забываются, после maxFailures попыток подряд вход блокируется на lockout
+func (s *Auth) ReserveLoginAttempt(ctx context.Context, subject string, maxFailures int, lockout time.Duration) (bool, error) {
+	currentTime := now()
+	var failures int
+	err := s.DB.conn(ctx).QueryRowContext(
+		ctx,
+		`INSERT INTO login_attempt (subject, failures, last_failure_at, locked_until)
+			VALUES (?1, 1, ?3, CASE WHEN ?2 <= 1 THEN ?5 END)
+			ON CONFLICT (subject) DO UPDATE SET
+				failures = CASE
+					WHEN login_attempt.last_failure_at < ?4 THEN 1
+					ELSE login_attempt.failures + 1
+				END,
+				last_failure_at = ?3,
+				locked_until = CASE
+					WHEN login_attempt.last_failure_at >= ?4
+						AND login_attempt.failures + 1 >= ?2 THEN ?5
+					ELSE login_attempt.locked_until
+				END
+				WHERE login_attempt.locked_until IS NULL OR login_attempt.locked_until <= ?3
+			RETURNING failures`,
+		subject,
+		maxFailures,
+		currentTime,
+		currentTime.Add(-lockout),
+		currentTime.Add(lockout)).Scan(&failures)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.New("ReserveLoginAttempt error in sql query")
+	}
+	return true, nil
+}
+
+// ReleaseLoginAttempt снятие учтенной попытки после успешного входа
+// вместе с попыткой снимается и блокировка: без нее предел неудач не достигнут
+func (s *Auth) ReleaseLoginAttempt(ctx context.Context, subject string) error {
+	_, err := s.DB.conn(ctx).ExecContext(
+		ctx,
+		"UPDATE login_attempt SET failures = failures - 1, locked_until = NULL WHERE subject = ?1 AND failures > 0",
+		subject)
+	if err != nil {
+		return errors.New("ReleaseLoginAttempt error in sql query")
+	}
+	return nil
+}
+
+// ResetLoginFailures сброс неудачных попыток после успешного входа
+func (s *Auth) ResetLoginFailures(ctx context.Context, subject string) error {
+	_, err := s.DB.conn(ctx).ExecContext(ctx, "DELETE FROM login_attempt WHERE subject = ?1", subject)
+	if err != nil {
+		return errors.New("ResetLoginFailures error in sql query")
+	}
+	return nil
+}
@@ -0,0 +1,364 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/account"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	"github.com/ramil063/secondgodiplom/internal/hash"
+	"github.com/ramil063/secondgodiplom/internal/logger"
+)
+
+func (s *Auth) SaveAccessToken(ctx context.Context, userID int, token string, session auth.SessionInfo) (int, error) {
+	createdAt := now()
+	var sessionCreated *time.Time
+	if !session.CreatedAt.IsZero() {
+		sessionCreated = &session.CreatedAt
+	}
+
+	var accessTokenID int
+	err := s.DB.conn(ctx).QueryRowContext(
+		ctx,
+		`INSERT INTO oauth_access_token (token_hash, user_id, expires_at, client_ip, user_agent, session_created_at, created_at)
+			VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7) RETURNING id`,
+		hash.GetTokenHash(token),
+		userID,
+		createdAt.Add(time.Duration(auth.TokenExpiredSeconds)*time.Second),
+		session.ClientIP,
+		session.UserAgent,
+		nullTime(sessionCreated),
+		createdAt).Scan(&accessTokenID)
+	if err != nil {
+		logger.WriteErrorLog(err.Error())
+		return 0, errors.New("SaveAccessToken error in sql empty result")
+	}
+	return accessTokenID, nil
+}
+
+// SaveRefreshToken сохранение refresh token'а в семействе токенов
+// семейство создается при входе и передается от старого токена новому при каждом обновлении
+func (s *Auth) SaveRefreshToken(ctx context.Context, accessTokenID int, token string, familyID string) error {
+	createdAt := now()
+	exec, err := s.DB.conn(ctx).ExecContext(
+		ctx,
+		`INSERT INTO oauth_refresh_token (token_hash, access_token_id, expires_at, family_id, created_at)
+			VALUES (?1, ?2, ?3, ?4, ?5)`,
+		hash.GetTokenHash(token),
+		accessTokenID,
+		createdAt.Add(time.Duration(auth.TokenExpiredSeconds)*time.Second),
+		familyID,
+		createdAt)
+	if err != nil {
+		logger.WriteErrorLog(err.Error())
+		return errors.New("SaveRefreshToken error in sql")
+	}
+
+	rows, err := exec.RowsAffected()
+	if err != nil || rows != 1 {
+		logger.WriteErrorLog("SaveRefreshToken error expected to affect 1 row")
+		return errors.New("SaveRefreshToken expected to affect 1 row")
+	}
+	return nil
+}
+
+// GetRefreshToken получение refresh token'а, в том числе отозванного: повторное использование
+// обмененного токена означает его кражу. Для токенов, выданных до учета семейств, семейство - сам токен
+func (s *Auth) GetRefreshToken(ctx context.Context, refreshToken string) (*auth.RefreshToken, error) {
+	row := s.DB.conn(ctx).QueryRowContext(
+		ctx,
+		`SELECT
+				ort.token_hash,
+				oat.token_hash,
+				oat.user_id,
+				ort.expires_at,
+				oat.session_created_at,
+				oat.created_at,
+				COALESCE(ort.family_id, CAST(ort.id AS TEXT)),
+				ort.is_revoked,
+				ort.rotated_at IS NOT NULL
+			FROM oauth_refresh_token ort
+				LEFT JOIN oauth_access_token oat ON oat.id = ort.access_token_id
+			WHERE ort.token_hash = ?1
+			ORDER BY ort.created_at DESC
+			LIMIT 1`,
+		hash.GetTokenHash(refreshToken))
+
+	token := &auth.RefreshToken{}
+	var tokenHash []byte
+	var accessTokenHash []byte
+	var sessionCreated *time.Time
+	err := row.Scan(
+		&tokenHash,
+		&accessTokenHash,
+		&token.UserID,
+		&token.ExpiresAt,
+		&sessionCreated,
+		&token.SessionCreated,
+		&token.FamilyID,
+		&token.IsRevoked,
+		&token.IsRotated,
+	)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "token not found")
+	}
+
+	token.TokenHash = string(tokenHash)
+	token.AccessTokenHash = string(accessTokenHash)
+	// для токенов одной сессии сохраняется дата первого входа
+	if sessionCreated != nil {
+		token.SessionCreated = *sessionCreated
+	}
+	return token, nil
+}
+
+// RevokeRefreshToken отзыв refresh token'а вместе с выданным с ним токеном авторизации при обмене на новую пару
+// после обновления токенов у сессии остается только новая пара
+// если токен уже отозван (например, параллельный обмен), возвращается FailedPrecondition
+func (s *Auth) RevokeRefreshToken(ctx context.Context, refreshToken string) error {
+	refreshTokenHash := hash.GetTokenHash(refreshToken)
+
+	return s.DB.WithTx(ctx, func(ctx context.Context) error {
+		exec, err := s.DB.conn(ctx).ExecContext(
+			ctx,
+			`UPDATE oauth_access_token SET is_revoked = TRUE
+				WHERE id IN (
+					SELECT access_token_id FROM oauth_refresh_token WHERE token_hash = ?1 AND is_revoked = FALSE
+				)`,
+			refreshTokenHash)
+		if err != nil {
+			logger.WriteErrorLog(err.Error())
+			return errors.New("RevokeRefreshToken error in sql")
+		}
+		if rows, err := exec.RowsAffected(); err != nil || rows != 1 {
+			logger.WriteErrorLog("RevokeRefreshToken error expected to affect 1 row")
+			return status.Error(codes.FailedPrecondition, "refresh token already revoked")
+		}
+
+		_, err = s.DB.conn(ctx).ExecContext(
+			ctx,
+			`UPDATE oauth_refresh_token SET is_revoked = TRUE, rotated_at = ?2
+				WHERE token_hash = ?1 AND is_revoked = FALSE`,
+			refreshTokenHash,
+			now())
+		if err != nil {
+			logger.WriteErrorLog(err.Error())
+			return errors.New("RevokeRefreshToken error in sql")
+		}
+		return nil
+	})
+}
+
+// RevokeTokenFamily отзыв всех refresh token'ов семейства и выданных с ними токенов авторизации
+// вместе с отзывом записывается событие безопасности, возвращает число отозванных токенов авторизации
+func (s *Auth) RevokeTokenFamily(ctx context.Context, familyID string, event account.SecurityEvent) (int64, error) {
+	var revoked int64
+	err := s.DB.WithTx(ctx, func(ctx context.Context) error {
+		exec, err := s.DB.conn(ctx).ExecContext(
+			ctx,
+			`UPDATE oauth_access_token SET is_revoked = TRUE
+				WHERE user_id = ?1 AND is_revoked = FALSE AND id IN (
+					SELECT access_token_id FROM oauth_refresh_token
+					WHERE COALESCE(family_id, CAST(id AS TEXT)) = ?2
+				)`,
+			event.UserID,
+			familyID)
+		if err != nil {
+			return err
+		}
+		if revoked, err = exec.RowsAffected(); err != nil {
+			return err
+		}
+
+		_, err = s.DB.conn(ctx).ExecContext(
+			ctx,
+			`UPDATE oauth_refresh_token SET is_revoked = TRUE
+				WHERE COALESCE(family_id, CAST(id AS TEXT)) = ?2
+					AND access_token_id IN (SELECT id FROM oauth_access_token WHERE user_id = ?1)`,
+			event.UserID,
+			familyID)
+		if err != nil {
+			return err
+		}
+		return saveSecurityEvent(ctx, s.DB.conn(ctx), event)
+	})
+	if err != nil {
+		logger.WriteErrorLog(err.Error())
+		return 0, errors.New("RevokeTokenFamily error in sql")
+	}
+	return revoked, nil
+}
+
+// IsAccessTokenActive проверка, что токен авторизации выдан сервером, не отозван, не истек
+// и принадлежит активному пользователю
+// у действующего токена отмечается время последнего использования
+func (s *Auth) IsAccessTokenActive(ctx context.Context, accessToken string) (bool, error) {
+	exec, err := s.DB.conn(ctx).ExecContext(
+		ctx,
+		`UPDATE oauth_access_token SET last_used_at = ?2
+			WHERE token_hash = ?1 AND is_revoked = FALSE AND expires_at > ?2
+				AND user_id IN (SELECT id FROM users WHERE is_active = TRUE)`,
+		hash.GetTokenHash(accessToken),
+		now())
+	if err != nil {
+		logger.WriteErrorLog(err.Error())
+		return false, errors.New("IsAccessTokenActive error in sql")
+	}
+
+	rows, err := exec.RowsAffected()
+	if err != nil {
+		return false, errors.New("IsAccessTokenActive error in sql empty result")
+	}
+	return rows > 0, nil
+}
+
+// RevokeAccessToken отзыв токена авторизации пользователя вместе с его refresh token'ами
+func (s *Auth) RevokeAccessToken(ctx context.Context, userID int, accessToken string) error {
+	revoked, err := s.revokeAccessTokens(
+		ctx,
+		"token_hash = ?1 AND user_id = ?2",
+		hash.GetTokenHash(accessToken),
+		userID)
+	if err != nil {
+		return errors.New("RevokeAccessToken error in sql")
+	}
+	if revoked != 1 {
+		logger.WriteErrorLog("RevokeAccessToken error expected to affect 1 row")
+		return status.Error(codes.NotFound, "token not found")
+	}
+	return nil
+}
+
+// RevokeUserTokens отзыв всех токенов пользователя, возвращает число отозванных сессий
+func (s *Auth) RevokeUserTokens(ctx context.Context, userID int) (int64, error) {
+	revoked, err := s.revokeAccessTokens(ctx, "user_id = ?1", userID)
+	if err != nil {
+		return 0, errors.New("RevokeUserTokens error in sql")
+	}
+	return revoked, nil
+}
+
+// ListSessions активные сессии пользователя, сначала недавно использованные
+// сессия активна, пока не отозваны и не истекли ее токен авторизации и refresh token
+func (s *Auth) ListSessions(ctx context.Context, userID int, currentAccessToken string) ([]auth.Session, error) {
+	currentTime := now()
+	rows, err := s.DB.conn(ctx).QueryContext(
+		ctx,
+		`SELECT
+				oat.id,
+				oat.client_ip,
+				oat.user_agent,
+				oat.session_created_at,
+				oat.created_at,
+				oat.last_used_at,
+				oat.token_hash = ?2
+			FROM oauth_access_token oat
+			WHERE oat.user_id = ?1 AND oat.is_revoked = FALSE AND oat.expires_at > ?3
+				AND EXISTS(
+					SELECT 1 FROM oauth_refresh_token ort
+					WHERE ort.access_token_id = oat.id AND ort.is_revoked = FALSE AND ort.expires_at > ?3
+				)
+			ORDER BY COALESCE(oat.last_used_at, oat.created_at) DESC, oat.id DESC`,
+		userID,
+		hash.GetTokenHash(currentAccessToken),
+		currentTime)
+	if err != nil {
+		logger.WriteErrorLog(err.Error())
+		return nil, errors.New("ListSessions error in sql")
+	}
+	defer rows.Close()
+
+	var sessions []auth.Session
+	for rows.Next() {
+		var session auth.Session
+		var sessionCreated *time.Time
+		err = rows.Scan(
+			&session.ID,
+			&session.ClientIP,
+			&session.UserAgent,
+			&sessionCreated,
+			&session.CreatedAt,
+			&session.LastUsedAt,
+			&session.Current,
+		)
+		if err != nil {
+			return nil, errors.New("ListSessions error in scan")
+		}
+		if sessionCreated != nil {
+			session.CreatedAt = *sessionCreated
+		}
+		sessions = append(sessions, session)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.New("ListSessions error in rows")
+	}
+	return sessions, nil
+}
+
+// RevokeSession отзыв одной сессии пользователя: токена авторизации и его refresh token'ов
+// возвращает хеш отозванного токена авторизации
+func (s *Auth) RevokeSession(ctx context.Context, userID int, sessionID int) (string, error) {
+	var tokenHash string
+	err := s.DB.WithTx(ctx, func(ctx context.Context) error {
+		q := s.DB.conn(ctx)
+		var revoked int64
+		if err := revokeAccessTokens(ctx, q, "id = ?1 AND user_id = ?2", &revoked, sessionID, userID); err != nil {
+			return err
+		}
+		if revoked != 1 {
+			return status.Error(codes.NotFound, "session not found")
+		}
+		return q.QueryRowContext(ctx, `SELECT token_hash FROM oauth_access_token WHERE id = ?1`, sessionID).Scan(&tokenHash)
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return "", err
+		}
+		logger.WriteErrorLog(err.Error())
+		return "", errors.New("RevokeSession error in sql")
+	}
+	return tokenHash, nil
+}
+
+// revokeAccessTokens отзыв неотозванных токенов авторизации по условию condition вместе с их refresh token'ами
+// возвращает число отозванных токенов авторизации
+func (s *Auth) revokeAccessTokens(ctx context.Context, condition string, args ...interface{}) (int64, error) {
+	var revoked int64
+	err := s.DB.WithTx(ctx, func(ctx context.Context) error {
+		return revokeAccessTokens(ctx, s.DB.conn(ctx), condition, &revoked, args...)
+	})
+	if err != nil {
+		logger.WriteErrorLog(err.Error())
+		return 0, err
+	}
+	return revoked, nil
+}
+
+// revokeAccessTokens отзыв токенов в открытой транзакции, используется и при смене пароля
+// refresh token'ы отзываются первыми, пока их токены авторизации еще находятся по условию
+func revokeAccessTokens(ctx context.Context, q querier, condition string, revoked *int64, args ...interface{}) error {
+	_, err := q.ExecContext(
+		ctx,
+		`UPDATE oauth_refresh_token SET is_revoked = TRUE
+			WHERE access_token_id IN (
+				SELECT id FROM oauth_access_token WHERE is_revoked = FALSE AND `+condition+`
+			)`,
+		args...)
+	if err != nil {
+		return err
+	}
+
+	exec, err := q.ExecContext(
+		ctx,
+		`UPDATE oauth_access_token SET is_revoked = TRUE WHERE is_revoked = FALSE AND `+condition,
+		args...)
+	if err != nil {
+		return err
+	}
+	*revoked, err = exec.RowsAffected()
+	return err
+}
@@ -0,0 +1,203 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	"github.com/ramil063/secondgodiplom/internal/hash"
+	"github.com/ramil063/secondgodiplom/internal/logger"
+)
+
+// SaveTOTP сохранение секрета второго фактора и кодов восстановления, коды хранятся хешами
+// неподтвержденный секрет перезаписывается вместе с кодами, подтвержденный не меняется
+func (s *Auth) SaveTOTP(ctx context.Context, userID int, totp *auth.TOTP, recoveryCodes []string) error {
+	return s.DB.WithTx(ctx, func(ctx context.Context) error {
+		exec, err := s.DB.conn(ctx).ExecContext(
+			ctx,
+			`INSERT INTO user_totp (user_id, encrypted_secret, iv, encryption_algorithm, key_version, created_at)
+				VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+				ON CONFLICT (user_id) DO UPDATE SET
+					encrypted_secret = excluded.encrypted_secret,
+					iv = excluded.iv,
+					encryption_algorithm = excluded.encryption_algorithm,
+					key_version = excluded.key_version,
+					last_used_step = 0,
+					created_at = excluded.created_at
+				WHERE user_totp.is_confirmed = FALSE`,
+			userID,
+			totp.EncryptedSecret,
+			totp.IV,
+			totp.Algorithm,
+			totp.KeyVersion,
+			now())
+		if err != nil {
+			logger.WriteErrorLog(err.Error())
+			return errors.New("SaveTOTP error in sql")
+		}
+		if rows, err := exec.RowsAffected(); err != nil || rows != 1 {
+			return status.Error(codes.FailedPrecondition, "totp already enabled")
+		}
+
+		_, err = s.DB.conn(ctx).ExecContext(ctx, "DELETE FROM user_recovery_code WHERE user_id = ?1", userID)
+		if err != nil {
+			logger.WriteErrorLog(err.Error())
+			return errors.New("SaveTOTP error in sql")
+		}
+		for _, code := range recoveryCodes {
+			_, err = s.DB.conn(ctx).ExecContext(
+				ctx,
+				"INSERT INTO user_recovery_code (user_id, code_hash) VALUES (?1, ?2)",
+				userID,
+				hash.GetTokenHash(code))
+			if err != nil {
+				logger.WriteErrorLog(err.Error())
+				return errors.New("SaveTOTP error in sql")
+			}
+		}
+		return nil
+	})
+}
+
+// GetTOTP получение второго фактора пользователя, если он не подключался возвращается nil без ошибки
+func (s *Auth) GetTOTP(ctx context.Context, userID int) (*auth.TOTP, error) {
+	row := s.DB.conn(ctx).QueryRowContext(
+		ctx,
+		`SELECT encrypted_secret, iv, encryption_algorithm, key_version, is_confirmed, last_used_step
+			FROM user_totp WHERE user_id = ?1`,
+		userID)
+
+	totp := &auth.TOTP{}
+	err := row.Scan(
+		&totp.EncryptedSecret,
+		&totp.IV,
+		&totp.Algorithm,
+		&totp.KeyVersion,
+		&totp.Confirmed,
+		&totp.LastUsedStep,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.New("GetTOTP error in scan")
+	}
+	return totp, nil
+}
+
+// ConfirmTOTP подтверждение подключения второго фактора, step - период принятого кода
+func (s *Auth) ConfirmTOTP(ctx context.Context, userID int, step int64) error {
+	exec, err := s.DB.conn(ctx).ExecContext(
+		ctx,
+		`UPDATE user_totp SET is_confirmed = TRUE, confirmed_at = ?3, last_used_step = ?2
+			WHERE user_id = ?1 AND is_confirmed = FALSE`,
+		userID,
+		step,
+		now())
+	if err != nil {
+		return errors.New("ConfirmTOTP error in sql")
+	}
+	if rows, err := exec.RowsAffected(); err != nil || rows != 1 {
+		return status.Error(codes.FailedPrecondition, "totp not enrolled or already enabled")
+	}
+	return nil
+}
+
+// UseTOTPStep отметка кода использованным
+// false, если код этого или более позднего периода уже принимался (повтор кода)
+func (s *Auth) UseTOTPStep(ctx context.Context, userID int, step int64) (bool, error) {
+	exec, err := s.DB.conn(ctx).ExecContext(
+		ctx,
+		`UPDATE user_totp SET last_used_step = ?2
+			WHERE user_id = ?1 AND is_confirmed = TRUE AND last_used_step < ?2`,
+		userID,
+		step)
+	if err != nil {
+		return false, errors.New("UseTOTPStep error in sql")
+	}
+	rows, err := exec.RowsAffected()
+	if err != nil {
+		return false, errors.New("UseTOTPStep error in sql empty result")
+	}
+	return rows == 1, nil
+}
+
+// UseRecoveryCode погашение кода восстановления, false если кода нет или он уже использован
+func (s *Auth) UseRecoveryCode(ctx context.Context, userID int, code string) (bool, error) {
+	exec, err := s.DB.conn(ctx).ExecContext(
+		ctx,
+		`UPDATE user_recovery_code SET used_at = ?3
+			WHERE id = (
+				SELECT id FROM user_recovery_code
+				WHERE user_id = ?1 AND code_hash = ?2 AND used_at IS NULL
+				LIMIT 1
+			)`,
+		userID,
+		hash.GetTokenHash(code),
+		now())
+	if err != nil {
+		return false, errors.New("UseRecoveryCode error in sql")
+	}
+	rows, err := exec.RowsAffected()
+	if err != nil {
+		return false, errors.New("UseRecoveryCode error in sql empty result")
+	}
+	return rows == 1, nil
+}
+
+// SaveLoginChallenge сохранение входа, ожидающего второй фактор
+func (s *Auth) SaveLoginChallenge(ctx context.Context, userID int, challenge string) error {
+	expiresAt := now().Add(time.Duration(auth.TOTPChallengeExpiredSeconds) * time.Second)
+	exec, err := s.DB.conn(ctx).ExecContext(
+		ctx,
+		"INSERT INTO login_challenge (token_hash, user_id, expires_at) VALUES (?1, ?2, ?3)",
+		hash.GetTokenHash(challenge),
+		userID,
+		expiresAt)
+	if err != nil {
+		return errors.New("SaveLoginChallenge error in sql")
+	}
+	if rows, err := exec.RowsAffected(); err != nil || rows != 1 {
+		return errors.New("SaveLoginChallenge expected to affect 1 row")
+	}
+	return nil
+}
+
+// UseLoginChallengeAttempt учет попытки ввода кода, возвращает пользователя входа
+// истекший, завершенный или исчерпавший попытки вход не принимается
+func (s *Auth) UseLoginChallengeAttempt(ctx context.Context, challenge string) (int, error) {
+	row := s.DB.conn(ctx).QueryRowContext(
+		ctx,
+		`UPDATE login_challenge SET attempts = attempts + 1
+			WHERE token_hash = ?1 AND is_used = FALSE AND expires_at > ?3 AND attempts < ?2
+			RETURNING user_id`,
+		hash.GetTokenHash(challenge),
+		auth.TOTPChallengeMaxAttempts,
+		now())
+
+	var userID int
+	if err := row.Scan(&userID); err != nil {
+		return 0, status.Error(codes.NotFound, "challenge not found")
+	}
+	return userID, nil
+}
+
+// CompleteLoginChallenge завершение входа, повторно завершить вход нельзя
+func (s *Auth) CompleteLoginChallenge(ctx context.Context, challenge string) error {
+	exec, err := s.DB.conn(ctx).ExecContext(
+		ctx,
+		"UPDATE login_challenge SET is_used = TRUE WHERE token_hash = ?1 AND is_used = FALSE",
+		hash.GetTokenHash(challenge))
+	if err != nil {
+		return errors.New("CompleteLoginChallenge error in sql")
+	}
+	if rows, err := exec.RowsAffected(); err != nil || rows != 1 {
+		return status.Error(codes.NotFound, "challenge not found")
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/user"
+	"github.com/ramil063/secondgodiplom/internal/logger"
+)
+
+// Auth авторизация пользователей: учетные данные, токены, сессии, второй фактор и попытки входа
+type Auth struct {
+	DB *DB
+}
+
+func (s *Auth) GetUserByLogin(ctx context.Context, login string) (*user.User, error) {
+	row := s.DB.conn(ctx).QueryRowContext(
+		ctx,
+		"SELECT id, password_hash, is_active, kdf_salt, wrapped_vault_key FROM users WHERE login = ?1",
+		login)
+
+	var passwordHash []byte
+	u := &user.User{}
+	err := row.Scan(&u.ID, &passwordHash, &u.IsActive, &u.KdfSalt, &u.WrappedVaultKey)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	u.PasswordHash = string(passwordHash)
+	return u, nil
+}
+
+func (s *Auth) SaveClientKeys(ctx context.Context, userID int, kdfSalt, wrappedVaultKey []byte) error {
+	exec, err := s.DB.conn(ctx).ExecContext(
+		ctx,
+		`UPDATE users SET kdf_salt = ?1, wrapped_vault_key = ?2, updated_at = ?4
+			WHERE id = ?3 AND wrapped_vault_key IS NULL`,
+		kdfSalt,
+		wrappedVaultKey,
+		userID,
+		now())
+	if err != nil {
+		return fmt.Errorf("SaveClientKeys error in sql: %w", err)
+	}
+
+	rows, err := exec.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("SaveClientKeys error in sql empty result: %w", err)
+	}
+	if rows != 1 {
+		return status.Error(codes.FailedPrecondition, "client encryption already enabled")
+	}
+	return nil
+}
+
+// RehashPassword замена хеша пароля пересчитанным после успешного входа
+// хеш обновляется, только если пароль не сменили параллельно
+func (s *Auth) RehashPassword(ctx context.Context, userID int, oldHash, newHash string) error {
+	_, err := s.DB.conn(ctx).ExecContext(
+		ctx,
+		`UPDATE users SET password_hash = ?3, updated_at = ?4
+			WHERE id = ?1 AND password_hash = ?2`,
+		userID,
+		oldHash,
+		newHash,
+		now())
+	if err != nil {
+		logger.WriteErrorLog(err.Error())
+		return errors.New("RehashPassword error in sql")
+	}
+	return nil
+}
+
+// GetUserByID получение пользователя по идентификатору
+func (s *Auth) GetUserByID(ctx context.Context, userID int) (*user.User, error) {
+	row := s.DB.conn(ctx).QueryRowContext(
+		ctx,
+		"SELECT id, login, kdf_salt, wrapped_vault_key FROM users WHERE id = ?1",
+		userID)
+
+	u := &user.User{}
+	err := row.Scan(&u.ID, &u.Login, &u.KdfSalt, &u.WrappedVaultKey)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	return u, nil
+}
@@ -0,0 +1,50 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ramil063/secondgodiplom/internal/security/crypto"
+)
+
+// DataKey хранилище обернутых ключей данных пользователей
+type DataKey struct {
+	DB *DB
+}
+
+func (d *DataKey) GetDataKey(ctx context.Context, userID int) (*crypto.WrappedKey, error) {
+	row := d.DB.conn(ctx).QueryRowContext(
+		ctx,
+		"SELECT wrapped_key, iv, encryption_algorithm, key_version, is_aad_bound FROM user_data_key WHERE user_id = ?1",
+		userID)
+
+	var key crypto.WrappedKey
+	err := row.Scan(&key.Key, &key.IV, &key.Algorithm, &key.KeyVersion, &key.AADBound)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data key: %w", err)
+	}
+	return &key, nil
+}
+
+func (d *DataKey) SaveDataKey(ctx context.Context, userID int, key *crypto.WrappedKey) error {
+	_, err := d.DB.conn(ctx).ExecContext(
+		ctx,
+		`INSERT INTO user_data_key (user_id, wrapped_key, iv, encryption_algorithm, key_version, is_aad_bound)
+			VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+			ON CONFLICT (user_id) DO NOTHING`,
+		userID,
+		key.Key,
+		key.IV,
+		key.Algorithm,
+		key.KeyVersion,
+		key.AADBound)
+	if err != nil {
+		return fmt.Errorf("failed to save data key: %w", err)
+	}
+	return nil
+}
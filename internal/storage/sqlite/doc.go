@@ -0,0 +1,5 @@
+// Package sqlite хранилище сервера во встроенной бд SQLite, без отдельного сервера бд
+// - для личного сервера на одном компьютере и для тестов, которым не нужен PostgreSQL
+// - реализует те же хранилища, что и PostgreSQL: пользователи, токены, записи, файлы, ключи, журнал аудита
+// - схема создается встроенными миграциями при открытии бд, версия схемы хранится в PRAGMA user_version
+package sqlite
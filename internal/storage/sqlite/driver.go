@@ -0,0 +1,28 @@
+package sqlite
+
+import (
+	"errors"
+	"strings"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// driverName драйвер database/sql, SQLite на чистом Go, сервер собирается без cgo
+const driverName = "sqlite"
+
+// dsn строка подключения к файлу бд path
+// включаются внешние ключи, журнал WAL и ожидание бд, занятой другим процессом (например, ротацией ключа)
+func dsn(path string) string {
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return path + separator + "_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)"
+}
+
+// isUniqueViolation ошибка нарушения уникального ключа
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
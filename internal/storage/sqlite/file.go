@@ -0,0 +1,348 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/items"
+	"github.com/ramil063/secondgodiplom/internal/logger"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/binarydata"
+)
+
+// File бинарные файлы пользователей, хранятся зашифрованными частями
+type File struct {
+	DB *DB
+}
+
+// fileColumns поля файла в порядке scanFile
+const fileColumns = `bf.id,
+	bf.filename,
+	COALESCE(bf.mime_type, ''),
+	bf.original_size,
+	COALESCE(bf.description, ''),
+	bf.chunk_size,
+	bf.total_chunks,
+	bf.created_at,
+	bf.client_encrypted`
+
+// WithTx выполнение fn в транзакции, методы хранилища с контекстом fn работают в ней
+// используется для атомарного сохранения записи о файле и его частей
+func (i *File) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return i.DB.WithTx(ctx, fn)
+}
+
+// ReserveFileID резервирование идентификатора нового файла
+// идентификатор нужен до сохранения, так как входит в связанные данные зашифрованных полей
+func (i *File) ReserveFileID(ctx context.Context) (int64, error) {
+	fileID, err := i.DB.reserveID(ctx, "binary_file")
+	if err != nil {
+		return 0, fmt.Errorf("failed to reserve file id: %w", err)
+	}
+	return fileID, nil
+}
+
+// ReserveClientFileID резервирование идентификатора файла, части которого зашифрованы на клиенте
+// клиент привязывает части к идентификатору до загрузки файла
+func (i *File) ReserveClientFileID(ctx context.Context, userID int64) (int64, error) {
+	var fileID int64
+	err := i.DB.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		if fileID, err = i.DB.reserveID(ctx, "binary_file"); err != nil {
+			return fmt.Errorf("failed to reserve file id: %w", err)
+		}
+		return i.DB.saveReservedID(ctx, items.ReservedKindFile, fileID, userID, now().Add(items.ReservedIDTTL))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return fileID, nil
+}
+
+// ClaimFileID использование идентификатора, зарезервированного пользователем userID
+// идентификатор используется один раз, чужой, просроченный или незарезервированный не находится (codes.NotFound)
+func (i *File) ClaimFileID(ctx context.Context, userID int64, fileID int64) error {
+	return i.DB.claimReservedID(ctx, items.ReservedKindFile, fileID, userID)
+}
+
+// CreateFileRecord создание записи о файле с зарезервированным идентификатором reservedID
+// если идентификатор не зарезервирован (0), он выдается базой
+func (i *File) CreateFileRecord(
+	ctx context.Context,
+	userID int,
+	reservedID int64,
+	metadata *binarydata.FileMetadata,
+) (int64, error) {
+	currentTime := now()
+	var fileID int64
+	err := i.DB.conn(ctx).QueryRowContext(
+		ctx,
+		`INSERT INTO binary_file (
+				id, user_id, filename, mime_type, original_size,
+				description, chunk_size, total_chunks, client_encrypted, created_at, updated_at
+			) VALUES (NULLIF(?1, 0), ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10, ?10)
+			RETURNING id`,
+		reservedID,
+		userID,
+		metadata.Filename,
+		metadata.MimeType,
+		metadata.OriginalSize,
+		metadata.Description,
+		metadata.ChunkSize,
+		metadata.TotalChunks,
+		metadata.ClientEncrypted,
+		currentTime,
+	).Scan(&fileID)
+	return fileID, err
+}
+
+func (i *File) SaveChunk(
+	ctx context.Context,
+	fileID int64,
+	chunkIndex int32,
+	encryptedData []byte,
+	algorithm string,
+	iv []byte,
+	keyVersion int,
+	aadBound bool,
+	streamBound bool,
+) error {
+	_, err := i.DB.conn(ctx).ExecContext(
+		ctx,
+		`INSERT INTO binary_file_chunk (file_id, chunk_index, encrypted_data, encryption_algorithm, iv, key_version, is_aad_bound, is_stream_bound)
+			VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8)`,
+		fileID,
+		chunkIndex,
+		encryptedData,
+		algorithm,
+		iv,
+		keyVersion,
+		aadBound,
+		streamBound)
+	if err != nil {
+		return fmt.Errorf("failed to save chunk: %w", err)
+	}
+	return nil
+}
+
+func (i *File) MarkFileComplete(ctx context.Context, fileID int64, totalBytes int64) error {
+	result, err := i.DB.conn(ctx).ExecContext(
+		ctx,
+		`UPDATE binary_file SET is_complete = TRUE, original_size = ?1, updated_at = ?3
+			WHERE id = ?2 AND is_deleted = FALSE`,
+		totalBytes,
+		fileID,
+		now())
+	if err != nil {
+		return fmt.Errorf("failed to mark file as complete: %w", err)
+	}
+
+	// Проверяем, что файл был найден и обновлен
+	if rows, err := result.RowsAffected(); err != nil || rows == 0 {
+		return fmt.Errorf("file not found or already deleted: ID %d", fileID)
+	}
+	return nil
+}
+
+func (i *File) GetFileInfo(ctx context.Context, fileID int64, userID int64) (*items.FileInfo, error) {
+	row := i.DB.conn(ctx).QueryRowContext(
+		ctx,
+		`SELECT `+fileColumns+`
+			FROM binary_file bf
+			WHERE bf.is_deleted = FALSE AND bf.id = ?1 AND bf.user_id = ?2`,
+		fileID,
+		userID)
+
+	fileInfo, err := scanFile(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+
+	rows, err := i.DB.conn(ctx).QueryContext(
+		ctx,
+		`SELECT id, file_id, name, COALESCE(value, ''), created_at FROM binary_file_metadata
+			WHERE file_id = ?1
+			ORDER BY created_at, id`,
+		fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metadata: %w", err)
+	}
+	if fileInfo.MetaDataItems, err = scanMetadata(rows); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata: %w", err)
+	}
+	if fileInfo.MetaDataItems == nil {
+		fileInfo.MetaDataItems = []*items.MetaData{}
+	}
+	return fileInfo, nil
+}
+
+// GetChunksInRange части файла с индексами от start до end, end исключается
+func (i *File) GetChunksInRange(ctx context.Context, fileID int64, start, end int32) ([]*items.ChunkData, error) {
+	rows, err := i.DB.conn(ctx).QueryContext(
+		ctx,
+		`SELECT chunk_index, encrypted_data, encryption_algorithm, iv, key_version, is_aad_bound, is_stream_bound
+			FROM binary_file_chunk
+			WHERE file_id = ?1 AND chunk_index >= ?2 AND chunk_index < ?3
+			ORDER BY chunk_index`,
+		fileID,
+		start,
+		end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chunks []*items.ChunkData
+	for rows.Next() {
+		var chunk items.ChunkData
+		err = rows.Scan(
+			&chunk.ChunkIndex,
+			&chunk.EncryptedData,
+			&chunk.EncryptionAlgorithm,
+			&chunk.IV,
+			&chunk.KeyVersion,
+			&chunk.AADBound,
+			&chunk.StreamBound,
+		)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, &chunk)
+	}
+	return chunks, rows.Err()
+}
+
+func (i *File) DeleteFile(ctx context.Context, userID, fileID int64) error {
+	exec, err := i.DB.conn(ctx).ExecContext(
+		ctx,
+		`UPDATE binary_file SET is_deleted = TRUE, updated_at = ?3 WHERE id = ?1 AND user_id = ?2`,
+		fileID,
+		userID,
+		now())
+	if err != nil {
+		return errors.New("DeleteFile error in sql empty result")
+	}
+
+	if rows, err := exec.RowsAffected(); err != nil || rows != 1 {
+		logger.WriteErrorLog("DeleteFile error expected to affect 1 row")
+		return errors.New("DeleteFile expected to affect 1 row")
+	}
+	return nil
+}
+
+// GetListFiles страница файлов пользователя, сначала новые
+// filter ищет подстроку в имени, типе, описании и метаданных файла
+func (i *File) GetListFiles(ctx context.Context, userID int64, page int32, perPage int32, filter string) ([]*items.FileInfo, int32, error) {
+	where := `FROM binary_file bf WHERE bf.user_id = ?1 AND bf.is_deleted = FALSE`
+	args := []interface{}{userID}
+	if filter != "" {
+		where += ` AND (
+			bf.description LIKE ?2
+			OR bf.filename LIKE ?2
+			OR bf.mime_type LIKE ?2
+			OR EXISTS(
+				SELECT 1 FROM binary_file_metadata bfm
+				WHERE bfm.file_id = bf.id AND (bfm.name LIKE ?2 OR bfm.value LIKE ?2)
+			)
+		)`
+		args = append(args, "%"+filter+"%")
+	}
+	limit := len(args) + 1
+
+	rows, err := i.DB.conn(ctx).QueryContext(
+		ctx,
+		`SELECT `+fileColumns+` `+where+`
+			ORDER BY bf.created_at DESC, bf.id DESC
+			LIMIT ?`+strconv.Itoa(limit)+` OFFSET ?`+strconv.Itoa(limit+1),
+		append(args, perPage, (page-1)*perPage)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query items: %w", err)
+	}
+	defer rows.Close()
+
+	var filesInfo []*items.FileInfo
+	for rows.Next() {
+		fileInfo, err := scanFile(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan items: %w", err)
+		}
+		filesInfo = append(filesInfo, fileInfo)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to query items: %w", err)
+	}
+
+	totalCount, err := i.GetTotalCount(ctx, `SELECT COUNT(*) `+where, userID, filter)
+	if err != nil {
+		logger.WriteErrorLog("GetTotalCount error: " + err.Error())
+		totalCount = 0
+	}
+	return filesInfo, totalCount, nil
+}
+
+// GetListFilesAfter файлы пользователя с идентификатором больше afterID, по возрастанию идентификатора
+// используется для чтения всех файлов пачками: добавленные и удаленные файлы не сдвигают следующие пачки
+func (i *File) GetListFilesAfter(ctx context.Context, userID int64, afterID int64, limit int32) ([]*items.FileInfo, error) {
+	rows, err := i.DB.conn(ctx).QueryContext(
+		ctx,
+		`SELECT `+fileColumns+`
+			FROM binary_file bf
+			WHERE bf.user_id = ?1 AND bf.is_deleted = FALSE AND bf.id > ?2
+			ORDER BY bf.id
+			LIMIT ?3`,
+		userID,
+		afterID,
+		limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query items: %w", err)
+	}
+	defer rows.Close()
+
+	var filesInfo []*items.FileInfo
+	for rows.Next() {
+		fileInfo, err := scanFile(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan items: %w", err)
+		}
+		filesInfo = append(filesInfo, fileInfo)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query items: %w", err)
+	}
+	return filesInfo, nil
+}
+
+// GetTotalCount число файлов по запросу query, ?1 - пользователь, ?2 - фильтр, если он задан
+func (i *File) GetTotalCount(ctx context.Context, query string, userID int64, filter string) (int32, error) {
+	args := []interface{}{userID}
+	if filter != "" {
+		args = append(args, "%"+filter+"%")
+	}
+
+	var totalCount int32
+	if err := i.DB.conn(ctx).QueryRowContext(ctx, query, args...).Scan(&totalCount); err != nil {
+		return 0, fmt.Errorf("failed to count items: %w", err)
+	}
+	return totalCount, nil
+}
+
+// scanFile чтение описания файла без метаданных
+func scanFile(row rowScanner) (*items.FileInfo, error) {
+	var fileInfo items.FileInfo
+	err := row.Scan(
+		&fileInfo.ID,
+		&fileInfo.Filename,
+		&fileInfo.MimeType,
+		&fileInfo.OriginalSize,
+		&fileInfo.Description,
+		&fileInfo.ChunkSize,
+		&fileInfo.TotalChunks,
+		&fileInfo.CreatedAt,
+		&fileInfo.ClientEncrypted,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &fileInfo, nil
+}
@@ -0,0 +1,124 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/binarydata"
+)
+
+func TestFile_Upload(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDB(t)
+	userID := createUser(t, d, "user")
+	s := &File{DB: d}
+
+	fileID, err := s.ReserveFileID(ctx)
+	require.NoError(t, err)
+
+	// ошибка при сохранении части откатывает запись о файле
+	errUpload := errors.New("upload interrupted")
+	err = s.WithTx(ctx, func(ctx context.Context) error {
+		_, err := s.CreateFileRecord(ctx, userID, fileID, &binarydata.FileMetadata{Filename: "a.txt", TotalChunks: 1})
+		require.NoError(t, err)
+		return errUpload
+	})
+	assert.ErrorIs(t, err, errUpload)
+	_, err = s.GetFileInfo(ctx, fileID, int64(userID))
+	assert.Error(t, err)
+
+	err = s.WithTx(ctx, func(ctx context.Context) error {
+		metadata := &binarydata.FileMetadata{Filename: "report.pdf", MimeType: "application/pdf", ChunkSize: 4, TotalChunks: 3}
+		if _, err := s.CreateFileRecord(ctx, userID, fileID, metadata); err != nil {
+			return err
+		}
+		for i, chunk := range []string{"aaaa", "bbbb", "cc"} {
+			if err := s.SaveChunk(ctx, fileID, int32(i), []byte(chunk), "AES-256-GCM", []byte("iv"), 1, true, true); err != nil {
+				return err
+			}
+		}
+		return s.MarkFileComplete(ctx, fileID, 10)
+	})
+	require.NoError(t, err)
+
+	err = s.SaveChunk(ctx, fileID, 0, []byte("dddd"), "AES-256-GCM", []byte("iv"), 1, true, true)
+	assert.Error(t, err)
+
+	info, err := s.GetFileInfo(ctx, fileID, int64(userID))
+	require.NoError(t, err)
+	assert.Equal(t, "report.pdf", info.Filename)
+	assert.Equal(t, int64(10), info.OriginalSize)
+	assert.Equal(t, int32(3), info.TotalChunks)
+	assert.Empty(t, info.MetaDataItems)
+
+	chunks, err := s.GetChunksInRange(ctx, fileID, 1, 3)
+	require.NoError(t, err)
+	require.Len(t, chunks, 2)
+	assert.Equal(t, int32(1), chunks[0].ChunkIndex)
+	assert.Equal(t, []byte("cc"), chunks[1].EncryptedData)
+	assert.True(t, chunks[1].StreamBound)
+
+	_, err = s.GetFileInfo(ctx, fileID, int64(userID)+1)
+	assert.Error(t, err)
+}
+
+func TestFile_GetListFiles(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDB(t)
+	userID := createUser(t, d, "user")
+	s := &File{DB: d}
+
+	var fileIDs []int64
+	for _, name := range []string{"photo.jpg", "report.pdf", "notes.txt"} {
+		fileID, err := s.CreateFileRecord(ctx, userID, 0, &binarydata.FileMetadata{Filename: name, TotalChunks: 1})
+		require.NoError(t, err)
+		fileIDs = append(fileIDs, fileID)
+	}
+	require.NoError(t, s.DeleteFile(ctx, int64(userID), fileIDs[2]))
+	assert.Error(t, s.DeleteFile(ctx, int64(userID)+1, fileIDs[0]))
+
+	tests := []struct {
+		name      string
+		filter    string
+		wantNames []string
+		wantTotal int32
+	}{
+		{
+			name:      "all",
+			wantNames: []string{"report.pdf", "photo.jpg"},
+			wantTotal: 2,
+		},
+		{
+			name:      "filter",
+			filter:    "PDF",
+			wantNames: []string{"report.pdf"},
+			wantTotal: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, total, err := s.GetListFiles(ctx, int64(userID), 1, 10, tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTotal, total)
+			var gotNames []string
+			for _, file := range got {
+				gotNames = append(gotNames, file.Filename)
+			}
+			assert.Equal(t, tt.wantNames, gotNames)
+		})
+	}
+
+	// пачками по возрастанию идентификатора, удаленные файлы пропускаются
+	got, err := s.GetListFilesAfter(ctx, int64(userID), 0, 1)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "photo.jpg", got[0].Filename)
+	got, err = s.GetListFilesAfter(ctx, int64(userID), fileIDs[0], 10)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "report.pdf", got[0].Filename)
+}
@@ -0,0 +1,429 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	itemModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/items"
+	"github.com/ramil063/secondgodiplom/internal/logger"
+)
+
+// Item записи пользователей: пароли, тексты и банковские карты
+type Item struct {
+	DB *DB
+}
+
+// errItemNotFound запись не найдена, удалена, принадлежит другому пользователю или имеет другой тип
+var errItemNotFound = status.Error(codes.NotFound, "item not found")
+
+// itemColumns поля записи в порядке scanItem
+const itemColumns = `ei.id,
+	ei.encrypted_data,
+	COALESCE(ei.description, ''),
+	ei.created_at,
+	COALESCE(ei.encryption_algorithm, ''),
+	ei.iv,
+	ei.key_version,
+	ei.is_aad_bound`
+
+// WithTx выполнение fn в транзакции, методы хранилища с контекстом fn работают в ней
+// используется для атомарного сохранения записи и ее метаданных
+func (pi *Item) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return pi.DB.WithTx(ctx, fn)
+}
+
+// ReserveItemID резервирование идентификатора новой записи
+// идентификатор нужен до сохранения, так как входит в связанные данные шифротекста
+func (pi *Item) ReserveItemID(ctx context.Context) (int64, error) {
+	itemID, err := pi.DB.reserveID(ctx, "encrypted_item")
+	if err != nil {
+		return 0, fmt.Errorf("failed to reserve item id: %w", err)
+	}
+	return itemID, nil
+}
+
+// ReserveClientItemID резервирование идентификатора записи для данных, зашифрованных на клиенте
+// клиент привязывает шифротекст к идентификатору до создания записи
+func (pi *Item) ReserveClientItemID(ctx context.Context, userID int64) (int64, error) {
+	var itemID int64
+	err := pi.DB.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		if itemID, err = pi.DB.reserveID(ctx, "encrypted_item"); err != nil {
+			return fmt.Errorf("failed to reserve item id: %w", err)
+		}
+		return pi.DB.saveReservedID(ctx, itemModel.ReservedKindItem, itemID, userID, now().Add(itemModel.ReservedIDTTL))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return itemID, nil
+}
+
+// ClaimItemID использование идентификатора, зарезервированного пользователем userID
+// идентификатор используется один раз, чужой, просроченный или незарезервированный не находится (codes.NotFound)
+func (pi *Item) ClaimItemID(ctx context.Context, userID int64, itemID int64) error {
+	return pi.DB.claimReservedID(ctx, itemModel.ReservedKindItem, itemID, userID)
+}
+
+func (pi *Item) SaveEncryptedData(ctx context.Context, encryptedItem *itemModel.EncryptedItem) (int64, error) {
+	row := pi.DB.conn(ctx).QueryRowContext(
+		ctx,
+		`INSERT INTO encrypted_item (id, encrypted_data, description, user_id, item_type_id, encryption_algorithm, iv, key_version, is_aad_bound)
+			SELECT NULLIF(?1, 0), ?2, ?3, ?4, it.id, ?6, ?7, ?8, ?9
+			FROM item_type it WHERE it.alias = ?5
+			RETURNING id`,
+		encryptedItem.ID,
+		encryptedItem.Data,
+		encryptedItem.Description,
+		encryptedItem.UserID,
+		encryptedItem.Type,
+		encryptedItem.EncryptionAlgorithm,
+		encryptedItem.Iv,
+		encryptedItem.KeyVersion,
+		encryptedItem.AADBound)
+
+	var itemID int64
+	if err := row.Scan(&itemID); err != nil {
+		logger.WriteErrorLog(err.Error())
+		return 0, errors.New("SaveEncryptedData error in sql empty result")
+	}
+	return itemID, nil
+}
+
+// SaveMetadata сохранение метаданных записи пользователя с типом itemType
+func (pi *Item) SaveMetadata(ctx context.Context, userID int64, itemType string, metadata *itemModel.MetaData) error {
+	currentTime := now()
+	exec, err := pi.DB.conn(ctx).ExecContext(
+		ctx,
+		`INSERT INTO item_metadata (item_id, name, value, created_at, updated_at)
+			SELECT ei.id, ?2, ?3, ?6, ?6
+			FROM encrypted_item ei
+			JOIN item_type it ON ei.item_type_id = it.id
+			WHERE ei.id = ?1 AND ei.user_id = ?4 AND it.alias = ?5 AND ei.is_deleted = FALSE`,
+		metadata.ItemID,
+		metadata.Name,
+		metadata.Value,
+		userID,
+		itemType,
+		currentTime)
+	if err != nil {
+		return err
+	}
+
+	rows, err := exec.RowsAffected()
+	if err != nil {
+		logger.WriteErrorLog("save metadata error in sql empty result")
+		return errors.New("error in sql empty result")
+	}
+	if rows != 1 {
+		return errItemNotFound
+	}
+	return nil
+}
+
+// GetListItems страница записей пользователя с типом itemType, сначала новые
+// filter ищет подстроку в описании и метаданных записи
+func (pi *Item) GetListItems(
+	ctx context.Context,
+	userID int64,
+	page int32,
+	perPage int32,
+	itemType string,
+	filter string,
+) ([]*itemModel.ItemData, int32, error) {
+	where := `FROM encrypted_item ei
+		JOIN item_type it ON ei.item_type_id = it.id
+		WHERE ei.user_id = ?1 AND ei.is_deleted = FALSE AND it.alias = ?2`
+	args := []interface{}{userID, itemType}
+	if filter != "" {
+		where += ` AND (
+			ei.description LIKE ?3
+			OR EXISTS(
+				SELECT 1 FROM item_metadata im
+				WHERE im.item_id = ei.id AND (im.name LIKE ?3 OR im.value LIKE ?3)
+			)
+		)`
+		args = append(args, "%"+filter+"%")
+	}
+	limit := len(args) + 1
+
+	rows, err := pi.DB.conn(ctx).QueryContext(
+		ctx,
+		`SELECT `+itemColumns+` `+where+`
+			ORDER BY ei.created_at DESC, ei.id DESC
+			LIMIT ?`+strconv.Itoa(limit)+` OFFSET ?`+strconv.Itoa(limit+1),
+		append(args, perPage, (page-1)*perPage)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query items: %w", err)
+	}
+
+	var items []*itemModel.ItemData
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			_ = rows.Close()
+			return nil, 0, fmt.Errorf("failed to scan items: %w", err)
+		}
+		items = append(items, item)
+	}
+	_ = rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to query items: %w", err)
+	}
+
+	if err = pi.loadMetadata(ctx, items); err != nil {
+		return nil, 0, err
+	}
+
+	var totalCount int32
+	if err = pi.DB.conn(ctx).QueryRowContext(ctx, `SELECT COUNT(*) `+where, args...).Scan(&totalCount); err != nil {
+		logger.WriteErrorLog("GetTotalCount error: " + err.Error())
+		totalCount = 0
+	}
+	return items, totalCount, nil
+}
+
+// GetListItemsAfter записи пользователя с типом itemType и идентификатором больше afterID, по возрастанию идентификатора
+// используется для чтения всех записей пачками: добавленные и удаленные записи не сдвигают следующие пачки
+func (pi *Item) GetListItemsAfter(
+	ctx context.Context,
+	userID int64,
+	itemType string,
+	afterID int64,
+	limit int32,
+) ([]*itemModel.ItemData, error) {
+	rows, err := pi.DB.conn(ctx).QueryContext(
+		ctx,
+		`SELECT `+itemColumns+`
+			FROM encrypted_item ei
+			JOIN item_type it ON ei.item_type_id = it.id
+			WHERE ei.user_id = ?1 AND ei.is_deleted = FALSE AND it.alias = ?2 AND ei.id > ?3
+			ORDER BY ei.id
+			LIMIT ?4`,
+		userID,
+		itemType,
+		afterID,
+		limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query items: %w", err)
+	}
+
+	var items []*itemModel.ItemData
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("failed to scan items: %w", err)
+		}
+		items = append(items, item)
+	}
+	_ = rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query items: %w", err)
+	}
+
+	if err = pi.loadMetadata(ctx, items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// GetItem запись пользователя с типом itemType
+func (pi *Item) GetItem(ctx context.Context, userID int64, itemType string, itemID int64) (*itemModel.ItemData, error) {
+	row := pi.DB.conn(ctx).QueryRowContext(
+		ctx,
+		`SELECT `+itemColumns+`
+			FROM encrypted_item ei
+			JOIN item_type it ON ei.item_type_id = it.id
+			WHERE ei.is_deleted = FALSE AND ei.id = ?1 AND ei.user_id = ?2 AND it.alias = ?3`,
+		itemID,
+		userID,
+		itemType)
+
+	item, err := scanItem(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errItemNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item: %w", err)
+	}
+	if err = pi.loadMetadata(ctx, []*itemModel.ItemData{item}); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// DeleteItem мягкое удаление записи пользователя с типом itemType
+func (pi *Item) DeleteItem(ctx context.Context, userID int64, itemType string, itemID int64) error {
+	exec, err := pi.DB.conn(ctx).ExecContext(
+		ctx,
+		`UPDATE encrypted_item SET is_deleted = TRUE, updated_at = ?4
+			WHERE id = ?1 AND user_id = ?2 AND is_deleted = FALSE
+				AND item_type_id = (SELECT id FROM item_type WHERE alias = ?3)`,
+		itemID,
+		userID,
+		itemType,
+		now())
+	if err != nil {
+		return errors.New("DeleteItem error in sql empty result")
+	}
+
+	rows, err := exec.RowsAffected()
+	if err != nil {
+		logger.WriteErrorLog("DeleteItem error in sql empty result")
+		return errors.New("DeleteItem error in sql empty result")
+	}
+	if rows != 1 {
+		return errItemNotFound
+	}
+	return nil
+}
+
+// UpdateItem обновление записи пользователя с типом itemType
+func (pi *Item) UpdateItem(
+	ctx context.Context,
+	userID int64,
+	itemType string,
+	itemID int64,
+	encryptedItem *itemModel.EncryptedItem,
+) (int64, error) {
+	var set []string
+	args := []interface{}{itemID, userID, itemType, now()}
+	addField := func(field string, value interface{}) {
+		args = append(args, value)
+		set = append(set, field+" = ?"+strconv.Itoa(len(args)))
+	}
+
+	if len(encryptedItem.Data) > 0 {
+		addField("encrypted_data", encryptedItem.Data)
+		// Признак привязки меняется только вместе с шифротекстом
+		addField("is_aad_bound", encryptedItem.AADBound)
+	}
+	if encryptedItem.Description != "" {
+		addField("description", encryptedItem.Description)
+	}
+	if encryptedItem.EncryptionAlgorithm != "" {
+		addField("encryption_algorithm", encryptedItem.EncryptionAlgorithm)
+	}
+	if len(encryptedItem.Iv) > 0 {
+		addField("iv", encryptedItem.Iv)
+	}
+	if encryptedItem.KeyVersion > 0 {
+		addField("key_version", encryptedItem.KeyVersion)
+	}
+	if len(set) == 0 {
+		return 0, errors.New("UpdateItem empty data in update")
+	}
+
+	exec, err := pi.DB.conn(ctx).ExecContext(
+		ctx,
+		`UPDATE encrypted_item
+			SET `+strings.Join(set, ", ")+`, updated_at = ?4
+			WHERE is_deleted = FALSE AND id = ?1 AND user_id = ?2
+				AND item_type_id = (SELECT id FROM item_type WHERE alias = ?3)`,
+		args...)
+	if err != nil {
+		logger.WriteErrorLog(err.Error())
+		return 0, errors.New("UpdateItem error in sql")
+	}
+	if rows, err := exec.RowsAffected(); err != nil || rows != 1 {
+		return 0, errItemNotFound
+	}
+	return itemID, nil
+}
+
+// GetMetaDataList метаданные записи пользователя с типом itemType
+func (pi *Item) GetMetaDataList(ctx context.Context, userID int64, itemType string, itemID int64) ([]*itemModel.MetaData, error) {
+	rows, err := pi.DB.conn(ctx).QueryContext(
+		ctx,
+		`SELECT im.id, im.item_id, im.name, COALESCE(im.value, ''), im.created_at
+			FROM item_metadata im
+			JOIN encrypted_item ei ON im.item_id = ei.id
+			JOIN item_type it ON ei.item_type_id = it.id
+			WHERE im.item_id = ?1 AND ei.user_id = ?2 AND it.alias = ?3 AND ei.is_deleted = FALSE
+			ORDER BY im.created_at, im.id`,
+		itemID,
+		userID,
+		itemType)
+	if err != nil {
+		return nil, errors.New("GetMetaDataList error on sql empty result")
+	}
+	return scanMetadata(rows)
+}
+
+// loadMetadata заполнение метаданных записей одним запросом
+func (pi *Item) loadMetadata(ctx context.Context, items []*itemModel.ItemData) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*itemModel.ItemData, len(items))
+	args := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		item.MetaDataItems = []*itemModel.MetaData{}
+		byID[item.ID] = item
+		args = append(args, item.ID)
+	}
+
+	rows, err := pi.DB.conn(ctx).QueryContext(
+		ctx,
+		`SELECT id, item_id, name, COALESCE(value, ''), created_at FROM item_metadata
+			WHERE item_id IN (`+placeholders(1, len(args))+`)
+			ORDER BY created_at, id`,
+		args...)
+	if err != nil {
+		return fmt.Errorf("failed to query metadata: %w", err)
+	}
+	metadata, err := scanMetadata(rows)
+	if err != nil {
+		return fmt.Errorf("failed to parse metadata: %w", err)
+	}
+	for _, m := range metadata {
+		byID[m.ItemID].MetaDataItems = append(byID[m.ItemID].MetaDataItems, m)
+	}
+	return nil
+}
+
+// scanItem чтение записи без метаданных
+func scanItem(row rowScanner) (*itemModel.ItemData, error) {
+	var item itemModel.ItemData
+	err := row.Scan(
+		&item.ID,
+		&item.Data,
+		&item.Description,
+		&item.CreatedAt,
+		&item.EncryptionAlgorithm,
+		&item.IV,
+		&item.KeyVersion,
+		&item.AADBound,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// scanMetadata чтение метаданных записей или файлов, rows закрываются
+func scanMetadata(rows *sql.Rows) ([]*itemModel.MetaData, error) {
+	defer rows.Close()
+
+	var metadata []*itemModel.MetaData
+	for rows.Next() {
+		var m itemModel.MetaData
+		if err := rows.Scan(&m.ID, &m.ItemID, &m.Name, &m.Value, &m.CreatedAt); err != nil {
+			return nil, errors.New("GetMetaDataList error in scan")
+		}
+		metadata = append(metadata, &m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New("GetMetaDataList error in rows")
+	}
+	return metadata, nil
+}
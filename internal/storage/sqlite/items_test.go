@@ -0,0 +1,213 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	itemModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/items"
+)
+
+// testItem запись с паролем пользователя userID
+func testItem(userID int, itemID int64) *itemModel.EncryptedItem {
+	return &itemModel.EncryptedItem{
+		ID:                  itemID,
+		UserID:              int64(userID),
+		Type:                "passwords",
+		Data:                []byte("encrypted"),
+		Description:         "description",
+		EncryptionAlgorithm: "AES-256-GCM",
+		Iv:                  []byte("iv"),
+		KeyVersion:          1,
+		AADBound:            true,
+	}
+}
+
+func TestItem_GetItem(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDB(t)
+	userID := createUser(t, d, "user")
+	otherID := createUser(t, d, "other")
+	s := &Item{DB: d}
+
+	itemID, err := s.SaveEncryptedData(ctx, testItem(userID, 0))
+	require.NoError(t, err)
+	require.NoError(t, s.SaveMetadata(ctx, int64(userID), "passwords", &itemModel.MetaData{ItemID: itemID, Name: "site", Value: "example.com"}))
+
+	err = s.SaveMetadata(ctx, int64(otherID), "passwords", &itemModel.MetaData{ItemID: itemID, Name: "site"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	tests := []struct {
+		name     string
+		userID   int
+		itemType string
+		wantErr  bool
+	}{
+		{
+			name:     "owner",
+			userID:   userID,
+			itemType: "passwords",
+		},
+		{
+			name:     "other user",
+			userID:   otherID,
+			itemType: "passwords",
+			wantErr:  true,
+		},
+		{
+			name:     "other type",
+			userID:   userID,
+			itemType: "card",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.GetItem(ctx, int64(tt.userID), tt.itemType, itemID)
+			if tt.wantErr {
+				assert.Equal(t, codes.NotFound, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []byte("encrypted"), got.Data)
+			assert.Equal(t, "description", got.Description)
+			assert.True(t, got.AADBound)
+			assert.False(t, got.CreatedAt.IsZero())
+			require.Len(t, got.MetaDataItems, 1)
+			assert.Equal(t, "example.com", got.MetaDataItems[0].Value)
+		})
+	}
+}
+
+func TestItem_GetListItems(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDB(t)
+	userID := createUser(t, d, "user")
+	s := &Item{DB: d}
+
+	var itemIDs []int64
+	for i := 0; i < 3; i++ {
+		itemID, err := s.SaveEncryptedData(ctx, testItem(userID, 0))
+		require.NoError(t, err)
+		itemIDs = append(itemIDs, itemID)
+	}
+	require.NoError(t, s.SaveMetadata(ctx, int64(userID), "passwords", &itemModel.MetaData{ItemID: itemIDs[0], Name: "site", Value: "bank"}))
+	require.NoError(t, s.DeleteItem(ctx, int64(userID), "passwords", itemIDs[2]))
+
+	tests := []struct {
+		name      string
+		page      int32
+		filter    string
+		wantIDs   []int64
+		wantTotal int32
+	}{
+		{
+			name:      "first page",
+			page:      1,
+			wantIDs:   []int64{itemIDs[1]},
+			wantTotal: 2,
+		},
+		{
+			name:      "second page",
+			page:      2,
+			wantIDs:   []int64{itemIDs[0]},
+			wantTotal: 2,
+		},
+		{
+			name:      "filter by metadata",
+			page:      1,
+			filter:    "BAN",
+			wantIDs:   []int64{itemIDs[0]},
+			wantTotal: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, total, err := s.GetListItems(ctx, int64(userID), tt.page, 1, "passwords", tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTotal, total)
+			var gotIDs []int64
+			for _, item := range got {
+				gotIDs = append(gotIDs, item.ID)
+			}
+			assert.Equal(t, tt.wantIDs, gotIDs)
+		})
+	}
+
+	// пачками по возрастанию идентификатора, удаленные записи пропускаются
+	got, err := s.GetListItemsAfter(ctx, int64(userID), "passwords", 0, 1)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, itemIDs[0], got[0].ID)
+	require.Len(t, got[0].MetaDataItems, 1)
+	got, err = s.GetListItemsAfter(ctx, int64(userID), "passwords", itemIDs[0], 10)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, itemIDs[1], got[0].ID)
+}
+
+func TestItem_UpdateItem(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDB(t)
+	userID := createUser(t, d, "user")
+	s := &Item{DB: d}
+
+	itemID, err := s.SaveEncryptedData(ctx, testItem(userID, 0))
+	require.NoError(t, err)
+
+	_, err = s.UpdateItem(ctx, int64(userID), "passwords", itemID, &itemModel.EncryptedItem{})
+	assert.Error(t, err)
+
+	got, err := s.UpdateItem(ctx, int64(userID), "passwords", itemID, &itemModel.EncryptedItem{Data: []byte("updated"), Iv: []byte("iv2")})
+	require.NoError(t, err)
+	assert.Equal(t, itemID, got)
+
+	item, err := s.GetItem(ctx, int64(userID), "passwords", itemID)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("updated"), item.Data)
+	assert.Equal(t, []byte("iv2"), item.IV)
+	assert.False(t, item.AADBound)
+
+	require.NoError(t, s.DeleteItem(ctx, int64(userID), "passwords", itemID))
+	_, err = s.UpdateItem(ctx, int64(userID), "passwords", itemID, &itemModel.EncryptedItem{Description: "new"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	err = s.DeleteItem(ctx, int64(userID), "passwords", itemID)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestItem_ClaimItemID(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDB(t)
+	userID := createUser(t, d, "user")
+	otherID := createUser(t, d, "other")
+	s := &Item{DB: d}
+
+	itemID, err := s.ReserveClientItemID(ctx, int64(userID))
+	require.NoError(t, err)
+	expiredID, err := s.ReserveClientItemID(ctx, int64(userID))
+	require.NoError(t, err)
+	assert.NotEqual(t, itemID, expiredID)
+	_, err = d.db.ExecContext(ctx, `UPDATE reserved_id SET expires_at = ?1 WHERE id = ?2`, now().Add(-time.Minute), expiredID)
+	require.NoError(t, err)
+
+	// чужой и просроченный идентификатор не используется
+	err = s.ClaimItemID(ctx, int64(otherID), itemID)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	err = s.ClaimItemID(ctx, int64(userID), expiredID)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// идентификатор используется один раз
+	require.NoError(t, s.ClaimItemID(ctx, int64(userID), itemID))
+	err = s.ClaimItemID(ctx, int64(userID), itemID)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// зарезервированный идентификатор не выдается новым записям
+	savedID, err := s.SaveEncryptedData(ctx, testItem(userID, 0))
+	require.NoError(t, err)
+	assert.Greater(t, savedID, expiredID)
+}
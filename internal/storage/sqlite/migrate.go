@@ -0,0 +1,56 @@
+package sqlite
+
+import (
+	"context"
+	"embed"
+	"fmt"
+
+	"github.com/ramil063/secondgodiplom/internal/storage/db/migrations"
+)
+
+// files миграции схемы встроенной бд, имена файлов как у миграций PostgreSQL
+//
+//go:embed migrations/*.sql
+var files embed.FS
+
+// Migrate применение новых миграций схемы, возвращает примененные
+// каждая миграция выполняется в своей транзакции вместе с записью версии в PRAGMA user_version
+func (d *DB) Migrate(ctx context.Context) ([]migrations.Migration, error) {
+	list, err := migrations.Parse(files, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	latest := len(list)
+
+	var applied []migrations.Migration
+	for {
+		var next *migrations.Migration
+		err = d.WithTx(ctx, func(ctx context.Context) error {
+			var current int
+			if err := d.conn(ctx).QueryRowContext(ctx, `PRAGMA user_version`).Scan(&current); err != nil {
+				return fmt.Errorf("get schema version: %w", err)
+			}
+			if current > latest {
+				return fmt.Errorf("%w: version %d, supported %d", migrations.ErrSchemaTooNew, current, latest)
+			}
+			if current == latest {
+				return nil
+			}
+
+			next = &list[current]
+			if _, err := d.conn(ctx).ExecContext(ctx, next.Up); err != nil {
+				return fmt.Errorf("apply migration %d_%s: %w", next.Version, next.Name, err)
+			}
+			// PRAGMA не принимает параметры запроса
+			_, err := d.conn(ctx).ExecContext(ctx, fmt.Sprintf(`PRAGMA user_version = %d`, next.Version))
+			return err
+		})
+		if err != nil {
+			return applied, err
+		}
+		if next == nil {
+			return applied, nil
+		}
+		applied = append(applied, *next)
+	}
+}
@@ -0,0 +1,18 @@
+-- Удаление всех таблиц хранилища, данные теряются
+DROP TABLE IF EXISTS binary_file_metadata;
+DROP TABLE IF EXISTS binary_file_chunk;
+DROP TABLE IF EXISTS binary_file;
+DROP TABLE IF EXISTS audit_event;
+DROP TABLE IF EXISTS personal_access_token;
+DROP TABLE IF EXISTS login_attempt;
+DROP TABLE IF EXISTS security_event;
+DROP TABLE IF EXISTS login_challenge;
+DROP TABLE IF EXISTS user_recovery_code;
+DROP TABLE IF EXISTS user_totp;
+DROP TABLE IF EXISTS oauth_refresh_token;
+DROP TABLE IF EXISTS oauth_access_token;
+DROP TABLE IF EXISTS item_metadata;
+DROP TABLE IF EXISTS encrypted_item;
+DROP TABLE IF EXISTS item_type;
+DROP TABLE IF EXISTS user_data_key;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,231 @@
+-- Исходная схема встроенной бд SQLite
+-- повторяет схему PostgreSQL (internal/storage/db/migrations/sql), комментарии к столбцам - там же
+-- время хранится строкой в UTC в формате драйвера, поэтому строки сравниваются и сортируются как время
+
+--USERS
+CREATE TABLE users (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	login TEXT UNIQUE NOT NULL,
+	password_hash TEXT NOT NULL,
+	first_name TEXT,
+	last_name TEXT,
+	is_active BOOLEAN NOT NULL DEFAULT TRUE,
+	kdf_salt BLOB,
+	wrapped_vault_key BLOB,
+	deletion_scheduled_at TIMESTAMP,
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+	updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+--USER_DATA_KEY
+CREATE TABLE user_data_key (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER UNIQUE NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	wrapped_key BLOB NOT NULL,
+	iv BLOB NOT NULL,
+	encryption_algorithm TEXT NOT NULL,
+	key_version INTEGER NOT NULL DEFAULT 1,
+	is_aad_bound BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+--ITEM_TYPE
+CREATE TABLE item_type (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	alias TEXT UNIQUE NOT NULL,
+	name TEXT NOT NULL
+);
+
+INSERT INTO item_type (alias, name) VALUES
+	('passwords', 'Пары логин/пароль'),
+	('text', 'Произвольные текстовые данные'),
+	('binary', 'Произвольные бинарные данные'),
+	('card', 'Данные банковских карт');
+
+--ENCRYPTED_ITEM
+CREATE TABLE encrypted_item (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	encrypted_data BLOB NOT NULL,
+	description TEXT,
+	is_deleted BOOLEAN NOT NULL DEFAULT FALSE,
+	user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	item_type_id INTEGER NOT NULL REFERENCES item_type(id),
+	encryption_algorithm TEXT,
+	iv BLOB,
+	key_version INTEGER NOT NULL DEFAULT 1,
+	is_aad_bound BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+	updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+--ITEM_METADATA
+CREATE TABLE item_metadata (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	item_id INTEGER NOT NULL REFERENCES encrypted_item(id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	value TEXT,
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+	updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+--OAUTH_ACCESS_TOKEN
+CREATE TABLE oauth_access_token (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	token_hash TEXT NOT NULL,
+	user_id INTEGER REFERENCES users(id),
+	expires_at TIMESTAMP NOT NULL,
+	is_revoked BOOLEAN NOT NULL DEFAULT FALSE,
+	client_ip TEXT NOT NULL DEFAULT '',
+	user_agent TEXT NOT NULL DEFAULT '',
+	last_used_at TIMESTAMP,
+	session_created_at TIMESTAMP,
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+--OAUTH_REFRESH_TOKEN
+CREATE TABLE oauth_refresh_token (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	token_hash TEXT NOT NULL,
+	access_token_id INTEGER NOT NULL REFERENCES oauth_access_token(id) ON DELETE CASCADE,
+	is_revoked BOOLEAN NOT NULL DEFAULT FALSE,
+	expires_at TIMESTAMP NOT NULL,
+	family_id TEXT,
+	rotated_at TIMESTAMP,
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+--USER_TOTP
+CREATE TABLE user_totp (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER UNIQUE NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	encrypted_secret BLOB NOT NULL,
+	iv BLOB NOT NULL,
+	encryption_algorithm TEXT NOT NULL,
+	key_version INTEGER NOT NULL DEFAULT 1,
+	is_confirmed BOOLEAN NOT NULL DEFAULT FALSE,
+	last_used_step INTEGER NOT NULL DEFAULT 0,
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+	confirmed_at TIMESTAMP
+);
+
+--USER_RECOVERY_CODE
+CREATE TABLE user_recovery_code (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	code_hash TEXT NOT NULL,
+	used_at TIMESTAMP,
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+--LOGIN_CHALLENGE
+CREATE TABLE login_challenge (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	token_hash TEXT UNIQUE NOT NULL,
+	user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	is_used BOOLEAN NOT NULL DEFAULT FALSE,
+	expires_at TIMESTAMP NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+--SECURITY_EVENT
+CREATE TABLE security_event (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	event_type TEXT NOT NULL,
+	client_ip TEXT NOT NULL DEFAULT '',
+	user_agent TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+--LOGIN_ATTEMPT
+CREATE TABLE login_attempt (
+	subject TEXT PRIMARY KEY,
+	failures INTEGER NOT NULL DEFAULT 0,
+	last_failure_at TIMESTAMP NOT NULL,
+	locked_until TIMESTAMP
+);
+
+--PERSONAL_ACCESS_TOKEN
+-- права токена хранятся массивом JSON
+CREATE TABLE personal_access_token (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	token_hash TEXT NOT NULL UNIQUE,
+	scopes TEXT NOT NULL,
+	expires_at TIMESTAMP,
+	last_used_at TIMESTAMP,
+	is_revoked BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+--AUDIT_EVENT
+CREATE TABLE audit_event (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+	login TEXT,
+	event_type TEXT NOT NULL,
+	method TEXT NOT NULL,
+	target_id INTEGER,
+	personal_token_id INTEGER,
+	success BOOLEAN NOT NULL,
+	status_code TEXT NOT NULL,
+	client_ip TEXT NOT NULL DEFAULT '',
+	user_agent TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+CREATE INDEX audit_event_user_id_idx ON audit_event (user_id, id);
+-- события не изменяются и не удаляются, кроме каскадного удаления вместе с пользователем:
+-- при каскадном удалении строки пользователя уже нет
+CREATE TRIGGER audit_event_append_only BEFORE UPDATE ON audit_event
+BEGIN
+	SELECT RAISE(ABORT, 'audit_event is append-only');
+END;
+CREATE TRIGGER audit_event_delete_with_user BEFORE DELETE ON audit_event
+WHEN OLD.user_id IS NULL OR EXISTS (SELECT 1 FROM users WHERE id = OLD.user_id)
+BEGIN
+	SELECT RAISE(ABORT, 'audit_event is append-only');
+END;
+
+--BINARY_FILE
+CREATE TABLE binary_file (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	filename TEXT NOT NULL,
+	mime_type TEXT,
+	original_size INTEGER NOT NULL,
+	chunk_size INTEGER NOT NULL,
+	total_chunks INTEGER NOT NULL,
+	description TEXT,
+	is_deleted BOOLEAN NOT NULL DEFAULT FALSE,
+	is_complete BOOLEAN NOT NULL DEFAULT FALSE,
+	client_encrypted BOOLEAN NOT NULL DEFAULT FALSE,
+	user_id INTEGER NOT NULL REFERENCES users(id),
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+	updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+--BINARY_FILE_CHUNK
+CREATE TABLE binary_file_chunk (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	file_id INTEGER NOT NULL REFERENCES binary_file(id) ON DELETE CASCADE,
+	chunk_index INTEGER NOT NULL,
+	encrypted_data BLOB NOT NULL,
+	encryption_algorithm TEXT NOT NULL,
+	iv BLOB NOT NULL,
+	key_version INTEGER NOT NULL DEFAULT 1,
+	is_aad_bound BOOLEAN NOT NULL DEFAULT FALSE,
+	is_stream_bound BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+	UNIQUE(file_id, chunk_index)
+);
+
+--BINARY_FILE_METADATA
+CREATE TABLE binary_file_metadata (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	file_id INTEGER NOT NULL REFERENCES binary_file(id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	value TEXT,
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+	updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
@@ -0,0 +1,11 @@
+-- Идентификаторы записей и файлов, зарезервированные клиентом
+-- при сквозном шифровании клиент привязывает шифротекст к идентификатору до создания записи
+
+--RESERVED_ID
+CREATE TABLE reserved_id (
+	kind TEXT NOT NULL,
+	id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	expires_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (kind, id)
+);
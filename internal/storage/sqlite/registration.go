@@ -0,0 +1,42 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/user"
+	internalErrors "github.com/ramil063/secondgodiplom/internal/errors"
+	"github.com/ramil063/secondgodiplom/internal/logger"
+)
+
+// Reg регистрация пользователей
+type Reg struct {
+	DB *DB
+}
+
+func (s *Reg) RegisterUser(ctx context.Context, user *user.User) (int, error) {
+	var userID int
+
+	err := s.DB.conn(ctx).QueryRowContext(
+		ctx,
+		`INSERT INTO users (login, password_hash, first_name, last_name, is_active, kdf_salt, wrapped_vault_key)
+			VALUES (?1, ?2, ?3, ?4, TRUE, ?5, ?6)
+			RETURNING id`,
+		user.Login,
+		user.PasswordHash,
+		user.FirstName,
+		user.LastName,
+		user.KdfSalt,
+		user.WrappedVaultKey,
+	).Scan(&userID)
+
+	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, internalErrors.ErrUniqueViolation
+		}
+		logger.WriteErrorLog("RegisterUser error" + err.Error())
+		return 0, fmt.Errorf("failed to register user: %w", err)
+	}
+
+	return userID, nil
+}
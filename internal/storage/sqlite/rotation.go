@@ -0,0 +1,177 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	rotationModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/rotation"
+	"github.com/ramil063/secondgodiplom/internal/logger"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/vault"
+)
+
+// Rotation хранилище для ротации мастер-ключа
+type Rotation struct {
+	DB *DB
+}
+
+// rotationQueries запросы ротации для одной таблицы
+// данные, зашифрованные на клиенте, не зависят от мастер-ключа и пропускаются
+// перешифрованная запись всегда получает актуальную схему связанных данных (для частей файла - STREAM)
+// запись сохраняется, только если ее версия ключа и вектор инициализации не изменились с момента чтения
+type rotationQueries struct {
+	count  string
+	rows   string
+	update string
+}
+
+var rotationTableQueries = map[string]rotationQueries{
+	rotationModel.TableDataKey: {
+		count: `SELECT COUNT(*) FROM user_data_key
+			WHERE key_version = ?1 AND encryption_algorithm IS NOT ?2`,
+		rows: `SELECT id, user_id, wrapped_key, iv, encryption_algorithm, key_version, '', 0, 0, FALSE, is_aad_bound, FALSE
+			FROM user_data_key
+			WHERE key_version = ?1 AND encryption_algorithm IS NOT ?2 AND id > ?3
+			ORDER BY id
+			LIMIT ?4`,
+		update: `UPDATE user_data_key
+			SET wrapped_key = ?1, iv = ?2, encryption_algorithm = ?3, key_version = ?4, is_aad_bound = ?7
+			WHERE id = ?5 AND key_version = ?6 AND iv = ?8`,
+	},
+	rotationModel.TableItem: {
+		count: `SELECT COUNT(*) FROM encrypted_item
+			WHERE key_version = ?1 AND encryption_algorithm IS NOT ?2`,
+		rows: `SELECT ei.id, ei.user_id, ei.encrypted_data, ei.iv, COALESCE(ei.encryption_algorithm, ''), ei.key_version,
+				it.alias, 0, 0, FALSE, ei.is_aad_bound, FALSE
+			FROM encrypted_item ei
+			JOIN item_type it ON it.id = ei.item_type_id
+			WHERE ei.key_version = ?1 AND ei.encryption_algorithm IS NOT ?2 AND ei.id > ?3
+			ORDER BY ei.id
+			LIMIT ?4`,
+		update: `UPDATE encrypted_item
+			SET encrypted_data = ?1, iv = ?2, encryption_algorithm = ?3, key_version = ?4, is_aad_bound = ?7
+			WHERE id = ?5 AND key_version = ?6 AND iv IS ?8`,
+	},
+	rotationModel.TableChunk: {
+		count: `SELECT COUNT(*) FROM binary_file_chunk
+			WHERE key_version = ?1 AND encryption_algorithm IS NOT ?2`,
+		rows: `SELECT bfc.id, bf.user_id, bfc.encrypted_data, bfc.iv, bfc.encryption_algorithm, bfc.key_version,
+				'', bfc.file_id, bfc.chunk_index, bfc.chunk_index = bf.total_chunks - 1,
+				bfc.is_aad_bound, bfc.is_stream_bound
+			FROM binary_file_chunk bfc
+			JOIN binary_file bf ON bf.id = bfc.file_id
+			WHERE bfc.key_version = ?1 AND bfc.encryption_algorithm IS NOT ?2 AND bfc.id > ?3
+			ORDER BY bfc.id
+			LIMIT ?4`,
+		update: `UPDATE binary_file_chunk
+			SET encrypted_data = ?1, iv = ?2, encryption_algorithm = ?3, key_version = ?4,
+				is_aad_bound = ?7, is_stream_bound = ?7
+			WHERE id = ?5 AND key_version = ?6 AND iv = ?8`,
+	},
+}
+
+func getRotationQueries(table string) (rotationQueries, error) {
+	q, ok := rotationTableQueries[table]
+	if !ok {
+		return rotationQueries{}, fmt.Errorf("unknown table for rotation: %s", table)
+	}
+	return q, nil
+}
+
+func (r *Rotation) CountRows(ctx context.Context, table string, keyVersion int) (int64, error) {
+	q, err := getRotationQueries(table)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = r.DB.conn(ctx).QueryRowContext(ctx, q.count, keyVersion, vault.Algorithm).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count rows: %w", err)
+	}
+	return count, nil
+}
+
+func (r *Rotation) GetRows(
+	ctx context.Context,
+	table string,
+	keyVersion int,
+	afterID int64,
+	limit int,
+) ([]*rotationModel.Row, error) {
+	q, err := getRotationQueries(table)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.conn(ctx).QueryContext(ctx, q.rows, keyVersion, vault.Algorithm, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rows: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*rotationModel.Row
+	for rows.Next() {
+		var row rotationModel.Row
+		err = rows.Scan(
+			&row.ID,
+			&row.UserID,
+			&row.Data,
+			&row.IV,
+			&row.EncryptionAlgorithm,
+			&row.KeyVersion,
+			&row.ItemType,
+			&row.FileID,
+			&row.ChunkIndex,
+			&row.FinalChunk,
+			&row.AADBound,
+			&row.StreamBound,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan rows: %w", err)
+		}
+		result = append(result, &row)
+	}
+	return result, rows.Err()
+}
+
+// UpdateRow сохранение перешифрованной записи
+// запись обновляется, только если ее версия ключа и вектор инициализации не изменились с момента чтения,
+// иначе возвращается rotationModel.ErrRowChanged
+func (r *Rotation) UpdateRow(
+	ctx context.Context,
+	table string,
+	row *rotationModel.Row,
+	oldKeyVersion int,
+	oldIV []byte,
+) error {
+	q, err := getRotationQueries(table)
+	if err != nil {
+		return err
+	}
+
+	exec, err := r.DB.conn(ctx).ExecContext(
+		ctx,
+		q.update,
+		row.Data,
+		row.IV,
+		row.EncryptionAlgorithm,
+		row.KeyVersion,
+		row.ID,
+		oldKeyVersion,
+		row.AADBound,
+		oldIV)
+
+	if err != nil {
+		return fmt.Errorf("failed to update row: %w", err)
+	}
+	updated, err := exec.RowsAffected()
+	if err != nil {
+		logger.WriteErrorLog("UpdateRow error in sql empty result")
+		return errors.New("UpdateRow error in sql empty result")
+	}
+	if updated != 1 {
+		return rotationModel.ErrRowChanged
+	}
+	return nil
+}
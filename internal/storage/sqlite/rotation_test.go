@@ -0,0 +1,89 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	rotationModel "github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/rotation"
+	"github.com/ramil063/secondgodiplom/internal/proto/gen/items/binarydata"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto"
+	"github.com/ramil063/secondgodiplom/internal/security/crypto/vault"
+)
+
+func TestDataKey_SaveDataKey(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDB(t)
+	userID := createUser(t, d, "user")
+	s := &DataKey{DB: d}
+
+	got, err := s.GetDataKey(ctx, userID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	key := &crypto.WrappedKey{Key: []byte("key"), IV: []byte("iv"), Algorithm: "AES-256-GCM", KeyVersion: 1, AADBound: true}
+	require.NoError(t, s.SaveDataKey(ctx, userID, key))
+	// ключ пользователя не перезаписывается
+	require.NoError(t, s.SaveDataKey(ctx, userID, &crypto.WrappedKey{Key: []byte("other"), IV: []byte("iv"), Algorithm: "AES-256-GCM", KeyVersion: 2}))
+
+	got, err = s.GetDataKey(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, key, got)
+}
+
+func TestRotation_Rows(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDB(t)
+	userID := createUser(t, d, "user")
+	s := &Rotation{DB: d}
+	items := &Item{DB: d}
+
+	itemID, err := items.SaveEncryptedData(ctx, testItem(userID, 0))
+	require.NoError(t, err)
+	clientItem := testItem(userID, 0)
+	clientItem.EncryptionAlgorithm = vault.Algorithm
+	_, err = items.SaveEncryptedData(ctx, clientItem)
+	require.NoError(t, err)
+
+	files := &File{DB: d}
+	fileID, err := files.CreateFileRecord(ctx, userID, 0, &binarydata.FileMetadata{Filename: "a.bin", TotalChunks: 2})
+	require.NoError(t, err)
+	require.NoError(t, files.SaveChunk(ctx, fileID, 0, []byte("a"), "AES-256-GCM", []byte("iv"), 1, true, true))
+	require.NoError(t, files.SaveChunk(ctx, fileID, 1, []byte("b"), "AES-256-GCM", []byte("iv"), 1, true, true))
+
+	count, err := s.CountRows(ctx, rotationModel.TableItem, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	rows, err := s.GetRows(ctx, rotationModel.TableItem, 1, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, itemID, rows[0].ID)
+	assert.Equal(t, "passwords", rows[0].ItemType)
+
+	chunks, err := s.GetRows(ctx, rotationModel.TableChunk, 1, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, chunks, 2)
+	assert.False(t, chunks[0].FinalChunk)
+	assert.True(t, chunks[1].FinalChunk)
+	assert.Equal(t, fileID, chunks[1].FileID)
+
+	row := rows[0]
+	oldIV := row.IV
+	row.Data = []byte("rotated")
+	row.IV = []byte("new iv")
+	row.KeyVersion = 2
+	// запись, перезаписанная сервером после чтения, не затирается
+	err = s.UpdateRow(ctx, rotationModel.TableItem, row, 1, []byte("other iv"))
+	assert.ErrorIs(t, err, rotationModel.ErrRowChanged)
+	require.NoError(t, s.UpdateRow(ctx, rotationModel.TableItem, row, 1, oldIV))
+
+	count, err = s.CountRows(ctx, rotationModel.TableItem, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	_, err = s.CountRows(ctx, "users", 1)
+	assert.Error(t, err)
+}
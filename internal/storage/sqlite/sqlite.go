@@ -0,0 +1,194 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// querier выполнение запросов, общее для соединения и транзакции
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// rowScanner чтение одной строки результата, общее для sql.Row и sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// txKey ключ открытой транзакции в контексте
+type txKey struct{}
+
+// DB встроенная бд SQLite
+// запросы выполняются через одно соединение: SQLite допускает только одну пишущую транзакцию,
+// поэтому запросы ждут завершения транзакции, а не получают ошибку занятой бд
+type DB struct {
+	db *sql.DB
+}
+
+// Open открытие файла бд path и применение новых миграций схемы, файл создается, если его нет
+// бд не открывается, если ее схема новее миграций, встроенных в сервер
+func Open(ctx context.Context, path string) (*DB, error) {
+	if path == "" {
+		return nil, errors.New("sqlite database path is empty")
+	}
+
+	sqlDB, err := sql.Open(driverName, dsn(path))
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite database: %w", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	d := &DB{db: sqlDB}
+	if err = sqlDB.PingContext(ctx); err != nil {
+		_ = sqlDB.Close()
+		return nil, fmt.Errorf("open sqlite database: %w", err)
+	}
+	if _, err = d.Migrate(ctx); err != nil {
+		_ = sqlDB.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// Close закрытие бд
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// WithTx выполнение fn в транзакции
+// запросы хранилищ с контекстом fn выполняются в транзакции, при ошибке или панике в fn она откатывается,
+// вложенный вызов выполняется в уже открытой транзакции
+func (d *DB) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		// После Commit откат ничего не делает
+		_ = tx.Rollback()
+	}()
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// conn транзакция, открытая WithTx, или бд, если транзакции в контексте нет
+func (d *DB) conn(ctx context.Context) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return d.db
+}
+
+// now текущее время в UTC
+// драйвер сохраняет время строкой вместе с часовым поясом, строки сравниваются как время только в одном поясе
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+// nullTime время в UTC для необязательного поля, nil сохраняется как NULL
+func nullTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+// placeholders параметры ?N для списка значений, начиная с номера first
+func placeholders(first, count int) string {
+	params := make([]string, count)
+	for i := range params {
+		params[i] = fmt.Sprintf("?%d", first+i)
+	}
+	return strings.Join(params, ", ")
+}
+
+// reserveID резервирование идентификатора новой строки таблицы с AUTOINCREMENT
+// счетчик таблицы хранится в sqlite_sequence, поэтому зарезервированный идентификатор не выдается при вставке
+func (d *DB) reserveID(ctx context.Context, table string) (int64, error) {
+	var id int64
+	err := d.WithTx(ctx, func(ctx context.Context) error {
+		err := d.conn(ctx).QueryRowContext(
+			ctx,
+			`UPDATE sqlite_sequence SET seq = seq + 1 WHERE name = ?1 RETURNING seq`,
+			table).Scan(&id)
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		// строка счетчика появляется после первой вставки в таблицу
+		id = 1
+		_, err = d.conn(ctx).ExecContext(ctx, `INSERT INTO sqlite_sequence (name, seq) VALUES (?1, ?2)`, table, id)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// errReservedIDNotFound идентификатор не зарезервирован пользователем, уже использован или просрочен
+var errReservedIDNotFound = status.Error(codes.NotFound, "reserved id not found")
+
+// saveReservedID сохранение идентификатора вида kind, зарезервированного клиентом пользователя userID
+// (таблица reserved_id), просроченные резервы пользователя удаляются
+func (d *DB) saveReservedID(ctx context.Context, kind string, id int64, userID int64, expiresAt time.Time) error {
+	_, err := d.conn(ctx).ExecContext(
+		ctx,
+		`DELETE FROM reserved_id WHERE user_id = ?1 AND expires_at <= ?2`,
+		userID,
+		now())
+	if err != nil {
+		return fmt.Errorf("failed to delete expired %s ids: %w", kind, err)
+	}
+	_, err = d.conn(ctx).ExecContext(
+		ctx,
+		`INSERT INTO reserved_id (kind, id, user_id, expires_at) VALUES (?1, ?2, ?3, ?4)`,
+		kind,
+		id,
+		userID,
+		expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to save reserved %s id: %w", kind, err)
+	}
+	return nil
+}
+
+// claimReservedID использование идентификатора вида kind, зарезервированного клиентом (таблица reserved_id)
+func (d *DB) claimReservedID(ctx context.Context, kind string, id int64, userID int64) error {
+	exec, err := d.conn(ctx).ExecContext(
+		ctx,
+		`DELETE FROM reserved_id WHERE kind = ?1 AND id = ?2 AND user_id = ?3 AND expires_at > ?4`,
+		kind,
+		id,
+		userID,
+		now())
+	if err != nil {
+		return fmt.Errorf("failed to claim %s id: %w", kind, err)
+	}
+	rows, err := exec.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to claim %s id: %w", kind, err)
+	}
+	if rows != 1 {
+		return errReservedIDNotFound
+	}
+	return nil
+}
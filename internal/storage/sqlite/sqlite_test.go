@@ -0,0 +1,121 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/user"
+	"github.com/ramil063/secondgodiplom/internal/storage/db/migrations"
+)
+
+// newTestDB бд во временном каталоге теста
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+	d, err := Open(context.Background(), filepath.Join(t.TempDir(), "gophkeeper.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = d.Close()
+	})
+	return d
+}
+
+// createUser регистрация пользователя для теста
+func createUser(t *testing.T, d *DB, login string) int {
+	t.Helper()
+	userID, err := (&Reg{DB: d}).RegisterUser(context.Background(), &user.User{Login: login, PasswordHash: "hash"})
+	require.NoError(t, err)
+	return userID
+}
+
+func TestOpen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "gophkeeper.db")
+
+	d, err := Open(ctx, path)
+	require.NoError(t, err)
+
+	var version int
+	require.NoError(t, d.db.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&version))
+	assert.Equal(t, 2, version)
+
+	applied, err := d.Migrate(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+
+	_, err = d.db.ExecContext(ctx, `PRAGMA user_version = 100`)
+	require.NoError(t, err)
+	require.NoError(t, d.Close())
+
+	_, err = Open(ctx, path)
+	assert.ErrorIs(t, err, migrations.ErrSchemaTooNew)
+
+	_, err = Open(ctx, "")
+	assert.Error(t, err)
+}
+
+func TestDB_WithTx(t *testing.T) {
+	tests := []struct {
+		name      string
+		fnErr     error
+		wantUsers int
+	}{
+		{
+			name:      "commit",
+			wantUsers: 1,
+		},
+		{
+			name:      "rollback on error",
+			fnErr:     errors.New("save metadata failed"),
+			wantUsers: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			d := newTestDB(t)
+
+			err := d.WithTx(ctx, func(ctx context.Context) error {
+				createUserErr := d.WithTx(ctx, func(ctx context.Context) error {
+					_, err := (&Reg{DB: d}).RegisterUser(ctx, &user.User{Login: "user", PasswordHash: "hash"})
+					return err
+				})
+				require.NoError(t, createUserErr)
+				return tt.fnErr
+			})
+			assert.ErrorIs(t, err, tt.fnErr)
+
+			var users int
+			require.NoError(t, d.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&users))
+			assert.Equal(t, tt.wantUsers, users)
+		})
+	}
+}
+
+func TestDB_reserveID(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDB(t)
+	userID := createUser(t, d, "user")
+	s := &Item{DB: d}
+
+	first, err := s.ReserveItemID(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), first)
+
+	second, err := s.ReserveItemID(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), second)
+
+	// запись без зарезервированного идентификатора не получает уже выданный
+	itemID, err := s.SaveEncryptedData(ctx, testItem(userID, 0))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), itemID)
+
+	itemID, err = s.SaveEncryptedData(ctx, testItem(userID, first))
+	require.NoError(t, err)
+	assert.Equal(t, first, itemID)
+}
@@ -0,0 +1,151 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+	"github.com/ramil063/secondgodiplom/internal/hash"
+	"github.com/ramil063/secondgodiplom/internal/logger"
+)
+
+// Token персональные токены доступа
+type Token struct {
+	DB *DB
+}
+
+// personalTokenColumns поля персонального токена в порядке scanPersonalToken
+const personalTokenColumns = "id, user_id, name, scopes, expires_at, last_used_at, created_at"
+
+// SavePersonalToken сохранение хеша персонального токена, возвращает идентификатор
+// токен не сохраняется, если у пользователя уже MaxPersonalTokens действующих токенов
+func (s *Token) SavePersonalToken(ctx context.Context, personal *auth.PersonalToken, token string) (int, error) {
+	scopes, err := json.Marshal(personal.Scopes)
+	if err != nil {
+		return 0, errors.New("SavePersonalToken error in marshal scopes")
+	}
+
+	row := s.DB.conn(ctx).QueryRowContext(
+		ctx,
+		`INSERT INTO personal_access_token (user_id, name, token_hash, scopes, expires_at, created_at)
+			SELECT ?1, ?2, ?3, ?4, ?5, ?7
+			WHERE (
+				SELECT COUNT(*) FROM personal_access_token
+				WHERE user_id = ?1 AND is_revoked = FALSE AND (expires_at IS NULL OR expires_at > ?7)
+			) < ?6
+			RETURNING id, created_at`,
+		personal.UserID,
+		personal.Name,
+		hash.GetTokenHash(token),
+		string(scopes),
+		nullTime(personal.ExpiresAt),
+		auth.MaxPersonalTokens,
+		now())
+
+	err = row.Scan(&personal.ID, &personal.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, status.Error(codes.ResourceExhausted, "too many personal tokens")
+	}
+	if err != nil {
+		logger.WriteErrorLog(err.Error())
+		return 0, errors.New("SavePersonalToken error in sql")
+	}
+	return personal.ID, nil
+}
+
+// GetPersonalToken действующий персональный токен по его значению
+// токен действует, пока не отозван, не истек и его владелец активен; отмечается время использования
+func (s *Token) GetPersonalToken(ctx context.Context, token string) (*auth.PersonalToken, error) {
+	row := s.DB.conn(ctx).QueryRowContext(
+		ctx,
+		`UPDATE personal_access_token SET last_used_at = ?2
+			WHERE token_hash = ?1 AND is_revoked = FALSE
+				AND (expires_at IS NULL OR expires_at > ?2)
+				AND user_id IN (SELECT id FROM users WHERE is_active = TRUE)
+			RETURNING `+personalTokenColumns,
+		hash.GetTokenHash(token),
+		now())
+
+	personal, err := scanPersonalToken(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "token not found")
+	}
+	if err != nil {
+		logger.WriteErrorLog(err.Error())
+		return nil, errors.New("GetPersonalToken error in sql")
+	}
+	return personal, nil
+}
+
+// ListPersonalTokens неотозванные персональные токены пользователя, сначала новые
+// истекшие токены тоже возвращаются, чтобы пользователь видел, что их нужно перевыпустить
+func (s *Token) ListPersonalTokens(ctx context.Context, userID int) ([]auth.PersonalToken, error) {
+	rows, err := s.DB.conn(ctx).QueryContext(
+		ctx,
+		`SELECT `+personalTokenColumns+`
+			FROM personal_access_token
+			WHERE user_id = ?1 AND is_revoked = FALSE
+			ORDER BY created_at DESC, id DESC`,
+		userID)
+	if err != nil {
+		return nil, errors.New("ListPersonalTokens error in sql")
+	}
+	defer rows.Close()
+
+	var tokens []auth.PersonalToken
+	for rows.Next() {
+		personal, err := scanPersonalToken(rows)
+		if err != nil {
+			return nil, errors.New("ListPersonalTokens error in scan")
+		}
+		tokens = append(tokens, *personal)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.New("ListPersonalTokens error in rows")
+	}
+	return tokens, nil
+}
+
+// RevokePersonalToken отзыв персонального токена пользователя
+func (s *Token) RevokePersonalToken(ctx context.Context, userID int, tokenID int) error {
+	exec, err := s.DB.conn(ctx).ExecContext(
+		ctx,
+		`UPDATE personal_access_token SET is_revoked = TRUE
+			WHERE id = ?1 AND user_id = ?2 AND is_revoked = FALSE`,
+		tokenID,
+		userID)
+	if err != nil {
+		return errors.New("RevokePersonalToken error in sql")
+	}
+	if rows, err := exec.RowsAffected(); err != nil || rows != 1 {
+		return status.Error(codes.NotFound, "token not found")
+	}
+	return nil
+}
+
+// scanPersonalToken чтение персонального токена, права хранятся массивом JSON
+func scanPersonalToken(row rowScanner) (*auth.PersonalToken, error) {
+	personal := &auth.PersonalToken{}
+	var scopes string
+	err := row.Scan(
+		&personal.ID,
+		&personal.UserID,
+		&personal.Name,
+		&scopes,
+		&personal.ExpiresAt,
+		&personal.LastUsedAt,
+		&personal.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal([]byte(scopes), &personal.Scopes); err != nil {
+		return nil, err
+	}
+	return personal, nil
+}
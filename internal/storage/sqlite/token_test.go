@@ -0,0 +1,100 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/account"
+	"github.com/ramil063/secondgodiplom/cmd/gophkeeper/storage/models/auth"
+)
+
+func TestToken_GetPersonalToken(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDB(t)
+	userID := createUser(t, d, "user")
+	s := &Token{DB: d}
+	expired := time.Now().Add(-time.Hour)
+
+	personal := &auth.PersonalToken{UserID: userID, Name: "backup", Scopes: []string{"passwords:read", "files:read"}}
+	tokenID, err := s.SavePersonalToken(ctx, personal, "gkp_active")
+	require.NoError(t, err)
+	assert.False(t, personal.CreatedAt.IsZero())
+	_, err = s.SavePersonalToken(ctx, &auth.PersonalToken{UserID: userID, Name: "old", Scopes: []string{}, ExpiresAt: &expired}, "gkp_expired")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{
+			name:  "active",
+			token: "gkp_active",
+		},
+		{
+			name:    "expired",
+			token:   "gkp_expired",
+			wantErr: true,
+		},
+		{
+			name:    "unknown",
+			token:   "gkp_unknown",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.GetPersonalToken(ctx, tt.token)
+			if tt.wantErr {
+				assert.Equal(t, codes.NotFound, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tokenID, got.ID)
+			assert.Equal(t, []string{"passwords:read", "files:read"}, got.Scopes)
+			assert.NotNil(t, got.LastUsedAt)
+			assert.Nil(t, got.ExpiresAt)
+		})
+	}
+
+	err = (&Account{DB: d}).DeactivateAccount(ctx, &account.Deactivation{
+		UserID:  userID,
+		PurgeAt: time.Now().Add(time.Hour),
+		Event:   account.SecurityEvent{UserID: userID, Type: account.EventAccountDeactivated},
+	})
+	require.NoError(t, err)
+	_, err = s.GetPersonalToken(ctx, "gkp_active")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestToken_ListPersonalTokens(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDB(t)
+	userID := createUser(t, d, "user")
+	s := &Token{DB: d}
+
+	var tokenIDs []int
+	for i := 0; i < auth.MaxPersonalTokens; i++ {
+		tokenID, err := s.SavePersonalToken(ctx, &auth.PersonalToken{UserID: userID, Name: "token", Scopes: []string{}}, fmt.Sprintf("gkp_%d", i))
+		require.NoError(t, err)
+		tokenIDs = append(tokenIDs, tokenID)
+	}
+	_, err := s.SavePersonalToken(ctx, &auth.PersonalToken{UserID: userID, Name: "token", Scopes: []string{}}, "gkp_extra")
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	require.NoError(t, s.RevokePersonalToken(ctx, userID, tokenIDs[0]))
+	err = s.RevokePersonalToken(ctx, userID, tokenIDs[0])
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	tokens, err := s.ListPersonalTokens(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, tokens, auth.MaxPersonalTokens-1)
+	assert.Equal(t, tokenIDs[len(tokenIDs)-1], tokens[0].ID)
+}